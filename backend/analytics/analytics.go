@@ -0,0 +1,171 @@
+// Package analytics emits structured gameplay events to a pluggable sink
+package analytics
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/metrics"
+)
+
+// Event names
+const (
+	EventGameCreated      = "game_created"
+	EventMatchDuration    = "match_duration"
+	EventFoodEatenCount   = "food_eaten_count"
+	EventDisconnectReason = "disconnect_reason"
+	EventRematchRate      = "rematch_rate"
+)
+
+const (
+	// bufferSize is the number of events queued for the sink before new ones are dropped
+	bufferSize = 4096
+	// batchSize is the maximum number of events handed to the sink at once
+	batchSize = 100
+	// flushInterval is how long a partial batch may wait before it is written
+	flushInterval = time.Second
+)
+
+var (
+	eventsSent = metrics.NewCounter(
+		"snake_analytics_events_sent_total",
+		"Analytics events written to the sink",
+	)
+	eventsDropped = metrics.NewCounter(
+		"snake_analytics_events_dropped_total",
+		"Analytics events dropped because the queue was full",
+	)
+	eventsFailed = metrics.NewCounter(
+		"snake_analytics_events_failed_total",
+		"Analytics events lost because the sink returned an error",
+	)
+)
+
+// Event is one analytics record
+type Event struct {
+	Name       string         `json:"event"`
+	Time       time.Time      `json:"time"`
+	InstanceID string         `json:"instance_id"`
+	GameID     string         `json:"game_id,omitempty"`
+	PlayerID   string         `json:"player_id,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// Sink stores batches of events
+// Write is only called from the emitter's goroutine, one batch at a time
+type Sink interface {
+	Write(events []Event) error
+	Close() error
+}
+
+// NewSink creates the sink selected by the configuration, or nil when analytics are disabled
+func NewSink(cfg config.Analytics) (Sink, error) {
+	switch cfg.Sink {
+	case config.AnalyticsNone:
+		return nil, nil
+	case config.AnalyticsStdout:
+		return NewStdout(), nil
+	case config.AnalyticsHTTP:
+		return NewHTTP(cfg.URL), nil
+	case config.AnalyticsKafka:
+		return NewKafka(cfg.URL, cfg.Topic), nil
+	}
+	return nil, fmt.Errorf("unknown analytics sink %q", cfg.Sink)
+}
+
+// Emitter queues events and writes them to a sink in batches on a background goroutine
+// Emitting never blocks gameplay: when the queue is full the event is dropped and counted.
+// A nil *Emitter discards every event, so analytics can be left unconfigured.
+type Emitter struct {
+	sink       Sink
+	instanceID string
+	events     chan Event
+	done       chan struct{}
+}
+
+// NewEmitter starts an emitter writing to sink, tagging events with the instance ID
+func NewEmitter(sink Sink, instanceID string) *Emitter {
+	e := &Emitter{
+		sink:       sink,
+		instanceID: instanceID,
+		events:     make(chan Event, bufferSize),
+		done:       make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Emit queues an event; gameID, playerID and data may be empty
+func (e *Emitter) Emit(name, gameID, playerID string, data map[string]any) {
+	if e == nil {
+		return
+	}
+
+	event := Event{
+		Name:       name,
+		Time:       time.Now().UTC(),
+		InstanceID: e.instanceID,
+		GameID:     gameID,
+		PlayerID:   playerID,
+		Data:       data,
+	}
+	select {
+	case e.events <- event:
+	default:
+		eventsDropped.Inc()
+	}
+}
+
+// Close writes the queued events and closes the sink
+// No events may be emitted after Close
+func (e *Emitter) Close() error {
+	if e == nil {
+		return nil
+	}
+	close(e.events)
+	<-e.done
+	return e.sink.Close()
+}
+
+// run batches queued events until the emitter is closed
+func (e *Emitter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, batchSize)
+	for {
+		select {
+		case event, ok := <-e.events:
+			if !ok {
+				e.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) == batchSize {
+				batch = e.flush(batch)
+			}
+		case <-ticker.C:
+			batch = e.flush(batch)
+		}
+	}
+}
+
+// flush writes a batch to the sink and returns the emptied batch for reuse
+// Failed batches are logged and dropped rather than retried, so a dead collector can't
+// grow memory without bound
+func (e *Emitter) flush(batch []Event) []Event {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := e.sink.Write(batch); err != nil {
+		eventsFailed.Add(uint64(len(batch)))
+		slog.Warn("Failed to write analytics events", "events", len(batch), "error", err)
+	} else {
+		eventsSent.Add(uint64(len(batch)))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// requestTimeout bounds each request to a collector
+const requestTimeout = 10 * time.Second
+
+// HTTP is a Sink posting each batch as a JSON array to a collector endpoint
+type HTTP struct {
+	url         string
+	contentType string
+	client      *http.Client
+	encode      func(events []Event) any // Shapes the request body
+}
+
+// NewHTTP creates a sink posting batches to url
+func NewHTTP(url string) *HTTP {
+	return &HTTP{
+		url:         url,
+		contentType: "application/json",
+		client:      &http.Client{Timeout: requestTimeout},
+		encode:      func(events []Event) any { return events },
+	}
+}
+
+// Write posts the batch; any non-2xx response is an error
+func (h *HTTP) Write(events []Event) error {
+	body, err := json.Marshal(h.encode(events))
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+
+	resp, err := h.client.Post(h.url, h.contentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector %s responded with %s", h.url, resp.Status)
+	}
+	return nil
+}
+
+// Close releases idle connections to the collector
+func (h *HTTP) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,28 @@
+package analytics
+
+import (
+	"net/url"
+	"strings"
+)
+
+// kafkaRecord is one record in a Kafka REST Proxy produce request
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"`
+	Value Event  `json:"value"`
+}
+
+// NewKafka creates a sink producing events to a Kafka topic through a Kafka REST Proxy
+// (v2 API) at proxyURL, so no Kafka client library is needed. Events are keyed by game ID
+// so all events of a game land on the same partition.
+func NewKafka(proxyURL, topic string) *HTTP {
+	sink := NewHTTP(strings.TrimRight(proxyURL, "/") + "/topics/" + url.PathEscape(topic))
+	sink.contentType = "application/vnd.kafka.json.v2+json"
+	sink.encode = func(events []Event) any {
+		records := make([]kafkaRecord, len(events))
+		for i, event := range events {
+			records[i] = kafkaRecord{Key: event.GameID, Value: event}
+		}
+		return map[string]any{"records": records}
+	}
+	return sink
+}
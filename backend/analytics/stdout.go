@@ -0,0 +1,31 @@
+package analytics
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Stdout is a Sink writing one JSON object per line, for collection by a log shipper
+type Stdout struct {
+	enc *json.Encoder
+}
+
+// NewStdout creates a sink writing to standard output (logs go to standard error)
+func NewStdout() *Stdout {
+	return &Stdout{enc: json.NewEncoder(os.Stdout)}
+}
+
+// Write encodes each event on its own line
+func (s *Stdout) Write(events []Event) error {
+	for _, event := range events {
+		if err := s.enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close does nothing; standard output stays open
+func (s *Stdout) Close() error {
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package audit records privileged admin actions
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// collection is the storage collection holding audit entries
+const collection = "audit"
+
+// Audited actions
+const (
+	ActionKick         = "kick"
+	ActionBan          = "ban"
+	ActionForceEnd     = "force_end"
+	ActionConfigChange = "config_change"
+	ActionAnnouncement = "announcement"
+	ActionReportReview = "report_review"
+	ActionRestrict     = "restrict"
+	ActionMaintenance  = "maintenance"
+)
+
+// Entry is one recorded admin action
+type Entry struct {
+	ID         int64          `json:"id"`
+	Time       time.Time      `json:"time"`
+	Actor      string         `json:"actor"`
+	RemoteAddr string         `json:"remote_addr,omitempty"`
+	Action     string         `json:"action"`
+	Target     string         `json:"target,omitempty"` // Player, game or setting acted on
+	Reason     string         `json:"reason,omitempty"`
+	Success    bool           `json:"success"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+// Filter selects audit entries; zero fields match everything
+type Filter struct {
+	Action string
+	Actor  string
+	Target string
+	Since  time.Time
+	Limit  int // Maximum number of entries returned, newest first
+}
+
+// Log keeps the audit trail in memory for queries and persists every entry to storage
+type Log struct {
+	store storage.Store
+
+	mu      sync.RWMutex
+	entries []Entry
+	nextID  int64
+}
+
+// Open loads the existing audit trail from the store
+func Open(store storage.Store) (*Log, error) {
+	l := &Log{store: store, nextID: 1}
+	err := store.Load(collection, func(raw json.RawMessage) error {
+		var entry Entry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return fmt.Errorf("decoding audit entry: %w", err)
+		}
+		l.entries = append(l.entries, entry)
+		l.nextID = max(l.nextID, entry.ID+1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record stores an entry, assigning its ID and time
+// The entry is kept for queries even if persisting it fails
+func (l *Log) Record(entry Entry) (Entry, error) {
+	l.mu.Lock()
+	entry.ID = l.nextID
+	l.nextID++
+	entry.Time = time.Now().UTC()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+
+	if err := l.store.Append(collection, entry); err != nil {
+		return entry, fmt.Errorf("persisting audit entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Query returns the entries matching the filter, newest first
+func (l *Log) Query(filter Filter) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	matches := []Entry{}
+	for _, entry := range slices.Backward(l.entries) {
+		if filter.Limit > 0 && len(matches) == filter.Limit {
+			break
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+		if filter.Target != "" && entry.Target != filter.Target {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+			break // Entries are in time order, everything after this is older
+		}
+		matches = append(matches, entry)
+	}
+	return matches
+}
@@ -7,7 +7,12 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("snake-game-secret-key-change-in-production") // TODO: Move to env variable
+var jwtSecret = []byte("snake-game-secret-key-change-in-production") // Replaced at startup by SetSecret
+
+// SetSecret sets the key used to sign and validate player tokens
+func SetSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
 
 type Claims struct {
 	PlayerID string `json:"player_id"`
@@ -1,10 +1,13 @@
 package auth
 
 import (
-	"log"
+	"crypto/subtle"
+	"log/slog"
 	"net/http"
-	"snake-backend/game"
-	"snake-backend/models"
+	"strings"
+
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/models"
 )
 
 // AuthMiddleware validates JWT token and adds player info to request context
@@ -23,7 +26,7 @@ func AuthMiddleware(gameManager *game.Manager) func(http.Handler) http.Handler {
 				http.Error(w, "Unauthorized: Player not found or inactive", http.StatusUnauthorized)
 				return
 			}
-			if player.Send == nil {
+			if !player.Connected() {
 				http.Error(w, "Unauthorized: Player not found or inactive", http.StatusUnauthorized)
 				return
 			}
@@ -43,6 +46,44 @@ func AuthMiddleware(gameManager *game.Manager) func(http.Handler) http.Handler {
 	}
 }
 
+// AdminMiddleware only lets through requests carrying the admin token as a Bearer token
+// Browsers can't set headers on WebSocket handshakes, so upgrade requests may pass the
+// token in the token query parameter instead
+// If no admin token is configured, the admin API is disabled entirely
+func AdminMiddleware(adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if adminToken == "" {
+				http.Error(w, "Forbidden: Admin API disabled", http.StatusForbidden)
+				return
+			}
+
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" && isWebSocketUpgrade(r) && r.URL.Query().Get("token") != "" {
+				authHeader = "Bearer " + r.URL.Query().Get("token")
+			}
+			tokenString, err := ExtractTokenFromHeader(authHeader)
+			if err != nil {
+				http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			if subtle.ConstantTimeCompare([]byte(tokenString), []byte(adminToken)) != 1 {
+				slog.Warn("Rejected admin request: invalid token", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				http.Error(w, "Unauthorized: Invalid admin token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isWebSocketUpgrade reports whether the request is a WebSocket handshake
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
 // extractTokenFromRequest extracts token from Authorization header or query parameter
 func extractTokenFromRequest(r *http.Request, w http.ResponseWriter) (string, error) {
 	authHeader := r.Header.Get("Authorization")
@@ -77,7 +118,7 @@ func extractAndValidateToken(r *http.Request, w http.ResponseWriter) (*Claims, e
 	// Validate token
 	claims, err := ValidateToken(tokenString)
 	if err != nil {
-		log.Printf("Token validation error: %v", err)
+		slog.Warn("Token validation failed", "error", err)
 		http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 		return nil, err
 	}
@@ -0,0 +1,214 @@
+// Package bitboard encodes a game state as a compact binary board for constrained clients,
+// such as microcontroller displays, that can't afford to parse JSON game updates
+//
+// A board is a header, bitmask planes of the grid and a record per snake. Multi-byte values
+// are big-endian:
+//
+//	header   version, status, countdown, width, height, flags, snake count (1 byte each)
+//	planes   snake bodies, food, then obstacles when flags has PlaneObstacles; each is
+//	         ceil(width*height/8) bytes, and cell (x, y) is bit (y*width + x) % 8, least
+//	         significant first, of byte (y*width + x) / 8
+//	snakes   head x, head y, direction, flags (1 byte each), length, score (2 bytes each),
+//	         in the order of the game state's snakes
+//	tick     the tick of the state (4 bytes) when flags has BoardTick
+//
+// Decoders skip what follows the parts they know, so boards of this version may grow
+// trailing parts announced by new flags.
+package bitboard
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// Version is the version of the layout, the first byte of every board
+const Version = 1
+
+// Sizes of the parts of a board, in bytes
+const (
+	HeaderSize = 7
+	SnakeSize  = 8
+	TickSize   = 4
+)
+
+// Header flags
+const (
+	PlaneObstacles = 1 << 0 // An obstacles plane follows the food plane
+	BoardTick      = 1 << 1 // The tick follows the snake records
+)
+
+// Snake flags
+const (
+	SnakeDown = 1 << 0 // Crashed and waiting to respawn
+)
+
+// statuses numbers the game statuses; unknown ones are sent as 0
+var statuses = []string{"waiting", "countdown", "playing", "paused", "finished"}
+
+var errShort = errors.New("bitboard: board is truncated")
+
+// Snake is the metadata of one snake on a board
+type Snake struct {
+	Head      engine.Position
+	Direction constants.Direction
+	Down      bool
+	Length    int
+	Score     int
+}
+
+// Board is a decoded board
+type Board struct {
+	Status    string
+	Countdown int
+	Tick      int // Tick of the state, which moves name the one they were sent after; 0 when not sent
+	Width     int
+	Height    int
+	Snakes    []Snake
+	Bodies    []byte // Planes as encoded; see Occupied
+	Food      []byte
+	Obstacles []byte // nil when the board has none
+}
+
+// PlaneSize returns the size in bytes of one plane of a width by height grid
+func PlaneSize(width, height int) int {
+	return (width*height + 7) / 8
+}
+
+// Encode lays out the state on the constants.GRID_WIDTH by constants.GRID_HEIGHT grid
+// Counts, scores and the tick are capped to fit their fields; cells off the grid are left out.
+func Encode(state *engine.GameState) []byte {
+	width, height := constants.GRID_WIDTH, constants.GRID_HEIGHT
+	planeSize := PlaneSize(width, height)
+
+	flags := byte(BoardTick)
+	planes := 2
+	if len(state.Obstacles) > 0 {
+		flags |= PlaneObstacles
+		planes++
+	}
+	snakes := state.Snakes[:min(len(state.Snakes), 255)]
+
+	board := make([]byte, HeaderSize+planes*planeSize+len(snakes)*SnakeSize+TickSize)
+	board[0] = Version
+	for i, status := range statuses {
+		if status == state.Status {
+			board[1] = byte(i)
+		}
+	}
+	board[2] = byte(min(max(state.Countdown, 0), 255))
+	board[3] = byte(width)
+	board[4] = byte(height)
+	board[5] = flags
+	board[6] = byte(len(snakes))
+
+	bodies := board[HeaderSize : HeaderSize+planeSize]
+	food := board[HeaderSize+planeSize : HeaderSize+2*planeSize]
+	for _, snake := range state.Snakes {
+		for _, cell := range snake.Body {
+			set(bodies, width, height, cell)
+		}
+	}
+	for _, f := range state.Foods {
+		set(food, width, height, f.Position)
+	}
+	if flags&PlaneObstacles != 0 {
+		obstacles := board[HeaderSize+2*planeSize : HeaderSize+3*planeSize]
+		for _, cell := range state.Obstacles {
+			set(obstacles, width, height, cell)
+		}
+	}
+
+	record := board[HeaderSize+planes*planeSize:]
+	for _, snake := range snakes {
+		if len(snake.Body) > 0 {
+			record[0] = byte(snake.Body[0].X)
+			record[1] = byte(snake.Body[0].Y)
+		}
+		record[2] = byte(snake.Direction)
+		if snake.Down {
+			record[3] |= SnakeDown
+		}
+		binary.BigEndian.PutUint16(record[4:], uint16(min(len(snake.Body), 0xffff)))
+		binary.BigEndian.PutUint16(record[6:], uint16(min(max(snake.Score, 0), 0xffff)))
+		record = record[SnakeSize:]
+	}
+	binary.BigEndian.PutUint32(record, uint32(min(uint64(max(state.Tick, 0)), math.MaxUint32)))
+	return board
+}
+
+// Decode reads a board, the reverse of Encode
+func Decode(data []byte) (Board, error) {
+	if len(data) < HeaderSize {
+		return Board{}, errShort
+	}
+	if data[0] != Version {
+		return Board{}, fmt.Errorf("bitboard: unsupported version %d", data[0])
+	}
+	board := Board{
+		Countdown: int(data[2]),
+		Width:     int(data[3]),
+		Height:    int(data[4]),
+	}
+	if int(data[1]) < len(statuses) {
+		board.Status = statuses[data[1]]
+	}
+	flags, count := data[5], int(data[6])
+
+	planeSize := PlaneSize(board.Width, board.Height)
+	planes := 2
+	if flags&PlaneObstacles != 0 {
+		planes++
+	}
+	if len(data) < HeaderSize+planes*planeSize+count*SnakeSize {
+		return Board{}, errShort
+	}
+	data = data[HeaderSize:]
+	board.Bodies, data = data[:planeSize], data[planeSize:]
+	board.Food, data = data[:planeSize], data[planeSize:]
+	if flags&PlaneObstacles != 0 {
+		board.Obstacles, data = data[:planeSize], data[planeSize:]
+	}
+
+	if flags&BoardTick != 0 && len(data) < count*SnakeSize+TickSize {
+		return Board{}, errShort
+	}
+
+	board.Snakes = make([]Snake, count)
+	for i := range board.Snakes {
+		board.Snakes[i] = Snake{
+			Head:      engine.Position{X: int(data[0]), Y: int(data[1])},
+			Direction: constants.Direction(data[2]),
+			Down:      data[3]&SnakeDown != 0,
+			Length:    int(binary.BigEndian.Uint16(data[4:])),
+			Score:     int(binary.BigEndian.Uint16(data[6:])),
+		}
+		data = data[SnakeSize:]
+	}
+	if flags&BoardTick != 0 {
+		board.Tick = int(binary.BigEndian.Uint32(data))
+	}
+	return board, nil
+}
+
+// Occupied reports whether a cell is set in a plane of the board
+func (b Board) Occupied(plane []byte, cell engine.Position) bool {
+	if plane == nil || cell.X < 0 || cell.X >= b.Width || cell.Y < 0 || cell.Y >= b.Height {
+		return false
+	}
+	i := cell.Y*b.Width + cell.X
+	return plane[i/8]&(1<<(i%8)) != 0
+}
+
+// set marks a cell in a plane
+func set(plane []byte, width, height int, cell engine.Position) {
+	if cell.X < 0 || cell.X >= width || cell.Y < 0 || cell.Y >= height {
+		return
+	}
+	i := cell.Y*width + cell.X
+	plane[i/8] |= 1 << (i % 8)
+}
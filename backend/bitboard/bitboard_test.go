@@ -0,0 +1,143 @@
+package bitboard
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+func testState() *engine.GameState {
+	return &engine.GameState{
+		Status: "playing",
+		Tick:   70000,
+		Snakes: []engine.Snake{
+			{ID: "a", Body: []engine.Position{{X: 3, Y: 4}, {X: 2, Y: 4}, {X: 1, Y: 4}}, Direction: constants.RIGHT, Score: 2},
+			{ID: "b", Body: []engine.Position{{X: 39, Y: 29}, {X: 39, Y: 28}}, Direction: constants.DOWN, Down: true},
+		},
+		Foods:     []engine.Food{{Position: engine.Position{X: 20, Y: 10}}, {Position: engine.Position{X: 0, Y: 0}}},
+		Obstacles: []engine.Position{{X: 5, Y: 5}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		state func() *engine.GameState
+	}{
+		{"with obstacles", testState},
+		{"without obstacles", func() *engine.GameState {
+			state := testState()
+			state.Obstacles = nil
+			return state
+		}},
+		{"countdown", func() *engine.GameState {
+			state := testState()
+			state.Status, state.Countdown, state.Tick = "countdown", 3, 0
+			return state
+		}},
+		{"no snakes", func() *engine.GameState { return &engine.GameState{Status: "waiting"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state()
+			board, err := Decode(Encode(state))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if board.Status != state.Status || board.Countdown != state.Countdown || board.Tick != state.Tick {
+				t.Errorf("header = %s/%d/tick %d, want %s/%d/tick %d", board.Status, board.Countdown, board.Tick, state.Status, state.Countdown, state.Tick)
+			}
+			if board.Width != constants.GRID_WIDTH || board.Height != constants.GRID_HEIGHT {
+				t.Errorf("grid = %dx%d, want %dx%d", board.Width, board.Height, constants.GRID_WIDTH, constants.GRID_HEIGHT)
+			}
+			if (board.Obstacles != nil) != (len(state.Obstacles) > 0) {
+				t.Errorf("obstacles plane present = %v, want %v", board.Obstacles != nil, len(state.Obstacles) > 0)
+			}
+			for _, cell := range state.Obstacles {
+				if !board.Occupied(board.Obstacles, cell) {
+					t.Errorf("obstacle %v not set", cell)
+				}
+			}
+			for _, food := range state.Foods {
+				if !board.Occupied(board.Food, food.Position) {
+					t.Errorf("food %v not set", food.Position)
+				}
+			}
+			if len(board.Snakes) != len(state.Snakes) {
+				t.Fatalf("snakes = %d, want %d", len(board.Snakes), len(state.Snakes))
+			}
+			for i, snake := range state.Snakes {
+				want := Snake{Head: snake.Body[0], Direction: snake.Direction, Down: snake.Down, Length: len(snake.Body), Score: snake.Score}
+				if board.Snakes[i] != want {
+					t.Errorf("snake %d = %+v, want %+v", i, board.Snakes[i], want)
+				}
+				for _, cell := range snake.Body {
+					if !board.Occupied(board.Bodies, cell) {
+						t.Errorf("snake %d cell %v not set", i, cell)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeOnlySetsGivenCells(t *testing.T) {
+	board, err := Decode(Encode(testState()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cell := range []engine.Position{{X: 4, Y: 4}, {X: 20, Y: 11}, {X: 6, Y: 5}} {
+		if board.Occupied(board.Bodies, cell) || board.Occupied(board.Food, cell) || board.Occupied(board.Obstacles, cell) {
+			t.Errorf("cell %v set, want it empty", cell)
+		}
+	}
+	if board.Occupied(board.Bodies, engine.Position{X: -1, Y: 0}) {
+		t.Error("cell off the grid reported occupied")
+	}
+}
+
+func TestDecodeWithoutTick(t *testing.T) {
+	// Boards written before the tick was added end after the snake records
+	data := Encode(testState())
+	data = data[:len(data)-TickSize]
+	data[5] &^= BoardTick
+	board, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if board.Tick != 0 || len(board.Snakes) != 2 {
+		t.Errorf("board = tick %d, %d snakes, want tick 0, 2 snakes", board.Tick, len(board.Snakes))
+	}
+}
+
+func TestDecodeSkipsTrailingParts(t *testing.T) {
+	data := append(Encode(testState()), 0xAA, 0xBB)
+	if _, err := Decode(data); err != nil {
+		t.Fatalf("Decode with trailing bytes: %v", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	data := Encode(testState())
+	for _, n := range []int{0, HeaderSize - 1, HeaderSize, len(data) - TickSize, len(data) - 1} {
+		if _, err := Decode(data[:n]); !errors.Is(err, errShort) {
+			t.Errorf("Decode of %d of %d bytes: err = %v, want %v", n, len(data), err, errShort)
+		}
+	}
+
+	other := append([]byte(nil), data...)
+	other[0] = Version + 1
+	if _, err := Decode(other); err == nil {
+		t.Error("Decode of an unknown version succeeded")
+	}
+}
+
+func TestEncodeCapsTick(t *testing.T) {
+	state := testState()
+	state.Tick = -5
+	if board, _ := Decode(Encode(state)); board.Tick != 0 {
+		t.Errorf("negative tick decoded as %d, want 0", board.Tick)
+	}
+}
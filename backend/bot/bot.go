@@ -0,0 +1,56 @@
+// Package bot runs automated players against a snake server
+//
+// A Bot only decides where to steer: it observes a frame and returns a direction. A Runner
+// connects it over the client SDK as a regular player flagged as a bot, answers challenges,
+// readies up and sends the moves, so bots play under the same rules and limits as people.
+package bot
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// Bot chooses the direction of its snake
+// Next is called with one frame at a time from a single goroutine. Frames that arrive while
+// Next is still deciding are skipped, so a slow bot reacts late rather than falling behind.
+type Bot interface {
+	Next(obs Observation) constants.Direction
+}
+
+// Func adapts a function to the Bot interface
+type Func func(obs Observation) constants.Direction
+
+// Next calls f
+func (f Func) Next(obs Observation) constants.Direction { return f(obs) }
+
+// Observation is what a bot sees of a game on one frame
+type Observation struct {
+	GameID   string
+	PlayerID string              // The bot's player ID, which is also its snake's ID
+	State    *engine.GameState   // The frame; the bot may keep it, it is not reused
+	Rules    engine.RulesProfile // Rules of the game, e.g. whether the board wraps
+}
+
+// Snake returns the bot's snake, nil if it is not on the board
+func (o Observation) Snake() *engine.Snake {
+	for i := range o.State.Snakes {
+		if o.State.Snakes[i].ID == o.PlayerID {
+			return &o.State.Snakes[i]
+		}
+	}
+	return nil
+}
+
+// Builtin are the bundled example bots by name, for tools that pick a bot by flag
+var Builtin = map[string]func() Bot{
+	"greedy":     func() Bot { return Greedy{} },
+	"wallhugger": func() Bot { return WallHugger{} },
+}
+
+// BuiltinNames returns the names of the bundled bots in order
+func BuiltinNames() []string {
+	return slices.Sorted(maps.Keys(Builtin))
+}
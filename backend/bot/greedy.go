@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// Greedy heads for the closest food and never turns into a cell that is already taken
+// It is the reference bot: simple, deterministic and good enough to beat an idle snake.
+type Greedy struct{}
+
+var directions = []constants.Direction{constants.UP, constants.RIGHT, constants.DOWN, constants.LEFT}
+
+// Next picks the safe direction closest to food, preferring to keep going straight
+func (Greedy) Next(obs Observation) constants.Direction {
+	snake := obs.Snake()
+	if snake == nil || len(snake.Body) == 0 {
+		return constants.UP
+	}
+
+	occupied := make(map[engine.Position]bool)
+	for _, s := range obs.State.Snakes {
+		for _, pos := range s.Body {
+			occupied[pos] = true
+		}
+	}
+
+	best, bestDistance := snake.Direction, -1
+	for _, direction := range directions {
+		if engine.Reverses(snake.Direction, direction) {
+			continue
+		}
+		head, ok := engine.NextHead(snake.Body[0], direction, obs.Rules.Wrap)
+		if !ok || occupied[head] {
+			continue
+		}
+		distance := foodDistance(head, obs.State.Foods)
+		if bestDistance < 0 || distance < bestDistance || distance == bestDistance && direction == snake.Direction {
+			best, bestDistance = direction, distance
+		}
+	}
+	return best
+}
+
+// foodDistance returns the Manhattan distance to the closest food, ignoring wrapping
+func foodDistance(pos engine.Position, foods []engine.Food) int {
+	best := constants.GRID_WIDTH + constants.GRID_HEIGHT
+	for _, food := range foods {
+		best = min(best, abs(food.Position.X-pos.X)+abs(food.Position.Y-pos.Y))
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
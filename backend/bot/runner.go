@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/client"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// defaultMovesPerSecond caps the moves a runner sends when MovesPerSecond is not set
+const defaultMovesPerSecond = 10
+
+// Runner plays a bot on a server as a regular player
+// It accepts every challenge and rematch, readies up for each game and, depending on the
+// options, challenges an opponent or keeps starting single player games.
+type Runner struct {
+	URL      string
+	Username string
+	Bot      Bot
+	Rules    string // Rules preset for games the runner starts, server default when empty
+
+	Opponent     string // Username to challenge whenever both are free; empty to wait for challenges
+	SinglePlayer bool   // Play single player games back to back instead
+
+	// MovesPerSecond caps the moves sent to the server (default 10); moves over the cap are
+	// dropped and the bot is asked again on a later frame
+	MovesPerSecond float64
+
+	Logger *slog.Logger // Defaults to slog.Default
+}
+
+// frame is a playing frame handed to the bot
+type frame struct {
+	gameID string
+	rules  engine.RulesProfile
+	state  *engine.GameState
+}
+
+// Run connects the bot and plays until ctx is done or the connection ends
+func (r *Runner) Run(ctx context.Context) error {
+	logger := r.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger = logger.With("bot", r.Username)
+
+	c, err := client.Connect(ctx, r.URL, client.Options{Username: r.Username, Bot: true})
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", r.Username, err)
+	}
+	defer c.Close()
+
+	// The callbacks below run on c.Run's goroutine and own this state
+	var (
+		gameID    string
+		rules     engine.RulesProfile
+		requested bool // A challenge or single player start is outstanding
+	)
+	frames := make(chan frame, 1)
+
+	endGame := func() {
+		gameID, requested = "", false
+	}
+	startSinglePlayer := func() {
+		requested = true
+		c.StartSinglePlayer(r.Rules)
+	}
+
+	c.OnLobbyStatus(func(players []client.LobbyPlayer) {
+		if r.Opponent == "" || gameID != "" || requested {
+			return
+		}
+		for _, p := range players {
+			if p.Username == r.Opponent && !p.InGame {
+				requested = true
+				c.Challenge(p.ID, r.Rules)
+				return
+			}
+		}
+	})
+	c.OnMatchFound(func(request client.GameRequest) {
+		c.Accept(request.GameID)
+	})
+	c.OnRequestSent(func(request client.GameRequest) {
+		rules = request.Rules
+	})
+	c.OnGameAccepted(func(id string, _ *engine.GameState) {
+		gameID, requested = id, false
+		c.Ready(id)
+	})
+	c.OnGameStart(func(start client.GameStart) {
+		gameID, rules, requested = start.State.ID, start.Rules, false
+	})
+	c.OnGameUpdate(func(state *engine.GameState) {
+		if state.ID != gameID || state.Status != "playing" {
+			return
+		}
+		// Only the newest frame is kept for the bot
+		select {
+		case <-frames:
+		default:
+		}
+		frames <- frame{gameID: gameID, rules: rules, state: state}
+	})
+	c.OnGameOver(func(state *engine.GameState) {
+		if state.ID != gameID {
+			return
+		}
+		endGame()
+		if r.SinglePlayer {
+			startSinglePlayer()
+		}
+	})
+	c.OnRematchRequest(func(request client.RematchRequest) {
+		c.AcceptRematch(request.GameID)
+	})
+	c.OnGameRejected(func(string, client.PlayerInfo) { endGame() })
+	c.OnDisconnect(func(client.Disconnect) { endGame() })
+	c.OnError(func(err *client.ServerError) {
+		logger.Warn("Server error", "code", err.Code, "message", err.Message)
+		// The request failed, let the next lobby update retry it
+		requested = false
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go r.play(ctx, c, logger, frames)
+
+	if err := c.JoinLobby(); err != nil {
+		return err
+	}
+	if r.SinglePlayer {
+		startSinglePlayer()
+	}
+	return c.Run(ctx)
+}
+
+// play asks the bot for a direction on each frame and sends the moves
+func (r *Runner) play(ctx context.Context, c *client.Client, logger *slog.Logger, frames <-chan frame) {
+	rate := r.MovesPerSecond
+	if rate <= 0 {
+		rate = defaultMovesPerSecond
+	}
+	minInterval := time.Duration(float64(time.Second) / rate)
+
+	var (
+		gameID   string
+		lastSent constants.Direction
+		sent     bool // lastSent is a turn not yet seen applied
+		lastMove time.Time
+	)
+	for {
+		var f frame
+		select {
+		case <-ctx.Done():
+			return
+		case f = <-frames:
+		}
+
+		if f.gameID != gameID {
+			gameID, sent = f.gameID, false
+		}
+		obs := Observation{GameID: f.gameID, PlayerID: c.ID(), State: f.state, Rules: f.rules}
+		snake := obs.Snake()
+		if snake == nil {
+			continue
+		}
+		direction, ok := r.decide(obs, logger)
+		if !ok {
+			continue
+		}
+
+		if direction == snake.Direction {
+			sent = false
+			continue
+		}
+		if sent && direction == lastSent {
+			continue
+		}
+		if time.Since(lastMove) < minInterval {
+			continue
+		}
+		if err := c.SendMove(f.gameID, direction); err != nil {
+			return
+		}
+		lastSent, sent, lastMove = direction, true, time.Now()
+	}
+}
+
+// decide asks the bot for a direction; a bot that panics keeps its heading for the frame
+func (r *Runner) decide(obs Observation, logger *slog.Logger) (direction constants.Direction, ok bool) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logger.Error("Bot panicked", "game_id", obs.GameID, "panic", recovered)
+			ok = false
+		}
+	}()
+	return r.Bot.Next(obs), true
+}
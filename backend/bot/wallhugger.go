@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// WallHugger plays for survival rather than score
+// It only takes moves that leave its head at least as much open space as its body is long,
+// and among those follows walls and bodies so it doesn't cut the open board into pockets.
+// Food is only a tie-break, so it grows slowly but rarely traps itself.
+type WallHugger struct{}
+
+// Next picks the roomiest safe direction, preferring cells next to walls and bodies
+func (WallHugger) Next(obs Observation) constants.Direction {
+	snake := obs.Snake()
+	if snake == nil || len(snake.Body) == 0 {
+		return constants.UP
+	}
+
+	occupied := make(map[engine.Position]bool)
+	for _, s := range obs.State.Snakes {
+		for _, pos := range s.Body {
+			occupied[pos] = true
+		}
+	}
+
+	// A move is roomy when the snake could fit its whole body into the space it leads to
+	need := len(snake.Body) + 1
+	best, found := snake.Direction, false
+	var bestRoomy bool
+	var bestArea, bestHug, bestFood int
+	for _, direction := range directions {
+		if engine.Reverses(snake.Direction, direction) {
+			continue
+		}
+		head, ok := engine.NextHead(snake.Body[0], direction, obs.Rules.Wrap)
+		if !ok || occupied[head] {
+			continue
+		}
+
+		area := openArea(head, occupied, obs.Rules.Wrap, need)
+		roomy := area >= need
+		hug := blockedNeighbours(head, occupied, obs.Rules.Wrap)
+		food := foodDistance(head, obs.State.Foods)
+
+		better := !found
+		switch {
+		case better:
+		case roomy != bestRoomy:
+			better = roomy
+		case !roomy && area != bestArea:
+			// Out of room either way, so buy the most time
+			better = area > bestArea
+		case hug != bestHug:
+			better = hug > bestHug
+		case food != bestFood:
+			better = food < bestFood
+		default:
+			better = direction == snake.Direction
+		}
+		if better {
+			best, found = direction, true
+			bestRoomy, bestArea, bestHug, bestFood = roomy, area, hug, food
+		}
+	}
+	return best
+}
+
+// openArea counts the free cells reachable from start, stopping once limit is reached
+func openArea(start engine.Position, occupied map[engine.Position]bool, wrap bool, limit int) int {
+	seen := map[engine.Position]bool{start: true}
+	queue := []engine.Position{start}
+	for len(queue) > 0 && len(seen) < limit {
+		pos := queue[0]
+		queue = queue[1:]
+		for _, direction := range directions {
+			next, ok := engine.NextHead(pos, direction, wrap)
+			if !ok || occupied[next] || seen[next] {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return len(seen)
+}
+
+// blockedNeighbours counts the walls and occupied cells around pos
+func blockedNeighbours(pos engine.Position, occupied map[engine.Position]bool, wrap bool) int {
+	blocked := 0
+	for _, direction := range directions {
+		if next, ok := engine.NextHead(pos, direction, wrap); !ok || occupied[next] {
+			blocked++
+		}
+	}
+	return blocked
+}
@@ -0,0 +1,12 @@
+package bus
+
+// Handler receives the payload of a message published on a subscribed topic
+type Handler func(payload []byte)
+
+// Bus publishes messages to, and receives messages from, other server instances
+// Implementations deliver a message to every subscriber of its topic, including the publisher
+type Bus interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(topic string, handler Handler) error
+	Close() error
+}
@@ -0,0 +1,95 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bariiss/snake/backend/reporting"
+)
+
+// connectTimeout bounds the initial connection check
+const connectTimeout = 5 * time.Second
+
+// Redis is a Bus backed by Redis pub/sub
+type Redis struct {
+	client *redis.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	subs []*redis.PubSub
+}
+
+// NewRedis connects to the Redis server at url (redis:// or rediss://)
+// An unreachable server is reported immediately rather than on first publish
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bus URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer pingCancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connecting to %s: %w", opts.Addr, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Redis{client: client, ctx: ctx, cancel: cancel}, nil
+}
+
+// Publish sends payload to every subscriber of topic
+func (r *Redis) Publish(topic string, payload []byte) error {
+	return r.client.Publish(r.ctx, topic, payload).Err()
+}
+
+// Subscribe calls handler for every message published on topic until the bus is closed
+// Messages are delivered sequentially on a dedicated goroutine
+func (r *Redis) Subscribe(topic string, handler Handler) error {
+	pubsub := r.client.Subscribe(r.ctx, topic)
+	// Wait for the subscription to be confirmed so no message published afterwards is missed
+	if _, err := pubsub.Receive(r.ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("subscribing to %s: %w", topic, err)
+	}
+
+	r.mu.Lock()
+	r.subs = append(r.subs, pubsub)
+	r.mu.Unlock()
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			func() {
+				defer func() {
+					if rec := recover(); rec != nil {
+						slog.Error("Bus handler panicked", "topic", topic, "panic", rec)
+						reporting.Panic("Bus handler panicked", rec, "topic", topic)
+					}
+				}()
+				handler([]byte(msg.Payload))
+			}()
+		}
+	}()
+	return nil
+}
+
+// Close stops all subscriptions and closes the connection
+func (r *Redis) Close() error {
+	r.cancel()
+
+	r.mu.Lock()
+	for _, pubsub := range r.subs {
+		pubsub.Close()
+	}
+	r.subs = nil
+	r.mu.Unlock()
+
+	return r.client.Close()
+}
@@ -0,0 +1,545 @@
+// Package clans keeps the clans players form and the results of their members' rounds
+//
+// Clans are keyed by their tag and members by player ID, which a player keeps across
+// reconnects through their token. Every change is appended to storage and the clans are
+// rebuilt from the log on start. Pending invites are kept in memory only.
+package clans
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// collection is the storage collection holding clan events
+const collection = "clans"
+
+// MaxMembers is the most players a clan holds
+const MaxMembers = 50
+
+// Name length limits, in characters
+const (
+	MinNameLength = 3
+	MaxNameLength = 32
+)
+
+// tagPattern is what a tag may look like before it is uppercased
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9]{2,5}$`)
+
+// Member roles, from highest to lowest
+const (
+	RoleLeader  = "leader"  // Manages the clan; there is exactly one
+	RoleOfficer = "officer" // Invites players and removes members
+	RoleMember  = "member"
+)
+
+// Errors returned for refused clan changes
+var (
+	ErrInvalidTag     = errors.New("a tag is 2 to 5 letters or digits")
+	ErrInvalidName    = fmt.Errorf("a clan name is %d to %d characters", MinNameLength, MaxNameLength)
+	ErrTagTaken       = errors.New("tag already taken")
+	ErrNotFound       = errors.New("clan not found")
+	ErrAlreadyInClan  = errors.New("already in a clan")
+	ErrNotInClan      = errors.New("not in a clan")
+	ErrNotOfficer     = errors.New("only the leader and officers can do this")
+	ErrNotLeader      = errors.New("only the leader can do this")
+	ErrOutranked      = errors.New("members can only be removed by someone of a higher role")
+	ErrNotMember      = errors.New("not a member of the clan")
+	ErrInvalidRole    = errors.New("role must be leader, officer or member")
+	ErrClanFull       = fmt.Errorf("a clan holds at most %d members", MaxMembers)
+	ErrInviteNotFound = errors.New("no pending invite from this clan")
+)
+
+// Member is a player in a clan
+type Member struct {
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"` // Username when the player joined
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Clan is a group of players sharing a tag
+type Clan struct {
+	Tag       string    `json:"tag"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Members   []Member  `json:"members"` // Leader first, then by role and join time
+}
+
+// member returns the member with the given player ID
+func (c *Clan) member(playerID string) (*Member, bool) {
+	index := slices.IndexFunc(c.Members, func(m Member) bool { return m.PlayerID == playerID })
+	if index < 0 {
+		return nil, false
+	}
+	return &c.Members[index], true
+}
+
+// Standing is a clan's record in rounds against members of other clans
+type Standing struct {
+	Tag     string `json:"tag"`
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+	Rounds  int    `json:"rounds"`
+	Wins    int    `json:"wins"`
+	Losses  int    `json:"losses"`
+	Ties    int    `json:"ties"`
+}
+
+// Matchup is the head-to-head record of two clans
+type Matchup struct {
+	ClanA string `json:"clan_a"` // The tag that sorts first
+	ClanB string `json:"clan_b"`
+	WinsA int    `json:"wins_a"`
+	WinsB int    `json:"wins_b"`
+	Ties  int    `json:"ties"`
+}
+
+// Scoreboard is the clan-vs-clan record computed from member rounds
+type Scoreboard struct {
+	Standings []Standing `json:"standings"` // Most wins first
+	Matchups  []Matchup  `json:"matchups"`  // Most rounds first
+}
+
+// Event kinds
+const (
+	eventCreated   = "created"
+	eventJoined    = "joined"
+	eventLeft      = "left" // Also recorded when a member is removed
+	eventRole      = "role"
+	eventDisbanded = "disbanded"
+	eventRound     = "round"
+)
+
+// event is one persisted clan change or round between members of two clans
+type event struct {
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`
+	Tag         string    `json:"tag"`
+	Name        string    `json:"name,omitempty"`
+	PlayerID    string    `json:"player_id,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	OpponentTag string    `json:"opponent_tag,omitempty"` // Set on rounds
+	Winner      string    `json:"winner,omitempty"`       // Tag of the winning clan; empty on a tie
+}
+
+// Clans holds every clan in memory and persists changes to storage
+type Clans struct {
+	store storage.Store
+
+	mu        sync.RWMutex
+	clans     map[string]*Clan               // Tag -> clan
+	byPlayer  map[string]string              // Player ID -> tag
+	invites   map[string]map[string]struct{} // Player ID -> tags that invited them
+	standings map[string]*Standing           // Tag -> record against other clans
+	matchups  map[[2]string]*Matchup         // Sorted tag pair -> head-to-head record
+}
+
+// Open rebuilds the clans from the store
+func Open(store storage.Store) (*Clans, error) {
+	c := &Clans{
+		store:     store,
+		clans:     make(map[string]*Clan),
+		byPlayer:  make(map[string]string),
+		invites:   make(map[string]map[string]struct{}),
+		standings: make(map[string]*Standing),
+		matchups:  make(map[[2]string]*Matchup),
+	}
+	err := store.Load(collection, func(raw json.RawMessage) error {
+		var e event
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return fmt.Errorf("decoding clan event: %w", err)
+		}
+		c.applyLocked(e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NormalizeTag returns the form tags are stored and compared in; tags are case-insensitive
+func NormalizeTag(tag string) string {
+	return strings.ToUpper(strings.TrimSpace(tag))
+}
+
+// Create founds a clan led by the player
+func (c *Clans) Create(tag, name, playerID, username string) (Clan, error) {
+	name = strings.TrimSpace(name)
+	if !tagPattern.MatchString(strings.TrimSpace(tag)) {
+		return Clan{}, ErrInvalidTag
+	}
+	if length := utf8.RuneCountInString(name); length < MinNameLength || length > MaxNameLength {
+		return Clan{}, ErrInvalidName
+	}
+	tag = NormalizeTag(tag)
+
+	c.mu.Lock()
+	if _, taken := c.clans[tag]; taken {
+		c.mu.Unlock()
+		return Clan{}, ErrTagTaken
+	}
+	if _, inClan := c.byPlayer[playerID]; inClan {
+		c.mu.Unlock()
+		return Clan{}, ErrAlreadyInClan
+	}
+	now := time.Now().UTC()
+	events := []event{
+		{Time: now, Kind: eventCreated, Tag: tag, Name: name},
+		{Time: now, Kind: eventJoined, Tag: tag, PlayerID: playerID, Username: username, Role: RoleLeader},
+	}
+	return c.commitLocked(tag, events...)
+}
+
+// Invite lets another player join the inviter's clan; the inviter must be an officer
+// Returns the inviter's clan.
+func (c *Clans) Invite(inviterID, inviteeID string) (Clan, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clan, err := c.officerClanLocked(inviterID)
+	if err != nil {
+		return Clan{}, err
+	}
+	if _, inClan := c.byPlayer[inviteeID]; inClan {
+		return Clan{}, ErrAlreadyInClan
+	}
+	if len(clan.Members) >= MaxMembers {
+		return Clan{}, ErrClanFull
+	}
+	invites, exists := c.invites[inviteeID]
+	if !exists {
+		invites = make(map[string]struct{})
+		c.invites[inviteeID] = invites
+	}
+	invites[clan.Tag] = struct{}{}
+	return clone(clan), nil
+}
+
+// Accept joins the clan that invited the player
+func (c *Clans) Accept(tag, playerID, username string) (Clan, error) {
+	tag = NormalizeTag(tag)
+	c.mu.Lock()
+	clan, exists := c.clans[tag]
+	if _, invited := c.invites[playerID][tag]; !exists || !invited {
+		c.mu.Unlock()
+		return Clan{}, ErrInviteNotFound
+	}
+	if _, inClan := c.byPlayer[playerID]; inClan {
+		c.mu.Unlock()
+		return Clan{}, ErrAlreadyInClan
+	}
+	if len(clan.Members) >= MaxMembers {
+		c.mu.Unlock()
+		return Clan{}, ErrClanFull
+	}
+	return c.commitLocked(tag, event{Time: time.Now().UTC(), Kind: eventJoined, Tag: tag, PlayerID: playerID, Username: username, Role: RoleMember})
+}
+
+// Decline drops the player's invite from a clan
+func (c *Clans) Decline(tag, playerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropInviteLocked(playerID, NormalizeTag(tag))
+}
+
+// Leave takes the player out of their clan
+// A leaving leader hands over to the longest-serving officer, or else member; the clan is
+// disbanded when its last member leaves, in which case the returned clan has no members.
+func (c *Clans) Leave(playerID string) (Clan, error) {
+	c.mu.Lock()
+	tag, inClan := c.byPlayer[playerID]
+	if !inClan {
+		c.mu.Unlock()
+		return Clan{}, ErrNotInClan
+	}
+	return c.commitLocked(tag, c.departureLocked(c.clans[tag], playerID)...)
+}
+
+// Remove takes a member out of the remover's clan; leaders remove anyone, officers
+// only members. Returns the clan and the removed member.
+func (c *Clans) Remove(removerID, playerID string) (Clan, Member, error) {
+	c.mu.Lock()
+	clan, err := c.officerClanLocked(removerID)
+	if err != nil {
+		c.mu.Unlock()
+		return Clan{}, Member{}, err
+	}
+	target, isMember := clan.member(playerID)
+	if !isMember {
+		c.mu.Unlock()
+		return Clan{}, Member{}, ErrNotMember
+	}
+	remover, _ := clan.member(removerID)
+	if rank(target.Role) >= rank(remover.Role) {
+		c.mu.Unlock()
+		return Clan{}, Member{}, ErrOutranked
+	}
+	removed := *target
+	updated, err := c.commitLocked(clan.Tag, c.departureLocked(clan, playerID)...)
+	return updated, removed, err
+}
+
+// SetRole changes a member's role; only the leader may. Making another member leader
+// hands the leadership over, and the old leader becomes an officer.
+func (c *Clans) SetRole(leaderID, playerID, role string) (Clan, error) {
+	if rank(role) < 0 {
+		return Clan{}, ErrInvalidRole
+	}
+	c.mu.Lock()
+	tag, inClan := c.byPlayer[leaderID]
+	if !inClan {
+		c.mu.Unlock()
+		return Clan{}, ErrNotInClan
+	}
+	clan := c.clans[tag]
+	if leader, _ := clan.member(leaderID); leader.Role != RoleLeader {
+		c.mu.Unlock()
+		return Clan{}, ErrNotLeader
+	}
+	target, isMember := clan.member(playerID)
+	if !isMember || playerID == leaderID {
+		c.mu.Unlock()
+		return Clan{}, ErrNotMember
+	}
+	now := time.Now().UTC()
+	events := []event{{Time: now, Kind: eventRole, Tag: tag, PlayerID: target.PlayerID, Role: role}}
+	if role == RoleLeader {
+		events = append(events, event{Time: now, Kind: eventRole, Tag: tag, PlayerID: leaderID, Role: RoleOfficer})
+	}
+	return c.commitLocked(tag, events...)
+}
+
+// Of returns the player's clan
+func (c *Clans) Of(playerID string) (Clan, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tag, inClan := c.byPlayer[playerID]
+	if !inClan {
+		return Clan{}, false
+	}
+	return clone(c.clans[tag]), true
+}
+
+// TagOf returns the tag of the player's clan, empty if they are in none
+func (c *Clans) TagOf(playerID string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.byPlayer[playerID]
+}
+
+// RecordRound adds a finished round to the scoreboard when its players belong to
+// different clans; winnerID is empty for a tie
+func (c *Clans) RecordRound(player1ID, player2ID, winnerID string) error {
+	c.mu.Lock()
+	tag1, tag2 := c.byPlayer[player1ID], c.byPlayer[player2ID]
+	if tag1 == "" || tag2 == "" || tag1 == tag2 {
+		c.mu.Unlock()
+		return nil
+	}
+	e := event{Time: time.Now().UTC(), Kind: eventRound, Tag: tag1, OpponentTag: tag2}
+	switch winnerID {
+	case player1ID:
+		e.Winner = tag1
+	case player2ID:
+		e.Winner = tag2
+	}
+	c.applyLocked(e)
+	c.mu.Unlock()
+	return c.persist(e)
+}
+
+// Scoreboard returns every clan's record and the head-to-head records between clans
+func (c *Clans) Scoreboard() Scoreboard {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	board := Scoreboard{Standings: []Standing{}, Matchups: []Matchup{}}
+	for tag, clan := range c.clans {
+		standing := Standing{Tag: tag}
+		if recorded, exists := c.standings[tag]; exists {
+			standing = *recorded
+		}
+		standing.Name = clan.Name
+		standing.Members = len(clan.Members)
+		board.Standings = append(board.Standings, standing)
+	}
+	slices.SortFunc(board.Standings, func(a, b Standing) int {
+		return cmp.Or(b.Wins-a.Wins, a.Losses-b.Losses, strings.Compare(a.Tag, b.Tag))
+	})
+	for _, matchup := range c.matchups {
+		board.Matchups = append(board.Matchups, *matchup)
+	}
+	slices.SortFunc(board.Matchups, func(a, b Matchup) int {
+		roundsA, roundsB := a.WinsA+a.WinsB+a.Ties, b.WinsA+b.WinsB+b.Ties
+		return cmp.Or(roundsB-roundsA, strings.Compare(a.ClanA, b.ClanA), strings.Compare(a.ClanB, b.ClanB))
+	})
+	return board
+}
+
+// officerClanLocked returns the clan the player is an officer or leader of; caller must
+// hold mu
+func (c *Clans) officerClanLocked(playerID string) (*Clan, error) {
+	tag, inClan := c.byPlayer[playerID]
+	if !inClan {
+		return nil, ErrNotInClan
+	}
+	clan := c.clans[tag]
+	if member, _ := clan.member(playerID); member.Role == RoleMember {
+		return nil, ErrNotOfficer
+	}
+	return clan, nil
+}
+
+// departureLocked builds the events of a member leaving the clan; caller must hold mu
+func (c *Clans) departureLocked(clan *Clan, playerID string) []event {
+	now := time.Now().UTC()
+	events := []event{{Time: now, Kind: eventLeft, Tag: clan.Tag, PlayerID: playerID}}
+	if len(clan.Members) == 1 {
+		return append(events, event{Time: now, Kind: eventDisbanded, Tag: clan.Tag})
+	}
+	if member, _ := clan.member(playerID); member.Role == RoleLeader {
+		// Members are kept by role, then join time, so the successor comes right after
+		successor := clan.Members[1]
+		events = append(events, event{Time: now, Kind: eventRole, Tag: clan.Tag, PlayerID: successor.PlayerID, Role: RoleLeader})
+	}
+	return events
+}
+
+// commitLocked applies events to a clan, releases mu and persists them
+// Returns the clan as it is after the events; it has no members once disbanded.
+func (c *Clans) commitLocked(tag string, events ...event) (Clan, error) {
+	for _, e := range events {
+		c.applyLocked(e)
+	}
+	clan := Clan{Tag: tag, Members: []Member{}}
+	if current, exists := c.clans[tag]; exists {
+		clan = clone(current)
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, e := range events {
+		errs = append(errs, c.persist(e))
+	}
+	return clan, errors.Join(errs...)
+}
+
+// applyLocked applies an event; caller must hold mu or own c exclusively
+func (c *Clans) applyLocked(e event) {
+	clan := c.clans[e.Tag]
+	if clan == nil && e.Kind != eventCreated && e.Kind != eventRound {
+		return
+	}
+	switch e.Kind {
+	case eventCreated:
+		c.clans[e.Tag] = &Clan{Tag: e.Tag, Name: e.Name, CreatedAt: e.Time, Members: []Member{}}
+	case eventJoined:
+		clan.Members = append(clan.Members, Member{PlayerID: e.PlayerID, Username: e.Username, Role: e.Role, JoinedAt: e.Time})
+		c.byPlayer[e.PlayerID] = e.Tag
+		delete(c.invites, e.PlayerID)
+		sortMembers(clan)
+	case eventLeft:
+		clan.Members = slices.DeleteFunc(clan.Members, func(m Member) bool { return m.PlayerID == e.PlayerID })
+		delete(c.byPlayer, e.PlayerID)
+	case eventRole:
+		if member, isMember := clan.member(e.PlayerID); isMember {
+			member.Role = e.Role
+			sortMembers(clan)
+		}
+	case eventDisbanded:
+		delete(c.clans, e.Tag)
+		delete(c.standings, e.Tag)
+		for key := range c.matchups {
+			if key[0] == e.Tag || key[1] == e.Tag {
+				delete(c.matchups, key)
+			}
+		}
+		for playerID := range c.invites {
+			c.dropInviteLocked(playerID, e.Tag)
+		}
+	case eventRound:
+		c.applyRoundLocked(e)
+	}
+}
+
+// applyRoundLocked adds a round to the standings and matchups; caller must hold mu or own
+// c exclusively
+func (c *Clans) applyRoundLocked(e event) {
+	for _, tag := range []string{e.Tag, e.OpponentTag} {
+		standing, exists := c.standings[tag]
+		if !exists {
+			standing = &Standing{Tag: tag}
+			c.standings[tag] = standing
+		}
+		standing.Rounds++
+		switch e.Winner {
+		case "":
+			standing.Ties++
+		case tag:
+			standing.Wins++
+		default:
+			standing.Losses++
+		}
+	}
+
+	key := [2]string{e.Tag, e.OpponentTag}
+	slices.Sort(key[:])
+	matchup, exists := c.matchups[key]
+	if !exists {
+		matchup = &Matchup{ClanA: key[0], ClanB: key[1]}
+		c.matchups[key] = matchup
+	}
+	switch e.Winner {
+	case "":
+		matchup.Ties++
+	case key[0]:
+		matchup.WinsA++
+	default:
+		matchup.WinsB++
+	}
+}
+
+// dropInviteLocked forgets a player's invite from a clan; caller must hold mu
+func (c *Clans) dropInviteLocked(playerID, tag string) {
+	delete(c.invites[playerID], tag)
+	if len(c.invites[playerID]) == 0 {
+		delete(c.invites, playerID)
+	}
+}
+
+// persist appends an event; the change stays applied in memory even if it fails
+func (c *Clans) persist(e event) error {
+	if err := c.store.Append(collection, e); err != nil {
+		return fmt.Errorf("persisting clan event: %w", err)
+	}
+	return nil
+}
+
+// rank orders roles from member up to leader; -1 for an unknown role
+func rank(role string) int {
+	return slices.Index([]string{RoleMember, RoleOfficer, RoleLeader}, role)
+}
+
+// sortMembers keeps the leader first, then officers, then members, each by join time
+func sortMembers(clan *Clan) {
+	slices.SortStableFunc(clan.Members, func(a, b Member) int {
+		return cmp.Or(rank(b.Role)-rank(a.Role), a.JoinedAt.Compare(b.JoinedAt))
+	})
+}
+
+// clone copies a clan so it can be used once mu is released
+func clone(clan *Clan) Clan {
+	copied := *clan
+	copied.Members = slices.Clone(clan.Members)
+	return copied
+}
@@ -0,0 +1,304 @@
+// Package client is a Go SDK for the snake WebSocket protocol, for bots, tools and
+// integration tests
+//
+// Connect dials the server and waits for the connected message; register callbacks, then
+// call Run to dispatch server messages until the connection ends:
+//
+//	c, err := client.Connect(ctx, "ws://localhost:8080/ws", client.Options{Username: "bot"})
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	c.OnGameUpdate(func(state *engine.GameState) { c.SendMove(state.ID, constants.UP) })
+//	c.JoinLobby()
+//	return c.Run(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bariiss/snake/backend/constants"
+)
+
+// writeWait is how long a single message write may take
+const writeWait = 5 * time.Second
+
+// ErrClosed is returned when sending on a client that has been closed
+var ErrClosed = errors.New("client closed")
+
+// Options select how a client identifies itself
+type Options struct {
+	Username string            // Used for the initial login when Token is empty
+	Token    string            // Token from a previous session, resumes that player
+	Bot      bool              // Show the player as a bot in the lobby
+	Device   string            // ID of the device the client runs on, kept across accounts; optional
+	Language string            // Language of error messages, such as "de"; English by default
+	Dialer   *websocket.Dialer // Defaults to websocket.DefaultDialer
+}
+
+// Client is one player session
+// Commands may be called from any goroutine, including from callbacks.
+type Client struct {
+	conn *websocket.Conn
+
+	id       string
+	username string
+	token    string
+	connID   string
+	features map[string]bool
+	emotes   []Emote
+	muted    []string
+	server   ServerInfo
+
+	announcement *Announcement
+	profile      Profile
+	clan         *Clan
+
+	writeMu sync.Mutex
+	closed  bool
+
+	handlersMu sync.Mutex
+	handlers   handlers
+}
+
+// Connect dials the server's WebSocket endpoint and waits for the connected message
+// A server that refuses the connection answers with an error, returned as *ServerError.
+func Connect(ctx context.Context, endpoint string, opts Options) (*Client, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+	query := target.Query()
+	switch {
+	case opts.Token != "":
+		query.Set("token", opts.Token)
+	case opts.Username != "":
+		query.Set("username", opts.Username)
+	default:
+		return nil, errors.New("a username or token is required")
+	}
+	if opts.Bot {
+		query.Set("bot", "true")
+	}
+	if opts.Device != "" {
+		query.Set("device", opts.Device)
+	}
+	if opts.Language != "" {
+		query.Set("lang", opts.Language)
+	}
+	target.RawQuery = query.Encode()
+
+	dialer := websocket.DefaultDialer
+	if opts.Dialer != nil {
+		dialer = opts.Dialer
+	}
+	// Ask for the protocols the SDK speaks, batch envelopes first; servers without
+	// negotiation ignore them
+	withProtocol := *dialer
+	withProtocol.Subprotocols = []string{constants.SUBPROTOCOL_JSON_V2, constants.SUBPROTOCOL_JSON_V1}
+	conn, _, err := withProtocol.DialContext(ctx, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	c := &Client{conn: conn}
+	if err := c.awaitConnected(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// awaitConnected reads the first message, which is either connected or the reason the
+// server refused the connection
+func (c *Client) awaitConnected(ctx context.Context) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetReadDeadline(deadline)
+		defer c.conn.SetReadDeadline(time.Time{})
+	}
+
+	_, frame, err := c.conn.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("read connected message: %w", err)
+	}
+	// The connected message is always alone in its frame
+	if messages := frameMessages(frame); len(messages) > 0 {
+		frame = messages[0]
+	}
+	var msg struct {
+		Type   string `json:"type"`
+		Player struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"player"`
+		ConnID   string          `json:"conn_id"`
+		Token    string          `json:"token"`
+		Features map[string]bool `json:"features"`
+		Emotes   []Emote         `json:"emotes"`
+		Muted    []string        `json:"muted"`
+		Server   ServerInfo      `json:"server"`
+
+		Announcement *Announcement `json:"announcement"`
+		Profile      Profile       `json:"profile"`
+		Clan         *Clan         `json:"clan"`
+		ServerError
+	}
+	if err := json.Unmarshal(frame, &msg); err != nil {
+		return fmt.Errorf("decode connected message: %w", err)
+	}
+
+	switch msg.Type {
+	case constants.MSG_CONNECTED:
+	case constants.MSG_ERROR:
+		return &msg.ServerError
+	default:
+		return fmt.Errorf("expected %s message, got %q", constants.MSG_CONNECTED, msg.Type)
+	}
+	c.id, c.username, c.token = msg.Player.ID, msg.Player.Username, msg.Token
+	c.connID, c.features, c.server = msg.ConnID, msg.Features, msg.Server
+	c.emotes, c.muted = msg.Emotes, msg.Muted
+	c.announcement, c.profile, c.clan = msg.Announcement, msg.Profile, msg.Clan
+	return nil
+}
+
+// ID returns the player ID assigned by the server
+func (c *Client) ID() string { return c.id }
+
+// Username returns the player's username
+func (c *Client) Username() string { return c.username }
+
+// Token returns the session token; pass it in Options to resume the player after a reconnect
+func (c *Client) Token() string { return c.token }
+
+// ConnID returns the ID the server logs this connection under
+func (c *Client) ConnID() string { return c.connID }
+
+// Features returns the feature flags the server reported on connect
+func (c *Client) Features() map[string]bool { return c.features }
+
+// Emotes returns the emotes the server accepts, in display order
+func (c *Client) Emotes() []Emote { return c.emotes }
+
+// Muted returns the usernames the player had muted when connecting, lowercased; later
+// changes are reported through OnMutedPlayers
+func (c *Client) Muted() []string { return c.muted }
+
+// Announcement returns the announcement shown when connecting, nil if there was none;
+// later ones are reported through OnAnnouncement
+func (c *Client) Announcement() *Announcement { return c.announcement }
+
+// Profile returns the player's titles when connecting; later changes are reported through
+// OnProfile and OnTitleEarned
+func (c *Client) Profile() Profile { return c.profile }
+
+// Clan returns the player's clan when connecting, nil if they were in none; later changes
+// are reported through OnClanUpdate and OnClanLeft
+func (c *Client) Clan() *Clan { return c.clan }
+
+// Server returns the instance the client is connected to
+func (c *Client) Server() ServerInfo { return c.server }
+
+// Subprotocol returns the WebSocket subprotocol the server selected, empty if the server
+// doesn't negotiate one
+func (c *Client) Subprotocol() string { return c.conn.Subprotocol() }
+
+// Run reads server messages and dispatches them to the registered callbacks until the
+// connection ends or ctx is done
+// Callbacks run on Run's goroutine, one at a time and in the order messages arrive.
+// Returns nil when ctx is done or the client was closed, and the read error otherwise;
+// a close frame from the server is returned as *websocket.CloseError.
+func (c *Client) Run(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	for {
+		_, frame, err := c.conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || c.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		for _, message := range frameMessages(frame) {
+			c.dispatch(message)
+		}
+	}
+}
+
+// envelopePrefix starts every frame of the snake.v2.json subprotocol
+var envelopePrefix = []byte(`{"type":"` + constants.MSG_BATCH + `",`)
+
+// frameMessages splits a frame into the messages it carries
+// The server batches queued messages into one frame: under snake.v2.json as the messages
+// of a batch envelope, otherwise separated by newlines.
+func frameMessages(frame []byte) [][]byte {
+	if bytes.HasPrefix(frame, envelopePrefix) {
+		var envelope struct {
+			Messages []json.RawMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(frame, &envelope); err == nil {
+			messages := make([][]byte, len(envelope.Messages))
+			for i, message := range envelope.Messages {
+				messages[i] = message
+			}
+			return messages
+		}
+	}
+
+	var messages [][]byte
+	for message := range bytes.SplitSeq(frame, []byte{'\n'}) {
+		if len(message) > 0 {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Close ends the session with a normal close frame; safe to call more than once
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return c.conn.Close()
+}
+
+func (c *Client) isClosed() bool {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.closed
+}
+
+// Send writes a message of the given type with data's fields merged in
+// The typed commands cover the protocol; Send is for messages they don't.
+func (c *Client) Send(msgType string, data map[string]any) error {
+	msg := make(map[string]any, len(data)+1)
+	for key, value := range data {
+		msg[key] = value
+	}
+	msg["type"] = msgType
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.conn.WriteJSON(msg)
+}
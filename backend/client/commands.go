@@ -0,0 +1,494 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+)
+
+var directionNames = map[constants.Direction]string{
+	constants.UP:    "up",
+	constants.DOWN:  "down",
+	constants.LEFT:  "left",
+	constants.RIGHT: "right",
+}
+
+// JoinLobby makes the player visible in the lobby
+func (c *Client) JoinLobby() error {
+	return c.Send(constants.MSG_JOIN_LOBBY, nil)
+}
+
+// LeaveLobby removes the player from the lobby
+func (c *Client) LeaveLobby() error {
+	return c.Send(constants.MSG_LEAVE_LOBBY, nil)
+}
+
+// Challenge asks another player for a game; rules names a preset, empty for the default
+func (c *Client) Challenge(targetID, rules string) error {
+	return c.Send(constants.MSG_GAME_REQUEST, map[string]any{"target_id": targetID, "rules": rules})
+}
+
+// ChallengeWithTieBreak asks another player for a game whose head-on collisions are
+// decided by tieBreak, such as constants.TIE_BREAK_LENGTH, instead of the preset's own
+func (c *Client) ChallengeWithTieBreak(targetID, rules, tieBreak string) error {
+	return c.Send(constants.MSG_GAME_REQUEST, map[string]any{"target_id": targetID, "rules": rules, "tie_break": tieBreak})
+}
+
+// CancelChallenge withdraws a challenge sent to targetID
+func (c *Client) CancelChallenge(targetID string) error {
+	return c.Send(constants.MSG_GAME_REQUEST_CANCEL, map[string]any{"target_id": targetID})
+}
+
+// Accept accepts a challenge
+func (c *Client) Accept(gameID string) error {
+	return c.Send(constants.MSG_GAME_ACCEPT, map[string]any{"game_id": gameID})
+}
+
+// Reject declines a challenge
+func (c *Client) Reject(gameID string) error {
+	return c.Send(constants.MSG_GAME_REJECT, map[string]any{"game_id": gameID})
+}
+
+// Ready marks the player ready; the round starts once both players are
+func (c *Client) Ready(gameID string) error {
+	return c.Send(constants.MSG_PLAYER_READY, map[string]any{"game_id": gameID})
+}
+
+// StartSinglePlayer starts a single player game; rules names a preset, empty for the default
+func (c *Client) StartSinglePlayer(rules string) error {
+	return c.Send(constants.MSG_START_SINGLE_PLAYER, map[string]any{"rules": rules})
+}
+
+// StartSinglePlayerAt starts a single player game of a variant, endless or score_attack, at a
+// difficulty, which sets how fast the game starts and how quickly it speeds up; empty
+// names pick the defaults
+func (c *Client) StartSinglePlayerAt(rules, difficulty, variant string) error {
+	return c.Send(constants.MSG_START_SINGLE_PLAYER, map[string]any{"rules": rules, "difficulty": difficulty, "variant": variant})
+}
+
+// RequestLeaderboard asks for the best scores of a single player variant played at a
+// difficulty, empty for the defaults; answered through OnLeaderboard
+func (c *Client) RequestLeaderboard(variant, difficulty string) error {
+	return c.Send(constants.MSG_GET_LEADERBOARD, map[string]any{"variant": variant, "difficulty": difficulty})
+}
+
+// PauseGame pauses a single player game, or resumes it when paused is false
+func (c *Client) PauseGame(gameID string, paused bool) error {
+	return c.Send(constants.MSG_PAUSE_GAME, map[string]any{"game_id": gameID, "paused": paused})
+}
+
+// RestartGame starts a single player game over right away, keeping its ID and settings
+func (c *Client) RestartGame(gameID string) error {
+	return c.Send(constants.MSG_RESTART_GAME, map[string]any{"game_id": gameID})
+}
+
+// SuspendGame saves a single player game being played so it can be resumed later, from any
+// connection of the same account; the game is removed
+func (c *Client) SuspendGame(gameID string) error {
+	return c.Send(constants.MSG_SUSPEND_GAME, map[string]any{"game_id": gameID})
+}
+
+// ResumeGame starts the player's saved game again from where it was suspended
+func (c *Client) ResumeGame() error {
+	return c.Send(constants.MSG_RESUME_GAME, nil)
+}
+
+// RequestSavedGame asks whether the player has a saved game, answered through OnSavedGame
+func (c *Client) RequestSavedGame() error {
+	return c.Send(constants.MSG_GET_SAVED_GAME, nil)
+}
+
+// RequestSinglePlayerStats asks for the player's lifetime single player stats, answered
+// through OnSinglePlayerStats
+func (c *Client) RequestSinglePlayerStats() error {
+	return c.Send(constants.MSG_GET_SINGLE_PLAYER_STATS, nil)
+}
+
+// RequestCampaign asks for the campaign levels and the player's progress, answered through
+// OnCampaign
+func (c *Client) RequestCampaign() error {
+	return c.Send(constants.MSG_GET_CAMPAIGN, nil)
+}
+
+// StartCampaignLevel starts a single player game of an unlocked campaign level, from 1
+func (c *Client) StartCampaignLevel(level int) error {
+	return c.Send(constants.MSG_START_CAMPAIGN_LEVEL, map[string]any{"level": level})
+}
+
+// SendMove turns the player's snake on the next tick
+// The snake turns at most once per tick; a second move before the tick is dropped.
+func (c *Client) SendMove(gameID string, direction constants.Direction) error {
+	name, ok := directionNames[direction]
+	if !ok {
+		return fmt.Errorf("unknown direction %d", direction)
+	}
+	return c.Send(constants.MSG_PLAYER_MOVE, map[string]any{"game_id": gameID, "direction": name})
+}
+
+// SendMoveAfter turns the player's snake on the tick after tick, the Tick of the game update
+// the move answers; the server drops it if that tick was already simulated, unless the
+// latency_compensation feature is on and it is only one tick late
+func (c *Client) SendMoveAfter(gameID string, direction constants.Direction, tick int) error {
+	name, ok := directionNames[direction]
+	if !ok {
+		return fmt.Errorf("unknown direction %d", direction)
+	}
+	return c.Send(constants.MSG_PLAYER_MOVE, map[string]any{"game_id": gameID, "direction": name, "tick": tick})
+}
+
+// StartHotSeat starts a local two-player game steered from this connection; guestName names
+// the second snake, empty for a default
+func (c *Client) StartHotSeat(rules, guestName string) error {
+	return c.Send(constants.MSG_START_HOT_SEAT, map[string]any{"rules": rules, "guest_name": guestName})
+}
+
+// StartCoop starts a couch co-op game: a second snake steered from this connection plays
+// on the player's team; guestName names it, empty for a default
+func (c *Client) StartCoop(rules, guestName string) error {
+	return c.Send(constants.MSG_START_SINGLE_PLAYER, map[string]any{"rules": rules, "coop": true, "guest_name": guestName})
+}
+
+// StartPractice starts a no-stakes game against a bot the server steers, in which crashed
+// snakes respawn; botName picks one of the bundled bots, empty for the default
+func (c *Client) StartPractice(botName string) error {
+	return c.Send(constants.MSG_START_PRACTICE, map[string]any{"bot": botName})
+}
+
+// SendGuestMove turns the guest snake of a hot-seat or co-op game the player started on the
+// next tick
+func (c *Client) SendGuestMove(gameID string, direction constants.Direction) error {
+	name, ok := directionNames[direction]
+	if !ok {
+		return fmt.Errorf("unknown direction %d", direction)
+	}
+	return c.Send(constants.MSG_PLAYER_MOVE, map[string]any{"game_id": gameID, "direction": name, "snake_slot": 2})
+}
+
+// SendEmote sends one of the server's predefined emotes to everyone in a game the player plays in
+func (c *Client) SendEmote(gameID, emoteID string) error {
+	return c.Send(constants.MSG_EMOTE, map[string]any{"game_id": gameID, "emote_id": emoteID})
+}
+
+// SendLoungeChat sends a chat message to everyone in the lounge of a finished game
+func (c *Client) SendLoungeChat(gameID, text string) error {
+	return c.Send(constants.MSG_LOUNGE_CHAT, map[string]any{"game_id": gameID, "text": text})
+}
+
+// LeaveGame leaves a game the player plays in or watches
+func (c *Client) LeaveGame(gameID string) error {
+	return c.Send(constants.MSG_LEAVE_GAME, map[string]any{"game_id": gameID})
+}
+
+// Spectate starts watching a game
+func (c *Client) Spectate(gameID string) error {
+	return c.Send(constants.MSG_JOIN_SPECTATOR, map[string]any{"game_id": gameID})
+}
+
+// InviteSpectator invites an online player, by username, to watch a game the player plays in
+func (c *Client) InviteSpectator(gameID, username string) error {
+	return c.Send(constants.MSG_INVITE_SPECTATOR, map[string]any{"game_id": gameID, "username": username})
+}
+
+// ReportPlayer reports a player to the moderators, in one of moderation.Categories
+// gameID names the game the report is about and may be empty.
+func (c *Client) ReportPlayer(username, category, comment, gameID string) error {
+	return c.Send(constants.MSG_REPORT_PLAYER, map[string]any{
+		"username": username,
+		"category": category,
+		"comment":  comment,
+		"game_id":  gameID,
+	})
+}
+
+// Mute hides lounge chat, emotes and spectator invites from username
+func (c *Client) Mute(username string) error {
+	return c.Send(constants.MSG_MUTE_PLAYER, map[string]any{"username": username})
+}
+
+// Unmute shows messages from a muted username again
+func (c *Client) Unmute(username string) error {
+	return c.Send(constants.MSG_UNMUTE_PLAYER, map[string]any{"username": username})
+}
+
+// RequestRecentOpponents asks for the player's recent opponents, answered through OnRecentOpponents
+func (c *Client) RequestRecentOpponents() error {
+	return c.Send(constants.MSG_GET_RECENT_OPPONENTS, nil)
+}
+
+// InviteToParty invites an online player, by username, to the player's party, creating one
+// led by the player if they have none
+func (c *Client) InviteToParty(username string) error {
+	return c.Send(constants.MSG_PARTY_INVITE, map[string]any{"username": username})
+}
+
+// AcceptPartyInvite joins the party that sent an invite
+func (c *Client) AcceptPartyInvite(partyID string) error {
+	return c.Send(constants.MSG_PARTY_ACCEPT, map[string]any{"party_id": partyID})
+}
+
+// DeclinePartyInvite turns down a party invite
+func (c *Client) DeclinePartyInvite(partyID string) error {
+	return c.Send(constants.MSG_PARTY_DECLINE, map[string]any{"party_id": partyID})
+}
+
+// LeaveParty leaves the player's party
+func (c *Client) LeaveParty() error {
+	return c.Send(constants.MSG_PARTY_LEAVE, nil)
+}
+
+// QueueParty pairs the members of the player's party into games among themselves; only the
+// leader may queue. rules names a rules preset and may be empty.
+func (c *Client) QueueParty(rules string) error {
+	return c.Send(constants.MSG_PARTY_QUEUE, map[string]any{"rules": rules})
+}
+
+// RequestRematch asks the opponent of a finished game for a rematch
+func (c *Client) RequestRematch(gameID string) error {
+	return c.Send(constants.MSG_REMATCH_REQUEST, map[string]any{"game_id": gameID})
+}
+
+// AcceptRematch accepts the opponent's rematch request
+func (c *Client) AcceptRematch(gameID string) error {
+	return c.Send(constants.MSG_REMATCH_ACCEPT, map[string]any{"game_id": gameID})
+}
+
+// ListGames asks for the games list, answered through OnGamesList
+func (c *Client) ListGames() error {
+	return c.Send(constants.MSG_LIST_GAMES, nil)
+}
+
+// ListRules asks for the rules presets, answered through OnRulesList
+func (c *Client) ListRules() error {
+	return c.Send(constants.MSG_LIST_RULES, nil)
+}
+
+// RequestGameState asks for the current state of a game, answered through OnGameUpdate
+func (c *Client) RequestGameState(gameID string) error {
+	return c.Send(constants.MSG_GET_GAME_STATE, map[string]any{"game_id": gameID})
+}
+
+// EquipTitle shows an earned title next to the player's username through update_profile;
+// an empty ID takes the title off. Answered through OnProfile
+func (c *Client) EquipTitle(titleID string) error {
+	return c.Send(constants.MSG_UPDATE_PROFILE, map[string]any{"title": titleID})
+}
+
+// CreateClan founds a clan led by the player; the tag is 2 to 5 letters or digits
+func (c *Client) CreateClan(tag, name string) error {
+	return c.Send(constants.MSG_CLAN_CREATE, map[string]any{"tag": tag, "name": name})
+}
+
+// InviteToClan invites an online player, by username, to the player's clan; only the
+// leader and officers may
+func (c *Client) InviteToClan(username string) error {
+	return c.Send(constants.MSG_CLAN_INVITE, map[string]any{"username": username})
+}
+
+// AcceptClanInvite joins the clan that sent an invite
+func (c *Client) AcceptClanInvite(tag string) error {
+	return c.Send(constants.MSG_CLAN_ACCEPT, map[string]any{"tag": tag})
+}
+
+// DeclineClanInvite turns down a clan invite
+func (c *Client) DeclineClanInvite(tag string) error {
+	return c.Send(constants.MSG_CLAN_DECLINE, map[string]any{"tag": tag})
+}
+
+// LeaveClan leaves the player's clan
+func (c *Client) LeaveClan() error {
+	return c.Send(constants.MSG_CLAN_LEAVE, nil)
+}
+
+// RemoveFromClan takes a member out of the player's clan by player ID
+func (c *Client) RemoveFromClan(playerID string) error {
+	return c.Send(constants.MSG_CLAN_REMOVE, map[string]any{"target_id": playerID})
+}
+
+// SetClanRole makes a member of the player's clan a leader, officer or member; only the
+// leader may, and making someone else leader hands the leadership over
+func (c *Client) SetClanRole(playerID, role string) error {
+	return c.Send(constants.MSG_CLAN_SET_ROLE, map[string]any{"target_id": playerID, "role": role})
+}
+
+// RequestClanScoreboard asks for the clan-vs-clan scoreboard, answered through OnClanScoreboard
+func (c *Client) RequestClanScoreboard() error {
+	return c.Send(constants.MSG_GET_CLAN_SCOREBOARD, nil)
+}
+
+// CreateTournament opens a single-elimination tournament organized by the player; rules names
+// a preset, empty for the default, and a maxPlayers of zero uses the server default
+func (c *Client) CreateTournament(name, rules string, maxPlayers int) error {
+	return c.Send(constants.MSG_TOURNAMENT_CREATE, map[string]any{"name": name, "rules": rules, "max_players": maxPlayers})
+}
+
+// ScheduleTournament opens a tournament that starts on its own at startsAt, after a check-in
+// in the 10 minutes before; repeat is daily or weekly for a recurring tournament, or empty
+func (c *Client) ScheduleTournament(name, rules string, maxPlayers int, startsAt time.Time, repeat string) error {
+	return c.Send(constants.MSG_TOURNAMENT_CREATE, map[string]any{
+		"name":        name,
+		"rules":       rules,
+		"max_players": maxPlayers,
+		"starts_at":   startsAt,
+		"repeat":      repeat,
+	})
+}
+
+// CreateLeague opens a round-robin league in which every entrant plays every other within
+// windowHours of its start; a zero startsAt leaves the start to the organizer, and a zero
+// maxPlayers or windowHours uses the server default
+func (c *Client) CreateLeague(name, rules string, maxPlayers, windowHours int, startsAt time.Time) error {
+	data := map[string]any{
+		"name":         name,
+		"format":       "league",
+		"rules":        rules,
+		"max_players":  maxPlayers,
+		"window_hours": windowHours,
+	}
+	if !startsAt.IsZero() {
+		data["starts_at"] = startsAt
+	}
+	return c.Send(constants.MSG_TOURNAMENT_CREATE, data)
+}
+
+// CreateSwiss opens a Swiss tournament, in which entrants with equal points meet each round;
+// a zero startsAt leaves the start to the organizer
+func (c *Client) CreateSwiss(name, rules string, maxPlayers int, startsAt time.Time) error {
+	data := map[string]any{
+		"name":        name,
+		"format":      "swiss",
+		"rules":       rules,
+		"max_players": maxPlayers,
+	}
+	if !startsAt.IsZero() {
+		data["starts_at"] = startsAt
+	}
+	return c.Send(constants.MSG_TOURNAMENT_CREATE, data)
+}
+
+// CreateTournamentWithCheckIn opens an elimination or Swiss tournament whose players check
+// in to each match within minutes, 1 to 15, before its game is created; those who don't lose
+// the match. A zero startsAt leaves the start to the organizer.
+func (c *Client) CreateTournamentWithCheckIn(name, format, rules string, maxPlayers, minutes int, startsAt time.Time) error {
+	data := map[string]any{
+		"name":           name,
+		"format":         format,
+		"rules":          rules,
+		"max_players":    maxPlayers,
+		"round_check_in": minutes,
+	}
+	if !startsAt.IsZero() {
+		data["starts_at"] = startsAt
+	}
+	return c.Send(constants.MSG_TOURNAMENT_CREATE, data)
+}
+
+// RequestLeagueStandings asks for the standings of a league or Swiss tournament, answered
+// through OnLeagueStandings
+func (c *Client) RequestLeagueStandings(tournamentID string) error {
+	return c.Send(constants.MSG_GET_LEAGUE_STANDINGS, map[string]any{"tournament_id": tournamentID})
+}
+
+// RequestCircuit asks for the tournament circuit standings of a season, a month such as
+// 2026-01, or of the current season when empty; answered through OnCircuitStandings
+func (c *Client) RequestCircuit(season string) error {
+	return c.Send(constants.MSG_GET_CIRCUIT, map[string]any{"season": season})
+}
+
+// WatchTournament streams the live scores of a tournament's games through OnTournamentScores
+// Spectating its featured game moves the player on to the next one when that game ends.
+func (c *Client) WatchTournament(tournamentID string) error {
+	return c.Send(constants.MSG_WATCH_TOURNAMENT, map[string]any{"tournament_id": tournamentID})
+}
+
+// UnwatchTournament stops the live scores of a tournament
+func (c *Client) UnwatchTournament(tournamentID string) error {
+	return c.Send(constants.MSG_UNWATCH_TOURNAMENT, map[string]any{"tournament_id": tournamentID})
+}
+
+// CheckIn confirms the player will play in a tournament whose check-in is open; entrants who
+// didn't check in are dropped when it starts. In a running tournament with round check-in it
+// checks the player in to their next match.
+func (c *Client) CheckIn(tournamentID string) error {
+	return c.Send(constants.MSG_CHECK_IN, map[string]any{"tournament_id": tournamentID})
+}
+
+// RegisterForTournament enters the player into a tournament open for registration
+func (c *Client) RegisterForTournament(tournamentID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_REGISTER, map[string]any{"tournament_id": tournamentID})
+}
+
+// UnregisterFromTournament withdraws the player from a tournament before it starts
+func (c *Client) UnregisterFromTournament(tournamentID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_UNREGISTER, map[string]any{"tournament_id": tournamentID})
+}
+
+// StartTournament draws the bracket and starts the first round; only the organizer may.
+// Match games then arrive through OnGameAccepted.
+func (c *Client) StartTournament(tournamentID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_START, map[string]any{"tournament_id": tournamentID})
+}
+
+// SetTournamentModerator lets an online player run a tournament with the organizer, or takes
+// them off it with remove; only the organizer may
+func (c *Client) SetTournamentModerator(tournamentID, username string, remove bool) error {
+	return c.Send(constants.MSG_TOURNAMENT_MODERATOR, map[string]any{"tournament_id": tournamentID, "username": username, "remove": remove})
+}
+
+// SetTournamentCaster lets an online player cast a tournament's match games, or takes the
+// role off them with remove; only the organizer may
+func (c *Client) SetTournamentCaster(tournamentID, username string, remove bool) error {
+	return c.Send(constants.MSG_TOURNAMENT_CASTER, map[string]any{"tournament_id": tournamentID, "username": username, "remove": remove})
+}
+
+// CastGames streams the messages of tournament match games through OnCastUpdate, alongside
+// the games cast already; only casters, moderators and organizers may
+func (c *Client) CastGames(gameIDs ...string) error {
+	return c.Send(constants.MSG_CAST_GAMES, map[string]any{"game_ids": gameIDs})
+}
+
+// UncastGames stops streaming the given games, or every game cast when none is given
+func (c *Client) UncastGames(gameIDs ...string) error {
+	return c.Send(constants.MSG_UNCAST_GAMES, map[string]any{"game_ids": gameIDs})
+}
+
+// PauseTournament stops or, with paused false, resumes creating a running tournament's
+// match games; only the organizer and moderators may
+func (c *Client) PauseTournament(tournamentID string, paused bool) error {
+	return c.Send(constants.MSG_TOURNAMENT_PAUSE, map[string]any{"tournament_id": tournamentID, "paused": paused})
+}
+
+// RestartTournamentMatch has a disputed match played again from the seed of its latest game;
+// only the organizer and moderators may
+func (c *Client) RestartTournamentMatch(tournamentID, matchID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_RESTART_MATCH, map[string]any{"tournament_id": tournamentID, "match_id": matchID})
+}
+
+// VerifyTournamentMatch has the latest game of a disputed match played again from its seed
+// and inputs and checked against the match's result; only the organizer and moderators may.
+// The verdict arrives as a match_verification message.
+func (c *Client) VerifyTournamentMatch(tournamentID, matchID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_VERIFY_MATCH, map[string]any{"tournament_id": tournamentID, "match_id": matchID})
+}
+
+// DisqualifyFromTournament removes a player from a tournament, losing every match they have
+// left; only the organizer and moderators may
+func (c *Client) DisqualifyFromTournament(tournamentID, playerID string) error {
+	return c.Send(constants.MSG_TOURNAMENT_DISQUALIFY, map[string]any{"tournament_id": tournamentID, "player_id": playerID})
+}
+
+// ExtendTournamentCheckIn moves a scheduled tournament's start back by 1 to 60 minutes; only
+// the organizer and moderators may
+func (c *Client) ExtendTournamentCheckIn(tournamentID string, minutes int) error {
+	return c.Send(constants.MSG_TOURNAMENT_EXTEND_CHECK_IN, map[string]any{"tournament_id": tournamentID, "minutes": minutes})
+}
+
+// ListTournaments asks for every tournament, answered through OnTournamentsList
+func (c *Client) ListTournaments() error {
+	return c.Send(constants.MSG_LIST_TOURNAMENTS, nil)
+}
+
+// RequestTournament asks for a tournament's bracket, answered through OnTournamentUpdate
+func (c *Client) RequestTournament(tournamentID string) error {
+	return c.Send(constants.MSG_GET_TOURNAMENT, map[string]any{"tournament_id": tournamentID})
+}
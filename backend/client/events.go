@@ -0,0 +1,1136 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+)
+
+// ServerInfo identifies the server instance a client is connected to
+type ServerInfo struct {
+	InstanceID string `json:"instance_id"`
+	Region     string `json:"region"`
+}
+
+// ServerError is an error message sent by the server
+type ServerError struct {
+	GameID  string `json:"game_id,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *ServerError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
+
+// PlayerInfo identifies another player
+type PlayerInfo struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// LobbyPlayer is an entry of the lobby status
+type LobbyPlayer struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	Ready      bool   `json:"ready"`
+	InGame     bool   `json:"in_game,omitempty"`
+	Bot        bool   `json:"bot,omitempty"`
+	Title      string `json:"title,omitempty"`       // Name of the player's equipped title
+	Clan       string `json:"clan,omitempty"`        // Tag of the player's clan
+	InstanceID string `json:"instance_id,omitempty"` // Set for players connected to another instance
+}
+
+// GameSummary is an unfinished game as listed in the lobby
+type GameSummary struct {
+	ID         string `json:"id"`
+	Player1    string `json:"player1"`
+	Player2    string `json:"player2,omitempty"` // Empty for single player games
+	Status     string `json:"status"`
+	Spectators int    `json:"spectators"`
+	InstanceID string `json:"instance_id"`
+	Region     string `json:"region"`
+}
+
+// GameRequest is a challenge received from another player, or the confirmation of one sent
+type GameRequest struct {
+	GameID string              `json:"game_id"`
+	From   PlayerInfo          `json:"from_player"` // Set on received challenges
+	To     PlayerInfo          `json:"to_player"`   // Set on sent challenges
+	Rules  engine.RulesProfile `json:"rules"`
+}
+
+// GameStart is sent when a round starts
+type GameStart struct {
+	State  *engine.GameState   `json:"data"`
+	Rules  engine.RulesProfile `json:"rules"`
+	Titles map[string]string   `json:"titles"` // Player ID -> name of their equipped title
+}
+
+// RematchRequest is a rematch asked for by the opponent
+type RematchRequest struct {
+	GameID        string `json:"game_id"`
+	RequesterID   string `json:"requester_id"`
+	RequesterName string `json:"requester_name"`
+}
+
+// Disconnect is sent when the opponent leaves a game
+type Disconnect struct {
+	GameID  string `json:"game_id"`
+	Player  string `json:"player"` // Username of the player who left
+	Message string `json:"message"`
+}
+
+// Kicked is sent when an admin removes this player from the server, just before the
+// connection is closed
+type Kicked struct {
+	Code    string    `json:"code"` // KICKED, or BANNED for a ban
+	Message string    `json:"message"`
+	Reason  string    `json:"reason"` // Given by the admin, may be empty
+	Until   time.Time `json:"until"`  // The player's token is refused until then; zero for a ban, which lasts until lifted
+}
+
+// GameForceEnded is sent to the players and spectators of a game an admin ended; the game is
+// removed right after and its players are back in the lobby
+type GameForceEnded struct {
+	GameID         string `json:"game_id"`
+	Outcome        string `json:"outcome"` // tie, win or void, when nothing was recorded
+	Winner         string `json:"winner"`  // ID of the player awarded the round, empty unless the outcome is win
+	WinnerUsername string `json:"winner_username"`
+	Reason         string `json:"reason"` // Given by the admin, may be empty
+	Code           string `json:"code"`
+	Message        string `json:"message"`
+}
+
+// Reconnecting is sent when the opponent drops out of a game and the server waits for them
+// to come back
+type Reconnecting struct {
+	GameID       string `json:"game_id"`
+	Player       string `json:"player"`        // Username of the player who dropped out
+	GraceSeconds int    `json:"grace_seconds"` // How long the server waits before they lose the round
+}
+
+// RulesList lists the rules presets and single player difficulties the server offers
+type RulesList struct {
+	Default           string                `json:"default"`
+	Rules             []engine.RulesProfile `json:"rules"`
+	DefaultDifficulty string                `json:"default_difficulty"`
+	Difficulties      []engine.Difficulty   `json:"difficulties"`
+	Variants          []string              `json:"variants"` // Single player variants, the default first
+}
+
+// Emote is a predefined quick-chat message the server accepts
+type Emote struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// EmoteEvent is an emote sent by a player of a game this player plays in or watches
+type EmoteEvent struct {
+	GameID   string `json:"game_id"`
+	PlayerID string `json:"player_id"`
+	Username string `json:"username"`
+	EmoteID  string `json:"emote_id"`
+	Text     string `json:"text"`
+}
+
+// MatchStats summarizes a finished round; it follows game_over
+type MatchStats struct {
+	GameID     string             `json:"game_id"`
+	Winner     string             `json:"winner"`
+	Round      int                `json:"round"` // 1 for the first round, one more per rematch
+	DurationMs int64              `json:"duration_ms"`
+	Ticks      int                `json:"ticks"`
+	Difficulty string             `json:"difficulty,omitempty"` // Difficulty of a single player game
+	Variant    string             `json:"variant,omitempty"`    // Variant of a single player game
+	Players    []PlayerMatchStats `json:"players"`
+}
+
+// PlayerMatchStats is one player's part of MatchStats
+type PlayerMatchStats struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+	Length   int    `json:"length"` // Snake length at the end of the round
+	Turns    int    `json:"turns"`  // Turns applied during the round
+	Wins     int    `json:"wins"`   // Rounds won in this game, across rematches
+}
+
+// LoungeChat is a chat message sent in the lounge of a finished game
+type LoungeChat struct {
+	GameID    string    `json:"game_id"`
+	PlayerID  string    `json:"player_id"`
+	Username  string    `json:"username"`
+	Spectator bool      `json:"spectator"`
+	Text      string    `json:"text"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// SpectatorInvite invites this player to watch a game
+type SpectatorInvite struct {
+	GameID       string   `json:"game_id"`
+	FromID       string   `json:"from_id"`
+	FromUsername string   `json:"from_username"`
+	Players      []string `json:"players"` // Usernames of the game's players
+	Link         string   `json:"link"`    // Web client route that watches the game
+}
+
+// ReportReceipt confirms a player report was filed for review
+type ReportReceipt struct {
+	ReportID int64  `json:"report_id"`
+	Username string `json:"username"` // The reported player
+	Category string `json:"category"`
+}
+
+// RecentOpponent is someone the player recently played against, with their current status
+// Challenge an opponent that is InLobby with their PlayerID.
+type RecentOpponent struct {
+	ID         string    `json:"id"` // Player ID the opponent had in the last round
+	Username   string    `json:"username"`
+	LastGameID string    `json:"last_game_id"`
+	LastPlayed time.Time `json:"last_played"`
+	LastResult string    `json:"last_result"` // win, loss or tie, for this player
+	Rounds     int       `json:"rounds"`
+	Online     bool      `json:"online"`
+	InLobby    bool      `json:"in_lobby"`
+	InGame     bool      `json:"in_game"`
+	PlayerID   string    `json:"player_id,omitempty"` // Current player ID while online
+}
+
+// PartyInvite invites this player to join a party
+type PartyInvite struct {
+	PartyID      string   `json:"party_id"`
+	FromID       string   `json:"from_id"`
+	FromUsername string   `json:"from_username"`
+	Members      []string `json:"members"` // Usernames of the party's members
+}
+
+// Party is the current state of the player's party
+type Party struct {
+	PartyID  string       `json:"party_id"`
+	LeaderID string       `json:"leader_id"`
+	Members  []PlayerInfo `json:"members"` // In join order
+	Invited  []PlayerInfo `json:"invited"` // Invited players who haven't answered yet
+}
+
+// PartySpectate tells this player they were added as a spectator of a party member's game
+type PartySpectate struct {
+	PartyID string   `json:"party_id"`
+	GameID  string   `json:"game_id"`
+	Players []string `json:"players"` // Usernames of the game's players
+	Link    string   `json:"link"`    // Web client route that watches the game
+}
+
+// Announcement is a server-wide banner from the operators
+type Announcement struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Severity  string    `json:"severity"` // info, warning or critical
+	SentAt    time.Time `json:"sent_at"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // When to hide the banner; unset when it has no expiry
+}
+
+// Title is a badge a player earns and can show next to their username
+type Title struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Source      string `json:"source"` // achievement, season or tournament
+}
+
+// EarnedTitle is a title this player earned
+type EarnedTitle struct {
+	Title
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+// Profile is this player's equipped title and the titles they can equip
+type Profile struct {
+	Rating       int                `json:"rating"`          // Elo rating from finished multiplayer rounds
+	Title        *Title             `json:"title,omitempty"` // Unset when no title is equipped
+	Titles       []EarnedTitle      `json:"titles"`
+	SinglePlayer *SinglePlayerStats `json:"single_player,omitempty"` // Unset on servers without profiles
+}
+
+// SinglePlayerStats sums up the player's finished single player games, kept apart from
+// their multiplayer rounds
+type SinglePlayerStats struct {
+	GamesPlayed       int     `json:"games_played"`
+	BestScore         int     `json:"best_score"`
+	TotalFood         int     `json:"total_food"`
+	LongestSurvivalMs int64   `json:"longest_survival_ms"`
+	AverageLength     float64 `json:"average_length"` // Mean snake length at the end of a game
+}
+
+// ClanMember is a player in a clan
+type ClanMember struct {
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"` // Username when the player joined
+	Role     string    `json:"role"`     // leader, officer or member
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Clan is the player's clan
+type Clan struct {
+	Tag       string       `json:"tag"`
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	Members   []ClanMember `json:"members"` // Leader first, then by role and join time
+}
+
+// ClanInvite invites this player to join a clan
+type ClanInvite struct {
+	Tag          string `json:"tag"`
+	Name         string `json:"name"`
+	Members      int    `json:"members"`
+	FromID       string `json:"from_id"`
+	FromUsername string `json:"from_username"`
+}
+
+// ClanStanding is a clan's record in rounds against members of other clans
+type ClanStanding struct {
+	Tag     string `json:"tag"`
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+	Rounds  int    `json:"rounds"`
+	Wins    int    `json:"wins"`
+	Losses  int    `json:"losses"`
+	Ties    int    `json:"ties"`
+}
+
+// ClanMatchup is the head-to-head record of two clans
+type ClanMatchup struct {
+	ClanA string `json:"clan_a"` // The tag that sorts first
+	ClanB string `json:"clan_b"`
+	WinsA int    `json:"wins_a"`
+	WinsB int    `json:"wins_b"`
+	Ties  int    `json:"ties"`
+}
+
+// ClanScoreboard is the clan-vs-clan record computed from member rounds
+type ClanScoreboard struct {
+	Standings []ClanStanding `json:"standings"` // Most wins first
+	Matchups  []ClanMatchup  `json:"matchups"`  // Most rounds first
+}
+
+// TournamentEntrant is a player registered for a tournament
+type TournamentEntrant struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Seed     int    `json:"seed,omitempty"`   // Set once the tournament starts; 1 is the top seed
+	Rating   int    `json:"rating,omitempty"` // Rating when registered, which seeds are given by
+	Finish   int    `json:"finish,omitempty"` // Final placing once finished; tied entrants share the best
+
+	CheckedIn    bool `json:"checked_in,omitempty"`   // Confirmed during check-in
+	Disqualified bool `json:"disqualified,omitempty"` // Removed by a moderator; isn't placed
+}
+
+// TournamentStaff is a player the organizer appointed a moderator or caster of a tournament
+type TournamentStaff struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// TournamentMatch is a pairing of a tournament bracket
+type TournamentMatch struct {
+	ID       string             `json:"id"`
+	Round    int                `json:"round"` // 1 for the first round
+	Index    int                `json:"index"` // Position within the round, from the top of the bracket
+	Player1  *TournamentEntrant `json:"player1"`
+	Player2  *TournamentEntrant `json:"player2"`
+	GameID   string             `json:"game_id,omitempty"`   // The game being played for the match
+	Winner   string             `json:"winner,omitempty"`    // Player ID of the winner once decided
+	Result   string             `json:"result,omitempty"`    // played, forfeit, no_show, bye, draw, expired or disqualified
+	Deadline time.Time          `json:"deadline,omitzero"`   // When players who haven't shown up forfeit
+	Seed     int64              `json:"seed,omitempty"`      // Seed of the latest game played for the match
+	Replay   bool               `json:"replay,omitempty"`    // The next game reuses Seed: a moderator restarted the match
+	Scores   map[string]int     `json:"scores,omitempty"`    // Player ID -> score in the game that decided the match
+	CheckIns []string           `json:"check_ins,omitempty"` // Players who checked in to the match, with round check-in
+}
+
+// Tournament is a single-elimination bracket and its registered players
+type Tournament struct {
+	ID            string               `json:"id"`
+	Name          string               `json:"name"`
+	Format        string               `json:"format"` // elimination, league or swiss
+	Rules         string               `json:"rules"`
+	OrganizerID   string               `json:"organizer_id"`
+	OrganizerName string               `json:"organizer_name"`
+	MaxPlayers    int                  `json:"max_players"`
+	Status        string               `json:"status"` // registering, check_in, running, finished or canceled
+	CreatedAt     time.Time            `json:"created_at"`
+	StartedAt     time.Time            `json:"started_at,omitzero"`
+	FinishedAt    time.Time            `json:"finished_at,omitzero"`
+	Entrants      []TournamentEntrant  `json:"entrants"`
+	Rounds        [][]*TournamentMatch `json:"rounds"` // First round first, empty until started; every pairing of a league
+	Champion      *TournamentEntrant   `json:"champion,omitempty"`
+
+	StartsAt time.Time `json:"starts_at,omitzero"` // Scheduled start, zero when the organizer starts it
+	Repeat   string    `json:"repeat,omitempty"`   // daily or weekly for a recurring tournament
+	Series   string    `json:"series,omitempty"`   // ID of the first tournament of a recurring series
+	Revision int       `json:"revision"`           // Incremented on every change
+
+	WindowHours int       `json:"window_hours,omitempty"` // How long a league runs once started
+	EndsAt      time.Time `json:"ends_at,omitzero"`       // When a running league closes
+
+	SwissRounds int `json:"swiss_rounds,omitempty"` // Rounds a Swiss tournament plays; each is drawn once the previous one is decided
+
+	Moderators   []TournamentStaff `json:"moderators,omitempty"`     // Players who run the tournament with the organizer
+	Casters      []TournamentStaff `json:"casters,omitempty"`        // Players who may cast its match games
+	Paused       bool              `json:"paused,omitempty"`         // No match games are created while paused
+	PausedAt     time.Time         `json:"paused_at,omitzero"`       // When the running pause began
+	DelayMinutes int               `json:"delay_minutes,omitempty"`  // How far check-in was extended past the scheduled start
+	RoundCheckIn int               `json:"round_check_in,omitempty"` // Minutes players have to check in to each match
+}
+
+// LeagueStanding is an entrant's record in a league or Swiss tournament
+type LeagueStanding struct {
+	Rank            int               `json:"rank"`
+	Entrant         TournamentEntrant `json:"entrant"`
+	Played          int               `json:"played"`
+	Wins            int               `json:"wins"`
+	Draws           int               `json:"draws"`
+	Losses          int               `json:"losses"`
+	Points          int               `json:"points"`           // 3 per win and 1 per draw
+	SonnebornBerger float64           `json:"sonneborn_berger"` // Breaks ties in points and wins
+}
+
+// LeagueStandings ranks the entrants of a league
+type LeagueStandings struct {
+	TournamentID string           `json:"tournament_id"`
+	Standings    []LeagueStanding `json:"standings"`
+}
+
+// CircuitStanding is a player's record in a season of the tournament circuit
+type CircuitStanding struct {
+	Rank        int    `json:"rank"`
+	PlayerID    string `json:"player_id"`
+	Username    string `json:"username"`
+	Points      int    `json:"points"`
+	Tournaments int    `json:"tournaments"` // Tournaments finished in the season
+	Wins        int    `json:"wins"`        // Tournaments won in the season
+}
+
+// CircuitStandings ranks the players of a circuit season
+type CircuitStandings struct {
+	Season    string            `json:"season"` // A month such as 2026-01
+	Standings []CircuitStanding `json:"standings"`
+}
+
+// CircuitPoints tells a player the circuit points a tournament placing earned them
+type CircuitPoints struct {
+	TournamentID   string `json:"tournament_id"`
+	TournamentName string `json:"tournament_name"`
+	Season         string `json:"season"`
+	Finish         int    `json:"finish"`
+	Points         int    `json:"points"`
+	Total          int    `json:"total"` // The player's points in the season so far
+}
+
+// Campaign lists the levels of the single player campaign and how far the player got
+type Campaign struct {
+	Levels       []engine.CampaignLevel `json:"levels"`
+	Cleared      int                    `json:"cleared"`                 // Highest level cleared; the next one is unlocked
+	LevelCleared int                    `json:"level_cleared,omitempty"` // Level the player just cleared, when sent after a game
+}
+
+// Leaderboard ranks the best scores of a single player variant played at a difficulty
+// The daily run's ranking also names its day and when it resets.
+type Leaderboard struct {
+	Variant    string      `json:"variant"`
+	Difficulty string      `json:"difficulty"`
+	Date       string      `json:"date,omitempty"`
+	ResetsAt   time.Time   `json:"resets_at,omitzero"`
+	Scores     []HighScore `json:"scores"`
+}
+
+// HighScore is a player's best score on a leaderboard
+type HighScore struct {
+	Rank     int       `json:"rank"`
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"`
+	Score    int       `json:"score"`
+	SetAt    time.Time `json:"set_at"`
+}
+
+// SavedGame is a single player game the player suspended and can resume
+type SavedGame struct {
+	SavedAt       time.Time `json:"saved_at"`
+	Rules         string    `json:"rules"`
+	Difficulty    string    `json:"difficulty,omitempty"`
+	Variant       string    `json:"variant,omitempty"`
+	CampaignLevel int       `json:"campaign_level,omitempty"`
+	Score         int       `json:"score"`
+}
+
+// CastUpdate is a message sent to the players and spectators of a game the player casts
+type CastUpdate struct {
+	GameID       string          `json:"game_id"`
+	TournamentID string          `json:"tournament_id"`
+	Event        string          `json:"event"`          // Type of the message, such as game_update or game_over
+	Data         json.RawMessage `json:"data,omitempty"` // The message's data, a game state for game_update and game_over
+
+	Raw json.RawMessage `json:"-"` // The whole message, for the fields of other events
+}
+
+// State decodes the game state of a game_update or game_over
+func (u CastUpdate) State() (*engine.GameState, error) {
+	var state engine.GameState
+	if err := json.Unmarshal(u.Data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Casting lists the games the player casts
+type Casting struct {
+	GameIDs []string `json:"game_ids"`
+}
+
+// TournamentScore is a match player's score in a live tournament game
+type TournamentScore struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+}
+
+// TournamentGame is a live match game of a watched tournament
+type TournamentGame struct {
+	MatchID    string            `json:"match_id"`
+	Round      int               `json:"round"`
+	GameID     string            `json:"game_id"`
+	Status     string            `json:"status"`
+	Players    []TournamentScore `json:"players"`
+	Spectators int               `json:"spectators"`
+}
+
+// TournamentScores lists the live games of a watched tournament in bracket order
+type TournamentScores struct {
+	TournamentID string           `json:"tournament_id"`
+	Games        []TournamentGame `json:"games"`
+	Featured     string           `json:"featured"` // Game ID of the featured match, empty when none is live
+}
+
+// TournamentFeatured announces the new featured game of a watched tournament
+type TournamentFeatured struct {
+	TournamentID string `json:"tournament_id"`
+	MatchID      string `json:"match_id"`
+	GameID       string `json:"game_id"`
+	Spectating   bool   `json:"spectating"` // The player was moved to the game from the featured one that ended
+}
+
+// handlers holds the registered callbacks
+type handlers struct {
+	message          func(msgType string, raw json.RawMessage)
+	lobbyStatus      func([]LobbyPlayer)
+	gamesList        func([]GameSummary)
+	rulesList        func(RulesList)
+	matchFound       func(GameRequest)
+	requestSent      func(GameRequest)
+	gameAccepted     func(gameID string, state *engine.GameState)
+	gameRejected     func(gameID string, by PlayerInfo)
+	gameStart        func(GameStart)
+	gameUpdate       func(*engine.GameState)
+	gameOver         func(*engine.GameState)
+	spectatorUpdate  func(gameID string, state *engine.GameState)
+	rematchRequest   func(RematchRequest)
+	rematchCountdown func(gameID string, seconds int)
+	disconnect       func(Disconnect)
+	reconnecting     func(Reconnecting)
+	reconnected      func(gameID, player string)
+	kicked           func(Kicked)
+	gameForceEnded   func(GameForceEnded)
+	emote            func(EmoteEvent)
+	matchStats       func(MatchStats)
+	loungeChat       func(LoungeChat)
+	loungeClosed     func(gameID string)
+	spectatorInvite  func(SpectatorInvite)
+	reportReceived   func(ReportReceipt)
+	mutedPlayers     func(usernames []string)
+	recentOpponents  func([]RecentOpponent)
+	announcement     func(Announcement)
+	announcementGone func(id string)
+	partyInvite      func(PartyInvite)
+	partyUpdate      func(Party)
+	partyLeft        func(partyID, reason string)
+	partySpectate    func(PartySpectate)
+	profile          func(Profile)
+	titleEarned      func(Title)
+	clanInvite       func(ClanInvite)
+	clanUpdate       func(Clan)
+	clanLeft         func(tag, reason string)
+	clanScoreboard   func(ClanScoreboard)
+	tournament       func(Tournament)
+	tournamentsList  func([]Tournament)
+	leagueStandings  func(LeagueStandings)
+	tournamentScores func(TournamentScores)
+	featured         func(TournamentFeatured)
+	circuit          func(CircuitStandings)
+	circuitPoints    func(CircuitPoints)
+	castUpdate       func(CastUpdate)
+	casting          func(Casting)
+	campaign         func(Campaign)
+	leaderboard      func(Leaderboard)
+	savedGame        func(*SavedGame)
+	singlePlayer     func(SinglePlayerStats)
+	serverError      func(*ServerError)
+}
+
+// register sets a callback under the handlers lock
+func register[F any](c *Client, slot *F, fn F) {
+	c.handlersMu.Lock()
+	*slot = fn
+	c.handlersMu.Unlock()
+}
+
+// OnMessage is called with every server message before its typed callback
+func (c *Client) OnMessage(fn func(msgType string, raw json.RawMessage)) {
+	register(c, &c.handlers.message, fn)
+}
+
+// OnLobbyStatus is called with the lobby's players whenever it changes
+func (c *Client) OnLobbyStatus(fn func([]LobbyPlayer)) { register(c, &c.handlers.lobbyStatus, fn) }
+
+// OnGamesList is called with the unfinished games of every instance
+func (c *Client) OnGamesList(fn func([]GameSummary)) { register(c, &c.handlers.gamesList, fn) }
+
+// OnRulesList is called with the answer to ListRules
+func (c *Client) OnRulesList(fn func(RulesList)) { register(c, &c.handlers.rulesList, fn) }
+
+// OnMatchFound is called when another player challenges this one
+func (c *Client) OnMatchFound(fn func(GameRequest)) { register(c, &c.handlers.matchFound, fn) }
+
+// OnRequestSent is called when a challenge this player sent was delivered
+func (c *Client) OnRequestSent(fn func(GameRequest)) { register(c, &c.handlers.requestSent, fn) }
+
+// OnGameAccepted is called on both players once a challenge is accepted
+func (c *Client) OnGameAccepted(fn func(gameID string, state *engine.GameState)) {
+	register(c, &c.handlers.gameAccepted, fn)
+}
+
+// OnGameRejected is called when the challenged player declines
+func (c *Client) OnGameRejected(fn func(gameID string, by PlayerInfo)) {
+	register(c, &c.handlers.gameRejected, fn)
+}
+
+// OnGameStart is called when a round starts
+func (c *Client) OnGameStart(fn func(GameStart)) { register(c, &c.handlers.gameStart, fn) }
+
+// OnGameUpdate is called with every countdown and playing frame
+func (c *Client) OnGameUpdate(fn func(*engine.GameState)) { register(c, &c.handlers.gameUpdate, fn) }
+
+// OnGameOver is called with the final frame of a round
+func (c *Client) OnGameOver(fn func(*engine.GameState)) { register(c, &c.handlers.gameOver, fn) }
+
+// OnSpectatorUpdate is called with the current state of a game this player started watching
+func (c *Client) OnSpectatorUpdate(fn func(gameID string, state *engine.GameState)) {
+	register(c, &c.handlers.spectatorUpdate, fn)
+}
+
+// OnRematchRequest is called when the opponent asks for a rematch
+func (c *Client) OnRematchRequest(fn func(RematchRequest)) {
+	register(c, &c.handlers.rematchRequest, fn)
+}
+
+// OnRematchCountdown is called with each second left before a rematch starts
+func (c *Client) OnRematchCountdown(fn func(gameID string, seconds int)) {
+	register(c, &c.handlers.rematchCountdown, fn)
+}
+
+// OnDisconnect is called when the opponent leaves the game
+func (c *Client) OnDisconnect(fn func(Disconnect)) { register(c, &c.handlers.disconnect, fn) }
+
+// OnOpponentReconnecting is called when the opponent drops out of a rated game and the
+// server waits for them to reconnect
+func (c *Client) OnOpponentReconnecting(fn func(Reconnecting)) {
+	register(c, &c.handlers.reconnecting, fn)
+}
+
+// OnOpponentReconnected is called when the opponent comes back within the grace period
+func (c *Client) OnOpponentReconnected(fn func(gameID, player string)) {
+	register(c, &c.handlers.reconnected, fn)
+}
+
+// OnKicked is called when an admin removes this player from the server; the connection
+// closes right after
+func (c *Client) OnKicked(fn func(Kicked)) { register(c, &c.handlers.kicked, fn) }
+
+// OnGameForceEnded is called when an admin ends a game this player plays in or watches; a
+// tie or win comes after the game's game_over
+func (c *Client) OnGameForceEnded(fn func(GameForceEnded)) {
+	register(c, &c.handlers.gameForceEnded, fn)
+}
+
+// OnEmote is called with emotes sent in games this player plays in or watches, including
+// the player's own
+func (c *Client) OnEmote(fn func(EmoteEvent)) { register(c, &c.handlers.emote, fn) }
+
+// OnMatchStats is called with the summary of each finished round
+func (c *Client) OnMatchStats(fn func(MatchStats)) { register(c, &c.handlers.matchStats, fn) }
+
+// OnLoungeChat is called with chat messages sent in the lounge of a finished game
+func (c *Client) OnLoungeChat(fn func(LoungeChat)) { register(c, &c.handlers.loungeChat, fn) }
+
+// OnLoungeClosed is called when a finished game's lounge closes; its players are back in the lobby
+func (c *Client) OnLoungeClosed(fn func(gameID string)) {
+	register(c, &c.handlers.loungeClosed, fn)
+}
+
+// OnSpectatorInvite is called when another player invites this one to watch their game;
+// accept with Spectate
+func (c *Client) OnSpectatorInvite(fn func(SpectatorInvite)) {
+	register(c, &c.handlers.spectatorInvite, fn)
+}
+
+// OnReportReceived is called when a report filed with ReportPlayer was stored
+func (c *Client) OnReportReceived(fn func(ReportReceipt)) {
+	register(c, &c.handlers.reportReceived, fn)
+}
+
+// OnMutedPlayers is called with the player's mute list, lowercased, after every change
+func (c *Client) OnMutedPlayers(fn func(usernames []string)) {
+	register(c, &c.handlers.mutedPlayers, fn)
+}
+
+// OnRecentOpponents is called with the answer to RequestRecentOpponents, most recent first
+func (c *Client) OnRecentOpponents(fn func([]RecentOpponent)) {
+	register(c, &c.handlers.recentOpponents, fn)
+}
+
+// OnAnnouncement is called with each announcement; it replaces the one shown before
+func (c *Client) OnAnnouncement(fn func(Announcement)) {
+	register(c, &c.handlers.announcement, fn)
+}
+
+// OnAnnouncementCleared is called when the operators take an announcement down before it expires
+func (c *Client) OnAnnouncementCleared(fn func(id string)) {
+	register(c, &c.handlers.announcementGone, fn)
+}
+
+// OnPartyInvite is called when another player invites this one to their party
+func (c *Client) OnPartyInvite(fn func(PartyInvite)) { register(c, &c.handlers.partyInvite, fn) }
+
+// OnPartyUpdate is called with the player's party whenever its members or invites change
+func (c *Client) OnPartyUpdate(fn func(Party)) { register(c, &c.handlers.partyUpdate, fn) }
+
+// OnPartyLeft is called when the player is no longer in a party; reason is left, or
+// disbanded when everyone else left
+func (c *Client) OnPartyLeft(fn func(partyID, reason string)) { register(c, &c.handlers.partyLeft, fn) }
+
+// OnPartySpectate is called when the player starts watching a party member's game; the
+// spectator updates follow through OnSpectatorUpdate
+func (c *Client) OnPartySpectate(fn func(PartySpectate)) { register(c, &c.handlers.partySpectate, fn) }
+
+// OnProfile is called with the player's profile after every UpdateProfile
+func (c *Client) OnProfile(fn func(Profile)) { register(c, &c.handlers.profile, fn) }
+
+// OnTitleEarned is called when the player earns a title they can then equip
+func (c *Client) OnTitleEarned(fn func(Title)) { register(c, &c.handlers.titleEarned, fn) }
+
+// OnClanInvite is called when an officer of a clan invites this player to join it
+func (c *Client) OnClanInvite(fn func(ClanInvite)) { register(c, &c.handlers.clanInvite, fn) }
+
+// OnClanUpdate is called with the player's clan whenever its members or roles change
+func (c *Client) OnClanUpdate(fn func(Clan)) { register(c, &c.handlers.clanUpdate, fn) }
+
+// OnClanLeft is called when the player is no longer in a clan; reason is left, removed,
+// or disbanded when they were its last member
+func (c *Client) OnClanLeft(fn func(tag, reason string)) { register(c, &c.handlers.clanLeft, fn) }
+
+// OnClanScoreboard is called with the answer to RequestClanScoreboard
+func (c *Client) OnClanScoreboard(fn func(ClanScoreboard)) {
+	register(c, &c.handlers.clanScoreboard, fn)
+}
+
+// OnTournamentUpdate is called whenever a tournament is created or its registrations or
+// bracket change, and with the answer to RequestTournament
+func (c *Client) OnTournamentUpdate(fn func(Tournament)) { register(c, &c.handlers.tournament, fn) }
+
+// OnTournamentsList is called with the answer to ListTournaments, newest first
+func (c *Client) OnTournamentsList(fn func([]Tournament)) {
+	register(c, &c.handlers.tournamentsList, fn)
+}
+
+// OnLeagueStandings is called with the answer to RequestLeagueStandings, for a league or a
+// Swiss tournament
+func (c *Client) OnLeagueStandings(fn func(LeagueStandings)) {
+	register(c, &c.handlers.leagueStandings, fn)
+}
+
+// OnTournamentScores is called with the live games of a tournament after WatchTournament,
+// then whenever a score changes
+func (c *Client) OnTournamentScores(fn func(TournamentScores)) {
+	register(c, &c.handlers.tournamentScores, fn)
+}
+
+// OnTournamentFeatured is called when a watched tournament's featured game changes
+func (c *Client) OnTournamentFeatured(fn func(TournamentFeatured)) {
+	register(c, &c.handlers.featured, fn)
+}
+
+// OnCircuitStandings is called with the answer to RequestCircuit
+func (c *Client) OnCircuitStandings(fn func(CircuitStandings)) { register(c, &c.handlers.circuit, fn) }
+
+// OnCircuitPoints is called when a finished tournament earned the player circuit points
+func (c *Client) OnCircuitPoints(fn func(CircuitPoints)) { register(c, &c.handlers.circuitPoints, fn) }
+
+// OnCastUpdate is called with every message of the games the player casts
+func (c *Client) OnCastUpdate(fn func(CastUpdate)) { register(c, &c.handlers.castUpdate, fn) }
+
+// OnCasting is called with the games the player casts whenever CastGames or UncastGames
+// changes them
+func (c *Client) OnCasting(fn func(Casting)) { register(c, &c.handlers.casting, fn) }
+
+// OnCampaign is called with the answer to RequestCampaign and after a campaign level is cleared
+func (c *Client) OnCampaign(fn func(Campaign)) { register(c, &c.handlers.campaign, fn) }
+
+// OnLeaderboard is called with the answer to RequestLeaderboard and when a finished game set
+// a personal best
+func (c *Client) OnLeaderboard(fn func(Leaderboard)) { register(c, &c.handlers.leaderboard, fn) }
+
+// OnSavedGame is called with the answer to RequestSavedGame and once a game was suspended;
+// the saved game is nil when the player has none
+func (c *Client) OnSavedGame(fn func(*SavedGame)) { register(c, &c.handlers.savedGame, fn) }
+
+// OnSinglePlayerStats is called with the answer to RequestSinglePlayerStats and after each
+// finished single player game
+func (c *Client) OnSinglePlayerStats(fn func(SinglePlayerStats)) {
+	register(c, &c.handlers.singlePlayer, fn)
+}
+
+// OnError is called with error messages from the server
+func (c *Client) OnError(fn func(*ServerError)) { register(c, &c.handlers.serverError, fn) }
+
+// dispatch decodes one server message and calls its callbacks
+// Messages that fail to decode or have no callback are skipped.
+func (c *Client) dispatch(message []byte) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	var msgType string
+	json.Unmarshal(msg["type"], &msgType)
+
+	c.handlersMu.Lock()
+	h := c.handlers
+	c.handlersMu.Unlock()
+
+	if h.message != nil {
+		h.message(msgType, message)
+	}
+
+	switch msgType {
+	case constants.MSG_LOBBY_STATUS:
+		var players []LobbyPlayer
+		if h.lobbyStatus != nil && decode(msg["players"], &players) {
+			h.lobbyStatus(players)
+		}
+	case constants.MSG_GAMES_LIST:
+		var games []GameSummary
+		if h.gamesList != nil && decode(msg["games"], &games) {
+			h.gamesList(games)
+		}
+	case constants.MSG_RULES_LIST:
+		var list RulesList
+		if h.rulesList != nil && json.Unmarshal(message, &list) == nil {
+			h.rulesList(list)
+		}
+	case constants.MSG_MATCH_FOUND:
+		var request GameRequest
+		if h.matchFound != nil && json.Unmarshal(message, &request) == nil {
+			h.matchFound(request)
+		}
+	case constants.MSG_GAME_REQUEST_SENT:
+		var request GameRequest
+		if h.requestSent != nil && json.Unmarshal(message, &request) == nil {
+			h.requestSent(request)
+		}
+	case constants.MSG_GAME_ACCEPT:
+		var accepted struct {
+			GameID string            `json:"game_id"`
+			State  *engine.GameState `json:"data"`
+		}
+		if h.gameAccepted != nil && json.Unmarshal(message, &accepted) == nil {
+			h.gameAccepted(accepted.GameID, accepted.State)
+		}
+	case constants.MSG_GAME_REJECT:
+		var rejected struct {
+			GameID string     `json:"game_id"`
+			By     PlayerInfo `json:"from_player"`
+		}
+		if h.gameRejected != nil && json.Unmarshal(message, &rejected) == nil {
+			h.gameRejected(rejected.GameID, rejected.By)
+		}
+	case constants.MSG_GAME_START:
+		var start GameStart
+		if h.gameStart != nil && json.Unmarshal(message, &start) == nil {
+			h.gameStart(start)
+		}
+	case constants.MSG_GAME_UPDATE:
+		var state engine.GameState
+		if h.gameUpdate != nil && decode(msg["data"], &state) {
+			h.gameUpdate(&state)
+		}
+	case constants.MSG_GAME_OVER:
+		var state engine.GameState
+		if h.gameOver != nil && decode(msg["data"], &state) {
+			h.gameOver(&state)
+		}
+	case constants.MSG_SPECTATOR_UPDATE:
+		var update struct {
+			GameID string            `json:"game_id"`
+			State  *engine.GameState `json:"data"`
+		}
+		if h.spectatorUpdate != nil && json.Unmarshal(message, &update) == nil {
+			h.spectatorUpdate(update.GameID, update.State)
+		}
+	case constants.MSG_REMATCH_REQUEST:
+		var request RematchRequest
+		if h.rematchRequest != nil && json.Unmarshal(message, &request) == nil {
+			h.rematchRequest(request)
+		}
+	case constants.MSG_REMATCH_COUNTDOWN:
+		var countdown struct {
+			GameID    string `json:"game_id"`
+			Countdown int    `json:"countdown"`
+		}
+		if h.rematchCountdown != nil && json.Unmarshal(message, &countdown) == nil {
+			h.rematchCountdown(countdown.GameID, countdown.Countdown)
+		}
+	case constants.MSG_PLAYER_DISCONNECTED:
+		var disconnect Disconnect
+		if h.disconnect != nil && json.Unmarshal(message, &disconnect) == nil {
+			h.disconnect(disconnect)
+		}
+	case constants.MSG_KICKED:
+		var kicked Kicked
+		if h.kicked != nil && json.Unmarshal(message, &kicked) == nil {
+			h.kicked(kicked)
+		}
+	case constants.MSG_GAME_FORCE_ENDED:
+		var ended GameForceEnded
+		if h.gameForceEnded != nil && json.Unmarshal(message, &ended) == nil {
+			h.gameForceEnded(ended)
+		}
+	case constants.MSG_PLAYER_RECONNECTING:
+		var reconnecting Reconnecting
+		if h.reconnecting != nil && json.Unmarshal(message, &reconnecting) == nil {
+			h.reconnecting(reconnecting)
+		}
+	case constants.MSG_PLAYER_RECONNECTED:
+		var reconnected struct {
+			GameID string `json:"game_id"`
+			Player string `json:"player"`
+		}
+		if h.reconnected != nil && json.Unmarshal(message, &reconnected) == nil {
+			h.reconnected(reconnected.GameID, reconnected.Player)
+		}
+	case constants.MSG_EMOTE:
+		var emote EmoteEvent
+		if h.emote != nil && json.Unmarshal(message, &emote) == nil {
+			h.emote(emote)
+		}
+	case constants.MSG_MATCH_STATS:
+		var stats MatchStats
+		if h.matchStats != nil && decode(msg["data"], &stats) {
+			h.matchStats(stats)
+		}
+	case constants.MSG_LOUNGE_CHAT:
+		var chat LoungeChat
+		if h.loungeChat != nil && json.Unmarshal(message, &chat) == nil {
+			h.loungeChat(chat)
+		}
+	case constants.MSG_LOUNGE_CLOSED:
+		var closed struct {
+			GameID string `json:"game_id"`
+		}
+		if h.loungeClosed != nil && json.Unmarshal(message, &closed) == nil {
+			h.loungeClosed(closed.GameID)
+		}
+	case constants.MSG_SPECTATOR_INVITE:
+		var invite SpectatorInvite
+		if h.spectatorInvite != nil && json.Unmarshal(message, &invite) == nil {
+			h.spectatorInvite(invite)
+		}
+	case constants.MSG_REPORT_RECEIVED:
+		var receipt ReportReceipt
+		if h.reportReceived != nil && json.Unmarshal(message, &receipt) == nil {
+			h.reportReceived(receipt)
+		}
+	case constants.MSG_MUTED_PLAYERS:
+		var muted struct {
+			Usernames []string `json:"usernames"`
+		}
+		if h.mutedPlayers != nil && json.Unmarshal(message, &muted) == nil {
+			h.mutedPlayers(muted.Usernames)
+		}
+	case constants.MSG_RECENT_OPPONENTS:
+		var recent struct {
+			Opponents []RecentOpponent `json:"opponents"`
+		}
+		if h.recentOpponents != nil && json.Unmarshal(message, &recent) == nil {
+			h.recentOpponents(recent.Opponents)
+		}
+	case constants.MSG_ANNOUNCEMENT:
+		var announcement Announcement
+		if h.announcement != nil && json.Unmarshal(message, &announcement) == nil {
+			h.announcement(announcement)
+		}
+	case constants.MSG_ANNOUNCEMENT_CLEARED:
+		var cleared struct {
+			ID string `json:"id"`
+		}
+		if h.announcementGone != nil && json.Unmarshal(message, &cleared) == nil {
+			h.announcementGone(cleared.ID)
+		}
+	case constants.MSG_PARTY_INVITE:
+		var invite PartyInvite
+		if h.partyInvite != nil && json.Unmarshal(message, &invite) == nil {
+			h.partyInvite(invite)
+		}
+	case constants.MSG_PARTY_UPDATE:
+		var party Party
+		if h.partyUpdate != nil && json.Unmarshal(message, &party) == nil {
+			h.partyUpdate(party)
+		}
+	case constants.MSG_PARTY_LEFT:
+		var left struct {
+			PartyID string `json:"party_id"`
+			Reason  string `json:"reason"`
+		}
+		if h.partyLeft != nil && json.Unmarshal(message, &left) == nil {
+			h.partyLeft(left.PartyID, left.Reason)
+		}
+	case constants.MSG_PARTY_SPECTATE:
+		var spectate PartySpectate
+		if h.partySpectate != nil && json.Unmarshal(message, &spectate) == nil {
+			h.partySpectate(spectate)
+		}
+	case constants.MSG_PROFILE:
+		var profile Profile
+		if h.profile != nil && json.Unmarshal(message, &profile) == nil {
+			h.profile(profile)
+		}
+	case constants.MSG_TITLE_EARNED:
+		var earned struct {
+			Title Title `json:"title"`
+		}
+		if h.titleEarned != nil && json.Unmarshal(message, &earned) == nil {
+			h.titleEarned(earned.Title)
+		}
+	case constants.MSG_CLAN_INVITE:
+		var invite ClanInvite
+		if h.clanInvite != nil && json.Unmarshal(message, &invite) == nil {
+			h.clanInvite(invite)
+		}
+	case constants.MSG_CLAN_UPDATE:
+		var update struct {
+			Clan Clan `json:"clan"`
+		}
+		if h.clanUpdate != nil && json.Unmarshal(message, &update) == nil {
+			h.clanUpdate(update.Clan)
+		}
+	case constants.MSG_CLAN_LEFT:
+		var left struct {
+			Tag    string `json:"tag"`
+			Reason string `json:"reason"`
+		}
+		if h.clanLeft != nil && json.Unmarshal(message, &left) == nil {
+			h.clanLeft(left.Tag, left.Reason)
+		}
+	case constants.MSG_CLAN_SCOREBOARD:
+		var board ClanScoreboard
+		if h.clanScoreboard != nil && json.Unmarshal(message, &board) == nil {
+			h.clanScoreboard(board)
+		}
+	case constants.MSG_TOURNAMENT_UPDATE:
+		var update struct {
+			Tournament Tournament `json:"tournament"`
+		}
+		if h.tournament != nil && json.Unmarshal(message, &update) == nil {
+			h.tournament(update.Tournament)
+		}
+	case constants.MSG_TOURNAMENTS_LIST:
+		var list struct {
+			Tournaments []Tournament `json:"tournaments"`
+		}
+		if h.tournamentsList != nil && json.Unmarshal(message, &list) == nil {
+			h.tournamentsList(list.Tournaments)
+		}
+	case constants.MSG_LEAGUE_STANDINGS:
+		var standings LeagueStandings
+		if h.leagueStandings != nil && json.Unmarshal(message, &standings) == nil {
+			h.leagueStandings(standings)
+		}
+	case constants.MSG_TOURNAMENT_SCORES:
+		var scores TournamentScores
+		if h.tournamentScores != nil && json.Unmarshal(message, &scores) == nil {
+			h.tournamentScores(scores)
+		}
+	case constants.MSG_TOURNAMENT_FEATURED:
+		var featured TournamentFeatured
+		if h.featured != nil && json.Unmarshal(message, &featured) == nil {
+			h.featured(featured)
+		}
+	case constants.MSG_CIRCUIT_STANDINGS:
+		var standings CircuitStandings
+		if h.circuit != nil && json.Unmarshal(message, &standings) == nil {
+			h.circuit(standings)
+		}
+	case constants.MSG_CIRCUIT_POINTS:
+		var points CircuitPoints
+		if h.circuitPoints != nil && json.Unmarshal(message, &points) == nil {
+			h.circuitPoints(points)
+		}
+	case constants.MSG_CAST_UPDATE:
+		var update CastUpdate
+		if h.castUpdate != nil && json.Unmarshal(message, &update) == nil {
+			update.Raw = message
+			h.castUpdate(update)
+		}
+	case constants.MSG_CASTING:
+		var casting Casting
+		if h.casting != nil && json.Unmarshal(message, &casting) == nil {
+			h.casting(casting)
+		}
+	case constants.MSG_CAMPAIGN:
+		var campaign Campaign
+		if h.campaign != nil && json.Unmarshal(message, &campaign) == nil {
+			h.campaign(campaign)
+		}
+	case constants.MSG_LEADERBOARD:
+		var leaderboard Leaderboard
+		if h.leaderboard != nil && json.Unmarshal(message, &leaderboard) == nil {
+			h.leaderboard(leaderboard)
+		}
+	case constants.MSG_SAVED_GAME:
+		var saved struct {
+			SavedGame *SavedGame `json:"saved_game"`
+		}
+		if h.savedGame != nil && json.Unmarshal(message, &saved) == nil {
+			h.savedGame(saved.SavedGame)
+		}
+	case constants.MSG_SINGLE_PLAYER_STATS:
+		var stats struct {
+			Stats SinglePlayerStats `json:"stats"`
+		}
+		if h.singlePlayer != nil && json.Unmarshal(message, &stats) == nil {
+			h.singlePlayer(stats.Stats)
+		}
+	case constants.MSG_ERROR:
+		var serverError ServerError
+		if h.serverError != nil && json.Unmarshal(message, &serverError) == nil {
+			h.serverError(&serverError)
+		}
+	}
+}
+
+// decode unmarshals a field of a message, reporting whether it was present and valid
+func decode(raw json.RawMessage, v any) bool {
+	return len(raw) > 0 && json.Unmarshal(raw, v) == nil
+}
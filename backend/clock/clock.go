@@ -0,0 +1,44 @@
+// Package clock abstracts time so game flows (countdowns, ticks, expiries) can be driven by
+// a fake clock instead of real sleeps
+package clock
+
+import "time"
+
+// Clock tells the time and creates tickers and timers
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Ticker delivers the time on C every period until stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real returns the clock backed by the time package
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
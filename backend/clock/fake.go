@@ -0,0 +1,141 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when Advance is called
+// Tickers, After and Sleep fire as Advance passes their deadlines, in deadline order
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a pending ticker, After or Sleep
+type fakeWaiter struct {
+	at     time.Time
+	period time.Duration // Zero for one-shot waiters
+	ch     chan time.Time
+}
+
+// NewFake creates a fake clock set to start
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Until returns the fake time left until t
+func (f *Fake) Until(t time.Time) time.Duration {
+	return t.Sub(f.Now())
+}
+
+// NewTicker creates a ticker that fires every d of fake time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{clock: f, waiter: f.wait(d, d)}
+}
+
+// After returns a channel that receives the fake time once d has passed
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.wait(d, 0).ch
+}
+
+// Sleep blocks until another goroutine advances the clock by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// Waiters returns the number of pending tickers, After channels and sleeps
+// Tests use it to wait until the code under test is blocked on the clock
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// TicksDelivered reports whether every ticker has received the ticks fired so far
+// Tests advancing the clock step by step wait for it between steps, so a receiver that is
+// slow to get to its ticker doesn't have a tick dropped.
+func (f *Fake) TicksDelivered() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, waiter := range f.waiters {
+		if waiter.period > 0 && len(waiter.ch) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Advance moves the clock forward by d, firing everything that falls due on the way
+// Like time.Ticker, a ticker whose last tick was not received drops the next one
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+	for {
+		sort.Slice(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+		if len(f.waiters) == 0 || f.waiters[0].at.After(end) {
+			break
+		}
+
+		waiter := f.waiters[0]
+		f.now = waiter.at
+		select {
+		case waiter.ch <- f.now:
+		default:
+		}
+		if waiter.period > 0 {
+			waiter.at = waiter.at.Add(waiter.period)
+			continue
+		}
+		f.waiters = f.waiters[1:]
+	}
+	f.now = end
+}
+
+func (f *Fake) wait(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	waiter := &fakeWaiter{at: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, waiter)
+	return waiter
+}
+
+func (f *Fake) remove(waiter *fakeWaiter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, w := range f.waiters {
+		if w == waiter {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	waiter *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.waiter.ch }
+func (t *fakeTicker) Stop()               { t.clock.remove(t.waiter) }
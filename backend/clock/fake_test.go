@@ -0,0 +1,179 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fired reports whether a channel has a value ready, without waiting
+func fired(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case at := <-ch:
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeNow(t *testing.T) {
+	fake := NewFake(start)
+	fake.Advance(90 * time.Second)
+	if got := fake.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Now = %v, want %v", got, start.Add(90*time.Second))
+	}
+	if got := fake.Since(start); got != 90*time.Second {
+		t.Errorf("Since = %v, want 90s", got)
+	}
+	if got := fake.Until(start.Add(2 * time.Minute)); got != 30*time.Second {
+		t.Errorf("Until = %v, want 30s", got)
+	}
+}
+
+func TestFakeAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance []time.Duration
+		fired   bool
+	}{
+		{"not yet due", []time.Duration{999 * time.Millisecond}, false},
+		{"exactly due", []time.Duration{time.Second}, true},
+		{"due over several advances", []time.Duration{400 * time.Millisecond, 600 * time.Millisecond}, true},
+		{"long past due", []time.Duration{time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFake(start)
+			ch := fake.After(time.Second)
+			for _, d := range tt.advance {
+				fake.Advance(d)
+			}
+			at, ok := fired(ch)
+			if ok != tt.fired {
+				t.Fatalf("fired = %v, want %v", ok, tt.fired)
+			}
+			// The channel receives the deadline, not the time the clock was advanced to
+			if ok && !at.Equal(start.Add(time.Second)) {
+				t.Errorf("fired at %v, want %v", at, start.Add(time.Second))
+			}
+			want := 1
+			if tt.fired {
+				want = 0
+			}
+			if fake.Waiters() != want {
+				t.Errorf("waiters = %d, want %d", fake.Waiters(), want)
+			}
+		})
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	fake := NewFake(start)
+	ticker := fake.NewTicker(100 * time.Millisecond)
+
+	fake.Advance(100 * time.Millisecond)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(start.Add(100*time.Millisecond)) {
+		t.Fatalf("first tick = %v, %v, want %v", at, ok, start.Add(100*time.Millisecond))
+	}
+
+	// Ticks that find the last one unreceived are dropped, like time.Ticker's
+	fake.Advance(350 * time.Millisecond)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(start.Add(200*time.Millisecond)) {
+		t.Errorf("tick after a long advance = %v, %v, want the first one due, %v", at, ok, start.Add(200*time.Millisecond))
+	}
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("dropped ticks were delivered")
+	}
+
+	ticker.Stop()
+	if fake.Waiters() != 0 {
+		t.Errorf("waiters after Stop = %d, want 0", fake.Waiters())
+	}
+	fake.Advance(time.Second)
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("stopped ticker fired")
+	}
+}
+
+func TestFakeTicksDelivered(t *testing.T) {
+	fake := NewFake(start)
+	ticker := fake.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	after := fake.After(100 * time.Millisecond)
+
+	if !fake.TicksDelivered() {
+		t.Error("ticks undelivered before any fired")
+	}
+	fake.Advance(100 * time.Millisecond)
+	if fake.TicksDelivered() {
+		t.Error("ticks delivered before the ticker was read")
+	}
+	fired(ticker.C())
+	// After channels don't drop, so their values may wait
+	if !fake.TicksDelivered() {
+		t.Error("ticks undelivered after the ticker was read")
+	}
+	if _, ok := fired(after); !ok {
+		t.Error("After did not fire")
+	}
+}
+
+func TestFakeFiresInDeadlineOrder(t *testing.T) {
+	fake := NewFake(start)
+	late, early := fake.After(2*time.Second), fake.After(time.Second)
+	ticker := fake.NewTicker(1500 * time.Millisecond)
+	defer ticker.Stop()
+
+	fake.Advance(3 * time.Second)
+	for name, tt := range map[string]struct {
+		ch   <-chan time.Time
+		want time.Duration
+	}{
+		"early": {early, time.Second},
+		"late":  {late, 2 * time.Second},
+		// The ticker's second tick, at 3s, was dropped as the first was not received
+		"ticker": {ticker.C(), 1500 * time.Millisecond},
+	} {
+		if at, ok := fired(tt.ch); !ok || !at.Equal(start.Add(tt.want)) {
+			t.Errorf("%s fired at %v, %v, want %v", name, at, ok, start.Add(tt.want))
+		}
+	}
+	if !fake.Now().Equal(start.Add(3 * time.Second)) {
+		t.Errorf("Now = %v, want %v", fake.Now(), start.Add(3*time.Second))
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	fake := NewFake(start)
+	woke := make(chan struct{})
+	go func() {
+		fake.Sleep(time.Minute)
+		close(woke)
+	}()
+
+	// Wait for the sleep to register before moving the clock past it
+	for fake.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-woke:
+		t.Fatal("Sleep returned before the clock moved")
+	default:
+	}
+	fake.Advance(time.Minute)
+	select {
+	case <-woke:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sleep did not return after the clock moved past it")
+	}
+}
+
+func TestFakeTickerPanicsOnNonPositiveInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTicker(0) did not panic")
+		}
+	}()
+	NewFake(start).NewTicker(0)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	snakebot "github.com/bariiss/snake/backend/bot"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+var directionNames = map[constants.Direction]string{
+	constants.UP:    "up",
+	constants.DOWN:  "down",
+	constants.LEFT:  "left",
+	constants.RIGHT: "right",
+}
+
+// bot is one simulated client
+// All state is owned by the goroutine running the bot; messages are read and answered in order
+type bot struct {
+	url      string
+	username string
+	opponent string // Username to challenge, empty when the bot waits to be challenged
+	single   bool   // Plays single player games instead of matchmaking
+	rules    string
+	strategy snakebot.Bot // Steers the snake in each game
+	stats    *stats
+
+	conn *websocket.Conn
+	id   string
+
+	gameID      string
+	gameRules   models.RulesProfile
+	requested   bool      // A game request or single player start is outstanding
+	requestedAt time.Time // When the outstanding request was sent
+	readyAt     time.Time // When player_ready was sent
+	lastFrame   time.Time // Arrival of the previous game_update while playing
+	lastSent    string    // Direction sent for the current heading, to avoid repeating it
+}
+
+// run connects the bot and plays until the context is cancelled or the connection fails
+func (b *bot) run(ctx context.Context) {
+	endpoint, err := url.Parse(b.url)
+	if err != nil {
+		b.stats.inc("error:dial")
+		return
+	}
+	query := endpoint.Query()
+	query.Set("username", b.username)
+	endpoint.RawQuery = query.Encode()
+
+	dialStart := time.Now()
+	dialer := *websocket.DefaultDialer
+	dialer.Subprotocols = []string{constants.SUBPROTOCOL_JSON_V1}
+	conn, _, err := dialer.DialContext(ctx, endpoint.String(), nil)
+	if err != nil {
+		if ctx.Err() == nil {
+			b.stats.inc("error:dial")
+		}
+		return
+	}
+	b.conn = conn
+	defer conn.Close()
+
+	// Unblock the read loop when the run ends
+	go func() {
+		<-ctx.Done()
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		conn.Close()
+	}()
+
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				b.stats.inc("error:disconnected")
+				if closeErr, ok := err.(*websocket.CloseError); ok {
+					b.stats.inc("close:" + closeErr.Text)
+				}
+			}
+			return
+		}
+
+		// The server batches queued messages into one frame separated by newlines
+		for message := range bytes.SplitSeq(frame, []byte{'\n'}) {
+			if len(message) == 0 {
+				continue
+			}
+			var msg map[string]json.RawMessage
+			if err := json.Unmarshal(message, &msg); err != nil {
+				b.stats.inc("error:decode")
+				continue
+			}
+			if !b.handle(msg, dialStart) {
+				return
+			}
+		}
+	}
+}
+
+// handle reacts to one server message, returning false when the bot should stop
+func (b *bot) handle(msg map[string]json.RawMessage, dialStart time.Time) bool {
+	var msgType string
+	json.Unmarshal(msg["type"], &msgType)
+	b.stats.inc("recv:" + msgType)
+
+	switch msgType {
+	case constants.MSG_CONNECTED:
+		var player struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(msg["player"], &player)
+		b.id = player.ID
+		b.stats.observe("connect", time.Since(dialStart))
+		b.stats.inc("bots_connected")
+		b.send(constants.MSG_JOIN_LOBBY, nil)
+		if b.single {
+			b.startSinglePlayer()
+		}
+
+	case constants.MSG_LOBBY_STATUS:
+		if b.opponent == "" || b.gameID != "" || b.requested {
+			return true
+		}
+		var players []struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+			InGame   bool   `json:"in_game"`
+		}
+		json.Unmarshal(msg["players"], &players)
+		for _, p := range players {
+			if p.Username != b.opponent || p.InGame {
+				continue
+			}
+			b.requested, b.requestedAt = true, time.Now()
+			b.send(constants.MSG_GAME_REQUEST, map[string]any{"target_id": p.ID, "rules": b.rules})
+			break
+		}
+
+	case constants.MSG_MATCH_FOUND:
+		var gameID string
+		json.Unmarshal(msg["game_id"], &gameID)
+		b.decodeRules(msg["rules"])
+		b.send(constants.MSG_GAME_ACCEPT, map[string]any{"game_id": gameID})
+
+	case constants.MSG_GAME_REQUEST_SENT:
+		b.decodeRules(msg["rules"])
+
+	case constants.MSG_GAME_ACCEPT:
+		json.Unmarshal(msg["game_id"], &b.gameID)
+		if b.requested {
+			b.stats.observe("matchmaking", time.Since(b.requestedAt))
+			b.requested = false
+		}
+		b.readyAt = time.Now()
+		b.send(constants.MSG_PLAYER_READY, map[string]any{"game_id": b.gameID})
+
+	case constants.MSG_GAME_START:
+		var state models.GameState
+		json.Unmarshal(msg["data"], &state)
+		b.decodeRules(msg["rules"])
+		b.gameID = state.ID
+		if b.single && b.requested {
+			b.stats.observe("game_start", time.Since(b.requestedAt))
+			b.requested = false
+		} else if !b.readyAt.IsZero() {
+			b.stats.observe("game_start", time.Since(b.readyAt))
+			b.readyAt = time.Time{}
+		}
+		b.lastFrame, b.lastSent = time.Time{}, ""
+		b.stats.inc("games_started")
+
+	case constants.MSG_GAME_UPDATE:
+		var state models.GameState
+		json.Unmarshal(msg["data"], &state)
+		if state.ID != b.gameID || state.Status != "playing" {
+			return true
+		}
+		now := time.Now()
+		if !b.lastFrame.IsZero() {
+			b.stats.observe("frame_interval", now.Sub(b.lastFrame))
+		}
+		b.lastFrame = now
+		b.move(&state)
+
+	case constants.MSG_GAME_OVER:
+		b.stats.inc("games_finished")
+		b.endGame()
+		if b.single {
+			b.startSinglePlayer()
+		}
+
+	case constants.MSG_PLAYER_DISCONNECTED, constants.MSG_GAME_REQUEST_CANCEL, constants.MSG_GAME_REJECT:
+		b.stats.inc("games_abandoned")
+		b.endGame()
+
+	case constants.MSG_ERROR:
+		var code, message string
+		json.Unmarshal(msg["code"], &code)
+		json.Unmarshal(msg["message"], &message)
+		if code == "" {
+			code = message
+		}
+		b.stats.inc("error:server:" + code)
+		// The request failed, let the next lobby update retry it
+		b.requested = false
+		if code == "USERNAME_EXISTS" || code == "SERVER_FULL" {
+			return false
+		}
+	}
+	return true
+}
+
+// endGame forgets the current game; challengers request the next one on the following lobby update
+func (b *bot) endGame() {
+	b.gameID = ""
+	b.requested = false
+	b.readyAt = time.Time{}
+	b.lastFrame = time.Time{}
+}
+
+// startSinglePlayer asks the server for a new single player game
+func (b *bot) startSinglePlayer() {
+	b.requested, b.requestedAt = true, time.Now()
+	b.send(constants.MSG_START_SINGLE_PLAYER, map[string]any{"rules": b.rules})
+}
+
+// decodeRules remembers the rules of the game being set up, which the strategy plays by
+func (b *bot) decodeRules(raw json.RawMessage) {
+	if len(raw) == 0 {
+		return
+	}
+	var rules models.RulesProfile
+	if err := json.Unmarshal(raw, &rules); err == nil {
+		b.gameRules = rules
+	}
+}
+
+// move asks the strategy for a direction and sends it when it turns the snake
+func (b *bot) move(state *models.GameState) {
+	obs := snakebot.Observation{GameID: b.gameID, PlayerID: b.id, State: state, Rules: b.gameRules}
+	snake := obs.Snake()
+	if snake == nil || len(snake.Body) == 0 {
+		return
+	}
+
+	next := b.strategy.Next(obs)
+	name := directionNames[next]
+	if next == snake.Direction || name == b.lastSent {
+		return
+	}
+	b.lastSent = name
+	b.send(constants.MSG_PLAYER_MOVE, map[string]any{"game_id": b.gameID, "direction": name})
+}
+
+// send writes a message to the server
+func (b *bot) send(msgType string, data map[string]any) {
+	msg := map[string]any{"type": msgType}
+	for key, value := range data {
+		msg[key] = value
+	}
+	b.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if err := b.conn.WriteJSON(msg); err != nil {
+		b.stats.inc("error:write")
+		return
+	}
+	b.stats.inc("sent:" + msgType)
+}
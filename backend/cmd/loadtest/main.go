@@ -0,0 +1,108 @@
+// Command loadtest drives a snake server with simulated bot clients
+//
+// Bots connect over WebSocket, join the lobby and pair up: even-numbered bots challenge
+// the next bot, play the game with one of the bundled bot strategies and challenge again
+// once it ends. With an odd bot count the last bot plays single player games. Latency and error
+// statistics are printed periodically and when the run ends.
+//
+//	go run ./cmd/loadtest -url ws://localhost:8080/ws -bots 200 -duration 2m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	snakebot "github.com/bariiss/snake/backend/bot"
+)
+
+func main() {
+	url := flag.String("url", "ws://localhost:8080/ws", "WebSocket endpoint of the server")
+	bots := flag.Int("bots", 10, "number of bot clients")
+	duration := flag.Duration("duration", time.Minute, "how long to run")
+	ramp := flag.Duration("ramp", 5*time.Second, "time over which bots are connected")
+	rules := flag.String("rules", "", "rules preset to request (server default when empty)")
+	strategy := flag.String("strategy", "greedy", "bot each client plays: "+strings.Join(snakebot.BuiltinNames(), ", "))
+	prefix := flag.String("prefix", "", "username prefix (random when empty)")
+	interval := flag.Duration("report", 5*time.Second, "progress report interval, 0 to disable")
+	flag.Parse()
+
+	if *bots < 1 {
+		log.Fatalf("-bots must be at least 1")
+	}
+	newStrategy, exists := snakebot.Builtin[*strategy]
+	if !exists {
+		log.Fatalf("unknown strategy %q, expected one of %s", *strategy, strings.Join(snakebot.BuiltinNames(), ", "))
+	}
+	if *prefix == "" {
+		// Usernames must be unique on the server, so separate runs must not collide
+		*prefix = fmt.Sprintf("bot%04x", time.Now().UnixNano()&0xffff)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stats := newStats()
+	start := time.Now()
+
+	if *interval > 0 {
+		go func() {
+			ticker := time.NewTicker(*interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					stats.progress(os.Stdout, time.Since(start))
+				}
+			}
+		}()
+	}
+
+	log.Printf("Starting %d bots against %s for %s", *bots, *url, *duration)
+
+	var wg sync.WaitGroup
+	step := *ramp / time.Duration(*bots)
+	for i := range *bots {
+		b := &bot{
+			url:      *url,
+			username: botName(*prefix, i),
+			rules:    *rules,
+			strategy: newStrategy(),
+			stats:    stats,
+		}
+		switch {
+		case i%2 == 0 && i+1 < *bots:
+			b.opponent = botName(*prefix, i+1)
+		case i%2 == 0:
+			b.single = true
+		}
+		wg.Go(func() { b.run(ctx) })
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(step):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	wg.Wait()
+
+	stats.report(os.Stdout, time.Since(start))
+}
+
+// botName returns the username of the i-th bot
+func botName(prefix string, i int) string {
+	return fmt.Sprintf("%s-%d", prefix, i)
+}
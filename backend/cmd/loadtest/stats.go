@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats collects latency samples and event counters from all bots
+type stats struct {
+	mu       sync.Mutex
+	samples  map[string][]time.Duration
+	counters map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		samples:  make(map[string][]time.Duration),
+		counters: make(map[string]int),
+	}
+}
+
+// observe records a latency sample
+func (s *stats) observe(name string, d time.Duration) {
+	s.mu.Lock()
+	s.samples[name] = append(s.samples[name], d)
+	s.mu.Unlock()
+}
+
+// inc increments a counter
+func (s *stats) inc(name string) {
+	s.mu.Lock()
+	s.counters[name]++
+	s.mu.Unlock()
+}
+
+// progress prints a one-line summary of the run so far
+func (s *stats) progress(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errors := 0
+	for name, count := range s.counters {
+		if strings.HasPrefix(name, "error:") {
+			errors += count
+		}
+	}
+	fmt.Fprintf(w, "[%6s] connected=%d games=%d/%d frames=%d moves=%d errors=%d\n",
+		elapsed.Round(time.Second), s.counters["bots_connected"],
+		s.counters["games_finished"], s.counters["games_started"],
+		s.counters["recv:game_update"], s.counters["sent:player_move"], errors)
+}
+
+// report prints latency percentiles and all counters
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "\nRun finished after %s\n\n", elapsed.Round(time.Millisecond))
+
+	fmt.Fprintf(w, "%-16s %8s %10s %10s %10s %10s %10s\n", "latency", "count", "min", "p50", "p95", "p99", "max")
+	for _, name := range slices.Sorted(maps.Keys(s.samples)) {
+		samples := s.samples[name]
+		slices.Sort(samples)
+		fmt.Fprintf(w, "%-16s %8d %10s %10s %10s %10s %10s\n", name, len(samples),
+			round(samples[0]), round(percentile(samples, 0.50)), round(percentile(samples, 0.95)),
+			round(percentile(samples, 0.99)), round(samples[len(samples)-1]))
+	}
+
+	fmt.Fprintf(w, "\n%-40s %10s %10s\n", "counter", "total", "per sec")
+	for _, name := range slices.Sorted(maps.Keys(s.counters)) {
+		count := s.counters[name]
+		fmt.Fprintf(w, "%-40s %10d %10.1f\n", name, count, float64(count)/elapsed.Seconds())
+	}
+}
+
+// percentile returns the p-th quantile of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted)-1) * p)
+	return sorted[index]
+}
+
+// round shortens a duration for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
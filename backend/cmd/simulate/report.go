@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// report prints the outcome counts, per-policy statistics and game durations
+func report(w io.Writer, sim simulation, results []result, elapsed time.Duration) {
+	games := len(results)
+	fmt.Fprintf(w, "%d games of %s (%s) in %s, %.0f games/s\n\n",
+		games, sim.rules.Name, sim.layout, elapsed.Round(time.Millisecond), float64(games)/elapsed.Seconds())
+
+	outcomes := make(map[int]int)
+	for _, r := range results {
+		outcomes[r.outcome]++
+	}
+
+	fmt.Fprintf(w, "%-12s %8s %8s %12s %12s\n", "policy", "wins", "win %", "avg length", "avg score")
+	for i, name := range sim.policies {
+		var lengths, scores int
+		for _, r := range results {
+			lengths += r.lengths[i]
+			scores += r.scores[i]
+		}
+		wins := outcomes[i]
+		if len(sim.policies) == 1 {
+			// A lone snake never wins; it either dies or survives until the tick limit
+			wins = outcomes[outcomeTimeout]
+		}
+		fmt.Fprintf(w, "%-12s %8d %7.1f%% %12.1f %12.2f\n", name, wins, percent(wins, games),
+			float64(lengths)/float64(games), float64(scores)/float64(games))
+	}
+	if len(sim.policies) == 1 {
+		fmt.Fprintf(w, "(single player: wins are games survived until -max-ticks)\n")
+	} else {
+		fmt.Fprintf(w, "%-12s %8d %7.1f%%\n", "tie", outcomes[outcomeTie], percent(outcomes[outcomeTie], games))
+		fmt.Fprintf(w, "%-12s %8d %7.1f%%\n", "timeout", outcomes[outcomeTimeout], percent(outcomes[outcomeTimeout], games))
+	}
+
+	ticks := make([]int, 0, games)
+	total := 0
+	for _, r := range results {
+		ticks = append(ticks, r.ticks)
+		total += r.ticks
+	}
+	slices.Sort(ticks)
+	interval := sim.rules.TickInterval()
+	fmt.Fprintf(w, "\n%-12s %10s %10s %10s %10s %10s\n", "duration", "avg", "p50", "p95", "min", "max")
+	fmt.Fprintf(w, "%-12s %10.1f %10d %10d %10d %10d\n", "ticks", float64(total)/float64(games),
+		ticks[games/2], ticks[(games-1)*95/100], ticks[0], ticks[games-1])
+	fmt.Fprintf(w, "%-12s %10s %10s %10s %10s %10s\n", "game time",
+		(time.Duration(total/games) * interval).Round(100*time.Millisecond),
+		time.Duration(ticks[games/2])*interval, time.Duration(ticks[(games-1)*95/100])*interval,
+		time.Duration(ticks[0])*interval, time.Duration(ticks[games-1])*interval)
+}
+
+// percent returns n as a percentage of total
+func percent(n, total int) float64 {
+	return 100 * float64(n) / float64(total)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/bariiss/snake/backend/bot"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// policies are the scripted players; each game gets fresh instances drawing from its seed
+var policies = map[string]func(rnd *rand.Rand) bot.Bot{
+	"greedy":     func(*rand.Rand) bot.Bot { return bot.Greedy{} },
+	"wallhugger": func(*rand.Rand) bot.Bot { return bot.WallHugger{} },
+	"random":     func(rnd *rand.Rand) bot.Bot { return randomPolicy{rnd: rnd} },
+	"straight": func(*rand.Rand) bot.Bot {
+		return bot.Func(func(obs bot.Observation) constants.Direction { return obs.Snake().Direction })
+	},
+}
+
+func policyNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(policies)), ", ")
+}
+
+// randomPolicy turns at random but never into an occupied cell or off a board that doesn't wrap
+type randomPolicy struct {
+	rnd *rand.Rand
+}
+
+func (p randomPolicy) Next(obs bot.Observation) constants.Direction {
+	snake := obs.Snake()
+	occupied := make(map[models.Position]bool)
+	for _, s := range obs.State.Snakes {
+		for _, pos := range s.Body {
+			occupied[pos] = true
+		}
+	}
+
+	var safe []constants.Direction
+	for _, direction := range []constants.Direction{constants.UP, constants.DOWN, constants.LEFT, constants.RIGHT} {
+		if engine.Reverses(snake.Direction, direction) {
+			continue
+		}
+		if head, ok := engine.NextHead(snake.Body[0], direction, obs.Rules.Wrap); ok && !occupied[head] {
+			safe = append(safe, direction)
+		}
+	}
+	if len(safe) == 0 {
+		return snake.Direction
+	}
+	return safe[p.rnd.IntN(len(safe))]
+}
+
+// simulation holds the settings every game is played with
+type simulation struct {
+	rules    models.RulesProfile
+	layout   string
+	length   int
+	policies []string
+	maxTicks int
+}
+
+// Outcomes of a game other than a policy winning
+const (
+	outcomeTie     = -1
+	outcomeTimeout = -2
+	outcomeLost    = -3 // A single player game the snake lost
+)
+
+// result is the outcome of one game
+type result struct {
+	outcome int   // Index of the winning policy, or one of the outcome constants
+	ticks   int   // Ticks played
+	lengths []int // Final length of each policy's snake
+	scores  []int // Final score of each policy's snake
+}
+
+// play runs one game to its end; with swap set the second policy spawns first
+func (s simulation) play(seed int64, swap bool) result {
+	rnd := rand.New(rand.NewPCG(uint64(seed), 0))
+
+	// Snake IDs are policy indexes so outcomes map straight back to policies
+	ids := make([]string, len(s.policies))
+	for i := range ids {
+		ids[i] = string(rune('0' + i))
+	}
+	spawnOrder := slices.Clone(ids)
+	if swap {
+		slices.Reverse(spawnOrder)
+	}
+
+	board := &models.GameState{
+		Status:         "playing",
+		IsSinglePlayer: len(ids) == 1,
+	}
+	state := engine.State{Board: board, Rules: s.rules, Rand: rng.New(seed)}
+	board.Snakes = engine.StartSnakes(state.Rand, s.layout, s.length, spawnOrder...)
+	state.Opening = board.Clone().Snakes
+	engine.OpeningFood(board, s.rules, s.layout, state.Rand)
+
+	players := make(map[string]bot.Bot, len(ids))
+	for i, id := range ids {
+		players[id] = policies[s.policies[i]](rnd)
+	}
+
+	res := result{outcome: outcomeTimeout}
+	for res.ticks < s.maxTicks {
+		inputs := make(engine.Inputs, len(ids))
+		for id, player := range players {
+			inputs[id] = player.Next(bot.Observation{PlayerID: id, State: board, Rules: s.rules})
+		}
+		step := engine.Step(state, inputs)
+		res.ticks++
+		if !step.Over() {
+			continue
+		}
+		switch step.Winner {
+		case "tie":
+			res.outcome = outcomeTie
+		case engine.GameOver:
+			res.outcome = outcomeLost
+		default:
+			res.outcome = int(step.Winner[0] - '0')
+		}
+		break
+	}
+
+	res.lengths = make([]int, len(ids))
+	res.scores = make([]int, len(ids))
+	for _, snake := range board.Snakes {
+		index := int(snake.ID[0] - '0')
+		res.lengths[index] = len(snake.Body)
+		res.scores[index] = snake.Score
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+// Command snake-bot connects one of the bundled bots to a snake server
+//
+// -strategy picks the bot: greedy chases the closest food, wallhugger plays for survival
+// along walls and bodies. The bot shows up in the lobby flagged as a bot and accepts every challenge. With
+// -opponent it challenges that player whenever both are free; with -single it plays single
+// player games back to back.
+//
+//	go run ./cmd/snake-bot -url ws://localhost:8080/ws -username greedy-bot
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/bariiss/snake/backend/bot"
+)
+
+func main() {
+	runner := &bot.Runner{}
+	strategy := flag.String("strategy", "greedy", "bot to run: "+strings.Join(bot.BuiltinNames(), ", "))
+	flag.StringVar(&runner.URL, "url", "ws://localhost:8080/ws", "WebSocket endpoint of the server")
+	flag.StringVar(&runner.Username, "username", "greedy-bot", "username of the bot")
+	flag.StringVar(&runner.Rules, "rules", "", "rules preset for games the bot starts (server default when empty)")
+	flag.StringVar(&runner.Opponent, "opponent", "", "username to challenge whenever both are free")
+	flag.BoolVar(&runner.SinglePlayer, "single", false, "play single player games back to back")
+	flag.Float64Var(&runner.MovesPerSecond, "moves-per-second", 10, "most moves sent per second")
+	flag.Parse()
+
+	newBot, exists := bot.Builtin[*strategy]
+	if !exists {
+		log.Fatalf("unknown strategy %q, expected one of %s", *strategy, strings.Join(bot.BuiltinNames(), ", "))
+	}
+	runner.Bot = newBot()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := runner.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"os"
+)
+
+// key is one key press
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyTab
+	keyEscape
+	keyRune // A printable character, see keyPress.r
+)
+
+// keyPress is a decoded key press
+type keyPress struct {
+	key key
+	r   rune
+}
+
+// readKeys decodes key presses from a terminal in raw mode
+// The channel is closed when the input ends or Ctrl-C is pressed.
+func readKeys(in *os.File) <-chan keyPress {
+	keys := make(chan keyPress)
+	go func() {
+		defer close(keys)
+		reader := bufio.NewReader(in)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			press := decodeKey(b, reader)
+			if press.key == keyRune && press.r == 3 { // Ctrl-C
+				return
+			}
+			if press.key != keyNone {
+				keys <- press
+			}
+		}
+	}()
+	return keys
+}
+
+// decodeKey decodes the key starting with b, reading the rest of an escape sequence
+func decodeKey(b byte, reader *bufio.Reader) keyPress {
+	switch b {
+	case '\r', '\n':
+		return keyPress{key: keyEnter}
+	case '\t':
+		return keyPress{key: keyTab}
+	case 0x1b:
+	default:
+		return keyPress{key: keyRune, r: rune(b)}
+	}
+
+	// A lone escape is the Escape key; arrows arrive as ESC [ A-D or ESC O A-D
+	if reader.Buffered() == 0 {
+		return keyPress{key: keyEscape}
+	}
+	next, _ := reader.ReadByte()
+	if next != '[' && next != 'O' {
+		return keyPress{key: keyEscape}
+	}
+	final, _ := reader.ReadByte()
+	switch final {
+	case 'A':
+		return keyPress{key: keyUp}
+	case 'B':
+		return keyPress{key: keyDown}
+	case 'C':
+		return keyPress{key: keyRight}
+	case 'D':
+		return keyPress{key: keyLeft}
+	}
+	return keyPress{}
+}
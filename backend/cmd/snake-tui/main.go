@@ -0,0 +1,91 @@
+// Command snake-tui plays snake in a terminal, without the web frontend
+//
+// It connects through the client SDK, shows the lobby and the unfinished games, and
+// renders games as colored cells. From the lobby a player can start a single player game,
+// challenge another player or watch a game; arrow keys or WASD steer while playing.
+//
+//	go run ./cmd/snake-tui -url ws://localhost:8080/ws -username alice
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bariiss/snake/backend/client"
+)
+
+func main() {
+	url := flag.String("url", "ws://localhost:8080/ws", "WebSocket endpoint of the server")
+	username := flag.String("username", os.Getenv("USER"), "username to play as")
+	rules := flag.String("rules", "", "rules preset for games you start (server default when empty)")
+	flag.Parse()
+
+	if err := run(*url, *username, *rules); err != nil {
+		fmt.Fprintln(os.Stderr, "snake-tui:", err)
+		os.Exit(1)
+	}
+}
+
+func run(url, username, rules string) error {
+	if username == "" {
+		return errors.New("-username is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	c, err := client.Connect(dialCtx, url, client.Options{Username: username})
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("terminal: %w", err)
+	}
+	defer restore()
+
+	ui := newUI(c, rules, os.Stdout)
+	ui.register()
+	ui.open()
+	defer ui.close()
+
+	ctx, quit := context.WithCancel(ctx)
+	defer quit()
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- c.Run(ctx) }()
+	keys := readKeys(os.Stdin)
+
+	if err := c.JoinLobby(); err != nil {
+		return err
+	}
+	c.ListGames()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-runErr:
+			if err != nil {
+				return fmt.Errorf("connection lost: %w", err)
+			}
+			return nil
+		case k, ok := <-keys:
+			if !ok || !ui.handleKey(k) {
+				return nil
+			}
+		case <-ui.redraw:
+		}
+		ui.render()
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw is not supported on this platform
+func makeRaw(*os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bariiss/snake/backend/client"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Terminal control sequences
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	cursorHide   = "\x1b[?25l"
+	cursorShow   = "\x1b[?25h"
+	clearScreen  = "\x1b[H\x1b[2J"
+	resetColor   = "\x1b[0m"
+	dimColor     = "\x1b[2m"
+	boldColor    = "\x1b[1m"
+	foodColor    = "\x1b[38;2;255;193;7m"
+)
+
+// lobbyFocus is the list the cursor moves in
+type lobbyFocus int
+
+const (
+	focusPlayers lobbyFocus = iota
+	focusGames
+)
+
+// ui holds what the terminal shows
+// SDK callbacks update it on the connection's goroutine and the main loop renders it and
+// handles keys, so everything is guarded by mu.
+type ui struct {
+	c      *client.Client
+	rules  string
+	out    io.Writer
+	redraw chan struct{}
+
+	mu      sync.Mutex
+	players []client.LobbyPlayer // Other players in the lobby
+	games   []client.GameSummary
+	focus   lobbyFocus
+	cursor  [2]int // Selected row of each lobby list
+
+	challenge *client.GameRequest    // Incoming challenge awaiting an answer
+	rematch   *client.RematchRequest // Incoming rematch request
+
+	gameID     string // Game being played or watched, empty in the lobby
+	left       string // Game left last, whose late frames are ignored
+	spectating bool
+	state      *models.GameState
+	status     string // Last notice or error
+}
+
+func newUI(c *client.Client, rules string, out io.Writer) *ui {
+	return &ui{c: c, rules: rules, out: out, redraw: make(chan struct{}, 1)}
+}
+
+// update changes the state under the lock and schedules a redraw
+func (u *ui) update(fn func()) {
+	u.mu.Lock()
+	fn()
+	u.mu.Unlock()
+	select {
+	case u.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// register subscribes to the server messages the ui shows
+func (u *ui) register() {
+	u.c.OnLobbyStatus(func(players []client.LobbyPlayer) {
+		u.update(func() {
+			u.players = u.players[:0]
+			for _, p := range players {
+				if p.ID != u.c.ID() {
+					u.players = append(u.players, p)
+				}
+			}
+			u.cursor[focusPlayers] = min(u.cursor[focusPlayers], max(len(u.players)-1, 0))
+		})
+	})
+	u.c.OnGamesList(func(games []client.GameSummary) {
+		u.update(func() {
+			u.games = games
+			u.cursor[focusGames] = min(u.cursor[focusGames], max(len(u.games)-1, 0))
+		})
+	})
+	u.c.OnMatchFound(func(request client.GameRequest) {
+		u.update(func() { u.challenge = &request })
+	})
+	u.c.OnRequestSent(func(request client.GameRequest) {
+		u.update(func() { u.status = "Challenged " + request.To.Username + ", waiting for an answer" })
+	})
+	u.c.OnGameRejected(func(_ string, by client.PlayerInfo) {
+		u.update(func() { u.status = by.Username + " declined your challenge" })
+	})
+	u.c.OnGameAccepted(func(gameID string, state *models.GameState) {
+		u.update(func() { u.enterGame(gameID, false, state) })
+		u.c.Ready(gameID)
+	})
+	u.c.OnGameStart(func(start client.GameStart) {
+		u.update(func() {
+			// Spectators are sent game_start too
+			watching := u.spectating && u.gameID == start.State.ID
+			u.enterGame(start.State.ID, watching, start.State)
+			u.rematch = nil
+			u.status = ""
+		})
+	})
+	u.c.OnGameUpdate(u.showFrame)
+	u.c.OnGameOver(u.showFrame)
+	u.c.OnSpectatorUpdate(func(gameID string, state *models.GameState) {
+		u.update(func() { u.enterGame(gameID, true, state) })
+	})
+	u.c.OnRematchRequest(func(request client.RematchRequest) {
+		u.update(func() { u.rematch = &request })
+	})
+	u.c.OnRematchCountdown(func(_ string, seconds int) {
+		u.update(func() { u.status = fmt.Sprintf("Rematch in %d", seconds) })
+	})
+	u.c.OnDisconnect(func(d client.Disconnect) {
+		u.update(func() { u.status = d.Message })
+	})
+	u.c.OnError(func(err *client.ServerError) {
+		u.update(func() { u.status = err.Message })
+	})
+}
+
+// enterGame switches to the game view (caller holds mu)
+func (u *ui) enterGame(gameID string, spectating bool, state *models.GameState) {
+	u.gameID, u.spectating = gameID, spectating
+	if state != nil {
+		u.state = state
+	}
+}
+
+// showFrame shows a frame of the current game; a frame arriving in the lobby is the
+// countdown of a single player game just started
+func (u *ui) showFrame(state *models.GameState) {
+	u.update(func() {
+		if u.gameID == "" && state.ID != u.left {
+			u.gameID = state.ID
+		}
+		if state.ID == u.gameID {
+			u.state = state
+		}
+	})
+}
+
+// handleKey reacts to a key press, returning false to quit
+func (u *ui) handleKey(k keyPress) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.gameID != "" {
+		return u.gameKey(k)
+	}
+	return u.lobbyKey(k)
+}
+
+// lobbyKey handles keys in the lobby (caller holds mu)
+func (u *ui) lobbyKey(k keyPress) bool {
+	if u.challenge != nil && k.key == keyRune {
+		switch k.r {
+		case 'y':
+			u.c.Accept(u.challenge.GameID)
+			u.challenge = nil
+			return true
+		case 'n':
+			u.c.Reject(u.challenge.GameID)
+			u.challenge = nil
+			return true
+		}
+	}
+
+	switch k.key {
+	case keyTab:
+		u.focus = 1 - u.focus
+	case keyUp:
+		u.cursor[u.focus] = max(u.cursor[u.focus]-1, 0)
+	case keyDown:
+		u.cursor[u.focus] = min(u.cursor[u.focus]+1, max(u.rows()-1, 0))
+	case keyEnter:
+		u.selectRow()
+	case keyRune:
+		switch k.r {
+		case 's':
+			u.c.StartSinglePlayer(u.rules)
+			u.status = "Starting a single player game"
+		case 'l':
+			u.c.ListGames()
+		case 'q':
+			return false
+		}
+	}
+	return true
+}
+
+// rows returns the length of the focused list (caller holds mu)
+func (u *ui) rows() int {
+	if u.focus == focusGames {
+		return len(u.games)
+	}
+	return len(u.players)
+}
+
+// selectRow challenges the selected player or watches the selected game (caller holds mu)
+func (u *ui) selectRow() {
+	row := u.cursor[u.focus]
+	if row >= u.rows() {
+		return
+	}
+	if u.focus == focusGames {
+		u.c.Spectate(u.games[row].ID)
+		return
+	}
+	player := u.players[row]
+	if player.InGame {
+		u.status = player.Username + " is in a game"
+		return
+	}
+	u.c.Challenge(player.ID, u.rules)
+}
+
+var moveKeys = map[key]constants.Direction{
+	keyUp:    constants.UP,
+	keyDown:  constants.DOWN,
+	keyLeft:  constants.LEFT,
+	keyRight: constants.RIGHT,
+}
+
+var moveRunes = map[rune]constants.Direction{
+	'w': constants.UP,
+	's': constants.DOWN,
+	'a': constants.LEFT,
+	'd': constants.RIGHT,
+}
+
+// gameKey handles keys while playing or watching (caller holds mu)
+func (u *ui) gameKey(k keyPress) bool {
+	direction, isMove := moveKeys[k.key]
+	if k.key == keyRune {
+		direction, isMove = moveRunes[k.r]
+	}
+	if isMove && !u.spectating {
+		u.c.SendMove(u.gameID, direction)
+		return true
+	}
+
+	finished := u.state != nil && u.state.Status == "finished"
+	switch {
+	case k.key == keyEscape, k.key == keyRune && k.r == 'q':
+		u.c.LeaveGame(u.gameID)
+		u.left = u.gameID
+		u.gameID, u.spectating, u.state, u.rematch, u.status = "", false, nil, nil, ""
+		u.c.JoinLobby()
+		u.c.ListGames()
+	case k.key == keyRune && k.r == 'r' && finished && !u.spectating:
+		if u.state.IsSinglePlayer {
+			u.gameID, u.state = "", nil
+			u.c.StartSinglePlayer(u.rules)
+			return true
+		}
+		if u.rematch != nil {
+			u.c.AcceptRematch(u.gameID)
+			return true
+		}
+		u.c.RequestRematch(u.gameID)
+		u.status = "Rematch requested"
+	}
+	return true
+}
+
+// open switches to the alternate screen
+func (u *ui) open() {
+	io.WriteString(u.out, altScreenOn+cursorHide)
+	u.render()
+}
+
+// close restores the normal screen
+func (u *ui) close() {
+	io.WriteString(u.out, resetColor+cursorShow+altScreenOff)
+}
+
+// render redraws the screen; lines end in \r\n because the terminal is in raw mode
+func (u *ui) render() {
+	u.mu.Lock()
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	if u.gameID != "" {
+		u.renderGame(&b)
+	} else {
+		u.renderLobby(&b)
+	}
+	if u.status != "" {
+		fmt.Fprintf(&b, "\r\n%s\r\n", u.status)
+	}
+	u.mu.Unlock()
+
+	io.WriteString(u.out, b.String())
+}
+
+// renderLobby draws the players and games lists (caller holds mu)
+func (u *ui) renderLobby(b *strings.Builder) {
+	fmt.Fprintf(b, "%sSnake%s  %s\r\n\r\n", boldColor, resetColor, u.c.Username())
+
+	u.renderList(b, focusPlayers, "Players", len(u.players), func(i int) string {
+		p := u.players[i]
+		if p.InGame {
+			return p.Username + dimColor + " (in game)" + resetColor
+		}
+		return p.Username
+	})
+	b.WriteString("\r\n")
+	u.renderList(b, focusGames, "Games", len(u.games), func(i int) string {
+		g := u.games[i]
+		players := g.Player1
+		if g.Player2 != "" {
+			players += " vs " + g.Player2
+		}
+		return fmt.Sprintf("%s  %s%s, %d watching%s", players, dimColor, g.Status, g.Spectators, resetColor)
+	})
+
+	if u.challenge != nil {
+		fmt.Fprintf(b, "\r\n%s%s challenges you (%s): y accept, n decline%s\r\n",
+			boldColor, u.challenge.From.Username, u.challenge.Rules.Name, resetColor)
+	}
+	fmt.Fprintf(b, "\r\n%sTab switch list  ↑↓ select  Enter challenge/watch  s single player  l refresh  q quit%s\r\n",
+		dimColor, resetColor)
+}
+
+// renderList draws one lobby list with the cursor on the focused one (caller holds mu)
+func (u *ui) renderList(b *strings.Builder, focus lobbyFocus, title string, n int, row func(int) string) {
+	fmt.Fprintf(b, "%s%s%s\r\n", boldColor, title, resetColor)
+	if n == 0 {
+		fmt.Fprintf(b, "  %snone%s\r\n", dimColor, resetColor)
+		return
+	}
+	for i := range n {
+		marker := "  "
+		if u.focus == focus && u.cursor[focus] == i {
+			marker = "> "
+		}
+		b.WriteString(marker + row(i) + "\r\n")
+	}
+}
+
+// renderGame draws the board, the scores and the game's status (caller holds mu)
+func (u *ui) renderGame(b *strings.Builder) {
+	state := u.state
+	if state == nil {
+		b.WriteString("Waiting for the game to start...\r\n")
+		return
+	}
+
+	// Each cell is two characters wide so the board looks square
+	cells := make([][]string, constants.GRID_HEIGHT)
+	for y := range cells {
+		cells[y] = make([]string, constants.GRID_WIDTH)
+	}
+	for _, food := range state.Foods {
+		if inGrid(food.Position) {
+			cells[food.Position.Y][food.Position.X] = foodColor + "()" + resetColor
+		}
+	}
+	for _, snake := range state.Snakes {
+		color := ansiColor(snake.Color)
+		for i, pos := range snake.Body {
+			if !inGrid(pos) {
+				continue
+			}
+			segment := "██"
+			if i == 0 {
+				segment = "▓▓"
+			}
+			cells[pos.Y][pos.X] = color + segment + resetColor
+		}
+	}
+
+	border := strings.Repeat("──", constants.GRID_WIDTH)
+	b.WriteString("┌" + border + "┐\r\n")
+	for _, row := range cells {
+		b.WriteString("│")
+		for _, cell := range row {
+			if cell == "" {
+				cell = "  "
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("│\r\n")
+	}
+	b.WriteString("└" + border + "┘\r\n")
+
+	for _, snake := range state.Snakes {
+		name := snake.Username
+		if name == "" {
+			name = u.c.Username()
+		}
+		fmt.Fprintf(b, "%s██%s %s %d  ", ansiColor(snake.Color), resetColor, name, snake.Score)
+	}
+	b.WriteString("\r\n")
+
+	switch state.Status {
+	case "countdown":
+		fmt.Fprintf(b, "%sStarting in %d%s\r\n", boldColor, state.Countdown, resetColor)
+	case "finished":
+		fmt.Fprintf(b, "%s%s%s\r\n", boldColor, u.result(state), resetColor)
+	}
+	if u.rematch != nil {
+		fmt.Fprintf(b, "%s wants a rematch\r\n", u.rematch.RequesterName)
+	}
+
+	help := "arrows/WASD steer  q leave"
+	switch {
+	case u.spectating:
+		help = "watching  q leave"
+	case state.Status == "finished":
+		help = "r rematch  q back to lobby"
+	}
+	fmt.Fprintf(b, "%s%s%s\r\n", dimColor, help, resetColor)
+}
+
+// result describes how a finished game ended
+func (u *ui) result(state *models.GameState) string {
+	switch state.Winner {
+	case "", engine.GameOver:
+		return "Game over"
+	case "tie":
+		return "It's a tie"
+	case "disconnect":
+		return "Game ended"
+	case u.c.ID():
+		return "You win!"
+	}
+	for _, snake := range state.Snakes {
+		if snake.ID == state.Winner && snake.Username != "" {
+			return snake.Username + " wins"
+		}
+	}
+	return "You lose"
+}
+
+// inGrid reports whether a position lies on the board
+func inGrid(pos models.Position) bool {
+	return pos.X >= 0 && pos.X < constants.GRID_WIDTH && pos.Y >= 0 && pos.Y < constants.GRID_HEIGHT
+}
+
+// ansiColor returns the 24-bit foreground color sequence for a #rrggbb color
+func ansiColor(hex string) string {
+	if len(hex) != 7 || hex[0] != '#' {
+		return ""
+	}
+	rgb, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16, rgb>>8&0xff, rgb&0xff)
+}
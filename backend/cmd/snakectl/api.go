@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// requestTimeout bounds a single admin API request; the stats stream has no limit
+const requestTimeout = 10 * time.Second
+
+// adminAPI sends authenticated requests to the admin API
+type adminAPI struct {
+	baseURL string
+	token   string
+	actor   string
+	reason  string
+	raw     bool // Print responses as JSON instead of tables
+}
+
+// apiError is a non-2xx response from the server
+type apiError struct {
+	status int
+	body   string
+}
+
+func (e *apiError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("server answered %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("server answered %d: %s", e.status, e.body)
+}
+
+// get requests path and decodes the JSON response into out
+// With -json the response is printed as is and out is left untouched
+func (a *adminAPI) get(ctx context.Context, path string, query url.Values, out any) error {
+	return a.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// post sends a POST to path, with body encoded as JSON unless it is nil, and decodes the
+// JSON response into out
+func (a *adminAPI) post(ctx context.Context, path string, body any, out any) error {
+	return a.do(ctx, http.MethodPost, path, nil, body, out)
+}
+
+// delete sends a DELETE to path and decodes the JSON response into out
+func (a *adminAPI) delete(ctx context.Context, path string, out any) error {
+	return a.do(ctx, http.MethodDelete, path, nil, nil, out)
+}
+
+func (a *adminAPI) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	target := strings.TrimSuffix(a.baseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return err
+	}
+	a.authorize(req.Header)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		apiErr := &apiError{status: resp.StatusCode, body: strings.TrimSpace(string(respBody))}
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			var payload struct {
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(respBody, &payload) == nil && payload.Error != "" {
+				apiErr.body = payload.Error
+			}
+		}
+		return apiErr
+	}
+	if a.raw {
+		return printJSON(json.RawMessage(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// stream opens the WebSocket at path and calls handle with each message until ctx is done
+func (a *adminAPI) stream(ctx context.Context, path string, handle func(data []byte) error) error {
+	base, err := url.Parse(strings.TrimSuffix(a.baseURL, "/") + path)
+	if err != nil {
+		return err
+	}
+	switch base.Scheme {
+	case "https":
+		base.Scheme = "wss"
+	default:
+		base.Scheme = "ws"
+	}
+
+	header := http.Header{}
+	a.authorize(header)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, base.String(), header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			return &apiError{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+		}
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		conn.Close()
+	})
+	defer stop()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if err := handle(data); err != nil {
+			return err
+		}
+	}
+}
+
+// authorize adds the admin token and the audit headers
+func (a *adminAPI) authorize(header http.Header) {
+	header.Set("Authorization", "Bearer "+a.token)
+	if a.actor != "" {
+		header.Set("X-Admin-Actor", a.actor)
+	}
+	if a.reason != "" {
+		header.Set("X-Admin-Reason", a.reason)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bariiss/snake/backend/audit"
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/replay"
+)
+
+// errUsage reports wrong arguments to a command
+var errUsage = errors.New("wrong arguments, see snakectl -h")
+
+// runPlayers lists the registered players
+func runPlayers(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var resp struct {
+		Players []game.PlayerStats `json:"players"`
+	}
+	if err := api.get(ctx, "/api/admin/players", nil, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("USERNAME", "ID", "BOT", "LOBBY", "CONNECTED", "GAMES", "LEFT")
+	for _, p := range resp.Players {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Username, p.ID, yesNo(p.Bot), yesNo(p.InLobby),
+			yesNo(p.Connected), orDash(strings.Join(p.Games, ",")), leftRounds(p))
+	}
+	return w.Flush()
+}
+
+// leftRounds describes the share of rated rounds a player left before they finished, and
+// whether that bars them from rated play
+func leftRounds(p game.PlayerStats) string {
+	left := fmt.Sprintf("%.0f%%", p.DisconnectRate*100)
+	if !p.BarredUntil.IsZero() {
+		left += ", barred until " + p.BarredUntil.Local().Format(time.Kitchen)
+	}
+	return left
+}
+
+// runGames lists the hosted games
+func runGames(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var resp struct {
+		Games []game.GameStats `json:"games"`
+	}
+	if err := api.get(ctx, "/api/admin/games", nil, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("ID", "STATUS", "MODE", "RULES", "PLAYERS", "SPECTATORS", "TICK")
+	for _, g := range resp.Games {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%.2fms\n", g.ID, g.Status, g.Mode, g.Rules,
+			strings.Join(g.Players, " vs "), g.Spectators, g.TickMs)
+	}
+	return w.Flush()
+}
+
+// runGame dumps one game's debug state; it is always printed as JSON
+func runGame(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	var debug json.RawMessage
+	if err := api.get(ctx, "/api/admin/games/"+url.PathEscape(args[0])+"/debug", nil, &debug); err != nil || api.raw {
+		return err
+	}
+	return printJSON(debug)
+}
+
+// runVerify plays a recorded round of a game again and prints how it differs from the report
+func runVerify(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errUsage
+	}
+	query := url.Values{}
+	if len(args) == 2 {
+		query.Set("round", args[1])
+	}
+
+	var verdict replay.Verdict
+	if err := api.get(ctx, "/api/admin/games/"+url.PathEscape(args[0])+"/verify", query, &verdict); err != nil || api.raw {
+		return err
+	}
+	if verdict.Verified {
+		fmt.Printf("Round %d of %s verified: %d ticks, winner %s\n", verdict.Round, verdict.GameID, verdict.Ticks, orDash(verdict.Winner))
+		return nil
+	}
+	fmt.Printf("Round %d of %s diverged from its replay:\n", verdict.Round, verdict.GameID)
+	for _, divergence := range verdict.Divergences {
+		fmt.Printf("  %s\n", divergence)
+	}
+	return nil
+}
+
+// runAudit lists audit entries matching the command's filter flags
+func runAudit(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	action := flags.String("action", "", "only entries with this action")
+	actor := flags.String("actor", "", "only entries by this actor")
+	target := flags.String("target", "", "only entries acting on this target")
+	since := flags.String("since", "", "only entries at or after this RFC 3339 time")
+	limit := flags.Int("limit", 0, "most entries returned (server default when 0)")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+
+	query := url.Values{}
+	for name, value := range map[string]string{"action": *action, "actor": *actor, "target": *target, "since": *since} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	var resp struct {
+		Entries []audit.Entry `json:"entries"`
+	}
+	if err := api.get(ctx, "/api/admin/audit", query, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("TIME", "ACTOR", "ACTION", "TARGET", "OK", "REASON")
+	for _, e := range resp.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Actor, e.Action,
+			orDash(e.Target), yesNo(e.Success), orDash(e.Reason))
+	}
+	return w.Flush()
+}
+
+// runReports lists player reports matching the command's filter flags
+func runReports(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("reports", flag.ContinueOnError)
+	status := flags.String("status", "", "open, resolved, dismissed or all (server default open)")
+	category := flags.String("category", "", "only reports in this category")
+	target := flags.String("target", "", "only reports about this player ID or username")
+	limit := flags.Int("limit", 0, "most reports returned (server default when 0)")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+
+	query := url.Values{}
+	for name, value := range map[string]string{"status": *status, "category": *category, "target": *target} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	var resp struct {
+		Open    int                 `json:"open"`
+		Reports []moderation.Report `json:"reports"`
+	}
+	if err := api.get(ctx, "/api/admin/reports", query, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("ID", "TIME", "STATUS", "CATEGORY", "TARGET", "REPORTER", "GAME", "COMMENT")
+	for _, r := range resp.Reports {
+		gameID := ""
+		if r.Game != nil {
+			gameID = r.Game.GameID
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Time.Local().Format(time.DateTime), r.Status,
+			r.Category, r.TargetName, r.ReporterName, orDash(gameID), orDash(r.Comment))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d open\n", resp.Open)
+	return nil
+}
+
+// runReview closes a report as resolved or dismissed
+func runReview(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errUsage
+	}
+	body := map[string]string{"status": args[1]}
+	if len(args) == 3 {
+		body["note"] = args[2]
+	}
+	var report moderation.Report
+	if err := api.post(ctx, "/api/admin/reports/"+url.PathEscape(args[0])+"/review", body, &report); err != nil || api.raw {
+		return err
+	}
+	fmt.Printf("Report %d about %s %s\n", report.ID, report.TargetName, report.Status)
+	return nil
+}
+
+// runRestrictions lists the players under a shadow restriction
+func runRestrictions(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var resp struct {
+		Restrictions []moderation.Restriction `json:"restrictions"`
+	}
+	if err := api.get(ctx, "/api/admin/restrictions", nil, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("PLAYER", "USERNAME", "SINCE", "BY", "REASON")
+	for _, r := range resp.Restrictions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.PlayerID, orDash(r.Username), r.Time.Local().Format(time.DateTime),
+			r.By, orDash(r.Reason))
+	}
+	return w.Flush()
+}
+
+// runRestrict puts a player under a shadow restriction, or lifts it with -lift
+// The reason given with -reason is recorded with the restriction.
+func runRestrict(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("restrict", flag.ContinueOnError)
+	lift := flags.Bool("lift", false, "lift the player's restriction")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	path := "/api/admin/players/" + url.PathEscape(flags.Arg(0)) + "/restriction"
+	var restriction moderation.Restriction
+	if *lift {
+		if err := api.delete(ctx, path, &restriction); err != nil || api.raw {
+			return err
+		}
+		fmt.Printf("Restriction of %s lifted\n", restriction.PlayerID)
+		return nil
+	}
+	if err := api.post(ctx, path, nil, &restriction); err != nil || api.raw {
+		return err
+	}
+	fmt.Printf("%s restricted\n", restriction.PlayerID)
+	return nil
+}
+
+// runLinks lists the accounts sharing a device or address with a player, or with -tournament
+// the linked pairs of a tournament's entrants
+func runLinks(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("links", flag.ContinueOnError)
+	tournamentID := flags.String("tournament", "", "list the linked entrants of this tournament")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if *tournamentID != "" {
+		if flags.NArg() != 0 {
+			return errUsage
+		}
+		var resp struct {
+			Links []game.EntrantLink `json:"links"`
+		}
+		if err := api.get(ctx, "/api/admin/tournaments/"+url.PathEscape(*tournamentID)+"/links", nil, &resp); err != nil || api.raw {
+			return err
+		}
+		w := table("PLAYER", "USERNAME", "LINKED", "LINKED USERNAME", "SHARED")
+		for _, l := range resp.Links {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.PlayerID, l.Username, l.LinkedID, l.LinkedUsername, strings.Join(l.Signals, ","))
+		}
+		return w.Flush()
+	}
+
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	var resp struct {
+		Links []moderation.Link `json:"links"`
+	}
+	if err := api.get(ctx, "/api/admin/players/"+url.PathEscape(flags.Arg(0))+"/links", nil, &resp); err != nil || api.raw {
+		return err
+	}
+	w := table("PLAYER", "USERNAME", "SHARED", "SINCE")
+	for _, l := range resp.Links {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", l.PlayerID, orDash(l.Username), strings.Join(l.Signals, ","), l.Since.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// runEnd ends a game right away with the outcome given, telling its players the -reason
+func runEnd(ctx context.Context, api *adminAPI, args []string) error {
+	body := map[string]any{}
+	switch {
+	case len(args) == 2 && (args[1] == game.OutcomeTie || args[1] == game.OutcomeVoid):
+		body["outcome"] = args[1]
+	case len(args) == 3 && args[1] == game.OutcomeWin:
+		body["outcome"], body["winner"] = args[1], args[2]
+	default:
+		return errUsage
+	}
+
+	var end game.ForcedEnd
+	if err := api.post(ctx, "/api/admin/games/"+url.PathEscape(args[0])+"/end", body, &end); err != nil || api.raw {
+		return err
+	}
+	switch end.Outcome {
+	case game.OutcomeWin:
+		fmt.Printf("Game %s ended, won by %s\n", end.GameID, end.WinnerUsername)
+	case game.OutcomeTie:
+		fmt.Printf("Game %s ended in a tie\n", end.GameID)
+	default:
+		fmt.Printf("Game %s voided\n", end.GameID)
+	}
+	return nil
+}
+
+// runKick disconnects a player with the -reason given and keeps them out for the cooldown
+func runKick(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("kick", flag.ContinueOnError)
+	cooldown := flags.Duration("cooldown", 0, "how long the player can't reconnect, in whole minutes (server default 5m)")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	body := map[string]any{"cooldown_minutes": int(cooldown.Minutes())}
+	var kick game.Kick
+	if err := api.post(ctx, "/api/admin/players/"+url.PathEscape(flags.Arg(0))+"/kick", body, &kick); err != nil || api.raw {
+		return err
+	}
+	fmt.Printf("%s kicked, can reconnect at %s\n", kick.Username, kick.Until.Local().Format(time.DateTime))
+	return nil
+}
+
+// runBans lists the banned players
+func runBans(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var resp struct {
+		Bans []moderation.Ban `json:"bans"`
+	}
+	if err := api.get(ctx, "/api/admin/bans", nil, &resp); err != nil || api.raw {
+		return err
+	}
+
+	w := table("PLAYER", "USERNAME", "SINCE", "BY", "REASON")
+	for _, b := range resp.Bans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.PlayerID, orDash(b.Username), b.Time.Local().Format(time.DateTime),
+			b.By, orDash(b.Reason))
+	}
+	return w.Flush()
+}
+
+// runBan bans a player until the ban is lifted, or lifts it with -lift
+// The reason given with -reason is shown to the player and recorded with the ban.
+func runBan(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("ban", flag.ContinueOnError)
+	lift := flags.Bool("lift", false, "lift the player's ban")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	path := "/api/admin/players/" + url.PathEscape(flags.Arg(0)) + "/ban"
+	var ban moderation.Ban
+	if *lift {
+		if err := api.delete(ctx, path, &ban); err != nil || api.raw {
+			return err
+		}
+		fmt.Printf("Ban of %s lifted\n", ban.PlayerID)
+		return nil
+	}
+	if err := api.post(ctx, path, nil, &ban); err != nil || api.raw {
+		return err
+	}
+	fmt.Printf("%s banned\n", ban.PlayerID)
+	return nil
+}
+
+// runAnnounce shows an announcement to every connected player, or clears it
+func runAnnounce(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("announce", flag.ContinueOnError)
+	severity := flags.String("severity", "", "info, warning or critical (server default info)")
+	expires := flags.Duration("expires", 0, "hide the banner after this long, 0 to keep it until cleared")
+	remove := flags.Bool("clear", false, "take down the current announcement")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var announcement game.Announcement
+	if *remove {
+		if flags.NArg() != 0 {
+			return errUsage
+		}
+		if err := api.delete(ctx, "/api/admin/announcement", &announcement); err != nil || api.raw {
+			return err
+		}
+		fmt.Printf("Announcement %s cleared\n", announcement.ID)
+		return nil
+	}
+
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	body := map[string]any{
+		"text":       flags.Arg(0),
+		"severity":   *severity,
+		"expires_in": int(expires.Seconds()),
+	}
+	if err := api.post(ctx, "/api/admin/announcement", body, &announcement); err != nil || api.raw {
+		return err
+	}
+	fmt.Printf("Announcement %s sent (%s)", announcement.ID, announcement.Severity)
+	if !announcement.ExpiresAt.IsZero() {
+		fmt.Printf(", shown until %s", announcement.ExpiresAt.Local().Format(time.DateTime))
+	}
+	fmt.Println()
+	return nil
+}
+
+// runMaintenance shows the maintenance status, or starts or ends maintenance
+func runMaintenance(ctx context.Context, api *adminAPI, args []string) error {
+	flags := flag.NewFlagSet("maintenance", flag.ContinueOnError)
+	on := flags.Bool("on", false, "stop new games and lobby joins, letting games playing finish")
+	off := flags.Bool("off", false, "take new games and players again")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var status game.MaintenanceStatus
+	var err error
+	switch {
+	case *on && !*off && flags.NArg() <= 1:
+		err = api.post(ctx, "/api/admin/maintenance", map[string]any{"message": flags.Arg(0)}, &status)
+	case *off && !*on && flags.NArg() == 0:
+		err = api.delete(ctx, "/api/admin/maintenance", &status)
+	case !*on && !*off && flags.NArg() == 0:
+		err = api.get(ctx, "/api/admin/maintenance", nil, &status)
+	default:
+		return errUsage
+	}
+	if err != nil || api.raw {
+		return err
+	}
+
+	switch {
+	case !status.Enabled:
+		fmt.Printf("Not in maintenance, %d games playing\n", status.ActiveGames)
+	case status.SafeToRestart:
+		fmt.Printf("In maintenance since %s, no games left: safe to restart\n", status.Maintenance.Since.Local().Format(time.DateTime))
+	default:
+		fmt.Printf("In maintenance since %s, waiting for %d games: %s\n", status.Maintenance.Since.Local().Format(time.DateTime),
+			status.ActiveGames, strings.Join(status.ActiveGameIDs, ", "))
+	}
+	return nil
+}
+
+// runTune shows the tunable limits, or adjusts the ones given as section.setting=value
+func runTune(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) == 0 {
+		var tuning map[string]map[string]any
+		if err := api.get(ctx, "/api/admin/tuning", nil, &tuning); err != nil || api.raw {
+			return err
+		}
+		w := table("SETTING", "VALUE")
+		for _, section := range slices.Sorted(maps.Keys(tuning)) {
+			for _, name := range slices.Sorted(maps.Keys(tuning[section])) {
+				fmt.Fprintf(w, "%s.%s\t%v\n", section, name, tuning[section][name])
+			}
+		}
+		return w.Flush()
+	}
+
+	patch := map[string]map[string]any{}
+	for _, arg := range args {
+		setting, raw, found := strings.Cut(arg, "=")
+		section, name, dotted := strings.Cut(setting, ".")
+		if !found || !dotted {
+			return errUsage
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return fmt.Errorf("%s: %q is not a number or boolean", setting, raw)
+		}
+		if patch[section] == nil {
+			patch[section] = map[string]any{}
+		}
+		patch[section][name] = value
+	}
+
+	var resp struct {
+		Changes []config.TuningChange `json:"changes"`
+	}
+	if err := api.post(ctx, "/api/admin/tuning", patch, &resp); err != nil || api.raw {
+		return err
+	}
+	if len(resp.Changes) == 0 {
+		fmt.Println("No changes")
+	}
+	for _, change := range resp.Changes {
+		fmt.Printf("%s: %v -> %v\n", change.Setting, change.From, change.To)
+	}
+	return nil
+}
+
+// runReload asks the server to re-read its configuration
+func runReload(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var resp struct {
+		Ignored []string `json:"ignored"`
+	}
+	if err := api.post(ctx, "/api/admin/config/reload", nil, &resp); err != nil || api.raw {
+		return err
+	}
+
+	fmt.Println("Configuration reloaded")
+	if len(resp.Ignored) > 0 {
+		fmt.Printf("Changes that need a restart: %s\n", strings.Join(resp.Ignored, ", "))
+	}
+	return nil
+}
+
+// runTail prints a line per stats snapshot until interrupted
+func runTail(ctx context.Context, api *adminAPI, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	header := true
+	return api.stream(ctx, "/api/admin/stats/ws", func(data []byte) error {
+		if api.raw {
+			fmt.Println(string(data))
+			return nil
+		}
+		var msg struct {
+			Type string           `json:"type"`
+			Data game.ServerStats `json:"data"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("decoding stats: %w", err)
+		}
+		if msg.Type != "server_stats" {
+			return nil
+		}
+
+		if header {
+			fmt.Printf("%-8s %6s %7s %5s %5s %6s %5s %7s %6s %9s\n",
+				"TIME", "CONNS", "PLAYERS", "LOBBY", "GAMES", "ACTIVE", "QUEUE", "PENDING", "STALE", "SLOW_DISC")
+			header = false
+		}
+		s := msg.Data
+		fmt.Printf("%-8s %6d %7d %5d %5d %6d %5d %7d %6d %9d\n",
+			s.Time.Local().Format(time.TimeOnly), s.Connections, s.Players, s.LobbyPlayers, s.Games,
+			s.ActiveGames, s.QueueDepth, s.PendingRequests, s.StaleFramesDropped, s.SlowConsumerDisconnects)
+		return nil
+	})
+}
+
+// table returns a tabwriter on stdout with the column headers written
+func table(columns ...string) *tabwriter.Writer {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	return w
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+// Command snakectl is a command line client for the admin API
+//
+// It lists players and games, dumps a game's internal state, verifies recorded rounds,
+// queries the audit log, works through the player report queue, manages shadow
+// restrictions, looks up linked accounts, ends games, kicks and bans players, broadcasts
+// announcements, drains the server for maintenance, tunes live limits, reloads the
+// configuration and tails the live stats stream. The admin token is read from -token or
+// SNAKE_ADMIN_TOKEN. Actions are recorded in the audit log under -actor, which defaults to
+// the local user name, with the -reason given.
+//
+//	SNAKE_ADMIN_TOKEN=... go run ./cmd/snakectl -url http://localhost:8080 players
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command is one snakectl subcommand
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, api *adminAPI, args []string) error
+}
+
+var commands = map[string]command{
+	"players":      {"players", "list the players registered on the server", runPlayers},
+	"games":        {"games", "list the games hosted by the server", runGames},
+	"game":         {"game <id>", "dump the internal state of a game", runGame},
+	"verify":       {"verify <game-id> [round]", "play a recorded round again and check it ends as the server reported", runVerify},
+	"audit":        {"audit [-action a] [-actor a] [-target t] [-since RFC3339] [-limit n]", "list recorded admin actions", runAudit},
+	"reports":      {"reports [-status s] [-category c] [-target t] [-limit n]", "list player reports, open ones by default", runReports},
+	"review":       {"review <id> resolved|dismissed [note]", "close a player report", runReview},
+	"restrictions": {"restrictions", "list the players under a shadow restriction", runRestrictions},
+	"restrict":     {"restrict [-lift] <player-id>", "hide a player from the lobby and keep them out of matchmaking, or lift it", runRestrict},
+	"links":        {"links <player-id> | links -tournament <id>", "list the accounts sharing a device or address with a player, or linked entrants of a tournament", runLinks},
+	"end":          {"end <game-id> tie | void | win <player>", "end a game right away, recording a tie or a win or nothing at all", runEnd},
+	"kick":         {"kick [-cooldown d] <player-id>", "disconnect a player and keep them from reconnecting for a while", runKick},
+	"bans":         {"bans", "list the banned players", runBans},
+	"ban":          {"ban [-lift] <player-id>", "disconnect a player and keep them off the server until lifted, or lift it", runBan},
+	"announce":     {"announce [-severity s] [-expires d] <text> | announce -clear", "show a banner to every connected player, or take it down", runAnnounce},
+	"maintenance":  {"maintenance [-on [message] | -off]", "show whether the server drains for maintenance and its games left, or start or end it", runMaintenance},
+	"tune":         {"tune [section.setting=value ...]", "show the limits that can be tuned at runtime, or adjust them until the next reload", runTune},
+	"reload":       {"reload", "re-read the configuration and apply reloadable settings", runReload},
+	"tail":         {"tail", "print the live stats stream until interrupted", runTail},
+}
+
+// commandOrder is the order commands are listed in the usage
+var commandOrder = []string{"players", "games", "game", "verify", "audit", "reports", "review", "restrictions", "restrict", "links", "end", "kick", "bans", "ban", "announce", "maintenance", "tune", "reload", "tail"}
+
+func main() {
+	api := &adminAPI{}
+	flag.StringVar(&api.baseURL, "url", envOr("SNAKE_URL", "http://localhost:8080"), "base URL of the server (env SNAKE_URL)")
+	flag.StringVar(&api.token, "token", os.Getenv("SNAKE_ADMIN_TOKEN"), "admin token (env SNAKE_ADMIN_TOKEN)")
+	flag.StringVar(&api.actor, "actor", os.Getenv("USER"), "name recorded as the actor in the audit log")
+	flag.StringVar(&api.reason, "reason", "", "reason recorded with the action in the audit log")
+	flag.BoolVar(&api.raw, "json", false, "print the raw JSON responses")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, exists := commands[flag.Arg(0)]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "snakectl: unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if api.token == "" {
+		fmt.Fprintln(os.Stderr, "snakectl: no admin token, set -token or SNAKE_ADMIN_TOKEN")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, api, flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "snakectl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: snakectl [flags] <command> [args]\n\nCommands:\n")
+	for _, name := range commandOrder {
+		cmd := commands[name]
+		fmt.Fprintf(out, "  %s\n    \t%s\n", cmd.usage, cmd.help)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// envOr returns the environment variable, or fallback when it is unset
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
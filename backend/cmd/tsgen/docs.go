@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// docDirs are the packages whose doc comments are copied to the generated types
+var docDirs = []string{"engine", "models", "client", "game", "constants"}
+
+// docs maps "pkgpath.Type" and "pkgpath.Type.Field" to their doc comments
+type docs map[string]string
+
+// loadDocs reads the type and field comments of docDirs under the module root
+func loadDocs(root string) (docs, error) {
+	module, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := docs{}
+	fset := token.NewFileSet()
+	for _, dir := range docDirs {
+		files, err := filepath.Glob(filepath.Join(root, dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		pkgPath := module + "/" + dir
+		for _, path := range files {
+			file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					key := pkgPath + "." + typeSpec.Name.Name
+					doc := typeSpec.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					result.add(key, doc)
+
+					structType, ok := typeSpec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					for _, field := range structType.Fields.List {
+						comment := field.Doc
+						if comment == nil {
+							comment = field.Comment
+						}
+						for _, name := range field.Names {
+							result.add(key+"."+name.Name, comment)
+						}
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (d docs) add(key string, group *ast.CommentGroup) {
+	if text := strings.TrimSpace(group.Text()); text != "" {
+		d[key] = text
+	}
+}
+
+// modulePath reads the module path from a go.mod file
+func modulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if module, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); found {
+			return strings.TrimSpace(module), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no module line", goMod)
+}
+
+// messageTypeNames returns the values of the MSG_ constants in declaration order
+func messageTypeNames(path string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				if !strings.HasPrefix(name.Name, "MSG_") || i >= len(valueSpec.Values) {
+					continue
+				}
+				lit, ok := valueSpec.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				value, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return nil, err
+				}
+				names = append(names, value)
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// generator writes TypeScript declarations for Go types
+type generator struct {
+	buf     bytes.Buffer
+	docs    docs
+	emitted map[string]reflect.Type // TypeScript name -> Go type written under it
+	queue   []reflect.Type          // Named types referenced but not written yet
+}
+
+func newGenerator(d docs) *generator {
+	return &generator{docs: d, emitted: make(map[string]reflect.Type)}
+}
+
+func (g *generator) header() {
+	g.buf.WriteString("// Code generated by cmd/tsgen from the backend's Go structs; DO NOT EDIT.\n")
+	g.buf.WriteString("// Regenerate with `go generate .` in backend/.\n")
+}
+
+func (g *generator) section(title string) {
+	fmt.Fprintf(&g.buf, "\n// %s\n", title)
+}
+
+// messageTypes writes the union of all message type names
+func (g *generator) messageTypes(names []string) {
+	g.buf.WriteString("\n/** Every message type of the protocol, in either direction */\n")
+	g.buf.WriteString("export type MessageType =\n")
+	for i, name := range names {
+		end := ""
+		if i == len(names)-1 {
+			end = ";"
+		}
+		fmt.Fprintf(&g.buf, "  | '%s'%s\n", name, end)
+	}
+}
+
+// named writes a named Go type as an interface or type alias, once
+func (g *generator) named(t reflect.Type) {
+	name := t.Name()
+	if previous, exists := g.emitted[name]; exists {
+		if previous != t {
+			log.Fatalf("%s and %s both map to the TypeScript type %s", previous.PkgPath(), t.PkgPath(), name)
+		}
+		return
+	}
+	g.emitted[name] = t
+	key := t.PkgPath() + "." + name
+
+	g.buf.WriteString("\n")
+	g.comment("", g.docs[key])
+	if t.Kind() != reflect.Struct {
+		fmt.Fprintf(&g.buf, "export type %s = %s;\n", name, basicType(t.Kind()))
+		return
+	}
+	fmt.Fprintf(&g.buf, "export interface %s {\n", name)
+	g.fields(t, key, false)
+	g.buf.WriteString("}\n")
+}
+
+// clientMessage writes the message a client sends for msgType with the payload's fields
+func (g *generator) clientMessage(msgType string, payload reflect.Type) {
+	g.buf.WriteString("\n")
+	fmt.Fprintf(&g.buf, "export interface %s {\n", messageName(msgType))
+	fmt.Fprintf(&g.buf, "  type: '%s';\n", msgType)
+	g.fields(payload, payload.PkgPath()+"."+payload.Name(), true)
+	g.buf.WriteString("}\n")
+}
+
+// clientUnion writes the union of all client messages
+func (g *generator) clientUnion(msgTypes []string) {
+	g.buf.WriteString("\n/** Any message a client may send */\n")
+	g.buf.WriteString("export type ClientMessage =\n")
+	for i, msgType := range msgTypes {
+		end := ""
+		if i == len(msgTypes)-1 {
+			end = ";"
+		}
+		fmt.Fprintf(&g.buf, "  | %s%s\n", messageName(msgType), end)
+	}
+}
+
+// pending writes the named types that were referenced but not listed
+func (g *generator) pending() {
+	for len(g.queue) > 0 {
+		t := g.queue[0]
+		g.queue = g.queue[1:]
+		g.named(t)
+	}
+}
+
+// fields writes the JSON fields of struct t; with payload set only fields tagged
+// `required:"true"` are required, as the server's router decodes them
+func (g *generator) fields(t reflect.Type, key string, payload bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.fields(field.Type, field.Type.PkgPath()+"."+field.Type.Name(), payload)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		optional := strings.Contains(options, "omitempty") || strings.Contains(options, "omitzero")
+		if payload {
+			optional = field.Tag.Get("required") != "true"
+		}
+		mark := ""
+		if optional {
+			mark = "?"
+		}
+		g.comment("  ", g.docs[key+"."+field.Name])
+		fmt.Fprintf(&g.buf, "  %s%s: %s;\n", name, mark, g.tsType(field.Type, false))
+	}
+}
+
+// tsType returns the TypeScript type of t, queueing named types it refers to
+func (g *generator) tsType(t reflect.Type, inArray bool) string {
+	switch {
+	case t == timeType:
+		return "string"
+	case t == rawMessageType:
+		return "unknown"
+	}
+
+	if t.Name() != "" && t.PkgPath() != "" && (t.Kind() == reflect.Struct || isBasic(t.Kind())) {
+		if _, exists := g.emitted[t.Name()]; !exists {
+			g.queue = append(g.queue, t)
+		}
+		return t.Name()
+	}
+
+	if isBasic(t.Kind()) {
+		return basicType(t.Kind())
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		nullable := g.tsType(t.Elem(), false) + " | null"
+		if inArray {
+			return "(" + nullable + ")"
+		}
+		return nullable
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // Base64
+		}
+		return g.tsType(t.Elem(), true) + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.tsType(t.Elem(), false) + ">"
+	case reflect.Interface:
+		return "unknown"
+	}
+	log.Fatalf("no TypeScript type for %s", t)
+	return ""
+}
+
+// isBasic reports whether values of kind k encode as a JSON number, string or bool
+func isBasic(k reflect.Kind) bool {
+	return k >= reflect.Bool && k <= reflect.Float64 || k == reflect.String
+}
+
+// basicType returns the TypeScript type of a bool, number or string kind
+func basicType(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	}
+	return "number"
+}
+
+// comment writes doc as a JSDoc comment at indent
+func (g *generator) comment(indent, doc string) {
+	if doc == "" {
+		return
+	}
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(&g.buf, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(&g.buf, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(&g.buf, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(&g.buf, "%s */\n", indent)
+}
+
+// messageName turns a message type like join_lobby into JoinLobbyMessage
+func messageName(msgType string) string {
+	var name strings.Builder
+	for part := range strings.SplitSeq(msgType, "_") {
+		if part != "" {
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	name.WriteString("Message")
+	return name.String()
+}
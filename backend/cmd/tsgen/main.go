@@ -0,0 +1,129 @@
+// Command tsgen writes the TypeScript types of the WebSocket protocol
+//
+// It describes the game state structs, the payloads of server messages as decoded by the
+// client SDK, every client message the server routes, and the message type names. Field
+// names, optionality and doc comments come from the Go structs and their json tags, so the
+// web frontend's types follow the backend protocol. Run it through go generate from the
+// backend directory:
+//
+//	go generate .
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+
+	"github.com/bariiss/snake/backend/client"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/game"
+)
+
+// stateTypes are the game state structs carried in game messages
+var stateTypes = []reflect.Type{
+	reflect.TypeFor[engine.GameState](),
+	reflect.TypeFor[engine.Snake](),
+	reflect.TypeFor[engine.Food](),
+	reflect.TypeFor[engine.Position](),
+	reflect.TypeFor[engine.PlayerStatus](),
+	reflect.TypeFor[engine.RulesProfile](),
+}
+
+// serverTypes are the payloads of server messages, as the client SDK decodes them
+var serverTypes = []reflect.Type{
+	reflect.TypeFor[client.ServerInfo](),
+	reflect.TypeFor[client.ServerError](),
+	reflect.TypeFor[client.PlayerInfo](),
+	reflect.TypeFor[client.LobbyPlayer](),
+	reflect.TypeFor[client.GameSummary](),
+	reflect.TypeFor[client.GameRequest](),
+	reflect.TypeFor[client.GameStart](),
+	reflect.TypeFor[client.RematchRequest](),
+	reflect.TypeFor[client.Disconnect](),
+	reflect.TypeFor[client.Reconnecting](),
+	reflect.TypeFor[client.Kicked](),
+	reflect.TypeFor[client.GameForceEnded](),
+	reflect.TypeFor[client.RulesList](),
+	reflect.TypeFor[client.Emote](),
+	reflect.TypeFor[client.EmoteEvent](),
+	reflect.TypeFor[client.MatchStats](),
+	reflect.TypeFor[client.LoungeChat](),
+	reflect.TypeFor[client.SpectatorInvite](),
+	reflect.TypeFor[client.ReportReceipt](),
+	reflect.TypeFor[client.RecentOpponent](),
+	reflect.TypeFor[client.Announcement](),
+	reflect.TypeFor[client.PartyInvite](),
+	reflect.TypeFor[client.Party](),
+	reflect.TypeFor[client.PartySpectate](),
+	reflect.TypeFor[client.Title](),
+	reflect.TypeFor[client.EarnedTitle](),
+	reflect.TypeFor[client.SinglePlayerStats](),
+	reflect.TypeFor[client.Profile](),
+	reflect.TypeFor[client.ClanMember](),
+	reflect.TypeFor[client.Clan](),
+	reflect.TypeFor[client.ClanInvite](),
+	reflect.TypeFor[client.ClanStanding](),
+	reflect.TypeFor[client.ClanMatchup](),
+	reflect.TypeFor[client.ClanScoreboard](),
+	reflect.TypeFor[client.TournamentEntrant](),
+	reflect.TypeFor[client.TournamentMatch](),
+	reflect.TypeFor[client.Tournament](),
+	reflect.TypeFor[client.LeagueStanding](),
+	reflect.TypeFor[client.LeagueStandings](),
+	reflect.TypeFor[client.TournamentScore](),
+	reflect.TypeFor[client.TournamentGame](),
+	reflect.TypeFor[client.TournamentScores](),
+	reflect.TypeFor[client.TournamentFeatured](),
+	reflect.TypeFor[client.CircuitStanding](),
+	reflect.TypeFor[client.CircuitStandings](),
+	reflect.TypeFor[client.CircuitPoints](),
+	reflect.TypeFor[client.CastUpdate](),
+	reflect.TypeFor[client.Casting](),
+	reflect.TypeFor[client.Campaign](),
+	reflect.TypeFor[client.HighScore](),
+	reflect.TypeFor[client.Leaderboard](),
+	reflect.TypeFor[client.SavedGame](),
+}
+
+func main() {
+	out := flag.String("out", "../frontend/src/app/services/protocol.ts", "file to write")
+	flag.Parse()
+
+	docs, err := loadDocs(".")
+	if err != nil {
+		log.Fatalf("reading doc comments: %v", err)
+	}
+	messageTypes, err := messageTypeNames("constants/constants.go")
+	if err != nil {
+		log.Fatalf("reading message types: %v", err)
+	}
+
+	g := newGenerator(docs)
+	g.header()
+	g.messageTypes(messageTypes)
+	g.section("Game state")
+	for _, t := range stateTypes {
+		g.named(t)
+	}
+	g.section("Server message payloads")
+	for _, t := range serverTypes {
+		g.named(t)
+	}
+	g.section("Client messages")
+	clientMessages := game.ClientMessages()
+	names := slices.Sorted(maps.Keys(clientMessages))
+	for _, msgType := range names {
+		g.clientMessage(msgType, clientMessages[msgType])
+	}
+	g.clientUnion(names)
+	g.pending()
+
+	if err := os.WriteFile(*out, g.buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %s (%d bytes)", *out, len(bytes.TrimSpace(g.buf.Bytes())))
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/bariiss/snake/backend/constants"
+
+	"github.com/google/uuid"
+)
+
+// Limits holds the capacity ceilings enforced by the game manager
+// A value of 0 disables the corresponding limit
+type Limits struct {
+	MaxGames          int `json:"max_games"`
+	MaxConnections    int `json:"max_connections"`
+	MaxGamesPerPlayer int `json:"max_games_per_player"`
+
+	MaxSpectatorsPerGame int `json:"max_spectators_per_game"`
+	SendQueueSize        int `json:"send_queue_size"` // Outbound messages queued per connection before it counts as slow; applied to new connections
+}
+
+// RateLimit controls how many messages a single connection may send
+type RateLimit struct {
+	MessagesPerSecond float64 `json:"messages_per_second"`
+	Burst             int     `json:"burst"`
+}
+
+// Broadcast holds how often periodic updates are pushed, in milliseconds
+type Broadcast struct {
+	TournamentScoresMs int `json:"tournament_scores_ms"` // Live scores sent to tournament watchers
+	AdminStatsMs       int `json:"admin_stats_ms"`       // Snapshots of the admin stats stream
+}
+
+// Countdown holds countdown lengths in seconds
+type Countdown struct {
+	GameSeconds    int `json:"game_seconds"`
+	RematchSeconds int `json:"rematch_seconds"`
+}
+
+// Gameplay holds settings applied to newly created games
+type Gameplay struct {
+	StartLength      int    `json:"start_length"`
+	SpawnLayout      string `json:"spawn_layout"`
+	StretchSlowTicks bool   `json:"stretch_slow_ticks"` // Double the tick interval of games whose ticks overrun it
+
+	FinishedGameRetentionSeconds int `json:"finished_game_retention_seconds"` // How long a finished game stays open for a rematch
+	ReconnectGraceSeconds        int `json:"reconnect_grace_seconds"`         // How long a rated round waits for a disconnected player before they forfeit
+}
+
+// Log controls the server log output
+type Log struct {
+	Level  string `json:"level"`  // debug, info, warn or error
+	Format string `json:"format"` // text or json
+}
+
+// Analytics sinks
+const (
+	AnalyticsNone   = ""
+	AnalyticsStdout = "stdout"
+	AnalyticsHTTP   = "http"
+	AnalyticsKafka  = "kafka"
+)
+
+// Analytics selects where gameplay analytics events are sent
+type Analytics struct {
+	Sink  string `json:"sink"`  // Empty (disabled), stdout, http or kafka
+	URL   string `json:"url"`   // Collector endpoint (http) or Kafka REST Proxy base URL (kafka)
+	Topic string `json:"topic"` // Kafka topic
+}
+
+// ErrorReporting selects the error tracker unexpected errors and panics are sent to
+type ErrorReporting struct {
+	DSN         string `json:"dsn"`         // Sentry-compatible DSN, reporting is disabled when empty
+	Environment string `json:"environment"` // Environment name attached to reports, e.g. production
+}
+
+// Notifications selects the chat webhooks notable events are posted to, and which events
+type Notifications struct {
+	DiscordWebhook  string `json:"discord_webhook"` // Discord incoming webhook URL, not posted to when empty
+	SlackWebhook    string `json:"slack_webhook"`   // Slack incoming webhook URL, not posted to when empty
+	TournamentStart bool   `json:"tournament_start"`
+	LeaderboardTop  bool   `json:"leaderboard_top"` // A new #1 on a single player leaderboard
+	WinStreak       bool   `json:"win_streak"`
+	WinStreakLength int    `json:"win_streak_length"` // Multiplayer wins in a row that are posted, again at every multiple
+}
+
+// Links controls how the signals tying accounts to one person are kept
+type Links struct {
+	Secret               string `json:"secret"`                 // Key of the hashes signals are kept as; changing it unlinks the accounts seen before
+	AddressRetentionDays int    `json:"address_retention_days"` // How long an account seen at an address is remembered
+}
+
+// ICEServer describes a STUN/TURN server offered to WebRTC peers
+type ICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// Config holds the server configuration
+type Config struct {
+	Port       string          `json:"port"`
+	InstanceID string          `json:"instance_id"`
+	Region     string          `json:"region"`
+	AdminToken string          `json:"admin_token"`
+	JWTSecret  string          `json:"jwt_secret"`
+	BusURL     string          `json:"bus_url"`
+	StorageDir string          `json:"storage_dir"` // Directory for persisted records, in memory when empty
+	ServeWeb   bool            `json:"serve_web"`   // Serve the embedded web client at /
+	TrustProxy bool            `json:"trust_proxy"` // Take client addresses from the X-Real-IP or X-Forwarded-For header of a reverse proxy
+	Log        Log             `json:"log"`
+	Analytics  Analytics       `json:"analytics"`
+	Errors     ErrorReporting  `json:"error_reporting"`
+	Notify     Notifications   `json:"notifications"`
+	Links      Links           `json:"links"`
+	Limits     Limits          `json:"limits"`
+	RateLimit  RateLimit       `json:"rate_limit"`
+	Broadcast  Broadcast       `json:"broadcast"`
+	Countdown  Countdown       `json:"countdown"`
+	Gameplay   Gameplay        `json:"gameplay"`
+	Features   map[string]bool `json:"features"`
+	ICEServers []ICEServer     `json:"ice_servers"`
+}
+
+// Default returns the configuration used when no overrides are set
+func Default() *Config {
+	return &Config{
+		Port:   "8080",
+		Region: "local",
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+		JWTSecret: "snake-game-secret-key-change-in-production",
+		Limits: Limits{
+			MaxGames:          500,
+			MaxConnections:    2000,
+			MaxGamesPerPlayer: 3,
+			SendQueueSize:     256,
+		},
+		RateLimit: RateLimit{
+			MessagesPerSecond: 30,
+			Burst:             60,
+		},
+		Broadcast: Broadcast{
+			TournamentScoresMs: 1000,
+			AdminStatsMs:       1000,
+		},
+		Countdown: Countdown{
+			GameSeconds:    3,
+			RematchSeconds: 5,
+		},
+		Gameplay: Gameplay{
+			StartLength: 3,
+			SpawnLayout: constants.SPAWN_MIRRORED_HORIZONTAL,
+
+			FinishedGameRetentionSeconds: 60,
+			ReconnectGraceSeconds:        15,
+		},
+		Notify: Notifications{
+			TournamentStart: true,
+			LeaderboardTop:  true,
+			WinStreak:       true,
+			WinStreakLength: 5,
+		},
+		Links: Links{
+			Secret:               "snake-link-secret-key-change-in-production",
+			AddressRetentionDays: 30,
+		},
+		Features:   map[string]bool{},
+		ICEServers: defaultICEServers(getEnv("WEBRTC_TURN_IP", "turn.li1.nl")),
+	}
+}
+
+// defaultICEServers returns the STUN and TURN servers hosted at the given address
+func defaultICEServers(turnServerIP string) []ICEServer {
+	return []ICEServer{
+		// STUN server
+		{
+			URLs: []string{fmt.Sprintf("stun:%s:3478", turnServerIP)},
+		},
+		// TURN server (non-TLS) with UDP and TCP transports
+		{
+			URLs: []string{
+				fmt.Sprintf("turn:%s:3478?transport=udp", turnServerIP),
+				fmt.Sprintf("turn:%s:3478?transport=tcp", turnServerIP),
+			},
+			Username:   "peaceast",
+			Credential: "endoplazmikretikulum",
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the optional CONFIG_FILE and environment variables
+// Environment variables take precedence over values from the file
+// The result is validated; all problems are reported together in a *ValidationError
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	env := &envReader{}
+	cfg.Port = env.string("PORT", cfg.Port)
+	cfg.InstanceID = env.string("INSTANCE_ID", cfg.InstanceID)
+	cfg.Region = env.string("REGION", cfg.Region)
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = defaultInstanceID()
+	}
+	cfg.AdminToken = env.string("ADMIN_TOKEN", cfg.AdminToken)
+	cfg.JWTSecret = env.string("JWT_SECRET", cfg.JWTSecret)
+	cfg.BusURL = env.string("BUS_URL", cfg.BusURL)
+	cfg.StorageDir = env.string("STORAGE_DIR", cfg.StorageDir)
+	cfg.ServeWeb = env.bool("SERVE_WEB", cfg.ServeWeb)
+	cfg.TrustProxy = env.bool("TRUST_PROXY", cfg.TrustProxy)
+	cfg.Log.Level = env.string("LOG_LEVEL", cfg.Log.Level)
+	cfg.Log.Format = env.string("LOG_FORMAT", cfg.Log.Format)
+	cfg.Analytics.Sink = env.string("ANALYTICS_SINK", cfg.Analytics.Sink)
+	cfg.Analytics.URL = env.string("ANALYTICS_URL", cfg.Analytics.URL)
+	cfg.Analytics.Topic = env.string("ANALYTICS_TOPIC", cfg.Analytics.Topic)
+	cfg.Errors.DSN = env.string("ERROR_REPORTING_DSN", cfg.Errors.DSN)
+	cfg.Errors.Environment = env.string("ERROR_REPORTING_ENVIRONMENT", cfg.Errors.Environment)
+	cfg.Notify.DiscordWebhook = env.string("NOTIFY_DISCORD_WEBHOOK", cfg.Notify.DiscordWebhook)
+	cfg.Notify.SlackWebhook = env.string("NOTIFY_SLACK_WEBHOOK", cfg.Notify.SlackWebhook)
+	cfg.Notify.TournamentStart = env.bool("NOTIFY_TOURNAMENT_START", cfg.Notify.TournamentStart)
+	cfg.Notify.LeaderboardTop = env.bool("NOTIFY_LEADERBOARD_TOP", cfg.Notify.LeaderboardTop)
+	cfg.Notify.WinStreak = env.bool("NOTIFY_WIN_STREAK", cfg.Notify.WinStreak)
+	cfg.Notify.WinStreakLength = env.int("NOTIFY_WIN_STREAK_LENGTH", cfg.Notify.WinStreakLength)
+	cfg.Links.Secret = env.string("LINK_SECRET", cfg.Links.Secret)
+	cfg.Links.AddressRetentionDays = env.int("LINK_ADDRESS_RETENTION_DAYS", cfg.Links.AddressRetentionDays)
+
+	cfg.Gameplay.StartLength = env.int("START_LENGTH", cfg.Gameplay.StartLength)
+	cfg.Gameplay.SpawnLayout = env.string("SPAWN_LAYOUT", cfg.Gameplay.SpawnLayout)
+	cfg.Gameplay.StretchSlowTicks = env.bool("STRETCH_SLOW_TICKS", cfg.Gameplay.StretchSlowTicks)
+	cfg.Gameplay.FinishedGameRetentionSeconds = env.int("FINISHED_GAME_RETENTION_SECONDS", cfg.Gameplay.FinishedGameRetentionSeconds)
+	cfg.Gameplay.ReconnectGraceSeconds = env.int("RECONNECT_GRACE_SECONDS", cfg.Gameplay.ReconnectGraceSeconds)
+
+	cfg.Limits.MaxGames = env.int("MAX_GAMES", cfg.Limits.MaxGames)
+	cfg.Limits.MaxConnections = env.int("MAX_CONNECTIONS", cfg.Limits.MaxConnections)
+	cfg.Limits.MaxGamesPerPlayer = env.int("MAX_GAMES_PER_PLAYER", cfg.Limits.MaxGamesPerPlayer)
+	cfg.Limits.MaxSpectatorsPerGame = env.int("MAX_SPECTATORS_PER_GAME", cfg.Limits.MaxSpectatorsPerGame)
+	cfg.Limits.SendQueueSize = env.int("SEND_QUEUE_SIZE", cfg.Limits.SendQueueSize)
+
+	cfg.Broadcast.TournamentScoresMs = env.int("BROADCAST_TOURNAMENT_SCORES_MS", cfg.Broadcast.TournamentScoresMs)
+	cfg.Broadcast.AdminStatsMs = env.int("BROADCAST_ADMIN_STATS_MS", cfg.Broadcast.AdminStatsMs)
+
+	problems := append(env.problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// defaultInstanceID identifies this process by hostname (the container ID under Docker),
+// falling back to a random ID
+func defaultInstanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return uuid.New().String()[:8]
+}
+
+// getEnv reads a string environment variable, falling back to def if unset
+func getEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// envReader reads environment overrides and collects parse errors for the validation report
+type envReader struct {
+	problems []string
+}
+
+// string reads a string environment variable, falling back to def if unset
+func (r *envReader) string(key, def string) string {
+	return getEnv(key, def)
+}
+
+// int reads an integer environment variable, falling back to def if unset or invalid
+func (r *envReader) int(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		r.problems = append(r.problems, fmt.Sprintf("%s: %q is not a non-negative integer", key, value))
+		return def
+	}
+	return parsed
+}
+
+// bool reads a boolean environment variable, falling back to def if unset or invalid
+func (r *envReader) bool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Sprintf("%s: %q is not a boolean", key, value))
+		return def
+	}
+	return parsed
+}
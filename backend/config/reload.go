@@ -0,0 +1,146 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Reloader keeps the active configuration and re-applies it when asked to reload
+// Only settings that are safe to change at runtime are taken from a reloaded config;
+// everything else keeps its startup value until the server restarts
+type Reloader struct {
+	mu      sync.Mutex
+	current *Config
+	hooks   []func(*Config)
+}
+
+// NewReloader creates a reloader starting from the given configuration
+func NewReloader(initial *Config) *Reloader {
+	return &Reloader{current: initial}
+}
+
+// Current returns the active configuration
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// OnReload registers a function called with the new configuration after every reload
+func (r *Reloader) OnReload(hook func(*Config)) {
+	r.mu.Lock()
+	r.hooks = append(r.hooks, hook)
+	r.mu.Unlock()
+}
+
+// Reload re-reads the configuration and applies the reloadable settings
+// Returns the names of changed settings that require a restart and were ignored
+func (r *Reloader) Reload() ([]string, error) {
+	next, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	applied, ignored := mergeReloadable(r.current, next)
+	r.current = applied
+	hooks := append([]func(*Config){}, r.hooks...)
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(applied)
+	}
+
+	if len(ignored) > 0 {
+		slog.Warn("Configuration reloaded, settings requiring restart were ignored", "ignored", ignored)
+	} else {
+		slog.Info("Configuration reloaded")
+	}
+	return ignored, nil
+}
+
+// WatchSignals reloads the configuration whenever the process receives SIGHUP
+func (r *Reloader) WatchSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			slog.Info("Received SIGHUP, reloading configuration")
+			if _, err := r.Reload(); err != nil {
+				slog.Error("Configuration reload failed, keeping previous configuration", "error", err)
+			}
+		}
+	}()
+}
+
+// mergeReloadable returns a copy of current with the reloadable settings taken from next,
+// along with the names of non-reloadable settings that differ between the two
+func mergeReloadable(current, next *Config) (*Config, []string) {
+	merged := *current
+
+	// Safe to change at runtime: applied to new games and connections
+	merged.Limits = next.Limits
+	merged.RateLimit = next.RateLimit
+	merged.Broadcast = next.Broadcast
+	merged.Countdown = next.Countdown
+	merged.Gameplay = next.Gameplay
+	merged.Features = next.Features
+	merged.ICEServers = next.ICEServers
+	merged.Log.Level = next.Log.Level
+	merged.Notify = next.Notify
+	merged.Notify.DiscordWebhook = current.Notify.DiscordWebhook
+	merged.Notify.SlackWebhook = current.Notify.SlackWebhook
+
+	// Bound at startup: changes require a restart
+	var ignored []string
+	if current.Port != next.Port {
+		ignored = append(ignored, "port")
+	}
+	if current.InstanceID != next.InstanceID {
+		ignored = append(ignored, "instance_id")
+	}
+	if current.Region != next.Region {
+		ignored = append(ignored, "region")
+	}
+	if current.JWTSecret != next.JWTSecret {
+		ignored = append(ignored, "jwt_secret")
+	}
+	if current.AdminToken != next.AdminToken {
+		ignored = append(ignored, "admin_token")
+	}
+	if current.BusURL != next.BusURL {
+		ignored = append(ignored, "bus_url")
+	}
+	if current.StorageDir != next.StorageDir {
+		ignored = append(ignored, "storage_dir")
+	}
+	if current.TrustProxy != next.TrustProxy {
+		ignored = append(ignored, "trust_proxy")
+	}
+	if current.ServeWeb != next.ServeWeb {
+		ignored = append(ignored, "serve_web")
+	}
+	if current.Log.Format != next.Log.Format {
+		ignored = append(ignored, "log.format")
+	}
+	if current.Errors != next.Errors {
+		ignored = append(ignored, "error_reporting")
+	}
+	if current.Links != next.Links {
+		ignored = append(ignored, "links")
+	}
+	if current.Analytics != next.Analytics {
+		ignored = append(ignored, "analytics")
+	}
+	if current.Notify.DiscordWebhook != next.Notify.DiscordWebhook {
+		ignored = append(ignored, "notifications.discord_webhook")
+	}
+	if current.Notify.SlackWebhook != next.Notify.SlackWebhook {
+		ignored = append(ignored, "notifications.slack_webhook")
+	}
+	return &merged, ignored
+}
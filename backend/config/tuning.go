@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Tuning holds the operational limits an admin may adjust while the server runs
+// Its fields mirror the sections of the configuration file they are taken from.
+type Tuning struct {
+	Limits    Limits    `json:"limits"`
+	RateLimit RateLimit `json:"rate_limit"`
+	Broadcast Broadcast `json:"broadcast"`
+}
+
+// TuningChange is a tuned setting with its previous and new value
+type TuningChange struct {
+	Setting string `json:"setting"` // Dotted path, e.g. rate_limit.burst
+	From    any    `json:"from"`
+	To      any    `json:"to"`
+}
+
+// Tuning returns the tunable settings of the configuration
+func (c *Config) Tuning() Tuning {
+	return Tuning{Limits: c.Limits, RateLimit: c.RateLimit, Broadcast: c.Broadcast}
+}
+
+// Tune applies a JSON object with some of the Tuning settings on top of the active
+// configuration. The result is validated like a loaded configuration and, if valid, applied
+// like a reload. Tuned values last until the next reload or restart, which take them from the
+// configuration file and environment again.
+// Returns the settings that changed value.
+func (r *Reloader) Tune(patch []byte) ([]TuningChange, error) {
+	r.mu.Lock()
+	tuning := r.current.Tuning()
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&tuning); err != nil {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("parsing tuning: %w", err)
+	}
+
+	next := *r.current
+	next.Limits, next.RateLimit, next.Broadcast = tuning.Limits, tuning.RateLimit, tuning.Broadcast
+	if err := next.Validate(); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	changes := tuningChanges(r.current.Tuning(), tuning)
+	if len(changes) == 0 {
+		r.mu.Unlock()
+		return changes, nil
+	}
+	r.current = &next
+	hooks := append([]func(*Config){}, r.hooks...)
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(&next)
+	}
+	slog.Info("Limits tuned", "changes", changes)
+	return changes, nil
+}
+
+// tuningChanges lists the settings that differ between two tunings, sorted by their path
+func tuningChanges(before, after Tuning) []TuningChange {
+	changes := []TuningChange{}
+	flatBefore, flatAfter := flattenTuning(before), flattenTuning(after)
+	for _, setting := range slices.Sorted(maps.Keys(flatAfter)) {
+		if !reflect.DeepEqual(flatBefore[setting], flatAfter[setting]) {
+			changes = append(changes, TuningChange{Setting: setting, From: flatBefore[setting], To: flatAfter[setting]})
+		}
+	}
+	return changes
+}
+
+// flattenTuning maps the dotted JSON path of every tuning setting to its value
+func flattenTuning(tuning Tuning) map[string]any {
+	data, _ := json.Marshal(tuning)
+	var sections map[string]map[string]any
+	json.Unmarshal(data, &sections)
+
+	flat := make(map[string]any)
+	for section, settings := range sections {
+		for name, value := range settings {
+			flat[section+"."+name] = value
+		}
+	}
+	return flat
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/features"
+	"github.com/bariiss/snake/backend/reporting"
+)
+
+const (
+	minJWTSecretLength  = 32
+	minLinkSecretLength = 32
+	minAdminTokenLength = 16
+	maxCountdownSeconds = 60
+
+	minSendQueueSize = 16
+	maxSendQueueSize = 4096
+	minBroadcastMs   = 100
+	maxBroadcastMs   = 60_000
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d problem(s)):", len(e.Problems))
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// Validate checks the complete configuration and returns a *ValidationError listing all problems
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// problems returns a human-readable description of each invalid setting
+func (c *Config) problems() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		add("port: %q must be a number between 1 and 65535 (set PORT)", c.Port)
+	}
+
+	if strings.TrimSpace(c.Region) == "" {
+		add("region: must not be empty (set REGION)")
+	}
+
+	if len(c.JWTSecret) < minJWTSecretLength {
+		add("jwt_secret: must be at least %d characters, got %d (set JWT_SECRET)", minJWTSecretLength, len(c.JWTSecret))
+	}
+	if len(c.Links.Secret) < minLinkSecretLength {
+		add("links.secret: must be at least %d characters, got %d (set LINK_SECRET)", minLinkSecretLength, len(c.Links.Secret))
+	}
+	if c.Links.AddressRetentionDays < 1 {
+		add("links.address_retention_days: must be at least 1, got %d (set LINK_ADDRESS_RETENTION_DAYS)", c.Links.AddressRetentionDays)
+	}
+	if c.AdminToken != "" && len(c.AdminToken) < minAdminTokenLength {
+		add("admin_token: must be at least %d characters when set, got %d (set ADMIN_TOKEN)", minAdminTokenLength, len(c.AdminToken))
+	}
+
+	if c.StorageDir != "" {
+		if err := checkStorageDir(c.StorageDir); err != nil {
+			add("storage_dir: %v (set STORAGE_DIR)", err)
+		}
+	}
+
+	if c.BusURL != "" {
+		if u, err := url.Parse(c.BusURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			add("bus_url: %q must be a redis:// or rediss:// URL (set BUS_URL)", c.BusURL)
+		}
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		add("log.level: %q must be one of debug, info, warn, error (set LOG_LEVEL)", c.Log.Level)
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		add("log.format: %q must be text or json (set LOG_FORMAT)", c.Log.Format)
+	}
+
+	switch c.Analytics.Sink {
+	case AnalyticsNone, AnalyticsStdout:
+	case AnalyticsHTTP, AnalyticsKafka:
+		if u, err := url.Parse(c.Analytics.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("analytics.url: %q must be an http:// or https:// URL for the %s sink (set ANALYTICS_URL)", c.Analytics.URL, c.Analytics.Sink)
+		}
+		if c.Analytics.Sink == AnalyticsKafka && strings.TrimSpace(c.Analytics.Topic) == "" {
+			add("analytics.topic: must not be empty for the kafka sink (set ANALYTICS_TOPIC)")
+		}
+	default:
+		add("analytics.sink: %q must be empty, stdout, http or kafka (set ANALYTICS_SINK)", c.Analytics.Sink)
+	}
+
+	if c.Errors.DSN != "" {
+		if _, _, err := reporting.ParseDSN(c.Errors.DSN); err != nil {
+			add("error_reporting.dsn: %v (set ERROR_REPORTING_DSN)", err)
+		}
+	}
+
+	if c.Notify.DiscordWebhook != "" && !isHTTPS(c.Notify.DiscordWebhook) {
+		add("notifications.discord_webhook: must be an https:// URL (set NOTIFY_DISCORD_WEBHOOK)")
+	}
+	if c.Notify.SlackWebhook != "" && !isHTTPS(c.Notify.SlackWebhook) {
+		add("notifications.slack_webhook: must be an https:// URL (set NOTIFY_SLACK_WEBHOOK)")
+	}
+	if c.Notify.WinStreakLength < 2 {
+		add("notifications.win_streak_length: %d must be at least 2 (set NOTIFY_WIN_STREAK_LENGTH)", c.Notify.WinStreakLength)
+	}
+
+	if c.Limits.MaxGames < 0 || c.Limits.MaxConnections < 0 || c.Limits.MaxGamesPerPlayer < 0 || c.Limits.MaxSpectatorsPerGame < 0 {
+		add("limits: values must not be negative (use 0 for unlimited)")
+	}
+	if c.Limits.SendQueueSize < minSendQueueSize || c.Limits.SendQueueSize > maxSendQueueSize {
+		add("limits.send_queue_size: %d must be between %d and %d (set SEND_QUEUE_SIZE)", c.Limits.SendQueueSize, minSendQueueSize, maxSendQueueSize)
+	}
+
+	if c.RateLimit.MessagesPerSecond < 0 {
+		add("rate_limit.messages_per_second: must not be negative (use 0 to disable)")
+	}
+	if c.RateLimit.MessagesPerSecond > 0 && c.RateLimit.Burst < 1 {
+		add("rate_limit.burst: must be at least 1 when rate limiting is enabled")
+	}
+
+	if c.Broadcast.TournamentScoresMs < minBroadcastMs || c.Broadcast.TournamentScoresMs > maxBroadcastMs {
+		add("broadcast.tournament_scores_ms: %d must be between %d and %d (set BROADCAST_TOURNAMENT_SCORES_MS)", c.Broadcast.TournamentScoresMs, minBroadcastMs, maxBroadcastMs)
+	}
+	if c.Broadcast.AdminStatsMs < minBroadcastMs || c.Broadcast.AdminStatsMs > maxBroadcastMs {
+		add("broadcast.admin_stats_ms: %d must be between %d and %d (set BROADCAST_ADMIN_STATS_MS)", c.Broadcast.AdminStatsMs, minBroadcastMs, maxBroadcastMs)
+	}
+
+	if c.Countdown.GameSeconds < 0 || c.Countdown.GameSeconds > maxCountdownSeconds {
+		add("countdown.game_seconds: %d must be between 0 and %d", c.Countdown.GameSeconds, maxCountdownSeconds)
+	}
+	if c.Countdown.RematchSeconds < 0 || c.Countdown.RematchSeconds > maxCountdownSeconds {
+		add("countdown.rematch_seconds: %d must be between 0 and %d", c.Countdown.RematchSeconds, maxCountdownSeconds)
+	}
+
+	// Grid bounds: both snakes must fit on their half of the board
+	maxLength := min(constants.GRID_WIDTH, constants.GRID_HEIGHT) / 4
+	if c.Gameplay.StartLength < 1 || c.Gameplay.StartLength > maxLength {
+		add("gameplay.start_length: %d must be between 1 and %d for a %dx%d grid (set START_LENGTH)",
+			c.Gameplay.StartLength, maxLength, constants.GRID_WIDTH, constants.GRID_HEIGHT)
+	}
+	switch c.Gameplay.SpawnLayout {
+	case constants.SPAWN_MIRRORED_HORIZONTAL, constants.SPAWN_DIAGONAL_CORNERS, constants.SPAWN_RANDOM_SYMMETRIC:
+	default:
+		add("gameplay.spawn_layout: unknown layout %q, expected one of %s, %s, %s (set SPAWN_LAYOUT)",
+			c.Gameplay.SpawnLayout, constants.SPAWN_MIRRORED_HORIZONTAL, constants.SPAWN_DIAGONAL_CORNERS, constants.SPAWN_RANDOM_SYMMETRIC)
+	}
+	if c.Gameplay.FinishedGameRetentionSeconds < 0 {
+		add("gameplay.finished_game_retention_seconds: must not be negative (use 0 to remove finished games right away)")
+	}
+	if c.Gameplay.ReconnectGraceSeconds < 0 {
+		add("gameplay.reconnect_grace_seconds: must not be negative (use 0 for players to forfeit as soon as they disconnect)")
+	}
+
+	for name := range c.Features {
+		if !features.Known(name) {
+			add("features: unknown feature flag %q", name)
+		}
+	}
+
+	for i, server := range c.ICEServers {
+		if len(server.URLs) == 0 {
+			add("ice_servers[%d]: at least one URL is required", i)
+		}
+		for _, rawURL := range server.URLs {
+			if err := validateICEURL(rawURL, server); err != nil {
+				add("ice_servers[%d]: %v", i, err)
+			}
+		}
+	}
+
+	return problems
+}
+
+// isHTTPS reports whether rawURL is an https:// URL with a host
+func isHTTPS(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+// checkStorageDir checks that the server can write records to dir: an existing directory
+// must take new files, and a missing one must be creatable in the nearest directory above it
+func checkStorageDir(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				if existing == dir {
+					return fmt.Errorf("%q is not a directory", dir)
+				}
+				return fmt.Errorf("%q can't be created: %q is not a directory", dir, existing)
+			}
+			break
+		}
+		// A file on the way up is reported once the walk reaches it
+		if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR) {
+			return fmt.Errorf("%q can't be read: %v", dir, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("%q can't be created: no parent directory exists", dir)
+		}
+		existing = parent
+	}
+
+	// Writing a file is the only reliable test: permission bits don't account for ACLs,
+	// read-only mounts or the user the server runs as
+	probe, err := os.CreateTemp(existing, ".storage-check-*")
+	if err != nil {
+		if existing == dir {
+			return fmt.Errorf("%q is not writable: %v", dir, err)
+		}
+		return fmt.Errorf("%q can't be created: %q is not writable: %v", dir, existing, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// validateICEURL checks a STUN/TURN URL of the form scheme:host[:port][?transport=udp|tcp]
+func validateICEURL(rawURL string, server ICEServer) error {
+	scheme, rest, found := strings.Cut(rawURL, ":")
+	if !found {
+		return fmt.Errorf("%q is missing a scheme (stun:, stuns:, turn: or turns:)", rawURL)
+	}
+
+	hostPort, query, _ := strings.Cut(rest, "?")
+	switch scheme {
+	case "stun", "stuns":
+		if query != "" {
+			return fmt.Errorf("%q: STUN URLs do not take query parameters", rawURL)
+		}
+	case "turn", "turns":
+		if query != "" && query != "transport=udp" && query != "transport=tcp" {
+			return fmt.Errorf("%q: transport must be udp or tcp", rawURL)
+		}
+		if server.Username == "" || server.Credential == "" {
+			return fmt.Errorf("%q: TURN servers require a username and credential", rawURL)
+		}
+	default:
+		return fmt.Errorf("%q has unsupported scheme %q", rawURL, scheme)
+	}
+
+	host := hostPort
+	if strings.Contains(hostPort, ":") {
+		var port string
+		var err error
+		host, port, err = net.SplitHostPort(hostPort)
+		if err != nil {
+			return fmt.Errorf("%q: %v", rawURL, err)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%q: invalid port %q", rawURL, port)
+		}
+	}
+	if host == "" {
+		return fmt.Errorf("%q is missing a host", rawURL)
+	}
+	return nil
+}
@@ -8,38 +8,175 @@ const (
 	GRID_HEIGHT = 30
 	TICK_RATE   = 100 * time.Millisecond
 
+	// Spawn layouts
+	SPAWN_MIRRORED_HORIZONTAL = "mirrored_horizontal"
+	SPAWN_DIAGONAL_CORNERS    = "diagonal_corners"
+	SPAWN_RANDOM_SYMMETRIC    = "random_symmetric"
+
+	// Food spawns: where new food is placed
+	FOOD_SPAWN_RANDOM = "random" // Any free cell, uniformly at random
+	FOOD_SPAWN_FAIR   = "fair"   // A free cell about as far from every snake's head, so no snake is closer by luck
+
+	// Tie-breaks: how a head-on collision of two snakes is decided
+	TIE_BREAK_TIE          = "tie"          // Both snakes die and the round is a tie
+	TIE_BREAK_LENGTH       = "length"       // The longer snake wins
+	TIE_BREAK_SCORE        = "score"        // The snake with the higher score wins
+	TIE_BREAK_SUDDEN_DEATH = "sudden_death" // The round is replayed from the opening, and the first snake to eat wins
+
+	// Rules presets
+	RULES_CLASSIC = "classic"
+	RULES_BLITZ   = "blitz"
+	RULES_ARCADE  = "arcade"
+	RULES_DUEL    = "duel"
+
+	// Rules of campaign levels and practice games, which aren't presets clients can choose
+	RULES_CAMPAIGN = "campaign"
+	RULES_PRACTICE = "practice"
+
+	// Single player difficulties
+	DIFFICULTY_EASY   = "easy"
+	DIFFICULTY_NORMAL = "normal"
+	DIFFICULTY_HARD   = "hard"
+
+	// Single player variants
+	VARIANT_ENDLESS      = "endless"
+	VARIANT_SCORE_ATTACK = "score_attack"
+	VARIANT_DAILY        = "daily"
+
+	// Win conditions
+	WIN_LAST_STANDING = "last_standing"
+	WIN_SCORE_TARGET  = "score_target"
+
+	// WebSocket subprotocols; the name carries the protocol version and the encoding
+	SUBPROTOCOL_JSON_V1     = "snake.v1.json"
+	SUBPROTOCOL_JSON_V2     = "snake.v2.json"     // Version 1 messages in one batch envelope per frame
+	SUBPROTOCOL_BITBOARD_V1 = "snake.v1.bitboard" // Version 1 JSON messages, game updates as binary bitboards
+
 	// Message types
-	MSG_CONNECTED           = "connected"
-	MSG_JOIN_LOBBY          = "join_lobby"
-	MSG_LEAVE_LOBBY         = "leave_lobby"
-	MSG_GAME_REQUEST        = "game_request"
-	MSG_GAME_REQUEST_SENT   = "game_request_sent"
-	MSG_GAME_ACCEPT         = "game_accept"
-	MSG_GAME_REJECT         = "game_reject"
-	MSG_PLAYER_READY        = "player_ready"
-	MSG_GAME_START          = "game_start"
-	MSG_GAME_UPDATE         = "game_update"
-	MSG_PLAYER_MOVE         = "player_move"
-	MSG_GAME_OVER           = "game_over"
-	MSG_ERROR               = "error"
-	MSG_LOBBY_STATUS        = "lobby_status"
-	MSG_MATCH_FOUND         = "match_found"
-	MSG_LIST_GAMES          = "list_games"
-	MSG_GAMES_LIST          = "games_list"
-	MSG_JOIN_SPECTATOR      = "join_spectator"
-	MSG_SPECTATOR_UPDATE    = "spectator_update"
-	MSG_REMATCH_REQUEST     = "rematch_request"
-	MSG_REMATCH_ACCEPT      = "rematch_accept"
-	MSG_REMATCH_COUNTDOWN   = "rematch_countdown"
-	MSG_REMATCH_START       = "rematch_start"
-	MSG_PLAYER_DISCONNECTED = "player_disconnected"
-	MSG_GAME_REQUEST_CANCEL = "game_request_cancel"
-	MSG_PEER_OFFER          = "peer_offer"
-	MSG_PEER_ANSWER         = "peer_answer"
-	MSG_PEER_ICE_CANDIDATE  = "peer_ice_candidate"
-	MSG_START_SINGLE_PLAYER = "start_single_player"
-	MSG_GET_GAME_STATE      = "get_game_state"
-	MSG_LEAVE_GAME          = "leave_game"
+	MSG_CONNECTED             = "connected"
+	MSG_BATCH                 = "batch"
+	MSG_JOIN_LOBBY            = "join_lobby"
+	MSG_LEAVE_LOBBY           = "leave_lobby"
+	MSG_GAME_REQUEST          = "game_request"
+	MSG_GAME_REQUEST_SENT     = "game_request_sent"
+	MSG_GAME_ACCEPT           = "game_accept"
+	MSG_GAME_REJECT           = "game_reject"
+	MSG_PLAYER_READY          = "player_ready"
+	MSG_GAME_START            = "game_start"
+	MSG_GAME_UPDATE           = "game_update"
+	MSG_PLAYER_MOVE           = "player_move"
+	MSG_GAME_OVER             = "game_over"
+	MSG_ERROR                 = "error"
+	MSG_LOBBY_STATUS          = "lobby_status"
+	MSG_MATCH_FOUND           = "match_found"
+	MSG_LIST_GAMES            = "list_games"
+	MSG_GAMES_LIST            = "games_list"
+	MSG_JOIN_SPECTATOR        = "join_spectator"
+	MSG_SPECTATOR_UPDATE      = "spectator_update"
+	MSG_REMATCH_REQUEST       = "rematch_request"
+	MSG_REMATCH_ACCEPT        = "rematch_accept"
+	MSG_REMATCH_COUNTDOWN     = "rematch_countdown"
+	MSG_REMATCH_START         = "rematch_start"
+	MSG_PLAYER_DISCONNECTED   = "player_disconnected"
+	MSG_PLAYER_RECONNECTING   = "player_reconnecting"
+	MSG_PLAYER_RECONNECTED    = "player_reconnected"
+	MSG_GAME_REQUEST_CANCEL   = "game_request_cancel"
+	MSG_PEER_OFFER            = "peer_offer"
+	MSG_PEER_ANSWER           = "peer_answer"
+	MSG_PEER_ICE_CANDIDATE    = "peer_ice_candidate"
+	MSG_START_SINGLE_PLAYER   = "start_single_player"
+	MSG_PAUSE_GAME            = "pause_game"
+	MSG_RESTART_GAME          = "restart_game"
+	MSG_GET_GAME_STATE        = "get_game_state"
+	MSG_LEAVE_GAME            = "leave_game"
+	MSG_LIST_RULES            = "list_rules"
+	MSG_RULES_LIST            = "rules_list"
+	MSG_START_HOT_SEAT        = "start_hot_seat"
+	MSG_EMOTE                 = "emote"
+	MSG_MATCH_STATS           = "match_stats"
+	MSG_LOUNGE_CHAT           = "lounge_chat"
+	MSG_LOUNGE_CLOSED         = "lounge_closed"
+	MSG_INVITE_SPECTATOR      = "invite_spectator"
+	MSG_SPECTATOR_INVITE      = "spectator_invite"
+	MSG_SPECTATOR_INVITE_SENT = "spectator_invite_sent"
+	MSG_REPORT_PLAYER         = "report_player"
+	MSG_REPORT_RECEIVED       = "report_received"
+	MSG_MUTE_PLAYER           = "mute_player"
+	MSG_UNMUTE_PLAYER         = "unmute_player"
+	MSG_MUTED_PLAYERS         = "muted_players"
+	MSG_GET_RECENT_OPPONENTS  = "get_recent_opponents"
+	MSG_RECENT_OPPONENTS      = "recent_opponents"
+	MSG_ANNOUNCEMENT          = "announcement"
+	MSG_ANNOUNCEMENT_CLEARED  = "announcement_cleared"
+	MSG_KICKED                = "kicked"
+	MSG_GAME_FORCE_ENDED      = "game_force_ended"
+	MSG_PARTY_INVITE          = "party_invite"
+	MSG_PARTY_ACCEPT          = "party_accept"
+	MSG_PARTY_DECLINE         = "party_decline"
+	MSG_PARTY_LEAVE           = "party_leave"
+	MSG_PARTY_QUEUE           = "party_queue"
+	MSG_PARTY_UPDATE          = "party_update"
+	MSG_PARTY_LEFT            = "party_left"
+	MSG_PARTY_SPECTATE        = "party_spectate"
+	MSG_UPDATE_PROFILE        = "update_profile"
+	MSG_PROFILE               = "profile"
+	MSG_TITLE_EARNED          = "title_earned"
+	MSG_CLAN_CREATE           = "clan_create"
+	MSG_CLAN_INVITE           = "clan_invite"
+	MSG_CLAN_ACCEPT           = "clan_accept"
+	MSG_CLAN_DECLINE          = "clan_decline"
+	MSG_CLAN_LEAVE            = "clan_leave"
+	MSG_CLAN_REMOVE           = "clan_remove"
+	MSG_CLAN_SET_ROLE         = "clan_set_role"
+	MSG_CLAN_UPDATE           = "clan_update"
+	MSG_CLAN_LEFT             = "clan_left"
+	MSG_GET_CLAN_SCOREBOARD   = "get_clan_scoreboard"
+	MSG_CLAN_SCOREBOARD       = "clan_scoreboard"
+	MSG_TOURNAMENT_CREATE     = "tournament_create"
+	MSG_TOURNAMENT_REGISTER   = "tournament_register"
+	MSG_TOURNAMENT_UNREGISTER = "tournament_unregister"
+	MSG_TOURNAMENT_START      = "tournament_start"
+	MSG_CHECK_IN              = "check_in"
+	MSG_TOURNAMENT_UPDATE     = "tournament_update"
+	MSG_LIST_TOURNAMENTS      = "list_tournaments"
+	MSG_TOURNAMENTS_LIST      = "tournaments_list"
+	MSG_GET_TOURNAMENT        = "get_tournament"
+	MSG_GET_LEAGUE_STANDINGS  = "get_league_standings"
+	MSG_LEAGUE_STANDINGS      = "league_standings"
+	MSG_WATCH_TOURNAMENT      = "watch_tournament"
+	MSG_UNWATCH_TOURNAMENT    = "unwatch_tournament"
+	MSG_TOURNAMENT_SCORES     = "tournament_scores"
+	MSG_TOURNAMENT_FEATURED   = "tournament_featured"
+	MSG_GET_CIRCUIT           = "get_circuit"
+	MSG_CIRCUIT_STANDINGS     = "circuit_standings"
+	MSG_CIRCUIT_POINTS        = "circuit_points"
+
+	MSG_TOURNAMENT_MODERATOR       = "tournament_moderator"
+	MSG_TOURNAMENT_PAUSE           = "tournament_pause"
+	MSG_TOURNAMENT_RESTART_MATCH   = "tournament_restart_match"
+	MSG_TOURNAMENT_DISQUALIFY      = "tournament_disqualify"
+	MSG_TOURNAMENT_EXTEND_CHECK_IN = "tournament_extend_check_in"
+	MSG_TOURNAMENT_CASTER          = "tournament_caster"
+	MSG_TOURNAMENT_VERIFY_MATCH    = "tournament_verify_match"
+	MSG_MATCH_VERIFICATION         = "match_verification"
+	MSG_CAST_GAMES                 = "cast_games"
+	MSG_UNCAST_GAMES               = "uncast_games"
+	MSG_CASTING                    = "casting"
+	MSG_CAST_UPDATE                = "cast_update"
+
+	MSG_GET_CAMPAIGN         = "get_campaign"
+	MSG_START_CAMPAIGN_LEVEL = "start_campaign_level"
+	MSG_CAMPAIGN             = "campaign"
+	MSG_GET_LEADERBOARD      = "get_leaderboard"
+	MSG_LEADERBOARD          = "leaderboard"
+	MSG_START_PRACTICE       = "start_practice"
+	MSG_SUSPEND_GAME         = "suspend_game"
+	MSG_RESUME_GAME          = "resume_game"
+	MSG_GET_SAVED_GAME       = "get_saved_game"
+	MSG_SAVED_GAME           = "saved_game"
+
+	MSG_GET_SINGLE_PLAYER_STATS = "get_single_player_stats"
+	MSG_SINGLE_PLAYER_STATS     = "single_player_stats"
 )
 
 type Direction int
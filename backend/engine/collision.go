@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/bariiss/snake/backend/constants"
+)
+
+// hitsObstacle reports whether a cell is covered by one of the board's obstacles
+func hitsObstacle(board *GameState, cell Position) bool {
+	for _, obstacle := range board.Obstacles {
+		if obstacle.X == cell.X && obstacle.Y == cell.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// wallCollisionWinner resolves snakes that left the board, or ran into an obstacle
+func wallCollisionWinner(board *GameState, crashed []bool) string {
+	if board.IsSinglePlayer {
+		if len(crashed) > 0 && crashed[0] {
+			return GameOver
+		}
+		return ""
+	}
+
+	switch {
+	case crashed[0] && crashed[1]:
+		return "tie"
+	case crashed[0]:
+		return board.Snakes[1].ID
+	case crashed[1]:
+		return board.Snakes[0].ID
+	}
+	return ""
+}
+
+// collisionWinner resolves snakes running into themselves or each other, and reports a
+// head-on collision for the tie-break to decide
+func collisionWinner(board *GameState) (string, bool) {
+	if board.IsSinglePlayer {
+		return selfCollisionSingle(board), false
+	}
+	return collisionWinnerMulti(board)
+}
+
+// selfCollisionSingle ends a single player game once the snake runs into itself
+func selfCollisionSingle(board *GameState) string {
+	if len(board.Snakes) == 0 {
+		return ""
+	}
+	head := board.Snakes[0].Body[0]
+	for _, bodyPart := range board.Snakes[0].Body[1:] {
+		if head.X == bodyPart.X && head.Y == bodyPart.Y {
+			return GameOver
+		}
+	}
+	return ""
+}
+
+// collisionWinnerMulti checks self, body and head-on collisions of a two snake game
+// A head-on collision is reported rather than decided.
+func collisionWinnerMulti(board *GameState) (string, bool) {
+	for i := range board.Snakes {
+		head := board.Snakes[i].Body[0]
+		for _, bodyPart := range board.Snakes[i].Body[1:] {
+			if head.X != bodyPart.X || head.Y != bodyPart.Y {
+				continue
+			}
+			// Snake i collided with itself, the other snake wins
+			if i == 0 {
+				return board.Snakes[1].ID, false
+			}
+			return board.Snakes[0].ID, false
+		}
+	}
+
+	snake1Head := board.Snakes[0].Body[0]
+	snake2Head := board.Snakes[1].Body[0]
+
+	if snake1Head.X != snake2Head.X || snake1Head.Y != snake2Head.Y {
+		// Check body collisions
+		for _, bodyPart := range board.Snakes[1].Body[1:] {
+			if snake1Head.X == bodyPart.X && snake1Head.Y == bodyPart.Y {
+				return board.Snakes[1].ID, false
+			}
+		}
+		for _, bodyPart := range board.Snakes[0].Body[1:] {
+			if snake2Head.X == bodyPart.X && snake2Head.Y == bodyPart.Y {
+				return board.Snakes[0].ID, false
+			}
+		}
+		return "", false
+	}
+	return "", true
+}
+
+// breakTie decides a head-on collision of a two snake game by the rules' tie-break and
+// records the rule applied on the board
+// Under sudden death the first head-on collision replays the round from the opening and
+// returns no winner; a second one is a tie.
+func breakTie(state State) string {
+	board := state.Board
+	rule := state.Rules.TieBreak
+	if rule == "" {
+		rule = constants.TIE_BREAK_SCORE
+	}
+	board.TieBreak = rule
+
+	first, second := board.Snakes[0], board.Snakes[1]
+	switch rule {
+	case constants.TIE_BREAK_SCORE:
+		return greater(first.ID, first.Score, second.ID, second.Score)
+	case constants.TIE_BREAK_LENGTH:
+		return greater(first.ID, len(first.Body), second.ID, len(second.Body))
+	case constants.TIE_BREAK_SUDDEN_DEATH:
+		if !board.SuddenDeath && startSuddenDeath(state) {
+			return ""
+		}
+	}
+	return "tie"
+}
+
+// greater returns the ID with the higher value, or "tie" when they are equal
+func greater(firstID string, first int, secondID string, second int) string {
+	switch {
+	case first > second:
+		return firstID
+	case second > first:
+		return secondID
+	}
+	return "tie"
+}
+
+// startSuddenDeath puts every snake back where it started the round and lays out new food,
+// keeping scores; returns false when the state has no opening for the board's snakes
+func startSuddenDeath(state State) bool {
+	board := state.Board
+	opening := make(map[string]Snake, len(state.Opening))
+	for _, snake := range state.Opening {
+		opening[snake.ID] = snake
+	}
+	for _, snake := range board.Snakes {
+		if _, exists := opening[snake.ID]; !exists {
+			return false
+		}
+	}
+
+	for i := range board.Snakes {
+		snake := &board.Snakes[i]
+		start := opening[snake.ID]
+		snake.Body = slices.Clone(start.Body)
+		snake.Direction = start.Direction
+		snake.Turns = nil
+		snake.Down = false
+	}
+	board.SuddenDeath = true
+	board.Foods = nil
+	SpawnFood(board, state.Rules, state.Rand)
+	return true
+}
+
+// suddenDeathWinner returns the snake that ate first under sudden death, "tie" when both
+// did on the same tick, and "" while neither has
+func suddenDeathWinner(ate []string) string {
+	switch len(ate) {
+	case 0:
+		return ""
+	case 1:
+		return ate[0]
+	}
+	return "tie"
+}
+
+// crashedSnakes returns the IDs of the snakes that left the board or ran into an obstacle,
+// any snake's body, or another head; snakes that are down already are left out
+func crashedSnakes(board *GameState, crashed []bool) []string {
+	var ids []string
+	for i, snake := range board.Snakes {
+		if snake.Down {
+			continue
+		}
+		if crashed[i] || hitsSnake(board, i) {
+			ids = append(ids, snake.ID)
+		}
+	}
+	return ids
+}
+
+// hitsSnake reports whether the head of snake i is on its own body or on any cell of
+// another snake
+func hitsSnake(board *GameState, i int) bool {
+	head := board.Snakes[i].Body[0]
+	for j, other := range board.Snakes {
+		body := other.Body
+		if j == i {
+			body = body[1:]
+		}
+		if slices.Contains(body, head) {
+			return true
+		}
+	}
+	return false
+}
+
+// teamScore returns the food eaten by all snakes of a co-op board
+func teamScore(board *GameState) int {
+	score := 0
+	for _, snake := range board.Snakes {
+		score += snake.Score
+	}
+	return score
+}
+
+// scoreTargetWinner returns the winner once a snake reaches the target score of a score_target game
+func scoreTargetWinner(board *GameState, rules RulesProfile) string {
+	if rules.WinCondition != constants.WIN_SCORE_TARGET || rules.TargetScore <= 0 {
+		return ""
+	}
+
+	winner := ""
+	bestScore := -1
+	for _, snake := range board.Snakes {
+		if snake.Score < rules.TargetScore {
+			continue
+		}
+		switch {
+		case snake.Score > bestScore:
+			winner = snake.ID
+			bestScore = snake.Score
+		case snake.Score == bestScore:
+			winner = "tie"
+		}
+	}
+	return winner
+}
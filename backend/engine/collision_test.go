@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// headOnState sets up two snakes whose heads meet on (6, 5) on the next step, with their
+// openings elsewhere on the board
+func headOnState(tieBreak string, foods ...Position) State {
+	board := &GameState{
+		Snakes: []Snake{
+			{ID: "a", Body: []Position{{5, 5}, {4, 5}, {3, 5}}, Direction: constants.RIGHT},
+			{ID: "b", Body: []Position{{7, 5}, {8, 5}, {9, 5}}, Direction: constants.LEFT},
+		},
+	}
+	for _, food := range foods {
+		board.Foods = append(board.Foods, Food{Position: food})
+	}
+	return State{
+		Board: board,
+		Rules: RulesProfile{FoodCount: max(len(foods), 1), TieBreak: tieBreak},
+		Rand:  rng.New(1),
+		Opening: []Snake{
+			{ID: "a", Body: []Position{{5, 20}, {4, 20}, {3, 20}}, Direction: constants.RIGHT},
+			{ID: "b", Body: []Position{{30, 20}, {31, 20}, {32, 20}}, Direction: constants.LEFT},
+		},
+	}
+}
+
+func TestHeadOnTieBreak(t *testing.T) {
+	tests := []struct {
+		name     string
+		tieBreak string
+		scores   [2]int
+		want     string
+	}{
+		{"score default", "", [2]int{2, 1}, "a"},
+		{"score", constants.TIE_BREAK_SCORE, [2]int{1, 3}, "b"},
+		{"score level", constants.TIE_BREAK_SCORE, [2]int{2, 2}, "tie"},
+		{"tie", constants.TIE_BREAK_TIE, [2]int{5, 0}, "tie"},
+		{"length level", constants.TIE_BREAK_LENGTH, [2]int{5, 0}, "tie"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := headOnState(tt.tieBreak)
+			state.Board.Snakes[0].Score, state.Board.Snakes[1].Score = tt.scores[0], tt.scores[1]
+			result := Step(state, nil)
+			if result.Winner != tt.want {
+				t.Fatalf("winner = %q, want %q", result.Winner, tt.want)
+			}
+			want := tt.tieBreak
+			if want == "" {
+				want = constants.TIE_BREAK_SCORE
+			}
+			if state.Board.TieBreak != want {
+				t.Errorf("tie break recorded = %q, want %q", state.Board.TieBreak, want)
+			}
+		})
+	}
+}
+
+func TestHeadOnLengthTieBreak(t *testing.T) {
+	state := headOnState(constants.TIE_BREAK_LENGTH)
+	state.Board.Snakes[1].Body = append(state.Board.Snakes[1].Body, Position{10, 5})
+	if result := Step(state, nil); result.Winner != "b" {
+		t.Fatalf("winner = %q, want b", result.Winner)
+	}
+}
+
+func TestSuddenDeathReplaysRound(t *testing.T) {
+	state := headOnState(constants.TIE_BREAK_SUDDEN_DEATH)
+	result := Step(state, nil)
+	if result.Over() {
+		t.Fatalf("winner = %q, want the round replayed", result.Winner)
+	}
+	if !state.Board.SuddenDeath {
+		t.Fatal("sudden death not started")
+	}
+	for i, snake := range state.Board.Snakes {
+		if !slices.Equal(snake.Body, state.Opening[i].Body) {
+			t.Errorf("snake %s body = %v, want its opening %v", snake.ID, snake.Body, state.Opening[i].Body)
+		}
+	}
+
+	// A second head-on collision is a tie
+	state.Board.Snakes[0].Body = []Position{{5, 5}, {4, 5}, {3, 5}}
+	state.Board.Snakes[1].Body = []Position{{7, 5}, {8, 5}, {9, 5}}
+	if result := Step(state, nil); result.Winner != "tie" {
+		t.Fatalf("second head-on winner = %q, want tie", result.Winner)
+	}
+}
+
+func TestSuddenDeathHeadOnOnFood(t *testing.T) {
+	state := headOnState(constants.TIE_BREAK_SUDDEN_DEATH, Position{6, 5})
+	result := Step(state, nil)
+	if result.Over() {
+		t.Fatalf("winner = %q, want the round replayed", result.Winner)
+	}
+	if !state.Board.SuddenDeath {
+		t.Fatal("sudden death not started")
+	}
+
+	// The first snake to eat in the replayed round wins it
+	head := state.Board.Snakes[1].Body[0]
+	state.Board.Foods = []Food{{Position: Position{head.X - 1, head.Y}}}
+	if result := Step(state, nil); result.Winner != "b" {
+		t.Fatalf("winner = %q, want b", result.Winner)
+	}
+}
+
+func TestSuddenDeathWithoutOpeningIsTie(t *testing.T) {
+	state := headOnState(constants.TIE_BREAK_SUDDEN_DEATH)
+	state.Opening = nil
+	if result := Step(state, nil); result.Winner != "tie" {
+		t.Fatalf("winner = %q, want tie", result.Winner)
+	}
+}
@@ -0,0 +1,168 @@
+// Package engine implements the rules of snake on plain game state: movement, wrapping,
+// food and collisions. It holds no locks, channels or connections, so servers, bots and
+// replays advance a board the same way.
+//
+// The engine is part of the module's public API, along with the rng, constants, client and
+// bot packages: it imports none of the server's packages, and its exported names only
+// change in backward-compatible ways.
+package engine
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// GameOver is the winner of a single player game the snake lost
+const GameOver = "game_over"
+
+// State is everything a step reads and changes
+type State struct {
+	Board *GameState
+	Rules RulesProfile
+	Rand  rng.Rand // Places new food; the same seed and inputs replay the same game
+
+	// Opening is the snakes as the round started, which sudden death puts them back to
+	// Without it, a head-on collision under the sudden death tie-break is a tie.
+	Opening []Snake
+}
+
+// Inputs maps snake IDs to the direction requested since the previous step
+type Inputs map[string]constants.Direction
+
+// Result is the outcome of a step
+type Result struct {
+	Winner  string   // Snake ID, "tie" or GameOver once the game has ended, "" while it goes on
+	Ate     []string // IDs of the snakes that ate food this step
+	Crashed []string // IDs of the snakes that crashed this step when crashing respawns them
+}
+
+// Over reports whether the step ended the game
+func (r Result) Over() bool {
+	return r.Winner != ""
+}
+
+// opposites maps each direction to the one a snake can't turn into
+var opposites = map[constants.Direction]constants.Direction{
+	constants.UP:    constants.DOWN,
+	constants.DOWN:  constants.UP,
+	constants.LEFT:  constants.RIGHT,
+	constants.RIGHT: constants.LEFT,
+}
+
+// Reverses reports whether turning from one direction to another reverses the snake
+func Reverses(from, to constants.Direction) bool {
+	return opposites[from] == to
+}
+
+// Step advances the board by one tick
+// Each snake turns to its input unless that reverses it, moves one cell and grows when it
+// eats; the board is then refilled with food and the winner, if any, is decided, with a
+// head-on collision going to the rules' tie-break. Under rules that respawn snakes there
+// is no winner: the snakes that crashed are reported, and snakes that are down stay put
+// until the caller respawns them.
+func Step(state State, inputs Inputs) Result {
+	board := state.Board
+	// Only food eaten in a replayed round decides sudden death, not food eaten on the
+	// collision that starts it
+	suddenDeath := board.SuddenDeath
+	for i := range board.Snakes {
+		if board.Snakes[i].Down {
+			continue
+		}
+		if direction, exists := inputs[board.Snakes[i].ID]; exists && !Reverses(board.Snakes[i].Direction, direction) {
+			board.Snakes[i].Direction = direction
+		}
+	}
+
+	var result Result
+	crashed := make([]bool, len(board.Snakes))
+	for i := range board.Snakes {
+		snake := &board.Snakes[i]
+		if snake.Down {
+			continue
+		}
+		newHead, inBounds := NextHead(snake.Body[0], snake.Direction, state.Rules.Wrap)
+		if !inBounds || hitsObstacle(board, newHead) {
+			// Without wrapping, leaving the board is fatal, as is running into an obstacle
+			crashed[i] = true
+			continue
+		}
+
+		snake.Body = append([]Position{newHead}, snake.Body...)
+
+		if eatFood(board, newHead) {
+			snake.Score++
+			result.Ate = append(result.Ate, snake.ID)
+		} else {
+			snake.Body = snake.Body[:len(snake.Body)-1]
+		}
+	}
+	SpawnFood(board, state.Rules, state.Rand)
+
+	if state.Rules.Respawns() {
+		result.Crashed = crashedSnakes(board, crashed)
+		return result
+	}
+	result.Winner = wallCollisionWinner(board, crashed)
+	if result.Winner == "" {
+		var headOn bool
+		result.Winner, headOn = collisionWinner(board)
+		switch {
+		case headOn && board.Coop:
+			result.Winner = "tie"
+		case headOn:
+			result.Winner = breakTie(state)
+		}
+	}
+	if board.Coop {
+		// Co-op snakes share their score and their fate: once either dies, both lose
+		board.TeamScore = teamScore(board)
+		if result.Winner != "" {
+			result.Winner = GameOver
+		}
+		return result
+	}
+	if result.Winner == "" && suddenDeath {
+		result.Winner = suddenDeathWinner(result.Ate)
+	}
+	if result.Winner == "" {
+		result.Winner = scoreTargetWinner(board, state.Rules)
+	}
+	return result
+}
+
+// NextHead returns the position a head moves to in the given direction
+// Returns false if the move leaves the board and wrapping is disabled
+func NextHead(head Position, direction constants.Direction, wrap bool) (Position, bool) {
+	newHead := head
+	switch direction {
+	case constants.UP:
+		newHead.Y--
+	case constants.DOWN:
+		newHead.Y++
+	case constants.LEFT:
+		newHead.X--
+	case constants.RIGHT:
+		newHead.X++
+	}
+
+	inBounds := newHead.X >= 0 && newHead.X < constants.GRID_WIDTH && newHead.Y >= 0 && newHead.Y < constants.GRID_HEIGHT
+	if inBounds {
+		return newHead, true
+	}
+	if !wrap {
+		return head, false
+	}
+
+	if newHead.X < 0 {
+		newHead.X = constants.GRID_WIDTH - 1
+	} else if newHead.X >= constants.GRID_WIDTH {
+		newHead.X = 0
+	}
+	if newHead.Y < 0 {
+		newHead.Y = constants.GRID_HEIGHT - 1
+	} else if newHead.Y >= constants.GRID_HEIGHT {
+		newHead.Y = 0
+	}
+	return newHead, true
+}
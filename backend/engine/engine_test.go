@@ -0,0 +1,397 @@
+package engine
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// snakeAt builds a snake heading in direction with its body on the given cells, head first
+func snakeAt(id string, direction constants.Direction, cells ...Position) Snake {
+	return Snake{ID: id, Direction: direction, Body: cells}
+}
+
+// twoSnakes is a board with two snakes far apart, heading towards nothing
+func twoSnakes() *GameState {
+	return &GameState{
+		Snakes: []Snake{
+			snakeAt("a", constants.RIGHT, Position{10, 10}, Position{9, 10}, Position{8, 10}),
+			snakeAt("b", constants.LEFT, Position{30, 20}, Position{31, 20}, Position{32, 20}),
+		},
+		Foods: []Food{{Position: Position{0, 29}}},
+	}
+}
+
+func TestStepMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		input constants.Direction
+		want  Position
+	}{
+		{"straight on", constants.RIGHT, Position{11, 10}},
+		{"turn up", constants.UP, Position{10, 9}},
+		{"turn down", constants.DOWN, Position{10, 11}},
+		{"reverse ignored", constants.LEFT, Position{11, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := twoSnakes()
+			result := Step(State{Board: board, Rules: RulesProfile{FoodCount: 1}, Rand: rng.New(1)}, Inputs{"a": tt.input})
+			if result.Over() {
+				t.Fatalf("winner = %q, want the game to go on", result.Winner)
+			}
+			a := board.Snakes[0]
+			if a.Body[0] != tt.want {
+				t.Errorf("head = %v, want %v", a.Body[0], tt.want)
+			}
+			if len(a.Body) != 3 || a.Body[1] != (Position{10, 10}) {
+				t.Errorf("body = %v, want the head followed by the old head and length 3", a.Body)
+			}
+			if b := board.Snakes[1]; b.Body[0] != (Position{29, 20}) {
+				t.Errorf("snake without input moved to %v, want %v", b.Body[0], Position{29, 20})
+			}
+		})
+	}
+}
+
+func TestNextHead(t *testing.T) {
+	last := Position{constants.GRID_WIDTH - 1, constants.GRID_HEIGHT - 1}
+	tests := []struct {
+		name      string
+		head      Position
+		direction constants.Direction
+		wrap      bool
+		want      Position
+		inBounds  bool
+	}{
+		{"inside", Position{5, 5}, constants.UP, false, Position{5, 4}, true},
+		{"left edge", Position{0, 5}, constants.LEFT, false, Position{0, 5}, false},
+		{"left edge wraps", Position{0, 5}, constants.LEFT, true, Position{last.X, 5}, true},
+		{"right edge wraps", Position{last.X, 5}, constants.RIGHT, true, Position{0, 5}, true},
+		{"top edge wraps", Position{5, 0}, constants.UP, true, Position{5, last.Y}, true},
+		{"bottom edge", Position{5, last.Y}, constants.DOWN, false, Position{5, last.Y}, false},
+		{"bottom edge wraps", Position{5, last.Y}, constants.DOWN, true, Position{5, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, inBounds := NextHead(tt.head, tt.direction, tt.wrap)
+			if got != tt.want || inBounds != tt.inBounds {
+				t.Errorf("NextHead = %v, %v, want %v, %v", got, inBounds, tt.want, tt.inBounds)
+			}
+		})
+	}
+}
+
+func TestStepWalls(t *testing.T) {
+	edge := func(board *GameState, i int) {
+		board.Snakes[i].Body = []Position{{0, 3 + i*10}, {1, 3 + i*10}, {2, 3 + i*10}}
+		board.Snakes[i].Direction = constants.LEFT
+	}
+	tests := []struct {
+		name   string
+		setup  func(*GameState)
+		wrap   bool
+		single bool
+		want   string
+	}{
+		{"first leaves the board", func(b *GameState) { edge(b, 0) }, false, false, "b"},
+		{"second leaves the board", func(b *GameState) { edge(b, 1) }, false, false, "a"},
+		{"both leave the board", func(b *GameState) { edge(b, 0); edge(b, 1) }, false, false, "tie"},
+		{"wrapping carries on", func(b *GameState) { edge(b, 0) }, true, false, ""},
+		{"single player leaves the board", func(b *GameState) { edge(b, 0) }, false, true, GameOver},
+		{"obstacle", func(b *GameState) { b.Obstacles = []Position{{11, 10}} }, true, false, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := twoSnakes()
+			if tt.single {
+				board.Snakes = board.Snakes[:1]
+				board.IsSinglePlayer = true
+			}
+			tt.setup(board)
+			result := Step(State{Board: board, Rules: RulesProfile{FoodCount: 1, Wrap: tt.wrap}, Rand: rng.New(1)}, nil)
+			if result.Winner != tt.want {
+				t.Errorf("winner = %q, want %q", result.Winner, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		snakes []Snake
+		want   string
+	}{
+		{
+			"into the other's body",
+			[]Snake{
+				snakeAt("a", constants.DOWN, Position{5, 4}, Position{5, 3}, Position{5, 2}),
+				snakeAt("b", constants.RIGHT, Position{6, 5}, Position{5, 5}, Position{4, 5}, Position{3, 5}),
+			},
+			"b",
+		},
+		{
+			"the other runs into this body",
+			[]Snake{
+				snakeAt("a", constants.RIGHT, Position{6, 5}, Position{5, 5}, Position{4, 5}, Position{3, 5}),
+				snakeAt("b", constants.UP, Position{5, 6}, Position{5, 7}, Position{5, 8}),
+			},
+			"a",
+		},
+		{
+			"into itself",
+			[]Snake{
+				snakeAt("a", constants.DOWN, Position{5, 5}, Position{6, 5}, Position{6, 6}, Position{5, 6}, Position{4, 6}),
+				snakeAt("b", constants.LEFT, Position{30, 20}, Position{31, 20}),
+			},
+			"b",
+		},
+		{
+			"head-on goes to the tie-break",
+			[]Snake{
+				snakeAt("a", constants.RIGHT, Position{5, 5}, Position{4, 5}),
+				snakeAt("b", constants.LEFT, Position{7, 5}, Position{8, 5}),
+			},
+			"tie",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := &GameState{Snakes: tt.snakes, Foods: []Food{{Position: Position{0, 29}}}}
+			result := Step(State{Board: board, Rules: RulesProfile{FoodCount: 1}, Rand: rng.New(1)}, nil)
+			if result.Winner != tt.want {
+				t.Errorf("winner = %q, want %q", result.Winner, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepSinglePlayerRunsIntoItself(t *testing.T) {
+	board := &GameState{
+		IsSinglePlayer: true,
+		Snakes:         []Snake{snakeAt("a", constants.DOWN, Position{5, 5}, Position{6, 5}, Position{6, 6}, Position{5, 6}, Position{4, 6})},
+		Foods:          []Food{{Position: Position{0, 29}}},
+	}
+	if result := Step(State{Board: board, Rules: RulesProfile{FoodCount: 1}, Rand: rng.New(1)}, nil); result.Winner != GameOver {
+		t.Errorf("winner = %q, want %q", result.Winner, GameOver)
+	}
+}
+
+func TestStepEats(t *testing.T) {
+	board := twoSnakes()
+	board.Foods = []Food{{Position: Position{11, 10}}, {Position: Position{0, 0}}}
+	rules := RulesProfile{FoodCount: 2}
+	result := Step(State{Board: board, Rules: rules, Rand: rng.New(7)}, nil)
+
+	if !slices.Equal(result.Ate, []string{"a"}) {
+		t.Errorf("ate = %v, want [a]", result.Ate)
+	}
+	a := board.Snakes[0]
+	if a.Score != 1 || len(a.Body) != 4 || a.Body[3] != (Position{8, 10}) {
+		t.Errorf("snake = score %d, body %v, want score 1 and the tail kept", a.Score, a.Body)
+	}
+	if len(board.Foods) != rules.FoodCount {
+		t.Fatalf("food = %v, want %d items", board.Foods, rules.FoodCount)
+	}
+	if board.Food != board.Foods[0] {
+		t.Errorf("legacy food = %v, want the first item %v", board.Food, board.Foods[0])
+	}
+	for _, food := range board.Foods {
+		if food.Position == (Position{11, 10}) {
+			t.Error("eaten food still on the board")
+		}
+		for _, snake := range board.Snakes {
+			if slices.Contains(snake.Body, food.Position) {
+				t.Errorf("food %v spawned on snake %s", food.Position, snake.ID)
+			}
+		}
+	}
+
+	// The same seed places the new food on the same cell
+	again := twoSnakes()
+	again.Foods = []Food{{Position: Position{11, 10}}, {Position: Position{0, 0}}}
+	Step(State{Board: again, Rules: rules, Rand: rng.New(7)}, nil)
+	if !slices.Equal(again.Foods, board.Foods) {
+		t.Errorf("food with the same seed = %v, want %v", again.Foods, board.Foods)
+	}
+}
+
+func TestStepScoreTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores [2]int
+		want   string
+	}{
+		{"below target", [2]int{4, 4}, ""},
+		{"first reaches it", [2]int{5, 2}, "a"},
+		{"second goes past it", [2]int{5, 6}, "b"},
+		{"both reach it level", [2]int{5, 5}, "tie"},
+	}
+	rules := RulesProfile{FoodCount: 1, WinCondition: constants.WIN_SCORE_TARGET, TargetScore: 5}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := twoSnakes()
+			board.Snakes[0].Score, board.Snakes[1].Score = tt.scores[0], tt.scores[1]
+			if result := Step(State{Board: board, Rules: rules, Rand: rng.New(1)}, nil); result.Winner != tt.want {
+				t.Errorf("winner = %q, want %q", result.Winner, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepCoop(t *testing.T) {
+	board := twoSnakes()
+	board.Coop = true
+	board.Snakes[0].Score, board.Snakes[1].Score = 2, 3
+	state := State{Board: board, Rules: RulesProfile{FoodCount: 1}, Rand: rng.New(1)}
+	if result := Step(state, nil); result.Over() || board.TeamScore != 5 {
+		t.Fatalf("winner = %q, team score = %d, want the game to go on with 5", result.Winner, board.TeamScore)
+	}
+
+	// Either snake crashing ends the game for both
+	board.Snakes[1].Body = []Position{{0, 20}, {1, 20}}
+	if result := Step(state, nil); result.Winner != GameOver {
+		t.Errorf("winner = %q, want %q", result.Winner, GameOver)
+	}
+}
+
+func TestStepRespawns(t *testing.T) {
+	board := twoSnakes()
+	board.Snakes[0].Body = []Position{{0, 3}, {1, 3}}
+	board.Snakes[0].Direction = constants.LEFT
+	state := State{Board: board, Rules: RulesProfile{FoodCount: 1, RespawnMs: 2000}, Rand: rng.New(1)}
+
+	result := Step(state, nil)
+	if result.Over() || !slices.Equal(result.Crashed, []string{"a"}) {
+		t.Fatalf("result = %+v, want a crashed and no winner", result)
+	}
+
+	// A snake that is down stays put until it respawns
+	board.Snakes[0].Down = true
+	body := slices.Clone(board.Snakes[0].Body)
+	result = Step(state, nil)
+	if len(result.Crashed) != 0 || !slices.Equal(board.Snakes[0].Body, body) {
+		t.Errorf("down snake moved to %v or crashed again (%v)", board.Snakes[0].Body, result.Crashed)
+	}
+}
+
+func TestFairFoodSpawn(t *testing.T) {
+	board := twoSnakes()
+	board.Foods = nil
+	rules := RulesProfile{FoodCount: 3, FoodSpawn: constants.FOOD_SPAWN_FAIR}
+	SpawnFood(board, rules, rng.New(3))
+	if len(board.Foods) != 3 {
+		t.Fatalf("food = %v, want 3 items", board.Foods)
+	}
+	for _, food := range board.Foods {
+		a := stepsFrom(board, board.Snakes[0].Body[0], false)
+		b := stepsFrom(board, board.Snakes[1].Body[0], false)
+		cell := food.Position.Y*constants.GRID_WIDTH + food.Position.X
+		if diff := a[cell] - b[cell]; diff < -fairSpawnTolerance || diff > fairSpawnTolerance {
+			t.Errorf("food %v is %d and %d steps from the heads, want within %d", food.Position, a[cell], b[cell], fairSpawnTolerance)
+		}
+	}
+}
+
+func TestStartSnakes(t *testing.T) {
+	layouts := []string{"", constants.SPAWN_MIRRORED_HORIZONTAL, constants.SPAWN_DIAGONAL_CORNERS, constants.SPAWN_RANDOM_SYMMETRIC}
+	for _, layout := range layouts {
+		t.Run(layout, func(t *testing.T) {
+			snakes := StartSnakes(rng.New(5), layout, 3, "a", "b")
+			if len(snakes) != 2 {
+				t.Fatalf("snakes = %d, want 2", len(snakes))
+			}
+			for _, snake := range snakes {
+				if len(snake.Body) != 3 {
+					t.Errorf("snake %s length = %d, want 3", snake.ID, len(snake.Body))
+				}
+				for _, cell := range snake.Body {
+					if !inGrid(cell) || slices.Contains(snakes[1-slices.Index([]string{"a", "b"}, snake.ID)].Body, cell) {
+						t.Errorf("snake %s cell %v off the grid or on the other snake", snake.ID, cell)
+					}
+				}
+			}
+			// Point-symmetric openings: the second head mirrors the first through the center
+			a, b := snakes[0].Body[0], snakes[1].Body[0]
+			if a.X+b.X != constants.GRID_WIDTH-1 {
+				t.Errorf("heads %v and %v are not mirrored", a, b)
+			}
+			if again := StartSnakes(rng.New(5), layout, 3, "a", "b"); !reflect.DeepEqual(again, snakes) {
+				t.Errorf("same seed laid out %v, want %v", again, snakes)
+			}
+		})
+	}
+}
+
+// TestStepReplays plays the same inputs twice from the same seed and expects the same game
+func TestStepReplays(t *testing.T) {
+	inputs := []Inputs{
+		{"a": constants.DOWN}, nil, {"b": constants.UP}, nil, nil,
+		{"a": constants.RIGHT, "b": constants.LEFT}, nil, {"a": constants.UP}, nil, nil,
+	}
+	play := func() (*GameState, []Result) {
+		rnd := rng.New(42)
+		board := &GameState{Snakes: StartSnakes(rnd, constants.SPAWN_RANDOM_SYMMETRIC, 3, "a", "b")}
+		rules := RulesProfile{FoodCount: 3, Wrap: true}
+		OpeningFood(board, rules, constants.SPAWN_RANDOM_SYMMETRIC, rnd)
+		var results []Result
+		for round := 0; round < 5; round++ {
+			for _, in := range inputs {
+				result := Step(State{Board: board, Rules: rules, Rand: rnd}, in)
+				results = append(results, result)
+				if result.Over() {
+					return board, results
+				}
+			}
+		}
+		return board, results
+	}
+
+	first, firstResults := play()
+	second, secondResults := play()
+	if !reflect.DeepEqual(first, second) || !reflect.DeepEqual(firstResults, secondResults) {
+		t.Errorf("replay diverged:\n%+v\n%+v", first, second)
+	}
+}
+
+// TestStepRewound plays a step again after rewinding the random source, as latency
+// compensation does when it takes back a tick, and expects the food to land on the same cell
+func TestStepRewound(t *testing.T) {
+	rnd := rng.New(11).(rng.Rewinder)
+	rules := RulesProfile{FoodCount: 1}
+	board := twoSnakes()
+	board.Foods = []Food{{Position: Position{11, 10}}}
+	before := board.Clone()
+	position := rnd.Position()
+
+	Step(State{Board: board, Rules: rules, Rand: rnd}, nil)
+	rnd.Rewind(position)
+	Step(State{Board: before, Rules: rules, Rand: rnd}, nil)
+	if !reflect.DeepEqual(board, before) {
+		t.Errorf("step after rewinding = %+v, want %+v", before, board)
+	}
+}
+
+// TestRandomSpawnSurvivesOpening lays out random openings without wrapping and expects
+// snakes left alone to survive until they could reach the wall they face
+func TestRandomSpawnSurvivesOpening(t *testing.T) {
+	ticks := constants.GRID_HEIGHT / 4
+	for seed := int64(0); seed < 300; seed++ {
+		for _, length := range []int{1, 3, 7} {
+			rnd := rng.New(seed)
+			board := &GameState{Snakes: StartSnakes(rnd, constants.SPAWN_RANDOM_SYMMETRIC, length, "a", "b")}
+			rules := RulesProfile{FoodCount: 1}
+			OpeningFood(board, rules, constants.SPAWN_RANDOM_SYMMETRIC, rnd)
+			opening := board.Clone()
+			for tick := 1; tick <= ticks; tick++ {
+				if result := Step(State{Board: board, Rules: rules, Rand: rnd}, nil); result.Over() {
+					t.Fatalf("seed %d, length %d: game over on tick %d from %+v", seed, length, tick, opening.Snakes)
+				}
+			}
+		}
+	}
+}
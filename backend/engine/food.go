@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// fairSpawnTolerance is how many more steps the farthest snake may need to reach fairly
+// spawned food than the nearest
+const fairSpawnTolerance = 1
+
+// RefillFood tops the board up to count food items placed uniformly at random, and keeps
+// the legacy Food field in sync
+func RefillFood(board *GameState, count int, rnd rng.Rand) {
+	for len(board.Foods) < max(count, 1) {
+		board.Foods = append(board.Foods, Food{Position: freeCell(rnd, board)})
+	}
+	board.Food = board.Foods[0]
+}
+
+// OpeningFood lays out the food a round starts with
+// Under the random symmetric spawn layout, the snakes' point-symmetric openings leave cells
+// exactly as far from every head, and the opening food goes on those; otherwise, and for
+// food that doesn't fit there, it is placed as SpawnFood would.
+func OpeningFood(board *GameState, rules RulesProfile, layout string, rnd rng.Rand) {
+	if layout == constants.SPAWN_RANDOM_SYMMETRIC {
+		for len(board.Foods) < max(rules.FoodCount, 1) {
+			cell, found := fairCell(rnd, board, rules.Wrap, 0)
+			if !found {
+				break
+			}
+			board.Foods = append(board.Foods, Food{Position: cell})
+		}
+	}
+	SpawnFood(board, rules, rnd)
+}
+
+// SpawnFood tops the board up to the rules' food count, placing each item as the rules'
+// food spawn says, and keeps the legacy Food field in sync
+// Under the fair spawn, food goes on a free cell that every snake still playing reaches in
+// about as many steps, within fairSpawnTolerance, or as close to that as the board allows;
+// with fewer than two snakes it is placed at random.
+func SpawnFood(board *GameState, rules RulesProfile, rnd rng.Rand) {
+	if rules.FoodSpawn != constants.FOOD_SPAWN_FAIR {
+		RefillFood(board, rules.FoodCount, rnd)
+		return
+	}
+	for len(board.Foods) < max(rules.FoodCount, 1) {
+		cell, found := fairCell(rnd, board, rules.Wrap, fairSpawnTolerance)
+		if !found {
+			cell = freeCell(rnd, board)
+		}
+		board.Foods = append(board.Foods, Food{Position: cell})
+	}
+	board.Food = board.Foods[0]
+}
+
+// fairCell picks a free cell whose distances from the heads of the snakes still playing
+// differ the least, at random among those within tolerance steps of each other
+// Returns false when fewer than two snakes are playing or no free cell is reachable by all.
+func fairCell(rnd rng.Rand, board *GameState, wrap bool, tolerance int) (Position, bool) {
+	var distances [][]int
+	for _, snake := range board.Snakes {
+		if !snake.Down && len(snake.Body) > 0 {
+			distances = append(distances, stepsFrom(board, snake.Body[0], wrap))
+		}
+	}
+	if len(distances) < 2 {
+		return Position{}, false
+	}
+
+	var candidates []Position
+	best := -1
+	for y := range constants.GRID_HEIGHT {
+		for x := range constants.GRID_WIDTH {
+			cell := Position{X: x, Y: y}
+			if !isFree(board, cell) {
+				continue
+			}
+			spread, reachable := distanceSpread(distances, y*constants.GRID_WIDTH+x)
+			if !reachable {
+				continue
+			}
+			spread = max(spread, tolerance)
+			switch {
+			case best < 0 || spread < best:
+				best, candidates = spread, []Position{cell}
+			case spread == best:
+				candidates = append(candidates, cell)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return Position{}, false
+	}
+	return candidates[rnd.Intn(len(candidates))], true
+}
+
+// distanceSpread returns how many more steps the farthest snake needs to reach a cell than
+// the nearest, and false when a snake can't reach it
+func distanceSpread(distances [][]int, cell int) (int, bool) {
+	nearest, farthest := -1, -1
+	for _, steps := range distances {
+		d := steps[cell]
+		if d < 0 {
+			return 0, false
+		}
+		if nearest < 0 || d < nearest {
+			nearest = d
+		}
+		farthest = max(farthest, d)
+	}
+	return farthest - nearest, true
+}
+
+// stepsFrom returns the fewest moves from a head to each cell of the grid, by y*width + x,
+// going around snakes and obstacles; -1 for cells it can't reach
+func stepsFrom(board *GameState, head Position, wrap bool) []int {
+	steps := make([]int, constants.GRID_WIDTH*constants.GRID_HEIGHT)
+	for i := range steps {
+		steps[i] = -1
+	}
+	blocked := make([]bool, len(steps))
+	for _, snake := range board.Snakes {
+		for _, part := range snake.Body {
+			if inGrid(part) {
+				blocked[part.Y*constants.GRID_WIDTH+part.X] = true
+			}
+		}
+	}
+	for _, obstacle := range board.Obstacles {
+		if inGrid(obstacle) {
+			blocked[obstacle.Y*constants.GRID_WIDTH+obstacle.X] = true
+		}
+	}
+	if !inGrid(head) {
+		return steps
+	}
+
+	steps[head.Y*constants.GRID_WIDTH+head.X] = 0
+	queue := []Position{head}
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		for _, direction := range []constants.Direction{constants.UP, constants.DOWN, constants.LEFT, constants.RIGHT} {
+			next, inBounds := NextHead(cell, direction, wrap)
+			i := next.Y*constants.GRID_WIDTH + next.X
+			if !inBounds || blocked[i] || steps[i] >= 0 {
+				continue
+			}
+			steps[i] = steps[cell.Y*constants.GRID_WIDTH+cell.X] + 1
+			queue = append(queue, next)
+		}
+	}
+	return steps
+}
+
+// inGrid reports whether a cell lies on the grid
+func inGrid(cell Position) bool {
+	return cell.X >= 0 && cell.X < constants.GRID_WIDTH && cell.Y >= 0 && cell.Y < constants.GRID_HEIGHT
+}
+
+// eatFood removes the food at pos, if any, and reports whether food was eaten
+func eatFood(board *GameState, pos Position) bool {
+	for i, food := range board.Foods {
+		if food.Position.X != pos.X || food.Position.Y != pos.Y {
+			continue
+		}
+		board.Foods = append(board.Foods[:i], board.Foods[i+1:]...)
+		return true
+	}
+	return false
+}
+
+// freeCell picks a random cell not covered by a snake, food or an obstacle
+func freeCell(rnd rng.Rand, board *GameState) Position {
+	for {
+		cell := Position{
+			X: rnd.Intn(constants.GRID_WIDTH),
+			Y: rnd.Intn(constants.GRID_HEIGHT),
+		}
+		if isFree(board, cell) {
+			return cell
+		}
+	}
+}
+
+// isFree reports whether a cell is not covered by a snake, food or an obstacle
+func isFree(board *GameState, cell Position) bool {
+	for _, snake := range board.Snakes {
+		for _, bodyPart := range snake.Body {
+			if cell.X == bodyPart.X && cell.Y == bodyPart.Y {
+				return false
+			}
+		}
+	}
+	for _, existing := range board.Foods {
+		if cell.X == existing.Position.X && cell.Y == existing.Position.Y {
+			return false
+		}
+	}
+	return !hitsObstacle(board, cell)
+}
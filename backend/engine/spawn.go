@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// spawnPoint is the head position and initial heading of a snake
+type spawnPoint struct {
+	head      Position
+	direction constants.Direction
+}
+
+// StartSnakes lays out the starting snakes of a game, one per ID, for the given spawn layout
+// The length is clamped to fit the grid; random layouts draw from rnd. Colors and
+// usernames are left to the caller.
+func StartSnakes(rnd rng.Rand, layout string, length int, ids ...string) []Snake {
+	width, height := constants.GRID_WIDTH, constants.GRID_HEIGHT
+	length = clampStartLength(length, width, height)
+	points := spawnPoints(rnd, layout, len(ids), width, height, length)
+
+	snakes := make([]Snake, 0, len(ids))
+	for i, id := range ids {
+		snakes = append(snakes, Snake{
+			ID:        id,
+			Body:      snakeBody(points[i], length, width, height),
+			Direction: points[i].direction,
+		})
+	}
+	return snakes
+}
+
+// clampStartLength keeps the starting length between 1 and a quarter of the smaller grid side
+func clampStartLength(length, width, height int) int {
+	return max(1, min(length, min(width, height)/4))
+}
+
+// spawnPoints returns the spawn point of each snake for the given layout
+// A lone snake starts at the center unless the layout is randomized; a second snake
+// is always placed symmetrically to the first
+func spawnPoints(rnd rng.Rand, layout string, count, width, height, length int) []spawnPoint {
+	if count == 1 && layout != constants.SPAWN_RANDOM_SYMMETRIC {
+		return []spawnPoint{{
+			head:      Position{X: width / 2, Y: height / 2},
+			direction: constants.RIGHT,
+		}}
+	}
+
+	var first spawnPoint
+	switch layout {
+	case constants.SPAWN_DIAGONAL_CORNERS:
+		first = spawnPoint{
+			head:      Position{X: max(width/8, length-1), Y: height / 8},
+			direction: constants.RIGHT,
+		}
+	case constants.SPAWN_RANDOM_SYMMETRIC:
+		first = randomSpawnPoint(rnd, width, height, length)
+	default:
+		first = spawnPoint{
+			head:      Position{X: max(width/8, length-1), Y: height / 2},
+			direction: constants.RIGHT,
+		}
+	}
+
+	points := []spawnPoint{first}
+	if count > 1 {
+		points = append(points, mirrorSpawnPoint(layout, first, width, height))
+	}
+	return points
+}
+
+// randomSpawnPoint picks a spawn point in the left quarter of the board whose body fits
+// without wrapping, heading right, up or down
+// The head is at least a quarter of the board height from the wall it faces, so a snake
+// left alone does not crash in its first moves.
+func randomSpawnPoint(rnd rng.Rand, width, height, length int) spawnPoint {
+	directions := []constants.Direction{constants.RIGHT, constants.UP, constants.DOWN}
+	direction := directions[rnd.Intn(len(directions))]
+
+	lead := height / 4
+	minX := length - 1
+	maxX := max(minX, width/4)
+	minY := length - 1
+	maxY := max(minY, height-length)
+	switch direction {
+	case constants.UP:
+		minY = min(max(minY, lead), maxY)
+	case constants.DOWN:
+		maxY = max(min(maxY, height-1-lead), minY)
+	}
+
+	return spawnPoint{
+		head: Position{
+			X: minX + rnd.Intn(maxX-minX+1),
+			Y: minY + rnd.Intn(maxY-minY+1),
+		},
+		direction: direction,
+	}
+}
+
+// mirrorSpawnPoint returns the opponent's spawn point for the given layout
+// The horizontal layout mirrors across the vertical center line; the others use
+// point symmetry about the board center
+func mirrorSpawnPoint(layout string, point spawnPoint, width, height int) spawnPoint {
+	mirrored := spawnPoint{
+		head:      Position{X: width - 1 - point.head.X, Y: height - 1 - point.head.Y},
+		direction: opposites[point.direction],
+	}
+	if layout != constants.SPAWN_DIAGONAL_CORNERS && layout != constants.SPAWN_RANDOM_SYMMETRIC {
+		mirrored.head.Y = point.head.Y
+	}
+	return mirrored
+}
+
+// snakeBody lays out a snake of the given length trailing behind its head, wrapping at the edges
+func snakeBody(point spawnPoint, length, width, height int) []Position {
+	dx, dy := 0, 0
+	switch point.direction {
+	case constants.UP:
+		dy = 1
+	case constants.DOWN:
+		dy = -1
+	case constants.LEFT:
+		dx = 1
+	case constants.RIGHT:
+		dx = -1
+	}
+
+	body := make([]Position, 0, length)
+	for i := range length {
+		body = append(body, Position{
+			X: ((point.head.X+dx*i)%width + width) % width,
+			Y: ((point.head.Y+dy*i)%height + height) % height,
+		})
+	}
+	return body
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+)
+
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type PlayerStatus struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Ready    bool   `json:"ready"`
+}
+
+type Snake struct {
+	ID        string                `json:"id"`
+	Body      []Position            `json:"body"`
+	Direction constants.Direction   `json:"direction"`
+	Turns     []constants.Direction `json:"-"` // Turn pending for the next tick; players get at most one
+	Color     string                `json:"color"`
+	Score     int                   `json:"score"`
+	Username  string                `json:"username,omitempty"`
+	Down      bool                  `json:"down,omitempty"` // Crashed and waiting to respawn; it stays where it crashed
+}
+
+type Food struct {
+	Position Position `json:"position"`
+}
+
+// RulesProfile describes the rules a game is played with
+type RulesProfile struct {
+	Name         string `json:"name"`
+	Wrap         bool   `json:"wrap"`
+	TickRateMs   int    `json:"tick_rate_ms"`
+	FoodCount    int    `json:"food_count"`
+	WinCondition string `json:"win_condition"`
+	TargetScore  int    `json:"target_score,omitempty"`
+	PowerUps     bool   `json:"power_ups"`
+	TieBreak     string `json:"tie_break,omitempty"`    // How a head-on collision is decided: "score" when empty, "tie", "length" or "sudden_death"
+	SpawnLayout  string `json:"spawn_layout,omitempty"` // Layout the snakes start in, such as "random_symmetric"; the server's configured one when empty
+	FoodSpawn    string `json:"food_spawn,omitempty"`   // How new food is placed: "random" when empty, or "fair" for cells as far from each head
+	RespawnMs    int    `json:"respawn_ms,omitempty"`   // Crashed snakes respawn after this long instead of ending the round; 0 for never
+}
+
+// Respawns reports whether crashing respawns a snake rather than ending the round
+func (r RulesProfile) Respawns() bool {
+	return r.RespawnMs > 0
+}
+
+// TickInterval returns the time between game ticks
+func (r RulesProfile) TickInterval() time.Duration {
+	if r.TickRateMs <= 0 {
+		return constants.TICK_RATE
+	}
+	return time.Duration(r.TickRateMs) * time.Millisecond
+}
+
+// Difficulty sets how fast a single player game starts and how quickly it speeds up
+// Every StageFood food eaten moves the game up a speed stage that ticks StageMs faster,
+// until it ticks every MinTickRateMs.
+type Difficulty struct {
+	Name          string `json:"name"`
+	TickRateMs    int    `json:"tick_rate_ms"`     // Starting tick rate; 0 keeps the rules' own
+	StageFood     int    `json:"stage_food"`       // Food eaten per speed stage
+	StageMs       int    `json:"stage_ms"`         // How much faster each stage ticks
+	MinTickRateMs int    `json:"min_tick_rate_ms"` // The fastest the game gets
+}
+
+// Turn is a turn a snake applied on a tick of a round
+type Turn struct {
+	Tick      int
+	Direction constants.Direction
+}
+
+// StageTickRate returns the tick rate at a speed stage of a game that started at start
+func (d Difficulty) StageTickRate(start, stage int) int {
+	if d.StageMs <= 0 || start <= d.MinTickRateMs {
+		return start
+	}
+	return max(d.MinTickRateMs, start-stage*d.StageMs)
+}
+
+// CampaignLevel is one level of the single player campaign: a board, a speed and the score
+// that clears it
+type CampaignLevel struct {
+	Level       int        `json:"level"`
+	Name        string     `json:"name"`
+	TargetScore int        `json:"target_score"`
+	TickRateMs  int        `json:"tick_rate_ms"`
+	Wrap        bool       `json:"wrap"`
+	Obstacles   []Position `json:"obstacles"`
+}
+
+type GameState struct {
+	ID             string         `json:"id"`
+	Snakes         []Snake        `json:"snakes"`
+	Food           Food           `json:"food"`                // First item of Foods, kept for older clients
+	Foods          []Food         `json:"foods"`               // All food on the board
+	Obstacles      []Position     `json:"obstacles,omitempty"` // Cells no snake may enter; fixed for the whole game
+	Status         string         `json:"status"`              // "waiting", "countdown", "playing", "paused", "finished"
+	Tick           int            `json:"tick"`                // Ticks simulated in the current round; moves name the one they were sent after
+	Countdown      int            `json:"countdown"`
+	Winner         string         `json:"winner,omitempty"`
+	Players        []PlayerStatus `json:"players,omitempty"`
+	IsSinglePlayer bool           `json:"is_single_player,omitempty"`
+	HotSeat        bool           `json:"hot_seat,omitempty"`    // Both snakes are steered from one connection
+	Coop           bool           `json:"coop,omitempty"`        // The snakes of a hot-seat game play as a team
+	Practice       bool           `json:"practice,omitempty"`    // A practice game against a server bot, nothing is recorded
+	TeamScore      int            `json:"team_score,omitempty"`  // Food eaten by a co-op team
+	Difficulty     string         `json:"difficulty,omitempty"`  // Difficulty of a single player game
+	SpeedStage     int            `json:"speed_stage,omitempty"` // Speed stages a single player game went up
+	CampaignLevel  int            `json:"campaign_level,omitempty"`
+	Variant        string         `json:"variant,omitempty"`      // endless, score_attack or daily, for a single player game outside the campaign
+	TimeLeft       int            `json:"time_left,omitempty"`    // Seconds left in a score attack
+	TieBreak       string         `json:"tie_break,omitempty"`    // Tie-break rule applied after a head-on collision this round
+	SuddenDeath    bool           `json:"sudden_death,omitempty"` // The round was replayed after a head-on collision; the first snake to eat wins
+}
+
+// Clone returns a deep copy of the state that stays consistent while the game keeps changing
+// Broadcasts serialize clones taken under the game lock, never the live state
+func (s *GameState) Clone() *GameState {
+	if s == nil {
+		return nil
+	}
+
+	clone := *s
+	clone.Snakes = make([]Snake, len(s.Snakes))
+	for i, snake := range s.Snakes {
+		snake.Body = slices.Clone(snake.Body)
+		snake.Turns = slices.Clone(snake.Turns)
+		clone.Snakes[i] = snake
+	}
+	clone.Foods = slices.Clone(s.Foods)
+	clone.Players = slices.Clone(s.Players)
+	return &clone
+}
@@ -0,0 +1,176 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Known feature flags for experimental modes
+const (
+	PowerUps            = "power_ups"
+	P2PHosting          = "p2p_hosting"
+	LatencyCompensation = "latency_compensation"
+)
+
+// defaults lists every known flag and its value when not configured
+var defaults = map[string]bool{
+	PowerUps:            false,
+	P2PHosting:          false,
+	LatencyCompensation: false,
+}
+
+var (
+	mu    sync.RWMutex
+	flags = maps.Clone(defaults)
+)
+
+// Load applies base (typically the "features" section of the config file) and then
+// the flags from FEATURE_FLAGS_FILE (JSON object) and FEATURE_FLAGS
+// FEATURE_FLAGS is a comma-separated list where "name" or "name=true" enables a flag
+// and "-name" or "name=false" disables it; it takes precedence over both files
+// An unknown flag name in any of them is an error.
+func Load(base map[string]bool) error {
+	for name := range base {
+		if !Known(name) {
+			return fmt.Errorf("unknown feature flag %q", name)
+		}
+	}
+	values := maps.Clone(defaults)
+	maps.Copy(values, base)
+
+	if path := os.Getenv("FEATURE_FLAGS_FILE"); path != "" {
+		fileValues, err := loadFile(path)
+		if err != nil {
+			return err
+		}
+		maps.Copy(values, fileValues)
+	}
+
+	if env := os.Getenv("FEATURE_FLAGS"); env != "" {
+		envValues, err := Parse(env)
+		if err != nil {
+			return err
+		}
+		maps.Copy(values, envValues)
+	}
+
+	Set(values)
+	return nil
+}
+
+// loadFile reads flags from a JSON file of the form {"power_ups": true}
+func loadFile(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading feature flags file: %w", err)
+	}
+
+	var values map[string]bool
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing feature flags file %s: %w", path, err)
+	}
+	for name := range values {
+		if !Known(name) {
+			return nil, fmt.Errorf("unknown feature flag %q in %s", name, path)
+		}
+	}
+	return values, nil
+}
+
+// Parse parses a comma-separated flag list such as "power_ups,-p2p_hosting,latency_compensation=false"
+func Parse(list string) (map[string]bool, error) {
+	values := make(map[string]bool)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(entry, "=")
+		enabled := true
+		if strings.HasPrefix(name, "-") {
+			name = strings.TrimPrefix(name, "-")
+			enabled = false
+		}
+		if hasValue {
+			switch strings.ToLower(strings.TrimSpace(value)) {
+			case "1", "true", "on", "yes":
+				enabled = true
+			case "0", "false", "off", "no":
+				enabled = false
+			default:
+				return nil, fmt.Errorf("invalid value %q for feature flag %s", value, name)
+			}
+		}
+		name = strings.TrimSpace(name)
+		if !Known(name) {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		values[name] = enabled
+	}
+	return values, nil
+}
+
+// Known reports whether name is a recognized feature flag
+func Known(name string) bool {
+	_, exists := defaults[name]
+	return exists
+}
+
+// Set replaces the current flag values
+// Flags not present in values keep their default
+func Set(values map[string]bool) {
+	next := maps.Clone(defaults)
+	maps.Copy(next, values)
+
+	mu.Lock()
+	flags = next
+	mu.Unlock()
+}
+
+// Enabled reports whether a feature flag is turned on
+func Enabled(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return flags[name]
+}
+
+// Snapshot returns a copy of all flag values
+func Snapshot() map[string]bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return maps.Clone(flags)
+}
+
+// EnabledList returns the names of all enabled flags in sorted order
+func EnabledList() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	enabled := make([]string, 0, len(flags))
+	for name, on := range flags {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	sort.Strings(enabled)
+	return enabled
+}
+
+// Require wraps a handler so it responds 404 while the given feature is disabled
+// The flag is checked on every request so toggling it takes effect immediately
+func Require(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled(name) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
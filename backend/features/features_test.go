@@ -0,0 +1,50 @@
+package features
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{"", map[string]bool{}, false},
+		{"power_ups", map[string]bool{PowerUps: true}, false},
+		{"-power_ups", map[string]bool{PowerUps: false}, false},
+		{"power_ups=false", map[string]bool{PowerUps: false}, false},
+		{"power_ups=on, -p2p_hosting", map[string]bool{PowerUps: true, P2PHosting: false}, false},
+		{"power_ups=maybe", nil, true},
+		{"power_up", nil, true},
+		{"-power_up", nil, true},
+		{"power_up=false", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, want error %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !maps.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownNames(t *testing.T) {
+	t.Setenv("FEATURE_FLAGS", "")
+	t.Setenv("FEATURE_FLAGS_FILE", "")
+	defer Set(nil)
+
+	if err := Load(map[string]bool{"power_up": true}); err == nil {
+		t.Error("Load accepted an unknown flag name")
+	}
+	if err := Load(map[string]bool{PowerUps: true}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !Enabled(PowerUps) {
+		t.Errorf("%s not enabled after Load", PowerUps)
+	}
+}
@@ -0,0 +1,190 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+var (
+	abandonedRounds   = metrics.NewCounter("snake_rated_rounds_abandoned_total", "Rated rounds a player left before they finished, recorded as their loss")
+	reconnectsAwaited = metrics.NewCounterVec("snake_reconnects_awaited_total", "Players who disconnected from a rated round and were waited for, by outcome", "outcome")
+)
+
+// ratedGame reports whether a game's rounds are recorded for ratings and recent opponents
+func ratedGame(game *models.Game) bool {
+	return game.Player2 != nil && !game.IsSinglePlayer && !game.HotSeat && !game.Practice
+}
+
+// gamePlayer returns the player of a game with the given ID, nil if neither is; caller
+// must hold game.Mutex
+func gamePlayer(game *models.Game, playerID string) *models.Player {
+	switch {
+	case game.Player1.ID == playerID:
+		return game.Player1
+	case game.Player2 != nil && game.Player2.ID == playerID:
+		return game.Player2
+	}
+	return nil
+}
+
+// opponentOf returns the other player of a two player game, nil if there is none; caller
+// must hold game.Mutex
+func opponentOf(game *models.Game, playerID string) *models.Player {
+	switch {
+	case game.Player2 == nil:
+		return nil
+	case game.Player1.ID == playerID:
+		return game.Player2
+	}
+	return game.Player1
+}
+
+// awaitReconnect keeps a rated round running for the reconnect grace period after one of
+// its players disconnected, and reports whether it does
+// Their snake goes on without them. A player who is back in time picks the round up;
+// otherwise they leave the game as if they had disconnected right away, and forfeit the
+// round if it is still being played.
+func (gm *Manager) awaitReconnect(game *models.Game, playerID string) bool {
+	grace := time.Duration(gm.Config().Gameplay.ReconnectGraceSeconds) * time.Second
+	if grace <= 0 || gm.kicked(playerID) {
+		return false
+	}
+
+	game.Mutex.Lock()
+	player := gamePlayer(game, playerID)
+	if player == nil || !ratedGame(game) || !game.IsActive() {
+		game.Mutex.Unlock()
+		return false
+	}
+	player.Detach()
+	deadline := gm.clock.Now().Add(grace)
+	if game.Reconnects == nil {
+		game.Reconnects = make(map[string]time.Time)
+	}
+	game.Reconnects[playerID] = deadline
+	opponent := opponentOf(game, playerID)
+	game.Mutex.Unlock()
+
+	slog.Info("Waiting for a player to reconnect", "game_id", game.ID, "player_id", playerID, "grace", grace)
+	gm.sendMessage(opponent, constants.MSG_PLAYER_RECONNECTING, map[string]any{
+		"game_id":       game.ID,
+		"player":        player.Username,
+		"grace_seconds": int(math.Ceil(grace.Seconds())),
+	})
+
+	go func() {
+		select {
+		case <-gm.clock.After(grace):
+			gm.expireReconnect(game, playerID, deadline)
+		case <-game.Context().Done():
+		}
+	}()
+	return true
+}
+
+// expireReconnect takes a player who didn't reconnect by deadline out of the game
+func (gm *Manager) expireReconnect(game *models.Game, playerID string, deadline time.Time) {
+	game.Mutex.Lock()
+	awaited, waiting := game.Reconnects[playerID]
+	expired := waiting && awaited.Equal(deadline)
+	if expired {
+		delete(game.Reconnects, playerID)
+	}
+	game.Mutex.Unlock()
+	if !expired {
+		return
+	}
+
+	reconnectsAwaited.Inc("expired")
+	gm.disconnectFromGame(game, playerID)
+}
+
+// resumeAwaited tells the opponent that a player the game was waiting for is back; caller
+// must have attached the player's connection to the game
+func (gm *Manager) resumeAwaited(game *models.Game, player *models.Player) {
+	game.Mutex.Lock()
+	_, waiting := game.Reconnects[player.ID]
+	delete(game.Reconnects, player.ID)
+	opponent := opponentOf(game, player.ID)
+	game.Mutex.Unlock()
+	if !waiting {
+		return
+	}
+
+	reconnectsAwaited.Inc("reconnected")
+	slog.Info("Player reconnected in time", "game_id", game.ID, "player_id", player.ID)
+	gm.sendMessage(opponent, constants.MSG_PLAYER_RECONNECTED, map[string]any{
+		"game_id": game.ID,
+		"player":  player.Username,
+	})
+}
+
+// abandonRound records that a player left a rated round before it finished; caller must
+// hold game.Mutex and end the round
+func abandonRound(game *models.Game, playerID string) {
+	if ratedGame(game) && game.IsActive() {
+		game.Abandoned = playerID
+	}
+}
+
+// abandonWinner returns the winner of a round a player left before it finished: their
+// opponent, or winner unchanged when nobody abandoned it
+func abandonWinner(game *models.Game, winner string) string {
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+	if winner != "disconnect" || game.Abandoned == "" {
+		return winner
+	}
+	if opponent := opponentOf(game, game.Abandoned); opponent != nil {
+		return opponent.ID
+	}
+	return winner
+}
+
+// noteAbandon counts a rated round the player abandoned, and logs when that bars them from
+// rated play
+func (gm *Manager) noteAbandon(player *models.Player) {
+	abandonedRounds.Inc()
+	if until, barred := gm.rankedBar(player); barred {
+		rate, rounds := gm.profiles.DisconnectRate(player.ID)
+		slog.Warn("Player barred from rated play for abandoning rounds", "player_id", player.ID,
+			"username", player.Username, "disconnect_rate", rate, "rounds", rounds, "until", until)
+	}
+}
+
+// rankedBar returns until when a player is barred from rated play for abandoning too many
+// of their latest rounds, and false when they aren't; bots never are
+func (gm *Manager) rankedBar(player *models.Player) (time.Time, bool) {
+	if gm.profiles == nil || player.Bot {
+		return time.Time{}, false
+	}
+	until := gm.profiles.BarredUntil(player.ID)
+	return until, gm.clock.Now().Before(until)
+}
+
+// refuseBarred tells a player barred from rated play that they can't play one yet, and
+// reports whether they were
+func (gm *Manager) refuseBarred(player *models.Player, gameID string) bool {
+	until, barred := gm.rankedBar(player)
+	if !barred {
+		return false
+	}
+	minutes := int(math.Ceil(gm.clock.Until(until).Minutes()))
+	data := map[string]any{
+		"code":    "RANKED_BARRED",
+		"message": fmt.Sprintf("You left too many games before they finished; you can play rated games again in %d min", minutes),
+		"until":   until,
+		"minutes": minutes,
+	}
+	if gameID != "" {
+		data["game_id"] = gameID
+	}
+	gm.sendMessage(player, constants.MSG_ERROR, data)
+	return true
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"time"
+
+	"github.com/bariiss/snake/backend/analytics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// emitGameCreated records a newly registered game
+func (gm *Manager) emitGameCreated(game *models.Game) {
+	properties := map[string]any{
+		"mode":  gameMode(game),
+		"rules": game.Rules.Name,
+	}
+	if game.Difficulty.Name != "" {
+		properties["difficulty"] = game.Difficulty.Name
+	}
+	if game.CampaignLevel > 0 {
+		properties["campaign_level"] = game.CampaignLevel
+	}
+	if game.Variant != "" {
+		properties["variant"] = game.Variant
+	}
+	gm.analytics.Emit(analytics.EventGameCreated, game.ID, game.Player1.ID, properties)
+}
+
+// emitMatchEnded records how long a finished round lasted, how much food each snake ate
+// and, for multiplayer, whether the round was a rematch
+// The rematch rate is the share of rematch_rate events with rematch set
+func (gm *Manager) emitMatchEnded(game *models.Game, winner string) {
+	if gm.analytics == nil {
+		return
+	}
+
+	game.Mutex.RLock()
+	startedAt := game.StartedAt
+	rematch := game.Rematch
+	mode := gameMode(game)
+	rules := game.Rules.Name
+	difficulty := game.Difficulty.Name
+	variant := game.Variant
+	scores := make(map[string]int, len(game.State.Snakes))
+	for _, snake := range game.State.Snakes {
+		scores[snake.ID] = snake.Score
+	}
+	game.Mutex.RUnlock()
+
+	if startedAt.IsZero() {
+		return
+	}
+
+	duration := map[string]any{
+		"mode":        mode,
+		"rules":       rules,
+		"winner":      winner,
+		"duration_ms": gm.clock.Since(startedAt).Milliseconds(),
+	}
+	if difficulty != "" {
+		duration["difficulty"] = difficulty
+	}
+	if variant != "" {
+		duration["variant"] = variant
+	}
+	gm.analytics.Emit(analytics.EventMatchDuration, game.ID, "", duration)
+	// Every food eaten scores one point; single player scores are ranked by variant and difficulty
+	for playerID, eaten := range scores {
+		eatenCount := map[string]any{"count": eaten}
+		if difficulty != "" {
+			eatenCount["difficulty"] = difficulty
+		}
+		if variant != "" {
+			eatenCount["variant"] = variant
+		}
+		gm.analytics.Emit(analytics.EventFoodEatenCount, game.ID, playerID, eatenCount)
+	}
+	if !game.IsSinglePlayer {
+		gm.analytics.Emit(analytics.EventRematchRate, game.ID, "", map[string]any{
+			"rematch": rematch,
+		})
+	}
+}
+
+// RecordDisconnect records why a player's connection ended and how long it lasted
+func (gm *Manager) RecordDisconnect(player *models.Player, connID, reason string, duration time.Duration) {
+	gm.analytics.Emit(analytics.EventDisconnectReason, "", player.ID, map[string]any{
+		"conn_id":     connID,
+		"reason":      reason,
+		"duration_ms": duration.Milliseconds(),
+	})
+}
+
+// gameMode names the kind of game for analytics
+func gameMode(game *models.Game) string {
+	switch {
+	case game.IsSinglePlayer:
+		return "single"
+	case game.Coop:
+		return "coop"
+	case game.Practice:
+		return "practice"
+	case game.HotSeat:
+		return "hot_seat"
+	}
+	return "multi"
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+)
+
+// Announcement severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// AnnouncementSeverities lists the severities an announcement may have
+var AnnouncementSeverities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
+// MaxAnnouncementLength is the longest announcement text, in characters
+const MaxAnnouncementLength = 280
+
+// ErrInvalidAnnouncement is returned by Announce for an empty or too long text, an unknown
+// severity or a negative lifetime
+var ErrInvalidAnnouncement = errors.New("invalid announcement")
+
+var announcementsSent = metrics.NewCounterVec("snake_announcements_total", "Announcements broadcast, by severity", "severity")
+
+// Announcement is a banner shown to every connected player and spectator
+// Only one announcement is shown at a time; a new one replaces it.
+type Announcement struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Severity  string    `json:"severity"`
+	SentAt    time.Time `json:"sent_at"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` // Zero when shown until replaced or cleared
+}
+
+// activeAt reports whether the announcement is still shown at now
+func (a *Announcement) activeAt(now time.Time) bool {
+	return a.ExpiresAt.IsZero() || now.Before(a.ExpiresAt)
+}
+
+// message returns the fields of the announcement message
+func (a *Announcement) message() map[string]any {
+	data := map[string]any{
+		"id":       a.ID,
+		"text":     a.Text,
+		"severity": a.Severity,
+		"sent_at":  a.SentAt,
+	}
+	if !a.ExpiresAt.IsZero() {
+		data["expires_at"] = a.ExpiresAt
+	}
+	return data
+}
+
+// Announce broadcasts a banner to every connected player and spectator, on every instance
+// The severity defaults to info. A ttl of zero shows the banner until it is replaced or
+// cleared; players connecting while it is shown receive it in the connected message.
+func (gm *Manager) Announce(text, severity string, ttl time.Duration) (Announcement, error) {
+	text = strings.TrimSpace(text)
+	if severity == "" {
+		severity = SeverityInfo
+	}
+	switch {
+	case text == "":
+		return Announcement{}, fmt.Errorf("%w: text is required", ErrInvalidAnnouncement)
+	case utf8.RuneCountInString(text) > MaxAnnouncementLength:
+		return Announcement{}, fmt.Errorf("%w: text is longer than %d characters", ErrInvalidAnnouncement, MaxAnnouncementLength)
+	case !slices.Contains(AnnouncementSeverities, severity):
+		return Announcement{}, fmt.Errorf("%w: severity must be one of %s", ErrInvalidAnnouncement, strings.Join(AnnouncementSeverities, ", "))
+	case ttl < 0:
+		return Announcement{}, fmt.Errorf("%w: expiry must not be negative", ErrInvalidAnnouncement)
+	}
+
+	now := gm.clock.Now().UTC()
+	announcement := Announcement{
+		ID:       uuid.New().String(),
+		Text:     text,
+		Severity: severity,
+		SentAt:   now,
+	}
+	if ttl > 0 {
+		announcement.ExpiresAt = now.Add(ttl)
+	}
+
+	announcementsSent.Inc(severity)
+	gm.showAnnouncement(announcement)
+	gm.publishAnnouncement(clusterAnnouncement, announcement)
+	return announcement, nil
+}
+
+// ClearAnnouncement takes down the announcement being shown, on every instance
+// Returns false if no announcement is shown.
+func (gm *Manager) ClearAnnouncement() (Announcement, bool) {
+	current, shown := gm.CurrentAnnouncement()
+	if !shown {
+		return Announcement{}, false
+	}
+	gm.hideAnnouncement(current.ID)
+	gm.publishAnnouncement(clusterAnnouncementCleared, current)
+	return current, true
+}
+
+// CurrentAnnouncement returns the announcement being shown, unless it expired
+func (gm *Manager) CurrentAnnouncement() (Announcement, bool) {
+	current := gm.announcement.Load()
+	if current == nil || !current.activeAt(gm.clock.Now()) {
+		return Announcement{}, false
+	}
+	return *current, true
+}
+
+// showAnnouncement makes an announcement the current one and sends it to the local players
+func (gm *Manager) showAnnouncement(announcement Announcement) {
+	gm.announcement.Store(&announcement)
+	gm.broadcast(gm.registeredPlayers(), constants.MSG_ANNOUNCEMENT, announcement.message())
+}
+
+// hideAnnouncement clears the announcement with the given ID if it is still the current one
+func (gm *Manager) hideAnnouncement(id string) {
+	current := gm.announcement.Load()
+	if current == nil || current.ID != id || !gm.announcement.CompareAndSwap(current, nil) {
+		return
+	}
+	gm.broadcast(gm.registeredPlayers(), constants.MSG_ANNOUNCEMENT_CLEARED, map[string]any{"id": id})
+}
+
+// publishAnnouncement relays an announcement or its removal to the other instances
+func (gm *Manager) publishAnnouncement(kind string, announcement Announcement) {
+	if gm.bus == nil {
+		return
+	}
+	payload, err := json.Marshal(announcement)
+	if err != nil {
+		slog.Error("Failed to encode announcement", "error", err)
+		return
+	}
+	gm.publish(topicAnnouncements, clusterEnvelope{Kind: kind, Payload: payload})
+}
+
+// applyRemoteAnnouncement shows or clears an announcement made on another instance
+func (gm *Manager) applyRemoteAnnouncement(envelope clusterEnvelope) {
+	var announcement Announcement
+	if err := json.Unmarshal(envelope.Payload, &announcement); err != nil {
+		slog.Warn("Ignoring malformed announcement", "instance", envelope.Instance, "error", err)
+		return
+	}
+	if envelope.Kind == clusterAnnouncementCleared {
+		gm.hideAnnouncement(announcement.ID)
+		return
+	}
+	gm.showAnnouncement(announcement)
+}
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+)
+
+// Anomalies the anti-cheat monitor reports: play no human could manage, filed as cheating
+// reports for moderators to review; nobody is banned automatically
+const (
+	anomalyReaction       = "inhuman_reaction"   // Moves arrive at the same moment after every tick
+	anomalyTurnStreak     = "tick_perfect_turns" // The snake turns on every tick, for minutes
+	anomalyCountdownMoves = "countdown_inputs"   // Moves pour in while the countdown runs
+)
+
+const (
+	// timingSample is how many of a player's latest moves are compared for their timing
+	timingSample = 60
+	// minTimingSpread is the standard deviation of the arrival of moves after a tick below which
+	// they are timed by a machine; people press keys at any point of a tick
+	minTimingSpread = 4 * time.Millisecond
+	// maxTurnStreak is how many ticks in a row a snake may turn on, two minutes at 100ms
+	maxTurnStreak = 1200
+	// maxCountdownMoves is how many moves a player may send during one countdown
+	maxCountdownMoves = 20
+)
+
+var anomaliesDetected = metrics.NewCounterVec("snake_anomalies_total", "Impossible play detected by the anti-cheat monitor and filed for review, by anomaly", "anomaly")
+
+// inputTiming returns how a player has been sending moves in a game; caller must hold
+// game.Mutex
+func inputTiming(game *models.Game, playerID string) *models.InputTiming {
+	if game.InputTimings == nil {
+		game.InputTimings = make(map[string]*models.InputTiming)
+	}
+	timing, exists := game.InputTimings[playerID]
+	if !exists {
+		timing = &models.InputTiming{Flagged: make(map[string]bool)}
+		game.InputTimings[playerID] = timing
+	}
+	return timing
+}
+
+// watchMoveTiming records how long after the latest tick a player's move arrived, and
+// flags a player whose latest moves all arrived at the same moment of a tick
+// Caller must hold game.Mutex.
+func (gm *Manager) watchMoveTiming(game *models.Game, player *models.Player) {
+	if game.TickedAt.IsZero() {
+		return
+	}
+	timing := inputTiming(game, player.ID)
+	timing.Offsets = append(timing.Offsets, gm.clock.Now().Sub(game.TickedAt))
+	if len(timing.Offsets) > timingSample {
+		timing.Offsets = timing.Offsets[len(timing.Offsets)-timingSample:]
+	}
+	if len(timing.Offsets) < timingSample {
+		return
+	}
+
+	var sum float64
+	for _, offset := range timing.Offsets {
+		sum += float64(offset)
+	}
+	mean := sum / timingSample
+	var squares float64
+	for _, offset := range timing.Offsets {
+		squares += (float64(offset) - mean) * (float64(offset) - mean)
+	}
+	spread := time.Duration(math.Sqrt(squares / timingSample))
+	if spread >= minTimingSpread {
+		return
+	}
+	gm.flagAnomaly(game, player, anomalyReaction,
+		fmt.Sprintf("%d moves in a row arrived %s after a tick, give or take %s", timingSample, time.Duration(mean).Round(time.Millisecond), spread.Round(time.Microsecond)),
+		map[string]float64{"moves": timingSample, "mean_ms": mean / float64(time.Millisecond), "spread_ms": float64(spread) / float64(time.Millisecond)})
+}
+
+// watchTurnStreaks counts the ticks in a row each player's snake turned on, and flags one
+// that turned on every tick for too long; caller must hold game.Mutex
+// Snakes the server steers are left out.
+func (gm *Manager) watchTurnStreaks(game *models.Game, inputs engine.Inputs) {
+	for _, player := range []*models.Player{game.Player1, game.Player2} {
+		if player == nil || (game.Autopilot != nil && player == game.Player2) {
+			continue
+		}
+		timing := inputTiming(game, player.ID)
+		if _, turned := inputs[player.ID]; !turned {
+			timing.TurnStreak = 0
+			continue
+		}
+		timing.TurnStreak++
+		if timing.TurnStreak == maxTurnStreak {
+			gm.flagAnomaly(game, player, anomalyTurnStreak,
+				fmt.Sprintf("The snake turned on each of %d ticks in a row, up to tick %d", maxTurnStreak, game.Ticks),
+				map[string]float64{"ticks": maxTurnStreak, "tick": float64(game.Ticks)})
+		}
+	}
+}
+
+// watchCountdownMove counts a move a player sent while the game's countdown runs, and flags
+// a player who keeps sending them
+func (gm *Manager) watchCountdownMove(game *models.Game, player *models.Player) {
+	game.Mutex.Lock()
+	defer game.Mutex.Unlock()
+	if game.Countdown == nil {
+		return
+	}
+	timing := inputTiming(game, player.ID)
+	if timing.Countdown != game.Countdown {
+		timing.Countdown, timing.CountdownMoves = game.Countdown, 0
+	}
+	timing.CountdownMoves++
+	if timing.CountdownMoves == maxCountdownMoves {
+		gm.flagAnomaly(game, player, anomalyCountdownMoves,
+			fmt.Sprintf("%d moves were sent during one countdown", maxCountdownMoves),
+			map[string]float64{"moves": maxCountdownMoves})
+	}
+}
+
+// flagAnomaly files a cheating report on a player for an anomaly, at most once per game;
+// caller must hold game.Mutex
+func (gm *Manager) flagAnomaly(game *models.Game, player *models.Player, anomaly, comment string, evidence map[string]float64) {
+	timing := inputTiming(game, player.ID)
+	if timing.Flagged[anomaly] {
+		return
+	}
+	timing.Flagged[anomaly] = true
+	anomaliesDetected.Inc(anomaly)
+	slog.Warn("Anomalous input detected", "game_id", game.ID, "player", player.Username, "anomaly", anomaly, "detail", comment)
+	if gm.reports == nil {
+		return
+	}
+
+	report := moderation.Report{
+		ReporterID:   moderation.SystemReporter,
+		ReporterName: "anti-cheat",
+		TargetID:     player.ID,
+		TargetName:   player.Username,
+		Category:     moderation.CategoryCheating,
+		Comment:      comment,
+		Game:         gameContext(game),
+		Anomaly:      anomaly,
+		Evidence:     evidence,
+	}
+	// Persisting can wait on storage, which the game's tick must not
+	go func() {
+		filed, err := gm.reports.File(report)
+		if err != nil {
+			slog.Error("Failed to persist report", "report_id", filed.ID, "error", err)
+		}
+	}()
+}
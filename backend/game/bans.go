@@ -0,0 +1,87 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/names"
+)
+
+// ErrBansUnavailable is returned when banning players without a bans store
+var ErrBansUnavailable = errors.New("bans are not available on this server")
+
+var banChanges = metrics.NewCounterVec("snake_bans_total", "Bans applied and lifted, by action", "action")
+
+// SetBans sets the store of banned players
+// Call before the server starts accepting connections; without it nobody is banned.
+func (gm *Manager) SetBans(bans *moderation.Bans) {
+	gm.bans = bans
+}
+
+// BanPlayer bans a player, who need not be connected, until the ban is lifted
+// A player connected to this instance is told why in a kicked message and disconnected, as
+// when kicked. From then on their token and username are refused when they connect.
+func (gm *Manager) BanPlayer(playerID, reason, by string) (moderation.Ban, error) {
+	if gm.bans == nil {
+		return moderation.Ban{}, ErrBansUnavailable
+	}
+	player, exists := gm.registeredPlayer(playerID)
+	var username string
+	if exists {
+		username = player.Username
+	}
+	ban, err := gm.bans.Ban(playerID, username, reason, by)
+	banChanges.Inc("ban")
+	if exists && player.InstanceID == "" {
+		gm.removeFromServer(player, map[string]any{
+			"code":    "BANNED",
+			"message": "You were banned from the server by an admin",
+			"reason":  reason,
+		})
+		player.Logger().Info("Player banned", "by", by, "reason", reason)
+	}
+	return ban, err
+}
+
+// LiftBan ends a player's ban
+func (gm *Manager) LiftBan(playerID, by string) (moderation.Ban, error) {
+	if gm.bans == nil {
+		return moderation.Ban{}, ErrBansUnavailable
+	}
+	ban, err := gm.bans.Lift(playerID, by)
+	if errors.Is(err, moderation.ErrNotBanned) {
+		return ban, err
+	}
+	banChanges.Inc("lift")
+	return ban, err
+}
+
+// Bans returns the bans in effect, newest first
+func (gm *Manager) Bans() []moderation.Ban {
+	if gm.bans == nil {
+		return []moderation.Ban{}
+	}
+	return gm.bans.List()
+}
+
+// BannedPlayer returns the ban keeping a player's token from connecting, false when they
+// have none
+func (gm *Manager) BannedPlayer(playerID string) (moderation.Ban, bool) {
+	if gm.bans == nil {
+		return moderation.Ban{}, false
+	}
+	return gm.bans.Banned(playerID)
+}
+
+// BannedUsername returns the ban keeping a username from being logged in with, false when
+// there is none
+func (gm *Manager) BannedUsername(username string) (moderation.Ban, bool) {
+	key := names.Key(username)
+	for _, ban := range gm.Bans() {
+		if ban.Username != "" && names.Key(ban.Username) == key {
+			return ban, true
+		}
+	}
+	return moderation.Ban{}, false
+}
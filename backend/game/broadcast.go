@@ -0,0 +1,202 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/bariiss/snake/backend/bitboard"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/reporting"
+)
+
+var staleFramesDropped = metrics.NewCounter(
+	"snake_stale_frames_dropped_total",
+	"Game updates replaced by a newer one before a lagging client received them",
+)
+
+var slowConsumerDisconnects = metrics.NewCounter(
+	"snake_slow_consumer_disconnects_total",
+	"Connections closed because their send buffer stayed full",
+)
+
+// outboundMessage is a message serialized once and shared by all of its recipients
+// The WebSocket form ({"type": ..., <fields>}) is built up front; the data channel form
+// ({"type": ..., "data": <fields>}) is only built if some recipient has an open P2P channel,
+// and the bitboard of a game update if some recipient speaks the bitboard subprotocol
+type outboundMessage struct {
+	msgType string
+	data    map[string]any
+	ws      []byte
+
+	p2pOnce sync.Once
+	p2p     []byte
+	p2pErr  error
+
+	boardOnce sync.Once
+	board     []byte
+}
+
+// maxPooledBufferSize keeps unusually large messages from pinning memory in the pool
+const maxPooledBufferSize = 64 << 10
+
+// encodeBuffers holds scratch buffers for serializing outbound messages
+var encodeBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// newOutboundMessage serializes a message for WebSocket delivery
+func newOutboundMessage(msgType string, data map[string]any) *outboundMessage {
+	jsonData, err := encodeMessage(msgType, data, false)
+	if err != nil {
+		slog.Error("Failed to encode message", "msg_type", msgType, "error", err)
+		reporting.Error("Failed to encode message", err, "msg_type", msgType)
+	}
+	return &outboundMessage{msgType: msgType, data: data, ws: jsonData}
+}
+
+// p2pPayload returns the data channel form of the message, serializing it on first use
+func (m *outboundMessage) p2pPayload() ([]byte, error) {
+	m.p2pOnce.Do(func() {
+		m.p2p, m.p2pErr = encodeMessage(m.msgType, m.data, true)
+	})
+	return m.p2p, m.p2pErr
+}
+
+// payloadFor returns the form of the message written to a connection: the bitboard of a game
+// update for one speaking the bitboard subprotocol, encoded on first use, and JSON otherwise
+func (m *outboundMessage) payloadFor(conn *models.Connection) []byte {
+	if conn == nil || conn.Subprotocol != constants.SUBPROTOCOL_BITBOARD_V1 || m.msgType != constants.MSG_GAME_UPDATE {
+		return m.ws
+	}
+	m.boardOnce.Do(func() {
+		if state, ok := m.data["data"].(*models.GameState); ok && state != nil {
+			m.board = bitboard.Encode(state)
+		}
+	})
+	if m.board == nil {
+		return m.ws
+	}
+	return m.board
+}
+
+// relayedPayload returns the form of a message serialized by another instance written to a
+// connection: a game update is decoded and laid out as a bitboard for a connection speaking
+// the bitboard subprotocol, and everything else is written as it came
+// Relayed messages are addressed to one player each, so nothing is decoded more than once.
+func relayedPayload(conn *models.Connection, msgType string, payload []byte) []byte {
+	if conn == nil || conn.Subprotocol != constants.SUBPROTOCOL_BITBOARD_V1 || msgType != constants.MSG_GAME_UPDATE {
+		return payload
+	}
+	var update struct {
+		Data *models.GameState `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &update); err != nil || update.Data == nil {
+		return payload
+	}
+	return bitboard.Encode(update.Data)
+}
+
+// encodeMessage writes {"type": msgType, <fields of data>} or, when nested is set,
+// {"type": msgType, "data": data} into a pooled buffer and returns a copy of the result
+// Fields are written in sorted order like encoding/json does for maps, without building
+// an intermediate map per message. A "type" field in data is dropped: clients dispatch on
+// the message type, so the data can't override it.
+func encodeMessage(msgType string, data map[string]any, nested bool) ([]byte, error) {
+	buf := encodeBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			encodeBuffers.Put(buf)
+		}
+	}()
+
+	enc := json.NewEncoder(buf)
+	// Encoder terminates every value with a newline; messages must stay on one line because
+	// the write pump batches queued messages separated by newlines
+	writeValue := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+
+	buf.WriteString(`{"type":`)
+	if err := writeValue(msgType); err != nil {
+		return nil, err
+	}
+
+	if nested {
+		buf.WriteString(`,"data":`)
+		if err := writeValue(data); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, key := range slices.Sorted(maps.Keys(data)) {
+			if key == "type" {
+				continue
+			}
+			buf.WriteByte(',')
+			if err := writeValue(key); err != nil {
+				return nil, err
+			}
+			buf.WriteByte(':')
+			if err := writeValue(data[key]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf.WriteByte('}')
+
+	// The result is shared by every recipient's queue, so it cannot alias the pooled buffer
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// deliver sends an already serialized message to one player
+func (gm *Manager) deliver(player *models.Player, msg *outboundMessage) {
+	if player == nil {
+		return
+	}
+
+	// For game updates: if P2P connection is active, skip WebSocket and send only via P2P
+	// For other messages: send via WebSocket (lobby/matchmaking/signaling)
+	hasP2PConnection := gm.WebRTCManager != nil && msg.msgType == constants.MSG_GAME_UPDATE &&
+		gm.WebRTCManager.HasOpenChannel(player.ID)
+	if !hasP2PConnection {
+		// Try WebSocket (for lobby/matchmaking/non-P2P game updates)
+		gm.enqueue(player, msg.msgType, msg.payloadFor(player.Connection()))
+	}
+
+	// Send via WebRTC/P2P if available
+	// For game updates with P2P: only send via P2P (skip WebSocket)
+	// For other messages: send via both WebSocket and P2P (if available)
+	if gm.WebRTCManager != nil {
+		gm.WebRTCManager.SendEncoded(player.ID, msg.p2pPayload)
+	}
+}
+
+// broadcast serializes a message once and sends it to every given player
+func (gm *Manager) broadcast(players []*models.Player, msgType string, data map[string]any) {
+	if len(players) == 0 {
+		return
+	}
+
+	// A message with text to show is sent in each player's language
+	if _, hasCode := data["code"]; hasCode {
+		for _, player := range players {
+			gm.sendMessage(player, msgType, data)
+		}
+		return
+	}
+
+	msg := newOutboundMessage(msgType, data)
+	for _, player := range players {
+		gm.deliver(player, msg)
+	}
+}
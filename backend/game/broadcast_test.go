@@ -0,0 +1,179 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/bariiss/snake/backend/bitboard"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// testFrame is a two snake game_update frame as broadcast mid-round
+func testFrame() *models.GameState {
+	return &models.GameState{
+		ID: "7b0d2f3e-5c1a-4a51-9d2e-3f1c9b8a6e42",
+		Snakes: []models.Snake{
+			{ID: "p1", Body: []models.Position{{X: 10, Y: 5}, {X: 9, Y: 5}, {X: 8, Y: 5}, {X: 7, Y: 5}}, Direction: constants.RIGHT, Color: "#FF0000", Score: 1, Username: "alice"},
+			{ID: "p2", Body: []models.Position{{X: 30, Y: 20}, {X: 31, Y: 20}, {X: 32, Y: 20}}, Direction: constants.LEFT, Color: "#0000FF", Username: "bob"},
+		},
+		Food:    models.Food{Position: models.Position{X: 20, Y: 12}},
+		Foods:   []models.Food{{Position: models.Position{X: 20, Y: 12}}},
+		Status:  "playing",
+		Tick:    42,
+		Players: []models.PlayerStatus{{ID: "p1", Username: "alice", Ready: true}, {ID: "p2", Username: "bob", Ready: true}},
+	}
+}
+
+// marshalMessage is how messages were encoded before encodeMessage: the data copied into an
+// envelope map with the type, then marshalled
+func marshalMessage(msgType string, data map[string]any, nested bool) ([]byte, error) {
+	if nested {
+		return json.Marshal(map[string]any{"type": msgType, "data": data})
+	}
+	message := map[string]any{"type": msgType}
+	maps.Copy(message, data)
+	return json.Marshal(message)
+}
+
+func TestEncodeMessageMatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgType string
+		data    map[string]any
+	}{
+		{"no data", constants.MSG_LOBBY_STATUS, nil},
+		{"empty data", constants.MSG_LOBBY_STATUS, map[string]any{}},
+		{"game update", constants.MSG_GAME_UPDATE, map[string]any{"data": testFrame()}},
+		{"error", constants.MSG_ERROR, map[string]any{"code": "SERVER_FULL", "message": "Full <again> & \"soon\"", "limit": "max_games"}},
+		{"nested values", constants.MSG_GAME_FORCE_ENDED, map[string]any{"game_id": "g", "players": []string{"a", "b"}, "wins": map[string]int{"b": 2, "a": 1}, "spectators": 3, "over": true, "winner": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, nested := range []bool{false, true} {
+				got, err := encodeMessage(tt.msgType, tt.data, nested)
+				if err != nil {
+					t.Fatalf("encodeMessage(nested=%v): %v", nested, err)
+				}
+				want, err := marshalMessage(tt.msgType, tt.data, nested)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !sameJSON(t, got, want) {
+					t.Errorf("encodeMessage(nested=%v) = %s, want %s", nested, got, want)
+				}
+				if !bytes.HasPrefix(got, []byte(`{"type":`)) {
+					t.Errorf("encodeMessage(nested=%v) = %s, want the type first", nested, got)
+				}
+				if bytes.ContainsRune(got, '\n') {
+					t.Errorf("encodeMessage(nested=%v) = %q, want a single line", nested, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeMessageDropsTypeInData(t *testing.T) {
+	got, err := encodeMessage(constants.MSG_ERROR, map[string]any{"type": "spoofed", "code": "X"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"error","code":"X"}`; string(got) != want {
+		t.Errorf("encodeMessage = %s, want %s", got, want)
+	}
+}
+
+func TestEncodeMessageUnsupportedValue(t *testing.T) {
+	if _, err := encodeMessage(constants.MSG_ERROR, map[string]any{"bad": make(chan int)}, false); err == nil {
+		t.Error("encodeMessage with a channel value succeeded, want an error")
+	}
+}
+
+func TestEncodeMessageDoesNotAliasPool(t *testing.T) {
+	first, _ := encodeMessage(constants.MSG_ERROR, map[string]any{"code": "FIRST"}, false)
+	kept := string(first)
+	encodeMessage(constants.MSG_ERROR, map[string]any{"code": "SECOND"}, false)
+	if string(first) != kept {
+		t.Errorf("message changed to %s after the next encode, want %s", first, kept)
+	}
+}
+
+// sameJSON reports whether two JSON documents decode to the same value
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("decode %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("decode %s: %v", b, err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+// BenchmarkEncodeMessage encodes a two snake game_update frame, compared with the envelope
+// map and json.Marshal it replaced
+func BenchmarkEncodeMessage(b *testing.B) {
+	data := map[string]any{"data": testFrame()}
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := encodeMessage(constants.MSG_GAME_UPDATE, data, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := marshalMessage(constants.MSG_GAME_UPDATE, data, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestPayloadForBitboardConnections(t *testing.T) {
+	board := models.NewConnection(4)
+	board.Subprotocol = constants.SUBPROTOCOL_BITBOARD_V1
+	other := models.NewConnection(4)
+	other.Subprotocol = constants.SUBPROTOCOL_BITBOARD_V1
+	plain := models.NewConnection(4)
+	plain.Subprotocol = constants.SUBPROTOCOL_JSON_V2
+
+	msg := newOutboundMessage(constants.MSG_GAME_UPDATE, map[string]any{"data": testFrame()})
+	first, second := msg.payloadFor(board), msg.payloadFor(other)
+	if &first[0] != &second[0] {
+		t.Error("bitboard encoded once per recipient, want once per message")
+	}
+	decoded, err := bitboard.Decode(first)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if decoded.Tick != 42 || len(decoded.Snakes) != 2 {
+		t.Errorf("board = tick %d, %d snakes, want tick 42, 2 snakes", decoded.Tick, len(decoded.Snakes))
+	}
+	if got := msg.payloadFor(plain); !bytes.Equal(got, msg.ws) {
+		t.Errorf("JSON connection got %q, want the JSON form", got)
+	}
+	if got := msg.payloadFor(nil); !bytes.Equal(got, msg.ws) {
+		t.Errorf("no connection got %q, want the JSON form", got)
+	}
+
+	chat := newOutboundMessage(constants.MSG_ERROR, map[string]any{"code": "X"})
+	if got := chat.payloadFor(board); !bytes.Equal(got, chat.ws) {
+		t.Errorf("bitboard connection got %q for an error, want the JSON form", got)
+	}
+
+	relayed := relayedPayload(board, constants.MSG_GAME_UPDATE, msg.ws)
+	if !bytes.Equal(relayed, first) {
+		t.Error("relayed game update differs from the local bitboard")
+	}
+	if got := relayedPayload(plain, constants.MSG_GAME_UPDATE, msg.ws); !bytes.Equal(got, msg.ws) {
+		t.Errorf("relayed update to a JSON connection = %q, want it as it came", got)
+	}
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// campaignLevels are the levels of the single player campaign, in the order they unlock
+// Each is faster and more crowded than the last. Obstacles stay clear of the middle row,
+// where the snake starts heading right.
+var campaignLevels = []models.CampaignLevel{
+	{Level: 1, Name: "First Steps", TargetScore: 5, TickRateMs: 120, Wrap: true},
+	{Level: 2, Name: "Pillars", TargetScore: 8, TickRateMs: 110, Wrap: true, Obstacles: cells(
+		block(9, 7, 2, 2), block(29, 7, 2, 2), block(9, 21, 2, 2), block(29, 21, 2, 2),
+	)},
+	{Level: 3, Name: "Corridors", TargetScore: 10, TickRateMs: 100, Wrap: true, Obstacles: cells(
+		hline(7, 8, 31), hline(22, 8, 31),
+	)},
+	{Level: 4, Name: "Walled In", TargetScore: 12, TickRateMs: 95, Obstacles: cells(
+		vline(10, 5, 10), vline(10, 19, 24), vline(29, 5, 10), vline(29, 19, 24),
+	)},
+	{Level: 5, Name: "Crossroads", TargetScore: 15, TickRateMs: 85, Wrap: true, Obstacles: cells(
+		vline(20, 0, 9), vline(20, 21, 29), hline(7, 0, 9), hline(22, 30, 39),
+	)},
+	{Level: 6, Name: "Maze", TargetScore: 20, TickRateMs: 75, Obstacles: cells(
+		hline(6, 5, 17), hline(6, 22, 34), hline(23, 5, 17), hline(23, 22, 34),
+		vline(8, 9, 13), vline(8, 17, 21), vline(31, 9, 13), vline(31, 17, 21),
+	)},
+}
+
+// hline returns the cells of a horizontal wall on row y from column x1 to x2
+func hline(y, x1, x2 int) []models.Position {
+	return block(x1, y, x2-x1+1, 1)
+}
+
+// vline returns the cells of a vertical wall in column x from row y1 to y2
+func vline(x, y1, y2 int) []models.Position {
+	return block(x, y1, 1, y2-y1+1)
+}
+
+// block returns the cells of a width by height rectangle whose top left cell is x, y
+func block(x, y, width, height int) []models.Position {
+	positions := make([]models.Position, 0, width*height)
+	for dy := range height {
+		for dx := range width {
+			positions = append(positions, models.Position{X: x + dx, Y: y + dy})
+		}
+	}
+	return positions
+}
+
+// cells joins walls into the obstacles of a level
+func cells(walls ...[]models.Position) []models.Position {
+	var positions []models.Position
+	for _, wall := range walls {
+		positions = append(positions, wall...)
+	}
+	return positions
+}
+
+// campaignLevel returns a level of the campaign by number
+func campaignLevel(level int) (models.CampaignLevel, bool) {
+	if level < 1 || level > len(campaignLevels) {
+		return models.CampaignLevel{}, false
+	}
+	return campaignLevels[level-1], true
+}
+
+// sendCampaignError tells a player why a campaign request was refused
+func (gm *Manager) sendCampaignError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"message": message,
+		"code":    code,
+	})
+}
+
+// SendCampaign sends the player the campaign levels and how far they got
+func (gm *Manager) SendCampaign(player *models.Player) {
+	if gm.profiles == nil {
+		gm.sendCampaignError(player, "PROFILES_UNAVAILABLE", "The campaign is not available on this server")
+		return
+	}
+	gm.sendMessage(player, constants.MSG_CAMPAIGN, map[string]any{
+		"levels":  campaignLevels,
+		"cleared": gm.profiles.LevelsCleared(player.ID),
+	})
+}
+
+// StartCampaignLevel starts a single player game of a campaign level; the first level and
+// those following a cleared one are unlocked
+func (gm *Manager) StartCampaignLevel(player *models.Player, number int) {
+	if gm.profiles == nil {
+		gm.sendCampaignError(player, "PROFILES_UNAVAILABLE", "The campaign is not available on this server")
+		return
+	}
+	level, exists := campaignLevel(number)
+	if !exists {
+		gm.sendCampaignError(player, "INVALID_LEVEL", "The campaign has no such level")
+		return
+	}
+	if number > gm.profiles.LevelsCleared(player.ID)+1 {
+		gm.sendCampaignError(player, "LEVEL_LOCKED", "Clear the previous level first")
+		return
+	}
+
+	rules := models.RulesProfile{
+		Name:         constants.RULES_CAMPAIGN,
+		Wrap:         level.Wrap,
+		TickRateMs:   level.TickRateMs,
+		FoodCount:    1,
+		WinCondition: constants.WIN_SCORE_TARGET,
+		TargetScore:  level.TargetScore,
+	}
+	gm.startSinglePlayer(player, rules, func(game *models.Game) {
+		game.CampaignLevel = level.Level
+		game.State.CampaignLevel = level.Level
+		game.State.Obstacles = level.Obstacles
+	})
+}
+
+// recordCampaignLevel unlocks the next level for a player who reached the target score of
+// a campaign level, and sends them their progress
+func (gm *Manager) recordCampaignLevel(game *models.Game, player *models.Player, winner string, stats MatchStats) {
+	if game.CampaignLevel == 0 || gm.profiles == nil || winner != player.ID {
+		return
+	}
+	score := 0
+	if len(stats.Players) > 0 {
+		score = stats.Players[0].Score
+	}
+	unlocked, err := gm.profiles.ClearLevel(player.ID, game.CampaignLevel, score, gm.clock.Now())
+	if err != nil {
+		slog.Error("Failed to persist campaign level", "player_id", player.ID, "level", game.CampaignLevel, "error", err)
+	}
+	if unlocked {
+		slog.Info("Campaign level cleared", "player_id", player.ID, "level", game.CampaignLevel)
+	}
+	gm.sendMessage(player, constants.MSG_CAMPAIGN, map[string]any{
+		"levels":        campaignLevels,
+		"cleared":       gm.profiles.LevelsCleared(player.ID),
+		"level_cleared": game.CampaignLevel,
+	})
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"math"
+	"sync"
+
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Capacity limit names reported in SERVER_FULL errors and metrics
+const (
+	LimitMaxGames          = "max_games"
+	LimitMaxConnections    = "max_connections"
+	LimitMaxGamesPerPlayer = "max_games_per_player"
+	LimitMaxSpectators     = "max_spectators_per_game"
+)
+
+var capacityRejections = metrics.NewCounterVec(
+	"snake_capacity_rejections_total",
+	"Requests rejected because a capacity limit was reached",
+	"limit",
+)
+
+// Limits returns the capacity limits currently enforced by the manager
+func (gm *Manager) Limits() config.Limits {
+	return gm.Config().Limits
+}
+
+// RateLimit returns the message rate limit currently enforced on every connection
+func (gm *Manager) RateLimit() config.RateLimit {
+	return gm.Config().RateLimit
+}
+
+// AcquireConnection reserves a connection slot
+// Returns false if the connection limit has been reached
+func (gm *Manager) AcquireConnection() bool {
+	limit := int64(gm.Limits().MaxConnections)
+	for {
+		current := gm.connections.Load()
+		if limit > 0 && current >= limit {
+			capacityRejections.Inc(LimitMaxConnections)
+			return false
+		}
+		if gm.connections.CompareAndSwap(current, current+1) {
+			return true
+		}
+	}
+}
+
+// ReleaseConnection frees a connection slot reserved by AcquireConnection
+func (gm *Manager) ReleaseConnection() {
+	gm.connections.Add(-1)
+}
+
+// ConnectionCount returns the number of open connections
+func (gm *Manager) ConnectionCount() int {
+	return int(gm.connections.Load())
+}
+
+// ActiveGameCount returns the number of games that have not finished
+func (gm *Manager) ActiveGameCount() int {
+	return gm.openGames.count()
+}
+
+// openGames indexes the unfinished games and the players playing them, so capacity checks
+// and metrics don't scan every game
+// It is updated as a game's status moves in and out of "finished" and as games are removed.
+type openGames struct {
+	mu      sync.Mutex
+	games   map[string][]string // Game ID -> IDs of its players
+	players map[string]int      // Player ID -> unfinished games they play
+}
+
+// open counts a game as unfinished; caller must hold the game's Mutex unless the game isn't
+// registered yet
+// A game that has been closed is left out, so a step racing with its removal can't count it
+// again.
+func (o *openGames) open(game *models.Game) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, exists := o.games[game.ID]; exists || game.Context().Err() != nil {
+		return
+	}
+	if o.games == nil {
+		o.games = make(map[string][]string)
+		o.players = make(map[string]int)
+	}
+	playerIDs := []string{game.Player1.ID}
+	if game.Player2 != nil {
+		playerIDs = append(playerIDs, game.Player2.ID)
+	}
+	o.games[game.ID] = playerIDs
+	for _, playerID := range playerIDs {
+		o.players[playerID]++
+	}
+}
+
+// close stops counting a game that finished or was removed
+func (o *openGames) close(gameID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	playerIDs, exists := o.games[gameID]
+	if !exists {
+		return
+	}
+	delete(o.games, gameID)
+	for _, playerID := range playerIDs {
+		if o.players[playerID]--; o.players[playerID] <= 0 {
+			delete(o.players, playerID)
+		}
+	}
+}
+
+// count returns the number of unfinished games
+func (o *openGames) count() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.games)
+}
+
+// playerCount returns the number of unfinished games a player plays
+func (o *openGames) playerCount(playerID string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.players[playerID]
+}
+
+// gameCapacityExceededLocked checks whether a new game with the given players would exceed a limit
+// Returns the name of the exceeded limit, LimitMaintenance while the instance drains, or "" if
+// the game can be created (caller must hold gm.Mutex)
+func (gm *Manager) gameCapacityExceededLocked(playerIDs ...string) string {
+	if !gm.Ready() {
+		return LimitMaintenance
+	}
+	limits := gm.Limits()
+
+	if limits.MaxGames > 0 && gm.openGames.count() >= limits.MaxGames {
+		capacityRejections.Inc(LimitMaxGames)
+		return LimitMaxGames
+	}
+
+	if limits.MaxGamesPerPlayer <= 0 {
+		return ""
+	}
+	for _, playerID := range playerIDs {
+		if gm.openGames.playerCount(playerID) >= limits.MaxGamesPerPlayer {
+			capacityRejections.Inc(LimitMaxGamesPerPlayer)
+			return LimitMaxGamesPerPlayer
+		}
+	}
+	return ""
+}
+
+// sendServerFull notifies a player that a capacity limit prevented their request
+func (gm *Manager) sendServerFull(player *models.Player, limit string) {
+	if limit == LimitMaintenance && gm.refuseMaintenance(player, "game", "") {
+		return
+	}
+	message := "The server is at capacity. Please try again later."
+	if limit == LimitMaxGamesPerPlayer {
+		message = "Too many open games. Finish or cancel one before starting another."
+	}
+
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":    "SERVER_FULL",
+		"message": message,
+		"limit":   limit,
+	})
+}
+
+// registerCapacityMetrics exposes current usage and headroom for each capacity limit
+func (gm *Manager) registerCapacityMetrics() {
+	headroom := func(limit, used int) float64 {
+		if limit <= 0 {
+			return math.Inf(1)
+		}
+		return float64(max(limit-used, 0))
+	}
+
+	metrics.NewGaugeFunc("snake_games_active", "Number of unfinished games", func() float64 {
+		return float64(gm.ActiveGameCount())
+	})
+	metrics.NewGaugeFunc("snake_games_limit", "Maximum number of concurrent games (0 = unlimited)", func() float64 {
+		return float64(gm.Limits().MaxGames)
+	})
+	metrics.NewGaugeFunc("snake_games_headroom", "Games that can still be created before the limit", func() float64 {
+		return headroom(gm.Limits().MaxGames, gm.ActiveGameCount())
+	})
+	metrics.NewGaugeFunc("snake_connections_active", "Number of open client connections", func() float64 {
+		return float64(gm.ConnectionCount())
+	})
+	metrics.NewGaugeFunc("snake_connections_limit", "Maximum number of client connections (0 = unlimited)", func() float64 {
+		return float64(gm.Limits().MaxConnections)
+	})
+	metrics.NewGaugeFunc("snake_connections_headroom", "Connections that can still be accepted before the limit", func() float64 {
+		return headroom(gm.Limits().MaxConnections, gm.ConnectionCount())
+	})
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// MaxCastGames is how many games a caster follows at once
+const MaxCastGames = 8
+
+// CastGames subscribes the player to the state of tournament match games, alongside those
+// they cast already. Everything the games' players and spectators are sent reaches the
+// player as a cast_update naming the game, starting with each game's current state, so one
+// connection can feed an overlay showing several games. Only the casters, moderators and
+// organizer of a game's tournament may cast it.
+func (gm *Manager) CastGames(player *models.Player, gameIDs []string) {
+	games := make([]*models.Game, 0, len(gameIDs))
+	for _, gameID := range gameIDs {
+		game, exists := gm.lookupGame(gameID)
+		switch {
+		case !exists:
+			gm.sendCastError(player, gameID, "GAME_NOT_FOUND", "Game not found")
+			return
+		case game.Tournament == "":
+			gm.sendCastError(player, gameID, "NOT_A_TOURNAMENT_GAME", "Only tournament match games can be cast")
+			return
+		}
+		games = append(games, game)
+	}
+
+	gm.tournamentsMu.Lock()
+	for _, game := range games {
+		if t, exists := gm.tournaments[game.Tournament]; !exists || !t.Casts(player.ID) {
+			gm.tournamentsMu.Unlock()
+			gm.sendCastError(player, game.ID, "NOT_CASTER", "Only the casters, moderators and organizer of the tournament can cast its games")
+			return
+		}
+	}
+	gm.tournamentsMu.Unlock()
+
+	casting := gm.castGamesOf(player.ID)
+	for _, game := range games {
+		casting[game.ID] = true
+	}
+	if len(casting) > MaxCastGames {
+		gm.sendCastError(player, "", "TOO_MANY_CAST_GAMES", "A caster follows up to 8 games at once")
+		return
+	}
+	gm.tournamentsMu.Lock()
+	gm.tournamentCasts[player.ID] = casting
+	gm.tournamentsMu.Unlock()
+
+	tournamentEvents.Inc("cast")
+	for _, game := range games {
+		game.Mutex.Lock()
+		if game.Casters == nil {
+			game.Casters = make(map[string]*models.Player)
+		}
+		game.Casters[player.ID] = player
+		state := game.State.Clone()
+		game.Mutex.Unlock()
+		gm.sendMessage(player, constants.MSG_CAST_UPDATE, castUpdate(game, constants.MSG_GAME_UPDATE, map[string]any{"data": state}))
+	}
+	gm.sendCasting(player, casting)
+}
+
+// UncastGames stops sending the player the state of the given games, or of every game they
+// cast when none is given
+func (gm *Manager) UncastGames(player *models.Player, gameIDs []string) {
+	casting := gm.stopCasting(player.ID, gameIDs)
+	gm.sendCasting(player, casting)
+}
+
+// stopCasting unsubscribes a caster from games, or from all of them when none is given,
+// returning the games they still cast
+func (gm *Manager) stopCasting(playerID string, gameIDs []string) map[string]bool {
+	gm.tournamentsMu.Lock()
+	casting := gm.tournamentCasts[playerID]
+	if len(gameIDs) == 0 {
+		gameIDs = slices.Collect(maps.Keys(casting))
+	}
+	for _, gameID := range gameIDs {
+		delete(casting, gameID)
+	}
+	if len(casting) == 0 {
+		delete(gm.tournamentCasts, playerID)
+	}
+	remaining := maps.Clone(casting)
+	gm.tournamentsMu.Unlock()
+
+	for _, gameID := range gameIDs {
+		if game, exists := gm.lookupGame(gameID); exists {
+			game.Mutex.Lock()
+			delete(game.Casters, playerID)
+			game.Mutex.Unlock()
+		}
+	}
+	return remaining
+}
+
+// castGamesOf returns the games a caster casts that still exist
+func (gm *Manager) castGamesOf(playerID string) map[string]bool {
+	gm.tournamentsMu.Lock()
+	casting := maps.Clone(gm.tournamentCasts[playerID])
+	gm.tournamentsMu.Unlock()
+
+	live := make(map[string]bool, len(casting))
+	for gameID := range casting {
+		if _, exists := gm.lookupGame(gameID); exists {
+			live[gameID] = true
+		}
+	}
+	return live
+}
+
+// sendCasting tells a caster which games they cast
+func (gm *Manager) sendCasting(player *models.Player, casting map[string]bool) {
+	gameIDs := slices.Sorted(maps.Keys(casting))
+	if gameIDs == nil {
+		gameIDs = []string{}
+	}
+	gm.sendMessage(player, constants.MSG_CASTING, map[string]any{"game_ids": gameIDs})
+}
+
+// sendCastError answers a cast request with an error
+func (gm *Manager) sendCastError(player *models.Player, gameID, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"game_id": gameID,
+		"message": message,
+		"code":    code,
+	})
+}
+
+// castUpdate wraps a message sent to a game's participants for its casters
+func castUpdate(game *models.Game, msgType string, data map[string]any) map[string]any {
+	update := maps.Clone(data)
+	update["game_id"] = game.ID
+	update["tournament_id"] = game.Tournament
+	update["event"] = msgType
+	return update
+}
+
+// connectedCasters returns a game's casters that have an active connection
+func connectedCasters(game *models.Game) []*models.Player {
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+	casters := make([]*models.Player, 0, len(game.Casters))
+	for _, caster := range game.Casters {
+		if caster.Connected() {
+			casters = append(casters, caster)
+		}
+	}
+	return casters
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/profile"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// recordCircuit gives the entrants of a finished tournament the circuit points of their
+// placing, tells those online and awards the circuit titles they reached
+// The first tournament to finish in a new season crowns the leader of the previous one.
+func (gm *Manager) recordCircuit(snapshot tournament.Tournament) {
+	if gm.profiles == nil {
+		return
+	}
+	now := gm.clock.Now().UTC()
+	season := profile.Season(now)
+	if previous := gm.profiles.LatestSeason(); previous != "" && previous < season {
+		if standings := gm.profiles.CircuitStandings(previous); len(standings) > 0 {
+			slog.Info("Circuit season closed", "season", previous, "champion", standings[0].Username, "points", standings[0].Points)
+			gm.awardTitle(standings[0].PlayerID, "season_champion")
+		}
+	}
+
+	for _, entrant := range snapshot.Entrants {
+		if entrant.Finish == 0 {
+			continue
+		}
+		points, total, err := gm.profiles.RecordPlacement(snapshot.ID, entrant.ID, entrant.Username, entrant.Finish, now)
+		if err != nil {
+			slog.Error("Failed to persist circuit points", "player_id", entrant.ID, "tournament_id", snapshot.ID, "error", err)
+		}
+		tournamentEvents.Inc("circuit_points")
+		if player, online := gm.registeredPlayer(entrant.ID); online {
+			gm.sendMessage(player, constants.MSG_CIRCUIT_POINTS, map[string]any{
+				"tournament_id":   snapshot.ID,
+				"tournament_name": snapshot.Name,
+				"season":          season,
+				"finish":          entrant.Finish,
+				"points":          points,
+				"total":           total,
+			})
+		}
+		gm.awardAchievements(entrant.ID)
+	}
+}
+
+// SendCircuit sends the player the circuit standings of a season; an empty season is the
+// current one
+func (gm *Manager) SendCircuit(player *models.Player, season string) {
+	if gm.profiles == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "The tournament circuit is not available on this server",
+			"code":    "PROFILES_UNAVAILABLE",
+		})
+		return
+	}
+	if season == "" {
+		season = profile.Season(gm.clock.Now())
+	}
+	if _, err := time.Parse(profile.SeasonLayout, season); err != nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "A season is a month such as 2026-01",
+			"code":    "INVALID_SEASON",
+		})
+		return
+	}
+	gm.sendMessage(player, constants.MSG_CIRCUIT_STANDINGS, map[string]any{
+		"season":    season,
+		"standings": gm.profiles.CircuitStandings(season),
+	})
+}
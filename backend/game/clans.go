@@ -0,0 +1,257 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/bariiss/snake/backend/clans"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Reasons a player is no longer in a clan
+const (
+	clanLeft      = "left"      // The player left
+	clanRemoved   = "removed"   // An officer or the leader removed the player
+	clanDisbanded = "disbanded" // The last member left
+)
+
+var clanEvents = metrics.NewCounterVec("snake_clan_events_total", "Clan changes, by event", "event")
+
+// clanErrorCodes maps refused clan changes to the codes sent to players
+var clanErrorCodes = map[error]string{
+	clans.ErrInvalidTag:     "INVALID_CLAN",
+	clans.ErrInvalidName:    "INVALID_CLAN",
+	clans.ErrTagTaken:       "CLAN_TAG_TAKEN",
+	clans.ErrNotFound:       "CLAN_NOT_FOUND",
+	clans.ErrAlreadyInClan:  "ALREADY_IN_CLAN",
+	clans.ErrNotInClan:      "NOT_IN_CLAN",
+	clans.ErrNotOfficer:     "NOT_CLAN_OFFICER",
+	clans.ErrNotLeader:      "NOT_CLAN_LEADER",
+	clans.ErrOutranked:      "NOT_CLAN_OFFICER",
+	clans.ErrNotMember:      "NOT_CLAN_MEMBER",
+	clans.ErrInvalidRole:    "INVALID_CLAN_ROLE",
+	clans.ErrClanFull:       "CLAN_FULL",
+	clans.ErrInviteNotFound: "CLAN_INVITE_NOT_FOUND",
+}
+
+// SetClans sets the clan store
+// Call before the server starts accepting connections; without it clan changes are refused
+func (gm *Manager) SetClans(c *clans.Clans) {
+	gm.clans = c
+}
+
+// Clan returns the player's clan, for the connected message
+func (gm *Manager) Clan(playerID string) (clans.Clan, bool) {
+	if gm.clans == nil {
+		return clans.Clan{}, false
+	}
+	return gm.clans.Of(playerID)
+}
+
+// CreateClan founds a clan led by the player
+func (gm *Manager) CreateClan(player *models.Player, tag, name string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	clan, err := gm.clans.Create(tag, name, player.ID, player.Username)
+	if gm.refuseClanChange(player, err) {
+		return
+	}
+	clanEvents.Inc("created")
+	gm.sendClanUpdate(clan)
+	gm.refreshClanTags(player.ID)
+}
+
+// InviteToClan invites an online player, by username, to the player's clan
+func (gm *Manager) InviteToClan(player *models.Player, username string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	target, _ := gm.sendInvite(player, username, invite{
+		msgType: constants.MSG_CLAN_INVITE,
+		refuse:  func(code, message string) { gm.sendClanError(player, code, message) },
+		record: func(target *models.Player) (map[string]any, string, string) {
+			clan, err := gm.clans.Invite(player.ID, target.ID)
+			if errors.Is(err, clans.ErrAlreadyInClan) {
+				return nil, "ALREADY_IN_CLAN", target.Username + " is already in a clan"
+			}
+			if code, message, refused := clanRefusal(player, err); refused {
+				return nil, code, message
+			}
+			return map[string]any{"tag": clan.Tag, "name": clan.Name, "members": len(clan.Members)}, "", ""
+		},
+	})
+	if target != nil {
+		clanEvents.Inc("invited")
+	}
+}
+
+// AcceptClanInvite joins the clan that invited the player
+func (gm *Manager) AcceptClanInvite(player *models.Player, tag string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	clan, err := gm.clans.Accept(tag, player.ID, player.Username)
+	if gm.refuseClanChange(player, err) {
+		return
+	}
+	clanEvents.Inc("joined")
+	gm.sendClanUpdate(clan)
+	gm.refreshClanTags(player.ID)
+}
+
+// DeclineClanInvite turns down a clan invite
+func (gm *Manager) DeclineClanInvite(player *models.Player, tag string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	gm.clans.Decline(tag, player.ID)
+}
+
+// LeaveClan takes the player out of their clan
+func (gm *Manager) LeaveClan(player *models.Player) {
+	if !gm.checkClans(player) {
+		return
+	}
+	clan, err := gm.clans.Leave(player.ID)
+	if gm.refuseClanChange(player, err) {
+		return
+	}
+	reason := clanLeft
+	if len(clan.Members) == 0 {
+		reason = clanDisbanded
+	}
+	clanEvents.Inc(reason)
+	gm.sendMessage(player, constants.MSG_CLAN_LEFT, map[string]any{"tag": clan.Tag, "reason": reason})
+	gm.sendClanUpdate(clan)
+	gm.refreshClanTags(player.ID)
+}
+
+// RemoveFromClan takes a member out of the player's clan
+func (gm *Manager) RemoveFromClan(player *models.Player, targetID string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	clan, removed, err := gm.clans.Remove(player.ID, targetID)
+	if gm.refuseClanChange(player, err) {
+		return
+	}
+	clanEvents.Inc(clanRemoved)
+	if target, online := gm.registeredPlayer(removed.PlayerID); online {
+		gm.sendMessage(target, constants.MSG_CLAN_LEFT, map[string]any{"tag": clan.Tag, "reason": clanRemoved})
+	}
+	gm.sendClanUpdate(clan)
+	gm.refreshClanTags(removed.PlayerID)
+}
+
+// SetClanRole changes the role of a member of the player's clan
+func (gm *Manager) SetClanRole(player *models.Player, targetID, role string) {
+	if !gm.checkClans(player) {
+		return
+	}
+	clan, err := gm.clans.SetRole(player.ID, targetID, role)
+	if gm.refuseClanChange(player, err) {
+		return
+	}
+	clanEvents.Inc("role_changed")
+	gm.sendClanUpdate(clan)
+}
+
+// SendClanScoreboard sends the player every clan's record against the other clans
+func (gm *Manager) SendClanScoreboard(player *models.Player) {
+	board := clans.Scoreboard{Standings: []clans.Standing{}, Matchups: []clans.Matchup{}}
+	if gm.clans != nil {
+		board = gm.clans.Scoreboard()
+	}
+	gm.sendMessage(player, constants.MSG_CLAN_SCOREBOARD, map[string]any{
+		"standings": board.Standings,
+		"matchups":  board.Matchups,
+	})
+}
+
+// recordClanRound adds the round that just ended to the clan scoreboard
+// Only multiplayer rounds between members of different clans count.
+func (gm *Manager) recordClanRound(game *models.Game, player1, player2 *models.Player, winner string) {
+	if gm.clans == nil || player2 == nil || game.IsSinglePlayer || game.HotSeat || game.Practice {
+		return
+	}
+	winner = abandonWinner(game, winner)
+	if winner == "tie" {
+		winner = ""
+	}
+	if err := gm.clans.RecordRound(player1.ID, player2.ID, winner); err != nil {
+		slog.Error("Failed to persist clan round", "game_id", game.ID, "error", err)
+	}
+}
+
+// clanTag returns the tag of the player's clan, empty if they are in none
+func (gm *Manager) clanTag(playerID string) string {
+	if gm.clans == nil {
+		return ""
+	}
+	return gm.clans.TagOf(playerID)
+}
+
+// sendClanUpdate sends a clan's roster to its online members
+func (gm *Manager) sendClanUpdate(clan clans.Clan) {
+	members := make([]*models.Player, 0, len(clan.Members))
+	for _, member := range clan.Members {
+		if player, online := gm.registeredPlayer(member.PlayerID); online {
+			members = append(members, player)
+		}
+	}
+	gm.broadcast(members, constants.MSG_CLAN_UPDATE, map[string]any{"clan": clan})
+}
+
+// refreshClanTags shows a player's new clan tag in the lobby if they are in it
+func (gm *Manager) refreshClanTags(playerID string) {
+	if _, inLobby := gm.Lobby.Get(playerID); inLobby {
+		gm.BroadcastLobbyStatus()
+	}
+}
+
+// checkClans answers the player when the server keeps no clans
+func (gm *Manager) checkClans(player *models.Player) bool {
+	if gm.clans == nil {
+		gm.sendClanError(player, "CLANS_UNAVAILABLE", "Clans are not available on this server")
+		return false
+	}
+	return true
+}
+
+// refuseClanChange answers the player when a clan change was refused, reporting whether
+// it was
+func (gm *Manager) refuseClanChange(player *models.Player, err error) bool {
+	code, message, refused := clanRefusal(player, err)
+	if refused {
+		gm.sendClanError(player, code, message)
+	}
+	return refused
+}
+
+// clanRefusal returns the code and message to answer a refused clan change with, and
+// whether it was refused; a change that only failed to persist is logged and goes ahead
+func clanRefusal(player *models.Player, err error) (string, string, bool) {
+	if err == nil {
+		return "", "", false
+	}
+	for refusal, code := range clanErrorCodes {
+		if errors.Is(err, refusal) {
+			message := err.Error()
+			return code, strings.ToUpper(message[:1]) + message[1:], true
+		}
+	}
+	slog.Error("Failed to persist clan change", "player_id", player.ID, "error", err)
+	return "", "", false
+}
+
+// sendClanError answers a clan message with an error
+func (gm *Manager) sendClanError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"message": message,
+		"code":    code,
+	})
+}
@@ -0,0 +1,536 @@
+package game
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/bus"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/reporting"
+)
+
+// Bus topics shared by all instances
+const (
+	topicPresence       = "snake:presence"
+	topicGames          = "snake:games"
+	topicAnnouncements  = "snake:announcements"
+	topicInstancePrefix = "snake:instance:" // Followed by the instance ID
+)
+
+// Envelope kinds
+const (
+	clusterPresence = "presence" // Connected players of an instance
+	clusterGames    = "games"    // Games hosted by an instance
+	clusterDeliver  = "deliver"  // Message for a player connected to the receiving instance
+	clusterInput    = "input"    // Message from a remote player for a game hosted by the receiving instance
+
+	clusterAnnouncement        = "announcement"         // Announcement made by an admin
+	clusterAnnouncementCleared = "announcement_cleared" // Announcement taken down by an admin
+)
+
+const (
+	clusterHeartbeat   = 5 * time.Second
+	clusterInstanceTTL = 3 * clusterHeartbeat
+)
+
+// clusterEnvelope is the message exchanged between instances over the bus
+type clusterEnvelope struct {
+	Kind     string           `json:"kind"`
+	Instance string           `json:"instance"`
+	Region   string           `json:"region"`
+	Players  []remotePlayer   `json:"players,omitempty"`
+	Games    []map[string]any `json:"games,omitempty"`
+	PlayerID string           `json:"player_id,omitempty"`
+	Username string           `json:"username,omitempty"`
+	GameID   string           `json:"game_id,omitempty"`
+	MsgType  string           `json:"msg_type,omitempty"`
+	Payload  json.RawMessage  `json:"payload,omitempty"`
+}
+
+// remotePlayer is a player connected to another instance
+type remotePlayer struct {
+	ID       string    `json:"id"`
+	Username string    `json:"username"`
+	Ready    bool      `json:"ready"`
+	JoinedAt time.Time `json:"joined_at"`
+	InLobby  bool      `json:"in_lobby"`
+	InGame   bool      `json:"in_game,omitempty"`
+	Bot      bool      `json:"bot,omitempty"`
+	Title    string    `json:"title,omitempty"` // Name of the equipped title
+	Clan     string    `json:"clan,omitempty"`  // Tag of the player's clan
+}
+
+// remoteInstance is the last known state of another instance
+type remoteInstance struct {
+	region    string
+	players   map[string]remotePlayer
+	games     []map[string]any
+	updatedAt time.Time
+}
+
+// cluster tracks other instances and the local stand-ins for their players
+type cluster struct {
+	mu        sync.RWMutex
+	instances map[string]*remoteInstance
+	proxies   map[string]*models.Player // Remote players taking part in local games
+	gameHosts map[string]string         // Remote game ID -> hosting instance
+}
+
+// SetBus connects the manager to other instances so that lobbies, games lists and
+// spectator streams are shared and players on different instances can challenge each other
+func (gm *Manager) SetBus(b bus.Bus) error {
+	gm.bus = b
+	gm.cluster = &cluster{
+		instances: make(map[string]*remoteInstance),
+		proxies:   make(map[string]*models.Player),
+		gameHosts: make(map[string]string),
+	}
+
+	topics := []string{topicPresence, topicGames, topicAnnouncements, topicInstancePrefix + gm.Config().InstanceID}
+	for _, topic := range topics {
+		if err := b.Subscribe(topic, gm.handleClusterMessage); err != nil {
+			return err
+		}
+	}
+
+	go gm.clusterHeartbeatLoop()
+	gm.publishPresence()
+	gm.publishGames()
+	return nil
+}
+
+// clusterHeartbeatLoop periodically republishes local state and forgets silent instances
+func (gm *Manager) clusterHeartbeatLoop() {
+	ticker := gm.clock.NewTicker(clusterHeartbeat)
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		gm.publishPresence()
+		gm.publishGames()
+
+		if expired := gm.expireInstances(); len(expired) > 0 {
+			slog.Warn("Instances stopped sending heartbeats", "instances", expired)
+			gm.broadcastLobbyStatusLocal()
+			gm.broadcastGamesListLocal()
+		}
+	}
+}
+
+// publish sends an envelope tagged with this instance's identity
+func (gm *Manager) publish(topic string, envelope clusterEnvelope) {
+	if gm.bus == nil {
+		return
+	}
+
+	cfg := gm.Config()
+	envelope.Instance = cfg.InstanceID
+	envelope.Region = cfg.Region
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		slog.Error("Failed to encode bus message", "kind", envelope.Kind, "error", err)
+		reporting.Error("Failed to encode bus message", err, "kind", envelope.Kind)
+		return
+	}
+	if err := gm.bus.Publish(topic, data); err != nil {
+		slog.Error("Failed to publish bus message", "kind", envelope.Kind, "topic", topic, "error", err)
+	}
+}
+
+// publishPresence announces the players connected to this instance
+func (gm *Manager) publishPresence() {
+	if gm.bus == nil {
+		return
+	}
+
+	inLobby := make(map[string]bool)
+	for _, p := range gm.Lobby.Snapshot() {
+		inLobby[p.ID] = true
+	}
+	playersInGame := gm.playersInGame()
+
+	registered := gm.registeredPlayers()
+	players := make([]remotePlayer, 0, len(registered))
+	for _, p := range registered {
+		players = append(players, remotePlayer{
+			ID:       p.ID,
+			Username: p.Username,
+			Ready:    p.Ready,
+			JoinedAt: p.JoinedAt,
+			InLobby:  inLobby[p.ID] && !gm.restricted(p.ID),
+			InGame:   playersInGame[p.ID],
+			Bot:      p.Bot,
+			Title:    gm.titleName(p.ID),
+			Clan:     gm.clanTag(p.ID),
+		})
+	}
+
+	gm.publish(topicPresence, clusterEnvelope{Kind: clusterPresence, Players: players})
+}
+
+// publishGames announces the games hosted by this instance
+func (gm *Manager) publishGames() {
+	if gm.bus == nil {
+		return
+	}
+	gm.publish(topicGames, clusterEnvelope{Kind: clusterGames, Games: gm.localGamesList()})
+}
+
+// handleClusterMessage dispatches a message received from the bus
+func (gm *Manager) handleClusterMessage(data []byte) {
+	var envelope clusterEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		slog.Warn("Ignoring malformed bus message", "error", err)
+		return
+	}
+	// Every instance receives its own broadcasts
+	if envelope.Instance == gm.Config().InstanceID {
+		return
+	}
+
+	switch envelope.Kind {
+	case clusterPresence:
+		gm.applyRemotePresence(envelope)
+	case clusterGames:
+		gm.applyRemoteGames(envelope)
+	case clusterDeliver:
+		gm.deliverRemoteMessage(envelope)
+	case clusterInput:
+		gm.handleRemoteInput(envelope)
+	case clusterAnnouncement, clusterAnnouncementCleared:
+		gm.applyRemoteAnnouncement(envelope)
+	}
+}
+
+// applyRemotePresence records another instance's players and drops stand-ins for players
+// that have disconnected from it
+func (gm *Manager) applyRemotePresence(envelope clusterEnvelope) {
+	c := gm.cluster
+	c.mu.Lock()
+	instance := c.instance(envelope.Instance)
+	instance.region = envelope.Region
+	instance.updatedAt = gm.clock.Now()
+	instance.players = make(map[string]remotePlayer, len(envelope.Players))
+	for _, p := range envelope.Players {
+		instance.players[p.ID] = p
+	}
+
+	var gone []string
+	for id, proxy := range c.proxies {
+		if _, present := instance.players[id]; proxy.InstanceID == envelope.Instance && !present {
+			gone = append(gone, id)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, id := range gone {
+		gm.removeProxy(id)
+	}
+	gm.broadcastLobbyStatusLocal()
+}
+
+// applyRemoteGames records the games hosted by another instance
+func (gm *Manager) applyRemoteGames(envelope clusterEnvelope) {
+	c := gm.cluster
+	c.mu.Lock()
+	instance := c.instance(envelope.Instance)
+	instance.region = envelope.Region
+	instance.updatedAt = gm.clock.Now()
+	instance.games = envelope.Games
+
+	// Rebuild the routes to this instance's games so finished games are forgotten
+	for gameID, host := range c.gameHosts {
+		if host == envelope.Instance {
+			delete(c.gameHosts, gameID)
+		}
+	}
+	for _, g := range envelope.Games {
+		if id, ok := g["id"].(string); ok {
+			c.gameHosts[id] = envelope.Instance
+		}
+	}
+	c.mu.Unlock()
+
+	gm.broadcastGamesListLocal()
+}
+
+// deliverRemoteMessage passes a message from a game hosted elsewhere to a local player
+func (gm *Manager) deliverRemoteMessage(envelope clusterEnvelope) {
+	if envelope.GameID != "" {
+		gm.cluster.mu.Lock()
+		gm.cluster.gameHosts[envelope.GameID] = envelope.Instance
+		gm.cluster.mu.Unlock()
+	}
+
+	player, exists := gm.registeredPlayer(envelope.PlayerID)
+	if !exists {
+		return
+	}
+	gm.enqueue(player, envelope.MsgType, relayedPayload(player.Connection(), envelope.MsgType, envelope.Payload))
+}
+
+// handleRemoteInput processes a message sent by a remote player for a game hosted here
+func (gm *Manager) handleRemoteInput(envelope clusterEnvelope) {
+	var msg map[string]any
+	if err := json.Unmarshal(envelope.Payload, &msg); err != nil {
+		slog.Warn("Ignoring malformed remote input", "instance_id", envelope.Instance, "player_id", envelope.PlayerID, "error", err)
+		return
+	}
+
+	proxy := gm.proxyFor(envelope.PlayerID, envelope.Username, envelope.Instance)
+	gm.handleMessage(proxy, envelope.MsgType, msg)
+}
+
+// forwardToHost sends a game message to the instance hosting the game
+// Returns false when the game is local or unknown and the message should be handled here
+func (gm *Manager) forwardToHost(player *models.Player, msgType string, msg map[string]any) bool {
+	if gm.bus == nil || gm.isRemote(player) {
+		return false
+	}
+
+	gameID, _ := msg["game_id"].(string)
+	if gameID == "" {
+		return false
+	}
+
+	_, local := gm.lookupGame(gameID)
+	if local {
+		return false
+	}
+
+	gm.cluster.mu.RLock()
+	host := gm.cluster.gameHosts[gameID]
+	gm.cluster.mu.RUnlock()
+	if host == "" {
+		return false
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		reporting.Error("Failed to encode relayed message", err, "game_id", gameID)
+		return false
+	}
+	gm.publish(topicInstancePrefix+host, clusterEnvelope{
+		Kind:     clusterInput,
+		PlayerID: player.ID,
+		Username: player.Username,
+		GameID:   gameID,
+		MsgType:  msgType,
+		Payload:  payload,
+	})
+	return true
+}
+
+// remoteLobbyPlayer returns a stand-in for a player waiting in another instance's lobby
+func (gm *Manager) remoteLobbyPlayer(playerID string) (*models.Player, bool) {
+	if gm.cluster == nil {
+		return nil, false
+	}
+
+	gm.cluster.mu.RLock()
+	var instanceID string
+	var found remotePlayer
+	for id, instance := range gm.cluster.instances {
+		if p, exists := instance.players[playerID]; exists && p.InLobby {
+			instanceID, found = id, p
+			break
+		}
+	}
+	gm.cluster.mu.RUnlock()
+
+	if instanceID == "" {
+		return nil, false
+	}
+	return gm.proxyFor(found.ID, found.Username, instanceID), true
+}
+
+// proxyFor returns the local stand-in for a remote player, creating it if needed
+// Messages sent to the stand-in are relayed to the player's instance
+func (gm *Manager) proxyFor(playerID, username, instanceID string) *models.Player {
+	c := gm.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if proxy, exists := c.proxies[playerID]; exists {
+		return proxy
+	}
+
+	conn := models.NewConnection(gm.Limits().SendQueueSize)
+	proxy := &models.Player{
+		ID:         playerID,
+		Username:   username,
+		JoinedAt:   gm.clock.Now(),
+		InstanceID: instanceID,
+	}
+	proxy.Attach(conn)
+	c.proxies[playerID] = proxy
+	go gm.relayProxy(proxy, conn)
+	return proxy
+}
+
+// relayProxy forwards everything sent to a stand-in to the remote player's instance
+func (gm *Manager) relayProxy(proxy *models.Player, conn *models.Connection) {
+	topic := topicInstancePrefix + proxy.InstanceID
+	var batch [][]byte
+	for {
+		select {
+		case <-conn.Done():
+			return
+		case payload := <-conn.Send:
+			batch = conn.Drain(append(batch[:0], payload))
+		case <-conn.FrameReady():
+			batch = conn.Drain(batch[:0])
+		}
+
+		for _, payload := range batch {
+			msgType, gameID := messageInfo(payload)
+			gm.publish(topic, clusterEnvelope{
+				Kind:     clusterDeliver,
+				PlayerID: proxy.ID,
+				GameID:   gameID,
+				MsgType:  msgType,
+				Payload:  payload,
+			})
+		}
+	}
+}
+
+// removeProxy treats a remote player that left its instance as disconnected
+func (gm *Manager) removeProxy(playerID string) {
+	gm.cluster.mu.Lock()
+	proxy, exists := gm.cluster.proxies[playerID]
+	delete(gm.cluster.proxies, playerID)
+	gm.cluster.mu.Unlock()
+	if !exists {
+		return
+	}
+
+	gm.RemovePlayer(playerID)
+	proxy.LastConnection().Close(models.CloseClientGone)
+}
+
+// expireInstances forgets instances that missed their heartbeats along with their players
+func (gm *Manager) expireInstances() []string {
+	c := gm.cluster
+	c.mu.Lock()
+	var expired []string
+	for id, instance := range c.instances {
+		if gm.clock.Since(instance.updatedAt) > clusterInstanceTTL {
+			expired = append(expired, id)
+			delete(c.instances, id)
+		}
+	}
+	var gone []string
+	for _, id := range expired {
+		for playerID, proxy := range c.proxies {
+			if proxy.InstanceID == id {
+				gone = append(gone, playerID)
+			}
+		}
+		for gameID, host := range c.gameHosts {
+			if host == id {
+				delete(c.gameHosts, gameID)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	for _, playerID := range gone {
+		gm.removeProxy(playerID)
+	}
+	return expired
+}
+
+// isRemote reports whether player is a stand-in for a player connected to another instance
+func (gm *Manager) isRemote(player *models.Player) bool {
+	return player.InstanceID != "" && player.InstanceID != gm.Config().InstanceID
+}
+
+// remoteLobbyEntries returns lobby_status entries for players in other instances' lobbies
+func (c *cluster) remoteLobbyEntries() []map[string]any {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var entries []map[string]any
+	for id, instance := range c.instances {
+		for _, p := range instance.players {
+			if !p.InLobby {
+				continue
+			}
+			entry := map[string]any{
+				"id":          p.ID,
+				"username":    p.Username,
+				"ready":       p.Ready,
+				"joined_at":   p.JoinedAt,
+				"instance_id": id,
+				"region":      instance.region,
+			}
+			if p.InGame {
+				entry["in_game"] = true
+			}
+			if p.Bot {
+				entry["bot"] = true
+			}
+			if p.Title != "" {
+				entry["title"] = p.Title
+			}
+			if p.Clan != "" {
+				entry["clan"] = p.Clan
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// remoteGames returns the games hosted by other instances
+func (c *cluster) remoteGames() []map[string]any {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var games []map[string]any
+	for _, instance := range c.instances {
+		games = append(games, instance.games...)
+	}
+	return games
+}
+
+// instance returns the record for an instance, creating it if needed (caller holds c.mu)
+func (c *cluster) instance(id string) *remoteInstance {
+	instance, exists := c.instances[id]
+	if !exists {
+		instance = &remoteInstance{players: make(map[string]remotePlayer)}
+		c.instances[id] = instance
+	}
+	return instance
+}
+
+// messageInfo extracts the type of a serialized message and the game it refers to, if any
+func messageInfo(payload []byte) (msgType, gameID string) {
+	var msg struct {
+		Type   string          `json:"type"`
+		GameID string          `json:"game_id"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil || msg.GameID != "" {
+		return msg.Type, msg.GameID
+	}
+
+	var state struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(msg.Data, &state); err != nil {
+		return msg.Type, ""
+	}
+	return msg.Type, state.ID
+}
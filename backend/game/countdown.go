@@ -0,0 +1,191 @@
+package game
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// countdownInterval is how often running countdowns are checked
+const countdownInterval = constants.TICK_RATE
+
+// startCountdown schedules a game to start after the given number of seconds
+// The countdown is advanced by the tick scheduler and stops as soon as the game is removed
+func (gm *Manager) startCountdown(game *models.Game, seconds int, rematch bool) {
+	game.Mutex.Lock()
+	if game.Countdown != nil || game.IsActive() {
+		game.Mutex.Unlock()
+		return
+	}
+	gm.armCountdownLocked(game, seconds, rematch)
+	game.Mutex.Unlock()
+
+	gm.runCountdown(game)
+}
+
+// armCountdownLocked sets a game's countdown to end after the given number of seconds;
+// caller must hold game.Mutex and run the countdown once it released it
+func (gm *Manager) armCountdownLocked(game *models.Game, seconds int, rematch bool) {
+	game.Countdown = &models.Countdown{
+		Ends:    gm.clock.Now().Add(time.Duration(seconds) * time.Second),
+		Rematch: rematch,
+	}
+	if !rematch {
+		game.State.Status = "countdown"
+		gm.openGames.open(game)
+		game.State.Countdown = seconds
+		game.State.IsSinglePlayer = game.IsSinglePlayer
+	}
+}
+
+// runCountdown hands an armed countdown to the tick scheduler
+func (gm *Manager) runCountdown(game *models.Game) {
+	gm.scheduler.add(game, countdownInterval)
+	// Announce the first second right away rather than on the next scheduler tick
+	gm.countdownTick(game)
+}
+
+// countdownStep is what a countdown tick has to announce
+type countdownStep struct {
+	remaining int               // Seconds left to announce, 0 if there is nothing to announce
+	started   bool              // The countdown ended and the round started
+	rematch   bool              // Announced as rematch_countdown rather than state frames
+	frame     *models.GameState // Frame to broadcast
+	players   []*models.Player  // Players of the round that started
+	rules     models.RulesProfile
+}
+
+// countdownTick announces each remaining second and starts the game once the countdown ends
+// Returns false if the game was torn down in the meantime
+func (gm *Manager) countdownTick(game *models.Game) bool {
+	if game.Context().Err() != nil {
+		return false
+	}
+
+	step := gm.advanceCountdown(game)
+	switch {
+	case step.started:
+	case step.remaining == 0:
+		return true
+	case step.rematch:
+		gm.broadcastToPlayers(game, constants.MSG_REMATCH_COUNTDOWN, map[string]any{
+			"game_id":   game.ID,
+			"countdown": step.remaining,
+		})
+		return true
+	default:
+		gm.broadcastToPlayers(game, constants.MSG_GAME_UPDATE, map[string]any{"data": step.frame})
+		return true
+	}
+
+	playerIDs := make([]string, 0, len(step.players))
+	for _, player := range step.players {
+		playerIDs = append(playerIDs, player.ID)
+	}
+	slog.Info("Game started", "game_id", game.ID, "player_ids", playerIDs, "rematch", step.rematch, "rules", step.rules.Name)
+
+	// Move the game from the countdown lane to its own tick rate
+	gm.scheduler.add(game, step.rules.TickInterval())
+
+	gm.broadcastToPlayers(game, constants.MSG_GAME_START, map[string]any{
+		"data":   step.frame,
+		"rules":  step.rules,
+		"titles": gm.playerTitles(step.players),
+	})
+	if !game.IsSinglePlayer && !game.HotSeat && !game.Practice {
+		gm.spectatePartyGame(game, step.players)
+	}
+
+	if step.rematch {
+		return true
+	}
+	for _, player := range step.players {
+		gm.RemoveFromLobby(player.ID)
+	}
+	if !game.IsSinglePlayer {
+		gm.BroadcastGamesList()
+	}
+	return true
+}
+
+// advanceCountdown updates a running countdown under the game lock and starts the round
+// once it ends
+func (gm *Manager) advanceCountdown(game *models.Game) countdownStep {
+	game.Mutex.Lock()
+	defer game.Mutex.Unlock()
+
+	countdown := game.Countdown
+	if countdown == nil {
+		return countdownStep{}
+	}
+
+	remaining := int(math.Ceil(gm.clock.Until(countdown.Ends).Seconds()))
+	if remaining > 0 {
+		if remaining == countdown.Shown {
+			return countdownStep{}
+		}
+		countdown.Shown = remaining
+		if countdown.Rematch {
+			return countdownStep{remaining: remaining, rematch: true}
+		}
+		game.State.Countdown = remaining
+		return countdownStep{remaining: remaining, frame: game.State.Clone()}
+	}
+
+	game.Countdown = nil
+	game.State.Status = "playing"
+	gm.openGames.open(game)
+	game.State.Countdown = 0
+	game.State.IsSinglePlayer = game.IsSinglePlayer
+	if countdown.Rematch {
+		game.State.Winner = ""
+		game.Player1.Ready = false
+		game.Player2.Ready = false
+	}
+
+	players := []*models.Player{game.Player1}
+	if game.Player2 != nil {
+		players = append(players, game.Player2)
+	}
+	game.Replay = nil
+	if game.Resume == nil {
+		game.Replay = gm.newReplay(game, players)
+		game.State.Snakes = gm.newSnakes(game, players...)
+		layout := gm.spawnLayout(game.Rules)
+		if run := game.DailyRun; run != nil {
+			// Replaying the run lays out the same opening
+			run.SpawnLayout, run.StartLength, run.Turns = layout, gm.Config().Gameplay.StartLength, nil
+		}
+		game.State.Foods = nil
+		game.State.TeamScore = 0
+		game.State.TieBreak, game.State.SuddenDeath = "", false
+		engine.OpeningFood(game.State, game.Rules, layout, game.Rand)
+		game.Opening = game.State.Clone().Snakes
+	}
+	game.StartRound()
+	game.Abandoned = ""
+	game.StartedAt = gm.clock.Now()
+	game.Ticks = 0
+	if resume := game.Resume; resume != nil {
+		// A resumed round keeps the board it was saved with and picks up its clock
+		game.StartedAt = game.StartedAt.Add(-resume.Played)
+		game.Ticks = resume.Ticks
+		game.Resume = nil
+	}
+	game.State.Tick = game.Ticks
+	game.Round++
+	openReplay(game)
+	game.TurnsTaken = make(map[string]int, len(players))
+	game.Rematch = countdown.Rematch
+	return countdownStep{
+		started: true,
+		rematch: countdown.Rematch,
+		frame:   game.State.Clone(),
+		players: players,
+		rules:   game.Rules,
+	}
+}
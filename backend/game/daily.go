@@ -0,0 +1,121 @@
+package game
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// dailyDateLayout formats the UTC day a daily run counts for
+const dailyDateLayout = "2006-01-02"
+
+// dailyDate returns the UTC day a daily run started at now counts for
+func dailyDate(now time.Time) string {
+	return now.UTC().Format(dailyDateLayout)
+}
+
+// dailyResetsAt returns when the daily ranking of the day of now resets: the next midnight UTC
+func dailyResetsAt(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
+
+// dailySeed derives the food-spawn seed everyone plays a day's daily run with from its date,
+// so every instance hands out the same one
+func dailySeed(date string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("daily/" + date))
+	return int64(hash.Sum64() >> 1)
+}
+
+// setupDailyRun seeds a new daily run with the seed of the day and starts its record
+func (gm *Manager) setupDailyRun(game *models.Game) {
+	date := dailyDate(gm.clock.Now())
+	game.Seed = dailySeed(date)
+	game.Rand = rng.New(game.Seed)
+	game.DailyRun = &models.DailyRun{Date: date}
+}
+
+// recordDailyRun ranks a finished daily run once replaying it from the seed of its day
+// and the turns applied reproduces the board it ended with
+func (gm *Manager) recordDailyRun(game *models.Game, player *models.Player, stats MatchStats) {
+	if game.DailyRun == nil || gm.profiles == nil || len(stats.Players) == 0 {
+		return
+	}
+	game.Mutex.RLock()
+	run := *game.DailyRun
+	seed, rules, ticks, final := game.Seed, game.Rules, game.Ticks, game.State.Clone()
+	game.Mutex.RUnlock()
+
+	if !replayDailyRun(run, seed, rules, ticks, final) {
+		slog.Warn("Daily run failed verification", "game_id", game.ID, "player_id", player.ID, "date", run.Date)
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Your daily run could not be verified and was not ranked",
+			"code":    "DAILY_RUN_UNVERIFIED",
+		})
+		return
+	}
+
+	score := stats.Players[0].Score
+	previousLeader := leader(gm.profiles.DailyLeaderboard(run.Date))
+	best, err := gm.profiles.RecordDailyRun(run.Date, player.ID, player.Username, score, gm.clock.Now())
+	if err != nil {
+		slog.Error("Failed to persist daily run", "player_id", player.ID, "date", run.Date, "error", err)
+	}
+	if best {
+		gm.notifyLeaderboardTop("daily run ("+run.Date+")", previousLeader, gm.profiles.DailyLeaderboard(run.Date))
+		gm.sendDailyLeaderboard(player, run.Date)
+	}
+}
+
+// replayDailyRun plays a daily run again from its seed and turns and reports whether it
+// ends on the same tick with the same board, i.e. the server itself simulated the run
+func replayDailyRun(run models.DailyRun, seed int64, rules models.RulesProfile, ticks int, final *models.GameState) bool {
+	if len(final.Snakes) != 1 {
+		return false
+	}
+	rnd := rng.New(seed)
+	id := final.Snakes[0].ID
+	board := &models.GameState{IsSinglePlayer: true, Snakes: engine.StartSnakes(rnd, run.SpawnLayout, run.StartLength, id)}
+	engine.OpeningFood(board, rules, run.SpawnLayout, rnd)
+
+	next := 0
+	for tick := 1; tick <= ticks; tick++ {
+		inputs := make(engine.Inputs, 1)
+		for ; next < len(run.Turns) && run.Turns[next].Tick == tick; next++ {
+			inputs[id] = run.Turns[next].Direction
+		}
+		if engine.Step(engine.State{Board: board, Rules: rules, Rand: rnd}, inputs).Over() && tick < ticks {
+			return false
+		}
+	}
+
+	replayed, played := board.Snakes[0], final.Snakes[0]
+	return next == len(run.Turns) &&
+		replayed.Score == played.Score &&
+		slices.Equal(replayed.Body, played.Body) &&
+		slices.Equal(board.Foods, final.Foods)
+}
+
+// sendDailyLeaderboard sends the player the ranking of a day's daily run and when it resets
+func (gm *Manager) sendDailyLeaderboard(player *models.Player, date string) {
+	gm.sendMessage(player, constants.MSG_LEADERBOARD, gm.dailyLeaderboard(date))
+}
+
+// dailyLeaderboard returns the ranking of a day's daily run and when it resets
+func (gm *Manager) dailyLeaderboard(date string) map[string]any {
+	day, _ := time.Parse(dailyDateLayout, date)
+	return map[string]any{
+		"variant":    constants.VARIANT_DAILY,
+		"difficulty": constants.DIFFICULTY_NORMAL,
+		"date":       date,
+		"resets_at":  dailyResetsAt(day),
+		"scores":     gm.profiles.DailyLeaderboard(date),
+	}
+}
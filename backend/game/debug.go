@@ -0,0 +1,115 @@
+package game
+
+import (
+	"maps"
+	"time"
+
+	"github.com/bariiss/snake/backend/models"
+)
+
+// GameDebug is the full internal state of a game, for diagnosing stuck games
+type GameDebug struct {
+	ID             string                  `json:"id"`
+	Status         string                  `json:"status"`
+	IsActive       bool                    `json:"is_active"`
+	IsSinglePlayer bool                    `json:"is_single_player"`
+	Rules          models.RulesProfile     `json:"rules"`
+	InstanceID     string                  `json:"instance_id"`
+	Region         string                  `json:"region"`
+	Countdown      *models.Countdown       `json:"countdown,omitempty"`
+	StartedAt      time.Time               `json:"started_at,omitzero"`
+	PausedAt       time.Time               `json:"paused_at,omitzero"` // A paused single player game isn't scheduled
+	Rematch        bool                    `json:"rematch"`
+	Ticks          int                     `json:"ticks"`
+	Seed           int64                   `json:"seed"`
+	Scheduled      bool                    `json:"scheduled"` // The tick scheduler is advancing the game
+	TickIntervalMs int64                   `json:"tick_interval_ms"`
+	LastTickMs     float64                 `json:"last_tick_ms"`
+	Players        []ParticipantDebug      `json:"players"`
+	Spectators     []ParticipantDebug      `json:"spectators"`
+	LastInputs     map[string]models.Input `json:"last_inputs"`
+	State          *models.GameState       `json:"state"`
+}
+
+// ParticipantDebug describes a player or spectator and their connection
+type ParticipantDebug struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	Ready        bool   `json:"ready"`
+	Connected    bool   `json:"connected"` // Has an outbound queue
+	ConnID       string `json:"conn_id,omitempty"`
+	Subprotocol  string `json:"subprotocol,omitempty"`
+	SendQueue    int    `json:"send_queue"` // Messages waiting to be written
+	SendCapacity int    `json:"send_capacity"`
+	Slow         bool   `json:"slow"`
+	CloseReason  string `json:"close_reason,omitempty"`
+	InstanceID   string `json:"instance_id,omitempty"`
+}
+
+// DebugGame returns the internal state of a game, false if it doesn't exist
+func (gm *Manager) DebugGame(gameID string) (*GameDebug, bool) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return nil, false
+	}
+
+	interval, lastTick, scheduled := gm.scheduler.schedule(gameID)
+
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+
+	debug := &GameDebug{
+		ID:             game.ID,
+		IsActive:       game.IsActive(),
+		IsSinglePlayer: game.IsSinglePlayer,
+		Rules:          game.Rules,
+		InstanceID:     game.InstanceID,
+		Region:         game.Region,
+		StartedAt:      game.StartedAt,
+		PausedAt:       game.PausedAt,
+		Rematch:        game.Rematch,
+		Ticks:          game.Ticks,
+		Seed:           game.Seed,
+		Scheduled:      scheduled,
+		TickIntervalMs: interval.Milliseconds(),
+		LastTickMs:     float64(lastTick.Microseconds()) / 1000,
+		Players:        []ParticipantDebug{participantDebug(game.Player1)},
+		Spectators:     []ParticipantDebug{},
+		LastInputs:     maps.Clone(game.LastInputs),
+		State:          game.State.Clone(),
+	}
+	if game.State != nil {
+		debug.Status = game.State.Status
+	}
+	if game.Countdown != nil {
+		countdown := *game.Countdown
+		debug.Countdown = &countdown
+	}
+	if game.Player2 != nil {
+		debug.Players = append(debug.Players, participantDebug(game.Player2))
+	}
+	for _, spectator := range game.Spectators {
+		debug.Spectators = append(debug.Spectators, participantDebug(spectator))
+	}
+	return debug, true
+}
+
+// participantDebug describes a player's connection without touching its queue
+func participantDebug(player *models.Player) ParticipantDebug {
+	debug := ParticipantDebug{
+		ID:         player.ID,
+		Username:   player.Username,
+		Ready:      player.Ready,
+		Connected:  player.Connected(),
+		InstanceID: player.InstanceID,
+	}
+	if conn := player.LastConnection(); conn != nil {
+		debug.ConnID = conn.ID
+		debug.Subprotocol = conn.Subprotocol
+		debug.SendQueue = conn.Pending()
+		debug.SendCapacity = cap(conn.Send)
+		debug.Slow = conn.Slow()
+		debug.CloseReason = conn.Reason()
+	}
+	return debug
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// difficultyPresets are the difficulties a single player game can be played at
+var difficultyPresets = map[string]models.Difficulty{
+	constants.DIFFICULTY_EASY: {
+		Name:          constants.DIFFICULTY_EASY,
+		TickRateMs:    140,
+		StageFood:     5,
+		StageMs:       5,
+		MinTickRateMs: 100,
+	},
+	constants.DIFFICULTY_NORMAL: {
+		Name:          constants.DIFFICULTY_NORMAL,
+		StageFood:     4,
+		StageMs:       5,
+		MinTickRateMs: 70,
+	},
+	constants.DIFFICULTY_HARD: {
+		Name:          constants.DIFFICULTY_HARD,
+		TickRateMs:    70,
+		StageFood:     2,
+		StageMs:       5,
+		MinTickRateMs: 45,
+	},
+}
+
+// difficultyOrder lists the difficulties from easiest to hardest
+var difficultyOrder = []string{constants.DIFFICULTY_EASY, constants.DIFFICULTY_NORMAL, constants.DIFFICULTY_HARD}
+
+// ResolveDifficulty returns the preset with the given name, defaulting to normal when name is empty
+func ResolveDifficulty(name string) (models.Difficulty, bool) {
+	if name == "" {
+		name = constants.DIFFICULTY_NORMAL
+	}
+	difficulty, exists := difficultyPresets[name]
+	return difficulty, exists
+}
+
+// sendInvalidDifficulty notifies a player that the requested difficulty does not exist
+func (gm *Manager) sendInvalidDifficulty(player *models.Player, name string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":       "INVALID_DIFFICULTY",
+		"message":    "Unknown difficulty: " + name,
+		"difficulty": name,
+	})
+}
+
+// difficultyList returns the difficulties from easiest to hardest
+func difficultyList() []models.Difficulty {
+	difficulties := make([]models.Difficulty, 0, len(difficultyOrder))
+	for _, name := range difficultyOrder {
+		difficulties = append(difficulties, difficultyPresets[name])
+	}
+	return difficulties
+}
+
+// rampSpeed moves a single player game up a speed stage for every StageFood food its
+// snake has eaten, rescheduling it at the faster tick rate of the new stage
+// Returns the stage the game is at.
+func (gm *Manager) rampSpeed(game *models.Game) int {
+	game.Mutex.Lock()
+	difficulty := game.Difficulty
+	if difficulty.StageFood <= 0 || len(game.State.Snakes) == 0 {
+		game.Mutex.Unlock()
+		return 0
+	}
+	stage := game.State.Snakes[0].Score / difficulty.StageFood
+	rate := difficulty.StageTickRate(difficulty.TickRateMs, stage)
+	game.State.SpeedStage = stage
+	changed := rate != game.Rules.TickRateMs
+	game.Rules.TickRateMs = rate
+	interval := game.Rules.TickInterval()
+	game.Mutex.Unlock()
+
+	if changed && gm.scheduler.reschedule(game, interval) {
+		slog.Debug("Single player game sped up", "game_id", game.ID, "stage", stage, "tick_rate_ms", rate)
+	}
+	return stage
+}
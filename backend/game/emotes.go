@@ -0,0 +1,133 @@
+package game
+
+import (
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Emote is a predefined quick-chat message players can send during a game
+type Emote struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Emotes are the quick-chat messages players may send, in display order
+// Free text is not accepted, so there is nothing to moderate
+var Emotes = []Emote{
+	{ID: "gl", Text: "Good luck!"},
+	{ID: "gg", Text: "Good game!"},
+	{ID: "nice", Text: "Nice move!"},
+	{ID: "wow", Text: "Wow!"},
+	{ID: "oops", Text: "Oops!"},
+	{ID: "close", Text: "That was close!"},
+	{ID: "thanks", Text: "Thanks!"},
+	{ID: "rematch", Text: "Rematch?"},
+}
+
+// Emote rate limit per player: a burst of emoteBurst, then one every emoteInterval
+const (
+	emoteBurst    = 3
+	emoteInterval = 3 * time.Second
+)
+
+var (
+	emotesSent    = metrics.NewCounterVec("snake_emotes_total", "Emotes relayed to game participants, by emote ID", "emote")
+	emotesDropped = metrics.NewCounterVec("snake_emotes_dropped_total", "Emotes dropped, by reason", "reason")
+)
+
+// emoteBucket is a player's emote allowance
+type emoteBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// findEmote returns the emote with the given ID
+func findEmote(id string) (Emote, bool) {
+	for _, emote := range Emotes {
+		if emote.ID == id {
+			return emote, true
+		}
+	}
+	return Emote{}, false
+}
+
+// SendEmote relays a predefined emote from a player to everyone in their game
+func (gm *Manager) SendEmote(player *models.Player, gameID string, emoteID string) {
+	emote, known := findEmote(emoteID)
+	if !known {
+		emotesDropped.Inc("unknown")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id":  gameID,
+			"code":     "UNKNOWN_EMOTE",
+			"message":  "Unknown emote: " + emoteID,
+			"emote_id": emoteID,
+		})
+		return
+	}
+
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return
+	}
+	game.Mutex.RLock()
+	isPlayer := game.Player1.ID == player.ID || (game.Player2 != nil && game.Player2.ID == player.ID)
+	game.Mutex.RUnlock()
+	if !isPlayer {
+		emotesDropped.Inc("not_a_player")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Only players can send emotes",
+			"code":    "NOT_A_PLAYER",
+		})
+		return
+	}
+
+	if !gm.allowEmote(player.ID) {
+		emotesDropped.Inc("rate_limited")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Too many emotes. Wait a moment before sending another.",
+			"code":    "EMOTE_RATE_LIMITED",
+		})
+		return
+	}
+
+	emotesSent.Inc(emote.ID)
+	gm.broadcastFrom(game, player, constants.MSG_EMOTE, map[string]any{
+		"game_id":   gameID,
+		"player_id": player.ID,
+		"username":  player.Username,
+		"emote_id":  emote.ID,
+		"text":      emote.Text,
+	})
+}
+
+// allowEmote takes one emote from the player's allowance, reporting whether there was one
+func (gm *Manager) allowEmote(playerID string) bool {
+	now := gm.clock.Now()
+	gm.emoteMu.Lock()
+	defer gm.emoteMu.Unlock()
+
+	bucket, exists := gm.emoteBuckets[playerID]
+	if !exists {
+		bucket = &emoteBucket{tokens: emoteBurst, last: now}
+		gm.emoteBuckets[playerID] = bucket
+	}
+	bucket.tokens = min(emoteBurst, bucket.tokens+now.Sub(bucket.last).Seconds()/emoteInterval.Seconds())
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// forgetEmotes drops a departed player's emote allowance
+func (gm *Manager) forgetEmotes(playerID string) {
+	gm.emoteMu.Lock()
+	delete(gm.emoteBuckets, playerID)
+	gm.emoteMu.Unlock()
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
+)
+
+// Outcomes an admin can end a game with
+const (
+	OutcomeTie  = "tie"  // The round is recorded as a tie
+	OutcomeWin  = "win"  // The round is recorded as won by the player named
+	OutcomeVoid = "void" // Nothing is recorded, as if the round had never been played
+)
+
+var (
+	// ErrInvalidOutcome is returned when ending a game with an unknown outcome
+	ErrInvalidOutcome = errors.New("outcome must be tie, win or void")
+	// ErrNotGamePlayer is returned when awarding a game to someone who doesn't play in it
+	ErrNotGamePlayer = errors.New("the winner must be one of the game's players")
+	// ErrNotDecidable is returned when a game without a round in progress is given a result
+	ErrNotDecidable = errors.New("only a round being played by two players can be decided, void the game instead")
+)
+
+var gamesForceEnded = metrics.NewCounterVec("snake_games_force_ended_total", "Games an admin ended, by outcome", "outcome")
+
+// ForcedEnd records an admin ending a game
+type ForcedEnd struct {
+	GameID         string    `json:"game_id"`
+	Outcome        string    `json:"outcome"`
+	Winner         string    `json:"winner,omitempty"` // ID of the player awarded the round
+	WinnerUsername string    `json:"winner_username,omitempty"`
+	Players        []string  `json:"players"` // Usernames
+	Spectators     int       `json:"spectators"`
+	Reason         string    `json:"reason,omitempty"`
+	By             string    `json:"by"`
+	At             time.Time `json:"at"`
+}
+
+// ForceEndGame ends a game hosted by this instance right away
+// A tie or a win, awarded to the player with the ID or username winner, ends the round
+// being played like any other, with its result recorded for ratings, tournaments and clans;
+// a void records nothing, and a tournament match it was played for is played again. The
+// players and spectators are told why in a game_force_ended message, then the game is
+// removed along with its lounge and the requests referring to it, and the players go back
+// to the lobby.
+func (gm *Manager) ForceEndGame(gameID, outcome, winner, reason, by string) (ForcedEnd, error) {
+	if outcome != OutcomeTie && outcome != OutcomeWin && outcome != OutcomeVoid {
+		return ForcedEnd{}, ErrInvalidOutcome
+	}
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return ForcedEnd{}, ErrGameNotFound
+	}
+
+	game.Mutex.Lock()
+	end := ForcedEnd{
+		GameID:     game.ID,
+		Outcome:    outcome,
+		Players:    []string{game.Player1.Username},
+		Spectators: len(game.Spectators),
+		Reason:     reason,
+		By:         by,
+		At:         gm.clock.Now().UTC(),
+	}
+	players := []*models.Player{game.Player1}
+	if game.Player2 != nil {
+		players = append(players, game.Player2)
+		end.Players = append(end.Players, game.Player2.Username)
+	}
+	if outcome == OutcomeWin {
+		for _, player := range players {
+			if player.ID == winner || names.Key(player.Username) == names.Key(winner) {
+				end.Winner, end.WinnerUsername = player.ID, player.Username
+			}
+		}
+		if end.Winner == "" {
+			game.Mutex.Unlock()
+			return ForcedEnd{}, ErrNotGamePlayer
+		}
+	}
+	if outcome != OutcomeVoid && (game.Player2 == nil || !game.IsActive()) {
+		game.Mutex.Unlock()
+		return ForcedEnd{}, ErrNotDecidable
+	}
+	isActive := game.EndRound()
+	if isActive {
+		gm.scheduler.remove(game.ID)
+	}
+	tournamentID := game.Tournament
+	game.Mutex.Unlock()
+
+	if outcome != OutcomeVoid {
+		result := OutcomeTie
+		if outcome == OutcomeWin {
+			result = end.Winner
+		}
+		gm.endGame(game, result, game.State)
+	}
+
+	gamesForceEnded.Inc(outcome)
+	slog.Warn("Game ended by an admin", "game_id", game.ID, "outcome", outcome, "winner", end.Winner, "by", by, "reason", reason)
+	gm.broadcastToPlayers(game, constants.MSG_GAME_FORCE_ENDED, map[string]any{
+		"game_id":         game.ID,
+		"outcome":         outcome,
+		"winner":          end.Winner,
+		"winner_username": end.WinnerUsername,
+		"reason":          reason,
+		"code":            "GAME_FORCE_ENDED",
+		"message":         "The game was ended by an admin",
+	})
+
+	gm.dropPendingRequestsFor(game.ID)
+	// deleteGame unindexes the spectators, so they are only dropped from the game afterwards
+	gm.deleteGame(game.ID)
+	game.Mutex.Lock()
+	clear(game.Spectators)
+	game.Mutex.Unlock()
+	for _, player := range players {
+		if !player.Connected() {
+			continue
+		}
+		if _, inLobby := gm.Lobby.Get(player.ID); !inLobby {
+			gm.AddToLobby(player)
+		}
+	}
+	// The sweep finds a voided match without its game and creates a new one
+	if tournamentID != "" && outcome == OutcomeVoid {
+		gm.startTournamentSweep()
+	}
+	gm.BroadcastLobbyStatus()
+	gm.BroadcastGamesList()
+	return end, nil
+}
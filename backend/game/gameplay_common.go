@@ -1,29 +1,44 @@
 package game
 
 import (
-	"log"
-	"math/rand"
-
-	"snake-backend/constants"
-	"snake-backend/models"
+	"log/slog"
+	"maps"
+	"math"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/features"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/rng"
 )
 
 // HandlePlayerMove handles player move input (common for both single and multiplayer)
-func (gm *Manager) HandlePlayerMove(player *models.Player, gameID string, directionStr string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+// tick is the tick of the latest game update the client had when it sent the move, nil when
+// the client doesn't say; the move is meant for the tick after it.
+func (gm *Manager) HandlePlayerMove(player *models.Player, gameID string, directionStr string, tick *int) {
+	game, exists := gm.lookupGame(gameID)
 
-	if !exists || !game.IsActive {
+	if !exists {
 		return
 	}
 
 	game.Mutex.RLock()
+	// Turns sent while a single player game is paused are dropped
+	isActive := game.IsActive() && game.PausedAt.IsZero()
 	isPlayer := game.Player1.ID == player.ID || (game.Player2 != nil && game.Player2.ID == player.ID)
+	inCountdown := game.Countdown != nil
 	game.Mutex.RUnlock()
 
+	if !isActive {
+		if inCountdown && isPlayer {
+			gm.watchCountdownMove(game, player)
+		}
+		return
+	}
 	if !isPlayer {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Only players can move. Spectators can only watch.",
 			"code":    "NOT_A_PLAYER",
 		})
@@ -44,109 +59,317 @@ func (gm *Manager) HandlePlayerMove(player *models.Player, gameID string, direct
 		return
 	}
 
+	// Key-repeat spam is dropped under the read lock, without contending for the write lock
+	game.Mutex.RLock()
+	ignored := moveIgnoreReason(game, player.ID, direction, tick)
+	game.Mutex.RUnlock()
+	if ignored != "" {
+		dropMove(ignored)
+		return
+	}
+
 	game.Mutex.Lock()
-	opposites := map[constants.Direction]constants.Direction{
-		constants.UP:    constants.DOWN,
-		constants.DOWN:  constants.UP,
-		constants.LEFT:  constants.RIGHT,
-		constants.RIGHT: constants.LEFT,
+	if ignored := moveIgnoreReason(game, player.ID, direction, tick); ignored != "" {
+		game.Mutex.Unlock()
+		dropMove(ignored)
+		return
+	}
+	gm.watchMoveTiming(game, player)
+	if tick != nil && *tick < game.Ticks {
+		// Accepted by moveIgnoreReason only when the latest tick can be taken back for it
+		game.Rollback.Late[player.ID] = direction
+		gm.recordInput(game, player.ID, models.Input{Direction: directionStr, At: gm.clock.Now(), Applied: true, Late: true})
+		game.Mutex.Unlock()
+		lateMoves.Inc()
+		return
 	}
+	applied := false
 	for i := range game.State.Snakes {
-		if game.State.Snakes[i].ID != player.ID {
+		snake := &game.State.Snakes[i]
+		if snake.ID != player.ID {
 			continue
 		}
-		if direction == opposites[game.State.Snakes[i].Direction] {
+		if engine.Reverses(snake.Direction, direction) {
 			break
 		}
-		game.State.Snakes[i].NextDir = direction
+		snake.Turns = append(snake.Turns, direction)
+		applied = true
 		break
 	}
+	gm.recordInput(game, player.ID, models.Input{Direction: directionStr, At: gm.clock.Now(), Applied: applied})
 	game.Mutex.Unlock()
 }
 
-// gameLoop is the main game loop (common for both single and multiplayer)
-func (gm *Manager) gameLoop(game *models.Game) {
-	defer game.Ticker.Stop()
+// recordInput keeps a player's latest move for diagnostics; caller must hold game.Mutex
+func (gm *Manager) recordInput(game *models.Game, playerID string, input models.Input) {
+	if game.LastInputs == nil {
+		game.LastInputs = make(map[string]models.Input)
+	}
+	game.LastInputs[playerID] = input
+}
 
-	for range game.Ticker.C {
-		game.Mutex.Lock()
-		if !game.IsActive {
-			game.Mutex.Unlock()
-			return
-		}
+// Reasons a move is dropped without being recorded
+const (
+	moveRedundant = "redundant" // Repeats the direction the snake will head in
+	moveStale     = "stale"     // Sent for a tick that was already simulated
+	moveEarly     = "early"     // Sent after a tick that wasn't simulated yet
+	moveCadence   = "cadence"   // The snake already turns on the next tick
+)
 
-		for i := range game.State.Snakes {
-			game.State.Snakes[i].Direction = game.State.Snakes[i].NextDir
-		}
+var (
+	ignoredMoves    = metrics.NewCounterVec("snake_moves_ignored_total", "Player moves dropped as repeats before taking the game lock", "reason")
+	inputViolations = metrics.NewCounterVec("snake_input_violations_total", "Player moves dropped for breaking the input cadence", "reason")
+	lateMoves       = metrics.NewCounter("snake_moves_late_total", "Player moves applied to the tick before the latest under latency compensation")
+	retakenTicks    = metrics.NewCounter("snake_ticks_retaken_total", "Ticks played again to apply moves that arrived late for them")
+)
 
-		for i := range game.State.Snakes {
-			head := game.State.Snakes[i].Body[0]
-			var newHead models.Position
-
-			switch game.State.Snakes[i].Direction {
-			case constants.UP:
-				newHead = models.Position{X: head.X, Y: head.Y - 1}
-			case constants.DOWN:
-				newHead = models.Position{X: head.X, Y: head.Y + 1}
-			case constants.LEFT:
-				newHead = models.Position{X: head.X - 1, Y: head.Y}
-			case constants.RIGHT:
-				newHead = models.Position{X: head.X + 1, Y: head.Y}
+// moveIgnoreReason returns why a move is dropped, or "" if it should be applied on the next
+// tick
+// A snake turns at most once per tick, so sending moves faster than the game ticks can't
+// decide which of them is applied, and a move stamped with a tick is only applied on the
+// tick after it. Under latency compensation a move stamped one tick late is applied to the
+// tick it was meant for, as long as that tick can still be taken back. Caller must hold
+// game.Mutex.
+func moveIgnoreReason(game *models.Game, playerID string, direction constants.Direction, tick *int) string {
+	if tick != nil {
+		switch {
+		case *tick == game.Ticks-1 && game.Rollback != nil && game.Rollback.Tick == game.Ticks && game.Rollback.Accepts(playerID, direction):
+			return ""
+		case *tick < game.Ticks:
+			return moveStale
+		case *tick > game.Ticks:
+			return moveEarly
+		}
+	}
+	for i := range game.State.Snakes {
+		snake := &game.State.Snakes[i]
+		if snake.ID != playerID {
+			continue
+		}
+		if len(snake.Turns) > 0 {
+			if snake.Turns[0] == direction {
+				return moveRedundant
 			}
+			return moveCadence
+		}
+		if snake.Direction == direction {
+			return moveRedundant
+		}
+		return ""
+	}
+	return ""
+}
 
-			if newHead.X < 0 {
-				newHead.X = constants.GRID_WIDTH - 1
-			} else if newHead.X >= constants.GRID_WIDTH {
-				newHead.X = 0
-			}
-			if newHead.Y < 0 {
-				newHead.Y = constants.GRID_HEIGHT - 1
-			} else if newHead.Y >= constants.GRID_HEIGHT {
-				newHead.Y = 0
-			}
+// dropMove counts a move dropped for reason
+func dropMove(reason string) {
+	if reason == moveRedundant {
+		ignoredMoves.Inc(reason)
+		return
+	}
+	inputViolations.Inc(reason)
+}
 
-			game.State.Snakes[i].Body = append([]models.Position{newHead}, game.State.Snakes[i].Body...)
+// tick advances a game by one step (common for both single and multiplayer)
+// Called by the tick scheduler; returns false once the game is no longer running
+func (gm *Manager) tick(game *models.Game) bool {
+	phase, result, frame := gm.step(game)
+	switch phase {
+	case phaseCountdown:
+		return gm.countdownTick(game)
+	case phaseStopped:
+		return false
+	case phasePaused:
+		return true
+	}
 
-			if newHead.X == game.State.Food.Position.X && newHead.Y == game.State.Food.Position.Y {
-				game.State.Snakes[i].Score++
-				game.State.Food = models.Food{Position: gm.generateFood(game.State.Snakes)}
-			} else {
-				game.State.Snakes[i].Body = game.State.Snakes[i].Body[:len(game.State.Snakes[i].Body)-1]
-			}
+	if result.Over() {
+		gm.endGame(game, result.Winner, frame)
+		return false
+	}
+	if game.IsSinglePlayer {
+		frame.SpeedStage = gm.rampSpeed(game)
+		slog.Debug("Single player game update", "game_id", game.ID, "status", frame.Status, "snakes", len(frame.Snakes))
+	}
+	gm.broadcastToPlayers(game, constants.MSG_GAME_UPDATE, map[string]any{"data": frame})
+	return true
+}
+
+// tickPhase is what a tick found the game doing
+type tickPhase int
+
+const (
+	phasePlaying   tickPhase = iota // A round is being played
+	phaseCountdown                  // A countdown is running
+	phaseStopped                    // Nothing is running, the game leaves the scheduler
+	phasePaused                     // A single player game was paused after the tick was due
+)
+
+// step advances the board of a playing game under the game lock
+// The lock is released by a deferred call so a panic in the engine can't leave it held
+func (gm *Manager) step(game *models.Game) (tickPhase, engine.Result, *models.GameState) {
+	game.Mutex.Lock()
+	defer game.Mutex.Unlock()
+
+	if game.Countdown != nil {
+		return phaseCountdown, engine.Result{}, nil
+	}
+	if !game.IsActive() {
+		return phaseStopped, engine.Result{}, nil
+	}
+	if !game.PausedAt.IsZero() {
+		return phasePaused, engine.Result{}, nil
+	}
+
+	result, retaken := gm.retakeTick(game)
+	if !retaken || !result.Over() {
+		result = gm.advance(game)
+	}
+	if result.Over() && game.Replay != nil {
+		game.Replay.Decided, game.Replay.Winner = true, result.Winner
+	}
+	// A score attack the snake survived ends as a win when its time is up
+	if left, timed := gm.timeLeft(game); timed {
+		game.State.TimeLeft = int(math.Ceil(left.Seconds()))
+		if left == 0 && !result.Over() {
+			result.Winner = game.Player1.ID
+		}
+	}
+	if result.Over() {
+		// For single player, "game_over" means player lost
+		if result.Winner == engine.GameOver {
+			game.State.Winner = "" // No winner in single player loss
 		}
+		return phasePlaying, result, game.State
+	}
+	return phasePlaying, result, game.State.Clone()
+}
 
-		winner := gm.checkCollisions(game)
-		if winner != "" {
-			// Ensure IsSinglePlayer flag is set correctly before copying
-			game.State.IsSinglePlayer = game.IsSinglePlayer
-			gameState := game.State
-			game.Mutex.Unlock()
-			// For single player, "game_over" means player lost
-			if winner == "game_over" {
-				gameState.Winner = "" // No winner in single player loss
-			}
-			gm.endGame(game, winner, gameState)
-			return
+// advance plays the next tick of a game; caller must hold game.Mutex
+// Under latency compensation it keeps what it takes to play the tick again.
+func (gm *Manager) advance(game *models.Game) engine.Result {
+	game.Ticks++
+	game.State.Tick = game.Ticks
+	game.State.IsSinglePlayer = game.IsSinglePlayer
+	game.TickedAt = gm.clock.Now()
+	if game.Rules.Respawns() {
+		gm.respawnSnakes(game)
+	}
+	steerAutopilot(game)
+	rollback := gm.newRollback(game)
+	// Each snake applies its oldest queued turn
+	inputs := make(engine.Inputs, len(game.State.Snakes))
+	for i := range game.State.Snakes {
+		snake := &game.State.Snakes[i]
+		if len(snake.Turns) == 0 {
+			continue
 		}
+		inputs[snake.ID] = snake.Turns[0]
+		if game.DailyRun != nil {
+			game.DailyRun.Turns = append(game.DailyRun.Turns, models.Turn{Tick: game.Ticks, Direction: snake.Turns[0]})
+		}
+		snake.Turns = snake.Turns[1:]
+		game.TurnsTaken[snake.ID]++
+	}
+	recordReplayInputs(game, inputs)
+	gm.watchTurnStreaks(game, inputs)
+	result := engine.Step(engine.State{Board: game.State, Rules: game.Rules, Rand: game.Rand, Opening: game.Opening}, inputs)
+	if len(result.Crashed) > 0 {
+		gm.knockDown(game, result.Crashed)
+	}
 
-		// Ensure IsSinglePlayer flag is set correctly
-		game.State.IsSinglePlayer = game.IsSinglePlayer
-		stateCopy := game.State
-		game.Mutex.Unlock()
-		// Log for debugging
-		if game.IsSinglePlayer {
-			log.Printf("Single player game update: status=%s, snakes=%d", stateCopy.Status, len(stateCopy.Snakes))
+	game.Rollback = nil
+	if rollback != nil && !result.Over() {
+		rollback.Inputs = inputs
+		rollback.RandTo = game.Rand.(rng.Rewinder).Position()
+		game.Rollback = rollback
+	}
+	return result
+}
+
+// newRollback saves the game as it is before its next tick is played, or returns nil when
+// latency compensation is off or the game's Rand can't be rewound
+func (gm *Manager) newRollback(game *models.Game) *models.Rollback {
+	rewinder, rewinds := game.Rand.(rng.Rewinder)
+	if !rewinds || !features.Enabled(features.LatencyCompensation) {
+		return nil
+	}
+	rollback := &models.Rollback{
+		Tick:     game.Ticks,
+		Board:    game.State.Clone(),
+		Respawns: maps.Clone(game.Respawns),
+		RandFrom: rewinder.Position(),
+		Late:     make(engine.Inputs),
+	}
+	if game.DailyRun != nil {
+		rollback.DailyTurns = len(game.DailyRun.Turns)
+	}
+	if game.Replay != nil {
+		rollback.Replayed = len(game.Replay.Inputs)
+	}
+	return rollback
+}
+
+// retakeTick plays the latest tick of a game again with the moves that arrived late for it,
+// and reports whether it did; caller must hold game.Mutex
+// Only snakes and food go back to how they were before the tick, and turns queued since are
+// kept. Nothing is taken back once the game's Rand has been drawn from since the tick.
+func (gm *Manager) retakeTick(game *models.Game) (engine.Result, bool) {
+	rollback := game.Rollback
+	if rollback == nil || len(rollback.Late) == 0 || rollback.Tick != game.Ticks {
+		return engine.Result{}, false
+	}
+	game.Rollback = nil
+	rewinder := game.Rand.(rng.Rewinder)
+	if rewinder.Position() != rollback.RandTo {
+		return engine.Result{}, false
+	}
+
+	queued := make(map[string][]constants.Direction, len(game.State.Snakes))
+	for _, snake := range game.State.Snakes {
+		queued[snake.ID] = snake.Turns
+	}
+	board := rollback.Board.Clone()
+	game.State.Snakes, game.State.Foods, game.State.Food = board.Snakes, board.Foods, board.Food
+	game.State.TeamScore = board.TeamScore
+	game.Respawns = rollback.Respawns
+	rewinder.Rewind(rollback.RandFrom)
+
+	inputs := maps.Clone(rollback.Inputs)
+	for snakeID, direction := range rollback.Late {
+		inputs[snakeID] = direction
+		game.TurnsTaken[snakeID]++
+	}
+	if game.DailyRun != nil {
+		game.DailyRun.Turns = game.DailyRun.Turns[:rollback.DailyTurns]
+		for _, snake := range game.State.Snakes {
+			if direction, exists := inputs[snake.ID]; exists {
+				game.DailyRun.Turns = append(game.DailyRun.Turns, models.Turn{Tick: game.Ticks, Direction: direction})
+			}
 		}
-		gm.broadcastToPlayers(game, constants.MSG_GAME_UPDATE, map[string]any{"data": stateCopy})
 	}
+	if game.Replay != nil {
+		game.Replay.Inputs = game.Replay.Inputs[:rollback.Replayed]
+		recordReplayInputs(game, inputs)
+	}
+	for i := range game.State.Snakes {
+		game.State.Snakes[i].Turns = queued[game.State.Snakes[i].ID]
+	}
+	result := engine.Step(engine.State{Board: game.State, Rules: game.Rules, Rand: game.Rand, Opening: game.Opening}, inputs)
+	if len(result.Crashed) > 0 {
+		gm.knockDown(game, result.Crashed)
+	}
+	retakenTicks.Inc()
+	return result, true
 }
 
 // endGame handles game ending (common for both single and multiplayer)
 func (gm *Manager) endGame(game *models.Game, winner string, stateCopy *models.GameState) {
 	game.Mutex.Lock()
-	game.IsActive = false
+	game.EndRound()
 	game.State.Status = "finished"
+	gm.openGames.close(game.ID)
 	game.State.Winner = winner
+	game.FinishedAt = gm.clock.Now()
 	game.State.IsSinglePlayer = game.IsSinglePlayer
 	game.Player1.Ready = false
 	if game.Player2 != nil {
@@ -159,33 +382,38 @@ func (gm *Manager) endGame(game *models.Game, winner string, stateCopy *models.G
 		stateCopy.Status = "finished"
 		stateCopy.Winner = winner
 	}
+	// Callers usually pass the live state; broadcast a frame that later changes can't touch
+	finalFrame := stateCopy.Clone()
+	stats := gm.recordMatchStats(game, winner)
+	lounge := hasLounge(game)
+	record := gm.closeReplay(game)
 
 	// Get player references before unlocking
-	player1 := game.Player1
-	player2 := game.Player2
+	player1, player2 := game.Player1, game.Player2
 	game.Mutex.Unlock()
 
-	// Broadcast game over
-	gm.broadcastToPlayers(game, constants.MSG_GAME_OVER, map[string]any{"data": stateCopy})
+	slog.Info("Game over", "game_id", game.ID, "winner", winner)
+	gm.emitMatchEnded(game, winner)
+	gm.recordOpponents(game, player1, player2, winner)
+	gm.recordClanRound(game, player1, player2, winner)
+	gm.recordTournamentMatch(game, player1, player2, winner, stats)
+	gm.recordCampaignLevel(game, player1, winner, stats)
+	gm.recordHighScore(game, player1, stats)
+	gm.recordDailyRun(game, player1, stats)
+	gm.recordSinglePlayerStats(game, player1, stats)
+	gm.retainFinishedGame(game)
+	gm.archiveReplay(record)
 
-	// Add players back to lobby if they still have active connections
-	// Check if player still exists (has active WebSocket connection)
-	if player1.Send == nil {
-		gm.BroadcastGamesList()
-		gm.BroadcastLobbyStatus()
-		return
-	}
-	if _, exists := gm.Lobby.Get(player1.ID); !exists {
-		gm.AddToLobby(player1)
-	}
-
-	if player2 == nil || player2.Send == nil {
-		gm.BroadcastGamesList()
-		gm.BroadcastLobbyStatus()
-		return
-	}
-	if _, exists := gm.Lobby.Get(player2.ID); !exists {
-		gm.AddToLobby(player2)
+	// Broadcast game over
+	gm.broadcastToPlayers(game, constants.MSG_GAME_OVER, map[string]any{"data": finalFrame})
+	gm.broadcastToPlayers(game, constants.MSG_MATCH_STATS, map[string]any{"game_id": game.ID, "data": stats})
+
+	// Multiplayer players stay in the game's lounge; a single player goes back to the lobby
+	// if they still have an active connection
+	if !lounge && player1.Connected() {
+		if _, exists := gm.Lobby.Get(player1.ID); !exists {
+			gm.AddToLobby(player1)
+		}
 	}
 
 	// Broadcast updated games list (finished games will be filtered out)
@@ -195,51 +423,39 @@ func (gm *Manager) endGame(game *models.Game, winner string, stateCopy *models.G
 	gm.BroadcastLobbyStatus()
 }
 
-// generateFood generates food position avoiding snake bodies (common utility)
-func (gm *Manager) generateFood(snakes []models.Snake) models.Position {
-	for {
-		food := models.Position{
-			X: rand.Intn(constants.GRID_WIDTH),
-			Y: rand.Intn(constants.GRID_HEIGHT),
-		}
-
-		valid := true
-		for _, snake := range snakes {
-			for _, bodyPart := range snake.Body {
-				if food.X == bodyPart.X && food.Y == bodyPart.Y {
-					valid = false
-					break
-				}
-			}
-			if !valid {
-				break
-			}
-		}
-
-		if valid {
-			return food
-		}
+// broadcastToPlayers broadcasts message to all players and spectators (common utility)
+// Casters of the game get it too, as a cast update naming the game.
+func (gm *Manager) broadcastToPlayers(game *models.Game, msgType string, data map[string]any) {
+	// Serialized once for all recipients
+	gm.broadcast(connectedParticipants(game), msgType, data)
+	if casters := connectedCasters(game); len(casters) > 0 {
+		gm.broadcast(casters, constants.MSG_CAST_UPDATE, castUpdate(game, msgType, data))
+	}
+	if watchedMessages[msgType] {
+		gm.broadcastToWatchers(game, msgType, data)
 	}
 }
 
-// broadcastToPlayers broadcasts message to all players and spectators (common utility)
-func (gm *Manager) broadcastToPlayers(game *models.Game, msgType string, data map[string]any) {
+// connectedParticipants returns a game's players and spectators that have an active connection
+func connectedParticipants(game *models.Game) []*models.Player {
+	game.Mutex.RLock()
+	recipients := make([]*models.Player, 0, 2+len(game.Spectators))
 	// Send to Player1 only if they have an active connection
-	if game.Player1 != nil && game.Player1.Send != nil {
-		gm.sendMessage(game.Player1, msgType, data)
+	if game.Player1 != nil && game.Player1.Connected() {
+		recipients = append(recipients, game.Player1)
 	}
 
 	// Send to Player2 if exists and has active connection (multiplayer only)
-	if game.Player2 != nil && game.Player2.Send != nil {
-		gm.sendMessage(game.Player2, msgType, data)
+	if game.Player2 != nil && game.Player2.Connected() {
+		recipients = append(recipients, game.Player2)
 	}
 
 	// Send to spectators only if they have active connections
-	game.Mutex.RLock()
 	for _, spectator := range game.Spectators {
-		if spectator != nil && spectator.Send != nil {
-			gm.sendMessage(spectator, msgType, data)
+		if spectator != nil && spectator.Connected() {
+			recipients = append(recipients, spectator)
 		}
 	}
 	game.Mutex.RUnlock()
+	return recipients
 }
@@ -1,15 +1,16 @@
 package game
 
 import (
-	"snake-backend/constants"
-	"snake-backend/models"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 )
 
 // PlayerReady handles player ready status for single player games
 func (gm *Manager) PlayerReadySingle(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		return
@@ -23,27 +24,128 @@ func (gm *Manager) PlayerReadySingle(player *models.Player, gameID string) {
 	game.State.Players = []models.PlayerStatus{
 		{ID: game.Player1.ID, Username: game.Player1.Username, Ready: game.Player1.Ready},
 	}
-	gameState := game.State
+	gameState := game.State.Clone()
 	game.Mutex.Unlock()
 
 	gm.broadcastToPlayers(game, constants.MSG_GAME_UPDATE, map[string]any{"data": gameState})
-	if game.Player1.Ready {
-		go gm.StartSinglePlayerGame(player)
+	switch {
+	case !game.Player1.Ready:
+	case game.CampaignLevel > 0:
+		gm.StartCampaignLevel(player, game.CampaignLevel)
+	default:
+		gm.StartSinglePlayerGame(player, game.Rules.Name, game.Difficulty.Name, game.Variant)
 	}
 }
 
-// checkCollisionsSingle checks collisions for single player games
-func (gm *Manager) checkCollisionsSingle(game *models.Game) string {
-	if len(game.State.Snakes) == 0 {
-		return ""
+// PauseSinglePlayerGame pauses or resumes a single player game for as long as its player
+// likes; there is no opponent to agree. Paused time doesn't count towards the round's
+// duration.
+func (gm *Manager) PauseSinglePlayerGame(player *models.Player, gameID string, paused bool) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Game not found",
+			"code":    "GAME_NOT_FOUND",
+		})
+		return
+	}
+
+	game.Mutex.Lock()
+	refusal := ""
+	switch {
+	case !game.IsSinglePlayer || game.Player1.ID != player.ID:
+		refusal = "Only your own single player game can be paused"
+	case !game.IsActive() || game.Countdown != nil:
+		refusal = "The game can only be paused while it is being played"
+	case game.DailyRun != nil:
+		refusal = "A daily run can't be paused"
 	}
-	head := game.State.Snakes[0].Body[0]
-	for j := 1; j < len(game.State.Snakes[0].Body); j++ {
-		if head.X != game.State.Snakes[0].Body[j].X || head.Y != game.State.Snakes[0].Body[j].Y {
-			continue
-		}
-		// Game over - player lost
-		return "game_over"
+	if refusal != "" {
+		game.Mutex.Unlock()
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": refusal,
+			"code":    "CANNOT_PAUSE",
+		})
+		return
 	}
-	return ""
+
+	now := gm.clock.Now()
+	switch {
+	case paused && game.PausedAt.IsZero():
+		game.PausedAt = now
+		game.State.Status = "paused"
+		gm.scheduler.remove(game.ID)
+	case !paused && !game.PausedAt.IsZero():
+		game.StartedAt = game.StartedAt.Add(now.Sub(game.PausedAt))
+		game.PausedAt = time.Time{}
+		game.State.Status = "playing"
+		gm.scheduler.add(game, game.Rules.TickInterval())
+	}
+	frame := game.State.Clone()
+	game.Mutex.Unlock()
+
+	gm.broadcastToPlayers(game, constants.MSG_GAME_UPDATE, map[string]any{"data": frame})
+}
+
+// RestartSinglePlayerGame starts a single player game over in place, finished or not: the
+// same game with the same settings gets a fresh snake and food and starts right away,
+// without a countdown. An unfinished round is abandoned without being recorded.
+func (gm *Manager) RestartSinglePlayerGame(player *models.Player, gameID string) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Game not found",
+			"code":    "GAME_NOT_FOUND",
+		})
+		return
+	}
+
+	game.Mutex.Lock()
+	refusal := ""
+	switch {
+	case !game.IsSinglePlayer || game.Player1.ID != player.ID:
+		refusal = "Only your own single player game can be restarted"
+	case game.Countdown != nil:
+		refusal = "The game is already starting"
+	}
+	if refusal != "" {
+		game.Mutex.Unlock()
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": refusal,
+			"code":    "CANNOT_RESTART",
+		})
+		return
+	}
+
+	abandoned := game.EndRound()
+	game.PausedAt = time.Time{}
+	game.Player1.Ready = true
+	game.State.Players = []models.PlayerStatus{
+		{ID: game.Player1.ID, Username: game.Player1.Username, Ready: true},
+	}
+	game.State.Winner = ""
+	game.State.SpeedStage = 0
+	if game.Difficulty.TickRateMs > 0 {
+		game.Rules.TickRateMs = game.Difficulty.TickRateMs
+	}
+	if game.Variant == constants.VARIANT_SCORE_ATTACK {
+		game.State.TimeLeft = int(ScoreAttackDuration.Seconds())
+	}
+	// A daily run replays the food of its day, any other game gets new food
+	if game.DailyRun != nil {
+		gm.setupDailyRun(game)
+	} else {
+		gm.seedGame(game)
+	}
+	gm.armCountdownLocked(game, 0, false)
+	game.Mutex.Unlock()
+
+	slog.Info("Single player game restarted", "game_id", gameID, "abandoned_round", abandoned)
+	// The countdown is already over, so this starts the round and schedules it at its tick
+	// rate; a tick of the abandoned round that got there first has done so already
+	gm.countdownTick(game)
 }
@@ -0,0 +1,137 @@
+package game
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
+
+	"github.com/google/uuid"
+)
+
+// Snake slots of a player_move; the guest seat only exists in hot-seat games
+const (
+	slotOwn   = 1 // The sender's own snake, also used when no slot is given
+	slotGuest = 2 // The guest snake of the sender's hot-seat game
+)
+
+// maxGuestNameLength is the longest guest name accepted, in characters
+const maxGuestNameLength = 24
+
+// StartHotSeatGame starts a local two-player game where both snakes are steered from the
+// player's connection
+// The second snake belongs to a guest seat: a player without a connection of its own that
+// is never registered, so it can't be reached or reconnected to from outside the game.
+func (gm *Manager) StartHotSeatGame(player *models.Player, rulesName string, guestName string) {
+	rules, valid := ResolveRules(rulesName)
+	if !valid {
+		gm.sendInvalidRules(player, rulesName)
+		return
+	}
+	gm.startHotSeat(player, rules, guestName, false)
+}
+
+// StartCoopGame starts a couch co-op game: a hot-seat game whose two snakes play as a team
+// Their scores add up to a team score, and the game is over as soon as either snake dies,
+// so the rules' win condition doesn't apply.
+func (gm *Manager) StartCoopGame(player *models.Player, rulesName string, guestName string) {
+	rules, valid := ResolveRules(rulesName)
+	if !valid {
+		gm.sendInvalidRules(player, rulesName)
+		return
+	}
+	gm.startHotSeat(player, rules, guestName, true)
+}
+
+// startHotSeat creates a hot-seat game with a guest seat and starts its countdown
+func (gm *Manager) startHotSeat(player *models.Player, rules models.RulesProfile, guestName string, coop bool) {
+	guestName = strings.TrimSpace(guestName)
+	if guestName == "" {
+		guestName = player.Username + " (guest)"
+	}
+	if utf8.RuneCountInString(guestName) > maxGuestNameLength || names.Equal(guestName, player.Username) {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"code":    "INVALID_GUEST_NAME",
+			"message": "Guest name must differ from your own and be at most 24 characters",
+		})
+		return
+	}
+
+	guest := &models.Player{
+		ID:       uuid.New().String(),
+		Username: guestName,
+		Ready:    true,
+		JoinedAt: gm.clock.Now(),
+	}
+
+	gameID := uuid.New().String()
+	game := &models.Game{
+		ID:         gameID,
+		Player1:    player,
+		Player2:    guest,
+		HotSeat:    true,
+		Coop:       coop,
+		Spectators: make(map[string]*models.Player),
+		Rules:      rules,
+		InstanceID: gm.Config().InstanceID,
+		Region:     gm.Config().Region,
+	}
+	game.State = &models.GameState{
+		ID:      gameID,
+		Status:  "countdown",
+		HotSeat: true,
+		Coop:    coop,
+		Players: []models.PlayerStatus{
+			{ID: player.ID, Username: player.Username, Ready: true},
+			{ID: guest.ID, Username: guest.Username, Ready: true},
+		},
+	}
+
+	gm.Mutex.Lock()
+	if limit := gm.gameCapacityExceededLocked(player.ID); limit != "" {
+		gm.Mutex.Unlock()
+		gm.sendServerFull(player, limit)
+		return
+	}
+	gm.addGameLocked(game)
+	gm.Mutex.Unlock()
+	gm.emitGameCreated(game)
+
+	gm.startCountdown(game, gm.Config().Countdown.GameSeconds, false)
+}
+
+// HandleSlotMove applies a move for the snake in the given slot of the player's game
+// Slot 2 steers the guest of a hot-seat or co-op game the player hosts
+func (gm *Manager) HandleSlotMove(player *models.Player, gameID string, slot int, direction string, tick *int) {
+	if slot != slotGuest {
+		gm.sendInvalidSlot(player, gameID, slot)
+		return
+	}
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return
+	}
+
+	game.Mutex.RLock()
+	guest := game.Player2
+	isHost := game.HotSeat && game.Player1.ID == player.ID
+	game.Mutex.RUnlock()
+
+	if !isHost {
+		gm.sendInvalidSlot(player, gameID, slot)
+		return
+	}
+	gm.HandlePlayerMove(guest, gameID, direction, tick)
+}
+
+// sendInvalidSlot tells a player that a move named a snake slot they don't control
+func (gm *Manager) sendInvalidSlot(player *models.Player, gameID string, slot int) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"game_id": gameID,
+		"code":    "INVALID_SNAKE_SLOT",
+		"message": "Unknown snake slot; slot 2 is only available in hot-seat games you started",
+		"slot":    slot,
+	})
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/bariiss/snake/backend/models"
+)
+
+// invite is a kind of invite a player sends another online player by username, such as to
+// a party, a clan or to watch a game
+// sendInvite runs the steps every kind shares; the hooks do what is particular to the kind.
+type invite struct {
+	msgType string // Message the invitee receives
+
+	// refuse answers the sender with the code and message of a refusal
+	refuse func(code, message string)
+	// check may refuse the invitee before the sender's allowance is spent, returning the code
+	// and message of the refusal; nil accepts any
+	check func(target *models.Player) (code, message string)
+	// record keeps the invite until the invitee answers and returns the fields of the
+	// message they receive, or the code and message of a refusal. Locks it takes are
+	// released before the refusal is sent.
+	record func(target *models.Player) (data map[string]any, code, message string)
+}
+
+// sendInvite finds the online invitee, spends one of the sender's invites and records the
+// invite, then sends it to the invitee with the sender's ID and username
+// An invitee who muted the sender never sees the invite; the sender isn't told. Returns the
+// invitee, nil when the invite was refused, and whether they were sent it.
+func (gm *Manager) sendInvite(player *models.Player, username string, kind invite) (*models.Player, bool) {
+	target := gm.FindPlayerByUsername(username)
+	if target == nil || !target.Connected() {
+		kind.refuse("PLAYER_NOT_FOUND", "No online player named "+strings.TrimSpace(username))
+		return nil, false
+	}
+	if kind.check != nil {
+		if code, message := kind.check(target); code != "" {
+			kind.refuse(code, message)
+			return nil, false
+		}
+	}
+	// Invites share the emote allowance, so they can't be used to spam other players
+	if !gm.allowEmote(player.ID) {
+		kind.refuse("INVITE_RATE_LIMITED", "Too many invites. Wait a moment before sending another.")
+		return nil, false
+	}
+	data, code, message := kind.record(target)
+	if code != "" {
+		kind.refuse(code, message)
+		return nil, false
+	}
+
+	if gm.mutedBy(target, player) {
+		return target, false
+	}
+	data["from_id"] = player.ID
+	data["from_username"] = player.Username
+	gm.sendMessage(target, kind.msgType, data)
+	return target, true
+}
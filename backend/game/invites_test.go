@@ -0,0 +1,161 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bariiss/snake/backend/clans"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// lobbyPlayer puts a connected player in the lobby and returns it with its connection
+func lobbyPlayer(gm *Manager, id, username string) (*models.Player, *models.Connection) {
+	player := &models.Player{ID: id, Username: username}
+	conn := models.NewConnection(64)
+	player.Attach(conn)
+	gm.RegisterPlayer(player)
+	gm.Lobby.Add(player)
+	return player, conn
+}
+
+// received decodes the messages waiting on a connection
+func received(t *testing.T, conn *models.Connection) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	for _, data := range conn.Drain(nil) {
+		var message map[string]any
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// ofType returns the first message of a type, nil if there is none
+func ofType(messages []map[string]any, msgType string) map[string]any {
+	for _, message := range messages {
+		if message["type"] == msgType {
+			return message
+		}
+	}
+	return nil
+}
+
+func TestSendInvite(t *testing.T) {
+	tests := []struct {
+		name     string
+		invite   func(gm *Manager, sender *models.Player)
+		msgType  string
+		wantData map[string]any
+	}{
+		{
+			"party",
+			func(gm *Manager, sender *models.Player) { gm.InviteToParty(sender, "Bob") },
+			constants.MSG_PARTY_INVITE,
+			map[string]any{"members": []any{"alice"}},
+		},
+		{
+			"spectator",
+			func(gm *Manager, sender *models.Player) {
+				gm.Mutex.Lock()
+				gm.addGameLocked(&models.Game{
+					ID:         "g1",
+					Player1:    sender,
+					Spectators: make(map[string]*models.Player),
+					State:      &models.GameState{ID: "g1", Status: "playing"},
+				})
+				gm.Mutex.Unlock()
+				gm.InviteSpectator(sender, "g1", "bob")
+			},
+			constants.MSG_SPECTATOR_INVITE,
+			map[string]any{"game_id": "g1", "players": []any{"alice"}, "link": spectateLink("g1")},
+		},
+		{
+			"clan",
+			func(gm *Manager, sender *models.Player) {
+				store, err := clans.Open(storage.NewMemory())
+				if err != nil {
+					t.Fatal(err)
+				}
+				gm.SetClans(store)
+				if _, err := store.Create("SNK", "Snakes", sender.ID, sender.Username); err != nil {
+					t.Fatal(err)
+				}
+				gm.InviteToClan(sender, "bob")
+			},
+			constants.MSG_CLAN_INVITE,
+			map[string]any{"tag": "SNK", "name": "Snakes", "members": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, _ := newTestManager(t, 1)
+			alice, aliceConn := lobbyPlayer(gm, "a", "alice")
+			_, bobConn := lobbyPlayer(gm, "b", "bob")
+
+			tt.invite(gm, alice)
+			invite := ofType(received(t, bobConn), tt.msgType)
+			if invite == nil {
+				t.Fatalf("bob got no %s", tt.msgType)
+			}
+			if invite["from_id"] != "a" || invite["from_username"] != "alice" {
+				t.Errorf("invite from %v/%v, want a/alice", invite["from_id"], invite["from_username"])
+			}
+			for key, want := range tt.wantData {
+				if got, _ := json.Marshal(invite[key]); string(got) != mustJSON(t, want) {
+					t.Errorf("%s = %s, want %s", key, got, mustJSON(t, want))
+				}
+			}
+			if errMsg := ofType(received(t, aliceConn), constants.MSG_ERROR); errMsg != nil {
+				t.Errorf("sender got an error: %v", errMsg)
+			}
+		})
+	}
+}
+
+func TestSendInviteRefusals(t *testing.T) {
+	gm, _ := newTestManager(t, 1)
+	alice, aliceConn := lobbyPlayer(gm, "a", "alice")
+	lobbyPlayer(gm, "b", "bob")
+	offline := &models.Player{ID: "c", Username: "carol"}
+	gm.Lobby.Add(offline)
+
+	tests := []struct {
+		name     string
+		username string
+		code     string
+	}{
+		{"unknown player", "nobody", "PLAYER_NOT_FOUND"},
+		{"offline player", "carol", "PLAYER_NOT_FOUND"},
+		{"refused by the kind's check", "alice", "INVALID_PARTY_INVITE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm.InviteToParty(alice, tt.username)
+			errMsg := ofType(received(t, aliceConn), constants.MSG_ERROR)
+			if errMsg == nil || errMsg["code"] != tt.code {
+				t.Errorf("error = %v, want code %s", errMsg, tt.code)
+			}
+		})
+	}
+
+	// Invites share the emote allowance, which the fake clock never refills
+	for range 20 {
+		gm.InviteToParty(alice, "bob")
+	}
+	if errMsg := ofType(received(t, aliceConn), constants.MSG_ERROR); errMsg == nil || errMsg["code"] != "INVITE_RATE_LIMITED" {
+		t.Errorf("error after a burst of invites = %v, want INVITE_RATE_LIMITED", errMsg)
+	}
+}
+
+func mustJSON(t *testing.T, value any) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
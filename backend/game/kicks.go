@@ -0,0 +1,124 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
+)
+
+var (
+	// ErrPlayerNotFound is returned when acting on a player who isn't connected
+	ErrPlayerNotFound = errors.New("player not found")
+	// ErrRemotePlayer is returned when acting on a player connected to another instance
+	ErrRemotePlayer = errors.New("player is connected to another instance")
+)
+
+// DefaultKickCooldown is how long a kicked player can't reconnect when no cooldown is given
+const DefaultKickCooldown = 5 * time.Minute
+
+// MaxKickCooldown is the longest a kick keeps a player out; longer absences call for a ban
+const MaxKickCooldown = 24 * time.Hour
+
+var playersKicked = metrics.NewCounter("snake_players_kicked_total", "Players an admin kicked off the server")
+
+// Kick records an admin removing a connected player
+type Kick struct {
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"`
+	Reason   string    `json:"reason,omitempty"`
+	By       string    `json:"by"`
+	At       time.Time `json:"at"`
+	Until    time.Time `json:"until"` // Until when the player's token and username can't be used to connect
+}
+
+// KickPlayer removes a player connected to this instance
+// They are told why in a kicked message before their connection is closed, which takes them
+// out of the lobby, their party and their games like any disconnect, without a reconnect
+// grace period. Their token is revoked, and their username can't be logged in with, until
+// the cooldown is over.
+func (gm *Manager) KickPlayer(playerID, reason, by string, cooldown time.Duration) (Kick, error) {
+	player, exists := gm.registeredPlayer(playerID)
+	switch {
+	case !exists:
+		return Kick{}, ErrPlayerNotFound
+	case player.InstanceID != "":
+		return Kick{}, fmt.Errorf("%w %s", ErrRemotePlayer, player.InstanceID)
+	}
+
+	now := gm.clock.Now()
+	kick := Kick{
+		PlayerID: player.ID,
+		Username: player.Username,
+		Reason:   reason,
+		By:       by,
+		At:       now,
+		Until:    now.Add(cooldown),
+	}
+	gm.kicksMu.Lock()
+	for id, previous := range gm.kicks {
+		if !now.Before(previous.Until) {
+			delete(gm.kicks, id)
+		}
+	}
+	gm.kicks[player.ID] = kick
+	gm.kicksMu.Unlock()
+
+	minutes := int(math.Ceil(cooldown.Minutes()))
+	gm.removeFromServer(player, map[string]any{
+		"code":    "KICKED",
+		"message": fmt.Sprintf("You were removed from the server by an admin; you can reconnect in %d min", minutes),
+		"reason":  reason,
+		"until":   kick.Until,
+	})
+	playersKicked.Inc()
+	player.Logger().Info("Player kicked", "by", by, "reason", reason, "until", kick.Until)
+	return kick, nil
+}
+
+// removeFromServer sends a player the kicked message with the given fields and closes their
+// connection, which takes them out of the lobby, their party and their games
+func (gm *Manager) removeFromServer(player *models.Player, data map[string]any) {
+	gm.sendMessage(player, constants.MSG_KICKED, data)
+	if conn := player.Connection(); conn != nil {
+		conn.Close(models.CloseKicked)
+	} else {
+		// Without a connection no pump cleans up after the player
+		gm.RemovePlayer(player.ID)
+	}
+}
+
+// KickedPlayer returns the kick keeping a player's token from connecting, false when there
+// is none in effect
+func (gm *Manager) KickedPlayer(playerID string) (Kick, bool) {
+	gm.kicksMu.Lock()
+	defer gm.kicksMu.Unlock()
+	kick, exists := gm.kicks[playerID]
+	return kick, exists && gm.clock.Now().Before(kick.Until)
+}
+
+// KickedUsername returns the kick keeping a username from being logged in with, false when
+// there is none in effect
+func (gm *Manager) KickedUsername(username string) (Kick, bool) {
+	key := names.Key(username)
+	now := gm.clock.Now()
+	gm.kicksMu.Lock()
+	defer gm.kicksMu.Unlock()
+	for _, kick := range gm.kicks {
+		if names.Key(kick.Username) == key && now.Before(kick.Until) {
+			return kick, true
+		}
+	}
+	return Kick{}, false
+}
+
+// kicked reports whether a player was kicked and can't come back yet
+func (gm *Manager) kicked(playerID string) bool {
+	_, kicked := gm.KickedPlayer(playerID)
+	return kicked
+}
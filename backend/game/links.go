@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+)
+
+// ErrLinksUnavailable is returned when looking up linked accounts without a links store
+var ErrLinksUnavailable = errors.New("account links are not available on this server")
+
+var linkedConnections = metrics.NewCounter("snake_linked_connections_total", "Connections from an account sharing a device or address with another account")
+
+// EntrantLink is a pair of entrants of a tournament whose accounts share signals
+type EntrantLink struct {
+	PlayerID       string   `json:"player_id"`
+	Username       string   `json:"username"`
+	LinkedID       string   `json:"linked_id"`
+	LinkedUsername string   `json:"linked_username"`
+	Signals        []string `json:"signals"` // Signals shared, strongest first
+}
+
+// SetLinks sets the store correlating accounts by the signals of their connections
+// Call before the server starts accepting connections; without it no account is linked.
+func (gm *Manager) SetLinks(links *moderation.Links) {
+	gm.links = links
+}
+
+// ObserveConnection records the signals a player's new connection left, by signal, such as
+// its device ID and address; bots are left out
+func (gm *Manager) ObserveConnection(player *models.Player, signals map[string]string) {
+	if gm.links == nil || player.Bot {
+		return
+	}
+	if err := gm.links.Observe(player.ID, player.Username, signals); err != nil {
+		player.Logger().Error("Failed to persist connection signals", "error", err)
+	}
+	if links := gm.links.Linked(player.ID); len(links) > 0 {
+		linkedConnections.Inc()
+		player.Logger().Info("Connection shares signals with other accounts", "linked", len(links))
+	}
+}
+
+// LinkedAccounts returns the accounts sharing signals with a player, those sharing the most
+// first
+func (gm *Manager) LinkedAccounts(playerID string) ([]moderation.Link, error) {
+	if gm.links == nil {
+		return nil, ErrLinksUnavailable
+	}
+	return gm.links.Linked(playerID), nil
+}
+
+// TournamentLinks returns the pairs of a tournament's entrants whose accounts share signals,
+// each pair once, so moderators can enforce one account per person
+func (gm *Manager) TournamentLinks(tournamentID string) ([]EntrantLink, error) {
+	if gm.links == nil {
+		return nil, ErrLinksUnavailable
+	}
+	gm.tournamentsMu.Lock()
+	t, exists := gm.tournaments[tournamentID]
+	var entrants map[string]string
+	var order []string
+	if exists {
+		entrants = make(map[string]string, len(t.Entrants))
+		for _, entrant := range t.Entrants {
+			entrants[entrant.ID] = entrant.Username
+			order = append(order, entrant.ID)
+		}
+	}
+	gm.tournamentsMu.Unlock()
+	if !exists {
+		return nil, ErrTournamentNotFound
+	}
+
+	pairs := []EntrantLink{}
+	reported := make(map[string]bool, len(order))
+	for _, playerID := range order {
+		reported[playerID] = true
+		for _, link := range gm.links.Linked(playerID) {
+			username, entered := entrants[link.PlayerID]
+			if !entered || reported[link.PlayerID] {
+				continue
+			}
+			pairs = append(pairs, EntrantLink{
+				PlayerID:       playerID,
+				Username:       entrants[playerID],
+				LinkedID:       link.PlayerID,
+				LinkedUsername: username,
+				Signals:        link.Signals,
+			})
+		}
+	}
+	return pairs, nil
+}
+
+// linkedCount returns how many accounts share signals with a player
+func (gm *Manager) linkedCount(playerID string) int {
+	if gm.links == nil {
+		return 0
+	}
+	return len(gm.links.Linked(playerID))
+}
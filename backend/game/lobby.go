@@ -1,52 +1,48 @@
 package game
 
 import (
-	"encoding/json"
-	"log"
+	"log/slog"
 	"maps"
-	"strings"
+	"slices"
 
-	"snake-backend/constants"
-	"snake-backend/models"
-
-	"github.com/pion/webrtc/v3"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/i18n"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
 )
 
 // UsernameExists checks if a username is already in use (in lobby, active games, or spectators)
-// Case-insensitive comparison
+// Names that look alike are the same name, see names.Equal
 // Only checks players with active connections (Send channel is not nil)
 func (gm *Manager) UsernameExists(username string) bool {
-	usernameLower := strings.ToLower(strings.TrimSpace(username))
-	if usernameLower == "" {
+	key := names.Key(username)
+	if key == "" {
 		return false
 	}
 
-	// Check lobby (case-insensitive) - only players with active connections
+	// Check lobby - only players with active connections
 	for _, p := range gm.Lobby.Snapshot() {
-		if strings.EqualFold(p.Username, username) && p.Send != nil {
+		if names.Key(p.Username) == key && p.Connected() {
 			return true
 		}
 	}
 
 	// Check active games and spectators - only players with active connections
-	gm.Mutex.RLock()
-	defer gm.Mutex.RUnlock()
-
-	for _, game := range gm.Games {
+	for _, game := range gm.gamesSnapshot() {
 		game.Mutex.RLock()
 		// Check Player1 - only if has active connection
-		if game.Player1 != nil && strings.EqualFold(game.Player1.Username, username) && game.Player1.Send != nil {
+		if game.Player1 != nil && names.Key(game.Player1.Username) == key && game.Player1.Connected() {
 			game.Mutex.RUnlock()
 			return true
 		}
 		// Check Player2 - only if has active connection
-		if game.Player2 != nil && strings.EqualFold(game.Player2.Username, username) && game.Player2.Send != nil {
+		if game.Player2 != nil && names.Key(game.Player2.Username) == key && game.Player2.Connected() {
 			game.Mutex.RUnlock()
 			return true
 		}
 		// Check spectators - only if has active connection
 		for _, spectator := range game.Spectators {
-			if !strings.EqualFold(spectator.Username, username) || spectator.Send == nil {
+			if names.Key(spectator.Username) != key || !spectator.Connected() {
 				continue
 			}
 			game.Mutex.RUnlock()
@@ -65,25 +61,17 @@ func (gm *Manager) FindPlayerByID(playerID string) *models.Player {
 	}
 
 	// First check global player registry
-	gm.Mutex.RLock()
-	if player, exists := gm.Players[playerID]; exists {
-		gm.Mutex.RUnlock()
+	if player, exists := gm.registeredPlayer(playerID); exists {
 		return player
 	}
-	gm.Mutex.RUnlock()
 
 	// Check lobby
-	for _, p := range gm.Lobby.Snapshot() {
-		if p.ID == playerID {
-			return p
-		}
+	if player, exists := gm.Lobby.Get(playerID); exists {
+		return player
 	}
 
-	// Check active games
-	gm.Mutex.RLock()
-	defer gm.Mutex.RUnlock()
-
-	for _, game := range gm.Games {
+	// Check the games the player is indexed in
+	for _, game := range gm.gamesOf(playerID) {
 		game.Mutex.RLock()
 		if game.Player1 != nil && game.Player1.ID == playerID {
 			game.Mutex.RUnlock()
@@ -107,38 +95,35 @@ func (gm *Manager) FindPlayerByID(playerID string) *models.Player {
 	return nil
 }
 
-// FindPlayerByUsername finds a player by username (case-insensitive)
+// FindPlayerByUsername finds a player by username, or by a name that looks alike
 // Returns the player if found, nil otherwise
 func (gm *Manager) FindPlayerByUsername(username string) *models.Player {
-	usernameLower := strings.ToLower(strings.TrimSpace(username))
-	if usernameLower == "" {
+	key := names.Key(username)
+	if key == "" {
 		return nil
 	}
 
 	// Check lobby
 	for _, p := range gm.Lobby.Snapshot() {
-		if strings.EqualFold(p.Username, username) {
+		if names.Key(p.Username) == key {
 			return p
 		}
 	}
 
 	// Check active games
-	gm.Mutex.RLock()
-	defer gm.Mutex.RUnlock()
-
-	for _, game := range gm.Games {
+	for _, game := range gm.gamesSnapshot() {
 		game.Mutex.RLock()
-		if game.Player1 != nil && strings.EqualFold(game.Player1.Username, username) {
+		if game.Player1 != nil && names.Key(game.Player1.Username) == key {
 			game.Mutex.RUnlock()
 			return game.Player1
 		}
-		if game.Player2 != nil && strings.EqualFold(game.Player2.Username, username) {
+		if game.Player2 != nil && names.Key(game.Player2.Username) == key {
 			game.Mutex.RUnlock()
 			return game.Player2
 		}
 		// Check spectators
 		for _, spectator := range game.Spectators {
-			if !strings.EqualFold(spectator.Username, username) {
+			if names.Key(spectator.Username) != key {
 				continue
 			}
 			game.Mutex.RUnlock()
@@ -151,12 +136,17 @@ func (gm *Manager) FindPlayerByUsername(username string) *models.Player {
 }
 
 func (gm *Manager) AddToLobby(player *models.Player) {
+	// Remote players are listed in the lobby of the instance they are connected to
+	if gm.isRemote(player) {
+		return
+	}
+
 	if added := gm.Lobby.Add(player); !added {
-		log.Printf("Player %s (%s) already in lobby", player.ID, player.Username)
+		player.Logger().Debug("Player already in lobby")
 		return
 	}
 
-	log.Printf("Player %s (%s) added to lobby, total players: %d", player.ID, player.Username, gm.Lobby.Len())
+	player.Logger().Info("Player added to lobby", "lobby_size", gm.Lobby.Len())
 
 	gm.BroadcastLobbyStatus()
 	gm.SendGamesList(player)
@@ -167,31 +157,28 @@ func (gm *Manager) RemoveFromLobby(playerID string) {
 	gm.BroadcastLobbyStatus()
 }
 
+// BroadcastLobbyStatus sends the lobby to local players and announces it to other instances
 func (gm *Manager) BroadcastLobbyStatus() {
+	gm.publishPresence()
+	gm.broadcastLobbyStatusLocal()
+}
+
+// broadcastLobbyStatusLocal sends the combined lobby of all instances to local lobby players
+func (gm *Manager) broadcastLobbyStatusLocal() {
 	players := gm.Lobby.Snapshot()
 
-	log.Printf("Broadcasting lobby status to %d players", len(players))
+	slog.Debug("Broadcasting lobby status", "recipients", len(players))
 
 	// Check which players are in active games
-	gm.Mutex.RLock()
-	playersInGame := make(map[string]bool)
-	for _, game := range gm.Games {
-		game.Mutex.RLock()
-		if game.State != nil && game.State.Status != "finished" {
-			if game.Player1 != nil {
-				playersInGame[game.Player1.ID] = true
-			}
-			if game.Player2 != nil {
-				playersInGame[game.Player2.ID] = true
-			}
-		}
-		game.Mutex.RUnlock()
-	}
-	gm.Mutex.RUnlock()
+	playersInGame := gm.playersInGame()
 
 	// Add in_game status to players
 	playersWithStatus := make([]map[string]any, 0, len(players))
+	var restricted []*models.Player
 	for _, p := range players {
+		if !p.Bot && gm.restricted(p.ID) {
+			restricted = append(restricted, p)
+		}
 		playerData := map[string]any{
 			"id":        p.ID,
 			"username":  p.Username,
@@ -201,84 +188,131 @@ func (gm *Manager) BroadcastLobbyStatus() {
 		if playersInGame[p.ID] {
 			playerData["in_game"] = true
 		}
+		if p.Bot {
+			playerData["bot"] = true
+		}
+		if title := gm.titleName(p.ID); title != "" {
+			playerData["title"] = title
+		}
+		if tag := gm.clanTag(p.ID); tag != "" {
+			playerData["clan"] = tag
+		}
 		playersWithStatus = append(playersWithStatus, playerData)
 	}
+	playersWithStatus = append(playersWithStatus, gm.cluster.remoteLobbyEntries()...)
 
-	for _, p := range players {
-		gm.sendMessage(p, constants.MSG_LOBBY_STATUS, map[string]any{
+	if len(restricted) == 0 {
+		gm.broadcast(players, constants.MSG_LOBBY_STATUS, map[string]any{
 			"players": playersWithStatus,
 		})
-		log.Printf("Sent lobby status to player %s (%s)", p.ID, p.Username)
+		return
+	}
+	// Restricted players are left out of everyone's lobby but their own, so they don't notice
+	hidden := func(entry map[string]any, viewer string) bool {
+		return slices.ContainsFunc(restricted, func(p *models.Player) bool {
+			return p.ID == entry["id"] && p.ID != viewer
+		})
+	}
+	public := slices.DeleteFunc(slices.Clone(playersWithStatus), func(entry map[string]any) bool {
+		return hidden(entry, "")
+	})
+	gm.broadcast(slices.DeleteFunc(slices.Clone(players), func(p *models.Player) bool {
+		return slices.Contains(restricted, p)
+	}), constants.MSG_LOBBY_STATUS, map[string]any{
+		"players": public,
+	})
+	for _, p := range restricted {
+		gm.sendMessage(p, constants.MSG_LOBBY_STATUS, map[string]any{
+			"players": slices.DeleteFunc(slices.Clone(playersWithStatus), func(entry map[string]any) bool {
+				return hidden(entry, p.ID)
+			}),
+		})
 	}
 }
 
+// playersInGame returns the IDs of players taking part in unfinished games
+func (gm *Manager) playersInGame() map[string]bool {
+	playersInGame := make(map[string]bool)
+	for _, game := range gm.gamesSnapshot() {
+		game.Mutex.RLock()
+		if game.State != nil && game.State.Status != "finished" {
+			if game.Player1 != nil {
+				playersInGame[game.Player1.ID] = true
+			}
+			if game.Player2 != nil {
+				playersInGame[game.Player2.ID] = true
+			}
+		}
+		game.Mutex.RUnlock()
+	}
+	return playersInGame
+}
+
 func (gm *Manager) sendMessage(player *models.Player, msgType string, data map[string]any) {
 	if player == nil {
 		return
 	}
+	if msgType == constants.MSG_ERROR {
+		data = tagError(player, data)
+	}
+	// Errors and system messages carry a code and a message to show; the message is sent in
+	// the player's language
+	if conn := player.Connection(); conn != nil {
+		data = i18n.Localize(conn.Language, data)
+	}
+	gm.deliver(player, newOutboundMessage(msgType, data))
+}
 
-	message := map[string]any{
-		"type": msgType,
+// tagError adds the connection ID to an error response and logs it, so a report quoting
+// the error can be matched with the server logs
+func tagError(player *models.Player, data map[string]any) map[string]any {
+	tagged := make(map[string]any, len(data)+1)
+	maps.Copy(tagged, data)
+	if conn := player.LastConnection(); conn != nil {
+		tagged["conn_id"] = conn.ID
 	}
-	maps.Copy(message, data)
-
-	jsonData, _ := json.Marshal(message)
-
-	// Check if player has active P2P connection for game updates
-	hasP2PConnection := false
-	if gm.WebRTCManager != nil && msgType == constants.MSG_GAME_UPDATE {
-		peer, exists := gm.WebRTCManager.GetPeer(player.ID)
-		if exists && peer != nil && peer.DataChannel != nil {
-			// Check if data channel is open
-			if peer.DataChannel.ReadyState() == webrtc.DataChannelStateOpen {
-				hasP2PConnection = true
-			}
-		}
+
+	gameID, _ := data["game_id"].(string)
+	player.Logger().Info("Error response sent", "code", data["code"], "message", data["message"], "game_id", gameID)
+	return tagged
+}
+
+// enqueue queues a serialized message on the player's connection
+// A game update replaces any update the client has not received yet, so a lagging client
+// skips straight to the newest state; any other message that does not fit disconnects the
+// client so it reconnects and resyncs
+func (gm *Manager) enqueue(player *models.Player, msgType string, data []byte) {
+	conn := player.Connection()
+	if conn == nil {
+		return
 	}
 
-	// For game updates: if P2P connection is active, skip WebSocket and send only via P2P
-	// For other messages: send via WebSocket (lobby/matchmaking/signaling)
-	if !hasP2PConnection {
-		// Try WebSocket (for lobby/matchmaking/non-P2P game updates)
-		if player.Send != nil {
-			// Use recover to handle case where channel is closed
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						// Channel closed - player disconnected, this is expected
-						// Don't log as error for game updates (they're frequent)
-						if msgType != constants.MSG_GAME_UPDATE {
-							log.Printf("Failed to send WebSocket message to player %s (%s) - channel closed: %v", player.ID, player.Username, r)
-						}
-					}
-				}()
-				select {
-				case player.Send <- jsonData:
-					// Successfully sent
-				default:
-					// Channel full - for game updates, this is OK (next update will come soon)
-					// Only log for non-game-update messages
-					if msgType != constants.MSG_GAME_UPDATE {
-						log.Printf("Failed to send WebSocket message to player %s (%s) - channel full", player.ID, player.Username)
-					}
-				}
-			}()
+	switch err := conn.Enqueue(data, msgType == constants.MSG_GAME_UPDATE); err {
+	case nil:
+	case models.ErrMessageDropped:
+		staleFramesDropped.Inc()
+	case models.ErrSlowConsumer:
+		slowConsumerDisconnects.Inc()
+		player.Logger().Warn("Disconnecting slow player: send buffer full", "msg_type", msgType)
+	case models.ErrConnectionClosed:
+		// Don't log game updates (they're frequent)
+		if msgType != constants.MSG_GAME_UPDATE {
+			player.Logger().Debug("Dropping message for closed connection", "msg_type", msgType)
 		}
 	}
+}
 
-	// Send via WebRTC/P2P if available
-	// For game updates with P2P: only send via P2P (skip WebSocket)
-	// For other messages: send via both WebSocket and P2P (if available)
-	if gm.WebRTCManager != nil {
-		gm.WebRTCManager.SendMessage(player.ID, msgType, data)
-	}
+// SendError sends an error message with a machine-readable code to a player
+func (gm *Manager) SendError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":    code,
+		"message": message,
+	})
 }
 
 // SendPeerOffer sends a peer-to-peer offer to a player
 func (gm *Manager) SendPeerOffer(playerID string, offer any) {
-	gm.Mutex.RLock()
 	player, exists := gm.Lobby.Get(playerID)
-	gm.Mutex.RUnlock()
 
 	if !exists {
 		return
@@ -292,9 +326,7 @@ func (gm *Manager) SendPeerOffer(playerID string, offer any) {
 
 // SendPeerAnswer sends a peer-to-peer answer to a player
 func (gm *Manager) SendPeerAnswer(playerID string, answer any) {
-	gm.Mutex.RLock()
 	player, exists := gm.Lobby.Get(playerID)
-	gm.Mutex.RUnlock()
 
 	if !exists {
 		return
@@ -308,9 +340,7 @@ func (gm *Manager) SendPeerAnswer(playerID string, answer any) {
 
 // SendICECandidate sends an ICE candidate to a player
 func (gm *Manager) SendICECandidate(playerID string, candidate any) {
-	gm.Mutex.RLock()
 	player, exists := gm.Lobby.Get(playerID)
-	gm.Mutex.RUnlock()
 
 	if !exists {
 		return
@@ -322,10 +352,19 @@ func (gm *Manager) SendICECandidate(playerID string, candidate any) {
 	})
 }
 
+// SendGamesList sends the unfinished games of all instances to a player
 func (gm *Manager) SendGamesList(player *models.Player) {
-	gm.Mutex.RLock()
-	gamesList := make([]map[string]any, 0, len(gm.Games))
-	for gameID, game := range gm.Games {
+	gamesList := append(gm.localGamesList(), gm.cluster.remoteGames()...)
+	gm.sendMessage(player, constants.MSG_GAMES_LIST, map[string]any{
+		"games": gamesList,
+	})
+}
+
+// localGamesList describes the unfinished games hosted by this instance
+func (gm *Manager) localGamesList() []map[string]any {
+	games := gm.gamesSnapshot()
+	gamesList := make([]map[string]any, 0, len(games))
+	for _, game := range games {
 		game.Mutex.RLock()
 		// Skip finished games - they shouldn't appear in the lobby
 		if game.State.Status == "finished" {
@@ -334,10 +373,12 @@ func (gm *Manager) SendGamesList(player *models.Player) {
 		}
 
 		gameInfo := map[string]any{
-			"id":         gameID,
-			"player1":    game.Player1.Username,
-			"status":     game.State.Status,
-			"spectators": len(game.Spectators),
+			"id":          game.ID,
+			"player1":     game.Player1.Username,
+			"status":      game.State.Status,
+			"spectators":  len(game.Spectators),
+			"instance_id": game.InstanceID,
+			"region":      game.Region,
 		}
 		// Only include player2 if it's a multiplayer game
 		if !game.IsSinglePlayer && game.Player2 != nil {
@@ -378,28 +419,37 @@ func (gm *Manager) SendGamesList(player *models.Player) {
 		game.Mutex.RUnlock()
 		gamesList = append(gamesList, gameInfo)
 	}
-	gm.Mutex.RUnlock()
 
-	gm.sendMessage(player, constants.MSG_GAMES_LIST, map[string]any{
-		"games": gamesList,
-	})
+	return gamesList
 }
 
+// BroadcastGamesList sends the games list to local lobby players and announces local games
+// to other instances
 func (gm *Manager) BroadcastGamesList() {
+	gm.publishGames()
+	gm.broadcastGamesListLocal()
+}
+
+// broadcastGamesListLocal sends the games list to local lobby players
+func (gm *Manager) broadcastGamesListLocal() {
 	players := gm.Lobby.Snapshot()
-	for _, p := range players {
-		gm.SendGamesList(p)
+	if len(players) == 0 {
+		return
 	}
+
+	gamesList := append(gm.localGamesList(), gm.cluster.remoteGames()...)
+	gm.broadcast(players, constants.MSG_GAMES_LIST, map[string]any{
+		"games": gamesList,
+	})
 }
 
 // SendGameState sends the current game state to a player
 func (gm *Manager) SendGameState(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Game not found",
 			"code":    "GAME_NOT_FOUND",
 		})
@@ -414,6 +464,7 @@ func (gm *Manager) SendGameState(player *models.Player, gameID string) {
 
 	if !isPlayer && !isSpectator {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "You are not part of this game",
 			"code":    "NOT_A_PLAYER",
 		})
@@ -422,7 +473,7 @@ func (gm *Manager) SendGameState(player *models.Player, gameID string) {
 
 	// Send current game state
 	game.Mutex.RLock()
-	stateCopy := game.State
+	stateCopy := game.State.Clone()
 	game.Mutex.RUnlock()
 
 	gm.sendMessage(player, constants.MSG_GAME_UPDATE, map[string]any{"data": stateCopy})
@@ -430,42 +481,43 @@ func (gm *Manager) SendGameState(player *models.Player, gameID string) {
 
 // RestorePlayerGameState restores game state for a reconnecting player
 func (gm *Manager) RestorePlayerGameState(player *models.Player) {
-	gm.Mutex.RLock()
-	defer gm.Mutex.RUnlock()
-
 	// Find active game for this player
-	for gameID, game := range gm.Games {
+	for _, game := range gm.gamesOf(player.ID) {
+		gameID := game.ID
 		game.Mutex.RLock()
 		isPlayer := game.Player1.ID == player.ID || (game.Player2 != nil && game.Player2.ID == player.ID)
 		isSpectator := game.Spectators[player.ID] != nil
-		isActive := game.IsActive
-		gameState := game.State
+		isActive := game.IsActive()
+		_, awaited := game.Reconnects[player.ID]
+		gameState := game.State.Clone()
 		game.Mutex.RUnlock()
 
-		if (isPlayer || isSpectator) && isActive && gameState != nil {
+		// A player the game waits for is back even if their round ended in the meantime
+		if (isPlayer || isSpectator) && (isActive || awaited) && gameState != nil {
 			// Player is in an active game - restore game state
-			log.Printf("Restoring game state for reconnecting player %s (game: %s)", player.Username, gameID)
+			player.Logger().Info("Restoring game state for reconnecting player", "game_id", gameID)
 
-			// Update player's Send channel reference in game
+			// Update player's connection reference in game
 			game.Mutex.Lock()
+			conn := player.Connection()
 			if game.Player1.ID == player.ID {
-				game.Player1.Send = player.Send
+				game.Player1.Attach(conn)
 			} else if game.Player2 != nil && game.Player2.ID == player.ID {
-				game.Player2.Send = player.Send
+				game.Player2.Attach(conn)
 			} else if isSpectator {
-				if game.Spectators[player.ID] != nil {
-					game.Spectators[player.ID].Send = player.Send
+				if spectator := game.Spectators[player.ID]; spectator != nil {
+					spectator.Attach(conn)
 				}
 			}
 			game.Mutex.Unlock()
+			gm.resumeAwaited(game, player)
 
 			// Send current game state
-			stateCopy := *gameState
-			gm.sendMessage(player, constants.MSG_GAME_UPDATE, map[string]any{"data": stateCopy})
+			gm.sendMessage(player, constants.MSG_GAME_UPDATE, map[string]any{"data": gameState})
 
 			// If game is playing, also send game_start to ensure frontend is in correct state
 			if gameState.Status == "playing" {
-				gm.sendMessage(player, constants.MSG_GAME_START, map[string]any{"data": stateCopy})
+				gm.sendMessage(player, constants.MSG_GAME_START, map[string]any{"data": gameState})
 			}
 
 			return
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// A multiplayer game that ends becomes a post-game lounge: its players stay in it, rather
+// than going back to the lobby, to read the match stats, chat with the spectators and
+// arrange a rematch. The lounge lasts as long as the finished game is retained; players who
+// leave it, or are still in it when it closes, return to the lobby.
+
+// maxLoungeChatLength is the longest lounge chat message accepted, in characters
+const maxLoungeChatLength = 200
+
+var loungeChats = metrics.NewCounterVec("snake_lounge_chat_total", "Lounge chat messages, by outcome", "outcome")
+
+// MatchStats is the summary of a finished round sent to everyone in the game
+type MatchStats struct {
+	GameID     string             `json:"game_id"`
+	Winner     string             `json:"winner"`
+	Round      int                `json:"round"` // 1 for the first round, one more per rematch
+	DurationMs int64              `json:"duration_ms"`
+	Ticks      int                `json:"ticks"`
+	Difficulty string             `json:"difficulty,omitempty"` // Difficulty of a single player game
+	Variant    string             `json:"variant,omitempty"`    // Variant of a single player game
+	Players    []PlayerMatchStats `json:"players"`
+}
+
+// PlayerMatchStats is one player's part of MatchStats
+type PlayerMatchStats struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+	Length   int    `json:"length"` // Snake length at the end of the round
+	Turns    int    `json:"turns"`  // Turns applied during the round
+	Wins     int    `json:"wins"`   // Rounds won in this game, across rematches
+}
+
+// hasLounge reports whether a game keeps its players in a lounge once a round ends
+func hasLounge(game *models.Game) bool {
+	return !game.IsSinglePlayer
+}
+
+// recordMatchStats credits the winner and summarizes the round that just ended
+// Caller must hold game.Mutex
+func (gm *Manager) recordMatchStats(game *models.Game, winner string) MatchStats {
+	players := []*models.Player{game.Player1}
+	if game.Player2 != nil {
+		players = append(players, game.Player2)
+	}
+	for _, player := range players {
+		if player.ID != winner {
+			continue
+		}
+		if game.Wins == nil {
+			game.Wins = make(map[string]int)
+		}
+		game.Wins[winner]++
+	}
+
+	stats := MatchStats{
+		GameID:     game.ID,
+		Winner:     winner,
+		Round:      game.Round,
+		Ticks:      game.Ticks,
+		Difficulty: game.Difficulty.Name,
+		Variant:    game.Variant,
+		Players:    make([]PlayerMatchStats, 0, len(players)),
+	}
+	if !game.StartedAt.IsZero() {
+		stats.DurationMs = game.FinishedAt.Sub(game.StartedAt).Milliseconds()
+	}
+	for _, player := range players {
+		playerStats := PlayerMatchStats{
+			ID:       player.ID,
+			Username: player.Username,
+			Turns:    game.TurnsTaken[player.ID],
+			Wins:     game.Wins[player.ID],
+		}
+		for _, snake := range game.State.Snakes {
+			if snake.ID == player.ID {
+				playerStats.Score = snake.Score
+				playerStats.Length = len(snake.Body)
+			}
+		}
+		stats.Players = append(stats.Players, playerStats)
+	}
+	return stats
+}
+
+// SendLoungeChat relays a chat message to everyone in a finished game's lounge
+func (gm *Manager) SendLoungeChat(player *models.Player, gameID string, text string) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Game not found",
+			"code":    "GAME_NOT_FOUND",
+		})
+		return
+	}
+
+	game.Mutex.RLock()
+	open := hasLounge(game) && game.State.Status == "finished" && game.Countdown == nil
+	isPlayer := game.Player1.ID == player.ID || (game.Player2 != nil && game.Player2.ID == player.ID)
+	_, isSpectator := game.Spectators[player.ID]
+	game.Mutex.RUnlock()
+
+	if !open {
+		loungeChats.Inc("closed")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Chat is only open after the game ends",
+			"code":    "LOUNGE_CLOSED",
+		})
+		return
+	}
+	if !isPlayer && !isSpectator {
+		loungeChats.Inc("not_a_participant")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "You are not part of this game",
+			"code":    "NOT_A_PLAYER",
+		})
+		return
+	}
+
+	text = cleanChatText(text)
+	if text == "" || utf8.RuneCountInString(text) > maxLoungeChatLength {
+		loungeChats.Inc("invalid")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Chat messages must be between 1 and 200 characters",
+			"code":    "INVALID_CHAT_MESSAGE",
+		})
+		return
+	}
+	// Chat shares the emote allowance, so neither can be used to flood the other
+	if !gm.allowEmote(player.ID) {
+		loungeChats.Inc("rate_limited")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Too many messages. Wait a moment before sending another.",
+			"code":    "CHAT_RATE_LIMITED",
+		})
+		return
+	}
+
+	loungeChats.Inc("sent")
+	gm.broadcastFrom(game, player, constants.MSG_LOUNGE_CHAT, map[string]any{
+		"game_id":   gameID,
+		"player_id": player.ID,
+		"username":  player.Username,
+		"spectator": !isPlayer,
+		"text":      text,
+		"sent_at":   gm.clock.Now().UTC(),
+	})
+}
+
+// cleanChatText trims a chat message and turns control characters, such as line breaks,
+// into spaces
+func cleanChatText(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text))
+}
+
+// closeLounge tells everyone still in an expired game's lounge that it closed and returns
+// its connected players to the lobby
+func (gm *Manager) closeLounge(gameID string, recipients []*models.Player, players []*models.Player) {
+	gm.broadcast(recipients, constants.MSG_LOUNGE_CLOSED, map[string]any{
+		"game_id": gameID,
+		"code":    "LOUNGE_EXPIRED",
+		"message": "The post-game lounge has closed",
+	})
+	for _, player := range players {
+		if !player.Connected() {
+			continue
+		}
+		if _, inLobby := gm.Lobby.Get(player.ID); !inLobby {
+			gm.AddToLobby(player)
+		}
+	}
+}
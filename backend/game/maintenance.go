@@ -0,0 +1,116 @@
+package game
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// LimitMaintenance is reported by gameCapacityExceededLocked while the instance drains for
+// maintenance
+const LimitMaintenance = "maintenance"
+
+var maintenanceRejections = metrics.NewCounterVec("snake_maintenance_rejections_total", "Requests refused while the instance drains for maintenance, by action", "action")
+
+// Maintenance is the instance draining before a restart
+type Maintenance struct {
+	Since   time.Time `json:"since"`
+	By      string    `json:"by"`
+	Message string    `json:"message,omitempty"` // Shown to players whose requests are refused
+}
+
+// MaintenanceStatus reports whether the instance drains for maintenance and what is left
+// of its games
+type MaintenanceStatus struct {
+	Enabled       bool         `json:"enabled"`
+	Maintenance   *Maintenance `json:"maintenance,omitempty"`
+	ActiveGames   int          `json:"active_games"`    // Games counting down or playing a round
+	ActiveGameIDs []string     `json:"active_game_ids"` // Their IDs, sorted
+	SafeToRestart bool         `json:"safe_to_restart"` // In maintenance with no game left to finish
+}
+
+// StartMaintenance drains the instance: new games, rematches, accepted requests and lobby
+// joins are refused with a maintenance notice, while games already playing finish as usual
+// Starting it again keeps the original start and replaces the message.
+func (gm *Manager) StartMaintenance(message, by string) MaintenanceStatus {
+	maintenance := &Maintenance{Since: gm.clock.Now().UTC(), By: by, Message: message}
+	if current := gm.maintenance.Load(); current != nil {
+		maintenance.Since = current.Since
+	}
+	gm.maintenance.Store(maintenance)
+	status := gm.MaintenanceStatus()
+	slog.Warn("Maintenance started", "by", by, "active_games", status.ActiveGames)
+	return status
+}
+
+// EndMaintenance lets the instance take new games and players again
+// Returns false if it wasn't in maintenance.
+func (gm *Manager) EndMaintenance(by string) (MaintenanceStatus, bool) {
+	if gm.maintenance.Swap(nil) == nil {
+		return gm.MaintenanceStatus(), false
+	}
+	slog.Info("Maintenance ended", "by", by)
+	return gm.MaintenanceStatus(), true
+}
+
+// MaintenanceStatus reports the maintenance in effect, if any, and the games still playing
+func (gm *Manager) MaintenanceStatus() MaintenanceStatus {
+	status := MaintenanceStatus{
+		Maintenance:   gm.maintenance.Load(),
+		ActiveGameIDs: []string{},
+	}
+	status.Enabled = status.Maintenance != nil
+	for _, game := range gm.gamesSnapshot() {
+		game.Mutex.RLock()
+		playing := game.Countdown != nil || game.IsActive()
+		game.Mutex.RUnlock()
+		if playing {
+			status.ActiveGameIDs = append(status.ActiveGameIDs, game.ID)
+		}
+	}
+	slices.Sort(status.ActiveGameIDs)
+	status.ActiveGames = len(status.ActiveGameIDs)
+	status.SafeToRestart = status.Enabled && status.ActiveGames == 0
+	return status
+}
+
+// Ready reports whether the instance takes new players, i.e. isn't in maintenance
+func (gm *Manager) Ready() bool {
+	return gm.maintenance.Load() == nil
+}
+
+// refuseMaintenance sends a player the maintenance notice for a refused action, and reports
+// whether the instance is in maintenance
+func (gm *Manager) refuseMaintenance(player *models.Player, action, gameID string) bool {
+	maintenance := gm.maintenance.Load()
+	if maintenance == nil {
+		return false
+	}
+	maintenanceRejections.Inc(action)
+	data := map[string]any{
+		"code":    "MAINTENANCE",
+		"message": "The server is about to restart for maintenance; new games can't be started right now",
+	}
+	if maintenance.Message != "" {
+		data["notice"] = maintenance.Message
+	}
+	if gameID != "" {
+		data["game_id"] = gameID
+	}
+	gm.sendMessage(player, constants.MSG_ERROR, data)
+	return true
+}
+
+// registerMaintenanceMetrics exposes whether the instance drains for maintenance
+func (gm *Manager) registerMaintenanceMetrics() {
+	metrics.NewGaugeFunc("snake_maintenance", "1 while the instance drains for maintenance", func() float64 {
+		if gm.Ready() {
+			return 0
+		}
+		return 1
+	})
+}
@@ -1,29 +1,134 @@
 package game
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"snake-backend/lobby"
-	"snake-backend/models"
-	webrtcManager "snake-backend/webrtc"
+	"github.com/bariiss/snake/backend/analytics"
+	"github.com/bariiss/snake/backend/bus"
+	"github.com/bariiss/snake/backend/clans"
+	"github.com/bariiss/snake/backend/clock"
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/lobby"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/notify"
+	"github.com/bariiss/snake/backend/profile"
+	"github.com/bariiss/snake/backend/replay"
+	"github.com/bariiss/snake/backend/rng"
+	"github.com/bariiss/snake/backend/storage"
+	"github.com/bariiss/snake/backend/tournament"
+	webrtcManager "github.com/bariiss/snake/backend/webrtc"
 )
 
 type Manager struct {
 	Lobby               *lobby.Service
-	Games               map[string]*models.Game
-	PendingRequests     map[string]map[string]*models.Game
-	MatchQueue          []*models.Player
-	Players             map[string]*models.Player // Global player registry
-	Mutex               sync.RWMutex
+	Games               map[string]*models.Game            // Guarded by Mutex
+	PendingRequests     map[string]map[string]*models.Game // Guarded by pendingMu
+	MatchQueue          []*models.Player                   // Guarded by pendingMu
+	Players             map[string]*models.Player          // Global player registry, guarded by playersMu
+	Mutex               sync.RWMutex                       // Guards Games and playerGames; see registry.go for the lock order
 	WebRTCManager       *webrtcManager.Manager
 	MultiplayerManager  *MultiplayerGameManager
 	SinglePlayerManager *SinglePlayerGameManager
+
+	playerGames map[string]map[string]struct{} // Player ID -> games they play in or spectate, guarded by Mutex
+
+	pendingMu sync.Mutex
+	playersMu sync.RWMutex
+
+	emoteMu      sync.Mutex
+	emoteBuckets map[string]*emoteBucket // Player ID -> emote allowance, guarded by emoteMu
+
+	partyMu       sync.Mutex
+	parties       map[string]*party // Party ID -> party, guarded by partyMu
+	playerParties map[string]*party // Member ID -> their party, guarded by partyMu
+
+	tournamentsMu      sync.Mutex
+	tournaments        map[string]*tournament.Tournament // Tournament ID -> tournament, guarded by tournamentsMu
+	tournamentGames    map[string]tournamentMatch        // Game ID -> match it is played for, guarded by tournamentsMu
+	tournamentSweeping bool                              // The match sweep is running, guarded by tournamentsMu
+	tournamentStore    storage.Store                     // Persists tournament snapshots, nil when they aren't kept
+	tournamentHubs     map[string]*tournamentHub         // Tournament ID -> players watching its games, guarded by tournamentsMu
+	tournamentWatching bool                              // The live scores loop is running, guarded by tournamentsMu
+	tournamentCasts    map[string]map[string]bool        // Caster ID -> IDs of the games they cast, guarded by tournamentsMu
+	tournamentResults  *tournament.Archive               // Results of every finished tournament, nil until SetTournaments
+
+	kicksMu sync.Mutex
+	kicks   map[string]Kick // Player ID -> their latest kick, guarded by kicksMu
+
+	scheduler *tickScheduler // Advances all active games
+	clock     clock.Clock    // Drives countdowns, ticks and timestamps
+	seeds     func() int64   // Seeds each new game's random source
+
+	ctx  context.Context // Parent of every game's context, canceled on shutdown
+	stop context.CancelFunc
+
+	cfg          atomic.Pointer[config.Config]
+	connections  atomic.Int64
+	openGames    openGames                // Unfinished games and their players, for the capacity limits
+	bus          bus.Bus                  // nil when running as a single instance
+	cluster      *cluster                 // Other instances, nil without a bus
+	analytics    *analytics.Emitter       // nil when analytics are disabled
+	reports      *moderation.Reports      // Moderation queue, nil when reporting is unavailable
+	restrictions *moderation.Restrictions // Players under a shadow restriction, nil when unavailable
+	bans         *moderation.Bans         // Players kept off the server, nil when unavailable
+	replays      *replay.Archive          // Recorded multiplayer rounds, nil when they aren't kept
+	links        *moderation.Links        // Accounts correlated by their connections, nil when unavailable
+	profiles     *profile.Profiles        // Player settings such as mutes, nil when unavailable
+	clans        *clans.Clans             // Clans and their scoreboard, nil when unavailable
+	notifier     *notify.Notifier         // Posts notable events to chat webhooks, nil when none are set
+
+	announcement atomic.Pointer[Announcement] // Banner shown to every player, nil when none
+	maintenance  atomic.Pointer[Maintenance]  // Set while the instance drains for maintenance
 }
 
 func (gm *Manager) SetWebRTCManager(webrtcMgr *webrtcManager.Manager) {
 	gm.WebRTCManager = webrtcMgr
 }
 
+// SetAnalytics sets the emitter gameplay analytics events are sent to
+// Call before the server starts accepting connections
+func (gm *Manager) SetAnalytics(emitter *analytics.Emitter) {
+	gm.analytics = emitter
+}
+
+// SetClock replaces the clock driving countdowns, ticks and timestamps, e.g. with a fake in tests
+// Call before any game is created
+func (gm *Manager) SetClock(c clock.Clock) {
+	gm.clock = c
+	gm.scheduler.clock = c
+}
+
+// Clock returns the clock the manager runs on
+func (gm *Manager) Clock() clock.Clock {
+	return gm.clock
+}
+
+// SetSeeds replaces the function seeding new games, e.g. with a constant for reproducible tests
+// Call before any game is created
+func (gm *Manager) SetSeeds(seeds func() int64) {
+	gm.seeds = seeds
+}
+
+// seedGame gives a new game its own random source
+func (gm *Manager) seedGame(game *models.Game) {
+	game.Seed = gm.seeds()
+	game.Rand = rng.New(game.Seed)
+}
+
+// SetConfig replaces the configuration used for new games, connections and limit checks
+func (gm *Manager) SetConfig(cfg *config.Config) {
+	gm.cfg.Store(cfg)
+}
+
+// Config returns the configuration currently in effect
+func (gm *Manager) Config() *config.Config {
+	return gm.cfg.Load()
+}
+
 func NewGameManager() *Manager {
 	manager := &Manager{
 		Lobby:           lobby.NewService(),
@@ -31,11 +136,68 @@ func NewGameManager() *Manager {
 		PendingRequests: make(map[string]map[string]*models.Game),
 		MatchQueue:      make([]*models.Player, 0),
 		Players:         make(map[string]*models.Player),
+		playerGames:     make(map[string]map[string]struct{}),
+		emoteBuckets:    make(map[string]*emoteBucket),
+		parties:         make(map[string]*party),
+		playerParties:   make(map[string]*party),
+		tournaments:     make(map[string]*tournament.Tournament),
+		tournamentGames: make(map[string]tournamentMatch),
+		tournamentHubs:  make(map[string]*tournamentHub),
+		tournamentCasts: make(map[string]map[string]bool),
+		kicks:           make(map[string]Kick),
 	}
 
+	manager.ctx, manager.stop = context.WithCancel(context.Background())
+	manager.cfg.Store(config.Default())
+	manager.clock = clock.Real()
+	manager.seeds = rng.Seed
+	manager.scheduler = newTickScheduler(manager.clock, manager.safeTick, manager.tickOverrun)
+
 	// Initialize game mode managers
 	manager.MultiplayerManager = NewMultiplayerGameManager(manager)
 	manager.SinglePlayerManager = NewSinglePlayerGameManager(manager)
 
+	manager.registerCapacityMetrics()
+	manager.registerMaintenanceMetrics()
+
 	return manager
 }
+
+// Context is canceled once the manager shuts down
+func (gm *Manager) Context() context.Context {
+	return gm.ctx
+}
+
+// Stop cancels the manager's context, ending every game, countdown and round along with the
+// event streams and long polls waiting on it
+// The HTTP server waits for those handlers before it returns from its own shutdown, so Stop
+// is registered to run as that begins; Shutdown calls it too.
+func (gm *Manager) Stop() {
+	gm.stop()
+}
+
+// Shutdown cancels every game, countdown and round, closes all connections and waits until
+// their pumps have finished or ctx is done
+// Returns false if connections were still open when ctx was done
+func (gm *Manager) Shutdown(ctx context.Context) bool {
+	gm.Stop()
+
+	gm.playersMu.RLock()
+	for _, player := range gm.Players {
+		if conn := player.Connection(); conn != nil {
+			conn.Close(models.CloseShutdown)
+		}
+	}
+	gm.playersMu.RUnlock()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for gm.ConnectionCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
@@ -3,91 +3,124 @@ package game
 import (
 	"fmt"
 
-	"snake-backend/constants"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 
 	"github.com/google/uuid"
 )
 
-func (gm *Manager) SendGameRequest(from *models.Player, toID string) {
-	target, exists := gm.Lobby.Get(toID)
-	if !exists {
-		gm.sendMessage(from, constants.MSG_ERROR, map[string]any{
-			"message": "Player not found in lobby",
-		})
-		return
-	}
-
+// newMultiplayerGame creates a game between two players, waiting for both to be ready
+func (gm *Manager) newMultiplayerGame(player1, player2 *models.Player, rules models.RulesProfile) *models.Game {
 	gameID := uuid.New().String()
 	game := &models.Game{
 		ID:         gameID,
-		Player1:    from,
-		Player2:    target,
-		IsActive:   false,
+		Player1:    player1,
+		Player2:    player2,
 		Spectators: make(map[string]*models.Player),
+		Rules:      rules,
+		InstanceID: gm.Config().InstanceID,
+		Region:     gm.Config().Region,
 	}
 	game.State = &models.GameState{
 		ID:             gameID,
 		Status:         "waiting",
 		IsSinglePlayer: false,
 		Players: []models.PlayerStatus{
-			{ID: from.ID, Username: from.Username, Ready: false},
-			{ID: target.ID, Username: target.Username, Ready: false},
+			{ID: player1.ID, Username: player1.Username, Ready: false},
+			{ID: player2.ID, Username: player2.Username, Ready: false},
 		},
 	}
+	return game
+}
+
+// SendGameRequest challenges a lobby player to a game with the named rules preset; a
+// non-empty tieBreak replaces the preset's tie-break for that game
+func (gm *Manager) SendGameRequest(from *models.Player, toID string, rulesName, tieBreak string) {
+	target, exists := gm.Lobby.Get(toID)
+	if !exists {
+		// The target may be waiting in another instance's lobby
+		target, exists = gm.remoteLobbyPlayer(toID)
+	}
+	// Restricted players are hidden from others, and don't learn that they are themselves
+	if !exists || !gm.rankedPairing(from, target) {
+		gm.sendMessage(from, constants.MSG_ERROR, map[string]any{
+			"code":    "PLAYER_NOT_FOUND",
+			"message": "Player not found in lobby",
+		})
+		return
+	}
+
+	if gm.refuseBarred(from, "") {
+		return
+	}
+	rules, valid := ResolveRules(rulesName)
+	if !valid {
+		gm.sendInvalidRules(from, rulesName)
+		return
+	}
+	rules, valid = withTieBreak(rules, tieBreak)
+	if !valid {
+		gm.sendInvalidTieBreak(from, tieBreak)
+		return
+	}
+
+	game := gm.newMultiplayerGame(from, target, rules)
+	gameID := game.ID
 
 	gm.Mutex.Lock()
+	if limit := gm.gameCapacityExceededLocked(from.ID, target.ID); limit != "" {
+		gm.Mutex.Unlock()
+		gm.sendServerFull(from, limit)
+		return
+	}
+	gm.pendingMu.Lock()
 	if gm.PendingRequests[toID] == nil {
 		gm.PendingRequests[toID] = make(map[string]*models.Game)
 	}
-	if _, exists := gm.PendingRequests[toID][from.ID]; exists {
-		gm.Mutex.Unlock()
+	_, duplicate := gm.PendingRequests[toID][from.ID]
+	if !duplicate {
+		gm.PendingRequests[toID][from.ID] = game
+		gm.addGameLocked(game)
+	}
+	gm.pendingMu.Unlock()
+	gm.Mutex.Unlock()
+
+	if duplicate {
 		gm.sendMessage(from, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"code":    "DUPLICATE_REQUEST",
 			"message": "You already sent a request to this player",
 		})
 		return
 	}
-
-	gm.Games[gameID] = game
-	gm.PendingRequests[toID][from.ID] = game
-	gm.Mutex.Unlock()
+	gm.emitGameCreated(game)
 
 	gm.sendMessage(target, constants.MSG_MATCH_FOUND, map[string]any{
 		"game_id":     gameID,
 		"from_player": from,
+		"rules":       rules,
 	})
 
 	gm.sendMessage(from, constants.MSG_GAME_REQUEST_SENT, map[string]any{
 		"game_id":   gameID,
 		"to_player": target,
 		"status":    "pending",
+		"rules":     rules,
 	})
 }
 
 func (gm *Manager) CancelGameRequest(from *models.Player, toID string) {
-	gm.Mutex.Lock()
-	defer gm.Mutex.Unlock()
-
-	targetRequests, exists := gm.PendingRequests[toID]
-	if !exists {
-		return
-	}
-
-	game, hasRequest := targetRequests[from.ID]
+	game, hasRequest := gm.takePendingRequest(toID, from.ID)
 	if !hasRequest {
 		return
 	}
+	gm.deleteGame(game.ID)
 
-	delete(targetRequests, from.ID)
-	if len(targetRequests) == 0 {
-		delete(gm.PendingRequests, toID)
-	}
-
-	delete(gm.Games, game.ID)
-
-	if target, ok := gm.Lobby.Get(toID); ok {
+	if target := game.Player2; target != nil {
 		gm.sendMessage(target, constants.MSG_GAME_REQUEST_CANCEL, map[string]any{
 			"from_player": from,
+			"player":      from.Username,
+			"code":        "GAME_REQUEST_CANCELLED",
 			"message":     fmt.Sprintf("%s cancelled the game request", from.Username),
 		})
 	}
@@ -99,12 +132,12 @@ func (gm *Manager) CancelGameRequest(from *models.Player, toID string) {
 }
 
 func (gm *Manager) AcceptGameRequest(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"code":    "GAME_NOT_FOUND",
 			"message": "Game not found",
 		})
 		return
@@ -112,31 +145,22 @@ func (gm *Manager) AcceptGameRequest(player *models.Player, gameID string) {
 
 	if game.Player2.ID != player.ID {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"code":    "NOT_REQUEST_TARGET",
 			"message": "You are not the target player",
 		})
 		return
 	}
 
-	gm.Mutex.Lock()
-	targetRequests, exists := gm.PendingRequests[player.ID]
-	if exists {
-		delete(targetRequests, game.Player1.ID)
-		if len(targetRequests) == 0 {
-			delete(gm.PendingRequests, player.ID)
-		}
-	}
-	for targetID, requests := range gm.PendingRequests {
-		if targetID == player.ID {
-			continue
-		}
-		delete(requests, player.ID)
-		if len(requests) == 0 {
-			delete(gm.PendingRequests, targetID)
-		}
+	if gm.refuseMaintenance(player, "accept", gameID) || gm.refuseBarred(player, gameID) {
+		return
 	}
-	gm.Mutex.Unlock()
+	gm.takePendingRequest(player.ID, game.Player1.ID)
+	gm.dropPendingRequestsFrom(player.ID)
 
-	gameState := game.State
+	game.Mutex.RLock()
+	gameState := game.State.Clone()
+	game.Mutex.RUnlock()
 
 	gm.sendMessage(game.Player1, constants.MSG_GAME_ACCEPT, map[string]any{
 		"game_id": gameID,
@@ -151,9 +175,7 @@ func (gm *Manager) AcceptGameRequest(player *models.Player, gameID string) {
 }
 
 func (gm *Manager) RejectGameRequest(player *models.Player, gameID string) {
-	gm.Mutex.Lock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.Unlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		return
@@ -163,16 +185,8 @@ func (gm *Manager) RejectGameRequest(player *models.Player, gameID string) {
 		return
 	}
 
-	gm.Mutex.Lock()
-	targetRequests, exists := gm.PendingRequests[player.ID]
-	if exists {
-		delete(targetRequests, game.Player1.ID)
-		if len(targetRequests) == 0 {
-			delete(gm.PendingRequests, player.ID)
-		}
-	}
-	delete(gm.Games, gameID)
-	gm.Mutex.Unlock()
+	gm.takePendingRequest(player.ID, game.Player1.ID)
+	gm.deleteGame(gameID)
 
 	gm.sendMessage(game.Player1, constants.MSG_GAME_REJECT, map[string]any{
 		"game_id":     gameID,
@@ -1,10 +1,409 @@
 package game
 
 import (
-	"snake-backend/constants"
-	"snake-backend/models"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/tournament"
 )
 
+// Message payloads; string fields tagged required must be present and non-empty
+type (
+	emptyPayload struct{}
+
+	gamePayload struct {
+		GameID string `json:"game_id" required:"true"`
+	}
+
+	targetPayload struct {
+		TargetID string `json:"target_id" required:"true"`
+	}
+
+	gameRequestPayload struct {
+		TargetID string `json:"target_id" required:"true"`
+		Rules    string `json:"rules"`
+		TieBreak string `json:"tie_break"` // Replaces the preset's tie-break; empty keeps it
+	}
+
+	movePayload struct {
+		GameID    string `json:"game_id" required:"true"`
+		Direction string `json:"direction" required:"true"`
+		SnakeSlot int    `json:"snake_slot"` // 2 steers the guest of a hot-seat or co-op game; 0 or 1 the sender's own snake
+		Tick      *int   `json:"tick"`       // Tick of the latest game update the client had; the move is meant for the next one
+	}
+
+	emotePayload struct {
+		GameID  string `json:"game_id" required:"true"`
+		EmoteID string `json:"emote_id" required:"true"`
+	}
+
+	chatPayload struct {
+		GameID string `json:"game_id" required:"true"`
+		Text   string `json:"text" required:"true"`
+	}
+
+	invitePayload struct {
+		GameID   string `json:"game_id" required:"true"`
+		Username string `json:"username" required:"true"`
+	}
+
+	reportPayload struct {
+		Username string `json:"username" required:"true"`
+		Category string `json:"category" required:"true"`
+		Comment  string `json:"comment"`
+		GameID   string `json:"game_id"` // The game the report is about, if any
+	}
+
+	usernamePayload struct {
+		Username string `json:"username" required:"true"`
+	}
+
+	partyPayload struct {
+		PartyID string `json:"party_id" required:"true"`
+	}
+
+	hotSeatPayload struct {
+		Rules     string `json:"rules"`
+		GuestName string `json:"guest_name"`
+	}
+
+	practicePayload struct {
+		Bot string `json:"bot"` // Bundled bot to practice against; empty for greedy
+	}
+
+	rulesPayload struct {
+		Rules string `json:"rules"`
+	}
+
+	pauseGamePayload struct {
+		GameID string `json:"game_id" required:"true"`
+		Paused bool   `json:"paused"` // false resumes the game
+	}
+
+	campaignLevelPayload struct {
+		Level int `json:"level"` // From 1
+	}
+
+	singlePlayerPayload struct {
+		Rules      string `json:"rules"`
+		Difficulty string `json:"difficulty"` // easy, normal or hard; empty for normal
+		Variant    string `json:"variant"`    // endless, score_attack or daily; empty for endless. A daily run ignores rules and difficulty
+		Coop       bool   `json:"coop"`       // Adds a guest snake steered with snake_slot 2 as a teammate; ignores difficulty and variant
+		GuestName  string `json:"guest_name"` // Names the co-op guest
+	}
+
+	leaderboardPayload struct {
+		Variant    string `json:"variant"`    // Empty for endless
+		Difficulty string `json:"difficulty"` // Empty for normal
+	}
+
+	circuitPayload struct {
+		Season string `json:"season"` // A month such as 2026-01; empty for the current season
+	}
+
+	clanPayload struct {
+		Tag string `json:"tag" required:"true"`
+	}
+
+	clanCreatePayload struct {
+		Tag  string `json:"tag" required:"true"`
+		Name string `json:"name" required:"true"`
+	}
+
+	clanRolePayload struct {
+		TargetID string `json:"target_id" required:"true"`
+		Role     string `json:"role" required:"true"` // leader, officer or member
+	}
+
+	profilePayload struct {
+		Title string `json:"title"` // Earned title ID to equip; empty takes the title off
+	}
+
+	tournamentPayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+	}
+
+	tournamentStaffPayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+		Username     string `json:"username" required:"true"`
+		Remove       bool   `json:"remove"` // Dismisses the player instead
+	}
+
+	castPayload struct {
+		GameIDs []string `json:"game_ids"` // Match games; uncasting none stops every game cast
+	}
+
+	tournamentPausePayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+		Paused       bool   `json:"paused"` // false resumes the tournament
+	}
+
+	tournamentMatchPayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+		MatchID      string `json:"match_id" required:"true"`
+	}
+
+	tournamentDisqualifyPayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+		PlayerID     string `json:"player_id" required:"true"`
+	}
+
+	tournamentExtendPayload struct {
+		TournamentID string `json:"tournament_id" required:"true"`
+		Minutes      int    `json:"minutes"` // 1 to 60
+	}
+
+	tournamentCreatePayload struct {
+		Name         string    `json:"name" required:"true"`
+		Format       string    `json:"format"` // elimination (default) or league
+		Rules        string    `json:"rules"`
+		MaxPlayers   int       `json:"max_players"`    // 0 uses DefaultTournamentPlayers
+		StartsAt     time.Time `json:"starts_at"`      // Scheduled start; zero for the organizer to start it
+		Repeat       string    `json:"repeat"`         // daily or weekly for a recurring scheduled tournament
+		WindowHours  int       `json:"window_hours"`   // How long a league runs once started
+		RoundCheckIn int       `json:"round_check_in"` // Minutes to check in to each match, 1 to 15; 0 for none
+	}
+)
+
+// Moves and state requests are accepted in any state: they often race the end of a game,
+// and their handlers already answer for games the player is not part of
+func init() {
+	handle(constants.MSG_JOIN_LOBBY, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		if gm.refuseMaintenance(player, "lobby", "") {
+			return
+		}
+		gm.AddToLobby(player)
+	})
+	handle(constants.MSG_LEAVE_LOBBY, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.RemoveFromLobby(player.ID)
+	})
+	handle(constants.MSG_GAME_REQUEST, authConnected, stateAny, func(gm *Manager, player *models.Player, p gameRequestPayload) {
+		gm.SendGameRequest(player, p.TargetID, p.Rules, p.TieBreak)
+	})
+	handle(constants.MSG_GAME_REQUEST_CANCEL, authConnected, stateAny, func(gm *Manager, player *models.Player, p targetPayload) {
+		gm.CancelGameRequest(player, p.TargetID)
+	})
+	handle(constants.MSG_GAME_ACCEPT, authConnected, stateAny, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.AcceptGameRequest(player, p.GameID)
+	})
+	handle(constants.MSG_GAME_REJECT, authConnected, stateAny, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.RejectGameRequest(player, p.GameID)
+	})
+	handle(constants.MSG_PLAYER_READY, authConnected, stateInGame, func(gm *Manager, player *models.Player, p gamePayload) {
+		if gm.isSinglePlayer(p.GameID) {
+			gm.SinglePlayerManager.HandlePlayerReady(player, p.GameID)
+			return
+		}
+		gm.MultiplayerManager.HandlePlayerReady(player, p.GameID)
+	})
+	handle(constants.MSG_PLAYER_MOVE, authConnected, stateAny, func(gm *Manager, player *models.Player, p movePayload) {
+		if p.SnakeSlot != 0 && p.SnakeSlot != slotOwn {
+			gm.HandleSlotMove(player, p.GameID, p.SnakeSlot, p.Direction, p.Tick)
+			return
+		}
+		if gm.isSinglePlayer(p.GameID) {
+			gm.SinglePlayerManager.HandlePlayerMove(player, p.GameID, p.Direction, p.Tick)
+			return
+		}
+		gm.MultiplayerManager.HandlePlayerMove(player, p.GameID, p.Direction, p.Tick)
+	})
+	handle(constants.MSG_EMOTE, authConnected, stateInGame, func(gm *Manager, player *models.Player, p emotePayload) {
+		gm.SendEmote(player, p.GameID, p.EmoteID)
+	})
+	handle(constants.MSG_LOUNGE_CHAT, authConnected, stateInGame|stateSpectating, func(gm *Manager, player *models.Player, p chatPayload) {
+		gm.SendLoungeChat(player, p.GameID, p.Text)
+	})
+	handle(constants.MSG_LIST_GAMES, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendGamesList(player)
+	})
+	handle(constants.MSG_JOIN_SPECTATOR, authConnected, stateAny, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.AddSpectator(player, p.GameID)
+	})
+	handle(constants.MSG_REPORT_PLAYER, authConnected, stateAny, func(gm *Manager, player *models.Player, p reportPayload) {
+		gm.ReportPlayer(player, p.Username, p.Category, p.Comment, p.GameID)
+	})
+	handle(constants.MSG_GET_RECENT_OPPONENTS, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendRecentOpponents(player)
+	})
+	handle(constants.MSG_UPDATE_PROFILE, authConnected, stateAny, func(gm *Manager, player *models.Player, p profilePayload) {
+		gm.UpdateProfile(player, p.Title)
+	})
+	handle(constants.MSG_MUTE_PLAYER, authConnected, stateAny, func(gm *Manager, player *models.Player, p usernamePayload) {
+		gm.MutePlayer(player, p.Username)
+	})
+	handle(constants.MSG_UNMUTE_PLAYER, authConnected, stateAny, func(gm *Manager, player *models.Player, p usernamePayload) {
+		gm.UnmutePlayer(player, p.Username)
+	})
+	handle(constants.MSG_PARTY_INVITE, authConnected, stateAny, func(gm *Manager, player *models.Player, p usernamePayload) {
+		gm.InviteToParty(player, p.Username)
+	})
+	handle(constants.MSG_PARTY_ACCEPT, authConnected, stateAny, func(gm *Manager, player *models.Player, p partyPayload) {
+		gm.AcceptPartyInvite(player, p.PartyID)
+	})
+	handle(constants.MSG_PARTY_DECLINE, authConnected, stateAny, func(gm *Manager, player *models.Player, p partyPayload) {
+		gm.DeclinePartyInvite(player, p.PartyID)
+	})
+	handle(constants.MSG_PARTY_LEAVE, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.LeaveParty(player)
+	})
+	handle(constants.MSG_PARTY_QUEUE, authConnected, stateAny, func(gm *Manager, player *models.Player, p rulesPayload) {
+		gm.QueueParty(player, p.Rules)
+	})
+	handle(constants.MSG_CLAN_CREATE, authConnected, stateAny, func(gm *Manager, player *models.Player, p clanCreatePayload) {
+		gm.CreateClan(player, p.Tag, p.Name)
+	})
+	handle(constants.MSG_CLAN_INVITE, authConnected, stateAny, func(gm *Manager, player *models.Player, p usernamePayload) {
+		gm.InviteToClan(player, p.Username)
+	})
+	handle(constants.MSG_CLAN_ACCEPT, authConnected, stateAny, func(gm *Manager, player *models.Player, p clanPayload) {
+		gm.AcceptClanInvite(player, p.Tag)
+	})
+	handle(constants.MSG_CLAN_DECLINE, authConnected, stateAny, func(gm *Manager, player *models.Player, p clanPayload) {
+		gm.DeclineClanInvite(player, p.Tag)
+	})
+	handle(constants.MSG_CLAN_LEAVE, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.LeaveClan(player)
+	})
+	handle(constants.MSG_CLAN_REMOVE, authConnected, stateAny, func(gm *Manager, player *models.Player, p targetPayload) {
+		gm.RemoveFromClan(player, p.TargetID)
+	})
+	handle(constants.MSG_CLAN_SET_ROLE, authConnected, stateAny, func(gm *Manager, player *models.Player, p clanRolePayload) {
+		gm.SetClanRole(player, p.TargetID, p.Role)
+	})
+	handle(constants.MSG_GET_CLAN_SCOREBOARD, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendClanScoreboard(player)
+	})
+	handle(constants.MSG_TOURNAMENT_CREATE, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentCreatePayload) {
+		gm.CreateTournament(player, TournamentOptions{
+			Name:         p.Name,
+			Format:       p.Format,
+			Rules:        p.Rules,
+			MaxPlayers:   p.MaxPlayers,
+			StartsAt:     p.StartsAt,
+			Repeat:       p.Repeat,
+			WindowHours:  p.WindowHours,
+			RoundCheckIn: p.RoundCheckIn,
+		})
+	})
+	handle(constants.MSG_CHECK_IN, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.CheckInTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_TOURNAMENT_REGISTER, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.RegisterForTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_TOURNAMENT_UNREGISTER, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.UnregisterFromTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_TOURNAMENT_START, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.StartTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_TOURNAMENT_MODERATOR, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentStaffPayload) {
+		gm.SetTournamentStaff(player, p.TournamentID, tournament.RoleModerator, p.Username, p.Remove)
+	})
+	handle(constants.MSG_TOURNAMENT_CASTER, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentStaffPayload) {
+		gm.SetTournamentStaff(player, p.TournamentID, tournament.RoleCaster, p.Username, p.Remove)
+	})
+	handle(constants.MSG_CAST_GAMES, authConnected, stateAny, func(gm *Manager, player *models.Player, p castPayload) {
+		gm.CastGames(player, p.GameIDs)
+	})
+	handle(constants.MSG_UNCAST_GAMES, authConnected, stateAny, func(gm *Manager, player *models.Player, p castPayload) {
+		gm.UncastGames(player, p.GameIDs)
+	})
+	handle(constants.MSG_TOURNAMENT_PAUSE, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPausePayload) {
+		gm.PauseTournament(player, p.TournamentID, p.Paused)
+	})
+	handle(constants.MSG_TOURNAMENT_RESTART_MATCH, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentMatchPayload) {
+		gm.RestartTournamentMatch(player, p.TournamentID, p.MatchID)
+	})
+	handle(constants.MSG_TOURNAMENT_VERIFY_MATCH, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentMatchPayload) {
+		gm.VerifyTournamentMatch(player, p.TournamentID, p.MatchID)
+	})
+	handle(constants.MSG_TOURNAMENT_DISQUALIFY, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentDisqualifyPayload) {
+		gm.DisqualifyFromTournament(player, p.TournamentID, p.PlayerID)
+	})
+	handle(constants.MSG_TOURNAMENT_EXTEND_CHECK_IN, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentExtendPayload) {
+		gm.ExtendTournamentCheckIn(player, p.TournamentID, p.Minutes)
+	})
+	handle(constants.MSG_LIST_TOURNAMENTS, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendTournaments(player)
+	})
+	handle(constants.MSG_GET_LEAGUE_STANDINGS, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.SendLeagueStandings(player, p.TournamentID)
+	})
+	handle(constants.MSG_GET_TOURNAMENT, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.SendTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_GET_CIRCUIT, authConnected, stateAny, func(gm *Manager, player *models.Player, p circuitPayload) {
+		gm.SendCircuit(player, p.Season)
+	})
+	handle(constants.MSG_WATCH_TOURNAMENT, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.WatchTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_UNWATCH_TOURNAMENT, authConnected, stateAny, func(gm *Manager, player *models.Player, p tournamentPayload) {
+		gm.UnwatchTournament(player, p.TournamentID)
+	})
+	handle(constants.MSG_INVITE_SPECTATOR, authConnected, stateInGame, func(gm *Manager, player *models.Player, p invitePayload) {
+		gm.InviteSpectator(player, p.GameID, p.Username)
+	})
+	// Rematch is only for multiplayer games
+	handle(constants.MSG_REMATCH_REQUEST, authConnected, stateInGame, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.MultiplayerManager.HandleRematchRequest(player, p.GameID)
+	})
+	handle(constants.MSG_REMATCH_ACCEPT, authConnected, stateInGame, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.MultiplayerManager.HandleRematchAccept(player, p.GameID)
+	})
+	handle(constants.MSG_START_SINGLE_PLAYER, authConnected, stateAny, func(gm *Manager, player *models.Player, p singlePlayerPayload) {
+		if p.Coop {
+			gm.StartCoopGame(player, p.Rules, p.GuestName)
+			return
+		}
+		gm.StartSinglePlayerGame(player, p.Rules, p.Difficulty, p.Variant)
+	})
+	handle(constants.MSG_GET_LEADERBOARD, authConnected, stateAny, func(gm *Manager, player *models.Player, p leaderboardPayload) {
+		gm.SendLeaderboard(player, p.Variant, p.Difficulty)
+	})
+	handle(constants.MSG_GET_CAMPAIGN, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendCampaign(player)
+	})
+	handle(constants.MSG_START_CAMPAIGN_LEVEL, authConnected, stateAny, func(gm *Manager, player *models.Player, p campaignLevelPayload) {
+		gm.StartCampaignLevel(player, p.Level)
+	})
+	handle(constants.MSG_PAUSE_GAME, authConnected, stateInGame, func(gm *Manager, player *models.Player, p pauseGamePayload) {
+		gm.PauseSinglePlayerGame(player, p.GameID, p.Paused)
+	})
+	handle(constants.MSG_RESTART_GAME, authConnected, stateInGame, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.RestartSinglePlayerGame(player, p.GameID)
+	})
+	handle(constants.MSG_SUSPEND_GAME, authConnected, stateInGame, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.SuspendSinglePlayerGame(player, p.GameID)
+	})
+	handle(constants.MSG_RESUME_GAME, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.ResumeSinglePlayerGame(player)
+	})
+	handle(constants.MSG_GET_SAVED_GAME, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendSavedGame(player)
+	})
+	handle(constants.MSG_GET_SINGLE_PLAYER_STATS, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendSinglePlayerStats(player)
+	})
+	handle(constants.MSG_START_HOT_SEAT, authConnected, stateAny, func(gm *Manager, player *models.Player, p hotSeatPayload) {
+		gm.StartHotSeatGame(player, p.Rules, p.GuestName)
+	})
+	handle(constants.MSG_START_PRACTICE, authConnected, stateAny, func(gm *Manager, player *models.Player, p practicePayload) {
+		gm.StartPracticeGame(player, p.Bot)
+	})
+	handle(constants.MSG_LIST_RULES, authConnected, stateAny, func(gm *Manager, player *models.Player, _ emptyPayload) {
+		gm.SendRulesList(player)
+	})
+	handle(constants.MSG_GET_GAME_STATE, authConnected, stateAny, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.SendGameState(player, p.GameID)
+	})
+	handle(constants.MSG_LEAVE_GAME, authConnected, stateInGame|stateSpectating, func(gm *Manager, player *models.Player, p gamePayload) {
+		gm.LeaveGame(player, p.GameID)
+	})
+}
+
 // HandleWebRTCMessage handles messages from WebRTC DataChannel
 func (gm *Manager) HandleWebRTCMessage(player *models.Player, msgType string, msg map[string]any) {
 	// Reuse the same message handler
@@ -19,86 +418,17 @@ func (gm *Manager) HandleWebSocketMessage(player *models.Player, msgType string,
 
 // handleMessage processes incoming messages from players
 func (gm *Manager) handleMessage(player *models.Player, msgType string, msg map[string]any) {
-	switch msgType {
-	case constants.MSG_JOIN_LOBBY:
-		gm.AddToLobby(player)
-	case constants.MSG_LEAVE_LOBBY:
-		gm.RemoveFromLobby(player.ID)
-	case constants.MSG_GAME_REQUEST:
-		if targetID, ok := msg["target_id"].(string); ok {
-			gm.SendGameRequest(player, targetID)
-		}
-	case constants.MSG_GAME_REQUEST_CANCEL:
-		if targetID, ok := msg["target_id"].(string); ok {
-			gm.CancelGameRequest(player, targetID)
-		}
-	case constants.MSG_GAME_ACCEPT:
-		if gameID, ok := msg["game_id"].(string); ok {
-			gm.AcceptGameRequest(player, gameID)
-		}
-	case constants.MSG_GAME_REJECT:
-		if gameID, ok := msg["game_id"].(string); ok {
-			gm.RejectGameRequest(player, gameID)
-		}
-	case constants.MSG_PLAYER_READY:
-		gameID, ok := msg["game_id"].(string)
-		if !ok {
-			break
-		}
-		// Check if single player or multiplayer
-		gm.Mutex.RLock()
-		game, exists := gm.Games[gameID]
-		gm.Mutex.RUnlock()
-
-		if exists && game.IsSinglePlayer {
-			gm.SinglePlayerManager.HandlePlayerReady(player, gameID)
-		} else {
-			gm.MultiplayerManager.HandlePlayerReady(player, gameID)
-		}
-	case constants.MSG_PLAYER_MOVE:
-		gameID, ok := msg["game_id"].(string)
-		if !ok {
-			break
-		}
-		direction, ok := msg["direction"].(string)
-		if !ok {
-			break
-		}
-		// Check if single player or multiplayer
-		gm.Mutex.RLock()
-		game, exists := gm.Games[gameID]
-		gm.Mutex.RUnlock()
-
-		if exists && game.IsSinglePlayer {
-			gm.SinglePlayerManager.HandlePlayerMove(player, gameID, direction)
-		} else {
-			gm.MultiplayerManager.HandlePlayerMove(player, gameID, direction)
-		}
-	case constants.MSG_LIST_GAMES:
-		gm.SendGamesList(player)
-	case constants.MSG_JOIN_SPECTATOR:
-		if gameID, ok := msg["game_id"].(string); ok {
-			gm.AddSpectator(player, gameID)
-		}
-	case constants.MSG_REMATCH_REQUEST:
-		if gameID, ok := msg["game_id"].(string); ok {
-			// Rematch is only for multiplayer games
-			gm.MultiplayerManager.HandleRematchRequest(player, gameID)
-		}
-	case constants.MSG_REMATCH_ACCEPT:
-		if gameID, ok := msg["game_id"].(string); ok {
-			// Rematch is only for multiplayer games
-			gm.MultiplayerManager.HandleRematchAccept(player, gameID)
-		}
-	case constants.MSG_START_SINGLE_PLAYER:
-		gm.StartSinglePlayerGame(player)
-	case constants.MSG_GET_GAME_STATE:
-		if gameID, ok := msg["game_id"].(string); ok {
-			gm.SendGameState(player, gameID)
-		}
-	case constants.MSG_LEAVE_GAME:
-		if gameID, ok := msg["game_id"].(string); ok {
-			gm.LeaveGame(player, gameID)
-		}
+	defer gm.recoverMessage(player, msgType, msg)
+
+	// Messages about games hosted by another instance are handled there
+	if gm.forwardToHost(player, msgType, msg) {
+		return
 	}
+	gm.dispatch(player, msgType, msg)
+}
+
+// isSinglePlayer reports whether the game exists and is a single player game
+func (gm *Manager) isSinglePlayer(gameID string) bool {
+	game, exists := gm.lookupGame(gameID)
+	return exists && game.IsSinglePlayer
 }
@@ -1,8 +1,8 @@
 package game
 
 import (
-	"snake-backend/constants"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 )
 
 // MultiplayerGameManager handles all multiplayer game logic
@@ -19,10 +19,7 @@ func NewMultiplayerGameManager(manager *Manager) *MultiplayerGameManager {
 
 // AuthorizeGameAccess checks if player is authorized to access a game
 func (mgm *MultiplayerGameManager) AuthorizeGameAccess(playerID, gameID string) bool {
-	mgm.manager.Mutex.RLock()
-	defer mgm.manager.Mutex.RUnlock()
-
-	game, exists := mgm.manager.Games[gameID]
+	game, exists := mgm.manager.lookupGame(gameID)
 	if !exists {
 		return false
 	}
@@ -45,17 +42,18 @@ func (mgm *MultiplayerGameManager) AuthorizeGameAccess(playerID, gameID string)
 }
 
 // HandlePlayerMove handles player move in multiplayer game
-func (mgm *MultiplayerGameManager) HandlePlayerMove(player *models.Player, gameID string, direction string) {
+func (mgm *MultiplayerGameManager) HandlePlayerMove(player *models.Player, gameID string, direction string, tick *int) {
 	// Check authorization
 	if !mgm.AuthorizeGameAccess(player.ID, gameID) {
 		mgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
 		return
 	}
 
-	mgm.manager.HandlePlayerMove(player, gameID, direction)
+	mgm.manager.HandlePlayerMove(player, gameID, direction, tick)
 }
 
 // HandlePlayerReady handles player ready in multiplayer game
@@ -63,6 +61,7 @@ func (mgm *MultiplayerGameManager) HandlePlayerReady(player *models.Player, game
 	// Check authorization
 	if !mgm.AuthorizeGameAccess(player.ID, gameID) {
 		mgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
@@ -77,6 +76,7 @@ func (mgm *MultiplayerGameManager) HandleRematchRequest(player *models.Player, g
 	// Check authorization
 	if !mgm.AuthorizeGameAccess(player.ID, gameID) {
 		mgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
@@ -91,6 +91,7 @@ func (mgm *MultiplayerGameManager) HandleRematchAccept(player *models.Player, ga
 	// Check authorization
 	if !mgm.AuthorizeGameAccess(player.ID, gameID) {
 		mgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
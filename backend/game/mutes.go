@@ -0,0 +1,105 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
+	"github.com/bariiss/snake/backend/profile"
+)
+
+var mutesChanged = metrics.NewCounterVec("snake_mutes_total", "Mute list changes, by action", "action")
+
+// SetProfiles sets the store of player settings, such as muted usernames
+// Call before the server starts accepting connections; without it mutes are refused
+func (gm *Manager) SetProfiles(profiles *profile.Profiles) {
+	gm.profiles = profiles
+}
+
+// MutedUsernames returns the usernames the player muted, lowercased and sorted
+func (gm *Manager) MutedUsernames(playerID string) []string {
+	if gm.profiles == nil {
+		return []string{}
+	}
+	return gm.profiles.Muted(playerID)
+}
+
+// MutePlayer hides lounge chat, emotes and spectator invites from username from the player
+// Mutes are by username, so they apply whether or not that player is online.
+func (gm *Manager) MutePlayer(player *models.Player, username string) {
+	if !gm.checkMute(player, username) {
+		return
+	}
+	err := gm.profiles.Mute(player.ID, username)
+	if errors.Is(err, profile.ErrTooManyMutes) {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "You can mute at most 100 players",
+			"code":    "MUTE_LIMIT_REACHED",
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to persist mute", "player_id", player.ID, "error", err)
+	}
+	mutesChanged.Inc("mute")
+	gm.sendMutedPlayers(player)
+}
+
+// UnmutePlayer shows messages from a muted username to the player again
+func (gm *Manager) UnmutePlayer(player *models.Player, username string) {
+	if !gm.checkMute(player, username) {
+		return
+	}
+	if err := gm.profiles.Unmute(player.ID, username); err != nil {
+		slog.Error("Failed to persist unmute", "player_id", player.ID, "error", err)
+	}
+	mutesChanged.Inc("unmute")
+	gm.sendMutedPlayers(player)
+}
+
+// checkMute validates a mute list change, answering the player when it is refused
+func (gm *Manager) checkMute(player *models.Player, username string) bool {
+	if gm.profiles == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Muting is not available on this server",
+			"code":    "MUTES_UNAVAILABLE",
+		})
+		return false
+	}
+	if names.Equal(username, player.Username) {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "You can't mute yourself",
+			"code":    "INVALID_MUTE",
+		})
+		return false
+	}
+	return true
+}
+
+// sendMutedPlayers sends the player their mute list
+func (gm *Manager) sendMutedPlayers(player *models.Player) {
+	gm.sendMessage(player, constants.MSG_MUTED_PLAYERS, map[string]any{
+		"usernames": gm.MutedUsernames(player.ID),
+	})
+}
+
+// mutedBy reports whether the recipient muted the sender
+func (gm *Manager) mutedBy(recipient *models.Player, sender *models.Player) bool {
+	return gm.profiles != nil && recipient.ID != sender.ID && gm.profiles.HasMuted(recipient.ID, sender.Username)
+}
+
+// broadcastFrom broadcasts a message a player sent to everyone in a game, except those
+// who muted the sender
+func (gm *Manager) broadcastFrom(game *models.Game, sender *models.Player, msgType string, data map[string]any) {
+	recipients := connectedParticipants(game)
+	unmuted := recipients[:0]
+	for _, recipient := range recipients {
+		if !gm.mutedBy(recipient, sender) {
+			unmuted = append(unmuted, recipient)
+		}
+	}
+	gm.broadcast(unmuted, msgType, data)
+}
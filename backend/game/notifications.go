@@ -0,0 +1,78 @@
+package game
+
+import (
+	"strconv"
+
+	"github.com/bariiss/snake/backend/notify"
+	"github.com/bariiss/snake/backend/profile"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// SetNotifier sets where notable events are posted for the community to see
+// Call before the server starts accepting connections
+func (gm *Manager) SetNotifier(notifier *notify.Notifier) {
+	gm.notifier = notifier
+}
+
+// notifyTournamentStarted posts that a tournament started; t must not change meanwhile
+func (gm *Manager) notifyTournamentStarted(t *tournament.Tournament) {
+	if !gm.Config().Notify.TournamentStart {
+		return
+	}
+	gm.notifier.Notify(notify.Message{
+		Event: notify.EventTournamentStart,
+		Title: "Tournament started",
+		Text:  t.Name + " is underway.",
+		Fields: []notify.Field{
+			{Name: "Format", Value: t.Format},
+			{Name: "Rules", Value: t.Rules},
+			{Name: "Players", Value: strconv.Itoa(len(t.Entrants))},
+		},
+	})
+}
+
+// leader returns who tops a leaderboard, "" for an empty one
+func leader(scores []profile.HighScore) string {
+	if len(scores) == 0 {
+		return ""
+	}
+	return scores[0].PlayerID
+}
+
+// notifyLeaderboardTop posts that a player took first place on a leaderboard from
+// previousLeader; keeping first place with a better score is no news
+func (gm *Manager) notifyLeaderboardTop(board, previousLeader string, scores []profile.HighScore) {
+	if !gm.Config().Notify.LeaderboardTop || len(scores) == 0 || scores[0].PlayerID == previousLeader {
+		return
+	}
+	top := scores[0]
+	gm.notifier.Notify(notify.Message{
+		Event: notify.EventLeaderboardTop,
+		Title: "New #1",
+		Text:  top.Username + " took first place on the " + board + " leaderboard.",
+		Fields: []notify.Field{
+			{Name: "Score", Value: strconv.Itoa(top.Score)},
+		},
+	})
+}
+
+// notifyWinStreak posts a player's multiplayer win streak once it reaches the configured
+// length, and again at every multiple of it
+func (gm *Manager) notifyWinStreak(playerID, username string) {
+	settings := gm.Config().Notify
+	if !settings.WinStreak || gm.notifier == nil {
+		return
+	}
+	streak := gm.profiles.WinStreak(playerID)
+	if streak == 0 || streak%settings.WinStreakLength != 0 {
+		return
+	}
+	gm.notifier.Notify(notify.Message{
+		Event: notify.EventWinStreak,
+		Title: "Win streak",
+		Text:  username + " has won " + strconv.Itoa(streak) + " games in a row.",
+		Fields: []notify.Field{
+			{Name: "Rating", Value: strconv.Itoa(gm.profiles.Rating(playerID))},
+		},
+	})
+}
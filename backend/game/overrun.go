@@ -0,0 +1,61 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+const (
+	// tickOverrunRatio is the share of the tick interval a tick may take before it is reported
+	tickOverrunRatio = 0.8
+	// overrunWarnInterval limits overrun warnings to one per game in this interval
+	overrunWarnInterval = 10 * time.Second
+	// maxStretchedInterval is the longest interval a slow game is stretched to
+	maxStretchedInterval = time.Second
+)
+
+var tickOverruns = metrics.NewCounterVec(
+	"snake_tick_overruns_total",
+	"Game ticks that took at least 80% of (near) or longer than (over) their tick interval",
+	"severity",
+)
+
+var tickStretches = metrics.NewCounter(
+	"snake_tick_stretches_total",
+	"Times a game's tick interval was doubled because its ticks overran",
+)
+
+// tickOverrun reports a tick whose update and broadcast took close to or longer than the
+// game's tick interval, which makes the game run in slow motion. With stretching enabled
+// a game that overran is moved to twice its interval so it runs slower but evenly.
+func (gm *Manager) tickOverrun(game *models.Game, took, interval time.Duration, warn bool) {
+	severity := "near"
+	if took > interval {
+		severity = "over"
+	}
+	tickOverruns.Inc(severity)
+
+	stretched := time.Duration(0)
+	if severity == "over" && gm.Config().Gameplay.StretchSlowTicks && interval*2 <= maxStretchedInterval {
+		stretched = interval * 2
+		if gm.scheduler.reschedule(game, stretched) {
+			tickStretches.Inc()
+		} else {
+			stretched = 0
+		}
+	}
+
+	if !warn && stretched == 0 {
+		return
+	}
+	slog.Warn("Game tick overran its interval",
+		"game_id", game.ID,
+		"severity", severity,
+		"tick_ms", float64(took.Microseconds())/1000,
+		"interval_ms", interval.Milliseconds(),
+		"stretched_to_ms", stretched.Milliseconds(),
+	)
+}
@@ -0,0 +1,389 @@
+package game
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// MaxPartySize is the most players a party holds, its leader and pending invites included
+const MaxPartySize = 4
+
+// Reasons a player is no longer in a party
+const (
+	partyLeft      = "left"      // The player left or disconnected
+	partyDisbanded = "disbanded" // Everyone else left
+)
+
+var partyEvents = metrics.NewCounterVec("snake_party_events_total", "Party changes, by event", "event")
+
+// party is a group of players who queue together and watch each other's games
+// Parties are kept by the instance their members are connected to and end once a single
+// member is left with no pending invite. Guarded by gm.partyMu.
+type party struct {
+	id      string
+	leader  string                    // Player ID of the leader
+	members []*models.Player          // In join order
+	invited map[string]*models.Player // Player ID -> invited player, until they answer
+}
+
+// partyMember is a player as listed in party messages
+type partyMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// snapshot returns the party_update fields; caller must hold gm.partyMu
+func (p *party) snapshot() map[string]any {
+	members := make([]partyMember, 0, len(p.members))
+	for _, member := range p.members {
+		members = append(members, partyMember{ID: member.ID, Username: member.Username})
+	}
+	invited := make([]partyMember, 0, len(p.invited))
+	for _, player := range p.invited {
+		invited = append(invited, partyMember{ID: player.ID, Username: player.Username})
+	}
+	slices.SortFunc(invited, func(a, b partyMember) int { return strings.Compare(a.Username, b.Username) })
+	return map[string]any{
+		"party_id":  p.id,
+		"leader_id": p.leader,
+		"members":   members,
+		"invited":   invited,
+	}
+}
+
+// isMember reports whether the player is in the party; caller must hold gm.partyMu
+func (p *party) isMember(playerID string) bool {
+	return slices.ContainsFunc(p.members, func(member *models.Player) bool { return member.ID == playerID })
+}
+
+// partyUpdate is a party message and its recipients, built under gm.partyMu so it can be
+// sent once the lock is released
+type partyUpdate struct {
+	msgType    string
+	recipients []*models.Player
+	data       map[string]any
+}
+
+// updateLocked builds the party_update for every member; caller must hold gm.partyMu
+func (p *party) updateLocked() partyUpdate {
+	return partyUpdate{msgType: constants.MSG_PARTY_UPDATE, recipients: slices.Clone(p.members), data: p.snapshot()}
+}
+
+// partyLeftUpdate builds the party_left message telling players they are no longer in a party
+func partyLeftUpdate(players []*models.Player, partyID, reason string) partyUpdate {
+	return partyUpdate{
+		msgType:    constants.MSG_PARTY_LEFT,
+		recipients: players,
+		data:       map[string]any{"party_id": partyID, "reason": reason},
+	}
+}
+
+// sendPartyUpdates sends party messages built under gm.partyMu
+func (gm *Manager) sendPartyUpdates(updates ...partyUpdate) {
+	for _, update := range updates {
+		gm.broadcast(update.recipients, update.msgType, update.data)
+	}
+}
+
+// sendPartyError answers a refused party request
+func (gm *Manager) sendPartyError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"message": message,
+		"code":    code,
+	})
+}
+
+// InviteToParty invites an online player, by username, to the sender's party
+// A player without a party becomes the leader of a new one; only the leader may invite.
+func (gm *Manager) InviteToParty(player *models.Player, username string) {
+	var update partyUpdate
+	target, _ := gm.sendInvite(player, username, invite{
+		msgType: constants.MSG_PARTY_INVITE,
+		refuse:  func(code, message string) { gm.sendPartyError(player, code, message) },
+		check: func(target *models.Player) (string, string) {
+			if target.ID == player.ID {
+				return "INVALID_PARTY_INVITE", "You can't invite yourself"
+			}
+			return "", ""
+		},
+		record: func(target *models.Player) (map[string]any, string, string) {
+			gm.partyMu.Lock()
+			defer gm.partyMu.Unlock()
+
+			p, exists := gm.playerParties[player.ID]
+			switch {
+			case exists && p.leader != player.ID:
+				return nil, "NOT_PARTY_LEADER", "Only the party leader can invite players"
+			case exists && p.isMember(target.ID):
+				return nil, "ALREADY_IN_PARTY", target.Username + " is already in your party"
+			case gm.playerParties[target.ID] != nil:
+				return nil, "ALREADY_IN_PARTY", target.Username + " is already in a party"
+			case exists && len(p.members)+len(p.invited) >= MaxPartySize:
+				if _, invited := p.invited[target.ID]; !invited {
+					return nil, "PARTY_FULL", "A party holds at most 4 players, pending invites included"
+				}
+			}
+			if !exists {
+				p = &party{
+					id:      uuid.New().String(),
+					leader:  player.ID,
+					members: []*models.Player{player},
+					invited: make(map[string]*models.Player),
+				}
+				gm.parties[p.id] = p
+				gm.playerParties[player.ID] = p
+				partyEvents.Inc("created")
+			}
+			p.invited[target.ID] = target
+			members := make([]string, 0, len(p.members))
+			for _, member := range p.members {
+				members = append(members, member.Username)
+			}
+			update = p.updateLocked()
+			return map[string]any{"party_id": p.id, "members": members}, "", ""
+		},
+	})
+	if target == nil {
+		return
+	}
+	partyEvents.Inc("invited")
+	gm.sendPartyUpdates(update)
+}
+
+// AcceptPartyInvite joins the party that invited the player
+func (gm *Manager) AcceptPartyInvite(player *models.Player, partyID string) {
+	gm.partyMu.Lock()
+	p, exists := gm.parties[partyID]
+	if !exists || p.invited[player.ID] == nil {
+		gm.partyMu.Unlock()
+		gm.sendPartyError(player, "PARTY_NOT_FOUND", "The party invite is no longer valid")
+		return
+	}
+	if gm.playerParties[player.ID] != nil {
+		gm.partyMu.Unlock()
+		gm.sendPartyError(player, "ALREADY_IN_PARTY", "Leave your party before joining another")
+		return
+	}
+	delete(p.invited, player.ID)
+	p.members = append(p.members, player)
+	gm.playerParties[player.ID] = p
+	update := p.updateLocked()
+	gm.partyMu.Unlock()
+
+	partyEvents.Inc("joined")
+	gm.sendPartyUpdates(update)
+}
+
+// DeclinePartyInvite turns down a party invite
+func (gm *Manager) DeclinePartyInvite(player *models.Player, partyID string) {
+	gm.partyMu.Lock()
+	p, exists := gm.parties[partyID]
+	if !exists || p.invited[player.ID] == nil {
+		gm.partyMu.Unlock()
+		return
+	}
+	delete(p.invited, player.ID)
+	update := gm.settlePartyLocked(p)
+	gm.partyMu.Unlock()
+
+	partyEvents.Inc("declined")
+	gm.sendPartyUpdates(update)
+}
+
+// LeaveParty removes the player from their party; the next member in join order takes over
+// as leader when the leader leaves
+func (gm *Manager) LeaveParty(player *models.Player) {
+	if partyID, found := gm.leaveParty(player.ID); found {
+		gm.sendPartyUpdates(partyLeftUpdate([]*models.Player{player}, partyID, partyLeft))
+	}
+}
+
+// leaveParty removes a player from their party and any invites, and tells the others
+// Returns the ID of the party the player was in, if any
+func (gm *Manager) leaveParty(playerID string) (string, bool) {
+	gm.partyMu.Lock()
+	var updates []partyUpdate
+	// Pending invites to the player are dropped as well
+	for _, p := range gm.parties {
+		if p.invited[playerID] != nil {
+			delete(p.invited, playerID)
+			updates = append(updates, gm.settlePartyLocked(p))
+		}
+	}
+	p, exists := gm.playerParties[playerID]
+	if exists {
+		p.members = slices.DeleteFunc(p.members, func(member *models.Player) bool { return member.ID == playerID })
+		delete(gm.playerParties, playerID)
+		if p.leader == playerID && len(p.members) > 0 {
+			p.leader = p.members[0].ID
+		}
+		updates = append(updates, gm.settlePartyLocked(p))
+	}
+	gm.partyMu.Unlock()
+
+	gm.sendPartyUpdates(updates...)
+	if !exists {
+		return "", false
+	}
+	partyEvents.Inc("left")
+	return p.id, true
+}
+
+// settlePartyLocked ends a party that is down to one member with no pending invite
+// Returns the party_update for the remaining members, or the party_left for the last one
+// when the party ended. Caller must hold gm.partyMu.
+func (gm *Manager) settlePartyLocked(p *party) partyUpdate {
+	if len(p.members) > 1 || len(p.invited) > 0 {
+		return p.updateLocked()
+	}
+	delete(gm.parties, p.id)
+	for _, member := range p.members {
+		delete(gm.playerParties, member.ID)
+	}
+	partyEvents.Inc("disbanded")
+	return partyLeftUpdate(p.members, p.id, partyDisbanded)
+}
+
+// QueueParty matches the leader's party into games among its members
+// Games are one against one, so members are paired in join order; a member left without
+// an opponent watches. Every member must be connected and not playing.
+func (gm *Manager) QueueParty(player *models.Player, rulesName string) {
+	rules, valid := ResolveRules(rulesName)
+	if !valid {
+		gm.sendInvalidRules(player, rulesName)
+		return
+	}
+
+	gm.partyMu.Lock()
+	p, exists := gm.playerParties[player.ID]
+	var members []*models.Player
+	var leader string
+	if exists {
+		members, leader = slices.Clone(p.members), p.leader
+	}
+	gm.partyMu.Unlock()
+
+	switch {
+	case !exists:
+		gm.sendPartyError(player, "NOT_IN_PARTY", "You are not in a party")
+		return
+	case leader != player.ID:
+		gm.sendPartyError(player, "NOT_PARTY_LEADER", "Only the party leader can queue the party")
+		return
+	case len(members) < 2:
+		gm.sendPartyError(player, "PARTY_TOO_SMALL", "Wait for someone to join your party first")
+		return
+	}
+	playing := gm.playersInGame()
+	for _, member := range members {
+		_, barred := gm.rankedBar(member)
+		if !member.Connected() || playing[member.ID] || barred {
+			gm.sendPartyError(player, "PARTY_MEMBER_BUSY", member.Username+" is not available to play")
+			return
+		}
+	}
+
+	games := gm.pairPartyGames(members, rules)
+	if len(games) == 0 {
+		// Only restrictions kept everyone apart, which nobody may learn: the party waits as
+		// if no game could be found yet
+		return
+	}
+	memberIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+	gm.Mutex.Lock()
+	if limit := gm.gameCapacityExceededLocked(memberIDs...); limit != "" {
+		gm.Mutex.Unlock()
+		gm.sendServerFull(player, limit)
+		return
+	}
+	for _, game := range games {
+		gm.addGameLocked(game)
+	}
+	gm.Mutex.Unlock()
+
+	partyEvents.Inc("queued")
+	for _, game := range games {
+		gm.emitGameCreated(game)
+		game.Mutex.RLock()
+		gameState := game.State.Clone()
+		game.Mutex.RUnlock()
+		// Party games skip the request: both players are sent straight to the ready screen
+		gm.broadcast([]*models.Player{game.Player1, game.Player2}, constants.MSG_GAME_ACCEPT, map[string]any{
+			"game_id": game.ID,
+			"data":    gameState,
+			"rules":   rules,
+			"party":   p.id,
+		})
+	}
+	gm.BroadcastGamesList()
+}
+
+// pairPartyGames pairs each member with the next one in join order they may play
+// Restricted members only play bots, as in matchmaking, so they are left to watch like an
+// odd member out, without being told why.
+func (gm *Manager) pairPartyGames(members []*models.Player, rules models.RulesProfile) []*models.Game {
+	games := make([]*models.Game, 0, len(members)/2)
+	unpaired := slices.Clone(members)
+	for len(unpaired) > 1 {
+		first := unpaired[0]
+		i := slices.IndexFunc(unpaired[1:], func(member *models.Player) bool {
+			return gm.rankedPairing(first, member)
+		})
+		if i >= 0 {
+			games = append(games, gm.newMultiplayerGame(first, unpaired[i+1], rules))
+			unpaired = slices.Delete(unpaired, i+1, i+2)
+		}
+		unpaired = unpaired[1:]
+	}
+	return games
+}
+
+// spectatePartyGame adds the idle party members of a game's players as spectators, once a
+// round starts
+// Members who are playing, watching or in a lounge are left where they are.
+func (gm *Manager) spectatePartyGame(game *models.Game, players []*models.Player) {
+	usernames := make([]string, 0, len(players))
+	for _, player := range players {
+		usernames = append(usernames, player.Username)
+	}
+
+	gm.partyMu.Lock()
+	var watchers []*models.Player
+	var partyID string
+	for _, player := range players {
+		p, exists := gm.playerParties[player.ID]
+		if !exists {
+			continue
+		}
+		for _, member := range p.members {
+			if member.ID != player.ID && !slices.Contains(players, member) && !slices.Contains(watchers, member) {
+				watchers = append(watchers, member)
+				partyID = p.id
+			}
+		}
+	}
+	gm.partyMu.Unlock()
+
+	for _, member := range watchers {
+		if !member.Connected() || len(gm.gamesOf(member.ID)) > 0 {
+			continue
+		}
+		partyEvents.Inc("auto_spectate")
+		gm.sendMessage(member, constants.MSG_PARTY_SPECTATE, map[string]any{
+			"party_id": partyID,
+			"game_id":  game.ID,
+			"players":  usernames,
+			"link":     spectateLink(game.ID),
+		})
+		gm.AddSpectator(member, game.ID)
+	}
+}
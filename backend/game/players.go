@@ -2,115 +2,112 @@ package game
 
 import (
 	"fmt"
-	"time"
 
-	"snake-backend/constants"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 )
 
 func (gm *Manager) RemovePlayer(playerID string) {
 	gm.Lobby.Remove(playerID)
 
-	gm.Mutex.Lock()
-	defer gm.Mutex.Unlock()
+	gm.unregisterPlayer(playerID)
+	gm.forgetEmotes(playerID)
+	gm.leaveParty(playerID)
+	gm.stopCasting(playerID, nil)
 
-	// Remove from global player registry
-	delete(gm.Players, playerID)
-
-	for i, p := range gm.MatchQueue {
-		if p.ID == playerID {
-			gm.MatchQueue = append(gm.MatchQueue[:i], gm.MatchQueue[i+1:]...)
-			break
+	// Leave every game the player is playing or watching; rated rounds wait for them to
+	// reconnect first
+	for _, game := range gm.gamesOf(playerID) {
+		if !gm.awaitReconnect(game, playerID) {
+			gm.disconnectFromGame(game, playerID)
 		}
 	}
+}
 
-	for gameID, game := range gm.Games {
-		game.Mutex.Lock()
-		// Check if player is in this game
-		isPlayer := game.Player1.ID == playerID || (game.Player2 != nil && game.Player2.ID == playerID)
-		if !isPlayer {
-			// Check if spectator
-			_, isSpectator := game.Spectators[playerID]
-			if isSpectator {
-				delete(game.Spectators, playerID)
-				game.Mutex.Unlock()
-				gm.BroadcastGamesList()
-				return
-			}
-			game.Mutex.Unlock()
-			continue
-		}
-
-		// Player is in this game
-		isActive := game.IsActive
-		isSinglePlayer := game.IsSinglePlayer
-		var disconnectedPlayer, otherPlayer *models.Player
+// disconnectFromGame removes a disconnected player from a game they play in or watch
+func (gm *Manager) disconnectFromGame(game *models.Game, playerID string) {
+	gameID := game.ID
 
-		// Determine which player is disconnecting
-		if game.Player1.ID == playerID {
-			disconnectedPlayer = game.Player1
-			otherPlayer = game.Player2
-			game.Player1.Send = nil
-		}
-		if game.Player2 != nil && game.Player2.ID == playerID {
-			disconnectedPlayer = game.Player2
-			otherPlayer = game.Player1
-			game.Player2.Send = nil
-		}
-		// Stop game ticker if game is active (for both single and multiplayer)
-		if isActive && game.Ticker != nil {
-			game.Ticker.Stop()
-			game.Ticker = nil
-			game.IsActive = false
-		}
+	game.Mutex.Lock()
+	isPlayer := game.Player1.ID == playerID || (game.Player2 != nil && game.Player2.ID == playerID)
+	if !isPlayer {
+		delete(game.Spectators, playerID)
 		game.Mutex.Unlock()
+		gm.unindexPlayer(playerID, gameID)
+		gm.BroadcastGamesList()
+		return
+	}
 
-		if isActive {
-			gm.endGame(game, "disconnect", game.State)
-		}
+	// Player is in this game
+	abandonRound(game, playerID)
+	isActive := game.EndRound()
+	isSinglePlayer := game.IsSinglePlayer
+	inLounge := hasLounge(game) && game.State.Status == "finished"
+	var disconnectedPlayer, otherPlayer *models.Player
 
-		// Only send disconnect message if it's a multiplayer game
-		if otherPlayer == nil || disconnectedPlayer == nil || isSinglePlayer {
-			delete(gm.Games, gameID)
-			return
-		}
+	// Determine which player is disconnecting
+	if game.Player1.ID == playerID {
+		disconnectedPlayer = game.Player1
+		otherPlayer = game.Player2
+		game.Player1.Detach()
+	}
+	if game.Player2 != nil && game.Player2.ID == playerID {
+		disconnectedPlayer = game.Player2
+		otherPlayer = game.Player1
+		game.Player2.Detach()
+	}
+	// Stop advancing the game if it is active (for both single and multiplayer)
+	if isActive {
+		gm.scheduler.remove(game.ID)
+	}
+	game.Mutex.Unlock()
 
-		// Send appropriate message based on game state
-		if isActive {
-			gm.sendMessage(otherPlayer, constants.MSG_PLAYER_DISCONNECTED, map[string]any{
-				"game_id": gameID,
-				"player":  disconnectedPlayer.Username,
-				"message": disconnectedPlayer.Username + " has left the game",
-			})
-			// Broadcast updated lobby status (disconnected player will show as "in game" until they reconnect)
-			gm.BroadcastLobbyStatus()
-		}
-		if !isActive {
-			gm.sendMessage(otherPlayer, constants.MSG_GAME_REQUEST_CANCEL, map[string]any{
-				"from_player": disconnectedPlayer,
-				"message":     fmt.Sprintf("%s left the lobby", disconnectedPlayer.Username),
-			})
-		}
+	if isActive {
+		gm.endGame(game, "disconnect", game.State)
+	}
 
-		// Add other player back to lobby if they still have active connection (common for both cases)
-		if otherPlayer.Send != nil {
-			if _, exists := gm.Lobby.Get(otherPlayer.ID); !exists {
-				gm.AddToLobby(otherPlayer)
-			}
-		}
-		delete(gm.Games, gameID)
+	// Only send disconnect message if it's a multiplayer game
+	if otherPlayer == nil || disconnectedPlayer == nil || isSinglePlayer {
+		gm.deleteGame(gameID)
 		return
 	}
+
+	// Send appropriate message based on game state; a game in its lounge has been played
+	if isActive || inLounge {
+		gm.sendMessage(otherPlayer, constants.MSG_PLAYER_DISCONNECTED, map[string]any{
+			"game_id": gameID,
+			"player":  disconnectedPlayer.Username,
+			"code":    "PLAYER_LEFT_GAME",
+			"message": disconnectedPlayer.Username + " has left the game",
+		})
+		// Broadcast updated lobby status (disconnected player will show as "in game" until they reconnect)
+		gm.BroadcastLobbyStatus()
+	}
+	if !isActive && !inLounge {
+		gm.sendMessage(otherPlayer, constants.MSG_GAME_REQUEST_CANCEL, map[string]any{
+			"from_player": disconnectedPlayer,
+			"player":      disconnectedPlayer.Username,
+			"code":        "PLAYER_LEFT_LOBBY",
+			"message":     fmt.Sprintf("%s left the lobby", disconnectedPlayer.Username),
+		})
+	}
+
+	// Add other player back to lobby if they still have active connection (common for both cases)
+	if otherPlayer.Connected() {
+		if _, exists := gm.Lobby.Get(otherPlayer.ID); !exists {
+			gm.AddToLobby(otherPlayer)
+		}
+	}
+	gm.deleteGame(gameID)
 }
 
 // LeaveGame allows a player to voluntarily leave a game
 func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Game not found",
 			"code":    "GAME_NOT_FOUND",
 		})
@@ -126,11 +123,13 @@ func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
 		if isSpectator {
 			delete(game.Spectators, player.ID)
 			game.Mutex.Unlock()
+			gm.unindexPlayer(player.ID, gameID)
 			gm.BroadcastGamesList()
 			return
 		}
 		game.Mutex.Unlock()
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "You are not in this game",
 			"code":    "NOT_IN_GAME",
 		})
@@ -138,25 +137,30 @@ func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
 	}
 
 	// Player is in this game - end the game
-	isActive := game.IsActive
+	abandonRound(game, player.ID)
+	isActive := game.EndRound()
 	isSinglePlayer := game.IsSinglePlayer
+	inLounge := hasLounge(game) && game.State.Status == "finished"
 	var otherPlayer *models.Player
 
-	// Determine which player is leaving
+	// Determine which player is leaving; a player leaving a lounge stays connected, as
+	// they go back to the lobby
 	if game.Player1.ID == player.ID {
 		otherPlayer = game.Player2
-		game.Player1.Send = nil
+		if !inLounge {
+			game.Player1.Detach()
+		}
 	}
 	if game.Player2 != nil && game.Player2.ID == player.ID {
 		otherPlayer = game.Player1
-		game.Player2.Send = nil
+		if !inLounge {
+			game.Player2.Detach()
+		}
 	}
 
-	// Stop game ticker if game is active
-	if isActive && game.Ticker != nil {
-		game.Ticker.Stop()
-		game.Ticker = nil
-		game.IsActive = false
+	// Stop advancing the game if it is active
+	if isActive {
+		gm.scheduler.remove(game.ID)
 	}
 	game.Mutex.Unlock()
 
@@ -166,10 +170,11 @@ func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
 	}
 
 	// Only send disconnect message if it's a multiplayer game with another player
-	if otherPlayer != nil && !isSinglePlayer && otherPlayer.Send != nil {
+	if otherPlayer != nil && !isSinglePlayer && otherPlayer.Connected() {
 		gm.sendMessage(otherPlayer, constants.MSG_PLAYER_DISCONNECTED, map[string]any{
 			"game_id": gameID,
 			"player":  player.Username,
+			"code":    "PLAYER_LEFT_GAME",
 			"message": player.Username + " has left the game",
 		})
 		// Add other player back to lobby
@@ -179,9 +184,14 @@ func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
 	}
 
 	// Remove game
-	gm.Mutex.Lock()
-	delete(gm.Games, gameID)
-	gm.Mutex.Unlock()
+	gm.deleteGame(gameID)
+
+	// Leaving a lounge returns the player to the lobby they played from
+	if inLounge && player.Connected() {
+		if _, exists := gm.Lobby.Get(player.ID); !exists {
+			gm.AddToLobby(player)
+		}
+	}
 
 	// Broadcast updated lobby status
 	gm.BroadcastLobbyStatus()
@@ -189,12 +199,11 @@ func (gm *Manager) LeaveGame(player *models.Player, gameID string) {
 }
 
 func (gm *Manager) AddSpectator(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Game not found",
 			"code":    "GAME_NOT_FOUND",
 		})
@@ -205,6 +214,7 @@ func (gm *Manager) AddSpectator(player *models.Player, gameID string) {
 	if game.Player1.ID == player.ID {
 		game.Mutex.Unlock()
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "You are already a player in this game",
 			"code":    "ALREADY_PLAYER",
 		})
@@ -213,6 +223,7 @@ func (gm *Manager) AddSpectator(player *models.Player, gameID string) {
 	if game.Player2 != nil && game.Player2.ID == player.ID {
 		game.Mutex.Unlock()
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "You are already a player in this game",
 			"code":    "ALREADY_PLAYER",
 		})
@@ -223,12 +234,28 @@ func (gm *Manager) AddSpectator(player *models.Player, gameID string) {
 		game.Mutex.Unlock()
 		return
 	}
+	// Invited players may watch whatever the spectator cap
+	_, invited := game.SpectatorInvites[player.ID]
+	if limit := gm.Limits().MaxSpectatorsPerGame; limit > 0 && len(game.Spectators) >= limit && !invited {
+		game.Mutex.Unlock()
+		capacityRejections.Inc(LimitMaxSpectators)
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "This game has as many spectators as it can take",
+			"code":    "SPECTATORS_FULL",
+			"limit":   limit,
+		})
+		return
+	}
 
 	game.Spectators[player.ID] = player
+	// A pending invite is used up once the invited player joins
+	delete(game.SpectatorInvites, player.ID)
 	game.Mutex.Unlock()
+	gm.indexPlayer(player.ID, gameID)
 
 	game.Mutex.RLock()
-	currentState := game.State
+	currentState := game.State.Clone()
 	game.Mutex.RUnlock()
 
 	gm.sendMessage(player, constants.MSG_SPECTATOR_UPDATE, map[string]any{
@@ -240,12 +267,11 @@ func (gm *Manager) AddSpectator(player *models.Player, gameID string) {
 }
 
 func (gm *Manager) HandleRematchRequest(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Game not found",
 			"code":    "GAME_NOT_FOUND",
 		})
@@ -257,6 +283,7 @@ func (gm *Manager) HandleRematchRequest(player *models.Player, gameID string) {
 		if game.Player2 == nil || game.Player2.ID != player.ID {
 			game.Mutex.Unlock()
 			gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+				"game_id": gameID,
 				"message": "Only players can request rematch",
 				"code":    "NOT_A_PLAYER",
 			})
@@ -264,22 +291,44 @@ func (gm *Manager) HandleRematchRequest(player *models.Player, gameID string) {
 		}
 	}
 
+	if game.Tournament != "" {
+		game.Mutex.Unlock()
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Tournament matches can't be rematched",
+			"code":    "REMATCH_UNAVAILABLE",
+		})
+		return
+	}
+
 	// Determine other player
 	otherPlayer := game.Player1
 	if game.Player1.ID == player.ID {
 		otherPlayer = game.Player2
 	}
+	hotSeat := game.HotSeat
+	rated := ratedGame(game)
 	game.Mutex.Unlock()
 
+	// Both seats of a hot-seat game share the connection, so nobody else has to accept
+	if hotSeat {
+		gm.HandleRematchAccept(player, gameID)
+		return
+	}
+	if gm.refuseMaintenance(player, "rematch", gameID) || (rated && gm.refuseBarred(player, gameID)) {
+		return
+	}
+
 	// Check if other player is still connected
-	if otherPlayer == nil || otherPlayer.Send == nil {
+	if otherPlayer == nil || !otherPlayer.Connected() {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Opponent has left the game. Returning to lobby...",
 			"code":    "OPPONENT_DISCONNECTED",
 		})
 		// Remove player from game and add back to lobby
-		delete(gm.Games, gameID)
-		if player.Send == nil {
+		gm.deleteGame(gameID)
+		if !player.Connected() {
 			return
 		}
 		if _, exists := gm.Lobby.Get(player.ID); !exists {
@@ -297,12 +346,11 @@ func (gm *Manager) HandleRematchRequest(player *models.Player, gameID string) {
 }
 
 func (gm *Manager) HandleRematchAccept(player *models.Player, gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"message": "Game not found",
 			"code":    "GAME_NOT_FOUND",
 		})
@@ -314,13 +362,27 @@ func (gm *Manager) HandleRematchAccept(player *models.Player, gameID string) {
 		if game.Player2 == nil || game.Player2.ID != player.ID {
 			game.Mutex.Unlock()
 			gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+				"game_id": gameID,
 				"message": "Only players can accept rematch",
 				"code":    "NOT_A_PLAYER",
 			})
 			return
 		}
 	}
+	tournamentGame := game.Tournament != ""
+	rated := ratedGame(game)
 	game.Mutex.Unlock()
+	if tournamentGame {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Tournament matches can't be rematched",
+			"code":    "REMATCH_UNAVAILABLE",
+		})
+		return
+	}
+	if gm.refuseMaintenance(player, "rematch", gameID) || (rated && gm.refuseBarred(player, gameID)) {
+		return
+	}
 
 	// Notify both players that rematch was accepted
 	gm.broadcastToPlayers(game, constants.MSG_REMATCH_ACCEPT, map[string]any{
@@ -330,13 +392,11 @@ func (gm *Manager) HandleRematchAccept(player *models.Player, gameID string) {
 	})
 
 	// Start rematch
-	go gm.startRematch(gameID)
+	gm.startRematch(gameID)
 }
 
 func (gm *Manager) startRematch(gameID string) {
-	gm.Mutex.RLock()
-	game, exists := gm.Games[gameID]
-	gm.Mutex.RUnlock()
+	game, exists := gm.lookupGame(gameID)
 
 	if !exists {
 		return
@@ -351,58 +411,6 @@ func (gm *Manager) startRematch(gameID string) {
 		return
 	}
 
-	// Countdown from 5 to 1
-	for i := 5; i > 0; i-- {
-		gm.broadcastToPlayers(game, constants.MSG_REMATCH_COUNTDOWN, map[string]any{
-			"game_id":   gameID,
-			"countdown": i,
-		})
-		time.Sleep(1 * time.Second)
-	}
-
-	// Reset game state and start game directly (no additional countdown)
-	game.Mutex.Lock()
-	// At this point, game.Player2 is guaranteed to be non-nil due to earlier check
-	game.State.Status = "playing"
-	game.State.Countdown = 0
-	game.State.Winner = ""
-	game.Player1.Ready = false
-	game.Player2.Ready = false
-
-	// Reset snakes
-	snake1 := models.Snake{
-		ID:        game.Player1.ID,
-		Body:      []models.Position{{X: 5, Y: 15}, {X: 4, Y: 15}, {X: 3, Y: 15}},
-		Direction: constants.RIGHT,
-		NextDir:   constants.RIGHT,
-		Color:     "#FF0000",
-		Score:     0,
-		Username:  game.Player1.Username,
-	}
-
-	snake2 := models.Snake{
-		ID:        game.Player2.ID,
-		Body:      []models.Position{{X: 35, Y: 15}, {X: 36, Y: 15}, {X: 37, Y: 15}},
-		Direction: constants.LEFT,
-		NextDir:   constants.LEFT,
-		Color:     "#0000FF",
-		Score:     0,
-		Username:  game.Player2.Username,
-	}
-
-	game.State.Snakes = []models.Snake{snake1, snake2}
-	game.State.Food = models.Food{Position: gm.generateFood([]models.Snake{snake1, snake2})}
-	game.IsActive = true
-	game.Mutex.Unlock()
-
-	// Stop existing ticker if any
-	if game.Ticker != nil {
-		game.Ticker.Stop()
-	}
-
-	game.Ticker = time.NewTicker(constants.TICK_RATE)
-	go gm.gameLoop(game)
-
-	// Broadcast game start
-	gm.broadcastToPlayers(game, constants.MSG_GAME_START, map[string]any{"data": game.State})
+	// The game restarts directly when the countdown ends (no additional countdown)
+	gm.startCountdown(game, gm.Config().Countdown.RematchSeconds, true)
 }
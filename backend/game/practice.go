@@ -0,0 +1,160 @@
+package game
+
+import (
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/bot"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+
+	"github.com/google/uuid"
+)
+
+// practiceBotName is the bundled bot a practice game is played against by default
+const practiceBotName = "greedy"
+
+// practiceRules are the rules of practice games: crashing costs two seconds instead of the round
+var practiceRules = models.RulesProfile{
+	Name:         constants.RULES_PRACTICE,
+	Wrap:         true,
+	TickRateMs:   100,
+	FoodCount:    2,
+	WinCondition: constants.WIN_LAST_STANDING,
+	RespawnMs:    2000,
+}
+
+// StartPracticeGame starts a no-stakes game against one of the bundled bots, which the
+// server steers itself
+// Crashed snakes respawn, so the round lasts until the player leaves, and nothing about
+// it is recorded.
+func (gm *Manager) StartPracticeGame(player *models.Player, botName string) {
+	if botName == "" {
+		botName = practiceBotName
+	}
+	newBot, exists := bot.Builtin[botName]
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"code":    "INVALID_BOT",
+			"message": "Unknown practice bot: " + botName,
+			"bot":     botName,
+			"bots":    bot.BuiltinNames(),
+		})
+		return
+	}
+
+	seat := &models.Player{
+		ID:       uuid.New().String(),
+		Username: "Practice bot",
+		Bot:      true,
+		Ready:    true,
+		JoinedAt: gm.clock.Now(),
+	}
+	rules := practiceRules
+	gameID := uuid.New().String()
+	opponent := newBot()
+
+	game := &models.Game{
+		ID:         gameID,
+		Player1:    player,
+		Player2:    seat,
+		Practice:   true,
+		Spectators: make(map[string]*models.Player),
+		Rules:      rules,
+		InstanceID: gm.Config().InstanceID,
+		Region:     gm.Config().Region,
+		Respawns:   make(map[string]time.Time),
+		// The bot gets a copy, as bots may keep the frames they are shown
+		Autopilot: func(state *models.GameState) constants.Direction {
+			return opponent.Next(bot.Observation{GameID: gameID, PlayerID: seat.ID, State: state.Clone(), Rules: rules})
+		},
+	}
+	game.State = &models.GameState{
+		ID:       gameID,
+		Status:   "countdown",
+		Practice: true,
+		Players: []models.PlayerStatus{
+			{ID: player.ID, Username: player.Username, Ready: true},
+			{ID: seat.ID, Username: seat.Username, Ready: true},
+		},
+	}
+
+	gm.Mutex.Lock()
+	if limit := gm.gameCapacityExceededLocked(player.ID); limit != "" {
+		gm.Mutex.Unlock()
+		gm.sendServerFull(player, limit)
+		return
+	}
+	gm.addGameLocked(game)
+	gm.Mutex.Unlock()
+	gm.emitGameCreated(game)
+
+	gm.startCountdown(game, gm.Config().Countdown.GameSeconds, false)
+}
+
+// steerAutopilot queues the next turn of the seat the server steers, unless its snake is
+// down or still has a turn queued; caller must hold game.Mutex
+func steerAutopilot(game *models.Game) {
+	if game.Autopilot == nil || game.Player2 == nil {
+		return
+	}
+	for i := range game.State.Snakes {
+		snake := &game.State.Snakes[i]
+		if snake.ID != game.Player2.ID || snake.Down || len(snake.Turns) > 0 {
+			continue
+		}
+		if direction := game.Autopilot(game.State); direction != snake.Direction {
+			snake.Turns = append(snake.Turns, direction)
+		}
+	}
+}
+
+// knockDown puts the snakes that crashed this tick down until their respawn is due;
+// caller must hold game.Mutex
+func (gm *Manager) knockDown(game *models.Game, crashed []string) {
+	respawnAt := gm.clock.Now().Add(time.Duration(game.Rules.RespawnMs) * time.Millisecond)
+	for i := range game.State.Snakes {
+		snake := &game.State.Snakes[i]
+		if !slices.Contains(crashed, snake.ID) {
+			continue
+		}
+		snake.Down = true
+		snake.Turns = nil
+		game.Respawns[snake.ID] = respawnAt
+	}
+}
+
+// respawnSnakes puts the snakes whose respawn is due back at their starting position, as
+// long as no other snake is in the way; they keep their score
+// Caller must hold game.Mutex.
+func (gm *Manager) respawnSnakes(game *models.Game) {
+	now := gm.clock.Now()
+	for i := range game.State.Snakes {
+		snake := &game.State.Snakes[i]
+		if !snake.Down || now.Before(game.Respawns[snake.ID]) {
+			continue
+		}
+		players := []*models.Player{game.Player1, game.Player2}
+		fresh := gm.newSnakes(game, players...)[i]
+		if spawnBlocked(game.State, i, fresh.Body) {
+			continue
+		}
+		snake.Body, snake.Direction, snake.Down = fresh.Body, fresh.Direction, false
+		delete(game.Respawns, snake.ID)
+	}
+}
+
+// spawnBlocked reports whether any snake but snake i covers a cell of body
+func spawnBlocked(board *models.GameState, i int, body []models.Position) bool {
+	for j, other := range board.Snakes {
+		if j == i {
+			continue
+		}
+		for _, cell := range body {
+			if slices.Contains(other.Body, cell) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/profile"
+)
+
+// RecentOpponent is an entry of a player's recent opponents with their current status
+// An opponent in the lobby can be challenged right away with a game_request for PlayerID.
+type RecentOpponent struct {
+	profile.Opponent
+	Online   bool   `json:"online"`
+	InLobby  bool   `json:"in_lobby"`
+	InGame   bool   `json:"in_game"`             // Playing a round that hasn't finished
+	PlayerID string `json:"player_id,omitempty"` // The opponent's current player ID while online
+}
+
+// recordOpponents remembers the round that just ended in both players' recent opponents
+// and awards the achievement titles it earned them.
+// Single-player, hot-seat and practice rounds have no opponent to remember.
+func (gm *Manager) recordOpponents(game *models.Game, player1, player2 *models.Player, winner string) {
+	if gm.profiles == nil || player2 == nil || game.IsSinglePlayer || game.HotSeat || game.Practice {
+		return
+	}
+	// A player who left before the round finished lost it
+	winner = abandonWinner(game, winner)
+	game.Mutex.RLock()
+	abandoned := game.Abandoned
+	game.Mutex.RUnlock()
+	for _, pair := range [][2]*models.Player{{player1, player2}, {player2, player1}} {
+		player, opponent := pair[0], pair[1]
+		if err := gm.profiles.RecordRound(player.ID, opponent.ID, opponent.Username, game.ID, roundResult(player.ID, winner), player.ID == abandoned); err != nil {
+			slog.Error("Failed to persist recent opponent", "player_id", player.ID, "error", err)
+		}
+		if player.ID == abandoned {
+			gm.noteAbandon(player)
+		}
+		gm.awardAchievements(player.ID)
+		gm.notifyWinStreak(player.ID, player.Username)
+	}
+}
+
+// roundResult is a player's result in a round won by winner
+func roundResult(playerID, winner string) string {
+	switch winner {
+	case playerID:
+		return profile.ResultWin
+	case "", "tie":
+		return profile.ResultTie
+	}
+	return profile.ResultLoss
+}
+
+// SendRecentOpponents sends the player their recent opponents, most recent first
+func (gm *Manager) SendRecentOpponents(player *models.Player) {
+	opponents := []RecentOpponent{}
+	if gm.profiles != nil {
+		for _, opponent := range gm.profiles.RecentOpponents(player.ID) {
+			opponents = append(opponents, gm.recentOpponent(opponent))
+		}
+	}
+	gm.sendMessage(player, constants.MSG_RECENT_OPPONENTS, map[string]any{
+		"opponents": opponents,
+	})
+}
+
+// recentOpponent looks up whether an opponent is online, by ID or else by username
+func (gm *Manager) recentOpponent(opponent profile.Opponent) RecentOpponent {
+	entry := RecentOpponent{Opponent: opponent}
+	current, exists := gm.registeredPlayer(opponent.ID)
+	if !exists || !current.Connected() {
+		current = gm.FindPlayerByUsername(opponent.Username)
+	}
+	if current == nil || !current.Connected() {
+		return entry
+	}
+	entry.Online = true
+	entry.PlayerID = current.ID
+	_, entry.InLobby = gm.Lobby.Get(current.ID)
+	for _, game := range gm.gamesOf(current.ID) {
+		game.Mutex.RLock()
+		playing := game.State.Status != "finished" &&
+			(game.Player1.ID == current.ID || (game.Player2 != nil && game.Player2.ID == current.ID))
+		game.Mutex.RUnlock()
+		entry.InGame = entry.InGame || playing
+	}
+	return entry
+}
+
+// MatchHistory returns the player's latest multiplayer rounds, most recent first
+func (gm *Manager) MatchHistory(playerID string) []profile.Round {
+	if gm.profiles == nil {
+		return []profile.Round{}
+	}
+	return gm.profiles.MatchHistory(playerID)
+}
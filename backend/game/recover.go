@@ -0,0 +1,140 @@
+package game
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/reporting"
+)
+
+// abortLockTimeout is how long aborting a game waits for its lock before treating the
+// game as wedged by the panic
+const abortLockTimeout = 100 * time.Millisecond
+
+var recoveredPanics = metrics.NewCounterVec("snake_recovered_panics_total", "Panics recovered without stopping the server", "source")
+
+// safeTick runs a tick and ends the game instead of crashing the server if it panics
+func (gm *Manager) safeTick(game *models.Game) (running bool) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		recoveredPanics.Inc("tick")
+		slog.Error("Game tick panicked, ending the game", "game_id", game.ID, "panic", rec, "stack", string(debug.Stack()))
+		reporting.Panic("Game tick panicked", rec, "game_id", game.ID)
+		gm.abortGame(game, "")
+		running = false
+	}()
+	return gm.tick(game)
+}
+
+// recoverMessage recovers a panic raised while handling a client message
+// The game the message was about, if the sender plays in it, ends with the sender
+// forfeiting; the sender gets an error and everyone else keeps playing
+func (gm *Manager) recoverMessage(player *models.Player, msgType string, msg map[string]any) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	recoveredPanics.Inc("message")
+	gameID, _ := msg["game_id"].(string)
+	player.Logger().Error("Message handler panicked", "msg_type", msgType, "game_id", gameID, "panic", rec, "stack", string(debug.Stack()))
+	reporting.Panic("Message handler panicked", rec, "msg_type", msgType, "game_id", gameID, "player_id", player.ID)
+
+	if game, exists := gm.lookupGame(gameID); exists {
+		gm.abortGame(game, player.ID)
+	}
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"game_id":  gameID,
+		"message":  "Internal error while handling " + msgType,
+		"code":     "INTERNAL_ERROR",
+		"msg_type": msgType,
+	})
+}
+
+// abortGame ends a game after a panic; culpritID forfeits, "" ends multiplayer games in a tie
+// Games the culprit only watches keep running. A game whose lock the panic left held is
+// dropped without notifying its players.
+func (gm *Manager) abortGame(game *models.Game, culpritID string) {
+	if !lockWithin(game, abortLockTimeout) {
+		slog.Error("Game is wedged after a panic, dropping it", "game_id", game.ID)
+		gm.forgetGame(game.ID)
+		return
+	}
+	isPlayer := game.Player1.ID == culpritID || (game.Player2 != nil && game.Player2.ID == culpritID)
+	if culpritID != "" && !isPlayer {
+		game.Mutex.Unlock()
+		return
+	}
+	running := game.EndRound() || game.Countdown != nil
+	game.Countdown = nil
+	winner := forfeitWinner(game, culpritID)
+	game.Mutex.Unlock()
+	gm.scheduler.remove(game.ID)
+
+	if !running {
+		return
+	}
+	slog.Warn("Game aborted", "game_id", game.ID, "winner", winner)
+	defer func() {
+		// The state that made the game panic may also break ending it
+		if rec := recover(); rec != nil {
+			slog.Error("Ending an aborted game panicked, dropping it", "game_id", game.ID, "panic", rec)
+			gm.forgetGame(game.ID)
+		}
+	}()
+	gm.endGame(game, winner, game.State)
+}
+
+// forfeitWinner returns the winner of a game the culprit forfeits (caller must hold game.Mutex)
+func forfeitWinner(game *models.Game, culpritID string) string {
+	switch {
+	case game.IsSinglePlayer:
+		return engine.GameOver
+	case game.Player2 == nil:
+		return "tie"
+	case culpritID == game.Player1.ID:
+		return game.Player2.ID
+	case culpritID == game.Player2.ID:
+		return game.Player1.ID
+	}
+	return "tie"
+}
+
+// forgetGame removes a game from the registry and scheduler without taking its lock
+func (gm *Manager) forgetGame(gameID string) {
+	gm.scheduler.remove(gameID)
+
+	gm.Mutex.Lock()
+	defer gm.Mutex.Unlock()
+	game, exists := gm.Games[gameID]
+	if !exists {
+		return
+	}
+	delete(gm.Games, gameID)
+	for playerID, games := range gm.playerGames {
+		if _, indexed := games[gameID]; indexed {
+			gm.unindexPlayerLocked(playerID, gameID)
+		}
+	}
+	game.Close()
+	gm.openGames.close(gameID)
+}
+
+// lockWithin takes the game lock, giving up after timeout
+func lockWithin(game *models.Game, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for !game.Mutex.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
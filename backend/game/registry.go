@@ -0,0 +1,229 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Lock order
+//
+// Each index has its own lock:
+//   - gm.Mutex guards Games and playerGames
+//   - gm.pendingMu guards PendingRequests and MatchQueue
+//   - gm.playersMu guards Players
+//   - gm.openGames.mu guards the index of unfinished games
+//
+// gm.Mutex may be held while taking a game's Mutex or gm.pendingMu, never the other way
+// round. gm.pendingMu, gm.playersMu, gm.partyMu, gm.tournamentsMu and gm.openGames.mu are leaves: nothing else is
+// locked while they are held. No manager lock is held while sending messages or calling back into the manager;
+// iterate over gamesSnapshot instead of holding gm.Mutex across per-game work.
+
+// lookupGame returns the game with the given ID
+func (gm *Manager) lookupGame(gameID string) (*models.Game, bool) {
+	gm.Mutex.RLock()
+	defer gm.Mutex.RUnlock()
+	game, exists := gm.Games[gameID]
+	return game, exists
+}
+
+// gamesSnapshot returns the registered games so callers can inspect them without holding gm.Mutex
+func (gm *Manager) gamesSnapshot() []*models.Game {
+	gm.Mutex.RLock()
+	defer gm.Mutex.RUnlock()
+
+	games := make([]*models.Game, 0, len(gm.Games))
+	for _, game := range gm.Games {
+		games = append(games, game)
+	}
+	return games
+}
+
+// addGameLocked registers a game, indexes its players and seeds it unless it was given a
+// random source (caller must hold gm.Mutex)
+func (gm *Manager) addGameLocked(game *models.Game) {
+	game.Open(gm.ctx)
+	if game.Rand == nil {
+		gm.seedGame(game)
+	}
+	gm.Games[game.ID] = game
+	gm.indexPlayerLocked(game.Player1.ID, game.ID)
+	if game.Player2 != nil {
+		gm.indexPlayerLocked(game.Player2.ID, game.ID)
+	}
+	if game.State == nil || game.State.Status != "finished" {
+		gm.openGames.open(game)
+	}
+}
+
+// deleteGame removes a game from the registry and the player index, and cancels it so any
+// countdown or round it has scheduled stops
+func (gm *Manager) deleteGame(gameID string) {
+	gm.Mutex.Lock()
+	if game, exists := gm.Games[gameID]; exists {
+		delete(gm.Games, gameID)
+		for _, playerID := range participants(game) {
+			gm.unindexPlayerLocked(playerID, gameID)
+		}
+		game.Close()
+		gm.openGames.close(gameID)
+	}
+	gm.Mutex.Unlock()
+	gm.scheduler.remove(gameID)
+}
+
+// participants returns the IDs of a game's players and spectators
+func participants(game *models.Game) []string {
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+
+	ids := make([]string, 0, 2+len(game.Spectators))
+	ids = append(ids, game.Player1.ID)
+	if game.Player2 != nil {
+		ids = append(ids, game.Player2.ID)
+	}
+	for id := range game.Spectators {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// indexPlayer records that a player plays in or watches a game
+func (gm *Manager) indexPlayer(playerID, gameID string) {
+	gm.Mutex.Lock()
+	gm.indexPlayerLocked(playerID, gameID)
+	gm.Mutex.Unlock()
+}
+
+func (gm *Manager) indexPlayerLocked(playerID, gameID string) {
+	games, exists := gm.playerGames[playerID]
+	if !exists {
+		games = make(map[string]struct{})
+		gm.playerGames[playerID] = games
+	}
+	games[gameID] = struct{}{}
+}
+
+// unindexPlayer records that a player left a game
+func (gm *Manager) unindexPlayer(playerID, gameID string) {
+	gm.Mutex.Lock()
+	gm.unindexPlayerLocked(playerID, gameID)
+	gm.Mutex.Unlock()
+}
+
+func (gm *Manager) unindexPlayerLocked(playerID, gameID string) {
+	games := gm.playerGames[playerID]
+	delete(games, gameID)
+	if len(games) == 0 {
+		delete(gm.playerGames, playerID)
+	}
+}
+
+// gamesOf returns the registered games a player plays in or watches
+func (gm *Manager) gamesOf(playerID string) []*models.Game {
+	gm.Mutex.RLock()
+	defer gm.Mutex.RUnlock()
+	return gm.gamesOfLocked(playerID)
+}
+
+// gamesOfLocked is gamesOf for callers holding gm.Mutex
+func (gm *Manager) gamesOfLocked(playerID string) []*models.Game {
+	gameIDs := gm.playerGames[playerID]
+	games := make([]*models.Game, 0, len(gameIDs))
+	for gameID := range gameIDs {
+		if game, exists := gm.Games[gameID]; exists {
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// InRunningGame reports whether a player plays or watches a game that is ticking
+func (gm *Manager) InRunningGame(playerID string) bool {
+	for _, game := range gm.gamesOf(playerID) {
+		game.Mutex.RLock()
+		playing := game.State != nil && game.State.Status == "playing"
+		game.Mutex.RUnlock()
+		if playing {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterPlayer adds a connected player to the global player registry
+func (gm *Manager) RegisterPlayer(player *models.Player) {
+	gm.playersMu.Lock()
+	gm.Players[player.ID] = player
+	gm.playersMu.Unlock()
+}
+
+// registeredPlayer returns a player from the global player registry
+func (gm *Manager) registeredPlayer(playerID string) (*models.Player, bool) {
+	gm.playersMu.RLock()
+	defer gm.playersMu.RUnlock()
+	player, exists := gm.Players[playerID]
+	return player, exists
+}
+
+// registeredPlayers returns every player in the global player registry
+func (gm *Manager) registeredPlayers() []*models.Player {
+	gm.playersMu.RLock()
+	defer gm.playersMu.RUnlock()
+
+	players := make([]*models.Player, 0, len(gm.Players))
+	for _, player := range gm.Players {
+		players = append(players, player)
+	}
+	return players
+}
+
+// unregisterPlayer removes a player from the global player registry, the match queue
+// and every pending request they sent or received
+func (gm *Manager) unregisterPlayer(playerID string) {
+	gm.playersMu.Lock()
+	delete(gm.Players, playerID)
+	gm.playersMu.Unlock()
+
+	gm.pendingMu.Lock()
+	gm.MatchQueue = slices.DeleteFunc(gm.MatchQueue, func(p *models.Player) bool {
+		return p.ID == playerID
+	})
+	delete(gm.PendingRequests, playerID)
+	gm.pendingMu.Unlock()
+
+	gm.dropPendingRequestsFrom(playerID)
+}
+
+// takePendingRequest removes and returns the request fromID sent to toID
+func (gm *Manager) takePendingRequest(toID, fromID string) (*models.Game, bool) {
+	gm.pendingMu.Lock()
+	defer gm.pendingMu.Unlock()
+
+	targetRequests, exists := gm.PendingRequests[toID]
+	if !exists {
+		return nil, false
+	}
+	game, exists := targetRequests[fromID]
+	if !exists {
+		return nil, false
+	}
+	delete(targetRequests, fromID)
+	if len(targetRequests) == 0 {
+		delete(gm.PendingRequests, toID)
+	}
+	return game, true
+}
+
+// dropPendingRequestsFrom removes every request the player has sent
+func (gm *Manager) dropPendingRequestsFrom(fromID string) {
+	gm.pendingMu.Lock()
+	defer gm.pendingMu.Unlock()
+
+	for targetID, requests := range gm.PendingRequests {
+		delete(requests, fromID)
+		if len(requests) == 0 {
+			delete(gm.PendingRequests, targetID)
+		}
+	}
+}
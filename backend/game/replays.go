@@ -0,0 +1,179 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/replay"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// Errors returned when verifying a recorded round
+var (
+	ErrReplaysUnavailable = errors.New("replays are not kept on this server")
+	ErrReplayNotFound     = errors.New("no recorded round of the game")
+)
+
+var replayVerifications = metrics.NewCounterVec("snake_replay_verifications_total", "Recorded rounds played again to verify them, by result", "result")
+
+// SetReplays sets the archive multiplayer rounds are recorded to
+// Call before the server starts accepting connections; without it nothing can be verified.
+func (gm *Manager) SetReplays(archive *replay.Archive) {
+	gm.replays = archive
+}
+
+// newReplay starts the record of a multiplayer round about to be laid out, or returns nil
+// when the round isn't kept; caller must hold game.Mutex
+// Practice games and rounds whose snakes respawn are left out, as they aren't decided on
+// the board alone.
+func (gm *Manager) newReplay(game *models.Game, players []*models.Player) *replay.Record {
+	rewinder, rewinds := game.Rand.(rng.Rewinder)
+	if gm.replays == nil || !rewinds || game.Player2 == nil || game.Practice || game.Rules.Respawns() {
+		return nil
+	}
+	settings := gm.Config().Gameplay
+	record := &replay.Record{
+		GameID:      game.ID,
+		Tournament:  game.Tournament,
+		Seed:        game.Seed,
+		RandFrom:    rewinder.Position(),
+		Rules:       game.Rules,
+		SpawnLayout: gm.spawnLayout(game.Rules),
+		StartLength: settings.StartLength,
+	}
+	for _, player := range players {
+		record.Snakes = append(record.Snakes, player.ID)
+	}
+	return record
+}
+
+// openReplay records the board a round starts on once it is laid out; caller must hold game.Mutex
+func openReplay(game *models.Game) {
+	if game.Replay != nil {
+		game.Replay.Round = game.Round
+		game.Replay.Opening = game.State.Clone()
+	}
+}
+
+// recordReplayInputs adds the turns applied on the current tick to the round's record;
+// caller must hold game.Mutex
+func recordReplayInputs(game *models.Game, inputs engine.Inputs) {
+	if game.Replay == nil {
+		return
+	}
+	for _, snakeID := range slices.Sorted(maps.Keys(inputs)) {
+		game.Replay.Inputs = append(game.Replay.Inputs, replay.Input{Tick: game.Ticks, Snake: snakeID, Direction: inputs[snakeID]})
+	}
+}
+
+// closeReplay ends the record of the round that just ended and returns it, nil when none
+// was kept; caller must hold game.Mutex
+func (gm *Manager) closeReplay(game *models.Game) *replay.Record {
+	record := game.Replay
+	game.Replay = nil
+	if record == nil || record.Opening == nil {
+		return nil
+	}
+	record.Ticks = game.Ticks
+	record.Final = game.State.Clone()
+	record.EndedAt = gm.clock.Now().UTC()
+	return record
+}
+
+// archiveReplay keeps the record of a round that ended so that it can be verified later
+func (gm *Manager) archiveReplay(record *replay.Record) {
+	if record == nil {
+		return
+	}
+	if err := gm.replays.Save(*record); err != nil {
+		slog.Error("Failed to persist replay", "game_id", record.GameID, "round", record.Round, "error", err)
+	}
+}
+
+// VerifyReplay plays a recorded round of a game again, its latest when round is 0, and
+// reports whether it ends as the server reported
+func (gm *Manager) VerifyReplay(gameID string, round int) (replay.Verdict, error) {
+	if gm.replays == nil {
+		return replay.Verdict{}, ErrReplaysUnavailable
+	}
+	record, exists := gm.replays.Find(gameID, round)
+	if !exists {
+		return replay.Verdict{}, ErrReplayNotFound
+	}
+	verdict := replay.Verify(record)
+	countVerification(verdict)
+	return verdict, nil
+}
+
+// VerifyTournamentMatch plays the latest game of a disputed tournament match again and
+// checks it against the match's result; only the tournament's organizer and moderators may
+func (gm *Manager) VerifyTournamentMatch(player *models.Player, tournamentID, matchID string) {
+	gm.tournamentsMu.Lock()
+	t, exists := gm.tournaments[tournamentID]
+	var moderates bool
+	var match tournamentMatchResult
+	if exists {
+		moderates = t.Moderates(player.ID)
+		if m, found := t.Match(matchID); found {
+			match = tournamentMatchResult{found: true, gameID: m.GameID, decided: m.Decided(), winner: m.Winner, scores: maps.Clone(m.Scores)}
+		}
+	}
+	gm.tournamentsMu.Unlock()
+
+	switch {
+	case !exists:
+		gm.sendTournamentError(player, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+		return
+	case !moderates:
+		gm.sendTournamentError(player, tournamentErrorCodes[errNotModerating], "Only the organizer or a moderator can do this")
+		return
+	case !match.found:
+		gm.sendTournamentError(player, "MATCH_NOT_FOUND", "Match not found")
+		return
+	}
+
+	var record replay.Record
+	if gm.replays != nil && match.gameID != "" {
+		record, exists = gm.replays.Find(match.gameID, 0)
+	}
+	if !exists || record.Tournament != tournamentID {
+		gm.sendTournamentError(player, "REPLAY_NOT_FOUND", "No recorded game of the match to verify")
+		return
+	}
+	verdict := replay.Verify(record)
+	if match.decided {
+		verdict.CompareResult(match.winner, match.scores)
+	}
+	countVerification(verdict)
+	slog.Info("Tournament match verified", "tournament_id", tournamentID, "match_id", matchID, "verified", verdict.Verified, "by", player.Username)
+	gm.sendMessage(player, constants.MSG_MATCH_VERIFICATION, map[string]any{
+		"tournament_id": tournamentID,
+		"match_id":      matchID,
+		"verdict":       verdict,
+	})
+}
+
+// tournamentMatchResult is the part of a tournament match a verification reads
+type tournamentMatchResult struct {
+	found   bool
+	gameID  string
+	decided bool
+	winner  string
+	scores  map[string]int
+}
+
+// countVerification counts a verification and warns of a round that diverged
+func countVerification(verdict replay.Verdict) {
+	if verdict.Verified {
+		replayVerifications.Inc("verified")
+		return
+	}
+	replayVerifications.Inc("diverged")
+	slog.Warn("Replay diverged from the reported round", "game_id", verdict.GameID, "round", verdict.Round, "divergences", verdict.Divergences)
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+)
+
+// maxReportCommentLength is the longest report comment accepted, in characters
+const maxReportCommentLength = 500
+
+var reportsFiled = metrics.NewCounterVec("snake_reports_total", "Player reports filed, by category", "category")
+
+// SetReports sets the moderation queue player reports are filed to
+// Call before the server starts accepting connections; without it reports are refused
+func (gm *Manager) SetReports(reports *moderation.Reports) {
+	gm.reports = reports
+}
+
+// ReportPlayer files a report about another player, with a snapshot of the game it concerns
+// Without a game ID the report is attached to a game the two players share, if any.
+func (gm *Manager) ReportPlayer(player *models.Player, username, category, comment, gameID string) {
+	if gm.reports == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Reporting is not available on this server",
+			"code":    "REPORTS_UNAVAILABLE",
+		})
+		return
+	}
+	if !moderation.ValidCategory(category) {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message":    "Unknown report category: " + category,
+			"code":       "INVALID_REPORT_CATEGORY",
+			"category":   category,
+			"categories": moderation.Categories,
+		})
+		return
+	}
+	comment = cleanChatText(comment)
+	if utf8.RuneCountInString(comment) > maxReportCommentLength {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Report comments must be at most 500 characters",
+			"code":    "INVALID_REPORT",
+		})
+		return
+	}
+
+	target := gm.FindPlayerByUsername(username)
+	if target == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message":  "No player named " + strings.TrimSpace(username),
+			"code":     "PLAYER_NOT_FOUND",
+			"username": username,
+		})
+		return
+	}
+	if target.ID == player.ID {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "You can't report yourself",
+			"code":    "INVALID_REPORT",
+		})
+		return
+	}
+	if gm.reports.HasOpen(player.ID, target.ID, category) {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "You already reported " + target.Username + " for this; a moderator will review it",
+			"code":    "DUPLICATE_REPORT",
+		})
+		return
+	}
+
+	report, err := gm.reports.File(moderation.Report{
+		ReporterID:   player.ID,
+		ReporterName: player.Username,
+		TargetID:     target.ID,
+		TargetName:   target.Username,
+		Category:     category,
+		Comment:      comment,
+		Game:         gm.reportContext(gameID, player.ID, target.ID),
+	})
+	if err != nil {
+		slog.Error("Failed to persist report", "report_id", report.ID, "error", err)
+	}
+	reportsFiled.Inc(category)
+	slog.Info("Player reported", "report_id", report.ID, "category", category, "reporter", player.Username, "target", target.Username)
+
+	gm.sendMessage(player, constants.MSG_REPORT_RECEIVED, map[string]any{
+		"report_id": report.ID,
+		"username":  target.Username,
+		"category":  category,
+	})
+}
+
+// reportContext snapshots the game a report concerns: the named one, or else one the
+// reporter and the target are both in
+// Returns nil when there is no such game; a named game that is gone keeps only its ID
+func (gm *Manager) reportContext(gameID, reporterID, targetID string) *moderation.GameContext {
+	var game *models.Game
+	if gameID != "" {
+		found, exists := gm.lookupGame(gameID)
+		if !exists {
+			return &moderation.GameContext{GameID: gameID}
+		}
+		game = found
+	} else {
+		for _, shared := range gm.gamesOf(reporterID) {
+			if slices.Contains(participants(shared), targetID) {
+				game = shared
+				break
+			}
+		}
+		if game == nil {
+			return nil
+		}
+	}
+
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+	return gameContext(game)
+}
+
+// gameContext snapshots a game for a report; caller must hold game.Mutex
+func gameContext(game *models.Game) *moderation.GameContext {
+	snapshot := &moderation.GameContext{
+		GameID:     game.ID,
+		Mode:       gameMode(game),
+		Status:     game.State.Status,
+		Rules:      game.Rules.Name,
+		Round:      game.Round,
+		Ticks:      game.Ticks,
+		Spectators: len(game.Spectators),
+		Scores:     make(map[string]int),
+		TurnsTaken: make(map[string]int),
+	}
+	for _, player := range []*models.Player{game.Player1, game.Player2} {
+		if player == nil {
+			continue
+		}
+		snapshot.Players = append(snapshot.Players, player.Username)
+		snapshot.TurnsTaken[player.Username] = game.TurnsTaken[player.ID]
+		for _, snake := range game.State.Snakes {
+			if snake.ID == player.ID {
+				snapshot.Scores[player.Username] = snake.Score
+			}
+		}
+	}
+	return snapshot
+}
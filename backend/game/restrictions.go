@@ -0,0 +1,72 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+)
+
+// ErrRestrictionsUnavailable is returned when restricting players without a restrictions store
+var ErrRestrictionsUnavailable = errors.New("restrictions are not available on this server")
+
+var restrictionChanges = metrics.NewCounterVec("snake_restrictions_total", "Shadow restrictions applied and lifted, by action", "action")
+
+// SetRestrictions sets the store of shadow restrictions
+// Call before the server starts accepting connections; without it nobody is restricted.
+func (gm *Manager) SetRestrictions(restrictions *moderation.Restrictions) {
+	gm.restrictions = restrictions
+}
+
+// RestrictPlayer shadow-restricts a player, who need not be connected
+// They disappear from the lobby of other players at once; games they are playing go on.
+func (gm *Manager) RestrictPlayer(playerID, reason, by string) (moderation.Restriction, error) {
+	if gm.restrictions == nil {
+		return moderation.Restriction{}, ErrRestrictionsUnavailable
+	}
+	var username string
+	if player, exists := gm.registeredPlayer(playerID); exists {
+		username = player.Username
+	}
+	restriction, err := gm.restrictions.Restrict(playerID, username, reason, by)
+	restrictionChanges.Inc("restrict")
+	gm.BroadcastLobbyStatus()
+	return restriction, err
+}
+
+// LiftRestriction ends a player's shadow restriction
+func (gm *Manager) LiftRestriction(playerID, by string) (moderation.Restriction, error) {
+	if gm.restrictions == nil {
+		return moderation.Restriction{}, ErrRestrictionsUnavailable
+	}
+	restriction, err := gm.restrictions.Lift(playerID, by)
+	if errors.Is(err, moderation.ErrNotRestricted) {
+		return restriction, err
+	}
+	restrictionChanges.Inc("lift")
+	gm.BroadcastLobbyStatus()
+	return restriction, err
+}
+
+// Restrictions returns the shadow restrictions in effect, newest first
+func (gm *Manager) Restrictions() []moderation.Restriction {
+	if gm.restrictions == nil {
+		return []moderation.Restriction{}
+	}
+	return gm.restrictions.List()
+}
+
+// restricted reports whether a player is under a shadow restriction
+func (gm *Manager) restricted(playerID string) bool {
+	return gm.restrictions != nil && gm.restrictions.Restricted(playerID)
+}
+
+// rankedPairing reports whether two players may be matched against each other: a restricted
+// player only plays bots
+func (gm *Manager) rankedPairing(a, b *models.Player) bool {
+	if a.Bot || b.Bot {
+		return true
+	}
+	return !gm.restricted(a.ID) && !gm.restricted(b.ID)
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// formParty makes the first player the leader of a party the others have joined
+func formParty(t *testing.T, gm *Manager, leader *models.Player, members ...*models.Player) {
+	t.Helper()
+	for _, member := range members {
+		gm.InviteToParty(leader, member.Username)
+		gm.partyMu.Lock()
+		p := gm.playerParties[leader.ID]
+		gm.partyMu.Unlock()
+		if p == nil {
+			t.Fatalf("%s has no party after inviting %s", leader.Username, member.Username)
+		}
+		gm.AcceptPartyInvite(member, p.id)
+	}
+}
+
+func TestQueuePartyHidesRestrictions(t *testing.T) {
+	tests := []struct {
+		name   string
+		leader int   // Index of the leader among alice, bob and carol
+		size   int   // Members, from alice on
+		paired []int // Members sent to a game
+	}{
+		{"restricted member left to watch", 0, 3, []int{0, 2}},
+		{"restricted leader left to watch", 1, 3, []int{0, 2}},
+		{"nobody to pair with", 0, 2, nil},
+		{"restricted leader with nobody to pair with", 1, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, _ := newTestManager(t, 1)
+			restrictions, err := moderation.OpenRestrictions(storage.NewMemory())
+			if err != nil {
+				t.Fatal(err)
+			}
+			gm.SetRestrictions(restrictions)
+
+			var players []*models.Player
+			var conns []*models.Connection
+			for _, name := range []string{"alice", "bob", "carol"}[:tt.size] {
+				player, conn := lobbyPlayer(gm, name[:1], name)
+				players, conns = append(players, player), append(conns, conn)
+			}
+			if _, err := gm.RestrictPlayer("b", "smurfing", "mod"); err != nil {
+				t.Fatal(err)
+			}
+			var others []*models.Player
+			for i, player := range players {
+				if i != tt.leader {
+					others = append(others, player)
+				}
+			}
+			formParty(t, gm, players[tt.leader], others...)
+			for _, conn := range conns {
+				received(t, conn)
+			}
+
+			gm.QueueParty(players[tt.leader], "")
+			for i, conn := range conns {
+				messages := received(t, conn)
+				if errMsg := ofType(messages, constants.MSG_ERROR); errMsg != nil {
+					t.Errorf("%s got an error: %v", players[i].Username, errMsg)
+				}
+				accept := ofType(messages, constants.MSG_GAME_ACCEPT)
+				if want := slices.Contains(tt.paired, i); (accept != nil) != want {
+					t.Errorf("%s sent to a game = %v, want %v", players[i].Username, accept != nil, want)
+				}
+			}
+		})
+	}
+}
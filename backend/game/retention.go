@@ -0,0 +1,81 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/models"
+)
+
+// retainFinishedGame keeps a game that just ended open for the retention window, so its
+// players can still ask for a rematch, and removes it afterwards
+// A rematch that starts in the meantime keeps the game; its own end starts a new window.
+func (gm *Manager) retainFinishedGame(game *models.Game) {
+	game.Mutex.RLock()
+	finishedAt := game.FinishedAt
+	game.Mutex.RUnlock()
+
+	retention := time.Duration(gm.Config().Gameplay.FinishedGameRetentionSeconds) * time.Second
+	go func() {
+		select {
+		case <-gm.clock.After(retention):
+			gm.expireFinishedGame(game, finishedAt)
+		case <-game.Context().Done():
+			// Removed by a disconnect or leave in the meantime, or the server is stopping
+		}
+	}()
+}
+
+// expireFinishedGame removes a game that is still finished since finishedAt, along with
+// the pending requests that refer to it and its spectators, and closes its lounge
+func (gm *Manager) expireFinishedGame(game *models.Game, finishedAt time.Time) {
+	game.Mutex.Lock()
+	expired := game.State.Status == "finished" && game.FinishedAt.Equal(finishedAt) &&
+		game.Countdown == nil && !game.IsActive()
+	spectators := len(game.Spectators)
+	var recipients, players []*models.Player
+	if expired && hasLounge(game) {
+		players = append(players, game.Player1)
+		if game.Player2 != nil {
+			players = append(players, game.Player2)
+		}
+		recipients = append(recipients, players...)
+		for _, spectator := range game.Spectators {
+			recipients = append(recipients, spectator)
+		}
+	}
+	game.Mutex.Unlock()
+	if !expired {
+		return
+	}
+
+	gm.dropPendingRequestsFor(game.ID)
+	// deleteGame unindexes the spectators, so they are only dropped from the game afterwards
+	gm.deleteGame(game.ID)
+	game.Mutex.Lock()
+	clear(game.Spectators)
+	game.Mutex.Unlock()
+	slog.Info("Finished game removed", "game_id", game.ID, "spectators", spectators)
+	if len(players) > 0 {
+		gm.closeLounge(game.ID, recipients, players)
+		gm.BroadcastLobbyStatus()
+	}
+	gm.BroadcastGamesList()
+}
+
+// dropPendingRequestsFor removes every pending request that refers to the game
+func (gm *Manager) dropPendingRequestsFor(gameID string) {
+	gm.pendingMu.Lock()
+	defer gm.pendingMu.Unlock()
+
+	for targetID, requests := range gm.PendingRequests {
+		for fromID, game := range requests {
+			if game != nil && game.ID == gameID {
+				delete(requests, fromID)
+			}
+		}
+		if len(requests) == 0 {
+			delete(gm.PendingRequests, targetID)
+		}
+	}
+}
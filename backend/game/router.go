@@ -0,0 +1,181 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// authLevel is who may send a message type
+type authLevel int
+
+const (
+	authAnyone    authLevel = iota // Any sender, even one whose connection was detached
+	authConnected                  // Players with a live connection
+)
+
+// playerState is a bitmask of where a player is; routes list the states they accept
+type playerState int
+
+const (
+	stateIdle       playerState = 1 << iota // Connected but neither in the lobby nor in a game
+	stateLobby                              // Waiting in the lobby
+	stateInGame                             // Playing in a game
+	stateSpectating                         // Watching a game
+
+	stateAny = stateIdle | stateLobby | stateInGame | stateSpectating
+)
+
+// route describes how one client message type is validated and handled
+type route struct {
+	auth    authLevel
+	states  playerState
+	payload reflect.Type
+	decode  func(msg map[string]any) (any, error)
+	handle  func(gm *Manager, player *models.Player, payload any)
+}
+
+// messageRoutes holds every client message type the manager handles
+var messageRoutes = map[string]route{}
+
+// handle registers the handler of a message type
+// The message is decoded into a P through its json tags; string fields tagged
+// `required:"true"` must be present and non-empty. Messages from senders below auth or
+// in none of states are rejected before the handler runs.
+func handle[P any](msgType string, auth authLevel, states playerState, fn func(gm *Manager, player *models.Player, payload P)) {
+	if _, exists := messageRoutes[msgType]; exists {
+		panic("game: duplicate route for " + msgType)
+	}
+	required := requiredFields(reflect.TypeFor[P]())
+
+	messageRoutes[msgType] = route{
+		auth:    auth,
+		states:  states,
+		payload: reflect.TypeFor[P](),
+		decode: func(msg map[string]any) (any, error) {
+			var payload P
+			raw, err := json.Marshal(msg)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					return nil, fmt.Errorf("%s must be a %s", typeErr.Field, typeErr.Type.Kind())
+				}
+				return nil, err
+			}
+			value := reflect.ValueOf(payload)
+			for _, field := range required {
+				if value.Field(field.index).String() == "" {
+					return nil, fmt.Errorf("%s is required", field.name)
+				}
+			}
+			return payload, nil
+		},
+		handle: func(gm *Manager, player *models.Player, payload any) {
+			fn(gm, player, payload.(P))
+		},
+	}
+}
+
+// ClientMessages returns the payload struct of every client message type, for tools that
+// describe the protocol; fields tagged `required:"true"` must be sent
+func ClientMessages() map[string]reflect.Type {
+	payloads := make(map[string]reflect.Type, len(messageRoutes))
+	for msgType, r := range messageRoutes {
+		payloads[msgType] = r.payload
+	}
+	return payloads
+}
+
+// requiredField is a string field of a payload that must not be empty
+type requiredField struct {
+	index int
+	name  string // JSON name, used in error messages
+}
+
+// requiredFields returns the fields of a payload struct tagged `required:"true"`
+func requiredFields(t reflect.Type) []requiredField {
+	var fields []requiredField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.Tag.Get("required") != "true" {
+			continue
+		}
+		if field.Type.Kind() != reflect.String {
+			panic("game: required payload field " + field.Name + " is not a string")
+		}
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			name = tag
+		}
+		fields = append(fields, requiredField{index: i, name: name})
+	}
+	return fields
+}
+
+// dispatch validates a client message against its route and runs the handler
+// Unknown message types are ignored so newer clients can talk to older servers
+func (gm *Manager) dispatch(player *models.Player, msgType string, msg map[string]any) {
+	route, exists := messageRoutes[msgType]
+	if !exists {
+		player.Logger().Debug("Ignoring unknown message type", "msg_type", msgType)
+		return
+	}
+
+	gameID, _ := msg["game_id"].(string)
+	if route.auth == authConnected && player.Connection() == nil {
+		player.Logger().Debug("Dropping message from disconnected player", "msg_type", msgType)
+		return
+	}
+	if state := gm.playerState(player.ID); route.states&state == 0 {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id":  gameID,
+			"message":  fmt.Sprintf("%s is not allowed right now", msgType),
+			"code":     "NOT_ALLOWED",
+			"msg_type": msgType,
+		})
+		return
+	}
+
+	payload, err := route.decode(msg)
+	if err != nil {
+		player.Logger().Debug("Invalid message payload", "msg_type", msgType, "error", err)
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id":  gameID,
+			"message":  fmt.Sprintf("Invalid %s message: %v", msgType, err),
+			"code":     "INVALID_PAYLOAD",
+			"msg_type": msgType,
+		})
+		return
+	}
+	route.handle(gm, player, payload)
+}
+
+// playerState returns where the player currently is
+// A player can be in the lobby while also playing or watching, so several bits may be set
+func (gm *Manager) playerState(playerID string) playerState {
+	var state playerState
+	if _, inLobby := gm.Lobby.Get(playerID); inLobby {
+		state |= stateLobby
+	}
+	for _, game := range gm.gamesOf(playerID) {
+		game.Mutex.RLock()
+		isPlayer := game.Player1.ID == playerID || (game.Player2 != nil && game.Player2.ID == playerID)
+		game.Mutex.RUnlock()
+		if isPlayer {
+			state |= stateInGame
+		} else {
+			state |= stateSpectating
+		}
+	}
+	if state == 0 {
+		return stateIdle
+	}
+	return state
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/features"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// rulesPresets are the named rules profiles clients can choose from
+var rulesPresets = map[string]models.RulesProfile{
+	constants.RULES_CLASSIC: {
+		Name:         constants.RULES_CLASSIC,
+		Wrap:         true,
+		TickRateMs:   100,
+		FoodCount:    1,
+		WinCondition: constants.WIN_LAST_STANDING,
+	},
+	constants.RULES_BLITZ: {
+		Name:         constants.RULES_BLITZ,
+		Wrap:         true,
+		TickRateMs:   70,
+		FoodCount:    3,
+		WinCondition: constants.WIN_SCORE_TARGET,
+		TargetScore:  10,
+	},
+	constants.RULES_ARCADE: {
+		Name:         constants.RULES_ARCADE,
+		Wrap:         false,
+		TickRateMs:   90,
+		FoodCount:    2,
+		WinCondition: constants.WIN_LAST_STANDING,
+		PowerUps:     true,
+	},
+	constants.RULES_DUEL: {
+		Name:         constants.RULES_DUEL,
+		Wrap:         true,
+		TickRateMs:   100,
+		FoodCount:    1,
+		WinCondition: constants.WIN_LAST_STANDING,
+		FoodSpawn:    constants.FOOD_SPAWN_FAIR,
+		TieBreak:     constants.TIE_BREAK_SUDDEN_DEATH,
+		SpawnLayout:  constants.SPAWN_RANDOM_SYMMETRIC,
+	},
+}
+
+// tieBreaks are the rules a head-on collision can be decided by
+var tieBreaks = []string{
+	constants.TIE_BREAK_TIE,
+	constants.TIE_BREAK_LENGTH,
+	constants.TIE_BREAK_SCORE,
+	constants.TIE_BREAK_SUDDEN_DEATH,
+}
+
+// ResolveRules returns the preset with the given name, defaulting to classic when name is empty
+// Power-ups are only enabled while the power_ups feature flag is on
+func ResolveRules(name string) (models.RulesProfile, bool) {
+	if name == "" {
+		name = constants.RULES_CLASSIC
+	}
+
+	rules, exists := rulesPresets[name]
+	if !exists {
+		return models.RulesProfile{}, false
+	}
+	rules.PowerUps = rules.PowerUps && features.Enabled(features.PowerUps)
+	return rules, true
+}
+
+// withTieBreak returns the rules with their tie-break replaced by the named one, or
+// unchanged when name is empty; false for an unknown tie-break
+func withTieBreak(rules models.RulesProfile, name string) (models.RulesProfile, bool) {
+	if name == "" {
+		return rules, true
+	}
+	if !slices.Contains(tieBreaks, name) {
+		return rules, false
+	}
+	rules.TieBreak = name
+	return rules, true
+}
+
+// sendInvalidTieBreak notifies a player that the requested tie-break does not exist
+func (gm *Manager) sendInvalidTieBreak(player *models.Player, name string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":      "INVALID_TIE_BREAK",
+		"message":   "Unknown tie-break: " + name,
+		"tie_break": name,
+	})
+}
+
+// sendInvalidRules notifies a player that the requested rules preset does not exist
+func (gm *Manager) sendInvalidRules(player *models.Player, name string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":    "INVALID_RULES",
+		"message": "Unknown rules profile: " + name,
+		"rules":   name,
+	})
+}
+
+// SendRulesList sends the available rules presets, single player difficulties and variants to a player
+func (gm *Manager) SendRulesList(player *models.Player) {
+	names := make([]string, 0, len(rulesPresets))
+	for name := range rulesPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]models.RulesProfile, 0, len(names))
+	for _, name := range names {
+		rules, _ := ResolveRules(name)
+		profiles = append(profiles, rules)
+	}
+
+	gm.sendMessage(player, constants.MSG_RULES_LIST, map[string]any{
+		"default":            constants.RULES_CLASSIC,
+		"rules":              profiles,
+		"default_difficulty": constants.DIFFICULTY_NORMAL,
+		"difficulties":       difficultyList(),
+		"variants":           singlePlayerVariants,
+	})
+}
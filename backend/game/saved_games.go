@@ -0,0 +1,176 @@
+package game
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// savedGame is a suspended single player game as kept in the player's profile
+type savedGame struct {
+	Rules         models.RulesProfile `json:"rules"`
+	Difficulty    models.Difficulty   `json:"difficulty"`
+	Variant       string              `json:"variant,omitempty"`
+	CampaignLevel int                 `json:"campaign_level,omitempty"`
+	Board         *models.GameState   `json:"board"`
+	Ticks         int                 `json:"ticks"`
+	PlayedMs      int64               `json:"played_ms"` // Time played before it was suspended, not counting pauses
+}
+
+// savedGameSummary is what a player is told about their saved game
+type savedGameSummary struct {
+	SavedAt       time.Time `json:"saved_at"`
+	Rules         string    `json:"rules"`
+	Difficulty    string    `json:"difficulty,omitempty"`
+	Variant       string    `json:"variant,omitempty"`
+	CampaignLevel int       `json:"campaign_level,omitempty"`
+	Score         int       `json:"score"`
+}
+
+// sendSavedGameError tells a player why a save or resume request was refused
+func (gm *Manager) sendSavedGameError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"message": message,
+		"code":    code,
+	})
+}
+
+// SuspendSinglePlayerGame saves a single player game being played to the player's profile
+// and removes it; it can be resumed later, from any connection of the same account, with
+// the same snake, score and food
+func (gm *Manager) SuspendSinglePlayerGame(player *models.Player, gameID string) {
+	if gm.profiles == nil {
+		gm.sendSavedGameError(player, "PROFILES_UNAVAILABLE", "Saving games is not available on this server")
+		return
+	}
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Game not found",
+			"code":    "GAME_NOT_FOUND",
+		})
+		return
+	}
+
+	game.Mutex.Lock()
+	refusal := ""
+	switch {
+	case !game.IsSinglePlayer || game.Player1.ID != player.ID:
+		refusal = "Only your own single player game can be saved"
+	case !game.IsActive() || game.Countdown != nil:
+		refusal = "The game can only be saved while it is being played"
+	case game.DailyRun != nil:
+		refusal = "A daily run can't be saved"
+	}
+	if refusal != "" {
+		game.Mutex.Unlock()
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": refusal,
+			"code":    "CANNOT_SUSPEND",
+		})
+		return
+	}
+
+	now := gm.clock.Now()
+	playedUntil := now
+	if !game.PausedAt.IsZero() {
+		playedUntil = game.PausedAt
+	}
+	saved := savedGame{
+		Rules:         game.Rules,
+		Difficulty:    game.Difficulty,
+		Variant:       game.Variant,
+		CampaignLevel: game.CampaignLevel,
+		Board:         game.State.Clone(),
+		Ticks:         game.Ticks,
+		PlayedMs:      playedUntil.Sub(game.StartedAt).Milliseconds(),
+	}
+	game.EndRound()
+	gm.scheduler.remove(game.ID)
+	game.Mutex.Unlock()
+
+	state, err := json.Marshal(saved)
+	if err == nil {
+		err = gm.profiles.SaveGame(player.ID, state, now)
+	}
+	if err != nil {
+		slog.Error("Failed to save game", "game_id", gameID, "player_id", player.ID, "error", err)
+	} else {
+		slog.Info("Single player game suspended", "game_id", gameID, "player_id", player.ID, "ticks", saved.Ticks)
+	}
+
+	gm.deleteGame(gameID)
+	gm.AddToLobby(player)
+	gm.SendSavedGame(player)
+}
+
+// ResumeSinglePlayerGame starts the player's saved game again, counting down from the
+// board it was saved with; the save is used up
+func (gm *Manager) ResumeSinglePlayerGame(player *models.Player) {
+	if gm.profiles == nil {
+		gm.sendSavedGameError(player, "PROFILES_UNAVAILABLE", "Saving games is not available on this server")
+		return
+	}
+	stored, exists, err := gm.profiles.TakeSavedGame(player.ID, gm.clock.Now())
+	if err != nil {
+		slog.Error("Failed to persist resumed game", "player_id", player.ID, "error", err)
+	}
+	if !exists {
+		gm.sendSavedGameError(player, "NO_SAVED_GAME", "You have no saved game")
+		return
+	}
+	var saved savedGame
+	if err := json.Unmarshal(stored.State, &saved); err != nil || saved.Board == nil {
+		slog.Error("Failed to decode saved game", "player_id", player.ID, "error", err)
+		gm.sendSavedGameError(player, "SAVED_GAME_INVALID", "Your saved game could not be restored")
+		return
+	}
+
+	gm.startSinglePlayer(player, saved.Rules, func(game *models.Game) {
+		game.Difficulty = saved.Difficulty
+		game.Variant = saved.Variant
+		game.CampaignLevel = saved.CampaignLevel
+		game.State.Snakes = saved.Board.Snakes
+		game.State.Foods = saved.Board.Foods
+		game.State.Obstacles = saved.Board.Obstacles
+		game.State.Difficulty = saved.Board.Difficulty
+		game.State.Variant = saved.Board.Variant
+		game.State.CampaignLevel = saved.Board.CampaignLevel
+		game.State.SpeedStage = saved.Board.SpeedStage
+		game.State.TimeLeft = saved.Board.TimeLeft
+		game.Resume = &models.Resume{
+			Ticks:  saved.Ticks,
+			Played: time.Duration(saved.PlayedMs) * time.Millisecond,
+		}
+	})
+}
+
+// SendSavedGame tells a player about their saved game; saved_game is null when they have none
+func (gm *Manager) SendSavedGame(player *models.Player) {
+	if gm.profiles == nil {
+		gm.sendSavedGameError(player, "PROFILES_UNAVAILABLE", "Saving games is not available on this server")
+		return
+	}
+	var summary *savedGameSummary
+	if stored, exists := gm.profiles.SavedGame(player.ID); exists {
+		var saved savedGame
+		if err := json.Unmarshal(stored.State, &saved); err == nil && saved.Board != nil {
+			summary = &savedGameSummary{
+				SavedAt:       stored.SavedAt,
+				Rules:         saved.Rules.Name,
+				Difficulty:    saved.Difficulty.Name,
+				Variant:       saved.Variant,
+				CampaignLevel: saved.CampaignLevel,
+			}
+			for _, snake := range saved.Board.Snakes {
+				summary.Score += snake.Score
+			}
+		}
+	}
+	gm.sendMessage(player, constants.MSG_SAVED_GAME, map[string]any{"saved_game": summary})
+}
@@ -0,0 +1,230 @@
+package game
+
+import (
+	"maps"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/clock"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// tickScheduler advances every active game from a shared clock instead of one ticker
+// goroutine per game. Games with the same tick interval share a lane and are advanced
+// on the same aligned tick; each lane splits its games across shards that run in parallel.
+type tickScheduler struct {
+	mu     sync.Mutex
+	lanes  map[time.Duration]*tickLane
+	step   func(*models.Game) bool // Advances a game one tick, returns false once it has ended
+	clock  clock.Clock
+	shards int
+
+	// overrun is told about steps that took close to or longer than their interval;
+	// warn is set at most once per overrunWarnInterval for each game
+	overrun func(game *models.Game, took, interval time.Duration, warn bool)
+
+	tickTimes    map[string]time.Duration // Game ID -> duration of its last step
+	overrunWarns map[string]time.Time     // Game ID -> when its last overrun warning was allowed
+}
+
+// tickLane drives all games that share a tick interval
+type tickLane struct {
+	interval time.Duration
+	ticker   clock.Ticker // Started with the lane, so a game ticks one interval after it is added
+	games    map[string]*models.Game
+	stop     chan struct{}
+}
+
+func newTickScheduler(clk clock.Clock, step func(*models.Game) bool, overrun func(*models.Game, time.Duration, time.Duration, bool)) *tickScheduler {
+	s := &tickScheduler{
+		lanes:        make(map[time.Duration]*tickLane),
+		step:         step,
+		clock:        clk,
+		overrun:      overrun,
+		shards:       runtime.GOMAXPROCS(0),
+		tickTimes:    make(map[string]time.Duration),
+		overrunWarns: make(map[string]time.Time),
+	}
+
+	metrics.NewGaugeFunc("snake_scheduled_games", "Games advanced by the tick scheduler", func() float64 {
+		return float64(s.count())
+	})
+	metrics.NewGaugeFunc("snake_scheduler_lanes", "Distinct tick intervals currently scheduled", func() float64 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return float64(len(s.lanes))
+	})
+	return s
+}
+
+// add schedules a game to be advanced every interval, replacing any previous schedule
+func (s *tickScheduler) add(game *models.Game, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addLocked(game, interval)
+}
+
+// reschedule moves a scheduled game to a different interval
+// Returns false if the game is no longer scheduled
+func (s *tickScheduler) reschedule(game *models.Game, interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, lane := range s.lanes {
+		if _, exists := lane.games[game.ID]; exists {
+			s.addLocked(game, interval)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *tickScheduler) addLocked(game *models.Game, interval time.Duration) {
+	s.removeLocked(game.ID)
+
+	lane, exists := s.lanes[interval]
+	if !exists {
+		lane = &tickLane{
+			interval: interval,
+			ticker:   s.clock.NewTicker(interval),
+			games:    make(map[string]*models.Game),
+			stop:     make(chan struct{}),
+		}
+		s.lanes[interval] = lane
+		go s.run(lane)
+	}
+	lane.games[game.ID] = game
+}
+
+// remove stops advancing a game
+func (s *tickScheduler) remove(gameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(gameID)
+}
+
+func (s *tickScheduler) removeLocked(gameID string) {
+	delete(s.tickTimes, gameID)
+	delete(s.overrunWarns, gameID)
+	for interval, lane := range s.lanes {
+		if _, exists := lane.games[gameID]; !exists {
+			continue
+		}
+		delete(lane.games, gameID)
+		// Idle lanes release their timer
+		if len(lane.games) == 0 {
+			close(lane.stop)
+			delete(s.lanes, interval)
+		}
+		return
+	}
+}
+
+// count returns the number of scheduled games
+func (s *tickScheduler) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, lane := range s.lanes {
+		total += len(lane.games)
+	}
+	return total
+}
+
+// schedule returns the interval a game is advanced at and the duration of its last step
+// Returns false if the game is not scheduled
+func (s *tickScheduler) schedule(gameID string) (interval, lastTick time.Duration, scheduled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, lane := range s.lanes {
+		if _, exists := lane.games[gameID]; exists {
+			return lane.interval, s.tickTimes[gameID], true
+		}
+	}
+	return 0, 0, false
+}
+
+// lastTickTimes returns how long the last step of each scheduled game took
+func (s *tickScheduler) lastTickTimes() map[string]time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.tickTimes)
+}
+
+// run advances the games of a lane on every tick until the lane is emptied
+func (s *tickScheduler) run(lane *tickLane) {
+	defer lane.ticker.Stop()
+
+	for {
+		select {
+		case <-lane.stop:
+			return
+		case <-lane.ticker.C():
+			s.advance(lane)
+		}
+	}
+}
+
+// advance runs one tick of every game in the lane, spreading games across shards
+func (s *tickScheduler) advance(lane *tickLane) {
+	s.mu.Lock()
+	games := make([]*models.Game, 0, len(lane.games))
+	for _, game := range lane.games {
+		games = append(games, game)
+	}
+	s.mu.Unlock()
+
+	// Step durations measure real work, so they use the wall clock even with a fake clock
+	shards := min(s.shards, len(games))
+	durations := make([]time.Duration, len(games))
+	var wg sync.WaitGroup
+	for shard := range shards {
+		wg.Go(func() {
+			for i := shard; i < len(games); i += shards {
+				start := time.Now()
+				// A canceled game was removed or the server is shutting down
+				if games[i].Context().Err() != nil || !s.step(games[i]) {
+					s.remove(games[i].ID)
+					continue
+				}
+				durations[i] = time.Since(start)
+			}
+		})
+	}
+	wg.Wait()
+
+	// Recorded after the shards finish so the lock is taken once per tick, not per game
+	type overrun struct {
+		game *models.Game
+		took time.Duration
+		warn bool
+	}
+	var overruns []overrun
+	threshold := time.Duration(float64(lane.interval) * tickOverrunRatio)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	for i, game := range games {
+		if _, scheduled := lane.games[game.ID]; !scheduled || durations[i] == 0 {
+			continue
+		}
+		s.tickTimes[game.ID] = durations[i]
+		if durations[i] < threshold {
+			continue
+		}
+		warn := now.Sub(s.overrunWarns[game.ID]) >= overrunWarnInterval
+		if warn {
+			s.overrunWarns[game.ID] = now
+		}
+		overruns = append(overruns, overrun{game: game, took: durations[i], warn: warn})
+	}
+	s.mu.Unlock()
+
+	for _, o := range overruns {
+		s.overrun(o.game, o.took, lane.interval, o.warn)
+	}
+}
@@ -1,83 +1,90 @@
 package game
 
 import (
-	"time"
-
-	"snake-backend/constants"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 
 	"github.com/google/uuid"
 )
 
-// StartSinglePlayerGame starts a single player game
-func (gm *Manager) StartSinglePlayerGame(player *models.Player) {
+// StartSinglePlayerGame starts a single player game of a variant at a difficulty, which
+// sets how fast the game starts and how quickly it speeds up as the snake eats
+// A daily run is always played with the default rules and difficulty.
+func (gm *Manager) StartSinglePlayerGame(player *models.Player, rulesName, difficultyName, variantName string) {
+	variant, valid := resolveVariant(variantName)
+	if !valid {
+		gm.sendInvalidVariant(player, variantName)
+		return
+	}
+	if variant == constants.VARIANT_DAILY {
+		rulesName, difficultyName = "", ""
+	}
+	rules, valid := ResolveRules(rulesName)
+	if !valid {
+		gm.sendInvalidRules(player, rulesName)
+		return
+	}
+	difficulty, valid := ResolveDifficulty(difficultyName)
+	if !valid {
+		gm.sendInvalidDifficulty(player, difficultyName)
+		return
+	}
+	if difficulty.TickRateMs == 0 {
+		difficulty.TickRateMs = rules.TickRateMs
+	}
+	rules.TickRateMs = difficulty.TickRateMs
+
+	gm.startSinglePlayer(player, rules, func(game *models.Game) {
+		game.Difficulty = difficulty
+		game.Variant = variant
+		game.State.Difficulty = difficulty.Name
+		game.State.Variant = variant
+		switch variant {
+		case constants.VARIANT_SCORE_ATTACK:
+			game.State.TimeLeft = int(ScoreAttackDuration.Seconds())
+		case constants.VARIANT_DAILY:
+			gm.setupDailyRun(game)
+		}
+	})
+}
+
+// startSinglePlayer creates a single player game with the given rules and starts its
+// countdown; setup adjusts the game before anyone can see it
+func (gm *Manager) startSinglePlayer(player *models.Player, rules models.RulesProfile, setup func(*models.Game)) {
 	gameID := uuid.New().String()
+	countdown := gm.Config().Countdown.GameSeconds
 
 	game := &models.Game{
 		ID:             gameID,
 		Player1:        player,
 		Player2:        nil,
-		IsActive:       false,
 		IsSinglePlayer: true,
 		Spectators:     make(map[string]*models.Player),
+		Rules:          rules,
+		InstanceID:     gm.Config().InstanceID,
+		Region:         gm.Config().Region,
 	}
 
 	game.State = &models.GameState{
 		ID:             gameID,
 		Status:         "countdown",
-		Countdown:      3,
+		Countdown:      countdown,
 		IsSinglePlayer: true,
 		Players: []models.PlayerStatus{
 			{ID: player.ID, Username: player.Username, Ready: true},
 		},
 	}
+	setup(game)
 
 	gm.Mutex.Lock()
-	gm.Games[gameID] = game
-	gm.Mutex.Unlock()
-
-	// Countdown
-	for i := 3; i > 0; i-- {
-		game.Mutex.Lock()
-		game.State.Countdown = i
-		game.State.IsSinglePlayer = true
-		game.Mutex.Unlock()
-
-		gm.sendMessage(player, constants.MSG_GAME_UPDATE, map[string]any{"data": game.State})
-		time.Sleep(1 * time.Second)
-	}
-
-	// Start game
-	game.Mutex.Lock()
-	game.State.Status = "playing"
-	game.State.Countdown = 0
-	game.State.IsSinglePlayer = true
-
-	snake := models.Snake{
-		ID:        player.ID,
-		Body:      []models.Position{{X: 20, Y: 15}, {X: 19, Y: 15}, {X: 18, Y: 15}},
-		Direction: constants.RIGHT,
-		NextDir:   constants.RIGHT,
-		Color:     "#4CAF50",
-		Score:     0,
-		Username:  player.Username,
-	}
-
-	game.State.Snakes = []models.Snake{snake}
-	game.State.Food = models.Food{Position: gm.generateFood(game.State.Snakes)}
-	game.IsActive = true
-	game.Mutex.Unlock()
-
-	gm.sendMessage(player, constants.MSG_GAME_START, map[string]any{"data": game.State})
-
-	// Remove from lobby
-	gm.RemoveFromLobby(player.ID)
-
-	// Stop existing ticker if any
-	if game.Ticker != nil {
-		game.Ticker.Stop()
+	if limit := gm.gameCapacityExceededLocked(player.ID); limit != "" {
+		gm.Mutex.Unlock()
+		gm.sendServerFull(player, limit)
+		return
 	}
+	gm.addGameLocked(game)
+	gm.Mutex.Unlock()
+	gm.emitGameCreated(game)
 
-	game.Ticker = time.NewTicker(constants.TICK_RATE)
-	go gm.gameLoop(game)
+	gm.startCountdown(game, countdown, false)
 }
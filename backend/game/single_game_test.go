@@ -0,0 +1,115 @@
+package game
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/bariiss/snake/backend/clock"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// newTestManager returns a manager on a fake clock whose games are all seeded with seed
+func newTestManager(t *testing.T, seed int64) (*Manager, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	gm := NewGameManager()
+	gm.SetClock(fake)
+	gm.SetSeeds(func() int64 { return seed })
+	t.Cleanup(gm.Stop)
+	return gm, fake
+}
+
+// startSingle starts a single player game with the given rules and returns it
+func startSingle(t *testing.T, gm *Manager, rules string) *models.Game {
+	t.Helper()
+	player := &models.Player{ID: "p1", Username: "alice"}
+	gm.StartSinglePlayerGame(player, rules, "", "")
+
+	gm.Mutex.RLock()
+	defer gm.Mutex.RUnlock()
+	for _, game := range gm.Games {
+		return game
+	}
+	t.Fatal("no game was created")
+	return nil
+}
+
+// status reads a game's status and tick under its lock
+func status(game *models.Game) (string, int) {
+	game.Mutex.Lock()
+	defer game.Mutex.Unlock()
+	return game.State.Status, game.Ticks
+}
+
+// playUntil advances the fake clock a tick interval at a time until the game reaches want
+// Each step waits for the scheduler to receive the tick, as an unreceived tick drops the next.
+func playUntil(t *testing.T, fake *clock.Fake, game *models.Game, want string) {
+	t.Helper()
+	interval := game.Rules.TickInterval()
+	for range 2000 {
+		if current, _ := status(game); current == want {
+			return
+		}
+		fake.Advance(interval)
+		for !fake.TicksDelivered() {
+			runtime.Gosched()
+		}
+	}
+	current, _ := status(game)
+	t.Fatalf("game status = %q, want %q", current, want)
+}
+
+func TestSinglePlayerCountdownWaitsForTheClock(t *testing.T) {
+	gm, fake := newTestManager(t, 1)
+	game := startSingle(t, gm, "")
+
+	countdown := time.Duration(gm.Config().Countdown.GameSeconds) * time.Second
+
+	// Real time passing does not move the countdown, only the clock does
+	time.Sleep(100 * time.Millisecond)
+	if current, _ := status(game); current != "countdown" {
+		t.Fatalf("status = %q before the clock moved, want countdown", current)
+	}
+
+	start := fake.Now()
+	playUntil(t, fake, game, "playing")
+	game.Mutex.Lock()
+	startedAt := game.StartedAt
+	game.Mutex.Unlock()
+	if waited := startedAt.Sub(start); waited < countdown {
+		t.Errorf("game started after %v of fake time, want at least %v", waited, countdown)
+	}
+}
+
+// TestSinglePlayerGameIsReproducible plays two games without input from the same seed on
+// fake clocks until the snake hits a wall and expects them to end on the same board
+func TestSinglePlayerGameIsReproducible(t *testing.T) {
+	play := func(seed int64) *models.GameState {
+		gm, fake := newTestManager(t, seed)
+		game := startSingle(t, gm, constants.RULES_ARCADE)
+		playUntil(t, fake, game, "finished")
+		game.Mutex.Lock()
+		defer game.Mutex.Unlock()
+		return game.State.Clone()
+	}
+
+	first, second := play(7), play(7)
+	if first.Tick != second.Tick || first.Winner != second.Winner {
+		t.Errorf("games ended at tick %d (%q) and %d (%q), want the same", first.Tick, first.Winner, second.Tick, second.Winner)
+	}
+	if len(first.Foods) == 0 || len(first.Foods) != len(second.Foods) {
+		t.Fatalf("food = %v and %v", first.Foods, second.Foods)
+	}
+	for i := range first.Foods {
+		if first.Foods[i].Position != second.Foods[i].Position {
+			t.Errorf("food %d at %v and %v, want the same cell", i, first.Foods[i].Position, second.Foods[i].Position)
+		}
+	}
+	for i := range first.Snakes {
+		if first.Snakes[i].Body[0] != second.Snakes[i].Body[0] || first.Snakes[i].Score != second.Snakes[i].Score {
+			t.Errorf("snake %d = %v/%d and %v/%d", i, first.Snakes[i].Body[0], first.Snakes[i].Score, second.Snakes[i].Body[0], second.Snakes[i].Score)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package game
 
 import (
-	"snake-backend/constants"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
 )
 
 // SinglePlayerGameManager handles all single player game logic
@@ -19,10 +19,7 @@ func NewSinglePlayerGameManager(manager *Manager) *SinglePlayerGameManager {
 
 // AuthorizeGameAccess checks if player is authorized to access a single player game
 func (spgm *SinglePlayerGameManager) AuthorizeGameAccess(playerID, gameID string) bool {
-	spgm.manager.Mutex.RLock()
-	defer spgm.manager.Mutex.RUnlock()
-
-	game, exists := spgm.manager.Games[gameID]
+	game, exists := spgm.manager.lookupGame(gameID)
 	if !exists {
 		return false
 	}
@@ -39,17 +36,18 @@ func (spgm *SinglePlayerGameManager) AuthorizeGameAccess(playerID, gameID string
 }
 
 // HandlePlayerMove handles player move in single player game
-func (spgm *SinglePlayerGameManager) HandlePlayerMove(player *models.Player, gameID string, direction string) {
+func (spgm *SinglePlayerGameManager) HandlePlayerMove(player *models.Player, gameID string, direction string, tick *int) {
 	// Check authorization
 	if !spgm.AuthorizeGameAccess(player.ID, gameID) {
 		spgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
 		return
 	}
 
-	spgm.manager.HandlePlayerMove(player, gameID, direction)
+	spgm.manager.HandlePlayerMove(player, gameID, direction, tick)
 }
 
 // HandlePlayerReady handles player ready in single player game
@@ -57,6 +55,7 @@ func (spgm *SinglePlayerGameManager) HandlePlayerReady(player *models.Player, ga
 	// Check authorization
 	if !spgm.AuthorizeGameAccess(player.ID, gameID) {
 		spgm.manager.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
 			"code":    "UNAUTHORIZED",
 			"message": "You are not authorized to perform this action",
 		})
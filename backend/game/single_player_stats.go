@@ -0,0 +1,43 @@
+package game
+
+import (
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/profile"
+)
+
+// recordSinglePlayerStats adds a finished single player game to the player's lifetime stats
+// and sends them the new totals
+// Every food eaten scores a point, so the score is also the food the snake ate.
+func (gm *Manager) recordSinglePlayerStats(game *models.Game, player *models.Player, stats MatchStats) {
+	if !game.IsSinglePlayer || gm.profiles == nil || len(stats.Players) == 0 {
+		return
+	}
+	result := stats.Players[0]
+	err := gm.profiles.RecordSinglePlayerGame(player.ID, profile.SinglePlayerGame{
+		Score:      result.Score,
+		Food:       result.Score,
+		SurvivalMs: stats.DurationMs,
+		Length:     result.Length,
+	}, gm.clock.Now())
+	if err != nil {
+		slog.Error("Failed to persist single player game", "game_id", game.ID, "player_id", player.ID, "error", err)
+	}
+	gm.SendSinglePlayerStats(player)
+}
+
+// SendSinglePlayerStats sends the player the stats of their finished single player games
+func (gm *Manager) SendSinglePlayerStats(player *models.Player) {
+	if gm.profiles == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Profiles are not available on this server",
+			"code":    "PROFILES_UNAVAILABLE",
+		})
+		return
+	}
+	gm.sendMessage(player, constants.MSG_SINGLE_PLAYER_STATS, map[string]any{
+		"stats": gm.profiles.SinglePlayerStats(player.ID),
+	})
+}
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// Snake colors, assigned in spawn order
+const (
+	singlePlayerColor = "#4CAF50"
+	player1Color      = "#FF0000"
+	player2Color      = "#0000FF"
+)
+
+// newSnakes creates the starting snakes of a game for one or two players using the
+// configured starting length and the game's spawn layout; random layouts draw from the
+// game's source
+func (gm *Manager) newSnakes(game *models.Game, players ...*models.Player) []models.Snake {
+	ids := make([]string, 0, len(players))
+	for _, player := range players {
+		ids = append(ids, player.ID)
+	}
+	snakes := engine.StartSnakes(game.Rand, gm.spawnLayout(game.Rules), gm.Config().Gameplay.StartLength, ids...)
+
+	colors := []string{player1Color, player2Color}
+	if len(players) == 1 {
+		colors = []string{singlePlayerColor}
+	}
+	for i, player := range players {
+		snakes[i].Color = colors[i]
+		snakes[i].Username = player.Username
+	}
+	return snakes
+}
+
+// spawnLayout returns the spawn layout of games played with the given rules: the rules'
+// own, or the configured one when they don't name one
+func (gm *Manager) spawnLayout(rules models.RulesProfile) string {
+	if rules.SpawnLayout != "" {
+		return rules.SpawnLayout
+	}
+	return gm.Config().Gameplay.SpawnLayout
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+)
+
+var spectatorInvites = metrics.NewCounterVec("snake_spectator_invites_total", "Spectator invites, by outcome", "outcome")
+
+// spectatorInviteOutcomes maps the codes of refused spectator invites to their outcomes
+var spectatorInviteOutcomes = map[string]string{
+	"PLAYER_NOT_FOUND":    "offline",
+	"ALREADY_IN_GAME":     "already_in_game",
+	"INVITE_RATE_LIMITED": "rate_limited",
+}
+
+// spectateLink is the client route that watches a game
+func spectateLink(gameID string) string {
+	return "/game/multiplayer/" + gameID
+}
+
+// InviteSpectator invites an online player, by username, to watch a game the sender plays in
+// The invite is recorded on the game until the invitee joins. Every game is open to
+// spectators for now; a check on who may watch must let pending invites through.
+func (gm *Manager) InviteSpectator(player *models.Player, gameID string, username string) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Game not found",
+			"code":    "GAME_NOT_FOUND",
+		})
+		return
+	}
+
+	game.Mutex.RLock()
+	isPlayer := game.Player1.ID == player.ID || (game.Player2 != nil && game.Player2.ID == player.ID)
+	live := game.State.Status != "finished"
+	players := []string{game.Player1.Username}
+	if game.Player2 != nil {
+		players = append(players, game.Player2.Username)
+	}
+	game.Mutex.RUnlock()
+
+	if !isPlayer {
+		spectatorInvites.Inc("not_a_player")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "Only players can invite spectators",
+			"code":    "NOT_A_PLAYER",
+		})
+		return
+	}
+	if !live {
+		spectatorInvites.Inc("finished")
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"game_id": gameID,
+			"message": "The game has already ended",
+			"code":    "GAME_FINISHED",
+		})
+		return
+	}
+
+	target, delivered := gm.sendInvite(player, username, invite{
+		msgType: constants.MSG_SPECTATOR_INVITE,
+		refuse: func(code, message string) {
+			spectatorInvites.Inc(spectatorInviteOutcomes[code])
+			data := map[string]any{"game_id": gameID, "message": message, "code": code}
+			if code != "INVITE_RATE_LIMITED" {
+				data["username"] = username
+			}
+			gm.sendMessage(player, constants.MSG_ERROR, data)
+		},
+		check: func(target *models.Player) (string, string) {
+			game.Mutex.RLock()
+			defer game.Mutex.RUnlock()
+			_, watching := game.Spectators[target.ID]
+			if watching || game.Player1.ID == target.ID || (game.Player2 != nil && game.Player2.ID == target.ID) {
+				return "ALREADY_IN_GAME", target.Username + " is already in this game"
+			}
+			return "", ""
+		},
+		record: func(target *models.Player) (map[string]any, string, string) {
+			game.Mutex.Lock()
+			if game.SpectatorInvites == nil {
+				game.SpectatorInvites = make(map[string]string)
+			}
+			game.SpectatorInvites[target.ID] = player.ID
+			game.Mutex.Unlock()
+			return map[string]any{
+				"game_id": gameID,
+				"players": players,
+				"link":    spectateLink(gameID),
+			}, "", ""
+		},
+	})
+	if target == nil {
+		return
+	}
+
+	if delivered {
+		spectatorInvites.Inc("sent")
+	} else {
+		spectatorInvites.Inc("muted")
+	}
+	gm.sendMessage(player, constants.MSG_SPECTATOR_INVITE_SENT, map[string]any{
+		"game_id":     gameID,
+		"to_id":       target.ID,
+		"to_username": target.Username,
+	})
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bariiss/snake/backend/models"
+)
+
+// ServerStats is a point-in-time summary of the server for operators
+type ServerStats struct {
+	Time                    time.Time   `json:"time"`
+	InstanceID              string      `json:"instance_id"`
+	Connections             int         `json:"connections"`
+	Players                 int         `json:"players"`
+	LobbyPlayers            int         `json:"lobby_players"`
+	Games                   int         `json:"games"`
+	ActiveGames             int         `json:"active_games"`
+	QueueDepth              int         `json:"queue_depth"`      // Players waiting in the match queue
+	PendingRequests         int         `json:"pending_requests"` // Game requests awaiting an answer
+	StaleFramesDropped      uint64      `json:"stale_frames_dropped"`
+	SlowConsumerDisconnects uint64      `json:"slow_consumer_disconnects"`
+	GameDetails             []GameStats `json:"game_details"`
+}
+
+// GameStats summarizes one game in ServerStats
+type GameStats struct {
+	ID         string   `json:"id"`
+	Status     string   `json:"status"`
+	Mode       string   `json:"mode"`
+	Rules      string   `json:"rules"`
+	Players    []string `json:"players"`
+	Spectators int      `json:"spectators"`
+	Watchers   int      `json:"watchers"` // Viewers streaming the game over server-sent events
+	TickMs     float64  `json:"tick_ms"`  // Duration of the game's last tick, 0 when not ticking
+}
+
+// Stats collects the current server statistics
+func (gm *Manager) Stats() ServerStats {
+	tickTimes := gm.scheduler.lastTickTimes()
+
+	stats := ServerStats{
+		Time:                    gm.clock.Now().UTC(),
+		InstanceID:              gm.Config().InstanceID,
+		Connections:             gm.ConnectionCount(),
+		Players:                 len(gm.registeredPlayers()),
+		LobbyPlayers:            gm.Lobby.Len(),
+		StaleFramesDropped:      staleFramesDropped.Value(),
+		SlowConsumerDisconnects: slowConsumerDisconnects.Value(),
+		GameDetails:             []GameStats{},
+	}
+
+	gm.pendingMu.Lock()
+	stats.QueueDepth = len(gm.MatchQueue)
+	for _, requests := range gm.PendingRequests {
+		stats.PendingRequests += len(requests)
+	}
+	gm.pendingMu.Unlock()
+
+	stats.GameDetails = gm.listGames(tickTimes)
+	for _, details := range stats.GameDetails {
+		stats.Games++
+		if details.Status != "finished" {
+			stats.ActiveGames++
+		}
+	}
+	return stats
+}
+
+// ListGames summarizes every game hosted by this instance, sorted by ID
+func (gm *Manager) ListGames() []GameStats {
+	return gm.listGames(gm.scheduler.lastTickTimes())
+}
+
+// listGames summarizes every game with its last tick duration from tickTimes
+func (gm *Manager) listGames(tickTimes map[string]time.Duration) []GameStats {
+	games := []GameStats{}
+	for _, game := range gm.gamesSnapshot() {
+		details := gameStats(game)
+		details.TickMs = float64(tickTimes[game.ID].Microseconds()) / 1000
+		games = append(games, details)
+	}
+	slices.SortFunc(games, func(a, b GameStats) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return games
+}
+
+// PlayerStats describes a registered player for operators
+type PlayerStats struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Bot         bool      `json:"bot,omitempty"`
+	Restricted  bool      `json:"restricted,omitempty"` // Under a shadow restriction
+	Linked      int       `json:"linked,omitempty"`     // Accounts sharing a device or address with the player
+	InLobby     bool      `json:"in_lobby"`
+	Connected   bool      `json:"connected"` // Has an outbound queue; false while waiting for a reconnect
+	ConnID      string    `json:"conn_id,omitempty"`
+	Subprotocol string    `json:"subprotocol,omitempty"`
+	JoinedAt    time.Time `json:"joined_at,omitzero"`
+	InstanceID  string    `json:"instance_id,omitempty"` // Set for proxies of players on another instance
+	Games       []string  `json:"games"`                 // IDs of the games the player takes part in
+
+	DisconnectRate float64   `json:"disconnect_rate,omitempty"` // Share of their latest rated rounds the player left before they finished
+	BarredUntil    time.Time `json:"barred_until,omitzero"`     // Until when the player is barred from rated play for leaving rounds; zero when they aren't
+}
+
+// ListPlayers describes every registered player, sorted by username
+func (gm *Manager) ListPlayers() []PlayerStats {
+	players := []PlayerStats{}
+	for _, player := range gm.registeredPlayers() {
+		players = append(players, gm.playerStats(player))
+	}
+	slices.SortFunc(players, func(a, b PlayerStats) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+	return players
+}
+
+// DescribePlayer describes a registered player, false when none has the ID
+func (gm *Manager) DescribePlayer(playerID string) (PlayerStats, bool) {
+	player, exists := gm.registeredPlayer(playerID)
+	if !exists {
+		return PlayerStats{}, false
+	}
+	return gm.playerStats(player), true
+}
+
+// playerStats describes a registered player
+func (gm *Manager) playerStats(player *models.Player) PlayerStats {
+	_, inLobby := gm.Lobby.Get(player.ID)
+	details := PlayerStats{
+		ID:         player.ID,
+		Username:   player.Username,
+		Bot:        player.Bot,
+		Restricted: gm.restricted(player.ID),
+		Linked:     gm.linkedCount(player.ID),
+		InLobby:    inLobby,
+		JoinedAt:   player.JoinedAt,
+		InstanceID: player.InstanceID,
+		Connected:  player.Connected(),
+		Games:      []string{},
+	}
+	if gm.profiles != nil {
+		details.DisconnectRate, _ = gm.profiles.DisconnectRate(player.ID)
+	}
+	if until, barred := gm.rankedBar(player); barred {
+		details.BarredUntil = until
+	}
+	if conn := player.LastConnection(); conn != nil {
+		details.ConnID = conn.ID
+		details.Subprotocol = conn.Subprotocol
+	}
+	for _, game := range gm.gamesOf(player.ID) {
+		details.Games = append(details.Games, game.ID)
+	}
+	slices.Sort(details.Games)
+	return details
+}
+
+// DescribeGame summarizes a game hosted by the instance, false when there is none
+func (gm *Manager) DescribeGame(gameID string) (GameStats, bool) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return GameStats{}, false
+	}
+	return gameStats(game), true
+}
+
+// gameStats summarizes a game without its board state
+func gameStats(game *models.Game) GameStats {
+	game.Mutex.RLock()
+	defer game.Mutex.RUnlock()
+
+	players := []string{game.Player1.Username}
+	if game.Player2 != nil {
+		players = append(players, game.Player2.Username)
+	}
+	return GameStats{
+		ID:         game.ID,
+		Status:     game.State.Status,
+		Mode:       gameMode(game),
+		Rules:      game.Rules.Name,
+		Players:    players,
+		Spectators: len(game.Spectators),
+		Watchers:   len(game.Watchers),
+	}
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/profile"
+)
+
+var titleEvents = metrics.NewCounterVec("snake_title_events_total", "Titles earned and equipped, by action", "action")
+
+// awardAchievements gives the player the achievement titles they just earned and tells them
+// if online
+func (gm *Manager) awardAchievements(playerID string) {
+	awarded, err := gm.profiles.AwardAchievements(playerID)
+	if err != nil {
+		slog.Error("Failed to persist title", "player_id", playerID, "error", err)
+	}
+	player, online := gm.registeredPlayer(playerID)
+	for _, title := range awarded {
+		titleEvents.Inc("earned")
+		if online {
+			gm.sendMessage(player, constants.MSG_TITLE_EARNED, map[string]any{
+				"title": title,
+			})
+		}
+	}
+}
+
+// awardTitle gives the player a title won outside of achievements and tells them if online
+func (gm *Manager) awardTitle(playerID, titleID string) {
+	if gm.profiles == nil {
+		return
+	}
+	awarded, err := gm.profiles.Award(playerID, titleID)
+	if err != nil {
+		slog.Error("Failed to persist title", "player_id", playerID, "error", err)
+	}
+	if !awarded {
+		return
+	}
+	titleEvents.Inc("earned")
+	if player, online := gm.registeredPlayer(playerID); online {
+		title, _ := profile.LookupTitle(titleID)
+		gm.sendMessage(player, constants.MSG_TITLE_EARNED, map[string]any{
+			"title": title,
+		})
+	}
+}
+
+// UpdateProfile equips one of the player's earned titles; an empty title takes it off
+// The new title shows in the lobby right away and in the next round's game_start.
+func (gm *Manager) UpdateProfile(player *models.Player, titleID string) {
+	if gm.profiles == nil {
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Profiles are not available on this server",
+			"code":    "PROFILES_UNAVAILABLE",
+		})
+		return
+	}
+	err := gm.profiles.Equip(player.ID, titleID)
+	switch {
+	case errors.Is(err, profile.ErrUnknownTitle):
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Unknown title",
+			"code":    "INVALID_TITLE",
+		})
+		return
+	case errors.Is(err, profile.ErrTitleNotEarned):
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "You haven't earned that title yet",
+			"code":    "TITLE_NOT_EARNED",
+		})
+		return
+	case err != nil:
+		slog.Error("Failed to persist title", "player_id", player.ID, "error", err)
+	}
+	titleEvents.Inc("equipped")
+	gm.sendMessage(player, constants.MSG_PROFILE, gm.Profile(player.ID))
+
+	if _, inLobby := gm.Lobby.Get(player.ID); inLobby {
+		gm.BroadcastLobbyStatus()
+	}
+}
+
+// Profile returns the fields of the profile message: the player's rating, the equipped title,
+// every earned one and their single player stats
+func (gm *Manager) Profile(playerID string) map[string]any {
+	data := map[string]any{"titles": []profile.EarnedTitle{}, "rating": gm.rating(playerID)}
+	if gm.profiles == nil {
+		return data
+	}
+	data["titles"] = gm.profiles.EarnedTitles(playerID)
+	data["single_player"] = gm.profiles.SinglePlayerStats(playerID)
+	if title, equipped := gm.profiles.EquippedTitle(playerID); equipped {
+		data["title"] = title
+	}
+	return data
+}
+
+// MultiplayerRecord counts a player's finished multiplayer rounds
+type MultiplayerRecord struct {
+	Rounds    int `json:"rounds"`
+	Wins      int `json:"wins"`
+	WinStreak int `json:"win_streak"` // Rounds won in a row, up to the latest
+}
+
+// Record returns the player's multiplayer record
+func (gm *Manager) Record(playerID string) MultiplayerRecord {
+	if gm.profiles == nil {
+		return MultiplayerRecord{}
+	}
+	rounds, wins := gm.profiles.RoundCounts(playerID)
+	return MultiplayerRecord{Rounds: rounds, Wins: wins, WinStreak: gm.profiles.WinStreak(playerID)}
+}
+
+// rating returns the player's rating, or the initial rating when profiles are unavailable
+func (gm *Manager) rating(playerID string) int {
+	if gm.profiles == nil {
+		return profile.InitialRating
+	}
+	return gm.profiles.Rating(playerID)
+}
+
+// titleName returns the name of the title the player shows next to their username, if any
+func (gm *Manager) titleName(playerID string) string {
+	if gm.profiles == nil {
+		return ""
+	}
+	title, _ := gm.profiles.EquippedTitle(playerID)
+	return title.Name
+}
+
+// playerTitles maps the players of a round to the names of their equipped titles
+func (gm *Manager) playerTitles(players []*models.Player) map[string]string {
+	titles := make(map[string]string)
+	for _, player := range players {
+		if name := gm.titleName(player.ID); name != "" {
+			titles[player.ID] = name
+		}
+	}
+	return titles
+}
@@ -0,0 +1,302 @@
+package game
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// tournamentHub tracks the players watching a tournament's live games
+type tournamentHub struct {
+	watchers  map[string]bool // Player IDs
+	followers map[string]bool // Watchers spectating the featured game when the hub last looked
+	featured  string          // Game ID of the featured match, empty when none is live
+	scores    string          // Encoding of the last scores sent, to skip unchanged ones
+}
+
+// hubGame is a live match game as shown in the tournament hub
+type hubGame struct {
+	MatchID    string     `json:"match_id"`
+	Round      int        `json:"round"`
+	GameID     string     `json:"game_id"`
+	Status     string     `json:"status"`
+	Players    []hubScore `json:"players"`
+	Spectators int        `json:"spectators"`
+}
+
+// hubScore is a match player's score in a live game
+type hubScore struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+}
+
+// WatchTournament subscribes the player to the live scores of a tournament's games
+// The player is sent the featured game whenever it changes, and is moved along to it when
+// they were spectating the featured game that just ended.
+func (gm *Manager) WatchTournament(player *models.Player, tournamentID string) {
+	gm.tournamentsMu.Lock()
+	if _, exists := gm.tournaments[tournamentID]; !exists {
+		gm.tournamentsMu.Unlock()
+		gm.sendTournamentError(player, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+		return
+	}
+	hub, exists := gm.tournamentHubs[tournamentID]
+	if !exists {
+		hub = &tournamentHub{watchers: make(map[string]bool), followers: make(map[string]bool)}
+		gm.tournamentHubs[tournamentID] = hub
+	}
+	hub.watchers[player.ID] = true
+	gm.tournamentsMu.Unlock()
+
+	tournamentEvents.Inc("watched")
+	games, featured := gm.hubGames(tournamentID)
+	gm.sendMessage(player, constants.MSG_TOURNAMENT_SCORES, map[string]any{
+		"tournament_id": tournamentID,
+		"games":         games,
+		"featured":      featured,
+	})
+	gm.startTournamentHub()
+}
+
+// UnwatchTournament stops sending the player a tournament's live scores
+func (gm *Manager) UnwatchTournament(player *models.Player, tournamentID string) {
+	gm.tournamentsMu.Lock()
+	defer gm.tournamentsMu.Unlock()
+	if hub, exists := gm.tournamentHubs[tournamentID]; exists {
+		delete(hub.watchers, player.ID)
+		delete(hub.followers, player.ID)
+		if len(hub.watchers) == 0 {
+			delete(gm.tournamentHubs, tournamentID)
+		}
+	}
+}
+
+// startTournamentHub starts the loop streaming live scores to tournament watchers, unless it
+// runs. The loop ends once nobody watches a tournament.
+func (gm *Manager) startTournamentHub() {
+	gm.tournamentsMu.Lock()
+	if gm.tournamentWatching {
+		gm.tournamentsMu.Unlock()
+		return
+	}
+	gm.tournamentWatching = true
+	gm.tournamentsMu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-gm.clock.After(gm.tournamentHubInterval()):
+			case <-gm.ctx.Done():
+				return
+			}
+			if !gm.streamTournamentHubs() {
+				return
+			}
+		}
+	}()
+}
+
+// tournamentHubInterval is how often watchers of a tournament get its live scores, read before
+// every round so tuning applies right away
+func (gm *Manager) tournamentHubInterval() time.Duration {
+	return time.Duration(gm.Config().Broadcast.TournamentScoresMs) * time.Millisecond
+}
+
+// streamTournamentHubs sends every watched tournament's live scores to its watchers when they
+// changed, and moves followers of a featured game that ended to the next one. Watchers who
+// disconnected are dropped, as are the hubs of finished tournaments once their last scores
+// are sent. Reports whether anybody still watches.
+func (gm *Manager) streamTournamentHubs() bool {
+	gm.tournamentsMu.Lock()
+	tournamentIDs := make([]string, 0, len(gm.tournamentHubs))
+	for tournamentID := range gm.tournamentHubs {
+		tournamentIDs = append(tournamentIDs, tournamentID)
+	}
+	gm.tournamentsMu.Unlock()
+
+	for _, tournamentID := range tournamentIDs {
+		gm.streamTournamentHub(tournamentID)
+	}
+
+	gm.tournamentsMu.Lock()
+	defer gm.tournamentsMu.Unlock()
+	if len(gm.tournamentHubs) == 0 {
+		gm.tournamentWatching = false
+		return false
+	}
+	return true
+}
+
+// streamTournamentHub streams one tournament's live scores
+func (gm *Manager) streamTournamentHub(tournamentID string) {
+	games, featured := gm.hubGames(tournamentID)
+	data := map[string]any{
+		"tournament_id": tournamentID,
+		"games":         games,
+		"featured":      featured,
+	}
+	encoded, _ := json.Marshal(data)
+
+	var spectators map[string]bool
+	if game, exists := gm.lookupGame(featured); exists {
+		game.Mutex.RLock()
+		spectators = make(map[string]bool, len(game.Spectators))
+		for spectatorID := range game.Spectators {
+			spectators[spectatorID] = true
+		}
+		game.Mutex.RUnlock()
+	}
+
+	gm.tournamentsMu.Lock()
+	hub, exists := gm.tournamentHubs[tournamentID]
+	var watcherIDs []string
+	if exists {
+		for watcherID := range hub.watchers {
+			watcherIDs = append(watcherIDs, watcherID)
+		}
+	}
+	gm.tournamentsMu.Unlock()
+	if !exists {
+		return
+	}
+	var watchers []*models.Player
+	var gone []string
+	for _, watcherID := range watcherIDs {
+		watcher, online := gm.registeredPlayer(watcherID)
+		if !online || !watcher.Connected() {
+			gone = append(gone, watcherID)
+			continue
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	gm.tournamentsMu.Lock()
+	for _, watcherID := range gone {
+		delete(hub.watchers, watcherID)
+		delete(hub.followers, watcherID)
+	}
+	var followers []*models.Player
+	featuredChanged := featured != hub.featured
+	if featuredChanged && featured != "" {
+		for _, watcher := range watchers {
+			if hub.followers[watcher.ID] {
+				followers = append(followers, watcher)
+			}
+		}
+	}
+	changed := string(encoded) != hub.scores
+	hub.scores, hub.featured = string(encoded), featured
+	// Followers of a featured game that ended are kept until the next one is live
+	if featured != "" {
+		hub.followers = spectators
+	}
+	t, kept := gm.tournaments[tournamentID]
+	if !kept || (t.Done() && len(games) == 0) || len(hub.watchers) == 0 {
+		delete(gm.tournamentHubs, tournamentID)
+	}
+	gm.tournamentsMu.Unlock()
+
+	if changed {
+		gm.broadcast(watchers, constants.MSG_TOURNAMENT_SCORES, data)
+	}
+	if !featuredChanged || featured == "" {
+		return
+	}
+	var matchID string
+	for _, game := range games {
+		if game.GameID == featured {
+			matchID = game.MatchID
+		}
+	}
+	playing := gm.playersInGame()
+	moving := make(map[string]bool, len(followers))
+	for _, follower := range followers {
+		// Followers who went on to play or to watch another live game stay where they are
+		moving[follower.ID] = !playing[follower.ID] && !gm.spectatingLiveGame(follower.ID)
+	}
+	for _, watcher := range watchers {
+		gm.sendMessage(watcher, constants.MSG_TOURNAMENT_FEATURED, map[string]any{
+			"tournament_id": tournamentID,
+			"match_id":      matchID,
+			"game_id":       featured,
+			"spectating":    moving[watcher.ID],
+		})
+		if moving[watcher.ID] {
+			tournamentEvents.Inc("featured_followed")
+			gm.AddSpectator(watcher, featured)
+		}
+	}
+}
+
+// hubGames returns the live games of a tournament's matches in bracket order, and the ID of
+// the featured one: the first game of the furthest round, empty when no game is live
+func (gm *Manager) hubGames(tournamentID string) ([]hubGame, string) {
+	gm.tournamentsMu.Lock()
+	var matches []tournament.Match
+	if t, exists := gm.tournaments[tournamentID]; exists {
+		for _, match := range t.Playable() {
+			if match.GameID != "" {
+				matches = append(matches, *match)
+			}
+		}
+	}
+	gm.tournamentsMu.Unlock()
+
+	slices.SortFunc(matches, func(a, b tournament.Match) int {
+		return cmp.Or(cmp.Compare(a.Round, b.Round), cmp.Compare(a.Index, b.Index))
+	})
+	games := make([]hubGame, 0, len(matches))
+	featured, featuredRound := "", 0
+	for _, match := range matches {
+		game, exists := gm.lookupGame(match.GameID)
+		if !exists {
+			continue
+		}
+		entry := hubGame{MatchID: match.ID, Round: match.Round, GameID: match.GameID}
+		game.Mutex.RLock()
+		entry.Status = game.State.Status
+		entry.Spectators = len(game.Spectators)
+		// Snakes exist once the game starts; the players are shown before that too
+		for _, player := range []*models.Player{game.Player1, game.Player2} {
+			if player == nil {
+				continue
+			}
+			score := hubScore{ID: player.ID, Username: player.Username}
+			for _, snake := range game.State.Snakes {
+				if snake.ID == player.ID {
+					score.Score = snake.Score
+				}
+			}
+			entry.Players = append(entry.Players, score)
+		}
+		game.Mutex.RUnlock()
+		if entry.Status == "finished" {
+			continue
+		}
+		if match.Round > featuredRound {
+			featured, featuredRound = match.GameID, match.Round
+		}
+		games = append(games, entry)
+	}
+	return games, featured
+}
+
+// spectatingLiveGame reports whether the player watches a game that hasn't finished
+func (gm *Manager) spectatingLiveGame(playerID string) bool {
+	for _, game := range gm.gamesOf(playerID) {
+		game.Mutex.RLock()
+		_, spectating := game.Spectators[playerID]
+		live := game.State.Status != "finished"
+		game.Mutex.RUnlock()
+		if spectating && live {
+			return true
+		}
+	}
+	return false
+}
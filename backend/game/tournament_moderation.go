@@ -0,0 +1,175 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// MaxCheckInExtension is the most a single extension moves a scheduled start back
+const MaxCheckInExtension = 60 * time.Minute
+
+// errNotModerating refuses moderator actions to players who don't run the tournament
+var errNotModerating = errors.New("only the organizer or a moderator can do this")
+
+// SetTournamentStaff appoints a player to a tournament role, a moderator or a caster, or
+// dismisses them with remove; only the organizer may. The player must be online to be
+// appointed.
+func (gm *Manager) SetTournamentStaff(player *models.Player, tournamentID, role, username string, remove bool) {
+	username = strings.TrimSpace(username)
+	if remove {
+		gm.changeTournament(player, tournamentID, role+"_dismissed", func(t *tournament.Tournament) error {
+			if t.OrganizerID != player.ID {
+				return errNotOrganizer
+			}
+			roster := t.Moderators
+			if role == tournament.RoleCaster {
+				roster = t.Casters
+			}
+			for _, staff := range roster {
+				if names.Equal(staff.Username, username) {
+					return t.Dismiss(role, staff.ID)
+				}
+			}
+			return tournament.ErrNotAppointed
+		})
+		return
+	}
+	target := gm.FindPlayerByUsername(username)
+	if target == nil || !target.Connected() {
+		gm.sendTournamentError(player, "PLAYER_NOT_FOUND", "No online player named "+username)
+		return
+	}
+	gm.changeTournament(player, tournamentID, role+"_appointed", func(t *tournament.Tournament) error {
+		if t.OrganizerID != player.ID {
+			return errNotOrganizer
+		}
+		return t.Appoint(role, tournament.Staff{ID: target.ID, Username: target.Username})
+	})
+}
+
+// PauseTournament pauses or resumes a running tournament; only its organizer and
+// moderators may. Games already playing finish while it is paused.
+func (gm *Manager) PauseTournament(player *models.Player, tournamentID string, paused bool) {
+	event := "paused"
+	if !paused {
+		event = "resumed"
+	}
+	changed := gm.changeTournament(player, tournamentID, event, func(t *tournament.Tournament) error {
+		if !t.Moderates(player.ID) {
+			return errNotModerating
+		}
+		if paused {
+			return t.Pause(gm.clock.Now().UTC())
+		}
+		return t.Resume(gm.clock.Now().UTC())
+	})
+	if changed {
+		slog.Info("Tournament pause changed", "tournament_id", tournamentID, "paused", paused, "by", player.Username)
+	}
+}
+
+// RestartTournamentMatch has a disputed match played again from the seed of its latest
+// game; only the tournament's organizer and moderators may. A game still playing for the
+// match ends without a result.
+func (gm *Manager) RestartTournamentMatch(player *models.Player, tournamentID, matchID string) {
+	var gameID string
+	restarted := gm.changeTournament(player, tournamentID, "match_restarted", func(t *tournament.Tournament) error {
+		if !t.Moderates(player.ID) {
+			return errNotModerating
+		}
+		if match, exists := t.Match(matchID); exists {
+			gameID = match.GameID
+		}
+		if err := t.RestartMatch(matchID); err != nil {
+			return err
+		}
+		delete(gm.tournamentGames, gameID)
+		return nil
+	})
+	if !restarted {
+		return
+	}
+	slog.Info("Tournament match restarted", "tournament_id", tournamentID, "match_id", matchID, "by", player.Username)
+	if gameID != "" {
+		gm.endMatchGames([]string{gameID}, "MATCH_RESTARTED", "The tournament match was restarted by a moderator")
+	}
+	gm.startTournamentSweep()
+}
+
+// DisqualifyFromTournament removes a player from a tournament, deciding every match they
+// have left against them; only the tournament's organizer and moderators may
+func (gm *Manager) DisqualifyFromTournament(player *models.Player, tournamentID, playerID string) {
+	var gameIDs []string
+	disqualified := gm.changeTournament(player, tournamentID, "disqualified", func(t *tournament.Tournament) error {
+		if !t.Moderates(player.ID) {
+			return errNotModerating
+		}
+		var playing []string
+		for _, match := range t.Playable() {
+			if match.Has(playerID) && match.GameID != "" {
+				playing = append(playing, match.GameID)
+			}
+		}
+		if err := t.Disqualify(playerID, gm.clock.Now().UTC()); err != nil {
+			return err
+		}
+		for _, gameID := range playing {
+			delete(gm.tournamentGames, gameID)
+		}
+		gameIDs = playing
+		return nil
+	})
+	if !disqualified {
+		return
+	}
+	slog.Info("Tournament player disqualified", "tournament_id", tournamentID, "player_id", playerID, "by", player.Username)
+	gm.endMatchGames(gameIDs, "PLAYER_DISQUALIFIED", "The tournament match was decided because a player was disqualified")
+}
+
+// ExtendTournamentCheckIn moves the start of a scheduled tournament back by up to an hour,
+// keeping check-in open for longer; only its organizer and moderators may
+func (gm *Manager) ExtendTournamentCheckIn(player *models.Player, tournamentID string, minutes int) {
+	extension := time.Duration(minutes) * time.Minute
+	if extension < time.Minute || extension > MaxCheckInExtension {
+		gm.sendTournamentError(player, "INVALID_EXTENSION", "Check-in is extended by 1 to 60 minutes")
+		return
+	}
+	gm.changeTournament(player, tournamentID, "check_in_extended", func(t *tournament.Tournament) error {
+		if !t.Moderates(player.ID) {
+			return errNotModerating
+		}
+		return t.ExtendCheckIn(extension)
+	})
+}
+
+// endMatchGames removes the games of matches a moderator decided or restarted, telling
+// their players why with code and message
+func (gm *Manager) endMatchGames(gameIDs []string, code, message string) {
+	for _, gameID := range gameIDs {
+		game, exists := gm.lookupGame(gameID)
+		if !exists {
+			continue
+		}
+		game.Mutex.RLock()
+		players := []*models.Player{game.Player1, game.Player2}
+		game.Mutex.RUnlock()
+		gm.deleteGame(gameID)
+		for _, player := range players {
+			gm.sendMessage(player, constants.MSG_GAME_REQUEST_CANCEL, map[string]any{
+				"game_id": gameID,
+				"code":    code,
+				"message": message,
+			})
+		}
+	}
+	if len(gameIDs) > 0 {
+		gm.BroadcastGamesList()
+	}
+}
@@ -0,0 +1,820 @@
+package game
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/rng"
+	"github.com/bariiss/snake/backend/storage"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// Tournament limits and timings
+const (
+	DefaultTournamentPlayers = 16
+	MaxTournamentNameLength  = 40
+	MaxFinishedTournaments   = 20                  // Finished tournaments kept for players to look up
+	MaxTournamentSchedule    = 30 * 24 * time.Hour // How far ahead a tournament can be scheduled
+
+	tournamentNoShowTimeout = 60 * time.Second // How long match players have to be free and get ready
+	tournamentSweepInterval = 2 * time.Second  // How often matches waiting for a game are checked
+)
+
+// ErrTournamentNotFound is returned when looking up a tournament the instance doesn't hold
+var ErrTournamentNotFound = errors.New("tournament not found")
+
+var tournamentEvents = metrics.NewCounterVec("snake_tournament_events_total", "Tournament changes, by event", "event")
+
+// tournamentErrorCodes maps refused tournament changes to the codes sent to players
+var tournamentErrorCodes = map[error]string{
+	tournament.ErrNotRegistering:    "REGISTRATION_CLOSED",
+	tournament.ErrNotCheckingIn:     "CHECK_IN_CLOSED",
+	tournament.ErrFull:              "TOURNAMENT_FULL",
+	tournament.ErrAlreadyRegistered: "ALREADY_REGISTERED",
+	tournament.ErrNotRegistered:     "NOT_REGISTERED",
+	tournament.ErrTooFewPlayers:     "TOO_FEW_PLAYERS",
+	tournament.ErrMatchNotFound:     "MATCH_NOT_FOUND",
+	tournament.ErrNotRunning:        "TOURNAMENT_NOT_RUNNING",
+	tournament.ErrPaused:            "TOURNAMENT_PAUSED",
+	tournament.ErrNotPaused:         "TOURNAMENT_NOT_PAUSED",
+	tournament.ErrNotScheduled:      "NOT_SCHEDULED",
+	tournament.ErrNotRestartable:    "MATCH_NOT_RESTARTABLE",
+	tournament.ErrResultUsed:        "MATCH_NOT_RESTARTABLE",
+	tournament.ErrDisqualified:      "ALREADY_DISQUALIFIED",
+	tournament.ErrUnknownRole:       "INVALID_ROLE",
+	tournament.ErrAlreadyAppointed:  "ALREADY_APPOINTED",
+	tournament.ErrNotAppointed:      "NOT_APPOINTED",
+	errNotModerating:                "NOT_ORGANIZER",
+}
+
+// tournamentMatch locates the match a game is played for
+type tournamentMatch struct {
+	tournamentID string
+	matchID      string
+}
+
+// SetTournaments restores the persisted tournaments and persists changes to the store from then on
+// It also opens the archive of finished tournaments' results served by the public API.
+// Call before the server starts accepting connections; without it tournaments are lost on restart.
+func (gm *Manager) SetTournaments(store storage.Store) error {
+	loaded, err := tournament.Load(store)
+	if err != nil {
+		return err
+	}
+	results, err := tournament.OpenArchive(store)
+	if err != nil {
+		return err
+	}
+	gm.tournamentsMu.Lock()
+	gm.tournamentStore = store
+	gm.tournamentResults = results
+	var finished []tournament.Tournament
+	for _, t := range loaded {
+		// The games of running matches ended with the previous process: matches are played
+		// again, and their players get a new no-show window
+		for _, match := range t.Playable() {
+			match.GameID = ""
+			match.Deadline = time.Time{}
+		}
+		gm.tournaments[t.ID] = t
+		if t.Status == tournament.StatusFinished {
+			finished = append(finished, t.Clone())
+		}
+	}
+	gm.tournamentsMu.Unlock()
+
+	// Tournaments that finished before results were archived are archived now
+	slices.SortFunc(finished, func(a, b tournament.Tournament) int { return a.FinishedAt.Compare(b.FinishedAt) })
+	for _, t := range finished {
+		if err := results.Record(t); err != nil {
+			slog.Error("Failed to archive tournament result", "tournament_id", t.ID, "error", err)
+		}
+	}
+	gm.pruneTournaments()
+
+	slog.Info("Tournaments restored", "count", len(loaded))
+	gm.startTournamentSweep()
+	return nil
+}
+
+// TournamentOptions describes a tournament to create
+type TournamentOptions struct {
+	Name        string
+	Format      string    // elimination, league or swiss; empty for elimination
+	Rules       string    // Rules preset of every match; empty for the default
+	MaxPlayers  int       // 0 uses DefaultTournamentPlayers, capped for leagues
+	StartsAt    time.Time // Scheduled start; zero for the organizer to start it
+	Repeat      string    // daily or weekly for a recurring scheduled tournament
+	WindowHours int       // How long a league runs; 0 uses tournament.DefaultLeagueHours
+
+	RoundCheckIn int // Minutes players have to check in to each match; 0 creates match games right away
+}
+
+// CreateTournament opens a tournament organized by the player
+// A tournament with a StartsAt starts on its own after check-in, and one that repeats daily
+// or weekly schedules its next occurrence when it starts.
+func (gm *Manager) CreateTournament(player *models.Player, opts TournamentOptions) {
+	name := strings.TrimSpace(opts.Name)
+	if length := utf8.RuneCountInString(name); length < 3 || length > MaxTournamentNameLength {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "A tournament name is 3 to 40 characters")
+		return
+	}
+	format := cmp.Or(opts.Format, tournament.FormatElimination)
+	if !slices.Contains([]string{tournament.FormatElimination, tournament.FormatLeague, tournament.FormatSwiss}, format) {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "A tournament is an elimination bracket, a league or a Swiss tournament")
+		return
+	}
+	maxPlayers, limit := cmp.Or(opts.MaxPlayers, DefaultTournamentPlayers), tournament.MaxPlayers
+	if format == tournament.FormatLeague {
+		limit = tournament.MaxLeaguePlayers
+	}
+	if maxPlayers < tournament.MinPlayers || maxPlayers > limit {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", fmt.Sprintf("A %s holds 2 to %d players", format, limit))
+		return
+	}
+	if opts.WindowHours != 0 && (format != tournament.FormatLeague || opts.WindowHours < 1 || opts.WindowHours > tournament.MaxLeagueHours) {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "A league runs for 1 to 168 hours")
+		return
+	}
+	if opts.RoundCheckIn != 0 && (format == tournament.FormatLeague || opts.RoundCheckIn < 1 || opts.RoundCheckIn > tournament.MaxRoundCheckIn) {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "Round check-in is 1 to 15 minutes, for brackets and Swiss tournaments")
+		return
+	}
+	now := gm.clock.Now().UTC()
+	startsAt := opts.StartsAt
+	if !startsAt.IsZero() && (!startsAt.After(now) || startsAt.Sub(now) > MaxTournamentSchedule) {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "A tournament starts within the next 30 days")
+		return
+	}
+	if _, repeats := tournament.RepeatInterval(opts.Repeat); opts.Repeat != "" && (!repeats || startsAt.IsZero()) {
+		gm.sendTournamentError(player, "INVALID_TOURNAMENT", "Only a scheduled tournament repeats, daily or weekly")
+		return
+	}
+	rules, valid := ResolveRules(opts.Rules)
+	if !valid {
+		gm.sendInvalidRules(player, opts.Rules)
+		return
+	}
+
+	t := tournament.New(uuid.New().String(), name, rules.Name, player.ID, player.Username, maxPlayers, now)
+	t.Format, t.WindowHours, t.RoundCheckIn = format, opts.WindowHours, opts.RoundCheckIn
+	t.StartsAt, t.Repeat = startsAt.UTC(), opts.Repeat
+	gm.tournamentsMu.Lock()
+	gm.tournaments[t.ID] = t
+	snapshot := revise(t)
+	gm.tournamentsMu.Unlock()
+
+	tournamentEvents.Inc("created")
+	gm.publishTournament(snapshot)
+	if t.Scheduled() {
+		gm.startTournamentSweep()
+	}
+}
+
+// RegisterForTournament enters the player into a tournament that is open for registration
+// The player is seeded by the rating they register with.
+func (gm *Manager) RegisterForTournament(player *models.Player, tournamentID string) {
+	rating := gm.rating(player.ID)
+	gm.changeTournament(player, tournamentID, "registered", func(t *tournament.Tournament) error {
+		// Registration always looks closed to a restricted player
+		if gm.restricted(player.ID) {
+			return tournament.ErrNotRegistering
+		}
+		return t.Register(tournament.Entrant{ID: player.ID, Username: player.Username, Rating: rating})
+	})
+}
+
+// UnregisterFromTournament withdraws the player from a tournament before it starts
+func (gm *Manager) UnregisterFromTournament(player *models.Player, tournamentID string) {
+	gm.changeTournament(player, tournamentID, "unregistered", func(t *tournament.Tournament) error {
+		return t.Unregister(player.ID)
+	})
+}
+
+// CheckInTournament confirms the player will play in a tournament whose check-in is open,
+// or in their next match of a running tournament with round check-in
+func (gm *Manager) CheckInTournament(player *models.Player, tournamentID string) {
+	gm.changeTournament(player, tournamentID, "checked_in", func(t *tournament.Tournament) error {
+		return t.CheckIn(player.ID)
+	})
+}
+
+// StartTournament closes registration and draws the bracket; only the organizer may
+// A scheduled tournament can be started early. The games of the first round are created
+// right away.
+func (gm *Manager) StartTournament(player *models.Player, tournamentID string) {
+	var scheduled []tournament.Tournament
+	started := gm.changeTournament(player, tournamentID, "started", func(t *tournament.Tournament) error {
+		if t.OrganizerID != player.ID {
+			return errNotOrganizer
+		}
+		if err := t.Start(gm.clock.Now().UTC()); err != nil {
+			return err
+		}
+		gm.notifyTournamentStarted(t)
+		if next := gm.scheduleNextLocked(t); next != nil {
+			scheduled = append(scheduled, next.Clone())
+		}
+		return nil
+	})
+	if !started {
+		return
+	}
+	for _, snapshot := range scheduled {
+		gm.publishTournament(snapshot)
+	}
+	gm.startTournamentSweep()
+}
+
+// scheduleNextLocked adds the next occurrence of a recurring tournament that just left
+// registration, returning it; nil if the tournament doesn't repeat
+func (gm *Manager) scheduleNextLocked(t *tournament.Tournament) *tournament.Tournament {
+	next := t.Next(uuid.New().String(), gm.clock.Now().UTC())
+	if next == nil {
+		return nil
+	}
+	next.Revision = 1
+	gm.tournaments[next.ID] = next
+	tournamentEvents.Inc("scheduled")
+	slog.Info("Next tournament scheduled", "tournament_id", next.ID, "series", next.Series, "starts_at", next.StartsAt)
+	return next
+}
+
+// errNotOrganizer refuses organizer actions to other players
+var errNotOrganizer = errors.New("only the organizer can do this")
+
+// changeTournament applies a change to a tournament and broadcasts the result, answering
+// the player when it is refused; a change that finished the tournament crowns its champion.
+// Reports whether the change was made.
+func (gm *Manager) changeTournament(player *models.Player, tournamentID, event string, change func(*tournament.Tournament) error) bool {
+	gm.tournamentsMu.Lock()
+	t, exists := gm.tournaments[tournamentID]
+	if !exists {
+		gm.tournamentsMu.Unlock()
+		gm.sendTournamentError(player, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+		return false
+	}
+	finished := t.Status == tournament.StatusFinished
+	err := change(t)
+	var snapshot tournament.Tournament
+	if err == nil {
+		snapshot = revise(t)
+	}
+	gm.tournamentsMu.Unlock()
+
+	if errors.Is(err, errNotOrganizer) {
+		gm.sendTournamentError(player, "NOT_ORGANIZER", "Only the organizer can do this")
+		return false
+	}
+	if err != nil {
+		message := err.Error()
+		gm.sendTournamentError(player, tournamentErrorCodes[err], strings.ToUpper(message[:1])+message[1:])
+		return false
+	}
+	tournamentEvents.Inc(event)
+	if !finished && snapshot.Status == tournament.StatusFinished {
+		gm.tournamentChanged(snapshot)
+		return true
+	}
+	gm.publishTournament(snapshot)
+	return true
+}
+
+// SendTournaments sends the player every tournament, newest first
+func (gm *Manager) SendTournaments(player *models.Player) {
+	gm.tournamentsMu.Lock()
+	tournaments := make([]tournament.Tournament, 0, len(gm.tournaments))
+	for _, t := range gm.tournaments {
+		tournaments = append(tournaments, t.Clone())
+	}
+	gm.tournamentsMu.Unlock()
+
+	slices.SortFunc(tournaments, func(a, b tournament.Tournament) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	gm.sendMessage(player, constants.MSG_TOURNAMENTS_LIST, map[string]any{
+		"tournaments": tournaments,
+	})
+}
+
+// SendLeagueStandings sends the player the standings of a league or Swiss tournament
+func (gm *Manager) SendLeagueStandings(player *models.Player, tournamentID string) {
+	gm.tournamentsMu.Lock()
+	t, exists := gm.tournaments[tournamentID]
+	var ranked bool
+	var standings []tournament.Standing
+	if exists {
+		ranked, standings = t.HasStandings(), t.Standings()
+	}
+	gm.tournamentsMu.Unlock()
+
+	switch {
+	case !exists:
+		gm.sendTournamentError(player, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+	case !ranked:
+		gm.sendTournamentError(player, "NOT_A_LEAGUE", "Standings are kept for leagues and Swiss tournaments only")
+	default:
+		gm.sendMessage(player, constants.MSG_LEAGUE_STANDINGS, map[string]any{
+			"tournament_id": tournamentID,
+			"standings":     standings,
+		})
+	}
+}
+
+// SendTournament sends the player the current bracket of a tournament
+func (gm *Manager) SendTournament(player *models.Player, tournamentID string) {
+	gm.tournamentsMu.Lock()
+	t, exists := gm.tournaments[tournamentID]
+	var snapshot tournament.Tournament
+	if exists {
+		snapshot = t.Clone()
+	}
+	gm.tournamentsMu.Unlock()
+
+	if !exists {
+		gm.sendTournamentError(player, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+		return
+	}
+	gm.sendMessage(player, constants.MSG_TOURNAMENT_UPDATE, map[string]any{"tournament": snapshot})
+}
+
+// TournamentResult returns the archived result of a finished tournament
+func (gm *Manager) TournamentResult(tournamentID string) (tournament.Result, bool) {
+	if gm.tournamentResults == nil {
+		return tournament.Result{}, false
+	}
+	return gm.tournamentResults.Get(tournamentID)
+}
+
+// TournamentResults lists up to limit archived results, most recently finished first,
+// skipping the first offset, with how many are archived
+func (gm *Manager) TournamentResults(limit, offset int) ([]tournament.ResultSummary, int) {
+	if gm.tournamentResults == nil {
+		return []tournament.ResultSummary{}, 0
+	}
+	return gm.tournamentResults.List(limit, offset)
+}
+
+// recordTournamentMatch decides the match a finished game was played for, keeping the game's
+// scores with the result
+// A player who left forfeits; a tie is a draw in a league or Swiss tournament and is replayed
+// in a new game in an elimination bracket.
+func (gm *Manager) recordTournamentMatch(game *models.Game, player1, player2 *models.Player, winner string, stats MatchStats) {
+	if game.Tournament == "" || player2 == nil {
+		return
+	}
+	result := tournament.ResultPlayed
+	if winner == "disconnect" {
+		// The player who left was detached before the game ended
+		result = tournament.ResultForfeit
+		winner = player1.ID
+		if !player1.Connected() && player2.Connected() {
+			winner = player2.ID
+		}
+	}
+
+	gm.tournamentsMu.Lock()
+	ref, exists := gm.tournamentGames[game.ID]
+	if !exists {
+		gm.tournamentsMu.Unlock()
+		return
+	}
+	delete(gm.tournamentGames, game.ID)
+	t := gm.tournaments[ref.tournamentID]
+	match, _ := t.Match(ref.matchID)
+	now := gm.clock.Now().UTC()
+	var err error
+	switch {
+	case winner == "tie" && t.HasStandings():
+		err = t.Draw(ref.matchID, now)
+	case winner == "tie":
+		match.GameID = ""
+		match.Deadline = now.Add(tournamentNoShowTimeout)
+	default:
+		err = t.Decide(ref.matchID, winner, result, now)
+	}
+	if err != nil {
+		slog.Warn("Ignoring tournament result", "tournament_id", t.ID, "match_id", ref.matchID, "error", err)
+	} else if match.Decided() {
+		match.Scores = make(map[string]int, len(stats.Players))
+		for _, player := range stats.Players {
+			match.Scores[player.ID] = player.Score
+		}
+	}
+	snapshot := revise(t)
+	gm.tournamentsMu.Unlock()
+
+	slog.Info("Tournament match ended", "tournament_id", snapshot.ID, "match_id", ref.matchID, "game_id", game.ID, "winner", winner)
+	gm.tournamentChanged(snapshot)
+}
+
+// tournamentChanged publishes a tournament and crowns its champion once it finished
+func (gm *Manager) tournamentChanged(snapshot tournament.Tournament) {
+	gm.publishTournament(snapshot)
+	if snapshot.Status != tournament.StatusFinished {
+		return
+	}
+	tournamentEvents.Inc("finished")
+	if gm.tournamentResults != nil {
+		if err := gm.tournamentResults.Record(snapshot); err != nil {
+			slog.Error("Failed to archive tournament result", "tournament_id", snapshot.ID, "error", err)
+		}
+	}
+	// A league whose matches all expired has no champion
+	if snapshot.Champion != nil {
+		slog.Info("Tournament finished", "tournament_id", snapshot.ID, "champion", snapshot.Champion.Username, "champion_seed", snapshot.Champion.Seed)
+		gm.awardTitle(snapshot.Champion.ID, "tournament_champion")
+		gm.recordCircuit(snapshot)
+	}
+	for _, entrant := range snapshot.Entrants {
+		// Entrants who finished above their seed beat the ratings' expectations
+		if entrant.Finish != 0 && entrant.Finish < entrant.Seed {
+			tournamentEvents.Inc("outperformed_seed")
+		}
+	}
+	gm.pruneTournaments()
+}
+
+// pruneTournaments forgets the oldest finished or canceled tournaments past MaxFinishedTournaments
+func (gm *Manager) pruneTournaments() {
+	gm.tournamentsMu.Lock()
+	defer gm.tournamentsMu.Unlock()
+	var finished []*tournament.Tournament
+	for _, t := range gm.tournaments {
+		if t.Done() {
+			finished = append(finished, t)
+		}
+	}
+	slices.SortFunc(finished, func(a, b *tournament.Tournament) int { return b.FinishedAt.Compare(a.FinishedAt) })
+	for _, t := range finished[min(len(finished), MaxFinishedTournaments):] {
+		delete(gm.tournaments, t.ID)
+	}
+}
+
+// startTournamentSweep starts the loop driving scheduled tournaments and creating and timing
+// out match games, unless it runs. The loop ends once no tournament is scheduled or running.
+func (gm *Manager) startTournamentSweep() {
+	gm.tournamentsMu.Lock()
+	if gm.tournamentSweeping {
+		gm.tournamentsMu.Unlock()
+		return
+	}
+	gm.tournamentSweeping = true
+	gm.tournamentsMu.Unlock()
+
+	go func() {
+		ticker := gm.clock.NewTicker(tournamentSweepInterval)
+		defer ticker.Stop()
+		for active := gm.sweepTournaments(); active; active = gm.sweepTournaments() {
+			select {
+			case <-ticker.C():
+			case <-gm.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// pendingMatch is a playable match as seen by a sweep
+type pendingMatch struct {
+	tournamentMatch
+	league   bool
+	rules    string
+	gameID   string
+	player1  string
+	player2  string
+	seeds    [2]int // Seeds of player1 and player2
+	deadline time.Time
+	replay   bool    // The game reuses seed: the match was restarted
+	seed     int64   // Seed of the match's latest game
+	checkIn  bool    // The players check in to the match before its game is created
+	present  [2]bool // Whether player1 and player2 checked in
+}
+
+// favorite returns the higher-seeded player, who advances when neither shows up
+func (m pendingMatch) favorite() string {
+	if m.seeds[1] < m.seeds[0] {
+		return m.player2
+	}
+	return m.player1
+}
+
+// sweepTournaments opens check-in and starts scheduled tournaments when their time comes,
+// creates the games of playable matches whose players are free and forfeits players who
+// don't show up in time. Reports whether a tournament is scheduled or running.
+func (gm *Manager) sweepTournaments() bool {
+	now := gm.clock.Now().UTC()
+	var pending []pendingMatch
+	var changes, closed []tournament.Tournament
+
+	gm.tournamentsMu.Lock()
+	active := false
+	for _, t := range gm.tournaments {
+		if t.Scheduled() {
+			active = true
+			changes = append(changes, gm.advanceScheduleLocked(t, now)...)
+		}
+		if t.Status != tournament.StatusRunning {
+			continue
+		}
+		active = true
+		if t.Paused {
+			continue
+		}
+		if t.League() && !now.Before(t.EndsAt) {
+			t.Close(now)
+			tournamentEvents.Inc("league_closed")
+			closed = append(closed, revise(t))
+			continue
+		}
+		changed := false
+		for _, match := range t.Playable() {
+			// League matches are played whenever both players are free; only a created game
+			// has a deadline for getting ready. With round check-in the players have the
+			// whole check-in window to show up.
+			if match.Deadline.IsZero() && !t.League() {
+				match.Deadline = now.Add(tournamentNoShowTimeout)
+				if t.RoundCheckIn > 0 && match.GameID == "" {
+					match.Deadline = now.Add(time.Duration(t.RoundCheckIn) * time.Minute)
+					tournamentEvents.Inc("round_check_in_opened")
+				}
+				changed = true
+			}
+			pending = append(pending, pendingMatch{
+				tournamentMatch: tournamentMatch{tournamentID: t.ID, matchID: match.ID},
+				league:          t.League(),
+				rules:           t.Rules,
+				gameID:          match.GameID,
+				player1:         match.Player1.ID,
+				player2:         match.Player2.ID,
+				seeds:           [2]int{match.Player1.Seed, match.Player2.Seed},
+				deadline:        match.Deadline,
+				replay:          match.Replay,
+				seed:            match.Seed,
+				checkIn:         t.RoundCheckIn > 0,
+				present:         [2]bool{match.CheckedIn(match.Player1.ID), match.CheckedIn(match.Player2.ID)},
+			})
+		}
+		if changed {
+			changes = append(changes, revise(t))
+		}
+	}
+	if !active {
+		gm.tournamentSweeping = false
+	}
+	gm.tournamentsMu.Unlock()
+
+	for _, snapshot := range changes {
+		gm.publishTournament(snapshot)
+	}
+	for _, snapshot := range closed {
+		gm.tournamentChanged(snapshot)
+	}
+	if len(pending) > 0 {
+		playing := gm.playersInGame()
+		for _, match := range pending {
+			gm.sweepMatch(match, playing, now)
+		}
+	}
+	return active
+}
+
+// advanceScheduleLocked opens check-in and starts a scheduled tournament once its time comes,
+// canceling it when too few players checked in. Returns the tournaments it changed.
+func (gm *Manager) advanceScheduleLocked(t *tournament.Tournament, now time.Time) []tournament.Tournament {
+	changed := false
+	if t.Status == tournament.StatusRegistering && !now.Before(t.StartsAt.Add(-tournament.CheckInWindow)) {
+		t.OpenCheckIn()
+		tournamentEvents.Inc("check_in_opened")
+		changed = true
+	}
+	if now.Before(t.StartsAt) {
+		if changed {
+			return []tournament.Tournament{revise(t)}
+		}
+		return nil
+	}
+
+	if err := t.Start(now); err != nil {
+		t.Cancel(now)
+		tournamentEvents.Inc("canceled")
+		slog.Info("Tournament canceled", "tournament_id", t.ID, "error", err)
+	} else {
+		tournamentEvents.Inc("started")
+		slog.Info("Tournament started", "tournament_id", t.ID, "entrants", len(t.Entrants))
+		gm.notifyTournamentStarted(t)
+	}
+	changes := []tournament.Tournament{revise(t)}
+	if next := gm.scheduleNextLocked(t); next != nil {
+		changes = append(changes, next.Clone())
+	}
+	return changes
+}
+
+// sweepMatch moves one playable match along: waits for its game, creates it once both
+// players are free and checked in when they must, or forfeits those who didn't show up by
+// the deadline
+func (gm *Manager) sweepMatch(match pendingMatch, playing map[string]bool, now time.Time) {
+	if match.gameID != "" {
+		game, exists := gm.lookupGame(match.gameID)
+		if exists {
+			gm.checkMatchGame(match, game, now)
+			return
+		}
+		// The game was removed before it started, e.g. a player left the ready screen. A
+		// league match waits out the rest of the ready window before its next game.
+		if _, cleared := gm.updateMatch(match, func(m *tournament.Match) {
+			m.GameID = ""
+			delete(gm.tournamentGames, match.gameID)
+		}); !cleared {
+			return
+		}
+		match.gameID = ""
+	}
+	if match.league && now.Before(match.deadline) {
+		return
+	}
+
+	player1, available1 := gm.availableForMatch(match.player1, playing)
+	player2, available2 := gm.availableForMatch(match.player2, playing)
+	if match.checkIn {
+		available1, available2 = available1 && match.present[0], available2 && match.present[1]
+	}
+	if available1 && available2 {
+		// League players have several matches to play: they play one at a time
+		if gm.createMatchGame(match, player1, player2) {
+			playing[player1.ID], playing[player2.ID] = true, true
+		}
+		return
+	}
+	if match.league || now.Before(match.deadline) {
+		return
+	}
+	winner := match.favorite()
+	if available1 != available2 {
+		winner = match.player1
+		if available2 {
+			winner = match.player2
+		}
+	}
+	gm.forfeitMatch(match, winner, now)
+}
+
+// checkMatchGame forfeits the players of a match game who didn't get ready by the deadline
+func (gm *Manager) checkMatchGame(match pendingMatch, game *models.Game, now time.Time) {
+	game.Mutex.RLock()
+	waiting := game.State.Status == "waiting" && game.Countdown == nil
+	ready1, ready2 := game.Player1.Ready, game.Player2 != nil && game.Player2.Ready
+	players := []*models.Player{game.Player1, game.Player2}
+	game.Mutex.RUnlock()
+	if !waiting || now.Before(match.deadline) {
+		return
+	}
+
+	winner := match.favorite()
+	if ready1 != ready2 {
+		winner = match.player1
+		if ready2 {
+			winner = match.player2
+		}
+	}
+	gm.deleteGame(game.ID)
+	for _, player := range players {
+		gm.sendMessage(player, constants.MSG_GAME_REQUEST_CANCEL, map[string]any{
+			"game_id": game.ID,
+			"code":    "MATCH_NO_SHOW",
+			"message": "The tournament match was decided because a player didn't get ready in time",
+		})
+	}
+	gm.forfeitMatch(match, winner, now)
+}
+
+// availableForMatch returns a match player if they are connected and not playing
+func (gm *Manager) availableForMatch(playerID string, playing map[string]bool) (*models.Player, bool) {
+	player, exists := gm.registeredPlayer(playerID)
+	return player, exists && player.Connected() && !playing[playerID]
+}
+
+// createMatchGame creates the game of a match and sends both players to its ready screen,
+// reporting whether it did
+func (gm *Manager) createMatchGame(match pendingMatch, player1, player2 *models.Player) bool {
+	rules, _ := ResolveRules(match.rules)
+	game := gm.newMultiplayerGame(player1, player2, rules)
+	game.Tournament = match.tournamentID
+	if match.replay {
+		game.Seed, game.Rand = match.seed, rng.New(match.seed)
+	}
+
+	gm.Mutex.Lock()
+	if limit := gm.gameCapacityExceededLocked(player1.ID, player2.ID); limit != "" {
+		gm.Mutex.Unlock()
+		slog.Warn("Tournament match waiting for capacity", "tournament_id", match.tournamentID, "match_id", match.matchID, "limit", limit)
+		return false
+	}
+	gm.addGameLocked(game)
+	gm.Mutex.Unlock()
+
+	snapshot, assigned := gm.updateMatch(match, func(m *tournament.Match) {
+		m.GameID, m.Seed, m.Replay = game.ID, game.Seed, false
+		// Players who waited for the check-in get the full window to get ready
+		if match.league || match.checkIn {
+			m.Deadline = gm.clock.Now().UTC().Add(tournamentNoShowTimeout)
+		}
+		gm.tournamentGames[game.ID] = match.tournamentMatch
+	})
+	if !assigned {
+		gm.deleteGame(game.ID)
+		return false
+	}
+
+	tournamentEvents.Inc("match_created")
+	gm.emitGameCreated(game)
+	game.Mutex.RLock()
+	gameState := game.State.Clone()
+	game.Mutex.RUnlock()
+	// Tournament games skip the request: both players are sent straight to the ready screen
+	gm.broadcast([]*models.Player{player1, player2}, constants.MSG_GAME_ACCEPT, map[string]any{
+		"game_id":    game.ID,
+		"data":       gameState,
+		"rules":      rules,
+		"tournament": match.tournamentID,
+		"match_id":   match.matchID,
+	})
+	gm.publishTournament(snapshot)
+	gm.BroadcastGamesList()
+	return true
+}
+
+// forfeitMatch decides a match the sweep found a no-show for
+func (gm *Manager) forfeitMatch(match pendingMatch, winner string, now time.Time) {
+	gm.tournamentsMu.Lock()
+	t := gm.tournaments[match.tournamentID]
+	if m, exists := t.Match(match.matchID); exists && m.GameID != match.gameID {
+		// A game was created or ended for the match since the sweep looked at it
+		gm.tournamentsMu.Unlock()
+		return
+	}
+	delete(gm.tournamentGames, match.gameID)
+	if err := t.Decide(match.matchID, winner, tournament.ResultNoShow, now); err != nil {
+		gm.tournamentsMu.Unlock()
+		return
+	}
+	snapshot := revise(t)
+	gm.tournamentsMu.Unlock()
+
+	tournamentEvents.Inc("no_show")
+	slog.Info("Tournament match forfeited", "tournament_id", match.tournamentID, "match_id", match.matchID, "winner", winner)
+	gm.tournamentChanged(snapshot)
+}
+
+// updateMatch changes a match the sweep looked at, unless it was decided or given another
+// game since. Returns the changed tournament, and whether the change was made.
+func (gm *Manager) updateMatch(match pendingMatch, change func(*tournament.Match)) (tournament.Tournament, bool) {
+	gm.tournamentsMu.Lock()
+	defer gm.tournamentsMu.Unlock()
+	t := gm.tournaments[match.tournamentID]
+	m, exists := t.Match(match.matchID)
+	if !exists || m.Decided() || m.GameID != match.gameID {
+		return tournament.Tournament{}, false
+	}
+	change(m)
+	return revise(t), true
+}
+
+// revise counts a change to a tournament and returns a snapshot of it to publish
+func revise(t *tournament.Tournament) tournament.Tournament {
+	t.Revision++
+	return t.Clone()
+}
+
+// publishTournament persists a tournament's snapshot and sends its bracket to every
+// connected player
+func (gm *Manager) publishTournament(snapshot tournament.Tournament) {
+	if gm.tournamentStore != nil {
+		if err := tournament.Save(gm.tournamentStore, snapshot); err != nil {
+			slog.Error("Failed to persist tournament", "tournament_id", snapshot.ID, "error", err)
+		}
+	}
+	gm.broadcast(gm.registeredPlayers(), constants.MSG_TOURNAMENT_UPDATE, map[string]any{
+		"tournament": snapshot,
+	})
+}
+
+// sendTournamentError answers a tournament message with an error
+func (gm *Manager) sendTournamentError(player *models.Player, code, message string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"message": message,
+		"code":    code,
+	})
+}
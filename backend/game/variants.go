@@ -0,0 +1,120 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// ScoreAttackDuration is how long a score attack lasts, not counting pauses
+const ScoreAttackDuration = 2 * time.Minute
+
+// singlePlayerVariants are the single player variants, the default first: endless lasts
+// until the snake crashes, a score attack also ends when its time is up, and a daily run
+// is endless with the food of the day, ranked until midnight UTC
+var singlePlayerVariants = []string{constants.VARIANT_ENDLESS, constants.VARIANT_SCORE_ATTACK, constants.VARIANT_DAILY}
+
+// resolveVariant returns the variant with the given name, defaulting to endless when name is empty
+func resolveVariant(name string) (string, bool) {
+	if name == "" {
+		return constants.VARIANT_ENDLESS, true
+	}
+	for _, variant := range singlePlayerVariants {
+		if variant == name {
+			return variant, true
+		}
+	}
+	return "", false
+}
+
+// sendInvalidVariant notifies a player that the requested single player variant does not exist
+func (gm *Manager) sendInvalidVariant(player *models.Player, name string) {
+	gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+		"code":    "INVALID_VARIANT",
+		"message": "Unknown single player variant: " + name,
+		"variant": name,
+	})
+}
+
+// timeLeft returns the time left in a score attack; caller must hold game.Mutex
+// Returns false for games without a time limit.
+func (gm *Manager) timeLeft(game *models.Game) (time.Duration, bool) {
+	if game.Variant != constants.VARIANT_SCORE_ATTACK || game.StartedAt.IsZero() {
+		return 0, false
+	}
+	return max(0, ScoreAttackDuration-gm.clock.Since(game.StartedAt)), true
+}
+
+// recordHighScore puts the score of a finished single player game on the leaderboard of its
+// variant and difficulty, telling the player when it is a new personal best
+func (gm *Manager) recordHighScore(game *models.Game, player *models.Player, stats MatchStats) {
+	if game.Variant == "" || game.DailyRun != nil || gm.profiles == nil || len(stats.Players) == 0 {
+		return
+	}
+	score := stats.Players[0].Score
+	previousLeader := leader(gm.profiles.Leaderboard(game.Variant, game.Difficulty.Name))
+	best, err := gm.profiles.RecordScore(player.ID, player.Username, game.Variant, game.Difficulty.Name, score, gm.clock.Now())
+	if err != nil {
+		slog.Error("Failed to persist high score", "player_id", player.ID, "variant", game.Variant, "error", err)
+	}
+	if best {
+		gm.notifyLeaderboardTop(game.Variant+" ("+game.Difficulty.Name+")", previousLeader, gm.profiles.Leaderboard(game.Variant, game.Difficulty.Name))
+		gm.SendLeaderboard(player, game.Variant, game.Difficulty.Name)
+	}
+}
+
+var (
+	// ErrLeaderboardsUnavailable is returned for leaderboards on a server without profiles
+	ErrLeaderboardsUnavailable = errors.New("leaderboards are not available on this server")
+	// ErrUnknownVariant is returned for a single player variant that doesn't exist
+	ErrUnknownVariant = errors.New("unknown single player variant")
+	// ErrUnknownDifficulty is returned for a difficulty that doesn't exist
+	ErrUnknownDifficulty = errors.New("unknown difficulty")
+)
+
+// SendLeaderboard sends the player the best scores of a single player variant played at a
+// difficulty; empty names pick the defaults
+// The daily run has a single ranking, that of the current day.
+func (gm *Manager) SendLeaderboard(player *models.Player, variantName, difficultyName string) {
+	leaderboard, err := gm.Leaderboard(variantName, difficultyName)
+	switch {
+	case errors.Is(err, ErrLeaderboardsUnavailable):
+		gm.sendMessage(player, constants.MSG_ERROR, map[string]any{
+			"message": "Leaderboards are not available on this server",
+			"code":    "PROFILES_UNAVAILABLE",
+		})
+	case errors.Is(err, ErrUnknownVariant):
+		gm.sendInvalidVariant(player, variantName)
+	case errors.Is(err, ErrUnknownDifficulty):
+		gm.sendInvalidDifficulty(player, difficultyName)
+	default:
+		gm.sendMessage(player, constants.MSG_LEADERBOARD, leaderboard)
+	}
+}
+
+// Leaderboard returns the best scores of a single player variant played at a difficulty, as
+// sent in a leaderboard message; empty names pick the defaults
+func (gm *Manager) Leaderboard(variantName, difficultyName string) (map[string]any, error) {
+	if gm.profiles == nil {
+		return nil, ErrLeaderboardsUnavailable
+	}
+	variant, valid := resolveVariant(variantName)
+	if !valid {
+		return nil, ErrUnknownVariant
+	}
+	if variant == constants.VARIANT_DAILY {
+		return gm.dailyLeaderboard(dailyDate(gm.clock.Now())), nil
+	}
+	difficulty, valid := ResolveDifficulty(difficultyName)
+	if !valid {
+		return nil, ErrUnknownDifficulty
+	}
+	return map[string]any{
+		"variant":    variant,
+		"difficulty": difficulty.Name,
+		"scores":     gm.profiles.Leaderboard(variant, difficulty.Name),
+	}, nil
+}
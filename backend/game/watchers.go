@@ -0,0 +1,92 @@
+package game
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// MaxWatchersPerGame caps the read-only viewers streaming one game
+const MaxWatchersPerGame = 100
+
+var (
+	// ErrGameNotFound is returned when watching a game this instance doesn't host
+	ErrGameNotFound = errors.New("game not found")
+	// ErrTooManyWatchers is returned when a game already streams to MaxWatchersPerGame viewers
+	ErrTooManyWatchers = errors.New("too many viewers")
+)
+
+// watchedMessages are the messages streamed to a game's watchers: its frames
+var watchedMessages = map[string]bool{
+	constants.MSG_GAME_START:  true,
+	constants.MSG_GAME_UPDATE: true,
+	constants.MSG_GAME_OVER:   true,
+}
+
+// WatchGame streams a game's frames to a read-only viewer that has no player session, such
+// as one behind a proxy that breaks WebSockets
+// The returned connection gets the current frame right away, then the game's game_start,
+// game_update and game_over messages; a lagging viewer skips straight to the newest update.
+// The done channel is closed once the game is removed. Call UnwatchGame when the viewer leaves.
+func (gm *Manager) WatchGame(gameID string) (*models.Connection, <-chan struct{}, error) {
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return nil, nil, ErrGameNotFound
+	}
+
+	conn := models.NewConnection(gm.Limits().SendQueueSize)
+	game.Mutex.Lock()
+	if len(game.Watchers) >= MaxWatchersPerGame {
+		game.Mutex.Unlock()
+		return nil, nil, ErrTooManyWatchers
+	}
+	if game.Watchers == nil {
+		game.Watchers = make(map[string]*models.Connection)
+	}
+	game.Watchers[conn.ID] = conn
+	frame := game.State.Clone()
+	game.Mutex.Unlock()
+
+	msg := newOutboundMessage(constants.MSG_GAME_UPDATE, map[string]any{"data": frame})
+	conn.Enqueue(msg.ws, true)
+	slog.Info("Game watcher joined", "game_id", gameID, "conn_id", conn.ID)
+	return conn, game.Context().Done(), nil
+}
+
+// UnwatchGame stops streaming a game to a viewer
+func (gm *Manager) UnwatchGame(gameID string, conn *models.Connection) {
+	conn.Close(models.CloseClientGone)
+	game, exists := gm.lookupGame(gameID)
+	if !exists {
+		return
+	}
+	game.Mutex.Lock()
+	delete(game.Watchers, conn.ID)
+	game.Mutex.Unlock()
+	slog.Info("Game watcher left", "game_id", gameID, "conn_id", conn.ID)
+}
+
+// broadcastToWatchers streams a message to a game's watchers, serialized once for all of them
+func (gm *Manager) broadcastToWatchers(game *models.Game, msgType string, data map[string]any) {
+	game.Mutex.RLock()
+	watchers := make([]*models.Connection, 0, len(game.Watchers))
+	for _, conn := range game.Watchers {
+		watchers = append(watchers, conn)
+	}
+	game.Mutex.RUnlock()
+	if len(watchers) == 0 {
+		return
+	}
+
+	msg := newOutboundMessage(msgType, data)
+	for _, conn := range watchers {
+		switch conn.Enqueue(msg.ws, msgType == constants.MSG_GAME_UPDATE) {
+		case models.ErrMessageDropped:
+			staleFramesDropped.Inc()
+		case models.ErrSlowConsumer:
+			slowConsumerDisconnects.Inc()
+		}
+	}
+}
@@ -0,0 +1,505 @@
+// Package graphql executes read-only GraphQL queries against a schema of resolvers
+//
+// Only queries are supported. Types are declared as objects whose fields have resolvers;
+// values are checked by the resolvers rather than by a type system, and there is no
+// introspection beyond __typename.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Query limits
+const (
+	MaxDepth  = 10     // How deeply a query may nest selections
+	MaxFields = 10_000 // Fields resolved for one query, so nested lists can't multiply without bound
+)
+
+// ResolveFunc returns the value of a field of source, the value of the parent field
+// Arguments hold literals as parsed (int, float64, string, bool, nil, []any, map[string]any,
+// enum names as string) and variables as decoded from JSON.
+type ResolveFunc func(source any, args map[string]any) (any, error)
+
+// Field is a field of an object type
+type Field struct {
+	Type    *Object     // Type of the value, or of its elements for a list; nil for scalars
+	Args    []string    // Names of the arguments the field accepts
+	Resolve ResolveFunc // nil reads the source's field of the same name, by JSON name for structs
+}
+
+// Object is an object type: a set of named fields
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Schema is the root types of the API
+type Schema struct {
+	Query *Object
+}
+
+// Request is a GraphQL request as posted in JSON
+type Request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// Error is an error in a response, with the path of the field that failed if any
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Response is the result of a request
+// Data is absent when the request failed before execution.
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Execute validates and runs a query, returning the selected data and any errors
+func Execute(schema *Schema, request Request) Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return failed("Syntax error: %v", err)
+	}
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return failed("%v", err)
+	}
+	if op.kind != "query" {
+		return failed("Only queries are supported, not %ss", op.kind)
+	}
+
+	defined := make(map[string]bool, len(op.variables))
+	for _, definition := range op.variables {
+		defined[definition.name] = true
+	}
+	v := &validator{doc: doc, variables: defined}
+	v.selections(schema.Query, op.selections, 1, nil)
+	if len(v.errors) > 0 {
+		return Response{Errors: v.errors}
+	}
+
+	variables := make(map[string]any, len(op.variables))
+	for _, definition := range op.variables {
+		value, given := request.Variables[definition.name]
+		switch {
+		case given:
+			variables[definition.name] = value
+		case definition.hasDefault:
+			variables[definition.name] = definition.defaultValue
+		case definition.nonNull:
+			return failed("Variable $%s is required", definition.name)
+		}
+	}
+
+	e := &executor{doc: doc, variables: variables}
+	data := e.selectionSet(schema.Query, nil, op.selections, nil)
+	return Response{Data: data, Errors: e.errors}
+}
+
+// failed is a response to a request that could not be executed
+func failed(format string, args ...any) Response {
+	return Response{Errors: []Error{{Message: fmt.Sprintf(format, args...)}}}
+}
+
+// selectOperation picks the operation named name, or the only one when name is empty
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required for a document with several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// validator checks a query against the schema before it runs
+type validator struct {
+	doc       *document
+	variables map[string]bool // Variables the operation defines
+	errors    []Error
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.errors = append(v.errors, Error{Message: fmt.Sprintf(format, args...)})
+}
+
+// selections checks the selections on an object type; spreading names the fragments
+// being spread, to catch cycles
+func (v *validator) selections(object *Object, selections []selection, depth int, spreading []string) {
+	if depth > MaxDepth {
+		v.fail("Query is nested deeper than %d levels", MaxDepth)
+		return
+	}
+	for _, sel := range selections {
+		for _, d := range sel.directives {
+			if d.name != "include" && d.name != "skip" {
+				v.fail("Unknown directive @%s", d.name)
+			}
+			for _, arg := range d.arguments {
+				if arg.name != "if" {
+					v.fail("Unknown argument %q on directive @%s", arg.name, d.name)
+				}
+				v.value(arg.value)
+			}
+		}
+
+		switch {
+		case sel.spread != "":
+			frag, exists := v.doc.fragments[sel.spread]
+			if !exists {
+				v.fail("Unknown fragment %q", sel.spread)
+				continue
+			}
+			for _, name := range spreading {
+				if name == sel.spread {
+					v.fail("Fragment %q spreads itself", sel.spread)
+					return
+				}
+			}
+			v.typeCondition(object, frag.typeCondition)
+			v.selections(object, frag.selections, depth, append(spreading, sel.spread))
+		case sel.inline:
+			if sel.typeCondition != "" {
+				v.typeCondition(object, sel.typeCondition)
+			}
+			v.selections(object, sel.selections, depth, spreading)
+		case sel.name == "__typename":
+			if len(sel.arguments) > 0 || len(sel.selections) > 0 {
+				v.fail("Field \"__typename\" takes no arguments or selections")
+			}
+		default:
+			field, exists := object.Fields[sel.name]
+			if !exists {
+				v.fail("Cannot query field %q on type %q", sel.name, object.Name)
+				continue
+			}
+			for _, arg := range sel.arguments {
+				if !contains(field.Args, arg.name) {
+					v.fail("Unknown argument %q on field %q of type %q", arg.name, sel.name, object.Name)
+				}
+				v.value(arg.value)
+			}
+			switch {
+			case field.Type == nil && len(sel.selections) > 0:
+				v.fail("Field %q of type %q is a scalar and can't have selections", sel.name, object.Name)
+			case field.Type != nil && len(sel.selections) == 0:
+				v.fail("Field %q of type %q must have a selection of subfields", sel.name, object.Name)
+			case field.Type != nil:
+				v.selections(field.Type, sel.selections, depth+1, spreading)
+			}
+		}
+	}
+}
+
+// typeCondition checks that a fragment applies to the object type
+// Every type is an object type, so a fragment on another type could never match.
+func (v *validator) typeCondition(object *Object, typeCondition string) {
+	if typeCondition != object.Name {
+		v.fail("Fragment on %q can't be spread in type %q", typeCondition, object.Name)
+	}
+}
+
+// value checks that the variables a value refers to are defined
+func (v *validator) value(value any) {
+	switch value := value.(type) {
+	case variable:
+		if !v.variables[string(value)] {
+			v.fail("Variable $%s is not defined", value)
+		}
+	case []any:
+		for _, item := range value {
+			v.value(item)
+		}
+	case map[string]any:
+		for _, item := range value {
+			v.value(item)
+		}
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// executor runs a validated query
+type executor struct {
+	doc       *document
+	variables map[string]any
+	errors    []Error
+	resolved  int // Fields resolved so far, bounded by MaxFields
+}
+
+// fieldGroup is the selections of one response key, merged across fragments
+type fieldGroup struct {
+	key        string
+	selections []selection
+}
+
+// selectionSet resolves the selected fields of source, an object of the given type
+func (e *executor) selectionSet(object *Object, source any, selections []selection, path []any) *orderedMap {
+	result := &orderedMap{values: make(map[string]any)}
+	for _, group := range e.collect(selections, nil) {
+		result.set(group.key, e.field(object, source, group, append(path[:len(path):len(path)], group.key)))
+	}
+	return result
+}
+
+// collect flattens fragments into the fields they select, in order, merging fields
+// selected under the same response key
+func (e *executor) collect(selections []selection, groups []fieldGroup) []fieldGroup {
+	for _, sel := range selections {
+		if !e.included(sel) {
+			continue
+		}
+		switch {
+		case sel.spread != "":
+			groups = e.collect(e.doc.fragments[sel.spread].selections, groups)
+		case sel.inline:
+			groups = e.collect(sel.selections, groups)
+		default:
+			key := sel.responseKey()
+			merged := false
+			for i := range groups {
+				if groups[i].key == key {
+					groups[i].selections = append(groups[i].selections, sel)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				groups = append(groups, fieldGroup{key: key, selections: []selection{sel}})
+			}
+		}
+	}
+	return groups
+}
+
+// included applies the @skip and @include directives of a selection
+func (e *executor) included(sel selection) bool {
+	for _, d := range sel.directives {
+		condition := false
+		for _, arg := range d.arguments {
+			condition, _ = e.substitute(arg.value).(bool)
+		}
+		if d.name == "skip" && condition || d.name == "include" && !condition {
+			return false
+		}
+	}
+	return true
+}
+
+// field resolves and completes one field of source
+func (e *executor) field(object *Object, source any, group fieldGroup, path []any) any {
+	sel := group.selections[0]
+	if sel.name == "__typename" {
+		return object.Name
+	}
+	field := object.Fields[sel.name]
+
+	e.resolved++
+	if e.resolved > MaxFields {
+		if e.resolved == MaxFields+1 {
+			e.errors = append(e.errors, Error{Message: fmt.Sprintf("Query resolves more than %d fields", MaxFields), Path: path})
+		}
+		return nil
+	}
+	args := make(map[string]any, len(sel.arguments))
+	for _, arg := range sel.arguments {
+		args[arg.name] = e.substitute(arg.value)
+	}
+	var value any
+	var err error
+	if field.Resolve != nil {
+		value, err = field.Resolve(source, args)
+	} else {
+		value = property(source, sel.name)
+	}
+	if err != nil {
+		e.errors = append(e.errors, Error{Message: err.Error(), Path: path})
+		return nil
+	}
+
+	var subselections []selection
+	for _, merged := range group.selections {
+		subselections = append(subselections, merged.selections...)
+	}
+	return e.complete(field.Type, value, subselections, path)
+}
+
+// complete selects the fields of an object value, or of each object in a list
+func (e *executor) complete(object *Object, value any, selections []selection, path []any) any {
+	if object == nil {
+		return value
+	}
+	if isNil(value) {
+		return nil
+	}
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return e.selectionSet(object, value, selections, path)
+	}
+	items := make([]any, list.Len())
+	for i := range items {
+		items[i] = e.complete(object, list.Index(i).Interface(), selections, append(path[:len(path):len(path)], i))
+	}
+	return items
+}
+
+// substitute replaces the variables in a value with their values
+func (e *executor) substitute(value any) any {
+	switch value := value.(type) {
+	case variable:
+		return e.variables[string(value)]
+	case enumValue:
+		return string(value)
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			items[i] = e.substitute(item)
+		}
+		return items
+	case map[string]any:
+		fields := make(map[string]any, len(value))
+		for name, item := range value {
+			fields[name] = e.substitute(item)
+		}
+		return fields
+	}
+	return value
+}
+
+// isNil reports whether a value is nil or a nil pointer, map or slice
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// property reads a field of a map, or of a struct by its JSON name, including the fields
+// of embedded structs; nil when there is none
+func property(source any, name string) any {
+	if m, ok := source.(map[string]any); ok {
+		return m[name]
+	}
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if field, found := structField(v, name); found {
+		return field.Interface()
+	}
+	return nil
+}
+
+// structField finds the exported field of a struct whose JSON name is name
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && tagName == "" && field.Type.Kind() == reflect.Struct {
+			if found, ok := structField(v.Field(i), name); ok {
+				return found, true
+			}
+			continue
+		}
+		if tagName == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// orderedMap is a response object; its fields are written in the order they were selected
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// IntArg returns an integer argument, def when it is absent or null
+// Variables decoded from JSON arrive as float64, so integral floats are accepted.
+func IntArg(args map[string]any, name string, def int) (int, error) {
+	switch value := args[name].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return value, nil
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) <= math.MaxInt32 {
+			return int(value), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q must be an integer", name)
+}
+
+// StringArg returns a string argument, def when it is absent or null
+func StringArg(args map[string]any, name, def string) (string, error) {
+	switch value := args[name].(type) {
+	case nil:
+		return def, nil
+	case string:
+		return value, nil
+	}
+	return "", fmt.Errorf("argument %q must be a string", name)
+}
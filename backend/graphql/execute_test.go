@@ -0,0 +1,307 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testPlayer struct {
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+	Private  string `json:"-"`
+}
+
+// testSchema is a query type with players, whose friends are players again
+func testSchema() *Schema {
+	player := &Object{Name: "Player"}
+	player.Fields = map[string]*Field{
+		"username": {},
+		"score":    {},
+		"friends": {
+			Type: player,
+			Resolve: func(source any, args map[string]any) (any, error) {
+				return []testPlayer{{Username: source.(testPlayer).Username + "'s friend"}}, nil
+			},
+		},
+	}
+	return &Schema{Query: &Object{Name: "Query", Fields: map[string]*Field{
+		"player": {
+			Type: player,
+			Args: []string{"username"},
+			Resolve: func(source any, args map[string]any) (any, error) {
+				username, err := StringArg(args, "username", "alice")
+				if username == "nobody" {
+					return (*testPlayer)(nil), err
+				}
+				return testPlayer{Username: username, Score: len(username)}, err
+			},
+		},
+		"players": {
+			Type: player,
+			Args: []string{"count"},
+			Resolve: func(source any, args map[string]any) (any, error) {
+				count, err := IntArg(args, "count", 2)
+				players := make([]testPlayer, count)
+				for i := range players {
+					players[i] = testPlayer{Username: fmt.Sprint("p", i), Score: i}
+				}
+				return players, err
+			},
+		},
+		"echo": {
+			Args:    []string{"value"},
+			Resolve: func(source any, args map[string]any) (any, error) { return args["value"], nil },
+		},
+		"broken": {
+			Resolve: func(source any, args map[string]any) (any, error) { return nil, errors.New("resolver failed") },
+		},
+	}}}
+}
+
+// run executes a query and returns its data as JSON and its error messages
+func run(t *testing.T, request Request) (string, []string) {
+	t.Helper()
+	response := Execute(testSchema(), request)
+	var messages []string
+	for _, err := range response.Errors {
+		messages = append(messages, err.Message)
+	}
+	if response.Data == nil {
+		return "", messages
+	}
+	data, err := json.Marshal(response.Data)
+	if err != nil {
+		t.Fatalf("marshal data: %v", err)
+	}
+	return string(data), messages
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+	}{
+		{
+			"fields in selection order",
+			`{ player(username: "bob") { score username __typename } }`, nil,
+			`{"player":{"score":3,"username":"bob","__typename":"Player"}}`,
+		},
+		{
+			"aliases",
+			`{ a: player { username } b: player(username: "cy") { username } }`, nil,
+			`{"a":{"username":"alice"},"b":{"username":"cy"}}`,
+		},
+		{
+			"lists and nested objects",
+			`{ players(count: 2) { username friends { username } } }`, nil,
+			`{"players":[{"username":"p0","friends":[{"username":"p0's friend"}]},{"username":"p1","friends":[{"username":"p1's friend"}]}]}`,
+		},
+		{
+			"null object",
+			`{ player(username: "nobody") { username } }`, nil,
+			`{"player":null}`,
+		},
+		{
+			"variables and defaults",
+			`query ($name: String!, $count: Int = 1) { player(username: $name) { username } players(count: $count) { username } }`,
+			map[string]any{"name": "dee"},
+			`{"player":{"username":"dee"},"players":[{"username":"p0"}]}`,
+		},
+		{
+			"variables decoded from JSON",
+			`query ($count: Int) { players(count: $count) { score } }`,
+			map[string]any{"count": 1.0},
+			`{"players":[{"score":0}]}`,
+		},
+		{
+			"literals",
+			`{ echo(value: {list: [1, 2.5, "s", true, null, ASC]}) }`, nil,
+			`{"echo":{"list":[1,2.5,"s",true,null,"ASC"]}}`,
+		},
+		{
+			"fragments merge into their fields",
+			`{ player { ...Name ... on Player { score } ... { username } } } fragment Name on Player { username }`, nil,
+			`{"player":{"username":"alice","score":5}}`,
+		},
+		{
+			"selections of a repeated field merge",
+			`{ player { username } player { score } }`, nil,
+			`{"player":{"username":"alice","score":5}}`,
+		},
+		{
+			"skip and include",
+			`query ($hide: Boolean!) { player { username @skip(if: $hide) score @include(if: $hide) ...F @include(if: false) } } fragment F on Player { friends { username } }`,
+			map[string]any{"hide": true},
+			`{"player":{"score":5}}`,
+		},
+		{
+			"nested just within the depth limit",
+			"{ player { " + strings.Repeat("friends { ", MaxDepth-2) + "username" + strings.Repeat(" }", MaxDepth-2) + " } }", nil,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := run(t, Request{Query: tt.query, Variables: tt.variables})
+			if len(errs) > 0 {
+				t.Fatalf("errors = %q", errs)
+			}
+			if tt.want != "" && data != tt.want {
+				t.Errorf("data = %s\nwant %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Request
+		want    string
+	}{
+		{"syntax error", Request{Query: "{ player { }"}, "Syntax error"},
+		{"mutation", Request{Query: "mutation { player { username } }"}, "Only queries are supported, not mutations"},
+		{"several operations unnamed", Request{Query: "query A { echo } query B { echo }"}, "operationName is required"},
+		{"unknown operation", Request{Query: "query A { echo }", OperationName: "B"}, `unknown operation "B"`},
+		{"unknown field", Request{Query: "{ player { email } }"}, `Cannot query field "email" on type "Player"`},
+		{"unknown argument", Request{Query: "{ player(id: 1) { username } }"}, `Unknown argument "id" on field "player"`},
+		{"selection on a scalar", Request{Query: "{ echo { value } }"}, `"echo" of type "Query" is a scalar`},
+		{"object without selection", Request{Query: "{ player }"}, "must have a selection of subfields"},
+		{"typename with selection", Request{Query: "{ __typename { a } }"}, "takes no arguments or selections"},
+		{"unknown directive", Request{Query: "{ echo @cached }"}, "Unknown directive @cached"},
+		{"unknown directive argument", Request{Query: "{ echo @skip(when: true) }"}, `Unknown argument "when" on directive @skip`},
+		{"undefined variable", Request{Query: "{ echo(value: $v) }"}, "Variable $v is not defined"},
+		{"undefined variable in a list", Request{Query: "{ echo(value: [1, {a: $v}]) }"}, "Variable $v is not defined"},
+		{"missing required variable", Request{Query: "query ($v: Int!) { echo(value: $v) }"}, "Variable $v is required"},
+		{"unknown fragment", Request{Query: "{ player { ...F } }"}, `Unknown fragment "F"`},
+		{"fragment on another type", Request{Query: "{ ...F } fragment F on Player { username }"}, `Fragment on "Player" can't be spread in type "Query"`},
+		{"inline fragment on another type", Request{Query: "{ ... on Player { username } }"}, `Fragment on "Player" can't be spread in type "Query"`},
+		{
+			"nested deeper than the limit",
+			Request{Query: "{ player { " + strings.Repeat("friends { ", MaxDepth-1) + "username" + strings.Repeat(" }", MaxDepth-1) + " } }"},
+			fmt.Sprintf("nested deeper than %d levels", MaxDepth),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := run(t, tt.request)
+			if data != "" {
+				t.Errorf("data = %s, want none", data)
+			}
+			if len(errs) == 0 || !strings.Contains(strings.Join(errs, "; "), tt.want) {
+				t.Errorf("errors = %q, want one containing %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteCyclicFragments(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"spreads itself", `{ player { ...A } } fragment A on Player { username ...A }`, `Fragment "A" spreads itself`},
+		{"through another", `{ player { ...A } } fragment A on Player { ...B } fragment B on Player { ...A }`, `Fragment "A" spreads itself`},
+		{"through a field", `{ player { ...A } } fragment A on Player { friends { ...A } }`, `Fragment "A" spreads itself`},
+		{"through an inline fragment", `{ player { ...A } } fragment A on Player { ... on Player { ...A } }`, `Fragment "A" spreads itself`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, errs := run(t, Request{Query: tt.query})
+			if data != "" || len(errs) == 0 || !strings.Contains(strings.Join(errs, "; "), tt.want) {
+				t.Errorf("data = %s, errors = %q, want no data and %q", data, errs, tt.want)
+			}
+		})
+	}
+
+	// The same fragment spread twice side by side is not a cycle
+	if _, errs := run(t, Request{Query: `{ player { ...A ...A } } fragment A on Player { username }`}); len(errs) > 0 {
+		t.Errorf("repeated spread: errors = %q", errs)
+	}
+}
+
+func TestExecuteFieldLimit(t *testing.T) {
+	// Each player resolves two fields, so the list goes over the limit partway through
+	count := MaxFields/2 + 10
+	response := Execute(testSchema(), Request{Query: fmt.Sprintf("{ players(count: %d) { username score } }", count)})
+	if len(response.Errors) != 1 {
+		t.Fatalf("errors = %+v, want exactly one", response.Errors)
+	}
+	err := response.Errors[0]
+	if err.Message != fmt.Sprintf("Query resolves more than %d fields", MaxFields) {
+		t.Errorf("message = %q", err.Message)
+	}
+	// The players field itself is the first one resolved, so the limit falls on a score
+	wantPath := []any{"players", (MaxFields - 1) / 2, "score"}
+	if fmt.Sprint(err.Path) != fmt.Sprint(wantPath) {
+		t.Errorf("path = %v, want %v", err.Path, wantPath)
+	}
+
+	data, marshalErr := json.Marshal(response.Data)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	var decoded struct {
+		Players []*testPlayer `json:"players"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	last := decoded.Players[len(decoded.Players)-1]
+	if len(decoded.Players) != count || last.Username != "" {
+		t.Errorf("players = %d, last = %+v, want %d with the fields past the limit left null", len(decoded.Players), last, count)
+	}
+}
+
+func TestExecuteResolverError(t *testing.T) {
+	response := Execute(testSchema(), Request{Query: `{ echo(value: 1) broken }`})
+	if len(response.Errors) != 1 || response.Errors[0].Message != "resolver failed" || fmt.Sprint(response.Errors[0].Path) != "[broken]" {
+		t.Fatalf("errors = %+v, want the resolver's error at [broken]", response.Errors)
+	}
+	data, _ := json.Marshal(response.Data)
+	if string(data) != `{"echo":1,"broken":null}` {
+		t.Errorf("data = %s, want the other fields resolved and broken null", data)
+	}
+
+	// An argument of the wrong type fails just its field
+	response = Execute(testSchema(), Request{Query: `{ players(count: "two") { username } }`})
+	if len(response.Errors) != 1 || !strings.Contains(response.Errors[0].Message, `"count" must be an integer`) {
+		t.Errorf("errors = %+v, want the argument rejected", response.Errors)
+	}
+}
+
+func TestProperty(t *testing.T) {
+	type Embedded struct {
+		Level int `json:"level"`
+	}
+	type withEmbedded struct {
+		Embedded
+		Name string `json:"name,omitempty"`
+	}
+	source := &withEmbedded{Embedded: Embedded{Level: 3}, Name: "x"}
+	tests := []struct {
+		source any
+		name   string
+		want   any
+	}{
+		{map[string]any{"a": 1}, "a", 1},
+		{testPlayer{Username: "bob"}, "username", "bob"},
+		{&testPlayer{Score: 4}, "score", 4},
+		{testPlayer{Private: "secret"}, "Private", nil},
+		{source, "name", "x"},
+		{source, "level", 3},
+		{(*testPlayer)(nil), "username", nil},
+		{42, "value", nil},
+	}
+	for _, tt := range tests {
+		if got := property(tt.source, tt.name); got != tt.want {
+			t.Errorf("property(%#v, %q) = %#v, want %#v", tt.source, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,659 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed request: its operations and the fragments they spread
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a query, mutation or subscription of a document
+type operation struct {
+	kind       string // query, mutation or subscription
+	name       string
+	variables  []variableDefinition
+	selections []selection
+}
+
+// variableDefinition declares a variable of an operation
+type variableDefinition struct {
+	name         string
+	nonNull      bool // The variable must be given unless it has a default
+	defaultValue any
+	hasDefault   bool
+}
+
+// fragment is a named set of selections on a type
+type fragment struct {
+	name          string
+	typeCondition string
+	selections    []selection
+}
+
+// selection is a field, a fragment spread (spread set) or an inline fragment (inline set)
+type selection struct {
+	alias      string
+	name       string
+	arguments  []argument
+	directives []directive
+	selections []selection
+
+	spread        string // Name of the spread fragment
+	inline        bool
+	typeCondition string // Of an inline fragment, empty when it applies to any type
+}
+
+// responseKey is the name a field's value is returned under
+func (s selection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// argument is a named value passed to a field or directive
+type argument struct {
+	name  string
+	value any
+}
+
+// directive is a @name(arguments) annotation of a selection
+type directive struct {
+	name      string
+	arguments []argument
+}
+
+// variable is a $name reference in a value, substituted when the operation is executed
+type variable string
+
+// enumValue is an enum literal such as DESC, passed to resolvers as its name
+type enumValue string
+
+// Token kinds
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// parser is a recursive descent parser for GraphQL executable documents
+type parser struct {
+	source string
+	pos    int
+	tok    token
+}
+
+// parse parses a GraphQL executable document
+func parse(source string) (*document, error) {
+	p := &parser{source: source}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections})
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.fragments[frag.name]; exists {
+				return nil, fmt.Errorf("there can be only one fragment named %q", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document contains no operation")
+	}
+	return doc, nil
+}
+
+// next advances to the next token, skipping whitespace, commas and comments
+func (p *parser) next() error {
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		if c == '#' {
+			for p.pos < len(p.source) && p.source[p.pos] != '\n' && p.source[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ',' {
+			break
+		}
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos >= len(p.source) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+	c := p.source[p.pos]
+	switch {
+	case strings.HasPrefix(p.source[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunctuator, value: "...", pos: start}
+	case strings.IndexByte("!$():=@[]{|}", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunctuator, value: string(c), pos: start}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.source) && (p.source[p.pos] == '_' || isLetter(p.source[p.pos]) || isDigit(p.source[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.source[start:p.pos], pos: start}
+	case c == '-' || isDigit(c):
+		return p.number()
+	case c == '"':
+		return p.string()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.source[p.pos:])
+		return fmt.Errorf("unexpected character %q at offset %d", r, start)
+	}
+	return nil
+}
+
+// number lexes an int or float literal
+func (p *parser) number() error {
+	start := p.pos
+	kind := tokenInt
+	if p.source[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.source) && isDigit(p.source[p.pos]) {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.source) && p.source[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.source) && (p.source[p.pos] == '+' || p.source[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	p.tok = token{kind: kind, value: p.source[start:p.pos], pos: start}
+	return nil
+}
+
+// string lexes a quoted string literal, decoding its escapes
+func (p *parser) string() error {
+	start := p.pos
+	if strings.HasPrefix(p.source[p.pos:], `"""`) {
+		end := strings.Index(p.source[p.pos+3:], `"""`)
+		if end < 0 {
+			return fmt.Errorf("unterminated block string at offset %d", start)
+		}
+		p.tok = token{kind: tokenString, value: p.source[p.pos+3 : p.pos+3+end], pos: start}
+		p.pos += 3 + end + 3
+		return nil
+	}
+
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.source) || p.source[p.pos] == '\n' || p.source[p.pos] == '\r' {
+			return fmt.Errorf("unterminated string at offset %d", start)
+		}
+		c := p.source[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.source) {
+			return fmt.Errorf("unterminated string at offset %d", start)
+		}
+		escape := p.source[p.pos+1]
+		p.pos += 2
+		switch escape {
+		case '"', '\\', '/':
+			b.WriteByte(escape)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.source) {
+				return fmt.Errorf("invalid unicode escape at offset %d", p.pos-2)
+			}
+			code, err := strconv.ParseUint(p.source[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				return fmt.Errorf("invalid unicode escape at offset %d", p.pos-2)
+			}
+			b.WriteRune(rune(code))
+			p.pos += 4
+		default:
+			return fmt.Errorf("invalid escape \\%c at offset %d", escape, p.pos-2)
+		}
+	}
+	p.tok = token{kind: tokenString, value: b.String(), pos: start}
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// peek reports whether the current token is of kind with the given value
+func (p *parser) peek(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip consumes the current token if it is the given punctuator
+func (p *parser) skip(punctuator string) (bool, error) {
+	if !p.peek(tokenPunctuator, punctuator) {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+// expect consumes the given punctuator or fails
+func (p *parser) expect(punctuator string) error {
+	if !p.peek(tokenPunctuator, punctuator) {
+		return p.unexpected()
+	}
+	return p.next()
+}
+
+// name consumes a name
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of document")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", p.tok.value, p.tok.pos)
+}
+
+// operation parses query|mutation|subscription Name? VariableDefinitions? Directives? SelectionSet
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek(tokenPunctuator, "(") {
+		variables, err := p.variableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.variables = variables
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+// variableDefinitions parses ($name: Type = default, ...)
+func (p *parser) variableDefinitions() ([]variableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var definitions []variableDefinition
+	for {
+		if done, err := p.skip(")"); err != nil || done {
+			return definitions, err
+		}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		nonNull, err := p.typeReference()
+		if err != nil {
+			return nil, err
+		}
+		definition := variableDefinition{name: name, nonNull: nonNull}
+		if found, err := p.skip("="); err != nil {
+			return nil, err
+		} else if found {
+			value, err := p.value(true)
+			if err != nil {
+				return nil, err
+			}
+			definition.defaultValue, definition.hasDefault = value, true
+		}
+		if _, err := p.directives(); err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+}
+
+// typeReference parses Name, [Type] or either followed by !, reporting the trailing !
+// Variables aren't type checked: resolvers check the values of their arguments.
+func (p *parser) typeReference() (bool, error) {
+	if found, err := p.skip("["); err != nil {
+		return false, err
+	} else if found {
+		if _, err := p.typeReference(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.name(); err != nil {
+		return false, err
+	}
+	return p.skip("!")
+}
+
+// fragment parses fragment Name on Type Directives? SelectionSet
+func (p *parser) fragment() (*fragment, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("a fragment can't be named \"on\"")
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, selections: selections}, nil
+}
+
+// selectionSet parses { Selection... }
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for {
+		if done, err := p.skip("}"); err != nil {
+			return nil, err
+		} else if done {
+			if len(selections) == 0 {
+				return nil, fmt.Errorf("a selection set must not be empty")
+			}
+			return selections, nil
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+}
+
+// selection parses a field, ...FragmentName or ... on Type { ... }
+func (p *parser) selection() (selection, error) {
+	var sel selection
+	if found, err := p.skip("..."); err != nil {
+		return sel, err
+	} else if found {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			sel.spread = p.tok.value
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			directives, err := p.directives()
+			sel.directives = directives
+			return sel, err
+		}
+		sel.inline = true
+		if p.peek(tokenName, "on") {
+			if err := p.next(); err != nil {
+				return sel, err
+			}
+			typeCondition, err := p.name()
+			if err != nil {
+				return sel, err
+			}
+			sel.typeCondition = typeCondition
+		}
+		directives, err := p.directives()
+		if err != nil {
+			return sel, err
+		}
+		sel.directives = directives
+		sel.selections, err = p.selectionSet()
+		return sel, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return sel, err
+	}
+	if found, err := p.skip(":"); err != nil {
+		return sel, err
+	} else if found {
+		sel.alias = name
+		if name, err = p.name(); err != nil {
+			return sel, err
+		}
+	}
+	sel.name = name
+	if sel.arguments, err = p.arguments(); err != nil {
+		return sel, err
+	}
+	if sel.directives, err = p.directives(); err != nil {
+		return sel, err
+	}
+	if p.peek(tokenPunctuator, "{") {
+		sel.selections, err = p.selectionSet()
+	}
+	return sel, err
+}
+
+// arguments parses an optional (name: value, ...)
+func (p *parser) arguments() ([]argument, error) {
+	if found, err := p.skip("("); err != nil || !found {
+		return nil, err
+	}
+	var arguments []argument
+	for {
+		if done, err := p.skip(")"); err != nil {
+			return nil, err
+		} else if done {
+			return arguments, nil
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument{name: name, value: value})
+	}
+}
+
+// directives parses any number of @name(arguments)
+func (p *parser) directives() ([]directive, error) {
+	var directives []directive
+	for p.peek(tokenPunctuator, "@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arguments, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive{name: name, arguments: arguments})
+	}
+	return directives, nil
+}
+
+// value parses a literal or, unless constant, a variable
+// Lists are []any and input objects map[string]any.
+func (p *parser) value(constant bool) (any, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %s at offset %d", tok.value, tok.pos)
+		}
+		return n, p.next()
+	case tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s at offset %d", tok.value, tok.pos)
+		}
+		return f, p.next()
+	case tokenString:
+		return tok.value, p.next()
+	case tokenName:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return enumValue(tok.value), nil
+	}
+
+	switch {
+	case p.peek(tokenPunctuator, "$") && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return variable(name), err
+	case p.peek(tokenPunctuator, "["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []any{}
+		for {
+			if done, err := p.skip("]"); err != nil {
+				return nil, err
+			} else if done {
+				return list, nil
+			}
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case p.peek(tokenPunctuator, "{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		object := map[string]any{}
+		for {
+			if done, err := p.skip("}"); err != nil {
+				return nil, err
+			} else if done {
+				return object, nil
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, p.unexpected()
+}
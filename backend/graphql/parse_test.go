@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseOne parses a document and returns its only operation
+func parseOne(t *testing.T, source string) (*document, *operation) {
+	t.Helper()
+	doc, err := parse(source)
+	if err != nil {
+		t.Fatalf("parse(%q): %v", source, err)
+	}
+	if len(doc.operations) != 1 {
+		t.Fatalf("parse(%q) = %d operations, want 1", source, len(doc.operations))
+	}
+	return doc, doc.operations[0]
+}
+
+func TestParseOperations(t *testing.T) {
+	tests := []struct {
+		source string
+		kind   string
+		name   string
+	}{
+		{"{ a }", "query", ""},
+		{"query { a }", "query", ""},
+		{"query Top { a }", "query", "Top"},
+		{"mutation Rename { a }", "mutation", "Rename"},
+		{"subscription Live { a }", "subscription", "Live"},
+		{"query Top @cached { a }", "query", "Top"},
+	}
+	for _, tt := range tests {
+		_, op := parseOne(t, tt.source)
+		if op.kind != tt.kind || op.name != tt.name {
+			t.Errorf("parse(%q) = %s %q, want %s %q", tt.source, op.kind, op.name, tt.kind, tt.name)
+		}
+	}
+
+	doc, err := parse("query A { a } query B { b }")
+	if err != nil || len(doc.operations) != 2 || doc.operations[1].name != "B" {
+		t.Errorf("parse of two operations = %+v, %v", doc, err)
+	}
+}
+
+func TestParseSelections(t *testing.T) {
+	_, op := parseOne(t, `{
+		# Leaders first
+		top: leaderboard(limit: 5, order: DESC) {
+			username, score
+		}
+		player(username: "bob") { username }
+	}`)
+	want := []selection{
+		{
+			alias: "top", name: "leaderboard",
+			arguments:  []argument{{"limit", 5}, {"order", enumValue("DESC")}},
+			selections: []selection{{name: "username"}, {name: "score"}},
+		},
+		{
+			name:       "player",
+			arguments:  []argument{{"username", "bob"}},
+			selections: []selection{{name: "username"}},
+		},
+	}
+	if !reflect.DeepEqual(op.selections, want) {
+		t.Errorf("selections = %+v, want %+v", op.selections, want)
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	_, op := parseOne(t, `query Q($name: String!, $limit: Int = 10, $ids: [ID!]! = ["a"], $opt: Boolean @deprecated) {
+		player(username: $name) { username }
+	}`)
+	want := []variableDefinition{
+		{name: "name", nonNull: true},
+		{name: "limit", defaultValue: 10, hasDefault: true},
+		{name: "ids", nonNull: true, defaultValue: []any{"a"}, hasDefault: true},
+		{name: "opt"},
+	}
+	if !reflect.DeepEqual(op.variables, want) {
+		t.Errorf("variables = %+v, want %+v", op.variables, want)
+	}
+	if got := op.selections[0].arguments[0].value; got != variable("name") {
+		t.Errorf("argument = %#v, want variable $name", got)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    any
+	}{
+		{"42", 42},
+		{"-7", -7},
+		{"1.5", 1.5},
+		{"2e3", 2000.0},
+		{`"a\"b\\c\/\né"`, "a\"b\\c/\né"},
+		{`"""raw "quoted" \n"""`, `raw "quoted" \n`},
+		{"true", true},
+		{"false", false},
+		{"null", nil},
+		{"ASC", enumValue("ASC")},
+		{"$v", variable("v")},
+		{"[1, [2], []]", []any{1, []any{2}, []any{}}},
+		{`{a: 1, b: {c: "d"}}`, map[string]any{"a": 1, "b": map[string]any{"c": "d"}}},
+	}
+	for _, tt := range tests {
+		_, op := parseOne(t, "{ f(x: "+tt.literal+") }")
+		if got := op.selections[0].arguments[0].value; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("value of %s = %#v, want %#v", tt.literal, got, tt.want)
+		}
+	}
+}
+
+func TestParseFragments(t *testing.T) {
+	doc, op := parseOne(t, `
+		query { player(username: "bob") { ...Fields ... on Player { score } ... @include(if: true) { wins } } }
+		fragment Fields on Player @cached { username }
+	`)
+	frag := doc.fragments["Fields"]
+	if frag == nil || frag.typeCondition != "Player" || !reflect.DeepEqual(frag.selections, []selection{{name: "username"}}) {
+		t.Fatalf("fragment = %+v, want Fields on Player selecting username", frag)
+	}
+
+	want := []selection{
+		{spread: "Fields"},
+		{inline: true, typeCondition: "Player", selections: []selection{{name: "score"}}},
+		{
+			inline:     true,
+			directives: []directive{{name: "include", arguments: []argument{{"if", true}}}},
+			selections: []selection{{name: "wins"}},
+		},
+	}
+	if got := op.selections[0].selections; !reflect.DeepEqual(got, want) {
+		t.Errorf("selections = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDirectives(t *testing.T) {
+	_, op := parseOne(t, `query ($hide: Boolean!) { a @skip(if: $hide) @include(if: true) ...F @skip(if: false) }`)
+	tests := []struct {
+		sel  selection
+		want []directive
+	}{
+		{op.selections[0], []directive{
+			{name: "skip", arguments: []argument{{"if", variable("hide")}}},
+			{name: "include", arguments: []argument{{"if", true}}},
+		}},
+		{op.selections[1], []directive{{name: "skip", arguments: []argument{{"if", false}}}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.sel.directives, tt.want) {
+			t.Errorf("directives of %+v = %+v, want %+v", tt.sel, tt.sel.directives, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"empty document", "", "contains no operation"},
+		{"fragments only", "fragment F on T { a }", "contains no operation"},
+		{"empty selection set", "{ }", "must not be empty"},
+		{"unclosed selection set", "{ a", "unexpected end of document"},
+		{"missing argument value", "{ a(x: ) }", `unexpected ")"`},
+		{"missing argument colon", "{ a(x 1) }", `unexpected "1"`},
+		{"unterminated string", `{ a(x: "abc) }`, "unterminated string"},
+		{"string across lines", "{ a(x: \"a\nb\") }", "unterminated string"},
+		{"unterminated block string", `{ a(x: """abc) }`, "unterminated block string"},
+		{"invalid escape", `{ a(x: "\q") }`, `invalid escape \q`},
+		{"invalid unicode escape", `{ a(x: "\u12") }`, "invalid unicode escape"},
+		{"unexpected character", "{ a ? }", `unexpected character '?'`},
+		{"int out of range", "{ a(x: 99999999999999999999) }", "invalid int"},
+		{"variable in a default", "query ($a: Int = $b) { a }", `unexpected "$"`},
+		{"variable without type", "query ($a) { a }", `unexpected ")"`},
+		{"unclosed list type", "query ($a: [Int) { a }", `unexpected ")"`},
+		{"fragment named on", "fragment on on T { a } { a }", `can't be named "on"`},
+		{"fragment without type", "fragment F { a } { a }", `unexpected "{"`},
+		{"duplicate fragment", "{ ...F } fragment F on T { a } fragment F on T { b }", `only one fragment named "F"`},
+		{"directive without name", "{ a @ }", `unexpected "}"`},
+		{"stray token", "{ a } }", `unexpected "}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parse(%q) error = %v, want one containing %q", tt.source, err, tt.want)
+			}
+		})
+	}
+}
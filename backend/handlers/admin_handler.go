@@ -0,0 +1,654 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bariiss/snake/backend/audit"
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/moderation"
+)
+
+// defaultAuditLimit is the number of audit entries returned when no limit is given
+const defaultAuditLimit = 100
+
+// defaultReportLimit is the number of reports returned when no limit is given
+const defaultReportLimit = 100
+
+// AdminHandler serves the admin REST API
+// Routes are expected to be wrapped with auth.AdminMiddleware
+// Every privileged action is recorded in the audit log. Callers name themselves in the
+// X-Admin-Actor header (the admin token is shared) and may explain the action in X-Admin-Reason.
+type AdminHandler struct {
+	gameManager *game.Manager
+	reloader    *config.Reloader
+	auditLog    *audit.Log
+	reports     *moderation.Reports
+}
+
+func NewAdminHandler(gameManager *game.Manager, reloader *config.Reloader, auditLog *audit.Log, reports *moderation.Reports) *AdminHandler {
+	return &AdminHandler{
+		gameManager: gameManager,
+		reloader:    reloader,
+		auditLog:    auditLog,
+		reports:     reports,
+	}
+}
+
+// HandleConfigReload re-reads the configuration and applies reloadable settings
+// POST /api/admin/config/reload
+func (h *AdminHandler) HandleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ignored, err := h.reloader.Reload()
+	if err != nil {
+		slog.Error("Configuration reload failed, keeping previous configuration", "error", err)
+		h.recordAudit(r, audit.ActionConfigChange, "config", false, map[string]any{"error": err.Error()})
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	h.recordAudit(r, audit.ActionConfigChange, "config", true, map[string]any{"ignored": ignored})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  "ok",
+		"ignored": ignored,
+	})
+}
+
+// maxTuningBodySize caps the JSON object a tuning request may send
+const maxTuningBodySize = 16 << 10
+
+// HandleTuning returns the operational limits in effect
+// GET /api/admin/tuning
+func (h *AdminHandler) HandleTuning(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.reloader.Current().Tuning())
+}
+
+// HandleTune adjusts operational limits without a restart
+// POST /api/admin/tuning with any of the settings returned by GET, e.g.
+// {"rate_limit": {"burst": 80}, "limits": {"max_spectators_per_game": 50}}; settings left out
+// keep their value. Tuned values last until the next configuration reload or restart.
+func (h *AdminHandler) HandleTune(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTuningBodySize))
+	if err != nil {
+		http.Error(w, "Bad request: body too large", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.reloader.Tune(body)
+	if err != nil {
+		h.recordAudit(r, audit.ActionConfigChange, "tuning", false, map[string]any{"error": err.Error()})
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"status": "error",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	if len(changes) > 0 {
+		h.recordAudit(r, audit.ActionConfigChange, "tuning", true, map[string]any{"changes": changes})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":  "ok",
+		"changes": changes,
+		"tuning":  h.reloader.Current().Tuning(),
+	})
+}
+
+// HandleAudit lists recorded admin actions, newest first
+// GET /api/admin/audit?action=&actor=&target=&since=<RFC 3339>&limit=
+func (h *AdminHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Action: query.Get("action"),
+		Actor:  query.Get("actor"),
+		Target: query.Get("target"),
+		Limit:  defaultAuditLimit,
+	}
+	if since := query.Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(w, "Bad request: since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		filter.Since = parsed
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Bad request: limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"entries": h.auditLog.Query(filter),
+	})
+}
+
+// HandlePlayers lists the players registered on this instance
+// GET /api/admin/players
+func (h *AdminHandler) HandlePlayers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"players": h.gameManager.ListPlayers(),
+	})
+}
+
+// HandleGames lists the games hosted by this instance, finished ones included
+// GET /api/admin/games
+func (h *AdminHandler) HandleGames(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"games": h.gameManager.ListGames(),
+	})
+}
+
+// HandleGameDebug dumps the internal state of one game for diagnosing stuck games
+// GET /api/admin/games/{id}/debug
+func (h *AdminHandler) HandleGameDebug(w http.ResponseWriter, r *http.Request) {
+	debug, exists := h.gameManager.DebugGame(r.PathValue("id"))
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, debug)
+}
+
+// HandleVerifyGame plays a recorded round of a game again from its seed and inputs and
+// reports whether it ends as the server reported; the latest round unless one is given
+// GET /api/admin/games/{id}/verify?round=
+func (h *AdminHandler) HandleVerifyGame(w http.ResponseWriter, r *http.Request) {
+	round := 0
+	if raw := r.URL.Query().Get("round"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Bad request: round must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		round = parsed
+	}
+
+	verdict, err := h.gameManager.VerifyReplay(r.PathValue("id"), round)
+	switch {
+	case errors.Is(err, game.ErrReplaysUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, game.ErrReplayNotFound):
+		http.Error(w, "Recorded round not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// HandleEndGame ends a game hosted by this instance right away with the outcome given
+// POST /api/admin/games/{id}/end with {"outcome": "tie"|"win"|"void", "winner": "<player ID
+// or username>", "reason": "..."}; the winner is required for a win, and the reason defaults
+// to X-Admin-Reason
+func (h *AdminHandler) HandleEndGame(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Outcome string `json:"outcome"`
+		Winner  string `json:"winner"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if body.Reason == "" {
+		body.Reason = r.Header.Get("X-Admin-Reason")
+	}
+
+	target := r.PathValue("id")
+	end, err := h.gameManager.ForceEndGame(target, body.Outcome, body.Winner, body.Reason, adminActor(r))
+	switch {
+	case errors.Is(err, game.ErrInvalidOutcome), errors.Is(err, game.ErrNotGamePlayer):
+		http.Error(w, "Bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, game.ErrGameNotFound):
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	case errors.Is(err, game.ErrNotDecidable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.recordAudit(r, audit.ActionForceEnd, target, true, map[string]any{
+		"outcome": end.Outcome,
+		"winner":  end.WinnerUsername,
+		"players": end.Players,
+		"reason":  end.Reason,
+	})
+	writeJSON(w, http.StatusOK, end)
+}
+
+// HandleReports lists player reports, newest first; open ones by default
+// GET /api/admin/reports?status=open|resolved|dismissed|all&category=&target=&limit=
+func (h *AdminHandler) HandleReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := moderation.Filter{
+		Status:   query.Get("status"),
+		Category: query.Get("category"),
+		Target:   query.Get("target"),
+		Limit:    defaultReportLimit,
+	}
+	switch filter.Status {
+	case "":
+		filter.Status = moderation.StatusOpen
+	case "all":
+		filter.Status = ""
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Bad request: limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"open":    h.reports.OpenCount(),
+		"reports": h.reports.Query(filter),
+	})
+}
+
+// HandleReviewReport closes a report as resolved or dismissed
+// POST /api/admin/reports/{id}/review with {"status": "resolved"|"dismissed", "note": "..."}
+func (h *AdminHandler) HandleReviewReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Bad request: invalid report ID", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	target := r.PathValue("id")
+	report, err := h.reports.Review(id, body.Status, adminActor(r), body.Note)
+	switch {
+	case errors.Is(err, moderation.ErrInvalidStatus):
+		http.Error(w, "Bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, moderation.ErrNotFound):
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	case err != nil:
+		// The review is applied in memory even if persisting it failed
+		slog.Error("Failed to persist report review", "report_id", id, "error", err)
+	}
+
+	h.recordAudit(r, audit.ActionReportReview, target, true, map[string]any{
+		"status":      report.Status,
+		"target_name": report.TargetName,
+		"category":    report.Category,
+	})
+	writeJSON(w, http.StatusOK, report)
+}
+
+// HandleRestrictions lists the players under a shadow restriction, newest first
+// GET /api/admin/restrictions
+func (h *AdminHandler) HandleRestrictions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"restrictions": h.gameManager.Restrictions(),
+	})
+}
+
+// HandleRestrict puts a player under a shadow restriction, whether or not they are connected
+// POST /api/admin/players/{id}/restriction with {"reason": "..."}; the reason defaults to
+// X-Admin-Reason
+func (h *AdminHandler) HandleRestrict(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.Reason == "" {
+		body.Reason = r.Header.Get("X-Admin-Reason")
+	}
+
+	target := r.PathValue("id")
+	restriction, err := h.gameManager.RestrictPlayer(target, body.Reason, adminActor(r))
+	switch {
+	case errors.Is(err, game.ErrRestrictionsUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		// The restriction applies even if persisting it failed
+		slog.Error("Failed to persist restriction", "player_id", target, "error", err)
+	}
+
+	h.recordAudit(r, audit.ActionRestrict, target, true, map[string]any{
+		"username": restriction.Username,
+		"reason":   restriction.Reason,
+	})
+	writeJSON(w, http.StatusOK, restriction)
+}
+
+// HandleLiftRestriction ends a player's shadow restriction
+// DELETE /api/admin/players/{id}/restriction
+func (h *AdminHandler) HandleLiftRestriction(w http.ResponseWriter, r *http.Request) {
+	target := r.PathValue("id")
+	restriction, err := h.gameManager.LiftRestriction(target, adminActor(r))
+	switch {
+	case errors.Is(err, game.ErrRestrictionsUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, moderation.ErrNotRestricted):
+		http.Error(w, "Player is not restricted", http.StatusNotFound)
+		return
+	case err != nil:
+		slog.Error("Failed to persist lifted restriction", "player_id", target, "error", err)
+	}
+
+	h.recordAudit(r, audit.ActionRestrict, target, true, map[string]any{
+		"username": restriction.Username,
+		"lifted":   true,
+	})
+	writeJSON(w, http.StatusOK, restriction)
+}
+
+// HandleBans lists the banned players, newest first
+// GET /api/admin/bans
+func (h *AdminHandler) HandleBans(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"bans": h.gameManager.Bans(),
+	})
+}
+
+// HandleBan bans a player, whether or not they are connected, until the ban is lifted
+// POST /api/admin/players/{id}/ban with {"reason": "..."}; the reason defaults to
+// X-Admin-Reason
+func (h *AdminHandler) HandleBan(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.Reason == "" {
+		body.Reason = r.Header.Get("X-Admin-Reason")
+	}
+
+	target := r.PathValue("id")
+	ban, err := h.gameManager.BanPlayer(target, body.Reason, adminActor(r))
+	switch {
+	case errors.Is(err, game.ErrBansUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		// The ban applies even if persisting it failed
+		slog.Error("Failed to persist ban", "player_id", target, "error", err)
+	}
+
+	h.recordAudit(r, audit.ActionBan, target, true, map[string]any{
+		"username": ban.Username,
+		"reason":   ban.Reason,
+	})
+	writeJSON(w, http.StatusOK, ban)
+}
+
+// HandleLiftBan ends a player's ban
+// DELETE /api/admin/players/{id}/ban
+func (h *AdminHandler) HandleLiftBan(w http.ResponseWriter, r *http.Request) {
+	target := r.PathValue("id")
+	ban, err := h.gameManager.LiftBan(target, adminActor(r))
+	switch {
+	case errors.Is(err, game.ErrBansUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, moderation.ErrNotBanned):
+		http.Error(w, "Player is not banned", http.StatusNotFound)
+		return
+	case err != nil:
+		slog.Error("Failed to persist lifted ban", "player_id", target, "error", err)
+	}
+
+	h.recordAudit(r, audit.ActionBan, target, true, map[string]any{
+		"username": ban.Username,
+		"lifted":   true,
+	})
+	writeJSON(w, http.StatusOK, ban)
+}
+
+// HandleKick kicks a player connected to this instance and keeps them from reconnecting for
+// a cooldown
+// POST /api/admin/players/{id}/kick with {"reason": "...", "cooldown_minutes": 5}; the reason
+// defaults to X-Admin-Reason and the cooldown to 5 minutes, at most a day
+func (h *AdminHandler) HandleKick(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason          string `json:"reason"`
+		CooldownMinutes int    `json:"cooldown_minutes"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.Reason == "" {
+		body.Reason = r.Header.Get("X-Admin-Reason")
+	}
+	cooldown := time.Duration(body.CooldownMinutes) * time.Minute
+	switch {
+	case body.CooldownMinutes == 0:
+		cooldown = game.DefaultKickCooldown
+	case cooldown < 0 || cooldown > game.MaxKickCooldown:
+		http.Error(w, "Bad request: cooldown_minutes must be 1 to 1440", http.StatusBadRequest)
+		return
+	}
+
+	target := r.PathValue("id")
+	kick, err := h.gameManager.KickPlayer(target, body.Reason, adminActor(r), cooldown)
+	switch {
+	case errors.Is(err, game.ErrPlayerNotFound):
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	case errors.Is(err, game.ErrRemotePlayer):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.recordAudit(r, audit.ActionKick, target, true, map[string]any{
+		"username": kick.Username,
+		"reason":   kick.Reason,
+		"until":    kick.Until,
+	})
+	writeJSON(w, http.StatusOK, kick)
+}
+
+// HandlePlayerLinks lists the accounts sharing a device or address with a player, those
+// sharing the most first
+// GET /api/admin/players/{id}/links
+func (h *AdminHandler) HandlePlayerLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.gameManager.LinkedAccounts(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"player_id": r.PathValue("id"),
+		"links":     links,
+	})
+}
+
+// HandleTournamentLinks lists the pairs of a tournament's entrants whose accounts share a
+// device or address
+// GET /api/admin/tournaments/{id}/links
+func (h *AdminHandler) HandleTournamentLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.gameManager.TournamentLinks(r.PathValue("id"))
+	switch {
+	case errors.Is(err, game.ErrLinksUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, game.ErrTournamentNotFound):
+		http.Error(w, "Tournament not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tournament_id": r.PathValue("id"),
+		"links":         links,
+	})
+}
+
+// HandleAnnouncement returns the announcement being shown, null when there is none
+// GET /api/admin/announcement
+func (h *AdminHandler) HandleAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var current *game.Announcement
+	if announcement, shown := h.gameManager.CurrentAnnouncement(); shown {
+		current = &announcement
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"announcement": current,
+	})
+}
+
+// HandleAnnounce broadcasts an announcement banner to every connected player and spectator
+// POST /api/admin/announcement with {"text": "...", "severity": "info"|"warning"|"critical", "expires_in": <seconds>}
+func (h *AdminHandler) HandleAnnounce(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text      string `json:"text"`
+		Severity  string `json:"severity"`
+		ExpiresIn int    `json:"expires_in"` // Seconds until the banner is hidden, 0 to keep it
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	announcement, err := h.gameManager.Announce(body.Text, body.Severity, time.Duration(body.ExpiresIn)*time.Second)
+	if err != nil {
+		http.Error(w, "Bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.recordAudit(r, audit.ActionAnnouncement, announcement.ID, true, map[string]any{
+		"text":       announcement.Text,
+		"severity":   announcement.Severity,
+		"expires_at": announcement.ExpiresAt,
+	})
+	writeJSON(w, http.StatusOK, announcement)
+}
+
+// HandleClearAnnouncement takes down the announcement being shown
+// DELETE /api/admin/announcement
+func (h *AdminHandler) HandleClearAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcement, shown := h.gameManager.ClearAnnouncement()
+	if !shown {
+		http.Error(w, "No announcement is shown", http.StatusNotFound)
+		return
+	}
+
+	h.recordAudit(r, audit.ActionAnnouncement, announcement.ID, true, map[string]any{
+		"cleared": true,
+		"text":    announcement.Text,
+	})
+	writeJSON(w, http.StatusOK, announcement)
+}
+
+// HandleMaintenance reports whether the instance drains for maintenance and the games it
+// still has to finish
+// GET /api/admin/maintenance
+func (h *AdminHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.gameManager.MaintenanceStatus())
+}
+
+// HandleStartMaintenance drains the instance for a restart
+// POST /api/admin/maintenance with an optional {"message": "..."} shown to refused players
+func (h *AdminHandler) HandleStartMaintenance(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Bad request: invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	status := h.gameManager.StartMaintenance(body.Message, adminActor(r))
+	h.recordAudit(r, audit.ActionMaintenance, "maintenance", true, map[string]any{
+		"enabled":      true,
+		"message":      body.Message,
+		"active_games": status.ActiveGames,
+	})
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HandleEndMaintenance lets the instance take new games and players again
+// DELETE /api/admin/maintenance
+func (h *AdminHandler) HandleEndMaintenance(w http.ResponseWriter, r *http.Request) {
+	status, ended := h.gameManager.EndMaintenance(adminActor(r))
+	if !ended {
+		http.Error(w, "Not in maintenance", http.StatusNotFound)
+		return
+	}
+	h.recordAudit(r, audit.ActionMaintenance, "maintenance", true, map[string]any{
+		"enabled": false,
+	})
+	writeJSON(w, http.StatusOK, status)
+}
+
+// adminActor returns the admin named in the X-Admin-Actor header
+func adminActor(r *http.Request) string {
+	if actor := r.Header.Get("X-Admin-Actor"); actor != "" {
+		return actor
+	}
+	return "admin"
+}
+
+// recordAudit records an admin action taken through the request
+func (h *AdminHandler) recordAudit(r *http.Request, action, target string, success bool, details map[string]any) {
+	actor := adminActor(r)
+	entry, err := h.auditLog.Record(audit.Entry{
+		Actor:      actor,
+		RemoteAddr: r.RemoteAddr,
+		Action:     action,
+		Target:     target,
+		Reason:     r.Header.Get("X-Admin-Reason"),
+		Success:    success,
+		Details:    details,
+	})
+	if err != nil {
+		slog.Error("Failed to persist audit entry", "action", action, "actor", actor, "error", err)
+	}
+	slog.Info("Admin action", "audit_id", entry.ID, "action", action, "actor", actor, "target", target, "success", success)
+}
+
+// writeJSON writes data as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("Failed to encode JSON response", "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bariiss/snake/backend/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// HandleStatsStream streams server statistics over a WebSocket, once per second by default
+// (broadcast.admin_stats_ms)
+// GET /api/admin/stats/ws
+// Messages sent by the client are ignored; the stream ends when either side closes it or the
+// server shuts down
+func (h *AdminHandler) HandleStatsStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Warn("Admin stats stream upgrade failed", "remote_addr", r.RemoteAddr, "error", err)
+		return
+	}
+	defer conn.Close()
+	slog.Info("Admin stats stream opened", "remote_addr", r.RemoteAddr)
+
+	// Reading is needed to process pings and notice when the dashboard goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(maxMessageSize)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(map[string]any{
+			"type": "server_stats",
+			"data": h.gameManager.Stats(),
+		}); err != nil {
+			slog.Info("Admin stats stream closed", "remote_addr", r.RemoteAddr, "error", err)
+			return
+		}
+
+		select {
+		case <-h.gameManager.Context().Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(writeWait))
+			slog.Info("Admin stats stream closed by shutdown", "remote_addr", r.RemoteAddr)
+			return
+		case <-closed:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+			slog.Info("Admin stats stream closed", "remote_addr", r.RemoteAddr)
+			return
+		case <-h.gameManager.Clock().After(statsInterval(h.gameManager)):
+		}
+	}
+}
+
+// statsInterval is how often the admin stats stream pushes a snapshot, read before every
+// snapshot so tuning applies to open streams
+func statsInterval(gm *game.Manager) time.Duration {
+	return time.Duration(gm.Config().Broadcast.AdminStatsMs) * time.Millisecond
+}
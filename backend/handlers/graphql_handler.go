@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bariiss/snake/backend/auth"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/graphql"
+	"github.com/bariiss/snake/backend/profile"
+	"github.com/bariiss/snake/backend/tournament"
+)
+
+// maxGraphQLBodySize bounds a posted GraphQL request
+const maxGraphQLBodySize = 64 * 1024
+
+// Page sizes of a player's match history
+const (
+	defaultMatchLimit = 10
+	maxMatchLimit     = profile.MatchHistorySize
+)
+
+// GraphQLHandler answers read-only GraphQL queries over players, games, match history,
+// leaderboards and tournament results, so community sites can fetch exactly the fields
+// they need in one request
+// Responses allow any origin, like the tournament results. A player's token is optional: it
+// lets a shadow-restricted player see themselves as everyone else sees unrestricted players.
+type GraphQLHandler struct {
+	gameManager *game.Manager
+	schema      *graphql.Schema // As seen by anonymous requests
+}
+
+func NewGraphQLHandler(gameManager *game.Manager) *GraphQLHandler {
+	return &GraphQLHandler{gameManager: gameManager, schema: newSchema(gameManager, "")}
+}
+
+// ServeHTTP runs a query given as query parameters or posted as JSON
+// GET /api/graphql?query=&variables=&operationName=
+// POST /api/graphql {"query": "...", "variables": {...}, "operationName": "..."}
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var request graphql.Request
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				http.Error(w, "Bad request: variables must be a JSON object", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBodySize)).Decode(&request); err != nil {
+			http.Error(w, "Bad request: body must be a JSON GraphQL request", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if request.Query == "" {
+		http.Error(w, "Bad request: query is required", http.StatusBadRequest)
+		return
+	}
+
+	schema := h.schema
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		tokenString, err := auth.ExtractTokenFromHeader(authHeader)
+		if err != nil {
+			http.Error(w, "Unauthorized: Invalid token format", http.StatusUnauthorized)
+			return
+		}
+		claims, err := auth.ValidateToken(tokenString)
+		if err != nil {
+			http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
+			return
+		}
+		schema = newSchema(h.gameManager, claims.PlayerID)
+	}
+
+	writeJSON(w, http.StatusOK, graphql.Execute(schema, request))
+}
+
+// playerRef is the source of the Player type: any player the server knows of, online or not
+type playerRef struct {
+	id       string
+	username string // As last seen
+}
+
+// hidden reports whether a player is left out of what the viewer sees: restricted players are
+// hidden from everyone but themselves, like in the lobby
+func hidden(details game.PlayerStats, viewer string) bool {
+	return details.Restricted && !details.Bot && details.ID != viewer
+}
+
+// newSchema declares the types of the GraphQL API and resolves them from the game manager,
+// as seen by the player with the viewer ID, or anonymously when it is empty
+func newSchema(gm *game.Manager, viewer string) *graphql.Schema {
+	player := &graphql.Object{Name: "Player"}
+	match := &graphql.Object{Name: "Match", Fields: map[string]*graphql.Field{
+		"game_id":           {},
+		"result":            {},
+		"played_at":         {},
+		"opponent_username": {},
+		"opponent": {Type: player, Resolve: func(source any, _ map[string]any) (any, error) {
+			round := source.(profile.Round)
+			return playerRef{id: round.OpponentID, username: round.OpponentUsername}, nil
+		}},
+	}}
+	title := &graphql.Object{Name: "Title", Fields: map[string]*graphql.Field{
+		"id":          {},
+		"name":        {},
+		"description": {},
+		"source":      {},
+		"earned_at":   {},
+	}}
+	singlePlayer := &graphql.Object{Name: "SinglePlayerStats", Fields: map[string]*graphql.Field{
+		"games_played":        {},
+		"best_score":          {},
+		"total_food":          {},
+		"longest_survival_ms": {},
+		"average_length":      {},
+	}}
+	player.Fields = map[string]*graphql.Field{
+		"id":       {Resolve: func(source any, _ map[string]any) (any, error) { return source.(playerRef).id, nil }},
+		"username": {Resolve: func(source any, _ map[string]any) (any, error) { return source.(playerRef).username, nil }},
+		"online": {Resolve: func(source any, _ map[string]any) (any, error) {
+			_, online := gm.DescribePlayer(source.(playerRef).id)
+			return online, nil
+		}},
+		"bot": {Resolve: func(source any, _ map[string]any) (any, error) {
+			details, _ := gm.DescribePlayer(source.(playerRef).id)
+			return details.Bot, nil
+		}},
+		"in_lobby": {Resolve: func(source any, _ map[string]any) (any, error) {
+			details, _ := gm.DescribePlayer(source.(playerRef).id)
+			return details.InLobby && !hidden(details, viewer), nil
+		}},
+		"games": {Resolve: func(source any, _ map[string]any) (any, error) {
+			details, _ := gm.DescribePlayer(source.(playerRef).id)
+			if details.Games == nil {
+				return []string{}, nil
+			}
+			return details.Games, nil
+		}},
+		"rating":        {Resolve: profileField(gm, "rating")},
+		"title":         {Type: title, Resolve: profileField(gm, "title")},
+		"titles":        {Type: title, Resolve: profileField(gm, "titles")},
+		"single_player": {Type: singlePlayer, Resolve: profileField(gm, "single_player")},
+		"rounds": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return gm.Record(source.(playerRef).id).Rounds, nil
+		}},
+		"wins": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return gm.Record(source.(playerRef).id).Wins, nil
+		}},
+		"win_streak": {Resolve: func(source any, _ map[string]any) (any, error) {
+			return gm.Record(source.(playerRef).id).WinStreak, nil
+		}},
+		"matches": {Type: match, Args: []string{"limit"}, Resolve: func(source any, args map[string]any) (any, error) {
+			limit, err := graphql.IntArg(args, "limit", defaultMatchLimit)
+			if err != nil {
+				return nil, err
+			}
+			if limit < 1 || limit > maxMatchLimit {
+				return nil, fmt.Errorf("limit must be between 1 and %d", maxMatchLimit)
+			}
+			history := gm.MatchHistory(source.(playerRef).id)
+			return history[:min(limit, len(history))], nil
+		}},
+	}
+
+	gameType := &graphql.Object{Name: "Game", Fields: map[string]*graphql.Field{
+		"id":         {},
+		"status":     {},
+		"mode":       {},
+		"rules":      {},
+		"players":    {},
+		"spectators": {},
+		"watchers":   {},
+	}}
+
+	score := &graphql.Object{Name: "Score", Fields: map[string]*graphql.Field{
+		"rank":     {},
+		"username": {},
+		"score":    {},
+		"set_at":   {},
+		"player": {Type: player, Resolve: func(source any, _ map[string]any) (any, error) {
+			score := source.(profile.HighScore)
+			return playerRef{id: score.PlayerID, username: score.Username}, nil
+		}},
+	}}
+	leaderboard := &graphql.Object{Name: "Leaderboard", Fields: map[string]*graphql.Field{
+		"variant":    {},
+		"difficulty": {},
+		"date":       {},
+		"resets_at":  {},
+		"scores":     {Type: score},
+	}}
+
+	placement := &graphql.Object{Name: "Placement", Fields: map[string]*graphql.Field{
+		"username":     {},
+		"finish":       {},
+		"seed":         {},
+		"rating":       {},
+		"disqualified": {},
+		"player": {Type: player, Resolve: func(source any, _ map[string]any) (any, error) {
+			placement := source.(tournament.Placement)
+			return playerRef{id: placement.PlayerID, username: placement.Username}, nil
+		}},
+	}}
+	matchPlayer := &graphql.Object{Name: "MatchPlayer", Fields: map[string]*graphql.Field{
+		"username": {},
+		"score":    {},
+		"player": {Type: player, Resolve: func(source any, _ map[string]any) (any, error) {
+			side := source.(tournament.MatchPlayer)
+			return playerRef{id: side.ID, username: side.Username}, nil
+		}},
+	}}
+	matchResult := &graphql.Object{Name: "MatchResult", Fields: map[string]*graphql.Field{
+		"id":      {},
+		"round":   {},
+		"players": {Type: matchPlayer},
+		"winner":  {},
+		"result":  {},
+	}}
+	tournamentResult := &graphql.Object{Name: "Tournament", Fields: map[string]*graphql.Field{
+		"id":             {},
+		"name":           {},
+		"format":         {},
+		"rules":          {},
+		"organizer_name": {},
+		"series":         {},
+		"started_at":     {},
+		"finished_at":    {},
+		"champion": {Type: placement, Resolve: func(source any, _ map[string]any) (any, error) {
+			if champion := source.(tournament.Result).Champion; champion != nil {
+				return *champion, nil
+			}
+			return nil, nil
+		}},
+		"placements": {Type: placement},
+		"rounds":     {Type: matchResult},
+	}}
+	tournamentSummary := &graphql.Object{Name: "TournamentSummary", Fields: map[string]*graphql.Field{
+		"id":          {},
+		"name":        {},
+		"format":      {},
+		"finished_at": {},
+		"players":     {},
+		"champion":    {},
+		"tournament": {Type: tournamentResult, Resolve: func(source any, _ map[string]any) (any, error) {
+			result, exists := gm.TournamentResult(source.(tournament.ResultSummary).ID)
+			if !exists {
+				return nil, nil
+			}
+			return result, nil
+		}},
+	}}
+	tournamentPage := &graphql.Object{Name: "TournamentPage", Fields: map[string]*graphql.Field{
+		"total":   {},
+		"results": {Type: tournamentSummary},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"players": {Type: player, Resolve: func(any, map[string]any) (any, error) {
+			players := []playerRef{}
+			for _, details := range gm.ListPlayers() {
+				if hidden(details, viewer) {
+					continue
+				}
+				players = append(players, playerRef{id: details.ID, username: details.Username})
+			}
+			return players, nil
+		}},
+		"player": {Type: player, Args: []string{"id"}, Resolve: func(_ any, args map[string]any) (any, error) {
+			id, err := graphql.StringArg(args, "id", "")
+			if err != nil {
+				return nil, err
+			}
+			details, exists := gm.DescribePlayer(id)
+			if !exists {
+				return nil, nil
+			}
+			return playerRef{id: details.ID, username: details.Username}, nil
+		}},
+		"games": {Type: gameType, Resolve: func(any, map[string]any) (any, error) {
+			return gm.ListGames(), nil
+		}},
+		"game": {Type: gameType, Args: []string{"id"}, Resolve: func(_ any, args map[string]any) (any, error) {
+			id, err := graphql.StringArg(args, "id", "")
+			if err != nil {
+				return nil, err
+			}
+			details, exists := gm.DescribeGame(id)
+			if !exists {
+				return nil, nil
+			}
+			return details, nil
+		}},
+		"leaderboard": {Type: leaderboard, Args: []string{"variant", "difficulty"}, Resolve: func(_ any, args map[string]any) (any, error) {
+			variant, err := graphql.StringArg(args, "variant", "")
+			if err != nil {
+				return nil, err
+			}
+			difficulty, err := graphql.StringArg(args, "difficulty", "")
+			if err != nil {
+				return nil, err
+			}
+			board, err := gm.Leaderboard(variant, difficulty)
+			if errors.Is(err, game.ErrLeaderboardsUnavailable) {
+				return nil, nil
+			}
+			return board, err
+		}},
+		"tournaments": {Type: tournamentPage, Args: []string{"limit", "offset"}, Resolve: func(_ any, args map[string]any) (any, error) {
+			limit, err := graphql.IntArg(args, "limit", defaultResultLimit)
+			if err != nil {
+				return nil, err
+			}
+			if limit < 1 || limit > maxResultLimit {
+				return nil, fmt.Errorf("limit must be between 1 and %d", maxResultLimit)
+			}
+			offset, err := graphql.IntArg(args, "offset", 0)
+			if err != nil {
+				return nil, err
+			}
+			if offset < 0 {
+				return nil, errors.New("offset must be non-negative")
+			}
+			results, total := gm.TournamentResults(limit, offset)
+			return map[string]any{"total": total, "results": results}, nil
+		}},
+		"tournament": {Type: tournamentResult, Args: []string{"id"}, Resolve: func(_ any, args map[string]any) (any, error) {
+			id, err := graphql.StringArg(args, "id", "")
+			if err != nil {
+				return nil, err
+			}
+			result, exists := gm.TournamentResult(id)
+			if !exists {
+				return nil, nil
+			}
+			return result, nil
+		}},
+	}}
+	return &graphql.Schema{Query: query}
+}
+
+// profileField resolves a field of a player's profile, as sent in the profile message
+func profileField(gm *game.Manager, name string) graphql.ResolveFunc {
+	return func(source any, _ map[string]any) (any, error) {
+		return gm.Profile(source.(playerRef).id)[name], nil
+	}
+}
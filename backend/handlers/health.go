@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/bariiss/snake/backend/game"
+)
+
+// ReadyHandler answers readiness checks of load balancers and orchestrators: 200 while the
+// instance takes new players, 503 while it drains for maintenance or shuts down
+// GET /readyz
+func ReadyHandler(gm *game.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := gm.MaintenanceStatus()
+		ready := !status.Enabled && gm.Context().Err() == nil
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, map[string]any{
+			"ready":        ready,
+			"maintenance":  status.Enabled,
+			"active_games": status.ActiveGames,
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/models"
+)
+
+const (
+	// pollWait is how long a poll waits for messages before returning an empty batch
+	pollWait = 25 * time.Second
+	// pollIdleTimeout closes a session that has neither polled nor sent for this long
+	pollIdleTimeout = 60 * time.Second
+	// maxPollBodySize bounds the commands posted in one request
+	maxPollBodySize = 16 * maxMessageSize
+)
+
+// pollRejectStatus maps the error codes of a refused connection to HTTP statuses
+var pollRejectStatus = map[string]int{
+	"INVALID_TOKEN":    http.StatusUnauthorized,
+	"INVALID_USERNAME": http.StatusBadRequest,
+	"USERNAME_EXISTS":  http.StatusConflict,
+	"SERVER_FULL":      http.StatusServiceUnavailable,
+}
+
+// LongPollHandler lets clients on networks that block WebSockets and WebRTC play over
+// plain HTTP requests: commands are posted, and server messages are fetched in batches
+// Each session is a player connection like a WebSocket's, so the game sends to it the same way.
+type LongPollHandler struct {
+	gameManager *game.Manager
+
+	mu       sync.Mutex
+	sessions map[string]*pollSession
+}
+
+func NewLongPollHandler(gameManager *game.Manager) *LongPollHandler {
+	return &LongPollHandler{
+		gameManager: gameManager,
+		sessions:    make(map[string]*pollSession),
+	}
+}
+
+// pollSession is one long-poll connection of a player
+// Batches are numbered by a cursor: a poll for the cursor before the last batch gets that
+// batch again, so a response lost on the way is not lost for good.
+type pollSession struct {
+	id      string
+	player  *models.Player
+	conn    *models.Connection
+	limiter *rateLimiter
+
+	mu          sync.Mutex // Guards everything below and serializes sends
+	cursor      int        // Cursor of the last batch with messages
+	last        [][]byte   // Last batch with messages, resent to a poll for the cursor before it
+	lastSeen    time.Time
+	rateLimited bool
+	cancelPoll  chan struct{} // Closed to end the waiting poll when a newer one arrives
+	pollMu      sync.Mutex    // Held by the poll collecting the next batch
+}
+
+// HandleConnect opens a session
+// POST /poll/connect
+// Authenticates like /ws: a token in the token query parameter or Authorization header, or
+// else a username for an initial login. Responds with the session ID and cursor 0; the first
+// batch starts with the connected message.
+func (h *LongPollHandler) HandleConnect(w http.ResponseWriter, r *http.Request) {
+	reject := func(code, message string) { writePollError(w, r, code, message) }
+
+	// The slot is held until the session closes
+	if !h.gameManager.AcquireConnection() {
+		reject("SERVER_FULL", "The server is at capacity. Please try again later.")
+		return
+	}
+	player, conn, token := connect(h.gameManager, r, "", reject)
+	if player == nil {
+		h.gameManager.ReleaseConnection()
+		return
+	}
+	attach(player, conn)
+
+	session := &pollSession{
+		id:       uuid.New().String(),
+		player:   player,
+		conn:     conn,
+		limiter:  newRateLimiter(h.gameManager.RateLimit, h.gameManager.Clock()),
+		lastSeen: h.gameManager.Clock().Now(),
+	}
+	h.mu.Lock()
+	h.sessions[session.id] = session
+	h.mu.Unlock()
+	go h.run(session)
+
+	connected, err := connectedMessage(h.gameManager, player, session.conn, token)
+	if err != nil {
+		session.conn.Close(models.CloseWriteFailed)
+		reject("", "")
+		return
+	}
+	// Nothing else can have been queued on the new connection yet
+	session.conn.Enqueue(connected, false)
+
+	// Check if player is in an active game and restore game state
+	h.gameManager.RestorePlayerGameState(player)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"session": session.id,
+		"cursor":  0,
+	})
+}
+
+// run keeps a session open until it is closed or goes idle, then tears it down
+func (h *LongPollHandler) run(session *pollSession) {
+	_, closed := openSession(h.gameManager, session.player, session.conn, "long_poll")
+	defer func() {
+		h.mu.Lock()
+		delete(h.sessions, session.id)
+		h.mu.Unlock()
+		closed()
+		h.gameManager.ReleaseConnection()
+	}()
+
+	ticker := h.gameManager.Clock().NewTicker(pollIdleTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-session.conn.Done():
+			return
+		case <-ticker.C():
+			if session.idleSince(h.gameManager.Clock().Now()) > pollIdleTimeout {
+				session.conn.Close(models.ClosePongTimeout)
+			}
+		}
+	}
+}
+
+// session looks up the session in the path, answering 410 Gone when it has closed
+func (h *LongPollHandler) session(w http.ResponseWriter, r *http.Request) *pollSession {
+	h.mu.Lock()
+	session := h.sessions[r.PathValue("id")]
+	h.mu.Unlock()
+	if session == nil {
+		writePollError(w, r, "SESSION_CLOSED", "Session closed, connect again with your token")
+	}
+	return session
+}
+
+// HandleSend handles the commands of a session
+// POST /poll/{id}/send
+// The body is one message as sent over the WebSocket, or several separated by newlines.
+// Responds 204 once they were handled.
+func (h *LongPollHandler) HandleSend(w http.ResponseWriter, r *http.Request) {
+	session := h.session(w, r)
+	if session == nil {
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPollBodySize))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	logger := session.player.Logger()
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.lastSeen = h.gameManager.Clock().Now()
+
+	for _, message := range bytes.Split(body, []byte{'\n'}) {
+		if len(bytes.TrimSpace(message)) == 0 {
+			continue
+		}
+		if session.conn.Closed() {
+			break
+		}
+		if !session.limiter.Allow() {
+			// Notify once per burst of dropped messages rather than once per message
+			if !session.rateLimited {
+				logger.Warn("Rate limit exceeded, dropping messages")
+				h.gameManager.SendError(session.player, "RATE_LIMITED", "Too many messages. Slow down.")
+				session.rateLimited = true
+			}
+			continue
+		}
+		session.rateLimited = false
+
+		msgData, err := jsonCodec{}.decode(message)
+		if err != nil {
+			logger.Warn("Failed to decode message", "error", err)
+			continue
+		}
+		msgType, ok := msgData["type"].(string)
+		if !ok {
+			logger.Warn("Message is missing the type field")
+			continue
+		}
+		h.gameManager.HandleWebSocketMessage(session.player, msgType, msgData)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlePoll returns the next batch of server messages of a session
+// GET /poll/{id}?cursor=N
+// cursor is the one of the last response, 0 at first. The poll waits up to 25 seconds for
+// messages and responds with the next cursor, the messages, possibly none, and closed, the
+// reason, once the server ended the session. Polling the previous cursor again returns the
+// same messages; only one poll per session waits at a time, a newer one ends the older one
+// with no messages.
+func (h *LongPollHandler) HandlePoll(w http.ResponseWriter, r *http.Request) {
+	session := h.session(w, r)
+	if session == nil {
+		return
+	}
+	cursor, err := strconv.Atoi(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	session.mu.Lock()
+	if session.cancelPoll != nil {
+		close(session.cancelPoll)
+	}
+	cancel := make(chan struct{})
+	session.cancelPoll = cancel
+	session.mu.Unlock()
+	defer session.endPoll(cancel, h.gameManager.Clock().Now)
+
+	session.pollMu.Lock()
+	defer session.pollMu.Unlock()
+
+	session.mu.Lock()
+	current, last := session.cursor, session.last
+	session.mu.Unlock()
+	switch cursor {
+	case current - 1:
+		// The last batch was lost on the way
+		writeBatchResponse(w, current, last, "")
+		return
+	case current:
+	default:
+		writeJSON(w, http.StatusConflict, map[string]any{
+			"type":    "error",
+			"code":    "INVALID_CURSOR",
+			"message": "Unknown cursor",
+			"cursor":  current,
+		})
+		return
+	}
+
+	var batch [][]byte
+	select {
+	case message := <-session.conn.Send:
+		batch = session.conn.Drain([][]byte{message})
+	case <-session.conn.FrameReady():
+		batch = session.conn.Drain(nil)
+	case <-session.conn.Done():
+		// Deliver what was sent before the session closed, such as a kick notice
+		batch = session.conn.Drain(nil)
+	case <-h.gameManager.Clock().After(pollWait):
+	case <-cancel:
+	case <-h.gameManager.Context().Done():
+		// Answer at once with no messages, as shutting down waits for open polls
+	case <-r.Context().Done():
+		return
+	}
+
+	// An empty batch keeps the cursor, so there is nothing to resend
+	if len(batch) > 0 {
+		session.mu.Lock()
+		session.cursor++
+		session.last = batch
+		current = session.cursor
+		session.mu.Unlock()
+	}
+
+	closedReason := ""
+	if session.conn.Closed() {
+		closedReason = session.conn.Reason()
+	}
+	writeBatchResponse(w, current, batch, closedReason)
+}
+
+// HandleClose closes a session
+// DELETE /poll/{id}
+func (h *LongPollHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	session := h.session(w, r)
+	if session == nil {
+		return
+	}
+	session.conn.Close(models.CloseClientClosed)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// endPoll marks the poll waiting on cancel as done
+func (s *pollSession) endPoll(cancel chan struct{}, now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelPoll == cancel {
+		s.cancelPoll = nil
+	}
+	s.lastSeen = now()
+}
+
+// idleSince reports how long ago the client last polled or sent, 0 while a poll waits
+func (s *pollSession) idleSince(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelPoll != nil {
+		return 0
+	}
+	return now.Sub(s.lastSeen)
+}
+
+// writeBatchResponse writes a batch of server messages with its cursor
+func writeBatchResponse(w http.ResponseWriter, cursor int, batch [][]byte, closedReason string) {
+	messages := make([]json.RawMessage, len(batch))
+	for i, message := range batch {
+		messages[i] = message
+	}
+	response := map[string]any{
+		"cursor":   cursor,
+		"messages": messages,
+	}
+	if closedReason != "" {
+		response["closed"] = closedReason
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writePollError writes an error like the ones sent over the WebSocket, in the language the
+// request asks for
+// An empty code refuses the request without an explanation.
+func writePollError(w http.ResponseWriter, r *http.Request, code, message string) {
+	if code == "" {
+		http.Error(w, "Connection refused", http.StatusBadRequest)
+		return
+	}
+	status, ok := pollRejectStatus[code]
+	if !ok {
+		status = http.StatusGone
+	}
+	writeJSON(w, status, map[string]any{
+		"type":    "error",
+		"code":    code,
+		"message": localizeError(r, code, message),
+	})
+}
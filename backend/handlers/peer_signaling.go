@@ -3,11 +3,11 @@ package handlers
 import (
 	"encoding/json"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
 
-	"snake-backend/game"
+	"github.com/bariiss/snake/backend/game"
 )
 
 // PeerSignalingHandler handles peer-to-peer WebRTC signaling
@@ -79,7 +79,7 @@ func (h *PeerSignalingHandler) HandlePeerOffer(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	log.Printf("Peer offer from %s to %s", offer.FromPlayerID, offer.ToPlayerID)
+	slog.Debug("Peer offer", "player_id", offer.FromPlayerID, "target_id", offer.ToPlayerID)
 
 	// Store offer
 	h.mutex.Lock()
@@ -118,7 +118,7 @@ func (h *PeerSignalingHandler) HandlePeerAnswer(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	log.Printf("Peer answer from %s to %s", answer.FromPlayerID, answer.ToPlayerID)
+	slog.Debug("Peer answer", "player_id", answer.FromPlayerID, "target_id", answer.ToPlayerID)
 
 	// Store answer
 	h.mutex.Lock()
@@ -157,7 +157,7 @@ func (h *PeerSignalingHandler) HandleICECandidate(w http.ResponseWriter, r *http
 		return
 	}
 
-	log.Printf("ICE candidate from %s to %s", candidate.FromPlayerID, candidate.ToPlayerID)
+	slog.Debug("ICE candidate", "player_id", candidate.FromPlayerID, "target_id", candidate.ToPlayerID)
 
 	// Forward ICE candidate to target player
 	h.gameManager.SendICECandidate(candidate.ToPlayerID, &candidate)
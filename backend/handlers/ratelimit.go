@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/bariiss/snake/backend/clock"
+	"github.com/bariiss/snake/backend/config"
+)
+
+// rateLimiter is a token bucket limiting how many messages a single connection may send
+// It is only used from the connection's read pump, so it needs no locking
+type rateLimiter struct {
+	limit  func() config.RateLimit // Current settings, read on every message so tuning applies to open connections
+	tokens float64
+	last   time.Time
+	clock  clock.Clock
+}
+
+// newRateLimiter creates a limiter following the configured rate; a rate of 0 disables limiting
+func newRateLimiter(limit func() config.RateLimit, clk clock.Clock) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		tokens: float64(max(limit().Burst, 1)),
+		last:   clk.Now(),
+		clock:  clk,
+	}
+}
+
+// Allow reports whether another message may be processed now
+func (l *rateLimiter) Allow() bool {
+	limit := l.limit()
+	if limit.MessagesPerSecond <= 0 {
+		return true
+	}
+
+	now := l.clock.Now()
+	l.tokens = min(float64(max(limit.Burst, 1)), l.tokens+now.Sub(l.last).Seconds()*limit.MessagesPerSecond)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bariiss/snake/backend/bitboard"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/metrics"
+)
+
+// subprotocols are the WebSocket subprotocols the server speaks, in order of preference,
+// advertised on rejection
+var subprotocols = []string{constants.SUBPROTOCOL_JSON_V2, constants.SUBPROTOCOL_JSON_V1, constants.SUBPROTOCOL_BITBOARD_V1}
+
+// batchWindow is how long a codec that batches per tick holds messages for the tick's game
+// update, so a message doesn't wait long for a tick that is late or never comes
+const batchWindow = 20 * time.Millisecond
+
+// codec reads client messages and writes batches of server messages on one connection
+// Server messages are serialized to JSON once for all recipients; a codec with another
+// encoding transcodes them when writing
+type codec interface {
+	decode(message []byte) (map[string]any, error)
+	writeBatch(conn *websocket.Conn, batch [][]byte) error
+	// version is the protocol version reported in the connected message
+	version() int
+	// perTick reports whether the write pump should hold messages until the tick's game
+	// update, up to batchWindow, so a tick's messages are written together; it only holds
+	// them while the player is in a running game
+	perTick() bool
+}
+
+// codecs maps each negotiated subprotocol to its codec
+// Clients that don't ask for a subprotocol are legacy clients speaking JSON v1
+var codecs = map[string]codec{
+	"":                                jsonCodec{},
+	constants.SUBPROTOCOL_JSON_V1:     jsonCodec{},
+	constants.SUBPROTOCOL_JSON_V2:     envelopeCodec{},
+	constants.SUBPROTOCOL_BITBOARD_V1: bitboardCodec{},
+}
+
+var batchSize = metrics.NewHistogramVec(
+	"snake_batch_messages",
+	"Server messages per frame written, by subprotocol (none for legacy clients)",
+	"subprotocol",
+	[]float64{1, 2, 4, 8, 16, 32, 64},
+)
+
+var connectionsOpened = metrics.NewCounterVec(
+	"snake_connections_opened_total",
+	"Client connections opened, by negotiated subprotocol (none for legacy clients)",
+	"subprotocol",
+)
+
+// subprotocolLabel is the metrics label of a negotiated subprotocol
+func subprotocolLabel(subprotocol string) string {
+	if subprotocol == "" {
+		return "none"
+	}
+	return subprotocol
+}
+
+// negotiateSubprotocol picks the first subprotocol offered in Sec-WebSocket-Protocol that
+// the server supports, the same one the upgrader selects
+// ok is false when the client offered subprotocols but none of them is supported
+func negotiateSubprotocol(r *http.Request) (subprotocol string, ok bool) {
+	offered := websocket.Subprotocols(r)
+	if len(offered) == 0 {
+		return "", true
+	}
+	for _, protocol := range offered {
+		if slices.Contains(subprotocols, protocol) {
+			return protocol, true
+		}
+	}
+	return "", false
+}
+
+// rejectSubprotocol refuses a handshake offering only unsupported subprotocols
+// The handshake fails before the upgrade, so the supported ones are listed in a header
+func rejectSubprotocol(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Supported-Subprotocols", strings.Join(subprotocols, ", "))
+	http.Error(w, "Unsupported WebSocket subprotocol, supported: "+strings.Join(subprotocols, ", "), http.StatusBadRequest)
+}
+
+// jsonCodec speaks JSON text frames; a frame may carry several newline-separated messages
+type jsonCodec struct{}
+
+func (jsonCodec) decode(message []byte) (map[string]any, error) {
+	var msg map[string]any
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeBatch writes messages as one newline-separated text frame
+func (jsonCodec) writeBatch(conn *websocket.Conn, batch [][]byte) error {
+	w, err := conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	for i, message := range batch {
+		if i > 0 {
+			w.Write([]byte{'\n'})
+		}
+		w.Write(message)
+	}
+	return w.Close()
+}
+
+func (jsonCodec) version() int { return 1 }
+
+func (jsonCodec) perTick() bool { return false }
+
+// envelopeCodec speaks JSON v1 messages wrapped in one batch envelope per text frame:
+// {"type":"batch","messages":[...]}, holding the messages of a tick for its game update
+type envelopeCodec struct {
+	jsonCodec
+}
+
+// envelopeStart opens a batch envelope; messages are already JSON, so they are spliced in
+// rather than encoded again
+var envelopeStart = []byte(`{"type":"` + constants.MSG_BATCH + `","messages":[`)
+
+// writeBatch writes messages as the elements of one envelope
+func (envelopeCodec) writeBatch(conn *websocket.Conn, batch [][]byte) error {
+	w, err := conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(envelopeStart)
+	for i, message := range batch {
+		if i > 0 {
+			w.Write([]byte{','})
+		}
+		w.Write(message)
+	}
+	w.Write([]byte("]}"))
+	return w.Close()
+}
+
+func (envelopeCodec) version() int { return 2 }
+
+func (envelopeCodec) perTick() bool { return true }
+
+// bitboardCodec speaks JSON v1 like jsonCodec, except that game updates are written as
+// binary frames holding a bitboard of the state, for constrained clients
+// The game queues game updates for these connections already laid out as bitboards, once
+// per broadcast; they are told apart from JSON messages, which are objects, by their first
+// byte, the bitboard version. Messages keep their order: the JSON messages before an update
+// are flushed first.
+type bitboardCodec struct {
+	jsonCodec
+}
+
+// isBoard reports whether a queued message is a bitboard rather than JSON
+func isBoard(message []byte) bool {
+	return len(message) > 0 && message[0] == bitboard.Version
+}
+
+// writeBatch writes runs of JSON messages as newline-separated text frames and each game
+// update as a binary frame
+func (c bitboardCodec) writeBatch(conn *websocket.Conn, batch [][]byte) error {
+	start := 0
+	for i, message := range batch {
+		if !isBoard(message) {
+			continue
+		}
+		if i > start {
+			if err := c.jsonCodec.writeBatch(conn, batch[start:i]); err != nil {
+				return err
+			}
+		}
+		if err := conn.WriteMessage(websocket.BinaryMessage, message); err != nil {
+			return err
+		}
+		start = i + 1
+	}
+	if start == len(batch) {
+		return nil
+	}
+	return c.jsonCodec.writeBatch(conn, batch[start:])
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bariiss/snake/backend/bitboard"
+	"github.com/bariiss/snake/backend/clock"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/models"
+)
+
+// frame is a WebSocket message as the client reads it
+type frame struct {
+	binary bool
+	data   []byte
+}
+
+// writeFrames writes a batch through a codec on a live connection and returns the frames
+// the client reads
+func writeFrames(t *testing.T, c codec, batch [][]byte) []frame {
+	t.Helper()
+	written := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			written <- err
+			return
+		}
+		defer conn.Close()
+		err = c.writeBatch(conn, batch)
+		if err == nil {
+			err = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		}
+		written <- err
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var frames []frame
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		frames = append(frames, frame{binary: kind == websocket.BinaryMessage, data: data})
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("writeBatch: %v", err)
+	}
+	return frames
+}
+
+func testBoard(tick int) []byte {
+	return bitboard.Encode(&engine.GameState{
+		Status: "playing",
+		Tick:   tick,
+		Snakes: []engine.Snake{{ID: "a", Body: []engine.Position{{X: 1, Y: 1}}, Direction: constants.RIGHT}},
+		Foods:  []engine.Food{{Position: engine.Position{X: 5, Y: 5}}},
+	})
+}
+
+func TestBitboardCodecWriteBatch(t *testing.T) {
+	chat := []byte(`{"type":"lounge_chat","text":"gg"}`)
+	emote := []byte(`{"type":"emote","emote":"wave"}`)
+	first, second := testBoard(7), testBoard(8)
+
+	frames := writeFrames(t, bitboardCodec{}, [][]byte{chat, first, emote, chat, second})
+	want := []frame{
+		{false, chat},
+		{true, first},
+		{false, append(append(append([]byte(nil), emote...), '\n'), chat...)},
+		{true, second},
+	}
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(want))
+	}
+	for i := range want {
+		if frames[i].binary != want[i].binary || !bytes.Equal(frames[i].data, want[i].data) {
+			t.Errorf("frame %d = binary %v %q, want binary %v %q", i, frames[i].binary, frames[i].data, want[i].binary, want[i].data)
+		}
+	}
+
+	board, err := bitboard.Decode(frames[3].data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if board.Tick != 8 {
+		t.Errorf("tick = %d, want 8", board.Tick)
+	}
+}
+
+func TestBitboardCodecJSONOnly(t *testing.T) {
+	update := []byte(`{"type":"game_update","data":{"tick":3}}`)
+	frames := writeFrames(t, bitboardCodec{}, [][]byte{update})
+	if len(frames) != 1 || frames[0].binary || !bytes.Equal(frames[0].data, update) {
+		t.Fatalf("frames = %+v, want the JSON update as one text frame", frames)
+	}
+}
+
+func TestJSONCodecWriteBatch(t *testing.T) {
+	batch := [][]byte{[]byte(`{"type":"a"}`), []byte(`{"type":"b"}`)}
+	frames := writeFrames(t, jsonCodec{}, batch)
+	if len(frames) != 1 || frames[0].binary || string(frames[0].data) != "{\"type\":\"a\"}\n{\"type\":\"b\"}" {
+		t.Fatalf("frames = %+v, want one newline-separated text frame", frames)
+	}
+}
+
+func TestEnvelopeCodecWriteBatch(t *testing.T) {
+	batch := [][]byte{[]byte(`{"type":"a","n":1}`), []byte(`{"type":"b"}`)}
+	frames := writeFrames(t, envelopeCodec{}, batch)
+	if len(frames) != 1 || frames[0].binary {
+		t.Fatalf("frames = %+v, want one text frame", frames)
+	}
+	var envelope struct {
+		Type     string           `json:"type"`
+		Messages []map[string]any `json:"messages"`
+	}
+	if err := json.Unmarshal(frames[0].data, &envelope); err != nil {
+		t.Fatalf("decode envelope %s: %v", frames[0].data, err)
+	}
+	if envelope.Type != constants.MSG_BATCH || len(envelope.Messages) != 2 || envelope.Messages[0]["type"] != "a" || envelope.Messages[1]["type"] != "b" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+// pumpSession runs the write pump of a new session on a live connection and returns the
+// session and the client's end
+func pumpSession(t *testing.T, c codec, inGame bool) (*models.Connection, *websocket.Conn) {
+	t.Helper()
+	gm := game.NewGameManager()
+	gm.SetClock(clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+	t.Cleanup(gm.Stop)
+	h := NewWebSocketHandler(gm)
+
+	session := models.NewConnection(16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		h.writePump(session, conn, c, func() bool { return inGame })
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		session.Close(models.CloseShutdown)
+		conn.Close()
+	})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return session, conn
+}
+
+// readEnvelope reads one envelope and returns the types of the messages in it
+func readEnvelope(t *testing.T, conn *websocket.Conn) []string {
+	t.Helper()
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope struct {
+		Messages []struct {
+			Type string `json:"type"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("decode envelope %s: %v", data, err)
+	}
+	types := make([]string, 0, len(envelope.Messages))
+	for _, msg := range envelope.Messages {
+		types = append(types, msg.Type)
+	}
+	return types
+}
+
+func TestWritePumpBatchesTick(t *testing.T) {
+	chat := []byte(`{"type":"emote","emote":"wave"}`)
+	update := []byte(`{"type":"game_update","data":{"tick":1}}`)
+
+	t.Run("in a running game", func(t *testing.T) {
+		session, conn := pumpSession(t, envelopeCodec{}, true)
+		session.Enqueue(chat, false)
+		// The pump has taken the chat message once nothing is pending; the tick's update
+		// then joins it
+		for session.Pending() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		session.Enqueue(update, true)
+		if types := readEnvelope(t, conn); !slices.Equal(types, []string{"emote", "game_update"}) {
+			t.Errorf("envelope holds %v, want the emote and the update together", types)
+		}
+	})
+
+	t.Run("outside a game", func(t *testing.T) {
+		session, conn := pumpSession(t, envelopeCodec{}, false)
+		session.Enqueue(chat, false)
+		if types := readEnvelope(t, conn); !slices.Equal(types, []string{"emote"}) {
+			t.Errorf("envelope holds %v, want the emote alone", types)
+		}
+	})
+}
+
+func TestCodecDecode(t *testing.T) {
+	for name, c := range map[string]codec{"json": jsonCodec{}, "envelope": envelopeCodec{}, "bitboard": bitboardCodec{}} {
+		msg, err := c.decode([]byte(`{"type":"move","direction":2}`))
+		if err != nil || msg["type"] != "move" {
+			t.Errorf("%s decode = %v, %v", name, msg, err)
+		}
+		if _, err := c.decode([]byte(`{"type":`)); err == nil {
+			t.Errorf("%s decode of malformed JSON succeeded", name)
+		}
+	}
+}
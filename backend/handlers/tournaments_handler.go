@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bariiss/snake/backend/game"
+)
+
+// Page sizes of the tournament results list
+const (
+	defaultResultLimit = 20
+	maxResultLimit     = 100
+)
+
+// TournamentsHandler serves the results of finished tournaments to anyone
+// Responses allow any origin so other sites can embed the outcomes.
+type TournamentsHandler struct {
+	gameManager *game.Manager
+}
+
+func NewTournamentsHandler(gameManager *game.Manager) *TournamentsHandler {
+	return &TournamentsHandler{gameManager: gameManager}
+}
+
+// HandleList lists finished tournaments, most recently finished first
+// GET /api/tournaments?limit=&offset=
+func (h *TournamentsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset := defaultResultLimit, 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxResultLimit {
+			http.Error(w, "Bad request: limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Bad request: offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	tournaments, total := h.gameManager.TournamentResults(limit, offset)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tournaments": tournaments,
+		"total":       total,
+	})
+}
+
+// HandleGet returns the placings and every match of a finished tournament
+// GET /api/tournaments/{id}
+func (h *TournamentsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	result, exists := h.gameManager.TournamentResult(r.PathValue("id"))
+	if !exists {
+		http.Error(w, "Tournament not found or not finished", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
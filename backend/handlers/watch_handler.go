@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bariiss/snake/backend/game"
+)
+
+// watchKeepAlive is how often an idle event stream gets a comment, so proxies keep it open
+const watchKeepAlive = 15 * time.Second
+
+// WatchHandler streams live games to read-only viewers over server-sent events, for those
+// behind proxies that break WebSockets
+type WatchHandler struct {
+	gameManager *game.Manager
+}
+
+func NewWatchHandler(gameManager *game.Manager) *WatchHandler {
+	return &WatchHandler{gameManager: gameManager}
+}
+
+// HandleEvents streams a game's frames as server-sent events
+// GET /api/games/{id}/events
+// Each event's data is a message as sent over the WebSocket: the current frame as a
+// game_update first, then the game's game_start, game_update and game_over messages. The
+// stream ends when the game is removed or the server shuts down.
+func (h *WatchHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	gameID := r.PathValue("id")
+	conn, gameDone, err := h.gameManager.WatchGame(gameID)
+	switch {
+	case errors.Is(err, game.ErrGameNotFound):
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	case errors.Is(err, game.ErrTooManyWatchers):
+		http.Error(w, "Too many viewers, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.gameManager.UnwatchGame(gameID, conn)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	// Keep buffering proxies such as nginx from holding frames back
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := h.gameManager.Clock().NewTicker(watchKeepAlive)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.gameManager.Context().Done():
+			return
+		case <-conn.Done():
+			slog.Info("Game watcher dropped", "game_id", gameID, "conn_id", conn.ID, "reason", conn.Reason())
+			return
+		case <-gameDone:
+			// Deliver the game_over and anything else sent before the game was removed
+			writeEvents(w, flusher, conn.Drain(batch[:0]))
+			return
+		case message := <-conn.Send:
+			batch = conn.Drain(append(batch[:0], message))
+			if err := writeEvents(w, flusher, batch); err != nil {
+				return
+			}
+		case <-conn.FrameReady():
+			batch = conn.Drain(batch[:0])
+			if err := writeEvents(w, flusher, batch); err != nil {
+				return
+			}
+		case <-ticker.C():
+			if _, err := w.Write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvents writes each message as the data of one server-sent event and flushes them
+// Messages are single-line JSON, so each fits in one data field.
+func writeEvents(w http.ResponseWriter, flusher http.Flusher, messages [][]byte) error {
+	for _, message := range messages {
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", message); err != nil {
+			return err
+		}
+	}
+	flusher.Flush()
+	return nil
+}
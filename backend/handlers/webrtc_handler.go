@@ -8,9 +8,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/pion/webrtc/v3"
 
-	"snake-backend/game"
-	"snake-backend/models"
-	webrtcManager "snake-backend/webrtc"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/models"
+	webrtcManager "github.com/bariiss/snake/backend/webrtc"
 )
 
 type WebRTCHandler struct {
@@ -71,8 +71,9 @@ func (h *WebRTCHandler) HandleOffer(w http.ResponseWriter, r *http.Request) {
 	player := &models.Player{
 		ID:       uuid.New().String(),
 		Username: offerData.Username,
-		Send:     make(chan []byte, 256),
 	}
+	// Messages reach this player over the data channel; the queue only marks it as connected
+	attach(player, newConnection(h.gameManager, r, ""))
 
 	peer, err := h.webrtcManager.CreatePeerConnection(player)
 	if err != nil {
@@ -2,17 +2,29 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
-	"snake-backend/auth"
-	"snake-backend/game"
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/auth"
+	"github.com/bariiss/snake/backend/features"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/i18n"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/names"
+	"github.com/bariiss/snake/backend/reporting"
 )
 
 const (
@@ -22,10 +34,25 @@ const (
 	maxMessageSize = 512
 )
 
+var (
+	connectionsClosed = metrics.NewCounterVec(
+		"snake_connections_closed_total",
+		"Client connections closed, by reason",
+		"reason",
+	)
+	connectionDuration = metrics.NewHistogramVec(
+		"snake_connection_duration_seconds",
+		"How long client connections stayed open, by close reason",
+		"reason",
+		[]float64{1, 5, 15, 60, 300, 900, 1800, 3600, 3 * 3600, 12 * 3600},
+	)
+)
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins in development
 	},
+	Subprotocols: subprotocols,
 }
 
 type WebSocketHandler struct {
@@ -38,124 +65,178 @@ func NewWebSocketHandler(gameManager *game.Manager) *WebSocketHandler {
 	}
 }
 
+// rejectFunc refuses a connection attempt with an error code and message; both are empty
+// when the attempt is refused without an explanation
+type rejectFunc func(code, message string)
+
 // sendErrorAndClose sends an error message and closes the connection
 func (h *WebSocketHandler) sendErrorAndClose(w http.ResponseWriter, r *http.Request, code, message string) {
 	conn, _ := upgrader.Upgrade(w, r, nil)
 	if conn == nil {
 		return
 	}
+	if code == "" {
+		conn.Close()
+		return
+	}
 	errorMsg := map[string]any{
 		"type":    "error",
 		"code":    code,
-		"message": message,
+		"message": localizeError(r, code, message),
+	}
+	jsonError, err := json.Marshal(errorMsg)
+	if err != nil {
+		reporting.Error("Failed to encode connection error", err, "code", code)
 	}
-	jsonError, _ := json.Marshal(errorMsg)
-	conn.WriteMessage(websocket.TextMessage, jsonError)
+	// The upgrader selected the subprotocol already, so the error is framed like any message
+	codecs[conn.Subprotocol()].writeBatch(conn, [][]byte{jsonError})
 	conn.Close()
 }
 
-// handleTokenConnection handles token-based connection
-func (h *WebSocketHandler) handleTokenConnection(tokenString string, w http.ResponseWriter, r *http.Request) (*models.Player, string) {
+// connectWithToken finds or registers the player of a token resuming their session
+// Shared by every transport; reject tells the client why it was refused.
+func connectWithToken(gm *game.Manager, tokenString string, r *http.Request, reject rejectFunc) (*models.Player, string) {
 	// Validate token
 	claims, err := auth.ValidateToken(tokenString)
 	if err != nil {
-		log.Printf("Token validation error: %v", err)
-		h.sendErrorAndClose(w, r, "INVALID_TOKEN", "Invalid token")
+		slog.Warn("Token validation failed", "error", err)
+		reject("INVALID_TOKEN", "Invalid token")
+		return nil, ""
+	}
+
+	// A kick revokes the player's token until its cooldown is over
+	if _, kicked := gm.KickedPlayer(claims.PlayerID); kicked {
+		slog.Warn("Kicked player tried to reconnect", "player_id", claims.PlayerID)
+		reject("KICKED", "You were removed from the server by an admin; try again later")
+		return nil, ""
+	}
+	if _, banned := gm.BannedPlayer(claims.PlayerID); banned {
+		slog.Warn("Banned player tried to reconnect", "player_id", claims.PlayerID)
+		reject("BANNED", "You are banned from the server")
 		return nil, ""
 	}
 
 	// Find player by ID from token
-	player := h.gameManager.FindPlayerByID(claims.PlayerID)
+	player := gm.FindPlayerByID(claims.PlayerID)
 	if player == nil {
 		// Player not found - create new player from token claims
 		// This can happen if server restarted or player was removed
-		log.Printf("Player not found for token, creating new player: %s (username: %s)", claims.PlayerID, claims.Username)
+		slog.Info("Player not found for token, creating new player", "player_id", claims.PlayerID, "username", claims.Username)
 		player = &models.Player{
 			ID:       claims.PlayerID,
 			Username: claims.Username,
-			Send:     make(chan []byte, 256),
-			JoinedAt: time.Now(),
+			JoinedAt: gm.Clock().Now(),
+			Bot:      isBot(r),
 		}
 
 		// Register player in global registry
-		h.gameManager.Mutex.Lock()
-		h.gameManager.Players[player.ID] = player
-		h.gameManager.Mutex.Unlock()
-	}
-
-	// If player already has an active connection, close it but DON'T remove player
-	// This allows the new connection to use the same player object
-	if player.Send == nil {
-		// No existing connection, create new channel
-		player.Send = make(chan []byte, 256)
-		return player, tokenString
-	}
-
-	// Player already has an active connection, close it
-	log.Printf("Player %s already has active connection, closing old connection", player.ID)
-	// Close old channel to signal old connection to stop
-	// Use recover to handle case where channel is already closed
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("Channel already closed for player %s (ignored): %v", player.ID, r)
-			}
-		}()
-		close(player.Send)
-	}()
-	// Don't call RemovePlayer here - we want to keep the player for the new connection
-	// Just wait a bit for the old connection to clean up
-	time.Sleep(100 * time.Millisecond)
-	// Recreate Send channel for new connection
-	player.Send = make(chan []byte, 256)
+		gm.RegisterPlayer(player)
+	}
 
 	return player, tokenString
 }
 
-// handleUsernameConnection handles username-based connection (for initial login)
-func (h *WebSocketHandler) handleUsernameConnection(r *http.Request, w http.ResponseWriter) (*models.Player, string) {
+// newConnection returns a fresh outbound connection speaking subprotocol, with messages in
+// the language the request asks for
+func newConnection(gm *game.Manager, r *http.Request, subprotocol string) *models.Connection {
+	conn := models.NewConnection(gm.Limits().SendQueueSize)
+	conn.Subprotocol = subprotocol
+	conn.Language = requestLanguage(r)
+	return conn
+}
+
+// attach makes session the player's connection, closing the one it replaces
+// A player who already has an active connection keeps the same player object on the new
+// one. Transports attach once the client can be written to, so a connection that fails to
+// open leaves the player's current one in place.
+func attach(player *models.Player, session *models.Connection) {
+	hadConnection := player.Connected()
+	old := player.Attach(session)
+	if old == nil {
+		return
+	}
+	if hadConnection {
+		player.Logger().Info("Player already has an active connection, closing old connection")
+	}
+	// Only the old write pump closes the old socket; the player keeps the new connection
+	old.Close(models.CloseReplaced)
+}
+
+// requestLanguage returns the language of the messages a connect request asks for, with its
+// lang parameter or else its Accept-Language header
+func requestLanguage(r *http.Request) string {
+	return i18n.Negotiate(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+}
+
+// localizeError returns the message of a refused request translated into the language it
+// asks for
+func localizeError(r *http.Request, code, message string) string {
+	if text, ok := i18n.Message(requestLanguage(r), code, nil); ok {
+		return text
+	}
+	return message
+}
+
+// usernameErrorMessage explains to the player why names.Normalize refused their username
+func usernameErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, names.ErrTooShort), errors.Is(err, names.ErrTooLong):
+		return fmt.Sprintf("Username must be %d to %d characters wide.", names.MinWidth, names.MaxWidth)
+	default:
+		return "Username may only contain letters, digits, punctuation, symbols and spaces."
+	}
+}
+
+// connectWithUsername registers a new player for an initial login and generates their token
+// Shared by every transport; reject tells the client why it was refused.
+func connectWithUsername(gm *game.Manager, r *http.Request, reject rejectFunc) (*models.Player, string) {
 	username := r.URL.Query().Get("username")
 	if username == "" {
 		username = r.Header.Get("X-Username")
 	}
 
 	if username == "" {
-		log.Printf("No username or token provided, closing connection")
-		conn, _ := upgrader.Upgrade(w, r, nil)
-		if conn == nil {
-			return nil, ""
-		}
-		conn.Close()
+		slog.Warn("No username or token provided, closing connection", "remote_addr", r.RemoteAddr)
+		reject("", "")
 		return nil, ""
 	}
 
-	username = strings.TrimSpace(username)
+	username, err := names.Normalize(username)
+	if err != nil {
+		slog.Warn("Invalid username, closing connection", "remote_addr", r.RemoteAddr, "error", err)
+		reject("INVALID_USERNAME", usernameErrorMessage(err))
+		return nil, ""
+	}
+
+	if _, kicked := gm.KickedUsername(username); kicked {
+		slog.Warn("Kicked username tried to log in", "username", username, "remote_addr", r.RemoteAddr)
+		reject("KICKED", "You were removed from the server by an admin; try again later")
+		return nil, ""
+	}
+	if _, banned := gm.BannedUsername(username); banned {
+		slog.Warn("Banned username tried to log in", "username", username, "remote_addr", r.RemoteAddr)
+		reject("BANNED", "You are banned from the server")
+		return nil, ""
+	}
 
 	// Check if username already exists and disconnect old connection if same username
-	existingPlayer := h.gameManager.FindPlayerByUsername(username)
-	if existingPlayer == nil {
-		// No existing player, continue
-	} else if existingPlayer.Send != nil {
+	// A name that only looks like the connected one is refused below rather than taking it over.
+	existingPlayer := gm.FindPlayerByUsername(username)
+	if existingPlayer == nil || existingPlayer.Username != username {
+		// No existing player, or an impostor of one; continue
+	} else if conn := existingPlayer.Connection(); conn != nil {
 		// Same username is already connected - close old connection
-		log.Printf("Username %s already connected, closing old connection (old ID: %s)", username, existingPlayer.ID)
-		// Use recover to handle case where channel is already closed
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("Channel already closed for player %s (ignored): %v", existingPlayer.ID, r)
-				}
-			}()
-			close(existingPlayer.Send)
-		}()
-		existingPlayer.Send = nil
-		h.gameManager.RemovePlayer(existingPlayer.ID)
-		time.Sleep(50 * time.Millisecond)
+		existingPlayer.Logger().Info("Username already connected, closing old connection")
+		conn.Close(models.CloseReplaced)
+		existingPlayer.Detach()
+		gm.RemovePlayer(existingPlayer.ID)
+		gm.Clock().Sleep(50 * time.Millisecond)
 	}
 
 	// Check again if username exists (after cleanup)
-	if h.gameManager.UsernameExists(username) {
-		log.Printf("Username %s still in use after cleanup, closing connection", username)
-		h.sendErrorAndClose(w, r, "USERNAME_EXISTS", "Username already in use. Please choose another name.")
+	if gm.UsernameExists(username) {
+		slog.Warn("Username still in use after cleanup, closing connection", "username", username)
+		reject("USERNAME_EXISTS", "Username already in use. Please choose another name.")
 		return nil, ""
 	}
 
@@ -163,32 +244,97 @@ func (h *WebSocketHandler) handleUsernameConnection(r *http.Request, w http.Resp
 	player := &models.Player{
 		ID:       uuid.New().String(),
 		Username: username,
-		Send:     make(chan []byte, 256),
-		JoinedAt: time.Now(),
+		JoinedAt: gm.Clock().Now(),
+		Bot:      isBot(r),
 	}
 
 	// Register player in global registry
-	h.gameManager.Mutex.Lock()
-	h.gameManager.Players[player.ID] = player
-	h.gameManager.Mutex.Unlock()
+	gm.RegisterPlayer(player)
 
 	// Generate token for new player
 	token, err := auth.GenerateToken(player.ID, player.Username)
 	if err != nil {
-		log.Printf("Error generating token: %v", err)
-		conn, _ := upgrader.Upgrade(w, r, nil)
-		if conn == nil {
-			return nil, ""
-		}
-		conn.Close()
+		player.Logger().Error("Failed to generate token", "error", err)
+		reject("", "")
 		return nil, ""
 	}
 
 	return player, token
 }
 
+// isBot reports whether the client opted in to being shown as a bot with ?bot=true
+func isBot(r *http.Request) bool {
+	bot, _ := strconv.ParseBool(r.URL.Query().Get("bot"))
+	return bot
+}
+
+// connect authenticates a connection attempt with a token, from the query or the
+// Authorization header, or else with a username for an initial login
+// Returns the player, a connection speaking subprotocol for the transport to attach once it
+// is open, and their token; a nil player once the attempt was rejected.
+func connect(gm *game.Manager, r *http.Request, subprotocol string, reject rejectFunc) (*models.Player, *models.Connection, string) {
+	tokenString := extractTokenFromRequest(r, reject)
+	// An Authorization header without a token has already been rejected
+	if tokenString == "" && r.Header.Get("Authorization") != "" {
+		return nil, nil, ""
+	}
+	var player *models.Player
+	var token string
+	if tokenString != "" {
+		player, token = connectWithToken(gm, tokenString, r, reject)
+	} else {
+		// Legacy: username-based connection (for initial login)
+		player, token = connectWithUsername(gm, r, reject)
+	}
+	if player == nil {
+		return nil, nil, ""
+	}
+	gm.ObserveConnection(player, connectionSignals(gm, r))
+	return player, newConnection(gm, r, subprotocol), token
+}
+
+// maxDeviceIDLength is the longest device ID a client may send; longer ones are ignored
+const maxDeviceIDLength = 128
+
+// connectionSignals returns the signals a connect request leaves that can tie accounts to
+// one person: the device ID the client sends with its device parameter or X-Device-ID
+// header, and the address it came from
+// Behind a reverse proxy, with TRUST_PROXY set, the address is the one the proxy forwards.
+func connectionSignals(gm *game.Manager, r *http.Request) map[string]string {
+	device := r.URL.Query().Get("device")
+	if device == "" {
+		device = r.Header.Get("X-Device-ID")
+	}
+	if len(device) > maxDeviceIDLength {
+		device = ""
+	}
+	return map[string]string{
+		moderation.SignalDevice:  device,
+		moderation.SignalAddress: clientAddress(r, gm.Config().TrustProxy),
+	}
+}
+
+// clientAddress returns the IP address a request came from, taken from the headers set by a
+// trusted reverse proxy when trustProxy is set
+func clientAddress(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); real != "" {
+			return real
+		}
+		if forwarded, _, _ := strings.Cut(r.Header.Get("X-Forwarded-For"), ","); strings.TrimSpace(forwarded) != "" {
+			return strings.TrimSpace(forwarded)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // extractTokenFromRequest extracts token from query parameter or Authorization header
-func (h *WebSocketHandler) extractTokenFromRequest(r *http.Request, w http.ResponseWriter) string {
+// An Authorization header without a bearer token is rejected, and "" returned.
+func extractTokenFromRequest(r *http.Request, reject rejectFunc) string {
 	tokenString := r.URL.Query().Get("token")
 	if tokenString != "" {
 		return tokenString
@@ -202,8 +348,8 @@ func (h *WebSocketHandler) extractTokenFromRequest(r *http.Request, w http.Respo
 	var err error
 	tokenString, err = auth.ExtractTokenFromHeader(authHeader)
 	if err != nil {
-		log.Printf("Invalid authorization header: %v", err)
-		h.sendErrorAndClose(w, r, "INVALID_TOKEN", "Invalid or missing token")
+		slog.Warn("Invalid authorization header", "error", err)
+		reject("INVALID_TOKEN", "Invalid or missing token")
 		return ""
 	}
 
@@ -211,58 +357,53 @@ func (h *WebSocketHandler) extractTokenFromRequest(r *http.Request, w http.Respo
 }
 
 func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Try to get token from query parameter or Authorization header
-	tokenString := h.extractTokenFromRequest(r, w)
-	// If extractTokenFromRequest returns "" and there was an Authorization header,
-	// it already sent an error and closed the connection, so we can return
-	if tokenString == "" && r.Header.Get("Authorization") != "" {
+	// Clients offering subprotocols must share one with the server
+	subprotocol, ok := negotiateSubprotocol(r)
+	if !ok {
+		slog.Warn("Rejected connection with unsupported subprotocols", "remote_addr", r.RemoteAddr,
+			"offered", websocket.Subprotocols(r))
+		rejectSubprotocol(w, r)
 		return
 	}
 
-	// If no token, fall back to username-based connection (for initial login)
-	var player *models.Player
-	var token string
-
-	if tokenString != "" {
-		player, token = h.handleTokenConnection(tokenString, w, r)
-		if player == nil {
-			return
-		}
+	// Reserve a connection slot for the lifetime of this request
+	if !h.gameManager.AcquireConnection() {
+		slog.Warn("Connection limit reached, rejecting connection", "remote_addr", r.RemoteAddr)
+		h.sendErrorAndClose(w, r, "SERVER_FULL", "The server is at capacity. Please try again later.")
+		return
 	}
-	if tokenString == "" {
-		// Legacy: username-based connection (for initial login)
-		player, token = h.handleUsernameConnection(r, w)
-		if player == nil {
-			return
-		}
+	defer h.gameManager.ReleaseConnection()
+
+	reject := func(code, message string) { h.sendErrorAndClose(w, r, code, message) }
+	player, session, token := connect(h.gameManager, r, subprotocol, reject)
+	if player == nil {
+		return
 	}
 
 	// Upgrade connection after all checks
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		player.Logger().Error("WebSocket upgrade failed", "error", err)
 		return
 	}
+	attach(player, session)
+	codec := codecs[session.Subprotocol]
 
 	// Don't add player to lobby automatically - wait for join_lobby message
 	// This allows frontend to show mode selection first
 
 	// Send connected message with token directly via WebSocket (before writePump starts)
 	// This ensures the message is sent immediately after connection is established
-	connectedMsg := map[string]any{
-		"type": "connected",
-		"player": map[string]any{
-			"id":       player.ID,
-			"username": player.Username,
-		},
-		"token": token,
+	jsonData, err := connectedMessage(h.gameManager, player, session, token)
+	if err != nil {
+		conn.Close()
+		return
 	}
-	jsonData, _ := json.Marshal(connectedMsg)
 
 	// Send directly via WebSocket connection to ensure it's sent immediately
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
-	if err := conn.WriteMessage(websocket.TextMessage, jsonData); err != nil {
-		log.Printf("Failed to send connected message to player %s: %v", player.Username, err)
+	if err := writeBatch(conn, session, codec, [][]byte{jsonData}); err != nil {
+		player.Logger().Error("Failed to send connected message", "error", err)
+		reportWriteError(err, session)
 		conn.Close()
 		return
 	}
@@ -271,23 +412,60 @@ func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.gameManager.RestorePlayerGameState(player)
 
 	// Start goroutines for reading and writing
-	go h.writePump(player, conn)
-	h.readPump(player, conn)
+	go h.writePump(session, conn, codec, func() bool { return h.gameManager.InRunningGame(player.ID) })
+	h.readPump(player, session, conn, codec)
+}
+
+// connectedMessage builds the first message of a connection: the player, their token and
+// profile, and the server's settings
+func connectedMessage(gm *game.Manager, player *models.Player, session *models.Connection, token string) ([]byte, error) {
+	connectedMsg := map[string]any{
+		"type": "connected",
+		"player": map[string]any{
+			"id":       player.ID,
+			"username": player.Username,
+		},
+		"conn_id":          session.ID,
+		"token":            token,
+		"features":         features.Snapshot(),
+		"emotes":           game.Emotes,
+		"muted":            gm.MutedUsernames(player.ID),
+		"profile":          gm.Profile(player.ID),
+		"protocol_version": codecs[session.Subprotocol].version(),
+		"server": map[string]any{
+			"instance_id": gm.Config().InstanceID,
+			"region":      gm.Config().Region,
+		},
+	}
+	if session.Subprotocol != "" {
+		connectedMsg["subprotocol"] = session.Subprotocol
+	}
+	if announcement, shown := gm.CurrentAnnouncement(); shown {
+		connectedMsg["announcement"] = announcement
+	}
+	if clan, inClan := gm.Clan(player.ID); inClan {
+		connectedMsg["clan"] = clan
+	}
+	jsonData, err := json.Marshal(connectedMsg)
+	if err != nil {
+		player.Logger().Error("Failed to encode connected message", "error", err)
+		reporting.Error("Failed to encode connected message", err, "player_id", player.ID)
+		return nil, err
+	}
+	return jsonData, nil
 }
 
-func (h *WebSocketHandler) readPump(player *models.Player, conn *websocket.Conn) {
+func (h *WebSocketHandler) readPump(player *models.Player, session *models.Connection, conn *websocket.Conn, codec codec) {
+	logger, closed := openSession(h.gameManager, player, session, "websocket")
 	defer func() {
-		// Only remove player if Send channel is nil (no new connection established)
-		// If Send channel is still active, a new connection is being established
-		// and we should not remove the player
-		if player.Send == nil {
-			h.gameManager.RemovePlayer(player.ID)
-		} else {
-			log.Printf("Player %s (%s) has new connection, not removing from manager", player.ID, player.Username)
-		}
+		session.Close(models.CloseClientGone)
+		closed()
 		conn.Close()
 	}()
 
+	limiter := newRateLimiter(h.gameManager.RateLimit, h.gameManager.Clock())
+	rateLimited := false
+
 	conn.SetReadDeadline(time.Now().Add(pongWait))
 	conn.SetReadLimit(maxMessageSize)
 	conn.SetPongHandler(func(string) error {
@@ -297,67 +475,200 @@ func (h *WebSocketHandler) readPump(player *models.Player, conn *websocket.Conn)
 
 	for {
 		_, message, err := conn.ReadMessage()
+		if session.Closed() {
+			break
+		}
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error for %s: %v", player.Username, err)
+				logger.Warn("WebSocket read failed", "error", err)
 			}
+			session.Close(readCloseReason(err))
 			break
 		}
 
-		var msgData map[string]any
-		if err := json.Unmarshal(message, &msgData); err != nil {
-			log.Printf("Error unmarshaling message from %s: %v", player.Username, err)
+		if !limiter.Allow() {
+			// Notify once per burst of dropped messages rather than once per message
+			if !rateLimited {
+				logger.Warn("Rate limit exceeded, dropping messages")
+				h.gameManager.SendError(player, "RATE_LIMITED", "Too many messages. Slow down.")
+				rateLimited = true
+			}
+			continue
+		}
+		rateLimited = false
+
+		msgData, err := codec.decode(message)
+		if err != nil {
+			logger.Warn("Failed to decode message", "error", err)
 			continue
 		}
 
 		msgType, ok := msgData["type"].(string)
 		if !ok {
-			log.Printf("Message from %s missing type field", player.Username)
+			logger.Warn("Message is missing the type field")
 			continue
 		}
 
+		gameID, _ := msgData["game_id"].(string)
+		logger.Debug("Message received", "msg_type", msgType, "game_id", gameID)
+
 		// Handle message through game manager
 		h.gameManager.HandleWebSocketMessage(player, msgType, msgData)
 	}
 }
 
-func (h *WebSocketHandler) writePump(player *models.Player, conn *websocket.Conn) {
-	ticker := time.NewTicker(pingPeriod)
+// openSession records a session of the player opening over transport
+// Returns the session's logger, tagged with its ID even after the player moves to a newer
+// connection, and the function that records it closing once it has been closed.
+func openSession(gm *game.Manager, player *models.Player, session *models.Connection, transport string) (*slog.Logger, func()) {
+	logger := slog.With("player_id", player.ID, "username", player.Username, "conn_id", session.ID)
+	logger.Info("Connection opened", "subprotocol", session.Subprotocol, "transport", transport)
+	connectionsOpened.Inc(subprotocolLabel(session.Subprotocol))
+	openedAt := gm.Clock().Now()
+
+	return logger, func() {
+		reason, lifetime := session.Reason(), gm.Clock().Since(openedAt)
+		connectionsClosed.Inc(reason)
+		connectionDuration.Observe(reason, lifetime.Seconds())
+		logger.Info("Connection closed", "reason", reason, "duration", lifetime.Round(time.Millisecond))
+		gm.RecordDisconnect(player, session.ID, reason, lifetime)
+		// Only remove the player if this was still their connection; a player who
+		// reconnected in the meantime is attached to the new one
+		if player.Release(session) {
+			gm.RemovePlayer(player.ID)
+		} else {
+			logger.Info("Player has a new connection, not removing from manager")
+		}
+	}
+}
+
+// readCloseReason classifies the error that ended the read pump
+func readCloseReason(err error) string {
+	var closeErr *websocket.CloseError
+	switch {
+	case errors.As(err, &closeErr) && closeErr.Code != websocket.CloseAbnormalClosure:
+		// 1006 is reported locally when the socket drops without a close frame
+		return models.CloseClientClosed
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return models.ClosePongTimeout
+	case errors.Is(err, websocket.ErrReadLimit):
+		return models.CloseTooLarge
+	}
+	return models.CloseClientGone
+}
+
+// writePump drains the session's outbound queue to the socket
+// It is the only place a connection is torn down: once the session is closed it sends a
+// close frame and closes the socket, which also ends the read pump. inGame reports whether
+// the player is in a running game, whose ticks a per-tick codec holds messages for.
+func (h *WebSocketHandler) writePump(session *models.Connection, conn *websocket.Conn, codec codec, inGame func() bool) {
+	// Socket deadlines are compared against the wall clock, so only the ping schedule uses the game clock
+	ticker := h.gameManager.Clock().NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		conn.Close()
 	}()
 
+	var batch [][]byte
+	// Holds a tick's messages for its game update, for codecs that batch per tick
+	window := time.NewTimer(batchWindow)
+	window.Stop()
+	defer window.Stop()
+
 	for {
 		select {
-		case message, ok := <-player.Send:
+		case <-session.Done():
 			conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
+			// Deliver what was sent before a kick, such as the kicked message
+			if session.Reason() == models.CloseKicked {
+				if batch = session.Drain(batch[:0]); len(batch) > 0 {
+					writeBatch(conn, session, codec, batch)
+				}
 			}
-
-			w, err := conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+			conn.WriteMessage(websocket.CloseMessage, closeMessage(session.Reason()))
+			return
+		case message := <-session.Send:
+			if codec.perTick() && inGame() {
+				awaitFrame(session, window)
+			}
+			// Add queued messages and the latest frame
+			batch = session.Drain(append(batch[:0], message))
+			if err := writeBatch(conn, session, codec, batch); err != nil {
+				reportWriteError(err, session)
+				session.Close(models.CloseWriteFailed)
 				return
 			}
-			w.Write(message)
-
-			// Add queued messages
-			n := len(player.Send)
-			for range n {
-				w.Write([]byte{'\n'})
-				w.Write(<-player.Send)
+		case <-session.FrameReady():
+			batch = session.Drain(batch[:0])
+			if len(batch) == 0 {
+				continue
 			}
-
-			if err := w.Close(); err != nil {
+			if err := writeBatch(conn, session, codec, batch); err != nil {
+				reportWriteError(err, session)
+				session.Close(models.CloseWriteFailed)
 				return
 			}
-		case <-ticker.C:
+		case <-ticker.C():
 			conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				reportWriteError(err, session)
+				session.Close(models.CloseWriteFailed)
 				return
 			}
 		}
 	}
 }
+
+// awaitFrame waits for the current tick's game update to be enqueued, up to batchWindow, so
+// the messages enqueued before it are written in the same batch
+// Returns early when the session closes, and at the end of the window when the tick is late.
+func awaitFrame(session *models.Connection, window *time.Timer) {
+	window.Reset(batchWindow)
+	defer window.Stop()
+	select {
+	case <-session.FrameReady():
+	case <-window.C:
+	case <-session.Done():
+	}
+}
+
+// writeBatch writes queued messages through the connection's codec
+func writeBatch(conn *websocket.Conn, session *models.Connection, codec codec, batch [][]byte) error {
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := codec.writeBatch(conn, batch); err != nil {
+		return err
+	}
+	batchSize.Observe(subprotocolLabel(session.Subprotocol), float64(len(batch)))
+	return nil
+}
+
+// reportWriteError reports a failed socket write unless the client simply went away
+// Closed, reset and timed out connections are routine on the public internet
+func reportWriteError(err error, session *models.Connection) {
+	if errors.Is(err, websocket.ErrCloseSent) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, os.ErrDeadlineExceeded) {
+		return
+	}
+	connID := ""
+	if session != nil {
+		connID = session.ID
+	}
+	reporting.Error("WebSocket write failed", err, "conn_id", connID)
+}
+
+// closeMessage builds the close frame sent when the server ends a connection
+func closeMessage(reason string) []byte {
+	switch reason {
+	case models.CloseReplaced:
+		return websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "connection replaced by a newer one")
+	case models.CloseSlowConsumer:
+		return websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow, reconnect to resync")
+	case models.CloseKicked:
+		return websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "removed by an admin")
+	case models.CloseShutdown:
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	default:
+		return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	}
+}
@@ -0,0 +1,355 @@
+package i18n
+
+// catalog holds the translations of each supported language other than Default, by message
+// code
+// A code raised with different English texts is translated once, in general terms.
+var catalog = map[string]map[string]string{
+	"de": german,
+	"es": spanish,
+	"tr": turkish,
+}
+
+var german = map[string]string{
+	"ALREADY_APPOINTED":       "Dieser Spieler hat die Rolle bereits",
+	"ALREADY_DISQUALIFIED":    "Dieser Spieler ist bereits disqualifiziert",
+	"ALREADY_IN_CLAN":         "Bereits in einem Clan",
+	"ALREADY_IN_GAME":         "Dieser Spieler ist bereits in diesem Spiel",
+	"ALREADY_IN_PARTY":        "Bereits in einer Gruppe",
+	"ALREADY_PLAYER":          "Du spielst bereits in diesem Spiel",
+	"ALREADY_REGISTERED":      "Du bist bereits angemeldet",
+	"BANNED":                  "Ein Admin hat dich vom Server verbannt",
+	"CANNOT_PAUSE":            "Dieses Spiel kann gerade nicht pausiert werden",
+	"CANNOT_RESTART":          "Dieses Spiel kann nicht neu gestartet werden",
+	"CANNOT_SUSPEND":          "Dieses Spiel kann gerade nicht gespeichert werden",
+	"CHAT_RATE_LIMITED":       "Zu viele Nachrichten. Warte einen Moment, bevor du die nächste sendest.",
+	"CHECK_IN_CLOSED":         "Der Check-in ist nicht geöffnet",
+	"CLANS_UNAVAILABLE":       "Clans sind auf diesem Server nicht verfügbar",
+	"CLAN_FULL":               "Der Clan ist voll",
+	"CLAN_INVITE_NOT_FOUND":   "Keine offene Einladung dieses Clans",
+	"CLAN_NOT_FOUND":          "Clan nicht gefunden",
+	"CLAN_TAG_TAKEN":          "Dieses Kürzel ist bereits vergeben",
+	"DAILY_RUN_UNVERIFIED":    "Dein Tageslauf konnte nicht überprüft werden und wurde nicht gewertet",
+	"DUPLICATE_REPORT":        "Du hast diesen Spieler bereits deswegen gemeldet; ein Moderator prüft es",
+	"DUPLICATE_REQUEST":       "Du hast diesem Spieler bereits eine Anfrage gesendet",
+	"EMOTE_RATE_LIMITED":      "Zu viele Emotes. Warte einen Moment, bevor du das nächste sendest.",
+	"GAME_FINISHED":           "Das Spiel ist bereits beendet",
+	"GAME_FORCE_ENDED":        "Das Spiel wurde von einem Admin beendet",
+	"GAME_NOT_FOUND":          "Spiel nicht gefunden",
+	"GAME_REQUEST_CANCELLED":  "{player} hat die Spielanfrage zurückgezogen",
+	"INTERNAL_ERROR":          "Interner Fehler bei der Verarbeitung von {msg_type}",
+	"INVALID_BOT":             "Unbekannter Übungsbot: {bot}",
+	"INVALID_CHAT_MESSAGE":    "Chatnachrichten müssen zwischen 1 und 200 Zeichen lang sein",
+	"INVALID_CLAN":            "Ungültiger Clanname oder ungültiges Kürzel",
+	"INVALID_CLAN_ROLE":       "Die Rolle muss Anführer, Offizier oder Mitglied sein",
+	"INVALID_CURSOR":          "Unbekannter Cursor",
+	"INVALID_DIFFICULTY":      "Unbekannter Schwierigkeitsgrad: {difficulty}",
+	"INVALID_EXTENSION":       "Der Check-in wird um 1 bis 60 Minuten verlängert",
+	"INVALID_GUEST_NAME":      "Der Gastname muss sich von deinem unterscheiden und darf höchstens 24 Zeichen lang sein",
+	"INVALID_LEVEL":           "Die Kampagne hat kein solches Level",
+	"INVALID_MUTE":            "Du kannst dich nicht selbst stummschalten",
+	"INVALID_PARTY_INVITE":    "Du kannst dich nicht selbst einladen",
+	"INVALID_PAYLOAD":         "Ungültige {msg_type}-Nachricht",
+	"INVALID_REPORT":          "Ungültige Meldung",
+	"INVALID_REPORT_CATEGORY": "Unbekannte Meldekategorie: {category}",
+	"INVALID_ROLE":            "Unbekannte Rolle",
+	"INVALID_RULES":           "Unbekanntes Regelprofil: {rules}",
+	"INVALID_SEASON":          "Eine Saison ist ein Monat wie 2026-01",
+	"INVALID_SNAKE_SLOT":      "Unbekannter Schlangenplatz; Platz 2 gibt es nur in Hot-Seat-Spielen, die du gestartet hast",
+	"INVALID_TIE_BREAK":       "Unbekannte Regel für Frontalzusammenstöße: {tie_break}",
+	"INVALID_TITLE":           "Unbekannter Titel",
+	"INVALID_TOKEN":           "Ungültiges oder fehlendes Token",
+	"INVALID_TOURNAMENT":      "Ungültige Turniereinstellungen",
+	"INVALID_USERNAME":        "Der Benutzername muss 2 bis 20 Zeichen breit sein und darf nur Buchstaben, Ziffern, Satzzeichen, Symbole und Leerzeichen enthalten.",
+	"INVALID_VARIANT":         "Unbekannte Einzelspielervariante: {variant}",
+	"INVITE_RATE_LIMITED":     "Zu viele Einladungen. Warte einen Moment, bevor du die nächste sendest.",
+	"KICKED":                  "Ein Admin hat dich vom Server entfernt; versuche es später erneut",
+	"LEVEL_LOCKED":            "Schließe zuerst das vorherige Level ab",
+	"LOUNGE_CLOSED":           "Der Chat ist erst nach dem Spielende geöffnet",
+	"LOUNGE_EXPIRED":          "Die Lounge nach dem Spiel wurde geschlossen",
+	"MAINTENANCE":             "Der Server wird gleich für Wartungsarbeiten neu gestartet; neue Spiele sind gerade nicht möglich",
+	"MATCH_NOT_FOUND":         "Match nicht gefunden",
+	"MATCH_NOT_RESTARTABLE":   "Dieses Match kann nicht neu gestartet werden",
+	"MATCH_NO_SHOW":           "Das Turniermatch wurde entschieden, weil ein Spieler nicht rechtzeitig bereit war",
+	"MATCH_RESTARTED":         "Das Turniermatch wurde von einem Moderator neu gestartet",
+	"MUTES_UNAVAILABLE":       "Stummschalten ist auf diesem Server nicht verfügbar",
+	"MUTE_LIMIT_REACHED":      "Du kannst höchstens 100 Spieler stummschalten",
+	"NOT_ALLOWED":             "{msg_type} ist gerade nicht erlaubt",
+	"NOT_APPOINTED":           "Dieser Spieler hat die Rolle nicht",
+	"NOT_A_LEAGUE":            "Tabellen gibt es nur für Ligen und Schweizer Turniere",
+	"NOT_A_PLAYER":            "Nur Spieler dieses Spiels können das tun",
+	"NOT_A_TOURNAMENT_GAME":   "Nur Turnierspiele können kommentiert werden",
+	"NOT_CASTER":              "Nur die Kommentatoren, Moderatoren und der Veranstalter des Turniers können seine Spiele kommentieren",
+	"NOT_CLAN_LEADER":         "Nur der Anführer kann das tun",
+	"NOT_CLAN_MEMBER":         "Kein Mitglied des Clans",
+	"NOT_CLAN_OFFICER":        "Nur der Anführer und die Offiziere können das tun",
+	"NOT_IN_CLAN":             "Du bist in keinem Clan",
+	"NOT_IN_GAME":             "Du bist nicht in diesem Spiel",
+	"NOT_IN_PARTY":            "Du bist in keiner Gruppe",
+	"NOT_ORGANIZER":           "Nur der Veranstalter kann das tun",
+	"NOT_PARTY_LEADER":        "Nur der Gruppenleiter kann das tun",
+	"NOT_REGISTERED":          "Du bist nicht angemeldet",
+	"NOT_REQUEST_TARGET":      "Die Anfrage ist nicht an dich gerichtet",
+	"NOT_SCHEDULED":           "Das Turnier hat keinen geplanten Check-in",
+	"NO_SAVED_GAME":           "Du hast kein gespeichertes Spiel",
+	"OPPONENT_DISCONNECTED":   "Der Gegner hat das Spiel verlassen. Zurück zur Lobby …",
+	"PARTY_FULL":              "Eine Gruppe hat höchstens 4 Spieler, offene Einladungen eingeschlossen",
+	"PARTY_MEMBER_BUSY":       "Ein Gruppenmitglied kann gerade nicht spielen",
+	"PARTY_NOT_FOUND":         "Die Gruppeneinladung ist nicht mehr gültig",
+	"PARTY_TOO_SMALL":         "Warte zuerst, bis jemand deiner Gruppe beitritt",
+	"PLAYER_DISQUALIFIED":     "Das Turniermatch wurde entschieden, weil ein Spieler disqualifiziert wurde",
+	"PLAYER_LEFT_GAME":        "{player} hat das Spiel verlassen",
+	"PLAYER_LEFT_LOBBY":       "{player} hat die Lobby verlassen",
+	"PLAYER_NOT_FOUND":        "Kein Spieler mit diesem Namen ist online",
+	"PROFILES_UNAVAILABLE":    "Das ist auf diesem Server nicht verfügbar",
+	"RANKED_BARRED":           "Du hast zu viele Spiele vor dem Ende verlassen; in {minutes} Min. kannst du wieder gewertete Spiele spielen",
+	"RATE_LIMITED":            "Zu viele Nachrichten. Langsamer.",
+	"REGISTRATION_CLOSED":     "Die Anmeldung ist geschlossen",
+	"REMATCH_UNAVAILABLE":     "Für dieses Spiel gibt es keine Revanche",
+	"REPLAY_NOT_FOUND":        "Kein aufgezeichnetes Spiel des Matches zum Überprüfen",
+	"REPORTS_UNAVAILABLE":     "Melden ist auf diesem Server nicht verfügbar",
+	"SAVED_GAME_INVALID":      "Dein gespeichertes Spiel konnte nicht wiederhergestellt werden",
+	"SERVER_FULL":             "Der Server ist voll. Bitte versuche es später noch einmal.",
+	"SESSION_CLOSED":          "Sitzung beendet, verbinde dich erneut mit deinem Token",
+	"SPECTATORS_FULL":         "Dieses Spiel hat bereits so viele Zuschauer, wie es aufnehmen kann",
+	"TITLE_NOT_EARNED":        "Diesen Titel hast du noch nicht verdient",
+	"TOO_FEW_PLAYERS":         "Zu wenige Spieler",
+	"TOO_MANY_CAST_GAMES":     "Ein Kommentator verfolgt höchstens 8 Spiele gleichzeitig",
+	"TOURNAMENT_FULL":         "Das Turnier ist voll",
+	"TOURNAMENT_NOT_FOUND":    "Turnier nicht gefunden",
+	"TOURNAMENT_NOT_PAUSED":   "Das Turnier ist nicht pausiert",
+	"TOURNAMENT_NOT_RUNNING":  "Das Turnier läuft nicht",
+	"TOURNAMENT_PAUSED":       "Das Turnier ist bereits pausiert",
+	"UNAUTHORIZED":            "Du darfst diese Aktion nicht ausführen",
+	"UNKNOWN_EMOTE":           "Unbekanntes Emote: {emote_id}",
+	"USERNAME_EXISTS":         "Der Benutzername ist bereits vergeben. Bitte wähle einen anderen.",
+}
+
+var spanish = map[string]string{
+	"ALREADY_APPOINTED":       "Ese jugador ya tiene el cargo",
+	"ALREADY_DISQUALIFIED":    "Ese jugador ya está descalificado",
+	"ALREADY_IN_CLAN":         "Ya está en un clan",
+	"ALREADY_IN_GAME":         "Ese jugador ya está en esta partida",
+	"ALREADY_IN_PARTY":        "Ya está en un grupo",
+	"ALREADY_PLAYER":          "Ya eres jugador de esta partida",
+	"ALREADY_REGISTERED":      "Ya estás inscrito",
+	"BANNED":                  "Un administrador te ha vetado en el servidor",
+	"CANNOT_PAUSE":            "Esta partida no se puede pausar ahora",
+	"CANNOT_RESTART":          "Esta partida no se puede reiniciar",
+	"CANNOT_SUSPEND":          "Esta partida no se puede guardar ahora",
+	"CHAT_RATE_LIMITED":       "Demasiados mensajes. Espera un momento antes de enviar otro.",
+	"CHECK_IN_CLOSED":         "El check-in no está abierto",
+	"CLANS_UNAVAILABLE":       "Los clanes no están disponibles en este servidor",
+	"CLAN_FULL":               "El clan está completo",
+	"CLAN_INVITE_NOT_FOUND":   "No hay ninguna invitación pendiente de este clan",
+	"CLAN_NOT_FOUND":          "Clan no encontrado",
+	"CLAN_TAG_TAKEN":          "Esa etiqueta ya está en uso",
+	"DAILY_RUN_UNVERIFIED":    "Tu partida diaria no se pudo verificar y no entró en la clasificación",
+	"DUPLICATE_REPORT":        "Ya denunciaste a ese jugador por esto; un moderador lo revisará",
+	"DUPLICATE_REQUEST":       "Ya enviaste una solicitud a este jugador",
+	"EMOTE_RATE_LIMITED":      "Demasiados emotes. Espera un momento antes de enviar otro.",
+	"GAME_FINISHED":           "La partida ya ha terminado",
+	"GAME_FORCE_ENDED":        "Un administrador ha terminado la partida",
+	"GAME_NOT_FOUND":          "Partida no encontrada",
+	"GAME_REQUEST_CANCELLED":  "{player} canceló la solicitud de partida",
+	"INTERNAL_ERROR":          "Error interno al procesar {msg_type}",
+	"INVALID_BOT":             "Bot de práctica desconocido: {bot}",
+	"INVALID_CHAT_MESSAGE":    "Los mensajes de chat deben tener entre 1 y 200 caracteres",
+	"INVALID_CLAN":            "Nombre o etiqueta de clan no válidos",
+	"INVALID_CLAN_ROLE":       "El cargo debe ser líder, oficial o miembro",
+	"INVALID_CURSOR":          "Cursor desconocido",
+	"INVALID_DIFFICULTY":      "Dificultad desconocida: {difficulty}",
+	"INVALID_EXTENSION":       "El check-in se amplía de 1 a 60 minutos",
+	"INVALID_GUEST_NAME":      "El nombre del invitado debe ser distinto del tuyo y tener como máximo 24 caracteres",
+	"INVALID_LEVEL":           "La campaña no tiene ese nivel",
+	"INVALID_MUTE":            "No puedes silenciarte a ti mismo",
+	"INVALID_PARTY_INVITE":    "No puedes invitarte a ti mismo",
+	"INVALID_PAYLOAD":         "Mensaje {msg_type} no válido",
+	"INVALID_REPORT":          "Denuncia no válida",
+	"INVALID_REPORT_CATEGORY": "Categoría de denuncia desconocida: {category}",
+	"INVALID_ROLE":            "Cargo desconocido",
+	"INVALID_RULES":           "Perfil de reglas desconocido: {rules}",
+	"INVALID_SEASON":          "Una temporada es un mes, como 2026-01",
+	"INVALID_SNAKE_SLOT":      "Serpiente desconocida; la serpiente 2 solo existe en las partidas hot-seat que iniciaste",
+	"INVALID_TIE_BREAK":       "Regla de desempate desconocida: {tie_break}",
+	"INVALID_TITLE":           "Título desconocido",
+	"INVALID_TOKEN":           "Token no válido o ausente",
+	"INVALID_TOURNAMENT":      "Configuración de torneo no válida",
+	"INVALID_USERNAME":        "El nombre de usuario debe ocupar de 2 a 20 caracteres de ancho y contener solo letras, dígitos, signos de puntuación, símbolos y espacios.",
+	"INVALID_VARIANT":         "Variante de un jugador desconocida: {variant}",
+	"INVITE_RATE_LIMITED":     "Demasiadas invitaciones. Espera un momento antes de enviar otra.",
+	"KICKED":                  "Un administrador te ha expulsado del servidor; inténtalo de nuevo más tarde",
+	"LEVEL_LOCKED":            "Supera primero el nivel anterior",
+	"LOUNGE_CLOSED":           "El chat solo se abre cuando termina la partida",
+	"LOUNGE_EXPIRED":          "La sala posterior a la partida se ha cerrado",
+	"MAINTENANCE":             "El servidor se reiniciará pronto por mantenimiento; ahora no se pueden empezar partidas nuevas",
+	"MATCH_NOT_FOUND":         "Enfrentamiento no encontrado",
+	"MATCH_NOT_RESTARTABLE":   "Este enfrentamiento no se puede reiniciar",
+	"MATCH_NO_SHOW":           "El enfrentamiento del torneo se decidió porque un jugador no estuvo listo a tiempo",
+	"MATCH_RESTARTED":         "Un moderador reinició el enfrentamiento del torneo",
+	"MUTES_UNAVAILABLE":       "Silenciar no está disponible en este servidor",
+	"MUTE_LIMIT_REACHED":      "Puedes silenciar como máximo a 100 jugadores",
+	"NOT_ALLOWED":             "{msg_type} no está permitido ahora",
+	"NOT_APPOINTED":           "Ese jugador no tiene el cargo",
+	"NOT_A_LEAGUE":            "Las clasificaciones solo existen en ligas y torneos suizos",
+	"NOT_A_PLAYER":            "Solo los jugadores de esta partida pueden hacerlo",
+	"NOT_A_TOURNAMENT_GAME":   "Solo se pueden comentar las partidas de torneo",
+	"NOT_CASTER":              "Solo los comentaristas, moderadores y el organizador del torneo pueden comentar sus partidas",
+	"NOT_CLAN_LEADER":         "Solo el líder puede hacerlo",
+	"NOT_CLAN_MEMBER":         "No es miembro del clan",
+	"NOT_CLAN_OFFICER":        "Solo el líder y los oficiales pueden hacerlo",
+	"NOT_IN_CLAN":             "No estás en ningún clan",
+	"NOT_IN_GAME":             "No estás en esta partida",
+	"NOT_IN_PARTY":            "No estás en ningún grupo",
+	"NOT_ORGANIZER":           "Solo el organizador puede hacerlo",
+	"NOT_PARTY_LEADER":        "Solo el líder del grupo puede hacerlo",
+	"NOT_REGISTERED":          "No estás inscrito",
+	"NOT_REQUEST_TARGET":      "La solicitud no va dirigida a ti",
+	"NOT_SCHEDULED":           "El torneo no tiene un check-in programado",
+	"NO_SAVED_GAME":           "No tienes ninguna partida guardada",
+	"OPPONENT_DISCONNECTED":   "Tu rival ha abandonado la partida. Volviendo al lobby...",
+	"PARTY_FULL":              "Un grupo tiene como máximo 4 jugadores, contando las invitaciones pendientes",
+	"PARTY_MEMBER_BUSY":       "Un miembro del grupo no puede jugar ahora",
+	"PARTY_NOT_FOUND":         "La invitación al grupo ya no es válida",
+	"PARTY_TOO_SMALL":         "Espera primero a que alguien se una a tu grupo",
+	"PLAYER_DISQUALIFIED":     "El enfrentamiento del torneo se decidió porque un jugador fue descalificado",
+	"PLAYER_LEFT_GAME":        "{player} ha abandonado la partida",
+	"PLAYER_LEFT_LOBBY":       "{player} ha salido del lobby",
+	"PLAYER_NOT_FOUND":        "No hay ningún jugador conectado con ese nombre",
+	"PROFILES_UNAVAILABLE":    "Esto no está disponible en este servidor",
+	"RANKED_BARRED":           "Abandonaste demasiadas partidas antes de terminar; podrás jugar partidas clasificatorias de nuevo en {minutes} min",
+	"RATE_LIMITED":            "Demasiados mensajes. Más despacio.",
+	"REGISTRATION_CLOSED":     "La inscripción está cerrada",
+	"REMATCH_UNAVAILABLE":     "Esta partida no admite revancha",
+	"REPLAY_NOT_FOUND":        "No hay ninguna partida grabada del enfrentamiento que verificar",
+	"REPORTS_UNAVAILABLE":     "Las denuncias no están disponibles en este servidor",
+	"SAVED_GAME_INVALID":      "No se pudo restaurar tu partida guardada",
+	"SERVER_FULL":             "El servidor está lleno. Inténtalo de nuevo más tarde.",
+	"SESSION_CLOSED":          "Sesión cerrada, vuelve a conectarte con tu token",
+	"SPECTATORS_FULL":         "Esta partida ya tiene todos los espectadores que admite",
+	"TITLE_NOT_EARNED":        "Todavía no has ganado ese título",
+	"TOO_FEW_PLAYERS":         "No hay suficientes jugadores",
+	"TOO_MANY_CAST_GAMES":     "Un comentarista sigue como máximo 8 partidas a la vez",
+	"TOURNAMENT_FULL":         "El torneo está completo",
+	"TOURNAMENT_NOT_FOUND":    "Torneo no encontrado",
+	"TOURNAMENT_NOT_PAUSED":   "El torneo no está en pausa",
+	"TOURNAMENT_NOT_RUNNING":  "El torneo no está en curso",
+	"TOURNAMENT_PAUSED":       "El torneo ya está en pausa",
+	"UNAUTHORIZED":            "No tienes permiso para realizar esta acción",
+	"UNKNOWN_EMOTE":           "Emote desconocido: {emote_id}",
+	"USERNAME_EXISTS":         "El nombre de usuario ya está en uso. Elige otro.",
+}
+
+var turkish = map[string]string{
+	"ALREADY_APPOINTED":       "Bu oyuncu zaten bu göreve atanmış",
+	"ALREADY_DISQUALIFIED":    "Bu oyuncu zaten diskalifiye edildi",
+	"ALREADY_IN_CLAN":         "Zaten bir klanda",
+	"ALREADY_IN_GAME":         "Bu oyuncu zaten bu oyunda",
+	"ALREADY_IN_PARTY":        "Zaten bir grupta",
+	"ALREADY_PLAYER":          "Bu oyunda zaten oyuncusun",
+	"ALREADY_REGISTERED":      "Zaten kayıtlısın",
+	"BANNED":                  "Bir yönetici seni sunucudan yasakladı",
+	"CANNOT_PAUSE":            "Bu oyun şu anda duraklatılamaz",
+	"CANNOT_RESTART":          "Bu oyun yeniden başlatılamaz",
+	"CANNOT_SUSPEND":          "Bu oyun şu anda kaydedilemez",
+	"CHAT_RATE_LIMITED":       "Çok fazla mesaj. Yenisini göndermeden önce biraz bekle.",
+	"CHECK_IN_CLOSED":         "Check-in açık değil",
+	"CLANS_UNAVAILABLE":       "Klanlar bu sunucuda kullanılamıyor",
+	"CLAN_FULL":               "Klan dolu",
+	"CLAN_INVITE_NOT_FOUND":   "Bu klandan bekleyen bir davet yok",
+	"CLAN_NOT_FOUND":          "Klan bulunamadı",
+	"CLAN_TAG_TAKEN":          "Bu etiket zaten alınmış",
+	"DAILY_RUN_UNVERIFIED":    "Günlük koşun doğrulanamadı ve sıralamaya girmedi",
+	"DUPLICATE_REPORT":        "Bu oyuncuyu bunun için zaten bildirdin; bir moderatör inceleyecek",
+	"DUPLICATE_REQUEST":       "Bu oyuncuya zaten istek gönderdin",
+	"EMOTE_RATE_LIMITED":      "Çok fazla emote. Yenisini göndermeden önce biraz bekle.",
+	"GAME_FINISHED":           "Oyun zaten bitti",
+	"GAME_FORCE_ENDED":        "Oyun bir yönetici tarafından sonlandırıldı",
+	"GAME_NOT_FOUND":          "Oyun bulunamadı",
+	"GAME_REQUEST_CANCELLED":  "{player} oyun isteğini iptal etti",
+	"INTERNAL_ERROR":          "{msg_type} işlenirken iç hata oluştu",
+	"INVALID_BOT":             "Bilinmeyen antrenman botu: {bot}",
+	"INVALID_CHAT_MESSAGE":    "Sohbet mesajları 1 ile 200 karakter arasında olmalı",
+	"INVALID_CLAN":            "Geçersiz klan adı veya etiketi",
+	"INVALID_CLAN_ROLE":       "Rol lider, subay veya üye olmalı",
+	"INVALID_CURSOR":          "Bilinmeyen imleç",
+	"INVALID_DIFFICULTY":      "Bilinmeyen zorluk: {difficulty}",
+	"INVALID_EXTENSION":       "Check-in 1 ila 60 dakika uzatılabilir",
+	"INVALID_GUEST_NAME":      "Misafir adı seninkinden farklı olmalı ve en fazla 24 karakter olmalı",
+	"INVALID_LEVEL":           "Kampanyada böyle bir seviye yok",
+	"INVALID_MUTE":            "Kendini susturamazsın",
+	"INVALID_PARTY_INVITE":    "Kendini davet edemezsin",
+	"INVALID_PAYLOAD":         "Geçersiz {msg_type} mesajı",
+	"INVALID_REPORT":          "Geçersiz bildirim",
+	"INVALID_REPORT_CATEGORY": "Bilinmeyen bildirim kategorisi: {category}",
+	"INVALID_ROLE":            "Bilinmeyen rol",
+	"INVALID_RULES":           "Bilinmeyen kural profili: {rules}",
+	"INVALID_SEASON":          "Sezon, 2026-01 gibi bir aydır",
+	"INVALID_SNAKE_SLOT":      "Bilinmeyen yılan yuvası; 2. yuva yalnızca başlattığın sıcak koltuk oyunlarında var",
+	"INVALID_TIE_BREAK":       "Bilinmeyen kafa kafaya çarpışma kuralı: {tie_break}",
+	"INVALID_TITLE":           "Bilinmeyen unvan",
+	"INVALID_TOKEN":           "Geçersiz veya eksik token",
+	"INVALID_TOURNAMENT":      "Geçersiz turnuva ayarları",
+	"INVALID_USERNAME":        "Kullanıcı adı 2 ila 20 karakter genişliğinde olmalı ve yalnızca harf, rakam, noktalama, sembol ve boşluk içermeli.",
+	"INVALID_VARIANT":         "Bilinmeyen tek oyunculu varyant: {variant}",
+	"INVITE_RATE_LIMITED":     "Çok fazla davet. Yenisini göndermeden önce biraz bekle.",
+	"KICKED":                  "Bir yönetici seni sunucudan çıkardı; daha sonra tekrar dene",
+	"LEVEL_LOCKED":            "Önce önceki seviyeyi geç",
+	"LOUNGE_CLOSED":           "Sohbet yalnızca oyun bittikten sonra açılır",
+	"LOUNGE_EXPIRED":          "Oyun sonrası salon kapandı",
+	"MAINTENANCE":             "Sunucu bakım için birazdan yeniden başlatılacak; şu anda yeni oyun başlatılamaz",
+	"MATCH_NOT_FOUND":         "Maç bulunamadı",
+	"MATCH_NOT_RESTARTABLE":   "Bu maç yeniden başlatılamaz",
+	"MATCH_NO_SHOW":           "Turnuva maçı bir oyuncu zamanında hazır olmadığı için sonuçlandı",
+	"MATCH_RESTARTED":         "Turnuva maçı bir moderatör tarafından yeniden başlatıldı",
+	"MUTES_UNAVAILABLE":       "Susturma bu sunucuda kullanılamıyor",
+	"MUTE_LIMIT_REACHED":      "En fazla 100 oyuncuyu susturabilirsin",
+	"NOT_ALLOWED":             "{msg_type} şu anda yapılamaz",
+	"NOT_APPOINTED":           "Bu oyuncu bu göreve atanmamış",
+	"NOT_A_LEAGUE":            "Puan durumu yalnızca ligler ve İsviçre turnuvaları için tutulur",
+	"NOT_A_PLAYER":            "Bunu yalnızca bu oyunun oyuncuları yapabilir",
+	"NOT_A_TOURNAMENT_GAME":   "Yalnızca turnuva maçları yayınlanabilir",
+	"NOT_CASTER":              "Turnuvanın maçlarını yalnızca yayıncıları, moderatörleri ve organizatörü yayınlayabilir",
+	"NOT_CLAN_LEADER":         "Bunu yalnızca lider yapabilir",
+	"NOT_CLAN_MEMBER":         "Klanın üyesi değil",
+	"NOT_CLAN_OFFICER":        "Bunu yalnızca lider ve subaylar yapabilir",
+	"NOT_IN_CLAN":             "Bir klanda değilsin",
+	"NOT_IN_GAME":             "Bu oyunda değilsin",
+	"NOT_IN_PARTY":            "Bir grupta değilsin",
+	"NOT_ORGANIZER":           "Bunu yalnızca organizatör yapabilir",
+	"NOT_PARTY_LEADER":        "Bunu yalnızca grup lideri yapabilir",
+	"NOT_REGISTERED":          "Kayıtlı değilsin",
+	"NOT_REQUEST_TARGET":      "Bu istek sana gönderilmedi",
+	"NOT_SCHEDULED":           "Turnuvanın planlanmış bir check-in'i yok",
+	"NO_SAVED_GAME":           "Kayıtlı oyunun yok",
+	"OPPONENT_DISCONNECTED":   "Rakip oyundan ayrıldı. Lobiye dönülüyor...",
+	"PARTY_FULL":              "Bir grupta bekleyen davetler dahil en fazla 4 oyuncu olabilir",
+	"PARTY_MEMBER_BUSY":       "Bir grup üyesi şu anda oynayamıyor",
+	"PARTY_NOT_FOUND":         "Grup daveti artık geçerli değil",
+	"PARTY_TOO_SMALL":         "Önce birinin grubuna katılmasını bekle",
+	"PLAYER_DISQUALIFIED":     "Turnuva maçı bir oyuncu diskalifiye edildiği için sonuçlandı",
+	"PLAYER_LEFT_GAME":        "{player} oyundan ayrıldı",
+	"PLAYER_LEFT_LOBBY":       "{player} lobiden ayrıldı",
+	"PLAYER_NOT_FOUND":        "Bu adda çevrimiçi bir oyuncu yok",
+	"PROFILES_UNAVAILABLE":    "Bu, bu sunucuda kullanılamıyor",
+	"RANKED_BARRED":           "Çok fazla oyunu bitmeden terk ettin; {minutes} dk sonra yeniden dereceli oyun oynayabilirsin",
+	"RATE_LIMITED":            "Çok fazla mesaj. Yavaşla.",
+	"REGISTRATION_CLOSED":     "Kayıtlar kapandı",
+	"REMATCH_UNAVAILABLE":     "Bu oyun için rövanş yapılamaz",
+	"REPLAY_NOT_FOUND":        "Maçın doğrulanacak kayıtlı bir oyunu yok",
+	"REPORTS_UNAVAILABLE":     "Bildirim bu sunucuda kullanılamıyor",
+	"SAVED_GAME_INVALID":      "Kayıtlı oyunun geri yüklenemedi",
+	"SERVER_FULL":             "Sunucu dolu. Lütfen daha sonra tekrar dene.",
+	"SESSION_CLOSED":          "Oturum kapandı, token'ınla yeniden bağlan",
+	"SPECTATORS_FULL":         "Bu oyunun izleyici kapasitesi dolu",
+	"TITLE_NOT_EARNED":        "Bu unvanı henüz kazanmadın",
+	"TOO_FEW_PLAYERS":         "Yeterli oyuncu yok",
+	"TOO_MANY_CAST_GAMES":     "Bir yayıncı aynı anda en fazla 8 oyunu takip edebilir",
+	"TOURNAMENT_FULL":         "Turnuva dolu",
+	"TOURNAMENT_NOT_FOUND":    "Turnuva bulunamadı",
+	"TOURNAMENT_NOT_PAUSED":   "Turnuva duraklatılmamış",
+	"TOURNAMENT_NOT_RUNNING":  "Turnuva devam etmiyor",
+	"TOURNAMENT_PAUSED":       "Turnuva zaten duraklatıldı",
+	"UNAUTHORIZED":            "Bu işlemi yapmaya yetkin yok",
+	"UNKNOWN_EMOTE":           "Bilinmeyen emote: {emote_id}",
+	"USERNAME_EXISTS":         "Kullanıcı adı zaten kullanımda. Lütfen başka bir ad seç.",
+}
@@ -0,0 +1,106 @@
+// Package i18n translates the error and system messages sent to players
+//
+// Messages are written in English where they are raised, each with a machine-readable
+// code. The catalog translates them by code, so a client can show the server's text in its
+// player's language or localize the code itself. A translation may name fields of the
+// message in braces, such as {msg_type}, which are filled in from the message.
+package i18n
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the language messages are written in, used when a client asks for none the
+// catalog has
+const Default = "en"
+
+// languages are the supported languages, Default first so that it wins when nothing matches
+var languages = []language.Tag{language.English, language.German, language.Spanish, language.Turkish}
+
+var matcher = language.NewMatcher(languages)
+
+// Languages returns the codes of the supported languages, Default first
+func Languages() []string {
+	codes := make([]string, len(languages))
+	for i, tag := range languages {
+		base, _ := tag.Base()
+		codes[i] = base.String()
+	}
+	return codes
+}
+
+// Negotiate picks the language of a client from the lang parameter of its connect request,
+// else from its Accept-Language header
+// Regional variants match their language, so "es-MX" gets Spanish; Default is returned when
+// no supported language is acceptable.
+func Negotiate(lang, acceptLanguage string) string {
+	var desired []language.Tag
+	if tag, err := language.Parse(lang); err == nil {
+		desired = append(desired, tag)
+	}
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		desired = append(desired, tags...)
+	}
+	_, index, confidence := matcher.Match(desired...)
+	if confidence == language.No {
+		return Default
+	}
+	base, _ := languages[index].Base()
+	return base.String()
+}
+
+// Message returns the translation of the message code into lang, with its fields filled in
+// ok is false when there is none, or a field it names is missing; the English text of the
+// message is kept then.
+func Message(lang, code string, fields map[string]any) (string, bool) {
+	template, exists := catalog[lang][code]
+	if !exists {
+		return "", false
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			b.WriteString(template)
+			return b.String(), true
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			b.WriteString(template)
+			return b.String(), true
+		}
+		value, exists := fields[template[start+1:start+end]]
+		if !exists {
+			return "", false
+		}
+		b.WriteString(template[:start])
+		fmt.Fprint(&b, value)
+		template = template[start+end+1:]
+	}
+}
+
+// Localize returns a message, which carries a code and an English message, with its message
+// translated into lang
+// The message is copied before being changed, so it may be shared between players.
+func Localize(lang string, data map[string]any) map[string]any {
+	if lang == "" || lang == Default {
+		return data
+	}
+	code, _ := data["code"].(string)
+	if _, isText := data["message"].(string); code == "" || !isText {
+		return data
+	}
+	text, ok := Message(lang, code, data)
+	if !ok {
+		return data
+	}
+
+	localized := maps.Clone(data)
+	localized["message"] = text
+	return localized
+}
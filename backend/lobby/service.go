@@ -1,10 +1,10 @@
 package lobby
 
 import (
-	"strings"
 	"sync"
 
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/models"
+	"github.com/bariiss/snake/backend/names"
 )
 
 type Service struct {
@@ -58,13 +58,13 @@ func (s *Service) ExistsByUsername(username string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	target := strings.ToLower(strings.TrimSpace(username))
+	target := names.Key(username)
 	if target == "" {
 		return false
 	}
 
 	for _, p := range s.players {
-		if strings.EqualFold(strings.TrimSpace(p.Username), target) {
+		if names.Key(p.Username) == target {
 			return true
 		}
 	}
@@ -0,0 +1,58 @@
+// Package logging configures the process-wide structured logger
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/bariiss/snake/backend/config"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// level is shared by the installed handler so reloads can change it at runtime
+var level slog.LevelVar
+
+// Setup installs the default slog logger writing to stderr in the configured format and level
+// attrs are attached to every record, e.g. the instance identity
+// Output of the standard log package is routed through the same handler
+func Setup(cfg config.Log, attrs ...slog.Attr) {
+	SetLevel(cfg.Level)
+	slog.SetDefault(slog.New(newHandler(os.Stderr, cfg.Format).WithAttrs(attrs)))
+}
+
+// SetLevel changes the minimum level of the installed logger
+func SetLevel(name string) {
+	parsed, _ := ParseLevel(name)
+	level.Set(parsed)
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog level
+// Unknown names yield info and false
+func ParseLevel(name string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "", "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
+
+func newHandler(w io.Writer, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: &level}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
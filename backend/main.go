@@ -1,38 +1,310 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"snake-backend/game"
-	"snake-backend/handlers"
-	"snake-backend/webrtc"
+	"github.com/bariiss/snake/backend/analytics"
+	"github.com/bariiss/snake/backend/audit"
+	"github.com/bariiss/snake/backend/auth"
+	"github.com/bariiss/snake/backend/bus"
+	"github.com/bariiss/snake/backend/clans"
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/features"
+	"github.com/bariiss/snake/backend/game"
+	"github.com/bariiss/snake/backend/handlers"
+	"github.com/bariiss/snake/backend/logging"
+	"github.com/bariiss/snake/backend/metrics"
+	"github.com/bariiss/snake/backend/moderation"
+	"github.com/bariiss/snake/backend/notify"
+	"github.com/bariiss/snake/backend/profile"
+	"github.com/bariiss/snake/backend/replay"
+	"github.com/bariiss/snake/backend/reporting"
+	"github.com/bariiss/snake/backend/storage"
+	"github.com/bariiss/snake/backend/web"
+	"github.com/bariiss/snake/backend/webrtc"
 )
 
+//go:generate go run ./cmd/tsgen -out ../frontend/src/app/services/protocol.ts
+
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Failed to load configuration", "error", err)
+	}
+
+	// Tag every log line and the metrics with this instance's identity
+	logging.Setup(cfg.Log, slog.String("instance_id", cfg.InstanceID), slog.String("region", cfg.Region))
+	// Unexpected errors and panics go to the error tracker when a DSN is configured
+	if cfg.Errors.DSN != "" {
+		reporter, err := reporting.NewSentry(cfg.Errors.DSN, cfg.InstanceID, cfg.Errors.Environment)
+		if err != nil {
+			fatal("Failed to set up error reporting", "error", err)
+		}
+		reporting.SetReporter(reporter)
+		slog.Info("Reporting errors to tracker", "environment", cfg.Errors.Environment)
+	}
+	if err := features.Load(cfg.Features); err != nil {
+		fatal("Failed to load feature flags", "error", err)
+	}
+	metrics.NewInfo("snake_instance_info", "Identity of this server instance", map[string]string{
+		"instance_id": cfg.InstanceID,
+		"region":      cfg.Region,
+	})
+
+	if cfg.JWTSecret == config.Default().JWTSecret {
+		slog.Warn("Using the default JWT secret, set JWT_SECRET in production")
+	}
+	auth.SetSecret(cfg.JWTSecret)
+	if cfg.Links.Secret == config.Default().Links.Secret {
+		slog.Warn("Using the default link secret, set LINK_SECRET in production")
+	}
+
 	gameManager := game.NewGameManager()
+	gameManager.SetConfig(cfg)
 	webrtcManager := webrtc.NewManager()
+	webrtcManager.SetICEServers(cfg.ICEServers)
 	gameManager.SetWebRTCManager(webrtcManager)
 
+	// Share lobbies, games lists and spectator streams with other instances
+	if cfg.BusURL != "" {
+		messageBus, err := bus.NewRedis(cfg.BusURL)
+		if err != nil {
+			fatal("Failed to connect to message bus", "error", err)
+		}
+		if err := gameManager.SetBus(messageBus); err != nil {
+			fatal("Failed to subscribe to message bus", "error", err)
+		}
+		slog.Info("Connected to message bus, sharing lobby with other instances")
+	}
+
+	// Gameplay analytics events (game_created, match_duration, ...) go to the configured sink
+	sink, err := analytics.NewSink(cfg.Analytics)
+	if err != nil {
+		fatal("Failed to set up analytics", "error", err)
+	}
+	var emitter *analytics.Emitter
+	if sink != nil {
+		emitter = analytics.NewEmitter(sink, cfg.InstanceID)
+		gameManager.SetAnalytics(emitter)
+		slog.Info("Sending analytics events", "sink", cfg.Analytics.Sink)
+	}
+
+	// Notable events (tournament starts, new leaderboard leaders, win streaks) go to chat webhooks
+	notifier := notify.New(notify.NewWebhooks(cfg.Notify))
+	if notifier != nil {
+		gameManager.SetNotifier(notifier)
+		slog.Info("Posting notifications to chat webhooks")
+	}
+
+	// Persisted records (audit log, player reports, replays, profiles, clans); kept in memory without STORAGE_DIR
+	store, err := storage.Open(cfg.StorageDir)
+	if err != nil {
+		fatal("Failed to open storage", "error", err)
+	}
+	auditLog, err := audit.Open(store)
+	if err != nil {
+		fatal("Failed to load audit log", "error", err)
+	}
+	reports, err := moderation.Open(store)
+	if err != nil {
+		fatal("Failed to load player reports", "error", err)
+	}
+	gameManager.SetReports(reports)
+	restrictions, err := moderation.OpenRestrictions(store)
+	if err != nil {
+		fatal("Failed to load player restrictions", "error", err)
+	}
+	gameManager.SetRestrictions(restrictions)
+	bans, err := moderation.OpenBans(store)
+	if err != nil {
+		fatal("Failed to load bans", "error", err)
+	}
+	gameManager.SetBans(bans)
+	links, err := moderation.OpenLinks(store, cfg.Links.Secret, time.Duration(cfg.Links.AddressRetentionDays)*24*time.Hour)
+	if err != nil {
+		fatal("Failed to load account links", "error", err)
+	}
+	gameManager.SetLinks(links)
+	replays, err := replay.Open(store)
+	if err != nil {
+		fatal("Failed to load replays", "error", err)
+	}
+	gameManager.SetReplays(replays)
+	profiles, err := profile.Open(store)
+	if err != nil {
+		fatal("Failed to load player profiles", "error", err)
+	}
+	gameManager.SetProfiles(profiles)
+	clanStore, err := clans.Open(store)
+	if err != nil {
+		fatal("Failed to load clans", "error", err)
+	}
+	gameManager.SetClans(clanStore)
+	if err := gameManager.SetTournaments(store); err != nil {
+		fatal("Failed to load tournaments", "error", err)
+	}
+
+	// Reloadable settings are applied to new games and connections on SIGHUP or admin request,
+	// as are limits tuned through the admin API
+	reloader := config.NewReloader(cfg)
+	reloader.OnReload(func(next *config.Config) {
+		if err := features.Load(next.Features); err != nil {
+			slog.Error("Failed to reload feature flags, keeping previous flags", "error", err)
+		}
+		logging.SetLevel(next.Log.Level)
+		gameManager.SetConfig(next)
+		webrtcManager.SetICEServers(next.ICEServers)
+	})
+	reloader.WatchSignals()
+
 	wsHandler := handlers.NewWebSocketHandler(gameManager)
 	peerSignalingHandler := handlers.NewPeerSignalingHandler(gameManager)
+	webrtcHandler := handlers.NewWebRTCHandler(gameManager, webrtcManager)
+	adminHandler := handlers.NewAdminHandler(gameManager, reloader, auditLog, reports)
+	tournamentsHandler := handlers.NewTournamentsHandler(gameManager)
+	watchHandler := handlers.NewWatchHandler(gameManager)
+	longPollHandler := handlers.NewLongPollHandler(gameManager)
+	graphQLHandler := handlers.NewGraphQLHandler(gameManager)
+	requireAdmin := auth.AdminMiddleware(cfg.AdminToken)
 
 	// WebSocket (for lobby, matchmaking)
 	http.Handle("/ws", wsHandler)
 
+	// Long-polling fallback for networks that block WebSockets and WebRTC
+	http.HandleFunc("POST /poll/connect", longPollHandler.HandleConnect)
+	http.HandleFunc("POST /poll/{id}/send", longPollHandler.HandleSend)
+	http.HandleFunc("GET /poll/{id}", longPollHandler.HandlePoll)
+	http.HandleFunc("DELETE /poll/{id}", longPollHandler.HandleClose)
+
 	// Peer-to-peer signaling
 	http.HandleFunc("/webrtc/peer/offer", peerSignalingHandler.HandlePeerOffer)
 	http.HandleFunc("/webrtc/peer/answer", peerSignalingHandler.HandlePeerAnswer)
 	http.HandleFunc("/webrtc/peer/ice", peerSignalingHandler.HandleICECandidate)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Server-hosted WebRTC data channel (experimental, behind feature flag)
+	http.Handle("/webrtc/offer", features.Require(features.P2PHosting, http.HandlerFunc(webrtcHandler.HandleOffer)))
+
+	// Admin API (requires ADMIN_TOKEN)
+	http.Handle("/api/admin/config/reload", requireAdmin(http.HandlerFunc(adminHandler.HandleConfigReload)))
+	http.Handle("GET /api/admin/tuning", requireAdmin(http.HandlerFunc(adminHandler.HandleTuning)))
+	http.Handle("POST /api/admin/tuning", requireAdmin(http.HandlerFunc(adminHandler.HandleTune)))
+	http.Handle("/api/admin/audit", requireAdmin(http.HandlerFunc(adminHandler.HandleAudit)))
+	http.Handle("/api/admin/stats/ws", requireAdmin(http.HandlerFunc(adminHandler.HandleStatsStream)))
+	http.Handle("GET /api/admin/players", requireAdmin(http.HandlerFunc(adminHandler.HandlePlayers)))
+	http.Handle("GET /api/admin/games", requireAdmin(http.HandlerFunc(adminHandler.HandleGames)))
+	http.Handle("GET /api/admin/games/{id}/debug", requireAdmin(http.HandlerFunc(adminHandler.HandleGameDebug)))
+	http.Handle("GET /api/admin/games/{id}/verify", requireAdmin(http.HandlerFunc(adminHandler.HandleVerifyGame)))
+	http.Handle("POST /api/admin/games/{id}/end", requireAdmin(http.HandlerFunc(adminHandler.HandleEndGame)))
+	http.Handle("GET /api/admin/reports", requireAdmin(http.HandlerFunc(adminHandler.HandleReports)))
+	http.Handle("POST /api/admin/reports/{id}/review", requireAdmin(http.HandlerFunc(adminHandler.HandleReviewReport)))
+	http.Handle("GET /api/admin/restrictions", requireAdmin(http.HandlerFunc(adminHandler.HandleRestrictions)))
+	http.Handle("POST /api/admin/players/{id}/restriction", requireAdmin(http.HandlerFunc(adminHandler.HandleRestrict)))
+	http.Handle("DELETE /api/admin/players/{id}/restriction", requireAdmin(http.HandlerFunc(adminHandler.HandleLiftRestriction)))
+	http.Handle("POST /api/admin/players/{id}/kick", requireAdmin(http.HandlerFunc(adminHandler.HandleKick)))
+	http.Handle("GET /api/admin/bans", requireAdmin(http.HandlerFunc(adminHandler.HandleBans)))
+	http.Handle("POST /api/admin/players/{id}/ban", requireAdmin(http.HandlerFunc(adminHandler.HandleBan)))
+	http.Handle("DELETE /api/admin/players/{id}/ban", requireAdmin(http.HandlerFunc(adminHandler.HandleLiftBan)))
+	http.Handle("GET /api/admin/players/{id}/links", requireAdmin(http.HandlerFunc(adminHandler.HandlePlayerLinks)))
+	http.Handle("GET /api/admin/tournaments/{id}/links", requireAdmin(http.HandlerFunc(adminHandler.HandleTournamentLinks)))
+	http.Handle("GET /api/admin/maintenance", requireAdmin(http.HandlerFunc(adminHandler.HandleMaintenance)))
+	http.Handle("POST /api/admin/maintenance", requireAdmin(http.HandlerFunc(adminHandler.HandleStartMaintenance)))
+	http.Handle("DELETE /api/admin/maintenance", requireAdmin(http.HandlerFunc(adminHandler.HandleEndMaintenance)))
+	http.Handle("GET /api/admin/announcement", requireAdmin(http.HandlerFunc(adminHandler.HandleAnnouncement)))
+	http.Handle("POST /api/admin/announcement", requireAdmin(http.HandlerFunc(adminHandler.HandleAnnounce)))
+	http.Handle("DELETE /api/admin/announcement", requireAdmin(http.HandlerFunc(adminHandler.HandleClearAnnouncement)))
+
+	// Public results of finished tournaments, for embedding on other sites
+	http.HandleFunc("GET /api/tournaments", tournamentsHandler.HandleList)
+	http.HandleFunc("GET /api/tournaments/{id}", tournamentsHandler.HandleGet)
+
+	// Read-only GraphQL queries over players, games, leaderboards and tournament results
+	http.Handle("/api/graphql", graphQLHandler)
+
+	// Live games as server-sent events, for viewers whose proxies break WebSockets
+	http.HandleFunc("GET /api/games/{id}/events", watchHandler.HandleEvents)
+
+	// Readiness, not ready while draining for maintenance
+	http.HandleFunc("GET /readyz", handlers.ReadyHandler(gameManager))
+
+	// Metrics (capacity headroom, counters)
+	http.Handle("/metrics", metrics.Handler())
+
+	// Web client embedded at build time, for single-binary deployments
+	if cfg.ServeWeb {
+		webHandler, err := web.Handler()
+		if err != nil {
+			fatal("Failed to serve the web client", "error", err)
+		}
+		http.Handle("/", webHandler)
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Printf("WebSocket endpoint: /ws")
-	log.Printf("Peer signaling endpoints: /webrtc/peer/offer, /webrtc/peer/answer, /webrtc/peer/ice")
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	slog.Info("Server starting",
+		"port", cfg.Port,
+		"websocket_endpoint", "/ws",
+		"long_poll_endpoint", "/poll",
+		"peer_signaling_endpoints", []string{"/webrtc/peer/offer", "/webrtc/peer/answer", "/webrtc/peer/ice"},
+		"features", features.EnabledList(),
+		"serve_web", cfg.ServeWeb,
+		"max_games", cfg.Limits.MaxGames,
+		"max_connections", cfg.Limits.MaxConnections,
+		"max_games_per_player", cfg.Limits.MaxGamesPerPlayer,
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + cfg.Port}
+	// Event streams and long polls end with the manager, so it stops as soon as the server
+	// begins shutting down rather than after the server has waited for them
+	server.RegisterOnShutdown(gameManager.Stop)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		fatal("Server stopped", "error", err)
+	case <-ctx.Done():
+	}
+
+	// Stop accepting connections, then end games and close the open connections
+	slog.Info("Shutting down", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("HTTP server shutdown failed", "error", err)
+	}
+	if !gameManager.Shutdown(shutdownCtx) {
+		// Lingering sessions may still record events, so the sinks are left open
+		slog.Warn("Connections still open after shutdown timeout", "open_connections", gameManager.ConnectionCount())
+		return
+	}
+	emitter.Close()
+	notifier.Close()
+	store.Close()
+	reporting.Flush(2 * time.Second)
+	slog.Info("Server stopped")
+}
+
+// shutdownTimeout bounds how long a shutdown waits for connections to close
+const shutdownTimeout = 5 * time.Second
+
+// fatal logs an error, waits briefly for pending error reports and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			reporting.Error(msg, err)
+		}
+	}
+	reporting.Flush(2 * time.Second)
+	os.Exit(1)
 }
@@ -0,0 +1,273 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// metric is implemented by every collector that can be exported
+type metric interface {
+	write(w io.Writer)
+}
+
+type registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+	order   []string
+}
+
+var defaultRegistry = &registry{
+	metrics: make(map[string]metric),
+}
+
+// register adds a metric to the default registry, replacing any metric with the same name
+func register(name string, m metric) {
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+
+	if _, exists := defaultRegistry.metrics[name]; !exists {
+		defaultRegistry.order = append(defaultRegistry.order, name)
+	}
+	defaultRegistry.metrics[name] = m
+}
+
+// Handler serves all registered metrics in the Prometheus text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+		defaultRegistry.mu.RLock()
+		defer defaultRegistry.mu.RUnlock()
+
+		for _, name := range defaultRegistry.order {
+			defaultRegistry.metrics[name].write(w)
+		}
+	})
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	name  string
+	help  string
+	value atomic.Uint64
+}
+
+// NewCounter creates and registers a counter
+func NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	register(name, c)
+	return c
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value returns the current counter value
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.name, c.value.Load())
+}
+
+// CounterVec is a set of counters partitioned by a single label
+type CounterVec struct {
+	name   string
+	help   string
+	label  string
+	mu     sync.RWMutex
+	values map[string]*atomic.Uint64
+}
+
+// NewCounterVec creates and registers a labelled counter
+func NewCounterVec(name, help, label string) *CounterVec {
+	c := &CounterVec{
+		name:   name,
+		help:   help,
+		label:  label,
+		values: make(map[string]*atomic.Uint64),
+	}
+	register(name, c)
+	return c
+}
+
+// Inc increments the counter for the given label value
+func (c *CounterVec) Inc(labelValue string) {
+	c.mu.RLock()
+	value, exists := c.values[labelValue]
+	c.mu.RUnlock()
+
+	if !exists {
+		c.mu.Lock()
+		if value, exists = c.values[labelValue]; !exists {
+			value = &atomic.Uint64{}
+			c.values[labelValue] = value
+		}
+		c.mu.Unlock()
+	}
+	value.Add(1)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	labels := make([]string, 0, len(c.values))
+	for label := range c.values {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		fmt.Fprintf(w, "%s{%s=%q} %d\n", c.name, c.label, label, c.values[label].Load())
+	}
+}
+
+// GaugeFunc is a gauge whose value is computed on every scrape
+type GaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc creates and registers a gauge backed by fn
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, fn: fn}
+	register(name, g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatValue(g.fn()))
+}
+
+// Info is a constant gauge with value 1 whose labels describe the process
+type Info struct {
+	name   string
+	help   string
+	labels map[string]string
+}
+
+// NewInfo creates and registers an info metric
+func NewInfo(name, help string, labels map[string]string) *Info {
+	i := &Info{name: name, help: help, labels: labels}
+	register(name, i)
+	return i
+}
+
+func (i *Info) write(w io.Writer) {
+	writeHeader(w, i.name, i.help, "gauge")
+
+	keys := make([]string, 0, len(i.labels))
+	for key := range i.labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, i.labels[key]))
+	}
+	fmt.Fprintf(w, "%s{%s} 1\n", i.name, strings.Join(pairs, ","))
+}
+
+// HistogramVec is a set of histograms with shared buckets, partitioned by a single label
+type HistogramVec struct {
+	name    string
+	help    string
+	label   string
+	buckets []float64 // Upper bounds, ascending; +Inf is implicit
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative; the last entry counts values above every bound
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec creates and registers a labelled histogram with the given bucket upper bounds
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		label:   label,
+		buckets: slices.Sorted(slices.Values(buckets)),
+		values:  make(map[string]*histogram),
+	}
+	register(name, h)
+	return h
+}
+
+// Observe records a value for the given label value
+func (h *HistogramVec) Observe(labelValue string, v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	value, exists := h.values[labelValue]
+	if !exists {
+		value = &histogram{counts: make([]uint64, len(h.buckets)+1)}
+		h.values[labelValue] = value
+	}
+	bucket, _ := slices.BinarySearch(h.buckets, v)
+	value.counts[bucket]++
+	value.sum += v
+	value.count++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	labels := make([]string, 0, len(h.values))
+	for label := range h.values {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		value := h.values[label]
+		cumulative := uint64(0)
+		for i, bound := range h.buckets {
+			cumulative += value.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s=%q,le=%q} %d\n", h.name, h.label, label, formatValue(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", h.name, h.label, label, value.count)
+		fmt.Fprintf(w, "%s_sum{%s=%q} %s\n", h.name, h.label, label, formatValue(value.sum))
+		fmt.Fprintf(w, "%s_count{%s=%q} %d\n", h.name, h.label, label, value.count)
+	}
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// Connection close reasons
+const (
+	CloseClientClosed = "client_close"      // The client sent a close frame
+	ClosePongTimeout  = "pong_timeout"      // Nothing, not even a pong, arrived within the read deadline
+	CloseClientGone   = "client_gone"       // The socket failed or was dropped without a close frame
+	CloseTooLarge     = "message_too_large" // The client sent a message over the read limit
+	CloseReplaced     = "replaced"          // The player opened a newer connection
+	CloseSlowConsumer = "slow_consumer"     // The outbound buffer stayed full
+	CloseWriteFailed  = "write_failed"      // Writing to the socket failed
+	CloseKicked       = "kicked"            // An admin removed the player
+	CloseShutdown     = "server_shutdown"   // The server is stopping
+)
+
+// maxDroppedFrames is how many frames in a row may be superseded before one is written
+// before the connection is considered too slow to keep
+const maxDroppedFrames = 50
+
+var (
+	// ErrConnectionClosed is returned when enqueueing on a closed connection
+	ErrConnectionClosed = errors.New("connection closed")
+	// ErrMessageDropped is returned when a frame replaced an older one that was never written
+	ErrMessageDropped = errors.New("stale frame dropped")
+	// ErrSlowConsumer is returned when a full buffer caused the connection to be closed
+	ErrSlowConsumer = errors.New("send buffer full, connection closed")
+)
+
+// Connection is the outbound side of one player session
+// Send is never closed: the write pump draining it is the only goroutine that ends the
+// session, and does so once Done is closed. Everyone else calls Close.
+//
+// Frames (messages the next one supersedes) don't queue on Send: only the newest unwritten
+// frame is kept, so a lagging client gets the latest state instead of replaying stale ones.
+// The kept frame is always newer than everything on Send; queueing any other message moves
+// it onto Send first, so messages are written in the order they were enqueued.
+type Connection struct {
+	ID          string // Correlates log lines and error responses of one session
+	Subprotocol string // Negotiated WebSocket subprotocol, empty for legacy clients; set before Attach
+	Language    string // Language of error and system messages, an i18n code; set before Attach
+	Send        chan []byte
+
+	mu         sync.Mutex // Serializes enqueueing against Drain
+	frame      []byte     // Newest unwritten frame
+	frameReady chan struct{}
+
+	done    chan struct{}
+	once    sync.Once
+	reason  atomic.Value // string
+	dropped atomic.Int32 // Frames superseded in a row without one being written
+	slow    atomic.Bool
+}
+
+// NewConnection creates a connection with an empty outbound buffer queueing up to queueSize
+// messages
+func NewConnection(queueSize int) *Connection {
+	return &Connection{
+		ID:         uuid.New().String(),
+		Send:       make(chan []byte, queueSize),
+		frameReady: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+}
+
+// Done is closed when the connection should be torn down
+func (c *Connection) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close asks the write pump to end the connection; only the first reason is kept
+// Safe to call multiple times and from any goroutine
+func (c *Connection) Close(reason string) {
+	c.once.Do(func() {
+		c.reason.Store(reason)
+		close(c.done)
+	})
+}
+
+// Closed reports whether Close has been called
+func (c *Connection) Closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reason returns why the connection was closed, or "" while it is open
+func (c *Connection) Reason() string {
+	reason, _ := c.reason.Load().(string)
+	return reason
+}
+
+// Pending returns the number of messages waiting to be written, including a waiting frame
+func (c *Connection) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := len(c.Send)
+	if c.frame != nil {
+		pending++
+	}
+	return pending
+}
+
+// Slow reports whether the client is falling behind
+func (c *Connection) Slow() bool {
+	return c.slow.Load()
+}
+
+// FrameReady receives a value after a frame is enqueued; call Drain to collect it
+func (c *Connection) FrameReady() <-chan struct{} {
+	return c.frameReady
+}
+
+// Enqueue queues data for the write pump without blocking
+// Droppable messages are frames: a frame replaces any frame still waiting to be written, and
+// too many replacements in a row mean the client stopped reading. Any other message that
+// does not fit in the buffer closes the connection so the client reconnects and resynchronizes.
+func (c *Connection) Enqueue(data []byte, droppable bool) error {
+	if c.Closed() {
+		return ErrConnectionClosed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if droppable {
+		return c.enqueueFrameLocked(data)
+	}
+
+	// The waiting frame was enqueued first, so it must be written first
+	if c.frame != nil {
+		select {
+		case c.Send <- c.frame:
+		default:
+			c.dropped.Add(1)
+		}
+		c.frame = nil
+	}
+
+	select {
+	case c.Send <- data:
+		c.slow.Store(false)
+		return nil
+	default:
+	}
+
+	c.slow.Store(true)
+	c.Close(CloseSlowConsumer)
+	return ErrSlowConsumer
+}
+
+func (c *Connection) enqueueFrameLocked(data []byte) error {
+	superseded := c.frame != nil
+	c.frame = data
+	select {
+	case c.frameReady <- struct{}{}:
+	default:
+	}
+
+	if !superseded {
+		return nil
+	}
+	c.slow.Store(true)
+	if c.dropped.Add(1) <= maxDroppedFrames {
+		return ErrMessageDropped
+	}
+	c.Close(CloseSlowConsumer)
+	return ErrSlowConsumer
+}
+
+// Drain appends every message waiting to be written to batch, in enqueue order
+func (c *Connection) Drain(batch [][]byte) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		select {
+		case data := <-c.Send:
+			batch = append(batch, data)
+			continue
+		default:
+		}
+		break
+	}
+
+	if c.frame != nil {
+		batch = append(batch, c.frame)
+		c.frame = nil
+		c.dropped.Store(0)
+		c.slow.Store(false)
+	}
+	return batch
+}
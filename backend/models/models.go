@@ -1,64 +1,284 @@
 package models
 
 import (
+	"context"
+	"log/slog"
 	"sync"
 	"time"
 
-	"snake-backend/constants"
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/replay"
+	"github.com/bariiss/snake/backend/rng"
 )
 
-type Position struct {
-	X int `json:"x"`
-	Y int `json:"y"`
+// Game state and rules are the engine's, so the server, bots and clients share one model
+type (
+	Position      = engine.Position
+	PlayerStatus  = engine.PlayerStatus
+	Snake         = engine.Snake
+	Food          = engine.Food
+	RulesProfile  = engine.RulesProfile
+	Difficulty    = engine.Difficulty
+	Turn          = engine.Turn
+	CampaignLevel = engine.CampaignLevel
+	GameState     = engine.GameState
+)
+
+// DailyRun is what a daily run needs to be replayed: everyone plays the same day from the
+// same seed, so its opening and the turns applied reproduce the run
+type DailyRun struct {
+	Date        string // UTC day the run counts for, as 2006-01-02
+	SpawnLayout string // Spawn layout and starting length the opening was laid out with
+	StartLength int
+	Turns       []Turn // Turns applied, in order
+}
+
+type Player struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	Ready      bool      `json:"ready"`
+	JoinedAt   time.Time `json:"joined_at"`
+	InstanceID string    `json:"instance_id,omitempty"` // Set for players connected to another instance
+	Bot        bool      `json:"bot,omitempty"`         // Connected as a bot, shown as one in the lobby
+
+	connMu    sync.Mutex  // Guards conn and connected, which are swapped together; see Attach
+	conn      *Connection // Current connection, kept for logging once the player disconnects
+	connected bool        // conn is live; false once the player disconnected
 }
 
-type PlayerStatus struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Ready    bool   `json:"ready"`
+// Logger returns a logger tagged with the player's ID, username and current connection ID
+func (p *Player) Logger() *slog.Logger {
+	logger := slog.With("player_id", p.ID, "username", p.Username)
+	if conn := p.LastConnection(); conn != nil {
+		logger = logger.With("conn_id", conn.ID)
+	}
+	return logger
 }
 
-type Snake struct {
-	ID        string              `json:"id"`
-	Body      []Position          `json:"body"`
-	Direction constants.Direction `json:"direction"`
-	NextDir   constants.Direction `json:"-"`
-	Color     string              `json:"color"`
-	Score     int                 `json:"score"`
-	Username  string              `json:"username,omitempty"`
+// Attach makes conn the player's current connection and returns the previous one
+// The connection is only assigned here, so senders never see one connection marked live
+// while another is being swapped in. The caller closes the previous connection.
+func (p *Player) Attach(conn *Connection) *Connection {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	old := p.conn
+	p.conn = conn
+	p.connected = conn != nil
+	return old
 }
 
-type Food struct {
-	Position Position `json:"position"`
+// Detach marks the player as disconnected; the connection is kept for logging
+func (p *Player) Detach() {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	p.connected = false
 }
 
-type GameState struct {
-	ID             string         `json:"id"`
-	Snakes         []Snake        `json:"snakes"`
-	Food           Food           `json:"food"`
-	Status         string         `json:"status"` // "waiting", "countdown", "playing", "finished"
-	Countdown      int            `json:"countdown"`
-	Winner         string         `json:"winner,omitempty"`
-	Players        []PlayerStatus `json:"players,omitempty"`
-	IsSinglePlayer bool           `json:"is_single_player,omitempty"`
+// Release detaches the player from a connection that closed and reports whether it was
+// still theirs; false when the player has moved on to a newer connection
+func (p *Player) Release(conn *Connection) bool {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	if p.conn != conn {
+		return false
+	}
+	p.connected = false
+	return true
 }
 
-type Player struct {
-	ID       string      `json:"id"`
-	Send     chan []byte `json:"-"` // Used for WebSocket
-	Username string      `json:"username"`
-	Ready    bool        `json:"ready"`
-	JoinedAt time.Time   `json:"joined_at"`
+// Connected reports whether the player has a live connection
+func (p *Player) Connected() bool {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	return p.connected
+}
+
+// Connection returns the player's current connection, nil when disconnected
+func (p *Player) Connection() *Connection {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	if !p.connected {
+		return nil
+	}
+	return p.conn
+}
+
+// LastConnection returns the player's current connection, or the last one once they
+// disconnected; nil if they never connected
+func (p *Player) LastConnection() *Connection {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	return p.conn
 }
 
 type Game struct {
-	ID             string
-	Player1        *Player
-	Player2        *Player // nil for single player games
-	State          *GameState
-	Ticker         *time.Ticker
-	Mutex          sync.RWMutex
-	IsActive       bool
-	IsSinglePlayer bool
-	Spectators     map[string]*Player
+	ID               string
+	Player1          *Player
+	Player2          *Player // nil for single player games
+	State            *GameState
+	Mutex            sync.RWMutex
+	IsSinglePlayer   bool
+	HotSeat          bool // Player2 is a local guest steered through Player1's connection
+	Coop             bool // Both snakes of a hot-seat game play as a team, the game ends when either dies
+	Practice         bool // Player2 is a bot the server steers, and crashed snakes respawn
+	Spectators       map[string]*Player
+	SpectatorInvites map[string]string      // Invited player ID -> inviting player ID, until the invitee joins
+	Casters          map[string]*Player     // Tournament casters following the game alongside others; not counted as spectators
+	Watchers         map[string]*Connection // Connection ID -> read-only viewer streaming the game's frames over server-sent events
+	Rules            RulesProfile
+	Difficulty       Difficulty       // Speeds up a single player game as its snake eats; zero for other games
+	CampaignLevel    int              // Campaign level a single player game plays, zero outside the campaign
+	Variant          string           // Single player variant outside the campaign, empty for other games
+	DailyRun         *DailyRun        // Record of a daily run the server replays before ranking it, nil for other games
+	Replay           *replay.Record   // Record of the current multiplayer round, archived for verification when it ends; nil when none is kept
+	Resume           *Resume          // Where a resumed single player round picks up once its countdown ends
+	InstanceID       string           // Server instance hosting the game
+	Region           string           // Region of the hosting instance
+	Tournament       string           // Tournament the game is played for, empty otherwise; it has no rematch
+	Countdown        *Countdown       // Running start or rematch countdown, nil when none is running
+	StartedAt        time.Time        // When the current round started playing, moved on by the time spent paused
+	PausedAt         time.Time        // When a single player game was paused, zero while it isn't
+	FinishedAt       time.Time        // When the last round ended, zero while none has
+	Rematch          bool             // The current round is a rematch
+	Ticks            int              // Ticks advanced in the current round
+	Round            int              // Rounds started: 1 for the first, one more per rematch
+	TurnsTaken       map[string]int   // Player ID -> turns applied in the current round
+	Wins             map[string]int   // Player ID -> rounds won in this game, across rematches
+	LastInputs       map[string]Input // Player ID -> most recent move input, for diagnostics
+	Seed             int64            // Seeds Rand; the seed and the inputs reproduce the game
+	Rand             rng.Rand         // Draws food and random spawns, guarded by Mutex
+	Rollback         *Rollback        // Takes back the latest tick for moves that arrive late for it; nil without latency compensation
+	Abandoned        string           // Player who left the current round before it finished, empty while nobody has
+	Opening          []Snake          // Snakes as the current round started, where sudden death puts them back
+
+	TickedAt     time.Time               // When the latest tick was played
+	InputTimings map[string]*InputTiming // Player ID -> how they send moves, watched for play no human could manage
+	Reconnects   map[string]time.Time    // Player ID -> when a player who disconnected from the current round forfeits unless they are back
+
+	Autopilot Autopilot            // Steers Player2 of a practice game on each tick, nil for other games
+	Respawns  map[string]time.Time // Snake ID -> when a crashed snake of a practice game respawns
+
+	ctx      context.Context // Lifetime of the game, canceled once it is removed
+	cancel   context.CancelFunc
+	round    context.Context // Current round, canceled when it ends; guarded by Mutex
+	endRound context.CancelFunc
+}
+
+// Open ties the game's lifetime to parent; call once before the game is published
+func (g *Game) Open(parent context.Context) {
+	g.ctx, g.cancel = context.WithCancel(parent)
+}
+
+// Close cancels the game and any round it is playing
+func (g *Game) Close() {
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// Context is canceled once the game is removed or the server shuts down
+func (g *Game) Context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// StartRound starts a round that lasts until EndRound or until the game is closed
+// Caller must hold Mutex
+func (g *Game) StartRound() context.Context {
+	g.round, g.endRound = context.WithCancel(g.Context())
+	g.Rollback = nil
+	return g.round
+}
+
+// EndRound cancels the current round and reports whether one was running
+// Caller must hold Mutex
+func (g *Game) EndRound() bool {
+	active := g.IsActive()
+	if g.endRound != nil {
+		g.endRound()
+	}
+	return active
+}
+
+// IsActive reports whether a round is being played
+// Caller must hold Mutex
+func (g *Game) IsActive() bool {
+	return g.round != nil && g.round.Err() == nil
+}
+
+// Autopilot picks the next direction of a seat the server steers from the live state
+// It is called under the game lock, so it must not keep the state.
+type Autopilot func(state *GameState) constants.Direction
+
+// Input is a move a player sent
+type Input struct {
+	Direction string    `json:"direction"`
+	At        time.Time `json:"at"`
+	Applied   bool      `json:"applied"` // False if it was rejected, e.g. reversing into the snake
+	Late      bool      `json:"late"`    // Arrived after the tick it was meant for, and was applied to it
+}
+
+// InputTiming is how a player has been sending moves in a game
+type InputTiming struct {
+	Offsets        []time.Duration // How long after a tick each of the latest moves arrived
+	TurnStreak     int             // Ticks in a row the snake turned on
+	Countdown      *Countdown      // The countdown CountdownMoves were sent during
+	CountdownMoves int
+	Flagged        map[string]bool // Anomalies already reported in the game
+}
+
+// Rollback is what it takes to play a game's latest tick again, so that moves meant for it
+// that arrive a tick late are applied to it instead of the tick after
+type Rollback struct {
+	Tick       int                  // The tick it takes back
+	Board      *GameState           // Snakes and food before the tick
+	Respawns   map[string]time.Time // Respawns before the tick
+	Inputs     engine.Inputs        // Turns applied on the tick
+	DailyTurns int                  // Turns the daily run had recorded before the tick
+	Replayed   int                  // Inputs the round's replay had recorded before the tick
+	RandFrom   uint64               // Position of the game's Rand before the tick
+	RandTo     uint64               // and after it; the tick can't be taken back once Rand moved on
+	Late       engine.Inputs        // Turns that arrived late for the tick
+}
+
+// Accepts reports whether a snake's turn can still be applied to the tick taken back: it
+// had no turn there, and the turn changes its direction then
+func (r *Rollback) Accepts(snakeID string, direction constants.Direction) bool {
+	if r == nil {
+		return false
+	}
+	if _, exists := r.Inputs[snakeID]; exists {
+		return false
+	}
+	if _, exists := r.Late[snakeID]; exists {
+		return false
+	}
+	for _, snake := range r.Board.Snakes {
+		if snake.ID == snakeID {
+			return !snake.Down && snake.Direction != direction && !engine.Reverses(snake.Direction, direction)
+		}
+	}
+	return false
+}
+
+// Resume is how far a suspended single player round had been played when it was saved
+type Resume struct {
+	Ticks  int           // Ticks advanced before it was suspended
+	Played time.Duration // Time it was played, not counting pauses
+}
+
+// Countdown is the time left before a game (re)starts, advanced by the tick scheduler
+type Countdown struct {
+	Ends    time.Time // When the game starts
+	Rematch bool      // Restarts a finished game: announced as rematch_countdown, not as state frames
+	Shown   int       // Seconds remaining at the last announcement
 }
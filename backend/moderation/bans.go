@@ -0,0 +1,121 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// bansCollection is the storage collection holding bans
+// Each change is appended; lifting one appends it with Lifted set, and the last wins on load.
+const bansCollection = "bans"
+
+// ErrNotBanned is returned when lifting the ban of a player who has none
+var ErrNotBanned = errors.New("player is not banned")
+
+// Ban keeps a player off the server until it is lifted: their token and username are refused
+// when they connect
+type Ban struct {
+	PlayerID   string    `json:"player_id"`
+	Username   string    `json:"username,omitempty"` // Username when banned, also refused at login
+	Reason     string    `json:"reason,omitempty"`
+	By         string    `json:"by"`
+	Time       time.Time `json:"time"`
+	Lifted     bool      `json:"lifted,omitempty"`
+	LiftedBy   string    `json:"lifted_by,omitempty"`
+	LiftedTime time.Time `json:"lifted_time,omitzero"`
+}
+
+// Bans keeps the banned players in memory and persists every change to storage
+type Bans struct {
+	store storage.Store
+
+	mu     sync.RWMutex
+	active map[string]Ban // Player ID -> ban in effect
+}
+
+// OpenBans loads the bans in effect from the store
+func OpenBans(store storage.Store) (*Bans, error) {
+	b := &Bans{store: store, active: make(map[string]Ban)}
+	err := store.Load(bansCollection, func(raw json.RawMessage) error {
+		var ban Ban
+		if err := json.Unmarshal(raw, &ban); err != nil {
+			return fmt.Errorf("decoding ban: %w", err)
+		}
+		if ban.Lifted {
+			delete(b.active, ban.PlayerID)
+			return nil
+		}
+		b.active[ban.PlayerID] = ban
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Ban bans a player, replacing any ban they had
+// The ban applies even if persisting it fails
+func (b *Bans) Ban(playerID, username, reason, by string) (Ban, error) {
+	ban := Ban{
+		PlayerID: playerID,
+		Username: username,
+		Reason:   reason,
+		By:       by,
+		Time:     time.Now().UTC(),
+	}
+	b.mu.Lock()
+	b.active[playerID] = ban
+	b.mu.Unlock()
+
+	if err := b.store.Append(bansCollection, ban); err != nil {
+		return ban, fmt.Errorf("persisting ban: %w", err)
+	}
+	return ban, nil
+}
+
+// Lift ends a player's ban
+func (b *Bans) Lift(playerID, by string) (Ban, error) {
+	b.mu.Lock()
+	ban, exists := b.active[playerID]
+	if !exists {
+		b.mu.Unlock()
+		return Ban{}, ErrNotBanned
+	}
+	delete(b.active, playerID)
+	b.mu.Unlock()
+
+	ban.Lifted = true
+	ban.LiftedBy = by
+	ban.LiftedTime = time.Now().UTC()
+	if err := b.store.Append(bansCollection, ban); err != nil {
+		return ban, fmt.Errorf("persisting ban: %w", err)
+	}
+	return ban, nil
+}
+
+// Banned returns the ban of a player, false when they have none
+func (b *Bans) Banned(playerID string) (Ban, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ban, exists := b.active[playerID]
+	return ban, exists
+}
+
+// List returns the bans in effect, newest first
+func (b *Bans) List() []Ban {
+	b.mu.RLock()
+	bans := slices.Collect(maps.Values(b.active))
+	b.mu.RUnlock()
+	slices.SortFunc(bans, func(a, b Ban) int {
+		return b.Time.Compare(a.Time)
+	})
+	return bans
+}
@@ -0,0 +1,278 @@
+package moderation
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// sightingsCollection is the storage collection holding the signals accounts were seen with
+const sightingsCollection = "sightings"
+
+// Signals a connection leaves that can tie accounts to one person
+const (
+	SignalDevice  = "device"  // Device ID the client keeps across accounts and sends when it connects
+	SignalAddress = "address" // Network address the connection came from
+)
+
+// signalOrder ranks the signals by how strongly sharing one links accounts
+var signalOrder = []string{SignalDevice, SignalAddress}
+
+// maxSharers is how many accounts may share a signal before it is too common to link them
+// by, such as the address of a school, a café or a mobile carrier
+const maxSharers = 8
+
+// pruneInterval is how often address sightings past their retention are dropped
+const pruneInterval = time.Hour
+
+// Sighting is the first time an account was seen with a signal
+// Only a hash of the signal's value keyed with the server's link secret is kept, which is
+// all it takes to compare accounts. Without the secret it can't be reversed by hashing
+// every possible value, as it could for the few billion IPv4 addresses.
+type Sighting struct {
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username,omitempty"`
+	Signal   string    `json:"signal"`
+	Hash     string    `json:"hash"`
+	Time     time.Time `json:"time"`
+}
+
+// Link is another account that shares signals with a player, a hint that one person plays
+// both rather than proof
+type Link struct {
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username,omitempty"`
+	Signals  []string  `json:"signals"` // Signals shared, strongest first
+	Since    time.Time `json:"since"`   // When the accounts were first seen sharing one
+}
+
+// Links correlates accounts by the signals their connections leave, and persists each
+// signal an account is first seen with to storage
+// Address sightings are forgotten once they are older than the address retention, in memory
+// and in storage; an account seen at the address again is recorded anew.
+type Links struct {
+	store            storage.Store
+	secret           []byte        // Key of the signal hashes
+	addressRetention time.Duration // How long address sightings are kept
+
+	mu        sync.RWMutex
+	sharers   map[string]map[string]Sighting // Signal and hash -> player ID -> sighting
+	signals   map[string][]string            // Player ID -> signals and hashes seen
+	usernames map[string]string              // Player ID -> username last seen with
+	prunedAt  time.Time                      // When expired sightings were last dropped
+}
+
+// OpenLinks loads the signals accounts were seen with from the store, hashing new ones with
+// the secret and keeping address sightings for addressRetention
+// Address sightings that expired while the server was down are dropped from the store.
+func OpenLinks(store storage.Store, secret string, addressRetention time.Duration) (*Links, error) {
+	l := &Links{
+		store:            store,
+		secret:           []byte(secret),
+		addressRetention: addressRetention,
+		sharers:          make(map[string]map[string]Sighting),
+		signals:          make(map[string][]string),
+		usernames:        make(map[string]string),
+	}
+	now := time.Now().UTC()
+	expired := 0
+	err := store.Load(sightingsCollection, func(raw json.RawMessage) error {
+		var sighting Sighting
+		if err := json.Unmarshal(raw, &sighting); err != nil {
+			return fmt.Errorf("decoding sighting: %w", err)
+		}
+		if l.expired(sighting, now) {
+			expired++
+			return nil
+		}
+		l.add(sighting)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.prunedAt = now
+	if expired > 0 {
+		if err := l.rewriteLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// hash returns the hash a signal's value is kept as
+func (l *Links) hash(signal, value string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(signal + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// expired reports whether a sighting is past its retention at now
+func (l *Links) expired(sighting Sighting, now time.Time) bool {
+	return sighting.Signal == SignalAddress && now.Sub(sighting.Time) >= l.addressRetention
+}
+
+// Observe records the signals of a player's connection, by signal; empty values are skipped
+// Signals are kept even if persisting them fails.
+func (l *Links) Observe(playerID, username string, values map[string]string) error {
+	now := time.Now().UTC()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	if now.Sub(l.prunedAt) >= pruneInterval {
+		errs = append(errs, l.pruneLocked(now))
+	}
+	l.usernames[playerID] = username
+	for _, signal := range signalOrder {
+		value := strings.TrimSpace(values[signal])
+		if value == "" {
+			continue
+		}
+		sighting := Sighting{PlayerID: playerID, Username: username, Signal: signal, Hash: l.hash(signal, value), Time: now}
+		if seen, exists := l.sharers[sighting.key()][playerID]; exists {
+			if !l.expired(seen, now) {
+				continue
+			}
+			errs = append(errs, l.pruneLocked(now))
+		}
+		l.add(sighting)
+		if err := l.store.Append(sightingsCollection, sighting); err != nil {
+			errs = append(errs, fmt.Errorf("persisting sighting: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pruneLocked drops the sightings that expired by now and rewrites the store without them
+// Caller must hold mu.
+func (l *Links) pruneLocked(now time.Time) error {
+	l.prunedAt = now
+	dropped := false
+	for key, sharers := range l.sharers {
+		for playerID, sighting := range sharers {
+			if !l.expired(sighting, now) {
+				continue
+			}
+			dropped = true
+			delete(sharers, playerID)
+			l.signals[playerID] = slices.DeleteFunc(l.signals[playerID], func(seen string) bool { return seen == key })
+			if len(l.signals[playerID]) == 0 {
+				delete(l.signals, playerID)
+			}
+		}
+		if len(sharers) == 0 {
+			delete(l.sharers, key)
+		}
+	}
+	if !dropped {
+		return nil
+	}
+	return l.rewriteLocked()
+}
+
+// rewriteLocked replaces the stored sightings with those kept in memory, oldest first
+// Caller must hold mu or own l.
+func (l *Links) rewriteLocked() error {
+	var sightings []Sighting
+	for _, sharers := range l.sharers {
+		for _, sighting := range sharers {
+			sightings = append(sightings, sighting)
+		}
+	}
+	slices.SortFunc(sightings, func(a, b Sighting) int {
+		if c := a.Time.Compare(b.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.PlayerID+a.key(), b.PlayerID+b.key())
+	})
+	records := make([]any, 0, len(sightings))
+	for _, sighting := range sightings {
+		records = append(records, sighting)
+	}
+	if err := l.store.Rewrite(sightingsCollection, records); err != nil {
+		return fmt.Errorf("dropping expired sightings: %w", err)
+	}
+	return nil
+}
+
+// Linked returns the accounts that share a signal with a player, those sharing the most
+// and the strongest signals first
+// Signals shared by more than maxSharers accounts link nobody.
+func (l *Links) Linked(playerID string) []Link {
+	now := time.Now().UTC()
+	l.mu.RLock()
+	linked := make(map[string]*Link)
+	for _, key := range l.signals[playerID] {
+		sharers := l.sharers[key]
+		own := sharers[playerID]
+		if len(sharers) > maxSharers || l.expired(own, now) {
+			continue
+		}
+		for otherID, sighting := range sharers {
+			if otherID == playerID || l.expired(sighting, now) {
+				continue
+			}
+			link, exists := linked[otherID]
+			if !exists {
+				link = &Link{PlayerID: otherID, Username: l.usernames[otherID]}
+				linked[otherID] = link
+			}
+			link.Signals = append(link.Signals, sighting.Signal)
+			since := sighting.Time
+			if own.Time.After(since) {
+				since = own.Time
+			}
+			if link.Since.IsZero() || since.Before(link.Since) {
+				link.Since = since
+			}
+		}
+	}
+	l.mu.RUnlock()
+
+	links := make([]Link, 0, len(linked))
+	for _, link := range linked {
+		slices.SortFunc(link.Signals, func(a, b string) int {
+			return slices.Index(signalOrder, a) - slices.Index(signalOrder, b)
+		})
+		links = append(links, *link)
+	}
+	slices.SortFunc(links, func(a, b Link) int {
+		if len(a.Signals) != len(b.Signals) {
+			return len(b.Signals) - len(a.Signals)
+		}
+		if a.Signals[0] != b.Signals[0] {
+			return slices.Index(signalOrder, a.Signals[0]) - slices.Index(signalOrder, b.Signals[0])
+		}
+		return strings.Compare(a.Username, b.Username)
+	})
+	return links
+}
+
+// add indexes a sighting; caller must hold mu or own l
+func (l *Links) add(sighting Sighting) {
+	key := sighting.key()
+	if l.sharers[key] == nil {
+		l.sharers[key] = make(map[string]Sighting)
+	}
+	l.usernames[sighting.PlayerID] = sighting.Username
+	if _, exists := l.sharers[key][sighting.PlayerID]; exists {
+		return
+	}
+	l.sharers[key][sighting.PlayerID] = sighting
+	l.signals[sighting.PlayerID] = append(l.signals[sighting.PlayerID], key)
+}
+
+// key identifies the signal and value of a sighting
+func (s Sighting) key() string {
+	return s.Signal + ":" + s.Hash
+}
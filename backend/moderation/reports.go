@@ -0,0 +1,220 @@
+// Package moderation keeps the player reports awaiting review by moderators
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// collection is the storage collection holding reports
+// A report is appended again each time it is reviewed; the last version wins on load.
+const collection = "reports"
+
+// Report categories
+const (
+	CategoryAbusiveName = "abusive_name"
+	CategoryChatAbuse   = "chat_abuse"
+	CategoryCheating    = "cheating"
+	CategoryAFKGriefing = "afk_griefing"
+)
+
+// Categories lists the categories a player may report for
+var Categories = []string{CategoryAbusiveName, CategoryChatAbuse, CategoryCheating, CategoryAFKGriefing}
+
+// SystemReporter is the reporter ID of reports the server files itself, such as the anomalies
+// its anti-cheat monitor detects
+const SystemReporter = "system"
+
+// Report statuses
+const (
+	StatusOpen      = "open"
+	StatusResolved  = "resolved"  // A moderator acted on the report
+	StatusDismissed = "dismissed" // A moderator found nothing to act on
+)
+
+// Errors returned by Review
+var (
+	ErrNotFound      = errors.New("report not found")
+	ErrInvalidStatus = errors.New("status must be resolved or dismissed")
+)
+
+// Report is a player's complaint about another player, or an anomaly the server detected in
+// a player's play
+type Report struct {
+	ID           int64              `json:"id"`
+	Time         time.Time          `json:"time"`
+	ReporterID   string             `json:"reporter_id"`
+	ReporterName string             `json:"reporter_name"`
+	TargetID     string             `json:"target_id"`
+	TargetName   string             `json:"target_name"`
+	Category     string             `json:"category"`
+	Comment      string             `json:"comment,omitempty"`
+	Game         *GameContext       `json:"game,omitempty"`     // The game the report is about, if any
+	Anomaly      string             `json:"anomaly,omitempty"`  // What the server detected, for reports by SystemReporter
+	Evidence     map[string]float64 `json:"evidence,omitempty"` // Measurements behind the anomaly
+	Status       string             `json:"status"`
+	ReviewedBy   string             `json:"reviewed_by,omitempty"`
+	ReviewedAt   time.Time          `json:"reviewed_at,omitzero"`
+	Note         string             `json:"note,omitempty"` // Moderator's note on the review
+}
+
+// GameContext is a snapshot of the reported game taken when the report was filed
+// Only the game ID is known when the game was already removed.
+type GameContext struct {
+	GameID     string         `json:"game_id"`
+	Mode       string         `json:"mode,omitempty"`
+	Status     string         `json:"status,omitempty"`
+	Rules      string         `json:"rules,omitempty"`
+	Round      int            `json:"round,omitempty"`
+	Ticks      int            `json:"ticks,omitempty"`
+	Players    []string       `json:"players,omitempty"`
+	Spectators int            `json:"spectators,omitempty"`
+	Scores     map[string]int `json:"scores,omitempty"`      // Username -> score in the current or last round
+	TurnsTaken map[string]int `json:"turns_taken,omitempty"` // Username -> turns applied in the round
+}
+
+// Filter selects reports; zero fields match everything
+type Filter struct {
+	Status   string
+	Category string
+	Target   string // Target player ID or username
+	Limit    int    // Maximum number of reports returned, newest first
+}
+
+// Reports keeps every report in memory for the moderation queue and persists them to storage
+type Reports struct {
+	store storage.Store
+
+	mu      sync.RWMutex
+	reports []*Report
+	nextID  int64
+}
+
+// Open loads the existing reports from the store
+func Open(store storage.Store) (*Reports, error) {
+	r := &Reports{store: store, nextID: 1}
+	byID := make(map[int64]*Report)
+	err := store.Load(collection, func(raw json.RawMessage) error {
+		var report Report
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return fmt.Errorf("decoding report: %w", err)
+		}
+		if existing, ok := byID[report.ID]; ok {
+			*existing = report
+			return nil
+		}
+		byID[report.ID] = &report
+		r.reports = append(r.reports, &report)
+		r.nextID = max(r.nextID, report.ID+1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ValidCategory reports whether category is one players may report for
+func ValidCategory(category string) bool {
+	return slices.Contains(Categories, category)
+}
+
+// File stores a new open report, assigning its ID and time
+// The report is kept in the queue even if persisting it fails
+func (r *Reports) File(report Report) (Report, error) {
+	r.mu.Lock()
+	report.ID = r.nextID
+	r.nextID++
+	report.Time = time.Now().UTC()
+	report.Status = StatusOpen
+	stored := report
+	r.reports = append(r.reports, &stored)
+	r.mu.Unlock()
+
+	if err := r.store.Append(collection, report); err != nil {
+		return report, fmt.Errorf("persisting report: %w", err)
+	}
+	return report, nil
+}
+
+// HasOpen reports whether the reporter already has an open report on the target in the category
+func (r *Reports) HasOpen(reporterID, targetID, category string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, report := range r.reports {
+		if report.Status == StatusOpen && report.ReporterID == reporterID &&
+			report.TargetID == targetID && report.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Review closes a report as resolved or dismissed
+func (r *Reports) Review(id int64, status, reviewer, note string) (Report, error) {
+	if status != StatusResolved && status != StatusDismissed {
+		return Report{}, ErrInvalidStatus
+	}
+
+	r.mu.Lock()
+	index := slices.IndexFunc(r.reports, func(report *Report) bool { return report.ID == id })
+	if index < 0 {
+		r.mu.Unlock()
+		return Report{}, ErrNotFound
+	}
+	report := r.reports[index]
+	report.Status = status
+	report.ReviewedBy = reviewer
+	report.ReviewedAt = time.Now().UTC()
+	report.Note = note
+	reviewed := *report
+	r.mu.Unlock()
+
+	if err := r.store.Append(collection, reviewed); err != nil {
+		return reviewed, fmt.Errorf("persisting report: %w", err)
+	}
+	return reviewed, nil
+}
+
+// Query returns the reports matching the filter, newest first
+func (r *Reports) Query(filter Filter) []Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := []Report{}
+	for _, report := range slices.Backward(r.reports) {
+		if filter.Limit > 0 && len(matches) == filter.Limit {
+			break
+		}
+		if filter.Status != "" && report.Status != filter.Status {
+			continue
+		}
+		if filter.Category != "" && report.Category != filter.Category {
+			continue
+		}
+		if filter.Target != "" && report.TargetID != filter.Target && report.TargetName != filter.Target {
+			continue
+		}
+		matches = append(matches, *report)
+	}
+	return matches
+}
+
+// OpenCount returns the number of reports awaiting review
+func (r *Reports) OpenCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, report := range r.reports {
+		if report.Status == StatusOpen {
+			count++
+		}
+	}
+	return count
+}
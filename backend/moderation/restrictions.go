@@ -0,0 +1,123 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// restrictionsCollection is the storage collection holding shadow restrictions
+// Each change is appended; lifting one appends it with Lifted set, and the last wins on load.
+const restrictionsCollection = "restrictions"
+
+// ErrNotRestricted is returned when lifting the restriction of a player who has none
+var ErrNotRestricted = errors.New("player is not restricted")
+
+// Restriction shadow-restricts an offending player: they can still connect and play
+// single player games, practice and lobby bots, but other players don't see them in the
+// lobby and they take no part in rated or tournament play; nothing tells them so
+type Restriction struct {
+	PlayerID   string    `json:"player_id"`
+	Username   string    `json:"username,omitempty"` // Username when restricted, for moderators
+	Reason     string    `json:"reason,omitempty"`
+	By         string    `json:"by"`
+	Time       time.Time `json:"time"`
+	Lifted     bool      `json:"lifted,omitempty"`
+	LiftedBy   string    `json:"lifted_by,omitempty"`
+	LiftedTime time.Time `json:"lifted_time,omitzero"`
+}
+
+// Restrictions keeps the players under a shadow restriction in memory and persists every
+// change to storage
+type Restrictions struct {
+	store storage.Store
+
+	mu     sync.RWMutex
+	active map[string]Restriction // Player ID -> restriction in effect
+}
+
+// OpenRestrictions loads the restrictions in effect from the store
+func OpenRestrictions(store storage.Store) (*Restrictions, error) {
+	r := &Restrictions{store: store, active: make(map[string]Restriction)}
+	err := store.Load(restrictionsCollection, func(raw json.RawMessage) error {
+		var restriction Restriction
+		if err := json.Unmarshal(raw, &restriction); err != nil {
+			return fmt.Errorf("decoding restriction: %w", err)
+		}
+		if restriction.Lifted {
+			delete(r.active, restriction.PlayerID)
+			return nil
+		}
+		r.active[restriction.PlayerID] = restriction
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Restrict puts a player under a shadow restriction, replacing any they had
+// The restriction applies even if persisting it fails
+func (r *Restrictions) Restrict(playerID, username, reason, by string) (Restriction, error) {
+	restriction := Restriction{
+		PlayerID: playerID,
+		Username: username,
+		Reason:   reason,
+		By:       by,
+		Time:     time.Now().UTC(),
+	}
+	r.mu.Lock()
+	r.active[playerID] = restriction
+	r.mu.Unlock()
+
+	if err := r.store.Append(restrictionsCollection, restriction); err != nil {
+		return restriction, fmt.Errorf("persisting restriction: %w", err)
+	}
+	return restriction, nil
+}
+
+// Lift ends a player's restriction
+func (r *Restrictions) Lift(playerID, by string) (Restriction, error) {
+	r.mu.Lock()
+	restriction, exists := r.active[playerID]
+	if !exists {
+		r.mu.Unlock()
+		return Restriction{}, ErrNotRestricted
+	}
+	delete(r.active, playerID)
+	r.mu.Unlock()
+
+	restriction.Lifted = true
+	restriction.LiftedBy = by
+	restriction.LiftedTime = time.Now().UTC()
+	if err := r.store.Append(restrictionsCollection, restriction); err != nil {
+		return restriction, fmt.Errorf("persisting restriction: %w", err)
+	}
+	return restriction, nil
+}
+
+// Restricted reports whether a player is under a shadow restriction
+func (r *Restrictions) Restricted(playerID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.active[playerID]
+	return exists
+}
+
+// List returns the restrictions in effect, newest first
+func (r *Restrictions) List() []Restriction {
+	r.mu.RLock()
+	restrictions := slices.Collect(maps.Values(r.active))
+	r.mu.RUnlock()
+	slices.SortFunc(restrictions, func(a, b Restriction) int {
+		return b.Time.Compare(a.Time)
+	})
+	return restrictions
+}
@@ -0,0 +1,132 @@
+// Package names validates usernames and compares them the way people read them
+//
+// Usernames may use any script. They are stored in NFC with their whitespace collapsed, so
+// the same name typed on different keyboards is stored the same way, and compared by a key
+// that also folds case, accents, compatibility forms and characters that look alike, so
+// "Bob", "ВОВ" (Cyrillic) and "ｂｏｂ" (fullwidth) can't be told apart by other players
+// and can't be held at once.
+package names
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Display width limits of a username, in terminal columns: wide characters such as CJK
+// ideographs and most emoji take two
+const (
+	MinWidth = 2
+	MaxWidth = 20
+)
+
+// maxMarks is how many combining marks may stack on one character, enough for any
+// script's diacritics but not for text that spills onto the lines around it
+const maxMarks = 3
+
+var (
+	ErrTooShort         = errors.New("username is too short")
+	ErrTooLong          = errors.New("username is too long")
+	ErrInvalidCharacter = errors.New("username contains a character that is not allowed")
+)
+
+// allowed are the categories a username is made of: letters, combining marks, digits,
+// punctuation and symbols; controls, invisible formatting characters, private use and
+// unassigned code points are refused
+var allowed = []*unicode.RangeTable{unicode.L, unicode.M, unicode.N, unicode.P, unicode.S}
+
+// confusables maps characters that look like a Latin letter or digit, once case is folded
+// and marks are dropped, to that letter
+// A subset of the Unicode confusables for the scripts players mix up in practice.
+var confusables = map[rune]rune{
+	'0': 'o', '1': 'l', '|': 'l', 'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd', 'ħ': 'h',
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k',
+	'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l', 'ɡ': 'g',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+}
+
+// sequences are letter pairs that read as one letter
+var sequences = strings.NewReplacer("rn", "m", "vv", "w")
+
+// Normalize returns the stored form of a username: NFC, with runs of whitespace collapsed
+// into single spaces and none around it
+// Fails when the name has a character that is not allowed or its display width is out of
+// bounds.
+func Normalize(raw string) (string, error) {
+	name := strings.Join(strings.Fields(norm.NFC.String(raw)), " ")
+
+	marks := 0
+	for _, r := range name {
+		if r != ' ' && !unicode.In(r, allowed...) {
+			return "", ErrInvalidCharacter
+		}
+		if unicode.Is(unicode.M, r) {
+			marks++
+			if marks > maxMarks {
+				return "", ErrInvalidCharacter
+			}
+			continue
+		}
+		marks = 0
+	}
+
+	switch columns := Width(name); {
+	case columns < MinWidth:
+		return "", ErrTooShort
+	case columns > MaxWidth:
+		return "", ErrTooLong
+	}
+	return name, nil
+}
+
+// Width returns the number of columns a name takes on screen
+// Wide and fullwidth characters take two, combining marks none.
+func Width(name string) int {
+	columns := 0
+	for _, r := range name {
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			continue
+		}
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			columns += 2
+		default:
+			columns++
+		}
+	}
+	return columns
+}
+
+// Key returns the form usernames are compared in: names that look alike share a key
+// Compatibility forms are decomposed, case is folded, combining marks are dropped and
+// confusable characters are mapped to the Latin letters they look like.
+func Key(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	folded := norm.NFKD.String(cases.Fold().String(norm.NFKD.String(name)))
+
+	var b strings.Builder
+	b.Grow(len(folded))
+	for _, r := range folded {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if latin, exists := confusables[r]; exists {
+			r = latin
+		}
+		b.WriteRune(r)
+	}
+	return sequences.Replace(b.String())
+}
+
+// Equal reports whether two usernames look alike
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
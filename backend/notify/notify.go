@@ -0,0 +1,135 @@
+// Package notify posts notable events, such as a tournament starting, to chat webhooks
+package notify
+
+import (
+	"log/slog"
+
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/metrics"
+)
+
+// Event types, each toggled in the notifications config
+const (
+	EventTournamentStart = "tournament_start"
+	EventLeaderboardTop  = "leaderboard_top"
+	EventWinStreak       = "win_streak"
+)
+
+// bufferSize is the number of messages queued for the webhooks before new ones are dropped
+const bufferSize = 64
+
+var (
+	messagesSent = metrics.NewCounterVec(
+		"snake_notifications_sent_total",
+		"Notifications posted to a webhook, by event type",
+		"event",
+	)
+	messagesDropped = metrics.NewCounter(
+		"snake_notifications_dropped_total",
+		"Notifications dropped because the queue was full",
+	)
+	messagesFailed = metrics.NewCounterVec(
+		"snake_notifications_failed_total",
+		"Notifications a webhook refused or that couldn't be delivered, by webhook",
+		"webhook",
+	)
+)
+
+// Field is a labeled value shown alongside a message
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Message is one notification, formatted by each webhook for its chat service
+type Message struct {
+	Event  string
+	Title  string
+	Text   string
+	Fields []Field
+}
+
+// Webhook posts messages to one chat service
+// Post is only called from the notifier's goroutine, one message at a time
+type Webhook interface {
+	Name() string
+	Post(message Message) error
+	Close() error
+}
+
+// NewWebhooks creates a webhook for every URL set in the configuration
+func NewWebhooks(cfg config.Notifications) []Webhook {
+	var webhooks []Webhook
+	if cfg.DiscordWebhook != "" {
+		webhooks = append(webhooks, NewDiscord(cfg.DiscordWebhook))
+	}
+	if cfg.SlackWebhook != "" {
+		webhooks = append(webhooks, NewSlack(cfg.SlackWebhook))
+	}
+	return webhooks
+}
+
+// Notifier queues messages and posts them to every webhook on a background goroutine
+// Notifying never blocks gameplay: when the queue is full the message is dropped and counted.
+// A nil *Notifier discards every message, so notifications can be left unconfigured.
+type Notifier struct {
+	webhooks []Webhook
+	messages chan Message
+	done     chan struct{}
+}
+
+// New starts a notifier posting to webhooks, or returns nil when there are none
+func New(webhooks []Webhook) *Notifier {
+	if len(webhooks) == 0 {
+		return nil
+	}
+	n := &Notifier{
+		webhooks: webhooks,
+		messages: make(chan Message, bufferSize),
+		done:     make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues a message for every webhook
+func (n *Notifier) Notify(message Message) {
+	if n == nil {
+		return
+	}
+	select {
+	case n.messages <- message:
+	default:
+		messagesDropped.Inc()
+	}
+}
+
+// Close posts the queued messages and closes the webhooks
+// No messages may be sent after Close
+func (n *Notifier) Close() {
+	if n == nil {
+		return
+	}
+	close(n.messages)
+	<-n.done
+	for _, webhook := range n.webhooks {
+		webhook.Close()
+	}
+}
+
+// run posts queued messages until the notifier is closed
+// Failed posts are logged and dropped rather than retried, so a dead webhook can't hold up
+// the messages behind them
+func (n *Notifier) run() {
+	defer close(n.done)
+	for message := range n.messages {
+		for _, webhook := range n.webhooks {
+			if err := webhook.Post(message); err != nil {
+				messagesFailed.Inc(webhook.Name())
+				slog.Warn("Failed to post notification", "webhook", webhook.Name(), "event", message.Event, "error", err)
+				continue
+			}
+			messagesSent.Inc(message.Event)
+		}
+	}
+}
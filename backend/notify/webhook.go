@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds each request to a webhook
+const requestTimeout = 10 * time.Second
+
+// discordColor is the accent color of Discord embeds, the snake green of the web client
+const discordColor = 0x4caf50
+
+// webhook posts messages as JSON to a chat service's incoming webhook URL
+type webhook struct {
+	name   string
+	url    string
+	client *http.Client
+	encode func(message Message) any // Shapes the request body for the chat service
+}
+
+// NewDiscord creates a webhook posting each message as an embed to a Discord channel
+func NewDiscord(url string) Webhook {
+	return &webhook{
+		name:   "discord",
+		url:    url,
+		client: &http.Client{Timeout: requestTimeout},
+		encode: discordMessage,
+	}
+}
+
+// NewSlack creates a webhook posting each message as blocks to a Slack channel
+func NewSlack(url string) Webhook {
+	return &webhook{
+		name:   "slack",
+		url:    url,
+		client: &http.Client{Timeout: requestTimeout},
+		encode: slackMessage,
+	}
+}
+
+func (w *webhook) Name() string {
+	return w.name
+}
+
+// Post sends the message; any non-2xx response is an error
+func (w *webhook) Post(message Message) error {
+	body, err := json.Marshal(w.encode(message))
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s webhook responded with %s", w.name, resp.Status)
+	}
+	return nil
+}
+
+// Close releases idle connections to the webhook
+func (w *webhook) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+// discordMessage shapes a message as a Discord embed with inline fields
+func discordMessage(message Message) any {
+	fields := make([]map[string]any, len(message.Fields))
+	for i, field := range message.Fields {
+		fields[i] = map[string]any{"name": field.Name, "value": field.Value, "inline": true}
+	}
+	return map[string]any{
+		"username": "Snake",
+		"embeds": []map[string]any{{
+			"title":       message.Title,
+			"description": message.Text,
+			"color":       discordColor,
+			"fields":      fields,
+		}},
+		// Usernames in messages must not ping anyone
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
+
+// slackMessage shapes a message as Slack blocks, with a plain text fallback for
+// notifications
+func slackMessage(message Message) any {
+	section := map[string]any{
+		"type": "section",
+		"text": map[string]any{"type": "mrkdwn", "text": slackEscape(message.Text)},
+	}
+	if len(message.Fields) > 0 {
+		fields := make([]map[string]any, len(message.Fields))
+		for i, field := range message.Fields {
+			fields[i] = map[string]any{"type": "mrkdwn", "text": "*" + slackEscape(field.Name) + "*\n" + slackEscape(field.Value)}
+		}
+		section["fields"] = fields
+	}
+	return map[string]any{
+		"text": message.Title + ": " + message.Text,
+		"blocks": []map[string]any{
+			{"type": "header", "text": map[string]any{"type": "plain_text", "text": message.Title}},
+			section,
+		},
+	}
+}
+
+// slackEscape escapes the characters Slack reads as control sequences, so a username
+// can't mention a channel or link somewhere
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,74 @@
+package profile
+
+import "time"
+
+// AbandonWindow is how many of a player's latest multiplayer rounds their disconnect rate
+// is taken over
+const AbandonWindow = 20
+
+// AbandonLimit is how many rounds within the window a player may abandon before they are
+// barred from rated play
+const AbandonLimit = 3
+
+// AbandonBar is how long a player who reached AbandonLimit is barred from rated play after
+// the round they last abandoned
+const AbandonBar = 30 * time.Minute
+
+// abandonRecord is which of a player's latest rounds they abandoned
+type abandonRecord struct {
+	rounds []bool    // The latest AbandonWindow rounds, oldest first; true for those abandoned
+	last   time.Time // When the player last abandoned a round
+}
+
+// abandoned counts the rounds of the window the player abandoned
+func (r *abandonRecord) abandoned() int {
+	count := 0
+	for _, abandoned := range r.rounds {
+		if abandoned {
+			count++
+		}
+	}
+	return count
+}
+
+// applyAbandonLocked adds a recorded round to the player's window; caller must hold mu or
+// own p exclusively
+func (p *Profiles) applyAbandonLocked(event opponentEvent) {
+	record := p.abandons[event.PlayerID]
+	if record == nil {
+		record = &abandonRecord{}
+		p.abandons[event.PlayerID] = record
+	}
+	record.rounds = append(record.rounds, event.Abandoned)
+	if len(record.rounds) > AbandonWindow {
+		record.rounds = record.rounds[len(record.rounds)-AbandonWindow:]
+	}
+	if event.Abandoned {
+		record.last = event.Time
+	}
+}
+
+// DisconnectRate returns the share of the player's latest rounds, up to AbandonWindow, that
+// they abandoned, and how many rounds that is taken over
+func (p *Profiles) DisconnectRate(playerID string) (float64, int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	record := p.abandons[playerID]
+	if record == nil || len(record.rounds) == 0 {
+		return 0, 0
+	}
+	return float64(record.abandoned()) / float64(len(record.rounds)), len(record.rounds)
+}
+
+// BarredUntil returns when the player may play rated rounds again after abandoning
+// AbandonLimit of their latest rounds; zero when they were never barred, and a time that
+// has passed once the bar is over
+func (p *Profiles) BarredUntil(playerID string) time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	record := p.abandons[playerID]
+	if record == nil || record.abandoned() < AbandonLimit {
+		return time.Time{}
+	}
+	return record.last.Add(AbandonBar)
+}
@@ -0,0 +1,60 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// campaignCollection is the storage collection holding cleared campaign levels
+const campaignCollection = "campaign"
+
+// levelEvent is one persisted cleared campaign level
+type levelEvent struct {
+	Time     time.Time `json:"time"`
+	PlayerID string    `json:"player_id"`
+	Level    int       `json:"level"`
+	Score    int       `json:"score"`
+}
+
+// loadCampaign replays the cleared campaign levels; called from Open
+func (p *Profiles) loadCampaign() error {
+	return p.store.Load(campaignCollection, func(raw json.RawMessage) error {
+		var event levelEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding campaign level: %w", err)
+		}
+		p.applyLevelLocked(event)
+		return nil
+	})
+}
+
+// ClearLevel records that the player cleared a campaign level, unlocking the next one
+// Returns false when the player had already cleared it.
+func (p *Profiles) ClearLevel(playerID string, level, score int, now time.Time) (bool, error) {
+	event := levelEvent{Time: now.UTC(), PlayerID: playerID, Level: level, Score: score}
+	p.mu.Lock()
+	if level <= p.campaign[playerID] {
+		p.mu.Unlock()
+		return false, nil
+	}
+	p.applyLevelLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(campaignCollection, event); err != nil {
+		return true, fmt.Errorf("persisting campaign level: %w", err)
+	}
+	return true, nil
+}
+
+// LevelsCleared returns the highest campaign level the player cleared, zero before the first
+func (p *Profiles) LevelsCleared(playerID string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.campaign[playerID]
+}
+
+// applyLevelLocked applies a cleared level; caller must hold p.mu or own p exclusively
+func (p *Profiles) applyLevelLocked(event levelEvent) {
+	p.campaign[event.PlayerID] = max(p.campaign[event.PlayerID], event.Level)
+}
@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// circuitCollection is the storage collection holding tournament placements
+const circuitCollection = "circuit"
+
+// SeasonLayout is the form of a season: seasons are calendar months, in UTC
+const SeasonLayout = "2006-01"
+
+// Circuit points a player needs within one season for the circuit titles
+const (
+	ContenderPoints   = 100
+	CircuitStarPoints = 500
+)
+
+// CircuitStanding is a player's record in a season of the tournament circuit
+type CircuitStanding struct {
+	Rank        int    `json:"rank"`
+	PlayerID    string `json:"player_id"`
+	Username    string `json:"username"` // As in the player's latest tournament
+	Points      int    `json:"points"`
+	Tournaments int    `json:"tournaments"` // Tournaments finished in the season
+	Wins        int    `json:"wins"`        // Tournaments won in the season
+}
+
+// placementEvent is one persisted tournament placing
+type placementEvent struct {
+	Time         time.Time `json:"time"`
+	Season       string    `json:"season"`
+	TournamentID string    `json:"tournament_id"`
+	PlayerID     string    `json:"player_id"`
+	Username     string    `json:"username"`
+	Finish       int       `json:"finish"`
+	Points       int       `json:"points"`
+}
+
+// Season returns the season a time falls in
+func Season(t time.Time) string {
+	return t.UTC().Format(SeasonLayout)
+}
+
+// PlacementPoints returns the circuit points a tournament placing earns
+func PlacementPoints(finish int) int {
+	switch {
+	case finish == 1:
+		return 100
+	case finish == 2:
+		return 70
+	case finish <= 4:
+		return 50
+	case finish <= 8:
+		return 30
+	case finish <= 16:
+		return 20
+	}
+	return 10
+}
+
+// loadCircuit replays the recorded placements; called from Open
+func (p *Profiles) loadCircuit() error {
+	return p.store.Load(circuitCollection, func(raw json.RawMessage) error {
+		var event placementEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding placement: %w", err)
+		}
+		p.applyPlacementLocked(event)
+		return nil
+	})
+}
+
+// RecordPlacement adds the points of a tournament placing to the player's circuit season
+// Returns the points earned and the player's season total.
+func (p *Profiles) RecordPlacement(tournamentID, playerID, username string, finish int, now time.Time) (int, int, error) {
+	event := placementEvent{
+		Time:         now.UTC(),
+		Season:       Season(now),
+		TournamentID: tournamentID,
+		PlayerID:     playerID,
+		Username:     username,
+		Finish:       finish,
+		Points:       PlacementPoints(finish),
+	}
+	p.mu.Lock()
+	p.applyPlacementLocked(event)
+	total := p.circuit[event.Season][playerID].Points
+	p.mu.Unlock()
+
+	if err := p.store.Append(circuitCollection, event); err != nil {
+		return event.Points, total, fmt.Errorf("persisting placement: %w", err)
+	}
+	return event.Points, total, nil
+}
+
+// LatestSeason returns the latest season a placement was recorded in, empty before the first
+func (p *Profiles) LatestSeason() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.latestSeason
+}
+
+// CircuitStandings ranks the players of a season by points, then tournaments won, then
+// fewest tournaments played
+func (p *Profiles) CircuitStandings(season string) []CircuitStanding {
+	p.mu.RLock()
+	standings := make([]CircuitStanding, 0, len(p.circuit[season]))
+	for _, standing := range p.circuit[season] {
+		standings = append(standings, *standing)
+	}
+	p.mu.RUnlock()
+
+	slices.SortFunc(standings, func(a, b CircuitStanding) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.Wins, a.Wins),
+			cmp.Compare(a.Tournaments, b.Tournaments),
+			strings.Compare(normalize(a.Username), normalize(b.Username)),
+		)
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+// applyPlacementLocked adds a placing to its season; caller must hold mu or own p exclusively
+func (p *Profiles) applyPlacementLocked(event placementEvent) {
+	season, exists := p.circuit[event.Season]
+	if !exists {
+		season = make(map[string]*CircuitStanding)
+		p.circuit[event.Season] = season
+	}
+	standing, exists := season[event.PlayerID]
+	if !exists {
+		standing = &CircuitStanding{PlayerID: event.PlayerID}
+		season[event.PlayerID] = standing
+	}
+	standing.Username = event.Username
+	standing.Points += event.Points
+	standing.Tournaments++
+	if event.Finish == 1 {
+		standing.Wins++
+	}
+
+	stats := p.stats[event.PlayerID]
+	stats.bestSeason = max(stats.bestSeason, standing.Points)
+	p.stats[event.PlayerID] = stats
+	p.latestSeason = max(p.latestSeason, event.Season)
+}
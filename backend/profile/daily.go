@@ -0,0 +1,85 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dailyCollection is the storage collection holding ranked daily runs
+const dailyCollection = "daily_runs"
+
+// dailyEvent is one persisted personal best of a day's daily run
+type dailyEvent struct {
+	Time     time.Time `json:"time"`
+	Date     string    `json:"date"`
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"`
+	Score    int       `json:"score"`
+}
+
+// loadDaily replays the ranked daily runs; called from Open
+// Only the latest day is kept, older days have been reset.
+func (p *Profiles) loadDaily() error {
+	return p.store.Load(dailyCollection, func(raw json.RawMessage) error {
+		var event dailyEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding daily run: %w", err)
+		}
+		p.applyDailyLocked(event)
+		return nil
+	})
+}
+
+// RecordDailyRun ranks a verified daily run of the given day; only a player's best run of
+// the day is kept, and runs of a day that has already been reset are dropped
+// Returns whether the run is a new personal best of the day.
+func (p *Profiles) RecordDailyRun(date, playerID, username string, score int, now time.Time) (bool, error) {
+	event := dailyEvent{Time: now.UTC(), Date: date, PlayerID: playerID, Username: username, Score: score}
+	p.mu.Lock()
+	if best, exists := p.daily[playerID]; score <= 0 || date < p.dailyDate || date == p.dailyDate && exists && score <= best.Score {
+		p.mu.Unlock()
+		return false, nil
+	}
+	p.applyDailyLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(dailyCollection, event); err != nil {
+		return true, fmt.Errorf("persisting daily run: %w", err)
+	}
+	return true, nil
+}
+
+// DailyLeaderboard ranks the best daily runs of a day, empty for any day but the latest
+func (p *Profiles) DailyLeaderboard(date string) []HighScore {
+	p.mu.RLock()
+	scores := make([]HighScore, 0, len(p.daily))
+	if date == p.dailyDate {
+		for _, best := range p.daily {
+			scores = append(scores, *best)
+		}
+	}
+	p.mu.RUnlock()
+	return rank(scores)
+}
+
+// applyDailyLocked applies a daily run, resetting the ranking when it is of a later day
+// Caller must hold p.mu or own p exclusively.
+func (p *Profiles) applyDailyLocked(event dailyEvent) {
+	switch {
+	case event.Date < p.dailyDate:
+		return
+	case event.Date > p.dailyDate:
+		p.daily = make(map[string]*HighScore)
+		p.dailyDate = event.Date
+	}
+	if best, exists := p.daily[event.PlayerID]; exists && best.Score >= event.Score {
+		return
+	}
+	p.daily[event.PlayerID] = &HighScore{
+		PlayerID: event.PlayerID,
+		Username: event.Username,
+		Score:    event.Score,
+		SetAt:    event.Time,
+	}
+}
@@ -0,0 +1,158 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/names"
+)
+
+// opponentsCollection is the storage collection holding finished multiplayer rounds
+const opponentsCollection = "opponents"
+
+// MaxRecentOpponents is how many distinct opponents are remembered per player
+const MaxRecentOpponents = 10
+
+// MatchHistorySize is how many of a player's latest rounds are remembered
+const MatchHistorySize = 50
+
+// Round results, from the player's point of view
+const (
+	ResultWin  = "win"
+	ResultLoss = "loss"
+	ResultTie  = "tie"
+)
+
+// Opponent is someone a player recently played against
+type Opponent struct {
+	ID         string    `json:"id"` // Player ID the opponent had in the last round
+	Username   string    `json:"username"`
+	LastGameID string    `json:"last_game_id"`
+	LastPlayed time.Time `json:"last_played"`
+	LastResult string    `json:"last_result"` // The player's result in the last round
+	Rounds     int       `json:"rounds"`      // Rounds played together while remembered
+}
+
+// Round is a finished multiplayer round from a player's point of view
+type Round struct {
+	GameID           string    `json:"game_id"`
+	OpponentID       string    `json:"opponent_id"` // Player ID the opponent had in the round
+	OpponentUsername string    `json:"opponent_username"`
+	Result           string    `json:"result"`
+	PlayedAt         time.Time `json:"played_at"`
+}
+
+// opponentEvent is one persisted round between a player and an opponent
+type opponentEvent struct {
+	Time       time.Time `json:"time"`
+	PlayerID   string    `json:"player_id"`
+	OpponentID string    `json:"opponent_id"`
+	Username   string    `json:"username"`
+	GameID     string    `json:"game_id"`
+	Result     string    `json:"result"`
+	Abandoned  bool      `json:"abandoned,omitempty"` // The player left before the round finished
+}
+
+// loadOpponents replays the recorded rounds; called from Open
+func (p *Profiles) loadOpponents() error {
+	return p.store.Load(opponentsCollection, func(raw json.RawMessage) error {
+		var event opponentEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding opponent: %w", err)
+		}
+		p.applyRoundLocked(event)
+		p.applyHistoryLocked(event)
+		p.applyStatsLocked(event)
+		p.applyRatingLocked(event)
+		p.applyAbandonLocked(event)
+		return nil
+	})
+}
+
+// RecordRound remembers that the player played a round against an opponent, abandoned
+// when the player left before it finished
+// Opponents are matched by username, as a returning player may get a new ID.
+func (p *Profiles) RecordRound(playerID, opponentID, opponentUsername, gameID, result string, abandoned bool) error {
+	event := opponentEvent{
+		Time:       time.Now().UTC(),
+		PlayerID:   playerID,
+		OpponentID: opponentID,
+		Username:   opponentUsername,
+		GameID:     gameID,
+		Result:     result,
+		Abandoned:  abandoned,
+	}
+	p.mu.Lock()
+	p.applyRoundLocked(event)
+	p.applyHistoryLocked(event)
+	p.applyStatsLocked(event)
+	p.applyRatingLocked(event)
+	p.applyAbandonLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(opponentsCollection, event); err != nil {
+		return fmt.Errorf("persisting opponent: %w", err)
+	}
+	return nil
+}
+
+// RecentOpponents returns the player's recent opponents, most recent first
+func (p *Profiles) RecentOpponents(playerID string) []Opponent {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.opponents[playerID])
+}
+
+// MatchHistory returns the player's latest rounds, most recent first
+func (p *Profiles) MatchHistory(playerID string) []Round {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	history := slices.Clone(p.history[playerID])
+	if history == nil {
+		return []Round{}
+	}
+	return history
+}
+
+// applyHistoryLocked adds the round to the front of the player's history; caller must hold
+// mu or own p exclusively
+func (p *Profiles) applyHistoryLocked(event opponentEvent) {
+	history := slices.Insert(p.history[event.PlayerID], 0, Round{
+		GameID:           event.GameID,
+		OpponentID:       event.OpponentID,
+		OpponentUsername: event.Username,
+		Result:           event.Result,
+		PlayedAt:         event.Time,
+	})
+	if len(history) > MatchHistorySize {
+		history = history[:MatchHistorySize]
+	}
+	p.history[event.PlayerID] = history
+}
+
+// applyRoundLocked moves the opponent to the front of the player's list; caller must
+// hold mu or own p exclusively
+func (p *Profiles) applyRoundLocked(event opponentEvent) {
+	opponents := p.opponents[event.PlayerID]
+	opponent := Opponent{Username: event.Username}
+	if index := slices.IndexFunc(opponents, func(o Opponent) bool {
+		return names.Equal(o.Username, event.Username)
+	}); index >= 0 {
+		opponent = opponents[index]
+		opponents = slices.Delete(opponents, index, index+1)
+	}
+	opponent.ID = event.OpponentID
+	opponent.Username = event.Username
+	opponent.LastGameID = event.GameID
+	opponent.LastPlayed = event.Time
+	opponent.LastResult = event.Result
+	opponent.Rounds++
+
+	opponents = slices.Insert(opponents, 0, opponent)
+	if len(opponents) > MaxRecentOpponents {
+		opponents = opponents[:MaxRecentOpponents]
+	}
+	p.opponents[event.PlayerID] = opponents
+}
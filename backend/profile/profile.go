@@ -0,0 +1,208 @@
+// Package profile keeps per-player settings and history that outlive a connection
+//
+// Profiles are keyed by player ID, which a player keeps across reconnects through their
+// token. Every change is appended to storage and the profiles are rebuilt from the log on
+// start.
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/names"
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// mutesCollection is the storage collection holding mute and unmute events
+const mutesCollection = "mutes"
+
+// MaxMutes is the most usernames a player may mute
+const MaxMutes = 100
+
+// ErrTooManyMutes is returned by Mute when the player already muted MaxMutes usernames
+var ErrTooManyMutes = errors.New("too many muted players")
+
+// muteEvent is one persisted mute or unmute
+type muteEvent struct {
+	Time     time.Time `json:"time"`
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"` // Muted username, lowercased
+	Muted    bool      `json:"muted"`    // False for an unmute
+}
+
+// Profiles holds every player's settings in memory and persists changes to storage
+type Profiles struct {
+	store storage.Store
+
+	mu        sync.RWMutex
+	mutes     map[string]map[string]string // Player ID -> names.Key of usernames they muted -> lowercased username
+	opponents map[string][]Opponent        // Player ID -> recent opponents, most recent first
+	history   map[string][]Round           // Player ID -> latest rounds, most recent first
+	stats     map[string]roundStats        // Player ID -> finished multiplayer rounds
+	ratings   map[string]float64           // Player ID -> Elo rating, unset for InitialRating
+	abandons  map[string]*abandonRecord    // Player ID -> their latest rounds and the ones they abandoned
+
+	circuit      map[string]map[string]*CircuitStanding // Season -> player ID -> their circuit record
+	latestSeason string                                 // Latest season with a placement
+
+	earned   map[string]map[string]time.Time // Player ID -> title ID -> when it was earned
+	equipped map[string]string               // Player ID -> equipped title ID
+
+	campaign map[string]int                   // Player ID -> highest campaign level cleared
+	scores   map[string]map[string]*HighScore // Leaderboard -> player ID -> their best single player score
+
+	daily     map[string]*HighScore // Player ID -> their best daily run of dailyDate
+	dailyDate string                // Latest day with a ranked daily run
+
+	saved map[string]SavedGame // Player ID -> their suspended single player game
+
+	singlePlayer map[string]*SinglePlayerStats // Player ID -> their finished single player games
+}
+
+// Open rebuilds the profiles from the store
+func Open(store storage.Store) (*Profiles, error) {
+	p := &Profiles{
+		store:     store,
+		mutes:     make(map[string]map[string]string),
+		opponents: make(map[string][]Opponent),
+		history:   make(map[string][]Round),
+		stats:     make(map[string]roundStats),
+		ratings:   make(map[string]float64),
+		abandons:  make(map[string]*abandonRecord),
+		circuit:   make(map[string]map[string]*CircuitStanding),
+		earned:    make(map[string]map[string]time.Time),
+		equipped:  make(map[string]string),
+		campaign:  make(map[string]int),
+		scores:    make(map[string]map[string]*HighScore),
+		daily:     make(map[string]*HighScore),
+		saved:     make(map[string]SavedGame),
+
+		singlePlayer: make(map[string]*SinglePlayerStats),
+	}
+	err := store.Load(mutesCollection, func(raw json.RawMessage) error {
+		var event muteEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding mute: %w", err)
+		}
+		p.applyMuteLocked(event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.loadOpponents(); err != nil {
+		return nil, err
+	}
+	if err := p.loadTitles(); err != nil {
+		return nil, err
+	}
+	if err := p.loadCircuit(); err != nil {
+		return nil, err
+	}
+	if err := p.loadCampaign(); err != nil {
+		return nil, err
+	}
+	if err := p.loadScores(); err != nil {
+		return nil, err
+	}
+	if err := p.loadDaily(); err != nil {
+		return nil, err
+	}
+	if err := p.loadSavedGames(); err != nil {
+		return nil, err
+	}
+	if err := p.loadSinglePlayerStats(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// normalize is the form muted usernames are stored and sorted in; they are compared by
+// names.Key, so a mute also covers names that look alike
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Mute hides the messages of username from the player
+// Muting a username twice is not an error
+func (p *Profiles) Mute(playerID, username string) error {
+	username = normalize(username)
+	p.mu.Lock()
+	muted := p.mutes[playerID]
+	if _, exists := muted[names.Key(username)]; exists {
+		p.mu.Unlock()
+		return nil
+	}
+	if len(muted) >= MaxMutes {
+		p.mu.Unlock()
+		return ErrTooManyMutes
+	}
+	event := muteEvent{Time: time.Now().UTC(), PlayerID: playerID, Username: username, Muted: true}
+	p.applyMuteLocked(event)
+	p.mu.Unlock()
+	return p.persist(event)
+}
+
+// Unmute shows the messages of username to the player again
+func (p *Profiles) Unmute(playerID, username string) error {
+	username = normalize(username)
+	p.mu.Lock()
+	if _, exists := p.mutes[playerID][names.Key(username)]; !exists {
+		p.mu.Unlock()
+		return nil
+	}
+	event := muteEvent{Time: time.Now().UTC(), PlayerID: playerID, Username: username}
+	p.applyMuteLocked(event)
+	p.mu.Unlock()
+	return p.persist(event)
+}
+
+// HasMuted reports whether the player muted username
+func (p *Profiles) HasMuted(playerID, username string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, muted := p.mutes[playerID][names.Key(username)]
+	return muted
+}
+
+// Muted returns the usernames the player muted, sorted
+func (p *Profiles) Muted(playerID string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	usernames := make([]string, 0, len(p.mutes[playerID]))
+	for _, username := range p.mutes[playerID] {
+		usernames = append(usernames, username)
+	}
+	slices.Sort(usernames)
+	return usernames
+}
+
+// applyMuteLocked applies a mute event; caller must hold mu or own p exclusively
+func (p *Profiles) applyMuteLocked(event muteEvent) {
+	if !event.Muted {
+		delete(p.mutes[event.PlayerID], names.Key(event.Username))
+		if len(p.mutes[event.PlayerID]) == 0 {
+			delete(p.mutes, event.PlayerID)
+		}
+		return
+	}
+	muted, exists := p.mutes[event.PlayerID]
+	if !exists {
+		muted = make(map[string]string)
+		p.mutes[event.PlayerID] = muted
+	}
+	muted[names.Key(event.Username)] = event.Username
+}
+
+// persist appends an event; the change stays applied in memory even if it fails
+func (p *Profiles) persist(event muteEvent) error {
+	if err := p.store.Append(mutesCollection, event); err != nil {
+		return fmt.Errorf("persisting mute: %w", err)
+	}
+	return nil
+}
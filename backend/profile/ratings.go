@@ -0,0 +1,41 @@
+package profile
+
+import "math"
+
+// InitialRating is the rating of a player who hasn't finished a multiplayer round
+const InitialRating = 1000
+
+// ratingK is how far a single round moves a rating
+const ratingK = 32
+
+// Rating returns the player's Elo rating from their finished multiplayer rounds
+func (p *Profiles) Rating(playerID string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return int(math.Round(p.ratingLocked(playerID)))
+}
+
+// ratingLocked returns the player's unrounded rating; caller must hold mu
+func (p *Profiles) ratingLocked(playerID string) float64 {
+	if rating, exists := p.ratings[playerID]; exists {
+		return rating
+	}
+	return InitialRating
+}
+
+// applyRatingLocked moves the player's rating by a recorded round; caller must hold mu or
+// own p exclusively
+// Each side of a round is recorded as its own event, so the second side is rated against
+// the first side's updated rating; the difference is a fraction of a point.
+func (p *Profiles) applyRatingLocked(event opponentEvent) {
+	rating, opponent := p.ratingLocked(event.PlayerID), p.ratingLocked(event.OpponentID)
+	expected := 1 / (1 + math.Pow(10, (opponent-rating)/400))
+	score := 0.5
+	switch event.Result {
+	case ResultWin:
+		score = 1
+	case ResultLoss:
+		score = 0
+	}
+	p.ratings[event.PlayerID] = rating + ratingK*(score-expected)
+}
@@ -0,0 +1,84 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// savedGamesCollection is the storage collection holding suspended single player games
+const savedGamesCollection = "saved_games"
+
+// SavedGame is a player's suspended single player game
+// The game server serializes and restores State; profiles only keep it.
+type SavedGame struct {
+	SavedAt time.Time       `json:"saved_at"`
+	State   json.RawMessage `json:"state"`
+}
+
+// savedGameEvent is one persisted save, or the removal of one when State is empty
+type savedGameEvent struct {
+	Time     time.Time       `json:"time"`
+	PlayerID string          `json:"player_id"`
+	State    json.RawMessage `json:"state,omitempty"`
+}
+
+// loadSavedGames replays the saved and resumed games; called from Open
+func (p *Profiles) loadSavedGames() error {
+	return p.store.Load(savedGamesCollection, func(raw json.RawMessage) error {
+		var event savedGameEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding saved game: %w", err)
+		}
+		p.applySavedGameLocked(event)
+		return nil
+	})
+}
+
+// SaveGame keeps a player's suspended game, replacing the one they saved before
+func (p *Profiles) SaveGame(playerID string, state json.RawMessage, now time.Time) error {
+	event := savedGameEvent{Time: now.UTC(), PlayerID: playerID, State: state}
+	p.mu.Lock()
+	p.applySavedGameLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(savedGamesCollection, event); err != nil {
+		return fmt.Errorf("persisting saved game: %w", err)
+	}
+	return nil
+}
+
+// SavedGame returns a player's suspended game
+func (p *Profiles) SavedGame(playerID string) (SavedGame, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	saved, exists := p.saved[playerID]
+	return saved, exists
+}
+
+// TakeSavedGame removes a player's suspended game and returns it, so it is resumed once
+func (p *Profiles) TakeSavedGame(playerID string, now time.Time) (SavedGame, bool, error) {
+	event := savedGameEvent{Time: now.UTC(), PlayerID: playerID}
+	p.mu.Lock()
+	saved, exists := p.saved[playerID]
+	if !exists {
+		p.mu.Unlock()
+		return SavedGame{}, false, nil
+	}
+	p.applySavedGameLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(savedGamesCollection, event); err != nil {
+		return saved, true, fmt.Errorf("persisting resumed game: %w", err)
+	}
+	return saved, true, nil
+}
+
+// applySavedGameLocked applies a save or removal; caller must hold p.mu or own p exclusively
+func (p *Profiles) applySavedGameLocked(event savedGameEvent) {
+	if len(event.State) == 0 {
+		delete(p.saved, event.PlayerID)
+		return
+	}
+	p.saved[event.PlayerID] = SavedGame{SavedAt: event.Time, State: event.State}
+}
@@ -0,0 +1,121 @@
+package profile
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// scoresCollection is the storage collection holding single player personal bests
+const scoresCollection = "single_player_scores"
+
+// LeaderboardSize is how many players a leaderboard ranks
+const LeaderboardSize = 20
+
+// HighScore is a player's best single player score in one leaderboard
+type HighScore struct {
+	Rank     int       `json:"rank"`
+	PlayerID string    `json:"player_id"`
+	Username string    `json:"username"` // As when the score was set
+	Score    int       `json:"score"`
+	SetAt    time.Time `json:"set_at"`
+}
+
+// scoreEvent is one persisted personal best
+type scoreEvent struct {
+	Time       time.Time `json:"time"`
+	PlayerID   string    `json:"player_id"`
+	Username   string    `json:"username"`
+	Variant    string    `json:"variant"`
+	Difficulty string    `json:"difficulty"`
+	Score      int       `json:"score"`
+}
+
+// board names the leaderboard of a variant played at a difficulty
+func board(variant, difficulty string) string {
+	return variant + "/" + difficulty
+}
+
+// loadScores replays the recorded personal bests; called from Open
+func (p *Profiles) loadScores() error {
+	return p.store.Load(scoresCollection, func(raw json.RawMessage) error {
+		var event scoreEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding high score: %w", err)
+		}
+		p.applyScoreLocked(event)
+		return nil
+	})
+}
+
+// RecordScore records a finished single player game's score on the leaderboard of its
+// variant and difficulty; only a player's best score there is kept
+// Returns whether the score is a new personal best.
+func (p *Profiles) RecordScore(playerID, username, variant, difficulty string, score int, now time.Time) (bool, error) {
+	event := scoreEvent{
+		Time:       now.UTC(),
+		PlayerID:   playerID,
+		Username:   username,
+		Variant:    variant,
+		Difficulty: difficulty,
+		Score:      score,
+	}
+	p.mu.Lock()
+	if best, exists := p.scores[board(variant, difficulty)][playerID]; score <= 0 || exists && score <= best.Score {
+		p.mu.Unlock()
+		return false, nil
+	}
+	p.applyScoreLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(scoresCollection, event); err != nil {
+		return true, fmt.Errorf("persisting high score: %w", err)
+	}
+	return true, nil
+}
+
+// Leaderboard ranks the best scores of a variant played at a difficulty, highest first and
+// the earliest set first among equal scores
+func (p *Profiles) Leaderboard(variant, difficulty string) []HighScore {
+	p.mu.RLock()
+	scores := make([]HighScore, 0, len(p.scores[board(variant, difficulty)]))
+	for _, best := range p.scores[board(variant, difficulty)] {
+		scores = append(scores, *best)
+	}
+	p.mu.RUnlock()
+	return rank(scores)
+}
+
+// rank orders best scores highest first and the earliest set first among equal scores,
+// keeping the top LeaderboardSize
+func rank(scores []HighScore) []HighScore {
+	slices.SortFunc(scores, func(a, b HighScore) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), a.SetAt.Compare(b.SetAt), cmp.Compare(a.PlayerID, b.PlayerID))
+	})
+	scores = scores[:min(len(scores), LeaderboardSize)]
+	for i := range scores {
+		scores[i].Rank = i + 1
+	}
+	return scores
+}
+
+// applyScoreLocked applies a personal best; caller must hold p.mu or own p exclusively
+func (p *Profiles) applyScoreLocked(event scoreEvent) {
+	key := board(event.Variant, event.Difficulty)
+	bests, exists := p.scores[key]
+	if !exists {
+		bests = make(map[string]*HighScore)
+		p.scores[key] = bests
+	}
+	if best, exists := bests[event.PlayerID]; exists && best.Score >= event.Score {
+		return
+	}
+	bests[event.PlayerID] = &HighScore{
+		PlayerID: event.PlayerID,
+		Username: event.Username,
+		Score:    event.Score,
+		SetAt:    event.Time,
+	}
+}
@@ -0,0 +1,98 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// singlePlayerCollection is the storage collection holding finished single player games
+const singlePlayerCollection = "single_player_games"
+
+// SinglePlayerStats sums up a player's finished single player games, kept apart from their
+// multiplayer rounds
+type SinglePlayerStats struct {
+	GamesPlayed       int     `json:"games_played"`
+	BestScore         int     `json:"best_score"`
+	TotalFood         int     `json:"total_food"`
+	LongestSurvivalMs int64   `json:"longest_survival_ms"`
+	AverageLength     float64 `json:"average_length"` // Mean snake length at the end of a game
+
+	totalLength int
+}
+
+// SinglePlayerGame is how a finished single player game went
+type SinglePlayerGame struct {
+	Score      int
+	Food       int
+	SurvivalMs int64 // Time played, not counting pauses
+	Length     int   // Snake length at the end of the game
+}
+
+// singlePlayerEvent is one persisted finished single player game
+type singlePlayerEvent struct {
+	Time       time.Time `json:"time"`
+	PlayerID   string    `json:"player_id"`
+	Score      int       `json:"score"`
+	Food       int       `json:"food"`
+	SurvivalMs int64     `json:"survival_ms"`
+	Length     int       `json:"length"`
+}
+
+// loadSinglePlayerStats replays the finished single player games; called from Open
+func (p *Profiles) loadSinglePlayerStats() error {
+	return p.store.Load(singlePlayerCollection, func(raw json.RawMessage) error {
+		var event singlePlayerEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding single player game: %w", err)
+		}
+		p.applySinglePlayerLocked(event)
+		return nil
+	})
+}
+
+// RecordSinglePlayerGame adds a finished single player game to the player's stats
+func (p *Profiles) RecordSinglePlayerGame(playerID string, game SinglePlayerGame, now time.Time) error {
+	event := singlePlayerEvent{
+		Time:       now.UTC(),
+		PlayerID:   playerID,
+		Score:      game.Score,
+		Food:       game.Food,
+		SurvivalMs: game.SurvivalMs,
+		Length:     game.Length,
+	}
+	p.mu.Lock()
+	p.applySinglePlayerLocked(event)
+	p.mu.Unlock()
+
+	if err := p.store.Append(singlePlayerCollection, event); err != nil {
+		return fmt.Errorf("persisting single player game: %w", err)
+	}
+	return nil
+}
+
+// SinglePlayerStats returns the player's single player stats, zero before their first game
+func (p *Profiles) SinglePlayerStats(playerID string) SinglePlayerStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if stats, exists := p.singlePlayer[playerID]; exists {
+		return *stats
+	}
+	return SinglePlayerStats{}
+}
+
+// applySinglePlayerLocked adds a finished game to the player's stats; caller must hold mu
+// or own p exclusively
+func (p *Profiles) applySinglePlayerLocked(event singlePlayerEvent) {
+	stats, exists := p.singlePlayer[event.PlayerID]
+	if !exists {
+		stats = &SinglePlayerStats{}
+		p.singlePlayer[event.PlayerID] = stats
+	}
+	stats.GamesPlayed++
+	stats.BestScore = max(stats.BestScore, event.Score)
+	stats.TotalFood += event.Food
+	stats.LongestSurvivalMs = max(stats.LongestSurvivalMs, event.SurvivalMs)
+	stats.totalLength += event.Length
+	stats.AverageLength = float64(stats.totalLength) / float64(stats.GamesPlayed)
+}
@@ -0,0 +1,253 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// titlesCollection is the storage collection holding earned and equipped titles
+const titlesCollection = "titles"
+
+// Where titles are earned
+const (
+	SourceAchievement = "achievement"
+	SourceSeason      = "season"
+	SourceTournament  = "tournament"
+)
+
+// ErrUnknownTitle is returned for a title ID that isn't in Titles
+var ErrUnknownTitle = errors.New("unknown title")
+
+// ErrTitleNotEarned is returned by Equip for a title the player hasn't earned
+var ErrTitleNotEarned = errors.New("title not earned")
+
+// Title is a badge a player can earn and show next to their username
+type Title struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Source      string `json:"source"`
+}
+
+// Titles lists every title that can be earned
+// Achievement titles and the circuit titles of the season source are awarded by
+// AwardAchievements as rounds and placements are recorded; the champion titles are awarded
+// with Award by the feature that runs them.
+var Titles = []Title{
+	{ID: "rookie", Name: "Rookie", Description: "Finish a multiplayer round", Source: SourceAchievement},
+	{ID: "victor", Name: "Victor", Description: "Win a multiplayer round", Source: SourceAchievement},
+	{ID: "veteran", Name: "Veteran", Description: "Finish 25 multiplayer rounds", Source: SourceAchievement},
+	{ID: "conqueror", Name: "Conqueror", Description: "Win 10 multiplayer rounds", Source: SourceAchievement},
+	{ID: "rival", Name: "Rival", Description: "Play 5 rounds against the same opponent", Source: SourceAchievement},
+	{ID: "contender", Name: "Contender", Description: "Earn 100 circuit points in a season", Source: SourceSeason},
+	{ID: "circuit_star", Name: "Circuit Star", Description: "Earn 500 circuit points in a season", Source: SourceSeason},
+	{ID: "season_champion", Name: "Season Champion", Description: "Finish a season at the top of the tournament circuit", Source: SourceSeason},
+	{ID: "tournament_champion", Name: "Champion", Description: "Win a tournament", Source: SourceTournament},
+}
+
+// LookupTitle returns the title with the given ID
+func LookupTitle(id string) (Title, bool) {
+	index := slices.IndexFunc(Titles, func(t Title) bool { return t.ID == id })
+	if index < 0 {
+		return Title{}, false
+	}
+	return Titles[index], true
+}
+
+// EarnedTitle is a title a player earned
+type EarnedTitle struct {
+	Title
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+// Title event kinds
+const (
+	titleEarned   = "earned"
+	titleEquipped = "equipped"
+)
+
+// titleEvent is one persisted award or equip
+type titleEvent struct {
+	Time     time.Time `json:"time"`
+	PlayerID string    `json:"player_id"`
+	Kind     string    `json:"kind"`
+	TitleID  string    `json:"title_id"` // Empty when an equipped title is taken off
+}
+
+// roundStats counts a player's finished multiplayer rounds and their best circuit season
+type roundStats struct {
+	rounds     int
+	wins       int
+	streak     int // Rounds won in a row, up to the latest
+	bestSeason int // Most circuit points earned within one season
+}
+
+// loadTitles replays the earned and equipped titles; called from Open
+func (p *Profiles) loadTitles() error {
+	return p.store.Load(titlesCollection, func(raw json.RawMessage) error {
+		var event titleEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return fmt.Errorf("decoding title: %w", err)
+		}
+		p.applyTitleLocked(event)
+		return nil
+	})
+}
+
+// Award gives the player a title
+// Returns false if the player had already earned it.
+func (p *Profiles) Award(playerID, titleID string) (bool, error) {
+	if _, known := LookupTitle(titleID); !known {
+		return false, ErrUnknownTitle
+	}
+	p.mu.Lock()
+	if _, earned := p.earned[playerID][titleID]; earned {
+		p.mu.Unlock()
+		return false, nil
+	}
+	event := titleEvent{Time: time.Now().UTC(), PlayerID: playerID, Kind: titleEarned, TitleID: titleID}
+	p.applyTitleLocked(event)
+	p.mu.Unlock()
+	return true, p.persistTitle(event)
+}
+
+// AwardAchievements gives the player the achievement and circuit titles their rounds and
+// tournament placings earned them
+// Returns the titles newly earned, in catalog order.
+func (p *Profiles) AwardAchievements(playerID string) ([]Title, error) {
+	p.mu.RLock()
+	stats := p.stats[playerID]
+	rivalry := slices.ContainsFunc(p.opponents[playerID], func(o Opponent) bool { return o.Rounds >= 5 })
+	p.mu.RUnlock()
+
+	reached := map[string]bool{
+		"rookie":    stats.rounds >= 1,
+		"victor":    stats.wins >= 1,
+		"veteran":   stats.rounds >= 25,
+		"conqueror": stats.wins >= 10,
+		"rival":     rivalry,
+
+		"contender":    stats.bestSeason >= ContenderPoints,
+		"circuit_star": stats.bestSeason >= CircuitStarPoints,
+	}
+	var awarded []Title
+	var errs []error
+	for _, title := range Titles {
+		if !reached[title.ID] {
+			continue
+		}
+		earned, err := p.Award(playerID, title.ID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if earned {
+			awarded = append(awarded, title)
+		}
+	}
+	return awarded, errors.Join(errs...)
+}
+
+// Equip shows an earned title next to the player's username; an empty ID takes it off
+func (p *Profiles) Equip(playerID, titleID string) error {
+	p.mu.Lock()
+	if titleID != "" {
+		if _, known := LookupTitle(titleID); !known {
+			p.mu.Unlock()
+			return ErrUnknownTitle
+		}
+		if _, earned := p.earned[playerID][titleID]; !earned {
+			p.mu.Unlock()
+			return ErrTitleNotEarned
+		}
+	}
+	if p.equipped[playerID] == titleID {
+		p.mu.Unlock()
+		return nil
+	}
+	event := titleEvent{Time: time.Now().UTC(), PlayerID: playerID, Kind: titleEquipped, TitleID: titleID}
+	p.applyTitleLocked(event)
+	p.mu.Unlock()
+	return p.persistTitle(event)
+}
+
+// EquippedTitle returns the title the player shows next to their username
+func (p *Profiles) EquippedTitle(playerID string) (Title, bool) {
+	p.mu.RLock()
+	titleID := p.equipped[playerID]
+	p.mu.RUnlock()
+	if titleID == "" {
+		return Title{}, false
+	}
+	return LookupTitle(titleID)
+}
+
+// EarnedTitles returns the titles the player earned, in catalog order
+func (p *Profiles) EarnedTitles(playerID string) []EarnedTitle {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	titles := []EarnedTitle{}
+	for _, title := range Titles {
+		if earnedAt, earned := p.earned[playerID][title.ID]; earned {
+			titles = append(titles, EarnedTitle{Title: title, EarnedAt: earnedAt})
+		}
+	}
+	return titles
+}
+
+// applyTitleLocked applies a title event; caller must hold mu or own p exclusively
+func (p *Profiles) applyTitleLocked(event titleEvent) {
+	switch event.Kind {
+	case titleEarned:
+		earned, exists := p.earned[event.PlayerID]
+		if !exists {
+			earned = make(map[string]time.Time)
+			p.earned[event.PlayerID] = earned
+		}
+		earned[event.TitleID] = event.Time
+	case titleEquipped:
+		if event.TitleID == "" {
+			delete(p.equipped, event.PlayerID)
+			return
+		}
+		p.equipped[event.PlayerID] = event.TitleID
+	}
+}
+
+// RoundCounts returns how many multiplayer rounds the player finished and won
+func (p *Profiles) RoundCounts(playerID string) (rounds, wins int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	stats := p.stats[playerID]
+	return stats.rounds, stats.wins
+}
+
+// WinStreak returns how many multiplayer rounds the player won in a row up to their latest
+func (p *Profiles) WinStreak(playerID string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stats[playerID].streak
+}
+
+// applyStatsLocked counts a recorded round; caller must hold mu or own p exclusively
+func (p *Profiles) applyStatsLocked(event opponentEvent) {
+	stats := p.stats[event.PlayerID]
+	stats.rounds++
+	if event.Result == ResultWin {
+		stats.wins++
+		stats.streak++
+	} else {
+		stats.streak = 0
+	}
+	p.stats[event.PlayerID] = stats
+}
+
+// persistTitle appends a title event; the change stays applied in memory even if it fails
+func (p *Profiles) persistTitle(event titleEvent) error {
+	if err := p.store.Append(titlesCollection, event); err != nil {
+		return fmt.Errorf("persisting title: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// collection is the storage collection holding recorded rounds
+const collection = "replays"
+
+// maxKept is how many of the latest recorded rounds are kept in memory to be verified
+const maxKept = 5000
+
+// Archive keeps the latest recorded rounds in memory and persists every one to storage
+type Archive struct {
+	store storage.Store
+
+	mu      sync.RWMutex
+	records []Record // Oldest first
+}
+
+// Open loads the latest recorded rounds from the store
+func Open(store storage.Store) (*Archive, error) {
+	a := &Archive{store: store}
+	err := store.Load(collection, func(raw json.RawMessage) error {
+		var record Record
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return fmt.Errorf("decoding replay: %w", err)
+		}
+		a.keep(record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Save archives a recorded round
+// The round is kept for verification even if persisting it fails
+func (a *Archive) Save(record Record) error {
+	a.mu.Lock()
+	a.keep(record)
+	a.mu.Unlock()
+
+	if err := a.store.Append(collection, record); err != nil {
+		return fmt.Errorf("persisting replay: %w", err)
+	}
+	return nil
+}
+
+// Find returns the recorded round of a game, its latest when round is 0
+func (a *Archive) Find(gameID string, round int) (Record, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for i := len(a.records) - 1; i >= 0; i-- {
+		record := a.records[i]
+		if record.GameID == gameID && (round == 0 || record.Round == round) {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
+
+// keep adds a record, dropping the oldest beyond maxKept; caller must hold mu or own a
+func (a *Archive) keep(record Record) {
+	if len(a.records) >= maxKept {
+		a.records = a.records[1:]
+	}
+	a.records = append(a.records, record)
+}
@@ -0,0 +1,166 @@
+// Package replay records multiplayer rounds and verifies them by playing them again
+//
+// A round is reproduced by its seed and the turns applied on each tick: the server's Rand
+// is the only source of chance, so running the engine from the same seed with the same
+// inputs must end on the board the server reported. A round that doesn't points to a
+// tampered record or a server that desynced from its own engine.
+package replay
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bariiss/snake/backend/constants"
+	"github.com/bariiss/snake/backend/engine"
+	"github.com/bariiss/snake/backend/rng"
+)
+
+// Input is a turn applied to a snake on a tick
+type Input struct {
+	Tick      int                 `json:"tick"`
+	Snake     string              `json:"snake"`
+	Direction constants.Direction `json:"direction"`
+}
+
+// Record is what it takes to play a round again, and how the server reported it ended
+type Record struct {
+	GameID      string              `json:"game_id"`
+	Round       int                 `json:"round"`
+	Tournament  string              `json:"tournament,omitempty"`
+	Seed        int64               `json:"seed"`
+	RandFrom    uint64              `json:"rand_from"` // Draws taken from the seeded Rand before the round was laid out
+	Rules       engine.RulesProfile `json:"rules"`
+	SpawnLayout string              `json:"spawn_layout"`
+	StartLength int                 `json:"start_length"`
+	Snakes      []string            `json:"snakes"`  // Snake IDs in the order they were laid out
+	Opening     *engine.GameState   `json:"opening"` // Board the round started on
+	Inputs      []Input             `json:"inputs"`  // Turns applied, by tick
+
+	Ticks   int               `json:"ticks"`
+	Decided bool              `json:"decided"`          // The board decided the round, rather than a player leaving or a moderator
+	Winner  string            `json:"winner,omitempty"` // Snake ID or "tie" when Decided
+	Final   *engine.GameState `json:"final"`            // Board the round ended on
+	EndedAt time.Time         `json:"ended_at"`
+}
+
+// Verdict is the outcome of playing a round again
+type Verdict struct {
+	GameID      string         `json:"game_id"`
+	Round       int            `json:"round"`
+	Verified    bool           `json:"verified"` // The replay ended on the reported board, with the reported result
+	Ticks       int            `json:"ticks"`
+	Winner      string         `json:"winner,omitempty"`      // Winner of the replay, empty when it was still going on
+	Scores      map[string]int `json:"scores"`                // Snake ID -> score at the end of the replay
+	Divergences []string       `json:"divergences,omitempty"` // How the replay differs from the report
+}
+
+// Verify plays a recorded round again through the engine and compares it to the report
+func Verify(record Record) Verdict {
+	verdict := Verdict{GameID: record.GameID, Round: record.Round, Ticks: record.Ticks}
+	if record.Opening == nil || record.Final == nil {
+		verdict.diverge("the record has no opening or final board")
+		return verdict
+	}
+
+	rnd := rng.New(record.Seed)
+	rewinder, rewinds := rnd.(rng.Rewinder)
+	if !rewinds {
+		verdict.diverge("the random source can't be positioned")
+		return verdict
+	}
+	rewinder.Rewind(record.RandFrom)
+	board := record.Opening.Clone()
+	board.Snakes = engine.StartSnakes(rnd, record.SpawnLayout, record.StartLength, record.Snakes...)
+	board.Foods, board.Food, board.TeamScore = nil, engine.Food{}, 0
+	engine.OpeningFood(board, record.Rules, record.SpawnLayout, rnd)
+	verdict.compareBoards("opening", board, record.Opening)
+	opening := board.Clone().Snakes
+
+	next := 0
+	for tick := 1; tick <= record.Ticks; tick++ {
+		inputs := make(engine.Inputs, len(board.Snakes))
+		for ; next < len(record.Inputs) && record.Inputs[next].Tick == tick; next++ {
+			inputs[record.Inputs[next].Snake] = record.Inputs[next].Direction
+		}
+		result := engine.Step(engine.State{Board: board, Rules: record.Rules, Rand: rnd, Opening: opening}, inputs)
+		if result.Over() {
+			verdict.Winner = result.Winner
+			if tick < record.Ticks {
+				verdict.diverge(fmt.Sprintf("the round ended on tick %d, not %d", tick, record.Ticks))
+				verdict.Ticks = tick
+			}
+			break
+		}
+	}
+	if next < len(record.Inputs) {
+		verdict.diverge(fmt.Sprintf("%d turns were recorded after the round ended", len(record.Inputs)-next))
+	}
+
+	switch {
+	case record.Decided && verdict.Winner == "":
+		verdict.diverge(fmt.Sprintf("the round was reported won by %s but was still going on", record.Winner))
+	case record.Decided && verdict.Winner != record.Winner:
+		verdict.diverge(fmt.Sprintf("the round was reported won by %s but %s won the replay", record.Winner, verdict.Winner))
+	}
+	verdict.Scores = make(map[string]int, len(board.Snakes))
+	for _, snake := range board.Snakes {
+		verdict.Scores[snake.ID] = snake.Score
+	}
+	verdict.compareBoards("final", board, record.Final)
+	verdict.Verified = len(verdict.Divergences) == 0
+	return verdict
+}
+
+// CompareResult checks a result reported elsewhere, such as a tournament match, against
+// the replay: the winner, empty for a draw, and the score of each player
+func (v *Verdict) CompareResult(winner string, scores map[string]int) {
+	switch replayed := v.Winner; {
+	case replayed == "":
+		// The board didn't decide the round; a player left or a moderator decided it
+	case replayed == "tie" && winner != "":
+		v.diverge(fmt.Sprintf("the result names %s the winner of a tied round", winner))
+	case replayed != "tie" && winner != replayed:
+		v.diverge(fmt.Sprintf("the result names %s the winner, the replay %s", orNobody(winner), replayed))
+	}
+	for _, snakeID := range slices.Sorted(maps.Keys(scores)) {
+		if score, replayed := scores[snakeID], v.Scores[snakeID]; score != replayed {
+			v.diverge(fmt.Sprintf("the result gives %s a score of %d, the replay %d", snakeID, score, replayed))
+		}
+	}
+	v.Verified = len(v.Divergences) == 0
+}
+
+// compareBoards records how the snakes and food of a replayed board differ from a reported one
+func (v *Verdict) compareBoards(name string, replayed, reported *engine.GameState) {
+	if len(replayed.Snakes) != len(reported.Snakes) {
+		v.diverge(fmt.Sprintf("the %s board has %d snakes, the replay %d", name, len(reported.Snakes), len(replayed.Snakes)))
+		return
+	}
+	for i, snake := range replayed.Snakes {
+		played := reported.Snakes[i]
+		switch {
+		case snake.ID != played.ID:
+			v.diverge(fmt.Sprintf("the %s board has snake %s where the replay has %s", name, played.ID, snake.ID))
+		case snake.Score != played.Score:
+			v.diverge(fmt.Sprintf("%s scored %d on the %s board, %d in the replay", snake.ID, played.Score, name, snake.Score))
+		case !slices.Equal(snake.Body, played.Body):
+			v.diverge(fmt.Sprintf("%s is placed differently on the %s board than in the replay", snake.ID, name))
+		}
+	}
+	if !slices.Equal(replayed.Foods, reported.Foods) {
+		v.diverge(fmt.Sprintf("the food on the %s board differs from the replay", name))
+	}
+}
+
+func (v *Verdict) diverge(divergence string) {
+	v.Divergences = append(v.Divergences, divergence)
+}
+
+func orNobody(winner string) string {
+	if winner == "" {
+		return "nobody"
+	}
+	return winner
+}
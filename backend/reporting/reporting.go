@@ -0,0 +1,132 @@
+// Package reporting forwards unexpected server errors and recovered panics to an error
+// tracker so production failures are aggregated instead of lost in the logs
+//
+// Call sites report through the package functions; without a configured reporter they
+// only count the report. Reporting never blocks the caller.
+package reporting
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/bariiss/snake/backend/metrics"
+)
+
+// Levels
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// maxStackDepth bounds the number of frames captured for a report
+const maxStackDepth = 64
+
+var reportsTotal = metrics.NewCounterVec(
+	"snake_error_reports_total",
+	"Errors and panics reported to the error tracker, by kind",
+	"kind",
+)
+
+// Event is one reported failure
+type Event struct {
+	Message string            // What the server was doing, e.g. "Failed to encode message"
+	Err     error             // The error, nil for panics
+	Panic   any               // The recovered value, nil for errors
+	Level   string            // LevelError or LevelFatal
+	Tags    map[string]string // Searchable context such as game_id or msg_type
+	Stack   []runtime.Frame   // Innermost frame first
+	Time    time.Time
+}
+
+// Reporter delivers events to an error tracker
+type Reporter interface {
+	// Report queues an event; it must not block
+	Report(event Event)
+	// Flush waits up to timeout for queued events to be delivered
+	Flush(timeout time.Duration) bool
+}
+
+// reporterBox lets atomic.Value hold any Reporter implementation
+type reporterBox struct{ Reporter }
+
+var current atomic.Value // reporterBox
+
+// SetReporter installs the reporter used by Error and Panic; nil disables reporting
+func SetReporter(r Reporter) {
+	current.Store(reporterBox{r})
+}
+
+// Flush waits for the installed reporter to deliver queued events
+func Flush(timeout time.Duration) bool {
+	if r := installed(); r != nil {
+		return r.Flush(timeout)
+	}
+	return true
+}
+
+// Error reports an unexpected error; tags are key/value pairs like slog attributes
+func Error(msg string, err error, tags ...string) {
+	report(Event{Message: msg, Err: err, Level: LevelError}, tags)
+}
+
+// Panic reports a value recovered from a panic; call it from the deferred function that
+// recovered so the stack still shows where the panic happened
+func Panic(msg string, recovered any, tags ...string) {
+	report(Event{Message: msg, Panic: recovered, Level: LevelFatal}, tags)
+}
+
+func report(event Event, tags []string) {
+	kind := "error"
+	if event.Panic != nil {
+		kind = "panic"
+	}
+	reportsTotal.Inc(kind)
+
+	r := installed()
+	if r == nil {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	event.Tags = make(map[string]string, len(tags)/2)
+	for i := 0; i+1 < len(tags); i += 2 {
+		event.Tags[tags[i]] = tags[i+1]
+	}
+	event.Stack = callers(3)
+	r.Report(event)
+}
+
+func installed() Reporter {
+	box, _ := current.Load().(reporterBox)
+	return box.Reporter
+}
+
+// callers captures the stack above the reporting functions
+func callers(skip int) []runtime.Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// Title returns a one-line summary of the event
+func (e Event) Title() string {
+	switch {
+	case e.Panic != nil:
+		return fmt.Sprintf("panic: %v", e.Panic)
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return e.Message
+}
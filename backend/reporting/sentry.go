@@ -0,0 +1,206 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sentryQueueSize is the number of events waiting for delivery before new ones are dropped
+	sentryQueueSize = 100
+	// sentryTimeout bounds each delivery request
+	sentryTimeout = 5 * time.Second
+	// sentryClient identifies this implementation to the tracker
+	sentryClient = "github.com/bariiss/snake/backend/1.0"
+)
+
+// Sentry is a Reporter sending events to Sentry or a compatible tracker (GlitchTip,
+// self-hosted Sentry) through the envelope endpoint of a DSN
+type Sentry struct {
+	endpoint    string
+	auth        string
+	dsn         string
+	serverName  string
+	environment string
+	client      *http.Client
+
+	events  chan Event
+	pending sync.WaitGroup
+}
+
+// NewSentry creates a reporter for a DSN of the form https://<key>@<host>/<project>
+// serverName and environment are attached to every event
+func NewSentry(dsn, serverName, environment string) (*Sentry, error) {
+	endpoint, key, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sentry{
+		endpoint:    endpoint,
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", key, sentryClient),
+		dsn:         dsn,
+		serverName:  serverName,
+		environment: environment,
+		client:      &http.Client{Timeout: sentryTimeout},
+		events:      make(chan Event, sentryQueueSize),
+	}
+	go s.run()
+	return s, nil
+}
+
+// ParseDSN returns the envelope endpoint and public key of a Sentry DSN
+func ParseDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing DSN: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("DSN scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("DSN is missing the public key")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectID := path[slash+1:]
+	if projectID == "" {
+		return "", "", fmt.Errorf("DSN is missing the project ID")
+	}
+	// Trackers hosted under a path prefix keep it in front of /api/
+	endpoint = fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path[:slash], projectID)
+	return endpoint, u.User.Username(), nil
+}
+
+// Report queues an event, dropping it if the queue is full
+func (s *Sentry) Report(event Event) {
+	s.pending.Add(1)
+	select {
+	case s.events <- event:
+	default:
+		s.pending.Done()
+		slog.Warn("Error report queue full, dropping report", "message", event.Message)
+	}
+}
+
+// Flush waits up to timeout for queued events to be sent
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (s *Sentry) run() {
+	for event := range s.events {
+		if err := s.send(event); err != nil {
+			// Logged only: reporting a failed report could loop
+			slog.Warn("Failed to send error report", "message", event.Message, "error", err)
+		}
+		s.pending.Done()
+	}
+}
+
+// send posts one event as an envelope
+func (s *Sentry) send(event Event) error {
+	eventID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	payload, err := json.Marshal(s.buildEvent(eventID, event))
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	header, _ := json.Marshal(map[string]string{
+		"event_id": eventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+		"dsn":      s.dsn,
+	})
+	itemHeader, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(itemHeader)
+	body.WriteByte('\n')
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("tracker responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// buildEvent converts an event to the Sentry event payload
+func (s *Sentry) buildEvent(eventID string, event Event) map[string]any {
+	// Sentry lists frames oldest first
+	frames := make([]map[string]any, 0, len(event.Stack))
+	for i := len(event.Stack) - 1; i >= 0; i-- {
+		frame := event.Stack[i]
+		frames = append(frames, map[string]any{
+			"function": frame.Function,
+			"abs_path": frame.File,
+			"filename": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(frame.Function, "github.com/bariiss/snake/backend/") || strings.HasPrefix(frame.Function, "main."),
+		})
+	}
+
+	exceptionType := "error"
+	switch {
+	case event.Panic != nil:
+		exceptionType = "panic"
+	case event.Err != nil:
+		exceptionType = reflect.TypeOf(event.Err).String()
+	}
+
+	return map[string]any{
+		"event_id":    eventID,
+		"timestamp":   event.Time.Format(time.RFC3339Nano),
+		"level":       event.Level,
+		"platform":    "go",
+		"logger":      "snake-backend",
+		"server_name": s.serverName,
+		"environment": s.environment,
+		"message":     map[string]string{"formatted": event.Message},
+		"tags":        event.Tags,
+		"exception": map[string]any{
+			"values": []map[string]any{{
+				"type":       exceptionType,
+				"value":      event.Title(),
+				"stacktrace": map[string]any{"frames": frames},
+			}},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+// Package rng provides the seedable random source each game draws food and spawns from, so
+// a game can be reproduced from its seed and inputs
+package rng
+
+import "math/rand"
+
+// Rand is a source of random numbers; implementations need not be safe for concurrent use
+type Rand interface {
+	// Intn returns a number in [0, n); n must be positive
+	Intn(n int) int
+}
+
+// Rewinder is a Rand that can go back to an earlier place in its sequence, so a game can
+// take back a step and draw the same numbers again
+type Rewinder interface {
+	Rand
+	// Position returns how many numbers have been drawn since seeding
+	Position() uint64
+	// Rewind moves to position, so the next number drawn is the one drawn there before
+	Rewind(position uint64)
+}
+
+// New returns a deterministic source seeded with seed
+// It is a Rewinder, and draws the same sequence as a math/rand source with the same seed.
+func New(seed int64) Rand {
+	counted := &counter{seed: seed, source: rand.NewSource(seed)}
+	return &source{Rand: rand.New(counted), counted: counted}
+}
+
+// Seed returns a random seed for a new game
+func Seed() int64 {
+	return rand.Int63()
+}
+
+// source is the Rewinder New returns
+type source struct {
+	*rand.Rand
+	counted *counter
+}
+
+func (s *source) Position() uint64 {
+	return s.counted.drawn
+}
+
+// Rewind reseeds and draws up to position when going back, which takes time linear in it
+func (s *source) Rewind(position uint64) {
+	if position < s.counted.drawn {
+		s.counted.Seed(s.counted.seed)
+	}
+	for s.counted.drawn < position {
+		s.counted.Int63()
+	}
+}
+
+// counter is a math/rand source that counts the numbers drawn from it
+// It hides the Uint64 method of the source it wraps, so every draw goes through Int63.
+type counter struct {
+	seed   int64
+	source rand.Source
+	drawn  uint64
+}
+
+func (c *counter) Int63() int64 {
+	c.drawn++
+	return c.source.Int63()
+}
+
+func (c *counter) Seed(seed int64) {
+	c.seed, c.drawn = seed, 0
+	c.source.Seed(seed)
+}
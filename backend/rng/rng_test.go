@@ -0,0 +1,75 @@
+package rng
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// draw takes n numbers below bound from r
+func draw(r Rand, n, bound int) []int {
+	numbers := make([]int, n)
+	for i := range numbers {
+		numbers[i] = r.Intn(bound)
+	}
+	return numbers
+}
+
+func TestNewIsDeterministic(t *testing.T) {
+	for _, seed := range []int64{0, 1, 42, -7} {
+		if a, b := draw(New(seed), 100, 1200), draw(New(seed), 100, 1200); !slices.Equal(a, b) {
+			t.Errorf("seed %d drew %v and %v", seed, a[:5], b[:5])
+		}
+	}
+	if a, b := draw(New(1), 100, 1200), draw(New(2), 100, 1200); slices.Equal(a, b) {
+		t.Error("seeds 1 and 2 drew the same numbers")
+	}
+}
+
+func TestNewMatchesMathRand(t *testing.T) {
+	for _, bound := range []int{1, 7, 1200, 1 << 30} {
+		want := draw(rand.New(rand.NewSource(99)), 50, bound)
+		if got := draw(New(99), 50, bound); !slices.Equal(got, want) {
+			t.Errorf("Intn(%d) drew %v, want %v", bound, got[:5], want[:5])
+		}
+	}
+}
+
+func TestRewind(t *testing.T) {
+	tests := []struct {
+		name     string
+		drawn    int // Numbers drawn before rewinding
+		position uint64
+	}{
+		{"to the start", 10, 0},
+		{"back a few", 10, 6},
+		{"to where it is", 10, 10},
+		{"forward", 3, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := draw(New(5), 20, 1000)
+
+			r := New(5).(Rewinder)
+			draw(r, tt.drawn, 1000)
+			r.Rewind(tt.position)
+			if r.Position() != tt.position {
+				t.Fatalf("position = %d, want %d", r.Position(), tt.position)
+			}
+			if got := draw(r, 5, 1000); !slices.Equal(got, want[tt.position:tt.position+5]) {
+				t.Errorf("drew %v after rewinding, want %v", got, want[tt.position:tt.position+5])
+			}
+		})
+	}
+}
+
+func TestPositionCountsDraws(t *testing.T) {
+	r := New(3).(Rewinder)
+	if r.Position() != 0 {
+		t.Fatalf("position = %d before drawing, want 0", r.Position())
+	}
+	draw(r, 4, 10)
+	if r.Position() != 4 {
+		t.Errorf("position = %d after 4 draws below 10, want 4", r.Position())
+	}
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// collectionName restricts collection names to safe file names
+var collectionName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// maxRecordSize is the longest record line read back from disk
+const maxRecordSize = 1 << 20
+
+// Dir is a Store writing each collection to a JSON lines file in a directory
+// Every append is synced to disk before it returns.
+type Dir struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*os.File // Open append handles by collection
+}
+
+// NewDir opens (creating if needed) a storage directory
+func NewDir(dir string) (*Dir, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating storage directory: %w", err)
+	}
+	return &Dir{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+// Append writes the record as one line at the end of the collection's file
+func (d *Dir) Append(collection string, record any) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := d.fileLocked(collection)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("writing %s: %w", collection, err)
+	}
+	return file.Sync()
+}
+
+// Load replays the records of a collection; a missing file is an empty collection
+func (d *Dir) Load(collection string, fn func(raw json.RawMessage) error) error {
+	path, err := d.path(collection)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", collection, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), maxRecordSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(json.RawMessage(scanner.Bytes())); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", collection, err)
+	}
+	return nil
+}
+
+// Rewrite replaces the collection's file with one holding just the records
+// The new file is synced and renamed over the old one, so a crash leaves either in place.
+func (d *Dir) Rewrite(collection string, records []any) error {
+	path, err := d.path(collection)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	for _, record := range records {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, raw...), '\n')
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.CreateTemp(d.dir, "."+collection+"-*")
+	if err != nil {
+		return fmt.Errorf("rewriting %s: %w", collection, err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o640)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("rewriting %s: %w", collection, err)
+	}
+	// The append handle still points at the replaced file
+	if old, exists := d.files[collection]; exists {
+		delete(d.files, collection)
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes every open collection file
+func (d *Dir) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for collection, file := range d.files {
+		errs = append(errs, file.Close())
+		delete(d.files, collection)
+	}
+	return errors.Join(errs...)
+}
+
+// fileLocked returns the append handle of a collection, opening it on first use
+func (d *Dir) fileLocked(collection string) (*os.File, error) {
+	if file, exists := d.files[collection]; exists {
+		return file, nil
+	}
+
+	path, err := d.path(collection)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", collection, err)
+	}
+	d.files[collection] = file
+	return file, nil
+}
+
+// path returns the file of a collection
+func (d *Dir) path(collection string) (string, error) {
+	if !collectionName.MatchString(collection) {
+		return "", fmt.Errorf("invalid collection name %q", collection)
+	}
+	return filepath.Join(d.dir, collection+".jsonl"), nil
+}
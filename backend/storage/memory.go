@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+)
+
+// Memory is a Store that keeps records in memory only
+type Memory struct {
+	mu          sync.RWMutex
+	collections map[string][]json.RawMessage
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{collections: make(map[string][]json.RawMessage)}
+}
+
+// Append encodes the record and adds it to the collection
+func (m *Memory) Append(collection string, record any) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.collections[collection] = append(m.collections[collection], raw)
+	m.mu.Unlock()
+	return nil
+}
+
+// Load replays the records of a collection
+func (m *Memory) Load(collection string, fn func(raw json.RawMessage) error) error {
+	m.mu.RLock()
+	records := slices.Clone(m.collections[collection])
+	m.mu.RUnlock()
+
+	for _, raw := range records {
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewrite encodes the records and replaces the collection with them
+func (m *Memory) Rewrite(collection string, records []any) error {
+	raws := make([]json.RawMessage, 0, len(records))
+	for _, record := range records {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		raws = append(raws, raw)
+	}
+
+	m.mu.Lock()
+	m.collections[collection] = raws
+	m.mu.Unlock()
+	return nil
+}
+
+// Close does nothing
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,28 @@
+// Package storage persists server records such as the audit log
+//
+// Records are appended to named collections and read back in insertion order when the
+// server starts. Without a storage directory records are kept in memory and lost on restart.
+package storage
+
+import (
+	"encoding/json"
+)
+
+// Store appends records to collections and replays them
+type Store interface {
+	// Append adds a JSON-encodable record to the end of a collection
+	Append(collection string, record any) error
+	// Load calls fn with every record of a collection in insertion order
+	Load(collection string, fn func(raw json.RawMessage) error) error
+	// Rewrite replaces every record of a collection, such as to drop expired ones
+	Rewrite(collection string, records []any) error
+	Close() error
+}
+
+// Open returns a store persisting to dir, or an in-memory store when dir is empty
+func Open(dir string) (Store, error) {
+	if dir == "" {
+		return NewMemory(), nil
+	}
+	return NewDir(dir)
+}
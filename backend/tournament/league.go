@@ -0,0 +1,228 @@
+package tournament
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// League limits
+const (
+	MaxLeaguePlayers   = 16  // Every entrant plays every other, so leagues stay small
+	DefaultLeagueHours = 24  // Window of a league when the organizer sets none
+	MaxLeagueHours     = 168 // A league runs for at most a week
+)
+
+// Points a league match gives each side
+const (
+	WinPoints  = 3
+	DrawPoints = 1
+)
+
+// Standing is an entrant's record in a league or Swiss tournament
+type Standing struct {
+	Rank    int     `json:"rank"`
+	Entrant Entrant `json:"entrant"`
+	Played  int     `json:"played"`
+	Wins    int     `json:"wins"`
+	Draws   int     `json:"draws"`
+	Losses  int     `json:"losses"`
+	Points  int     `json:"points"`
+	// Points of the opponents the entrant beat plus half of those it drew with; breaks ties
+	// in points and wins in favor of results against stronger opponents
+	SonnebornBerger float64 `json:"sonneborn_berger"`
+}
+
+// Draw records a league or Swiss match that ended in a tie
+func (t *Tournament) Draw(matchID string, now time.Time) error {
+	match, exists := t.Match(matchID)
+	switch {
+	case !exists:
+		return ErrMatchNotFound
+	case match.Decided():
+		return ErrMatchDecided
+	}
+	t.settle(match, "", ResultDraw, now)
+	return nil
+}
+
+// Close ends a running league whose window is over; matches not played by then expire
+// and give no points
+func (t *Tournament) Close(now time.Time) {
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if !match.Decided() {
+				match.Result = ResultExpired
+				match.Deadline = time.Time{}
+			}
+		}
+	}
+	t.finishStandings(now)
+}
+
+// Standings ranks the entrants of a league or Swiss tournament by points, then wins, then
+// Sonneborn-Berger score, then seed. A bye counts as a win against nobody. Disqualified
+// entrants are ranked last.
+func (t *Tournament) Standings() []Standing {
+	byID := make(map[string]*Standing, len(t.Entrants))
+	standings := make([]*Standing, len(t.Entrants))
+	for i, entrant := range t.Entrants {
+		standings[i] = &Standing{Entrant: entrant}
+		byID[entrant.ID] = standings[i]
+	}
+	played := t.played()
+	for _, match := range played {
+		if match.Result == ResultBye {
+			bye := byID[match.Winner]
+			bye.Played, bye.Wins, bye.Points = bye.Played+1, bye.Wins+1, bye.Points+WinPoints
+			continue
+		}
+		one, two := byID[match.Player1.ID], byID[match.Player2.ID]
+		one.Played++
+		two.Played++
+		switch match.Winner {
+		case "":
+			one.Draws, two.Draws = one.Draws+1, two.Draws+1
+			one.Points, two.Points = one.Points+DrawPoints, two.Points+DrawPoints
+		case one.Entrant.ID:
+			one.Wins, two.Losses = one.Wins+1, two.Losses+1
+			one.Points += WinPoints
+		default:
+			two.Wins, one.Losses = two.Wins+1, one.Losses+1
+			two.Points += WinPoints
+		}
+	}
+	for _, match := range played {
+		if match.Result == ResultBye {
+			continue
+		}
+		one, two := byID[match.Player1.ID], byID[match.Player2.ID]
+		switch match.Winner {
+		case "":
+			one.SonnebornBerger += float64(two.Points) / 2
+			two.SonnebornBerger += float64(one.Points) / 2
+		case one.Entrant.ID:
+			one.SonnebornBerger += float64(two.Points)
+		default:
+			two.SonnebornBerger += float64(one.Points)
+		}
+	}
+
+	slices.SortStableFunc(standings, func(a, b *Standing) int {
+		return cmp.Or(
+			compareBool(a.Entrant.Disqualified, b.Entrant.Disqualified),
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(b.Wins, a.Wins),
+			cmp.Compare(b.SonnebornBerger, a.SonnebornBerger),
+			cmp.Compare(a.Entrant.Seed, b.Entrant.Seed),
+		)
+	})
+	ranked := make([]Standing, len(standings))
+	for i, standing := range standings {
+		standing.Rank = i + 1
+		ranked[i] = *standing
+	}
+	return ranked
+}
+
+// drawLeague pairs every entrant with every other using the circle method, so each round
+// has every entrant play at most once
+func (t *Tournament) drawLeague(now time.Time) {
+	seeds := make([]int, len(t.Entrants))
+	for i := range seeds {
+		seeds[i] = i + 1
+	}
+	if len(seeds)%2 == 1 {
+		seeds = append(seeds, 0) // The entrant paired with 0 sits the round out
+	}
+	t.Rounds = nil
+	for round := 1; round < len(seeds); round++ {
+		var matches []*Match
+		for i := range len(seeds) / 2 {
+			one, two := seeds[i], seeds[len(seeds)-1-i]
+			if one == 0 || two == 0 {
+				continue
+			}
+			matches = append(matches, &Match{
+				ID:      fmt.Sprintf("R%d-M%d", round, len(matches)+1),
+				Round:   round,
+				Index:   len(matches),
+				Player1: t.seeded(min(one, two)),
+				Player2: t.seeded(max(one, two)),
+			})
+		}
+		t.Rounds = append(t.Rounds, matches)
+		// Keep the first seed in place and rotate the others
+		seeds = append([]int{seeds[0], seeds[len(seeds)-1]}, seeds[1:len(seeds)-1]...)
+	}
+	t.EndsAt = now.Add(time.Duration(cmp.Or(t.WindowHours, DefaultLeagueHours)) * time.Hour)
+}
+
+// settle records the result of a league or Swiss match. A league finishes once every match
+// has a result; a Swiss tournament then draws its next round, or finishes after the last.
+func (t *Tournament) settle(match *Match, winnerID, result string, now time.Time) {
+	match.Winner = winnerID
+	match.Result = result
+	match.Deadline = time.Time{}
+	// A Swiss round can be decided as soon as it is drawn: a bye is all that is left once
+	// every other entrant was disqualified
+	for t.decided() {
+		if !t.Swiss() || len(t.Rounds) >= t.SwissRounds {
+			t.finishStandings(now)
+			return
+		}
+		t.drawSwiss()
+	}
+}
+
+// decided reports whether every match drawn so far has a result
+func (t *Tournament) decided() bool {
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if !match.Decided() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// finishStandings places every entrant by their standing and crowns the leader, if any
+// match was played
+func (t *Tournament) finishStandings(now time.Time) {
+	t.Status = StatusFinished
+	t.FinishedAt = now
+	if len(t.played()) == 0 {
+		return
+	}
+	finishes := make(map[string]int, len(t.Entrants))
+	for _, standing := range t.Standings() {
+		finishes[standing.Entrant.ID] = standing.Rank
+	}
+	t.place(finishes)
+}
+
+// played returns the matches that were played, forfeited or byes, leaving out expired ones
+func (t *Tournament) played() []*Match {
+	var matches []*Match
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if match.Decided() && match.Result != ResultExpired {
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,207 @@
+package tournament
+
+import (
+	"slices"
+	"time"
+)
+
+// Roles the organizer appoints players to
+const (
+	RoleModerator = "moderator" // Runs the tournament with the organizer
+	RoleCaster    = "caster"    // Follows the state of several match games at once
+)
+
+// Staff is a player the organizer appointed to a role
+type Staff struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Moderates reports whether the player runs the tournament: its organizer or a moderator
+func (t *Tournament) Moderates(playerID string) bool {
+	return playerID == t.OrganizerID || t.Appointed(RoleModerator, playerID)
+}
+
+// Casts reports whether the player may follow the tournament's match games as a caster:
+// casters, and those who run it
+func (t *Tournament) Casts(playerID string) bool {
+	return t.Moderates(playerID) || t.Appointed(RoleCaster, playerID)
+}
+
+// Appointed reports whether the player holds a role
+func (t *Tournament) Appointed(role, playerID string) bool {
+	roster, _ := t.roster(role)
+	return roster != nil && slices.ContainsFunc(*roster, func(s Staff) bool { return s.ID == playerID })
+}
+
+// Appoint gives a player a role; the organizer holds every role already
+func (t *Tournament) Appoint(role string, staff Staff) error {
+	roster, known := t.roster(role)
+	switch {
+	case !known:
+		return ErrUnknownRole
+	case staff.ID == t.OrganizerID || t.Appointed(role, staff.ID):
+		return ErrAlreadyAppointed
+	}
+	*roster = append(*roster, staff)
+	return nil
+}
+
+// Dismiss takes a role off a player
+func (t *Tournament) Dismiss(role, playerID string) error {
+	roster, known := t.roster(role)
+	switch {
+	case !known:
+		return ErrUnknownRole
+	case !t.Appointed(role, playerID):
+		return ErrNotAppointed
+	}
+	*roster = slices.DeleteFunc(*roster, func(s Staff) bool { return s.ID == playerID })
+	return nil
+}
+
+// roster returns the players holding a role, reporting whether the role is known
+func (t *Tournament) roster(role string) (*[]Staff, bool) {
+	switch role {
+	case RoleModerator:
+		return &t.Moderators, true
+	case RoleCaster:
+		return &t.Casters, true
+	}
+	return nil, false
+}
+
+// Pause holds a running tournament: games already playing finish and count, but no new
+// match game is created and nobody forfeits until it resumes
+func (t *Tournament) Pause(now time.Time) error {
+	switch {
+	case t.Status != StatusRunning:
+		return ErrNotRunning
+	case t.Paused:
+		return ErrPaused
+	}
+	t.Paused, t.PausedAt = true, now
+	return nil
+}
+
+// Resume lifts a pause, moving the no-show deadlines of waiting matches and the end of a
+// league back by how long it lasted
+func (t *Tournament) Resume(now time.Time) error {
+	if !t.Paused {
+		return ErrNotPaused
+	}
+	paused := now.Sub(t.PausedAt)
+	t.Paused, t.PausedAt = false, time.Time{}
+	for _, match := range t.Playable() {
+		if !match.Deadline.IsZero() {
+			match.Deadline = match.Deadline.Add(paused)
+		}
+	}
+	if t.League() {
+		t.EndsAt = t.EndsAt.Add(paused)
+	}
+	return nil
+}
+
+// ExtendCheckIn moves the start of a scheduled tournament back, giving its entrants longer
+// to check in
+func (t *Tournament) ExtendCheckIn(by time.Duration) error {
+	if !t.Scheduled() {
+		return ErrNotScheduled
+	}
+	t.StartsAt = t.StartsAt.Add(by)
+	t.DelayMinutes += int(by / time.Minute)
+	return nil
+}
+
+// RestartMatch clears a disputed match so it is played again in a new game, which starts
+// from the same seed as the match's latest game so the replay of either is comparable
+// A match playing its game restarts right away; a decided one only while no later match
+// depends on its result: the next round's match hasn't started in a bracket, and its round
+// is the latest in a Swiss tournament. The caller ends the match's game.
+func (t *Tournament) RestartMatch(matchID string) error {
+	match, exists := t.Match(matchID)
+	switch {
+	case !exists:
+		return ErrMatchNotFound
+	case t.Status != StatusRunning:
+		return ErrNotRunning
+	case !match.Playable() && !slices.Contains([]string{ResultPlayed, ResultForfeit, ResultNoShow, ResultDraw}, match.Result):
+		return ErrNotRestartable
+	case !match.Decided() && match.GameID == "":
+		return ErrNotRestartable
+	case t.opponentOfDisqualified(match) != "":
+		return ErrNotRestartable
+	}
+	if match.Decided() {
+		switch {
+		case t.Swiss() && match.Round < len(t.Rounds):
+			return ErrResultUsed
+		case !t.HasStandings():
+			next := t.Rounds[match.Round][match.Index/2]
+			if next.Decided() || next.GameID != "" {
+				return ErrResultUsed
+			}
+			if match.Index%2 == 0 {
+				next.Player1 = nil
+			} else {
+				next.Player2 = nil
+			}
+			next.Deadline = time.Time{}
+		}
+	}
+	match.Winner, match.Result, match.Scores = "", "", nil
+	match.GameID, match.Deadline = "", time.Time{}
+	match.Replay = match.Seed != 0
+	return nil
+}
+
+// Disqualify removes a player from the tournament
+// Before it starts the player is dropped like a withdrawn entrant. Once running, they lose
+// every match they have left: those with a known opponent right away, a bracket match still
+// waiting for the opponent once they arrive. A disqualified entrant isn't placed.
+func (t *Tournament) Disqualify(playerID string, now time.Time) error {
+	index := slices.IndexFunc(t.Entrants, func(e Entrant) bool { return e.ID == playerID })
+	switch {
+	case index < 0:
+		return ErrNotRegistered
+	case t.Open():
+		t.Entrants = slices.Delete(t.Entrants, index, index+1)
+		return nil
+	case t.Status != StatusRunning:
+		return ErrNotRunning
+	case t.Entrants[index].Disqualified:
+		return ErrDisqualified
+	}
+	t.Entrants[index].Disqualified = true
+	for _, match := range t.Playable() {
+		if !match.Has(playerID) || match.Decided() || t.Status != StatusRunning {
+			continue
+		}
+		winner := match.Player1.ID
+		if winner == playerID {
+			winner = match.Player2.ID
+		}
+		if t.HasStandings() {
+			t.settle(match, winner, ResultDisqualified, now)
+		} else {
+			t.decide(match, winner, ResultDisqualified, now)
+		}
+	}
+	return nil
+}
+
+// opponentOfDisqualified returns the opponent of a disqualified player of a playable match,
+// empty when neither player was disqualified
+func (t *Tournament) opponentOfDisqualified(match *Match) string {
+	for _, entrant := range t.Entrants {
+		switch {
+		case !entrant.Disqualified:
+		case entrant.ID == match.Player1.ID:
+			return match.Player2.ID
+		case entrant.ID == match.Player2.ID:
+			return match.Player1.ID
+		}
+	}
+	return ""
+}
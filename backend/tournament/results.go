@@ -0,0 +1,218 @@
+package tournament
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// resultsCollection is where the results of finished tournaments are persisted
+const resultsCollection = "tournament_results"
+
+// Result is the public record of a finished tournament: its placings and every match
+// Results are kept for good, unlike the finished tournaments players look up in the lobby.
+type Result struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Format        string          `json:"format"`
+	Rules         string          `json:"rules"`
+	OrganizerName string          `json:"organizer_name"`
+	Series        string          `json:"series,omitempty"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    time.Time       `json:"finished_at"`
+	Champion      *Placement      `json:"champion,omitempty"` // A league whose matches all expired has none
+	Placements    []Placement     `json:"placements"`         // By finish, disqualified entrants last
+	Rounds        [][]MatchResult `json:"rounds"`
+}
+
+// Placement is an entrant's final placing
+type Placement struct {
+	PlayerID     string `json:"player_id"`
+	Username     string `json:"username"`
+	Finish       int    `json:"finish,omitempty"` // Zero for a disqualified entrant
+	Seed         int    `json:"seed"`
+	Rating       int    `json:"rating,omitempty"` // Rating when registered
+	Disqualified bool   `json:"disqualified,omitempty"`
+}
+
+// MatchResult is how a match of a finished tournament was decided
+type MatchResult struct {
+	ID      string        `json:"id"`
+	Round   int           `json:"round"`
+	Players []MatchPlayer `json:"players"`          // One player for a bye
+	Winner  string        `json:"winner,omitempty"` // Player ID, empty for a draw or an expired match
+	Result  string        `json:"result"`
+}
+
+// MatchPlayer is one side of a match result
+type MatchPlayer struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Score    *int   `json:"score,omitempty"` // In the game that decided the match; absent when none was played
+}
+
+// ResultSummary lists a result without its matches
+type ResultSummary struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Format     string    `json:"format"`
+	FinishedAt time.Time `json:"finished_at"`
+	Players    int       `json:"players"`
+	Champion   string    `json:"champion,omitempty"` // Username
+}
+
+// ResultOf records the outcome of a finished tournament
+func ResultOf(t Tournament) Result {
+	result := Result{
+		ID:            t.ID,
+		Name:          t.Name,
+		Format:        t.Format,
+		Rules:         t.Rules,
+		OrganizerName: t.OrganizerName,
+		Series:        t.Series,
+		StartedAt:     t.StartedAt,
+		FinishedAt:    t.FinishedAt,
+		Placements:    make([]Placement, 0, len(t.Entrants)),
+		Rounds:        make([][]MatchResult, 0, len(t.Rounds)),
+	}
+	for _, entrant := range t.Entrants {
+		result.Placements = append(result.Placements, Placement{
+			PlayerID:     entrant.ID,
+			Username:     entrant.Username,
+			Finish:       entrant.Finish,
+			Seed:         entrant.Seed,
+			Rating:       entrant.Rating,
+			Disqualified: entrant.Disqualified,
+		})
+	}
+	slices.SortStableFunc(result.Placements, func(a, b Placement) int {
+		return cmp.Or(compareBool(a.Finish == 0, b.Finish == 0), cmp.Compare(a.Finish, b.Finish))
+	})
+	if t.Champion != nil {
+		index := slices.IndexFunc(result.Placements, func(p Placement) bool { return p.PlayerID == t.Champion.ID })
+		if index >= 0 {
+			champion := result.Placements[index]
+			result.Champion = &champion
+		}
+	}
+
+	for _, round := range t.Rounds {
+		matches := make([]MatchResult, 0, len(round))
+		for _, match := range round {
+			played := MatchResult{ID: match.ID, Round: match.Round, Winner: match.Winner, Result: match.Result}
+			for _, entrant := range []*Entrant{match.Player1, match.Player2} {
+				if entrant == nil {
+					continue
+				}
+				player := MatchPlayer{ID: entrant.ID, Username: entrant.Username}
+				if score, exists := match.Scores[entrant.ID]; exists {
+					player.Score = &score
+				}
+				played.Players = append(played.Players, player)
+			}
+			matches = append(matches, played)
+		}
+		result.Rounds = append(result.Rounds, matches)
+	}
+	return result
+}
+
+// Summary returns the result without its matches
+func (r Result) Summary() ResultSummary {
+	summary := ResultSummary{
+		ID:         r.ID,
+		Name:       r.Name,
+		Format:     r.Format,
+		FinishedAt: r.FinishedAt,
+		Players:    len(r.Placements),
+	}
+	if r.Champion != nil {
+		summary.Champion = r.Champion.Username
+	}
+	return summary
+}
+
+// Archive keeps the results of every finished tournament and persists them to storage
+type Archive struct {
+	store storage.Store
+
+	mu      sync.RWMutex
+	results []*Result // In the order they finished
+	byID    map[string]*Result
+}
+
+// OpenArchive loads the persisted tournament results from the store
+func OpenArchive(store storage.Store) (*Archive, error) {
+	a := &Archive{store: store, byID: make(map[string]*Result)}
+	err := store.Load(resultsCollection, func(raw json.RawMessage) error {
+		var result Result
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return fmt.Errorf("decoding tournament result: %w", err)
+		}
+		a.addLocked(result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Record archives the result of a finished tournament; a tournament already archived is
+// left as it was
+// The result is kept in memory even if persisting it fails.
+func (a *Archive) Record(t Tournament) error {
+	result := ResultOf(t)
+	a.mu.Lock()
+	if _, exists := a.byID[result.ID]; exists {
+		a.mu.Unlock()
+		return nil
+	}
+	a.addLocked(result)
+	a.mu.Unlock()
+
+	if err := a.store.Append(resultsCollection, result); err != nil {
+		return fmt.Errorf("persisting tournament result: %w", err)
+	}
+	return nil
+}
+
+// Get returns the result of a tournament
+func (a *Archive) Get(id string) (Result, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	result, exists := a.byID[id]
+	if !exists {
+		return Result{}, false
+	}
+	return *result, true
+}
+
+// List returns up to limit results, most recently finished first, skipping the first offset
+// Also returns how many results are archived.
+func (a *Archive) List(limit, offset int) ([]ResultSummary, int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	summaries := []ResultSummary{}
+	for i := len(a.results) - 1 - offset; i >= 0 && len(summaries) < limit; i-- {
+		summaries = append(summaries, a.results[i].Summary())
+	}
+	return summaries, len(a.results)
+}
+
+// addLocked stores a result; caller must hold mu or own a exclusively
+// A result loaded twice keeps its latest copy.
+func (a *Archive) addLocked(result Result) {
+	if existing, exists := a.byID[result.ID]; exists {
+		*existing = result
+		return
+	}
+	stored := &result
+	a.results = append(a.results, stored)
+	a.byID[result.ID] = stored
+}
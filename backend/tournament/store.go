@@ -0,0 +1,45 @@
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bariiss/snake/backend/storage"
+)
+
+// collection is where tournament snapshots are persisted
+const collection = "tournaments"
+
+// Save persists a snapshot of a tournament
+// Snapshots are appended; Load keeps the one with the highest revision.
+func Save(store storage.Store, t Tournament) error {
+	return store.Append(collection, t)
+}
+
+// Load rebuilds every persisted tournament from its latest snapshot
+func Load(store storage.Store) ([]*Tournament, error) {
+	latest := make(map[string]*Tournament)
+	var order []string
+	err := store.Load(collection, func(raw json.RawMessage) error {
+		t := &Tournament{}
+		if err := json.Unmarshal(raw, t); err != nil {
+			return fmt.Errorf("decoding tournament: %w", err)
+		}
+		previous, seen := latest[t.ID]
+		if !seen {
+			order = append(order, t.ID)
+		}
+		if !seen || t.Revision >= previous.Revision {
+			latest[t.ID] = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tournaments := make([]*Tournament, 0, len(order))
+	for _, id := range order {
+		tournaments = append(tournaments, latest[id])
+	}
+	return tournaments, nil
+}
@@ -0,0 +1,107 @@
+package tournament
+
+import (
+	"fmt"
+	"slices"
+)
+
+// swissRounds returns how many rounds a Swiss tournament of the given size plays: as many
+// as an elimination bracket of the same size, so a single entrant can win them all
+func swissRounds(entrants int) int {
+	rounds := 1
+	for 1<<rounds < entrants {
+		rounds++
+	}
+	return rounds
+}
+
+// drawSwiss pairs the next round of a Swiss tournament
+// The first round pairs the top half of the seeds with the bottom half; later rounds go down
+// the standings, pairing each entrant with the next one they haven't met, or with the next
+// one when they met everybody left. With an odd number of entrants, the lowest-ranked
+// entrant who hasn't had a bye yet sits the round out and wins it. Disqualified entrants
+// aren't drawn.
+func (t *Tournament) drawSwiss() {
+	round := len(t.Rounds) + 1
+	order := slices.Clone(t.Entrants)
+	if round > 1 {
+		order = order[:0]
+		for _, standing := range t.Standings() {
+			order = append(order, standing.Entrant)
+		}
+	}
+	order = slices.DeleteFunc(order, func(e Entrant) bool { return e.Disqualified })
+	met, byes := t.swissHistory()
+
+	var bye *Entrant
+	if len(order)%2 == 1 {
+		index := len(order) - 1
+		for i := len(order) - 1; i >= 0; i-- {
+			if !byes[order[i].ID] {
+				index = i
+				break
+			}
+		}
+		bye = &order[index]
+		order = slices.Delete(slices.Clone(order), index, index+1)
+	}
+
+	var matches []*Match
+	pair := func(one, two Entrant) {
+		matches = append(matches, &Match{
+			ID:      fmt.Sprintf("R%d-M%d", round, len(matches)+1),
+			Round:   round,
+			Index:   len(matches),
+			Player1: &one,
+			Player2: &two,
+		})
+	}
+	if round == 1 {
+		half := len(order) / 2
+		for i := range half {
+			pair(order[i], order[i+half])
+		}
+	} else {
+		for len(order) > 0 {
+			one := order[0]
+			next := slices.IndexFunc(order[1:], func(e Entrant) bool { return !met[one.ID][e.ID] }) + 1
+			if next == 0 {
+				next = 1
+			}
+			pair(one, order[next])
+			order = slices.Delete(order, next, next+1)[1:]
+		}
+	}
+	if bye != nil {
+		entrant := *bye
+		matches = append(matches, &Match{
+			ID:      fmt.Sprintf("R%d-M%d", round, len(matches)+1),
+			Round:   round,
+			Index:   len(matches),
+			Player1: &entrant,
+			Winner:  entrant.ID,
+			Result:  ResultBye,
+		})
+	}
+	t.Rounds = append(t.Rounds, matches)
+}
+
+// swissHistory returns who met whom in the rounds drawn so far, and who had a bye
+func (t *Tournament) swissHistory() (map[string]map[string]bool, map[string]bool) {
+	met := make(map[string]map[string]bool, len(t.Entrants))
+	byes := make(map[string]bool)
+	for _, entrant := range t.Entrants {
+		met[entrant.ID] = make(map[string]bool)
+	}
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if match.Result == ResultBye {
+				byes[match.Winner] = true
+				continue
+			}
+			met[match.Player1.ID][match.Player2.ID] = true
+			met[match.Player2.ID][match.Player1.ID] = true
+		}
+	}
+	return met, byes
+}
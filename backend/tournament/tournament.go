@@ -0,0 +1,562 @@
+// Package tournament models single-elimination brackets, round-robin leagues and Swiss
+// tournaments: registration, check-in, seeding, byes and deciding matches until a champion
+// is left
+//
+// A Tournament is plain data and is not safe for concurrent use; the game manager guards
+// it and creates the games its matches are played in.
+package tournament
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Tournament statuses
+const (
+	StatusRegistering = "registering"
+	StatusCheckIn     = "check_in" // Registered players confirm they will play before it starts
+	StatusRunning     = "running"
+	StatusFinished    = "finished"
+	StatusCanceled    = "canceled" // Too few players checked in by the start time
+)
+
+// Tournament formats
+const (
+	FormatElimination = "elimination" // Single elimination: winners advance until one is left
+	FormatLeague      = "league"      // Round robin: every entrant plays every other within a window
+	FormatSwiss       = "swiss"       // Entrants with equal points meet, for a fixed number of rounds
+)
+
+// CheckInWindow is how long before a scheduled start check-in opens
+const CheckInWindow = 10 * time.Minute
+
+// MaxRoundCheckIn is the longest round check-in, in minutes
+const MaxRoundCheckIn = 15
+
+// How a scheduled tournament repeats
+const (
+	RepeatDaily  = "daily"
+	RepeatWeekly = "weekly"
+)
+
+// Player limits of a bracket
+const (
+	MinPlayers = 2
+	MaxPlayers = 64
+)
+
+// How a match was decided
+const (
+	ResultPlayed  = "played"  // The winner won the round
+	ResultForfeit = "forfeit" // The loser left the game
+	ResultNoShow  = "no_show" // The loser didn't show up, check in or get ready in time
+	ResultBye     = "bye"     // The winner had no opponent
+	ResultDraw    = "draw"    // A league round ended in a tie
+	ResultExpired = "expired" // The league window closed before the match was played
+
+	ResultDisqualified = "disqualified" // The loser was disqualified by a moderator
+)
+
+// Errors returned for refused changes
+var (
+	ErrNotRegistering    = errors.New("registration is closed")
+	ErrNotCheckingIn     = errors.New("check-in is not open")
+	ErrFull              = errors.New("the tournament is full")
+	ErrAlreadyRegistered = errors.New("already registered")
+	ErrNotRegistered     = errors.New("not registered")
+	ErrTooFewPlayers     = fmt.Errorf("a tournament needs at least %d players", MinPlayers)
+	ErrMatchNotFound     = errors.New("match not found")
+	ErrMatchDecided      = errors.New("match already decided")
+	ErrNotInMatch        = errors.New("not a player of the match")
+	ErrNotRunning        = errors.New("the tournament is not running")
+	ErrPaused            = errors.New("the tournament is already paused")
+	ErrNotPaused         = errors.New("the tournament is not paused")
+	ErrNotScheduled      = errors.New("the tournament has no scheduled check-in")
+	ErrNotRestartable    = errors.New("the match can't be restarted")
+	ErrResultUsed        = errors.New("later matches depend on the result")
+	ErrDisqualified      = errors.New("already disqualified")
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrAlreadyAppointed  = errors.New("already appointed")
+	ErrNotAppointed      = errors.New("not appointed")
+)
+
+// Entrant is a registered player
+type Entrant struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Seed     int    `json:"seed,omitempty"`   // Set when the tournament starts; 1 is the top seed
+	Rating   int    `json:"rating,omitempty"` // Rating when registered, which seeds are given by
+	Finish   int    `json:"finish,omitempty"` // Final placing once finished; tied entrants share the best
+
+	CheckedIn    bool `json:"checked_in,omitempty"`   // Confirmed during check-in
+	Disqualified bool `json:"disqualified,omitempty"` // Removed by a moderator; loses every match left and isn't placed
+}
+
+// Match is a pairing of the bracket
+type Match struct {
+	ID       string    `json:"id"`
+	Round    int       `json:"round"` // 1 for the first round
+	Index    int       `json:"index"` // Position within the round, from the top of the bracket
+	Player1  *Entrant  `json:"player1"`
+	Player2  *Entrant  `json:"player2"`
+	GameID   string    `json:"game_id,omitempty"` // The game being played for the match
+	Winner   string    `json:"winner,omitempty"`  // Player ID of the winner, empty for a draw
+	Result   string    `json:"result,omitempty"`  // How the match was decided, empty until it is
+	Deadline time.Time `json:"deadline,omitzero"` // When players who haven't shown up forfeit
+	Seed     int64     `json:"seed,omitempty"`    // Seed of the latest game played for the match
+	Replay   bool      `json:"replay,omitempty"`  // The next game reuses Seed; set when a moderator restarts the match
+
+	Scores   map[string]int `json:"scores,omitempty"`    // Player ID -> score in the game that decided the match
+	CheckIns []string       `json:"check_ins,omitempty"` // Players who checked in to the match, with round check-in
+}
+
+// Decided reports whether the match has a result
+func (m *Match) Decided() bool {
+	return m.Result != ""
+}
+
+// CheckedIn reports whether a player checked in to the match
+func (m *Match) CheckedIn(playerID string) bool {
+	return slices.Contains(m.CheckIns, playerID)
+}
+
+// Playable reports whether both players are known and the match is undecided
+func (m *Match) Playable() bool {
+	return m.Player1 != nil && m.Player2 != nil && !m.Decided()
+}
+
+// Has reports whether the player plays in the match
+func (m *Match) Has(playerID string) bool {
+	return (m.Player1 != nil && m.Player1.ID == playerID) || (m.Player2 != nil && m.Player2.ID == playerID)
+}
+
+// Tournament is a single-elimination bracket, a league or a Swiss tournament
+type Tournament struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Format        string     `json:"format"` // elimination, league or swiss
+	Rules         string     `json:"rules"`  // Rules preset of every match
+	OrganizerID   string     `json:"organizer_id"`
+	OrganizerName string     `json:"organizer_name"`
+	MaxPlayers    int        `json:"max_players"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     time.Time  `json:"started_at,omitzero"`
+	FinishedAt    time.Time  `json:"finished_at,omitzero"`
+	Entrants      []Entrant  `json:"entrants"` // In registration order, then by seed once started
+	Rounds        [][]*Match `json:"rounds"`   // First round first
+	Champion      *Entrant   `json:"champion,omitempty"`
+
+	StartsAt time.Time `json:"starts_at,omitzero"` // Scheduled start, zero when the organizer starts it
+	Repeat   string    `json:"repeat,omitempty"`   // daily or weekly for a recurring tournament
+	Series   string    `json:"series,omitempty"`   // ID of the first tournament of a recurring series
+	Revision int       `json:"revision"`           // Incremented on every change; the latest persisted revision wins
+
+	WindowHours int       `json:"window_hours,omitempty"` // How long a league runs once started
+	EndsAt      time.Time `json:"ends_at,omitzero"`       // When a running league closes
+
+	SwissRounds int `json:"swiss_rounds,omitempty"` // Rounds a Swiss tournament plays; each is drawn once the previous one is decided
+
+	Moderators   []Staff   `json:"moderators,omitempty"`    // Players the organizer lets run the tournament with them
+	Casters      []Staff   `json:"casters,omitempty"`       // Players the organizer lets follow several match games at once
+	Paused       bool      `json:"paused,omitempty"`        // No match games are created and nobody forfeits while paused
+	PausedAt     time.Time `json:"paused_at,omitzero"`      // When the running pause began
+	DelayMinutes int       `json:"delay_minutes,omitempty"` // How far check-in was extended past the scheduled start
+
+	RoundCheckIn int `json:"round_check_in,omitempty"` // Minutes players have to check in to each match; 0 when they don't
+}
+
+// New opens a tournament for registration
+func New(id, name, rules, organizerID, organizerName string, maxPlayers int, now time.Time) *Tournament {
+	return &Tournament{
+		ID:            id,
+		Name:          name,
+		Format:        FormatElimination,
+		Rules:         rules,
+		OrganizerID:   organizerID,
+		OrganizerName: organizerName,
+		MaxPlayers:    maxPlayers,
+		Status:        StatusRegistering,
+		CreatedAt:     now,
+		Entrants:      []Entrant{},
+		Rounds:        [][]*Match{},
+	}
+}
+
+// League reports whether the tournament is a round-robin league
+func (t *Tournament) League() bool {
+	return t.Format == FormatLeague
+}
+
+// Swiss reports whether the tournament pairs entrants by points each round
+func (t *Tournament) Swiss() bool {
+	return t.Format == FormatSwiss
+}
+
+// HasStandings reports whether the tournament ranks entrants by points rather than by
+// elimination: leagues and Swiss tournaments. A tie in one of its matches is a draw.
+func (t *Tournament) HasStandings() bool {
+	return t.League() || t.Swiss()
+}
+
+// Scheduled reports whether the tournament waits for its start time
+func (t *Tournament) Scheduled() bool {
+	return !t.StartsAt.IsZero() && t.Open()
+}
+
+// Open reports whether players can still register
+func (t *Tournament) Open() bool {
+	return t.Status == StatusRegistering || t.Status == StatusCheckIn
+}
+
+// Done reports whether the tournament finished or was canceled
+func (t *Tournament) Done() bool {
+	return t.Status == StatusFinished || t.Status == StatusCanceled
+}
+
+// Registered reports whether the player is an entrant
+func (t *Tournament) Registered(playerID string) bool {
+	return slices.ContainsFunc(t.Entrants, func(e Entrant) bool { return e.ID == playerID })
+}
+
+// Register adds an entrant while registration is open
+// Registering during check-in checks the entrant in.
+func (t *Tournament) Register(entrant Entrant) error {
+	switch {
+	case !t.Open():
+		return ErrNotRegistering
+	case t.Registered(entrant.ID):
+		return ErrAlreadyRegistered
+	case len(t.Entrants) >= t.MaxPlayers:
+		return ErrFull
+	}
+	entrant.CheckedIn = t.Status == StatusCheckIn
+	t.Entrants = append(t.Entrants, entrant)
+	return nil
+}
+
+// Unregister removes an entrant while registration is open
+func (t *Tournament) Unregister(playerID string) error {
+	if !t.Open() {
+		return ErrNotRegistering
+	}
+	if !t.Registered(playerID) {
+		return ErrNotRegistered
+	}
+	t.Entrants = slices.DeleteFunc(t.Entrants, func(e Entrant) bool { return e.ID == playerID })
+	return nil
+}
+
+// OpenCheckIn moves a tournament open for registration to check-in
+func (t *Tournament) OpenCheckIn() error {
+	if t.Status != StatusRegistering {
+		return ErrNotRegistering
+	}
+	t.Status = StatusCheckIn
+	return nil
+}
+
+// CheckIn confirms an entrant will play
+// Once a tournament with round check-in runs, it confirms the entrant will play their next
+// match, whose game is only created once both players checked in.
+func (t *Tournament) CheckIn(playerID string) error {
+	if t.Status == StatusRunning && t.RoundCheckIn > 0 {
+		return t.checkInMatch(playerID)
+	}
+	if t.Status != StatusCheckIn {
+		return ErrNotCheckingIn
+	}
+	index := slices.IndexFunc(t.Entrants, func(e Entrant) bool { return e.ID == playerID })
+	if index < 0 {
+		return ErrNotRegistered
+	}
+	t.Entrants[index].CheckedIn = true
+	return nil
+}
+
+// checkInMatch checks an entrant in to their match waiting for its game
+func (t *Tournament) checkInMatch(playerID string) error {
+	for _, match := range t.Playable() {
+		if !match.Has(playerID) || match.GameID != "" {
+			continue
+		}
+		if !match.CheckedIn(playerID) {
+			match.CheckIns = append(match.CheckIns, playerID)
+		}
+		return nil
+	}
+	if t.Registered(playerID) {
+		return ErrNotCheckingIn
+	}
+	return ErrNotRegistered
+}
+
+// Start closes registration and draws the bracket, seeding entrants by rating, then in
+// registration order. Entrants who didn't check in are dropped when check-in was open. The
+// bracket is padded to a power of two with byes, which go to the top seeds.
+func (t *Tournament) Start(now time.Time) error {
+	if !t.Open() {
+		return ErrNotRegistering
+	}
+	entrants := t.Entrants
+	if t.Status == StatusCheckIn {
+		entrants = slices.DeleteFunc(slices.Clone(entrants), func(e Entrant) bool { return !e.CheckedIn })
+	}
+	if len(entrants) < MinPlayers {
+		return ErrTooFewPlayers
+	}
+	t.Entrants = slices.Clone(entrants)
+	slices.SortStableFunc(t.Entrants, func(a, b Entrant) int { return cmp.Compare(b.Rating, a.Rating) })
+	for i := range t.Entrants {
+		t.Entrants[i].Seed = i + 1
+	}
+	t.Status = StatusRunning
+	t.StartedAt = now
+	switch {
+	case t.League():
+		t.drawLeague(now)
+		return nil
+	case t.Swiss():
+		t.SwissRounds = swissRounds(len(t.Entrants))
+		t.Rounds = nil
+		t.drawSwiss()
+		return nil
+	}
+
+	size := 1
+	for size < len(t.Entrants) {
+		size *= 2
+	}
+	order := seedOrder(size)
+	t.Rounds = nil
+	for round, matches := 1, size/2; matches >= 1; round, matches = round+1, matches/2 {
+		t.Rounds = append(t.Rounds, make([]*Match, matches))
+		for index := range matches {
+			t.Rounds[round-1][index] = &Match{ID: fmt.Sprintf("R%d-M%d", round, index+1), Round: round, Index: index}
+		}
+	}
+	for index, match := range t.Rounds[0] {
+		match.Player1 = t.seeded(order[2*index])
+		match.Player2 = t.seeded(order[2*index+1])
+	}
+
+	// Byes are decided right away
+	for _, match := range t.Rounds[0] {
+		switch {
+		case match.Player2 == nil:
+			t.decide(match, match.Player1.ID, ResultBye, now)
+		case match.Player1 == nil:
+			t.decide(match, match.Player2.ID, ResultBye, now)
+		}
+	}
+	return nil
+}
+
+// Cancel ends a tournament that can't start
+func (t *Tournament) Cancel(now time.Time) {
+	t.Status = StatusCanceled
+	t.FinishedAt = now
+}
+
+// Next returns the next occurrence of a recurring tournament, open for registration from
+// now and starting at the first repeat after now; nil if it doesn't repeat
+func (t *Tournament) Next(id string, now time.Time) *Tournament {
+	interval, repeats := RepeatInterval(t.Repeat)
+	if !repeats || t.StartsAt.IsZero() {
+		return nil
+	}
+	next := New(id, t.Name, t.Rules, t.OrganizerID, t.OrganizerName, t.MaxPlayers, now)
+	// Extending check-in delays this occurrence only
+	next.StartsAt = t.StartsAt.Add(interval - time.Duration(t.DelayMinutes)*time.Minute)
+	for !next.StartsAt.After(now) {
+		next.StartsAt = next.StartsAt.Add(interval)
+	}
+	next.Repeat = t.Repeat
+	next.Format, next.WindowHours, next.RoundCheckIn = t.Format, t.WindowHours, t.RoundCheckIn
+	next.Series = cmp.Or(t.Series, t.ID)
+	next.Moderators, next.Casters = slices.Clone(t.Moderators), slices.Clone(t.Casters)
+	return next
+}
+
+// RepeatInterval returns the time between occurrences of a repeat, reporting whether it is known
+func RepeatInterval(repeat string) (time.Duration, bool) {
+	switch repeat {
+	case RepeatDaily:
+		return 24 * time.Hour, true
+	case RepeatWeekly:
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// Match returns the match with the given ID
+func (t *Tournament) Match(matchID string) (*Match, bool) {
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if match.ID == matchID {
+				return match, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Playable returns the undecided matches whose players are both known
+func (t *Tournament) Playable() []*Match {
+	var matches []*Match
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if match.Playable() {
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// Decide records the winner of a match and advances them to the next round
+// Deciding the final finishes the tournament, as does deciding a league's last match or the
+// last match of a Swiss tournament's last round.
+func (t *Tournament) Decide(matchID, winnerID, result string, now time.Time) error {
+	match, exists := t.Match(matchID)
+	switch {
+	case !exists:
+		return ErrMatchNotFound
+	case match.Decided():
+		return ErrMatchDecided
+	case !match.Has(winnerID):
+		return ErrNotInMatch
+	}
+	if t.HasStandings() {
+		t.settle(match, winnerID, result, now)
+		return nil
+	}
+	t.decide(match, winnerID, result, now)
+	return nil
+}
+
+// decide records a winner the caller checked belongs to the match
+func (t *Tournament) decide(match *Match, winnerID, result string, now time.Time) {
+	match.Winner = winnerID
+	match.Result = result
+	match.Deadline = time.Time{}
+	winner := match.Player1
+	if match.Player2 != nil && match.Player2.ID == winnerID {
+		winner = match.Player2
+	}
+
+	if match.Round == len(t.Rounds) {
+		t.Status = StatusFinished
+		t.FinishedAt = now
+		t.placeEliminated(winner.ID)
+		return
+	}
+	next := t.Rounds[match.Round][match.Index/2]
+	advanced := *winner
+	if match.Index%2 == 0 {
+		next.Player1 = &advanced
+	} else {
+		next.Player2 = &advanced
+	}
+	// A disqualified player waiting in the next round loses to whoever joins them
+	if next.Playable() {
+		if opponent := t.opponentOfDisqualified(next); opponent != "" {
+			t.decide(next, opponent, ResultDisqualified, now)
+		}
+	}
+}
+
+// placeEliminated gives every entrant of a finished bracket their placing: the champion is
+// first and the losers of a round share the place below those who went further, so the
+// losing semifinalists are both third. The champion is recorded with their placing.
+func (t *Tournament) placeEliminated(championID string) {
+	finishes := map[string]int{championID: 1}
+	for _, round := range t.Rounds {
+		for _, match := range round {
+			if loser := match.loser(); loser != nil {
+				finishes[loser.ID] = 1<<(len(t.Rounds)-match.Round) + 1
+			}
+		}
+	}
+	t.place(finishes)
+}
+
+// place records the entrants' placings, and the champion as the entrant placed first
+func (t *Tournament) place(finishes map[string]int) {
+	for i := range t.Entrants {
+		t.Entrants[i].Finish = finishes[t.Entrants[i].ID]
+		if t.Entrants[i].Disqualified {
+			t.Entrants[i].Finish = 0
+		}
+		if t.Entrants[i].Finish == 1 {
+			champion := t.Entrants[i]
+			t.Champion = &champion
+		}
+	}
+}
+
+// loser returns the player who lost a decided match, nil for a bye or a draw
+func (m *Match) loser() *Entrant {
+	switch {
+	case !m.Decided() || m.Winner == "" || m.Player1 == nil || m.Player2 == nil:
+		return nil
+	case m.Player1.ID == m.Winner:
+		return m.Player2
+	}
+	return m.Player1
+}
+
+// seeded returns the entrant with the given seed, nil past the last entrant
+func (t *Tournament) seeded(seed int) *Entrant {
+	if seed > len(t.Entrants) {
+		return nil
+	}
+	entrant := t.Entrants[seed-1]
+	return &entrant
+}
+
+// Clone returns a deep copy that can be read while the tournament keeps changing
+func (t *Tournament) Clone() Tournament {
+	copied := *t
+	copied.Entrants = slices.Clone(t.Entrants)
+	copied.Moderators, copied.Casters = slices.Clone(t.Moderators), slices.Clone(t.Casters)
+	copied.Rounds = make([][]*Match, len(t.Rounds))
+	for i, round := range t.Rounds {
+		copied.Rounds[i] = make([]*Match, len(round))
+		for j, match := range round {
+			m := *match
+			m.Player1, m.Player2 = cloneEntrant(match.Player1), cloneEntrant(match.Player2)
+			m.Scores, m.CheckIns = maps.Clone(match.Scores), slices.Clone(match.CheckIns)
+			copied.Rounds[i][j] = &m
+		}
+	}
+	copied.Champion = cloneEntrant(t.Champion)
+	return copied
+}
+
+// cloneEntrant copies an optional entrant
+func cloneEntrant(entrant *Entrant) *Entrant {
+	if entrant == nil {
+		return nil
+	}
+	copied := *entrant
+	return &copied
+}
+
+// seedOrder returns the seeds of a bracket of the given size from top to bottom, so that
+// seeds 1 and 2 can only meet in the final: 1, 8, 4, 5, 2, 7, 3, 6 for 8 players
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, 2*len(order))
+		for _, seed := range order {
+			next = append(next, seed, 2*len(order)+1-seed)
+		}
+		order = next
+	}
+	return order
+}
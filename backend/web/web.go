@@ -0,0 +1,121 @@
+// Package web serves the built web client embedded in the binary
+//
+// The frontend build output is copied into web/dist before `go build` (the root Dockerfile
+// does this), so a single binary serves both the client and the game server. Builds
+// without it embed only a placeholder and Handler reports that the client is missing.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+const (
+	// immutableCache is sent for files whose name carries a content hash
+	immutableCache = "public, max-age=31536000, immutable"
+	// assetCache is sent for other static files, which may change between releases
+	assetCache = "public, max-age=3600"
+	// documentCache makes browsers revalidate index.html, so a release is picked up
+	// on the next load; the ETag keeps revalidation cheap
+	documentCache = "no-cache"
+)
+
+// hashedName matches file names with a build hash, such as main.3f2a9c1b7d4e8f60.js
+var hashedName = regexp.MustCompile(`[.-][0-9a-f]{16,}\.`)
+
+// ErrNotEmbedded is returned by Handler when the binary was built without the web client
+var ErrNotEmbedded = errors.New("web client not embedded: copy the frontend build into backend/web/dist and rebuild")
+
+// file is an embedded file with its precomputed validator
+type file struct {
+	content []byte
+	etag    string
+}
+
+// handler serves the embedded files, falling back to index.html for client routes
+type handler struct {
+	files   map[string]file
+	index   file
+	modTime time.Time
+}
+
+// Handler returns the handler serving the embedded web client
+func Handler() (http.Handler, error) {
+	root, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, err
+	}
+	h := &handler{files: make(map[string]file), modTime: time.Now()}
+	err = fs.WalkDir(root, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || name == ".gitkeep" {
+			return err
+		}
+		content, err := fs.ReadFile(root, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		h.files[name] = file{content: content, etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	index, ok := h.files["index.html"]
+	if !ok {
+		return nil, ErrNotEmbedded
+	}
+	h.index = index
+	return h, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	f, ok := h.files[name]
+	switch {
+	case ok && name != "index.html":
+		if hashedName.MatchString(path.Base(name)) {
+			w.Header().Set("Cache-Control", immutableCache)
+		} else {
+			w.Header().Set("Cache-Control", assetCache)
+		}
+	case ok || isClientRoute(name):
+		// Client-side routes get the app shell; the client's router takes over
+		name, f = "index.html", h.index
+		w.Header().Set("Cache-Control", documentCache)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("ETag", f.etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, h.modTime, bytes.NewReader(f.content))
+}
+
+// isClientRoute reports whether a path that matches no file is a route of the client
+// rather than a missing asset or an unknown API endpoint
+func isClientRoute(name string) bool {
+	if name == "api" || strings.HasPrefix(name, "api/") {
+		return false
+	}
+	return path.Ext(name) == ""
+}
@@ -2,12 +2,10 @@ package webrtc
 
 import (
 	"encoding/json"
-	"fmt"
-	"log"
-	"os"
 	"sync"
 
-	"snake-backend/models"
+	"github.com/bariiss/snake/backend/config"
+	"github.com/bariiss/snake/backend/models"
 
 	"github.com/pion/webrtc/v3"
 )
@@ -20,14 +18,17 @@ type PeerConnection struct {
 }
 
 type Manager struct {
-	peers map[string]*PeerConnection
-	mutex sync.RWMutex
+	peers      map[string]*PeerConnection
+	iceServers []webrtc.ICEServer
+	mutex      sync.RWMutex
 }
 
 func NewManager() *Manager {
-	return &Manager{
+	manager := &Manager{
 		peers: make(map[string]*PeerConnection),
 	}
+	manager.SetICEServers(config.Default().ICEServers)
+	return manager
 }
 
 func (m *Manager) CreatePeerConnection(player *models.Player) (*PeerConnection, error) {
@@ -41,24 +42,24 @@ func (m *Manager) CreatePeerConnection(player *models.Player) (*PeerConnection,
 	// Handle ICE candidates
 	peerConnection.OnICECandidate(func(candidate *webrtc.ICECandidate) {
 		if candidate != nil {
-			log.Printf("ICE Candidate for %s: %s", player.Username, candidate.String())
+			player.Logger().Debug("ICE candidate", "candidate", candidate.String())
 		} else {
-			log.Printf("ICE Candidate gathering completed for %s", player.Username)
+			player.Logger().Debug("ICE candidate gathering completed")
 		}
 	})
 
 	// Handle ICE connection state
 	peerConnection.OnICEConnectionStateChange(func(state webrtc.ICEConnectionState) {
-		log.Printf("ICE Connection State for %s: %s", player.Username, state.String())
+		player.Logger().Info("ICE connection state changed", "state", state.String())
 		if state == webrtc.ICEConnectionStateDisconnected || state == webrtc.ICEConnectionStateFailed {
-			log.Printf("ICE Connection failed for %s, removing peer", player.Username)
+			player.Logger().Warn("ICE connection failed, removing peer")
 			m.RemovePeer(player.ID)
 		}
 	})
 
 	// Handle ICE gathering state
 	peerConnection.OnICEGatheringStateChange(func(state webrtc.ICEGathererState) {
-		log.Printf("ICE Gathering State for %s: %s", player.Username, state.String())
+		player.Logger().Debug("ICE gathering state changed", "state", state.String())
 	})
 
 	// Create data channel
@@ -76,22 +77,22 @@ func (m *Manager) CreatePeerConnection(player *models.Player) (*PeerConnection,
 
 	// Set up data channel handlers
 	dataChannel.OnOpen(func() {
-		log.Printf("DataChannel opened for player %s", player.Username)
+		player.Logger().Info("DataChannel opened")
 	})
 
 	dataChannel.OnMessage(func(msg webrtc.DataChannelMessage) {
 		// Messages will be handled by the game manager
 		// This is just for logging
-		log.Printf("Received message from %s", player.Username)
+		player.Logger().Debug("DataChannel message received")
 	})
 
 	dataChannel.OnClose(func() {
-		log.Printf("DataChannel closed for player %s", player.Username)
+		player.Logger().Info("DataChannel closed")
 		m.RemovePeer(player.ID)
 	})
 
 	dataChannel.OnError(func(err error) {
-		log.Printf("DataChannel error for %s: %v", player.Username, err)
+		player.Logger().Warn("DataChannel error", "error", err)
 	})
 
 	m.mutex.Lock()
@@ -120,6 +121,17 @@ func (m *Manager) RemovePeer(playerID string) {
 }
 
 func (m *Manager) SendMessage(playerID string, messageType string, data any) error {
+	return m.SendEncoded(playerID, func() ([]byte, error) {
+		return json.Marshal(map[string]any{
+			"type": messageType,
+			"data": data,
+		})
+	})
+}
+
+// SendEncoded sends a message over the player's data channel
+// encode is only called when the channel is open, so callers can serialize lazily
+func (m *Manager) SendEncoded(playerID string, encode func() ([]byte, error)) error {
 	peer, exists := m.GetPeer(playerID)
 	if !exists || peer.DataChannel == nil {
 		return nil // Peer not found or channel not ready
@@ -129,12 +141,7 @@ func (m *Manager) SendMessage(playerID string, messageType string, data any) err
 		return nil // Channel not open
 	}
 
-	payload := map[string]any{
-		"type": messageType,
-		"data": data,
-	}
-
-	jsonData, err := json.Marshal(payload)
+	jsonData, err := encode()
 	if err != nil {
 		return err
 	}
@@ -142,42 +149,44 @@ func (m *Manager) SendMessage(playerID string, messageType string, data any) err
 	return peer.DataChannel.Send(jsonData)
 }
 
+// HasOpenChannel reports whether the player has an open data channel
+func (m *Manager) HasOpenChannel(playerID string) bool {
+	peer, exists := m.GetPeer(playerID)
+	return exists && peer != nil && peer.DataChannel != nil &&
+		peer.DataChannel.ReadyState() == webrtc.DataChannelStateOpen
+}
+
 func (m *Manager) BroadcastToGame(player1ID, player2ID string, messageType string, data any) {
 	m.SendMessage(player1ID, messageType, data)
 	m.SendMessage(player2ID, messageType, data)
 }
 
+// SetICEServers replaces the STUN/TURN servers offered to new peer connections
+func (m *Manager) SetICEServers(servers []config.ICEServer) {
+	iceServers := make([]webrtc.ICEServer, 0, len(servers))
+	for _, server := range servers {
+		iceServers = append(iceServers, webrtc.ICEServer{
+			URLs:       server.URLs,
+			Username:   server.Username,
+			Credential: server.Credential,
+		})
+	}
+
+	m.mutex.Lock()
+	m.iceServers = iceServers
+	m.mutex.Unlock()
+}
+
 // getICEConfiguration returns the ICE server configuration with STUN and TURN servers
-// TURN server IP is read from environment variable WEBRTC_TURN_IP
-// Default: turn.li1.nl
+// Servers come from the configuration (see config.ICEServer), which defaults to
+// the host in environment variable WEBRTC_TURN_IP (default: turn.li1.nl)
 func (m *Manager) getICEConfiguration() webrtc.Configuration {
-	turnServerIP := getTurnServerIP()
+	m.mutex.RLock()
+	iceServers := m.iceServers
+	m.mutex.RUnlock()
 
 	return webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{
-			// STUN server
-			{
-				URLs: []string{fmt.Sprintf("stun:%s:3478", turnServerIP)},
-			},
-			// TURN server (non-TLS) with UDP and TCP transports
-			{
-				URLs: []string{
-					fmt.Sprintf("turn:%s:3478?transport=udp", turnServerIP),
-					fmt.Sprintf("turn:%s:3478?transport=tcp", turnServerIP),
-				},
-				Username:   "peaceast",
-				Credential: "endoplazmikretikulum",
-			},
-		},
+		ICEServers:         iceServers,
 		ICETransportPolicy: webrtc.ICETransportPolicyAll,
 	}
 }
-
-// getTurnServerIP gets TURN server IP from environment variable or uses default
-func getTurnServerIP() string {
-	if ip := os.Getenv("WEBRTC_TURN_IP"); ip != "" {
-		return ip
-	}
-	// Default fallback
-	return "turn.li1.nl"
-}
@@ -1,7 +1,7 @@
 import { Component, OnInit, OnDestroy } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { RouterOutlet } from '@angular/router';
-import { GameService } from './services/game.service';
+import { Announcement, GameService } from './services/game.service';
 import { ConnectionStatusComponent } from './components/connection-status/connection-status.component';
 import { Subscription } from 'rxjs';
 
@@ -17,6 +17,7 @@ export class AppComponent implements OnInit, OnDestroy {
   isLoading = false; // Don't show loading on initial page load
   currentStep = 'idle';
   completedSteps: string[] = [];
+  announcement: Announcement | null = null;
   private subscriptions = new Subscription();
   private initialLoadComplete = false;
 
@@ -26,6 +27,12 @@ export class AppComponent implements OnInit, OnDestroy {
     // Mark initial load as complete immediately (no initial loading screen)
     this.initialLoadComplete = true;
     
+    this.subscriptions.add(
+      this.gameService.getAnnouncement().subscribe(announcement => {
+        this.announcement = announcement;
+      })
+    );
+
     // Listen to connection status
     this.subscriptions.add(
       this.gameService.getConnectionStatus().subscribe(status => {
@@ -77,6 +84,10 @@ export class AppComponent implements OnInit, OnDestroy {
     );
   }
 
+  dismissAnnouncement() {
+    this.gameService.dismissAnnouncement();
+  }
+
   ngOnDestroy() {
     this.subscriptions.unsubscribe();
   }
@@ -1,13 +1,14 @@
 import { Component, OnInit, OnDestroy, ViewChild, ElementRef, HostListener } from '@angular/core';
 import { CommonModule } from '@angular/common';
+import { FormsModule } from '@angular/forms';
 import { ActivatedRoute, Router } from '@angular/router';
-import { GameService, GameState, Snake, Position, Food, PlayerStatus } from '../../services/game.service';
+import { GameService, GameState, Snake, Position, Food, PlayerStatus, Emote, EmoteEvent, LoungeChat, MatchStats } from '../../services/game.service';
 import { Subscription } from 'rxjs';
 
 @Component({
   selector: 'app-game',
   standalone: true,
-  imports: [CommonModule],
+  imports: [CommonModule, FormsModule],
   templateUrl: './game.component.html',
   styleUrls: ['./game.component.css']
 })
@@ -22,6 +23,22 @@ export class GameComponent implements OnInit, OnDestroy {
   currentPlayerId: string = '';
   banner: { type: 'info' | 'warning'; message: string } | null = null;
   opponentDisconnected: boolean = false; // Track if opponent disconnected
+  emotes: Emote[] = [];
+  recentEmotes: EmoteEvent[] = []; // Shown for a few seconds after they arrive
+  matchStats: MatchStats | null = null;
+  loungeChat: LoungeChat[] = [];
+  chatDraft: string = '';
+  inviteUsername: string = '';
+  reportCategory: string = '';
+  mutedUsernames: string[] = [];
+  playerTitles: Record<string, string> = {};
+  campaignTargets: Record<number, number> = {};
+  readonly reportCategories = [
+    { id: 'abusive_name', label: 'Abusive name' },
+    { id: 'chat_abuse', label: 'Chat abuse' },
+    { id: 'cheating', label: 'Cheating' },
+    { id: 'afk_griefing', label: 'AFK griefing' }
+  ];
   private canvas!: HTMLCanvasElement;
   private ctx!: CanvasRenderingContext2D;
   private cellSize = 20;
@@ -125,32 +142,13 @@ export class GameComponent implements OnInit, OnDestroy {
             return;
           }
           
-          // Check if game is finished - redirect if accessing finished game URL
-          if (state.status === 'finished' && state.id === this.gameId && !this.isRedirecting) {
+          // Single player games redirect once finished; multiplayer games stay on the game over
+          // screen, which doubles as the post-game lounge until it is left or closed
+          const isSinglePlayer = state.is_single_player || (!state.hot_seat && state.players && state.players.length <= 1);
+          if (state.status === 'finished' && state.id === this.gameId && isSinglePlayer && !this.isRedirecting) {
             // Game is finished, set redirecting flag immediately to prevent multiple redirects
             this.isRedirecting = true;
             
-            // For multiplayer games, automatically redirect to lobby after showing game over
-            const isSinglePlayer = state.is_single_player || (state.players && state.players.length <= 1);
-            if (!isSinglePlayer && !this.isSpectator) {
-              // Wait a moment to show the game over screen, then redirect to lobby
-              setTimeout(() => {
-                // Clear game state to prevent further updates
-                this.gameState = null;
-                // Clear game state from service
-                this.gameService.getCurrentGameState().subscribe(currentState => {
-                  if (currentState?.id === this.gameId) {
-                    // Clear the game state for this specific game
-                    this.gameService.leaveGame(this.gameId);
-                  }
-                }).unsubscribe();
-                // Navigate to lobby
-                this.router.navigate(['/lobby']);
-              }, 3000); // 3 seconds to show game over screen
-              return;
-            }
-            
-            // For single player games, use the existing redirect logic
             // Clear game state to prevent further updates
             this.gameState = null;
             // Clear game state from service immediately to stop receiving updates for this game
@@ -232,6 +230,54 @@ export class GameComponent implements OnInit, OnDestroy {
       })
     );
 
+    this.subscriptions.add(
+      this.gameService.getEmotes().subscribe(emotes => {
+        this.emotes = emotes;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getEmoteEvents().subscribe(emote => {
+        if (emote.game_id !== this.gameId) {
+          return;
+        }
+        this.recentEmotes = [...this.recentEmotes.slice(-2), emote];
+        setTimeout(() => {
+          this.recentEmotes = this.recentEmotes.filter(e => e !== emote);
+        }, 4000);
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getMutedUsernames().subscribe(usernames => {
+        this.mutedUsernames = usernames;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getPlayerTitles().subscribe(titles => {
+        this.playerTitles = titles;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getCampaign().subscribe(campaign => {
+        this.campaignTargets = Object.fromEntries((campaign?.levels || []).map(level => [level.level, level.target_score]));
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getMatchStats().subscribe(stats => {
+        this.matchStats = stats && stats.game_id === this.gameId ? stats : null;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getLoungeChat().subscribe(messages => {
+        this.loungeChat = messages.filter(m => m.game_id === this.gameId);
+      })
+    );
+
     this.subscriptions.add(
       this.gameService.getBanner().subscribe(banner => {
         this.banner = banner;
@@ -323,10 +369,39 @@ export class GameComponent implements OnInit, OnDestroy {
       return;
     }
 
+    // P or Escape pauses and resumes a single player game
+    if ((event.key === 'p' || event.key === 'P' || event.key === 'Escape') && this.canPause()) {
+      event.preventDefault();
+      this.togglePause();
+      return;
+    }
+
+    // R starts a single player game over right away
+    if ((event.key === 'r' || event.key === 'R') && this.canRestart()) {
+      event.preventDefault();
+      this.restartGame();
+      return;
+    }
+
     if (!this.gameState || this.gameState.status !== 'playing') {
       return;
     }
 
+    // Typing in a form, such as the spectator invite, doesn't steer
+    if (event.target instanceof HTMLInputElement) {
+      return;
+    }
+
+    // Hot-seat: WASD steers the host's snake, the arrow keys the guest's
+    if (this.gameState.hot_seat) {
+      const seatDirection = this.getDirectionFromKey(event.key);
+      if (seatDirection) {
+        event.preventDefault();
+        this.gameService.sendPlayerMove(this.gameId, seatDirection, 2);
+        return;
+      }
+    }
+
     let direction = '';
     switch (event.key) {
       case 'ArrowUp':
@@ -500,6 +575,9 @@ export class GameComponent implements OnInit, OnDestroy {
 
     this.drawGrid();
 
+    // Campaign levels have obstacles the snake must avoid
+    (this.gameState.obstacles || []).forEach(obstacle => this.drawObstacle(obstacle));
+
     // Draw food
     if (this.gameState.food && this.gameState.food.position) {
       this.drawFood(this.gameState.food.position);
@@ -547,6 +625,11 @@ export class GameComponent implements OnInit, OnDestroy {
     }
   }
 
+  drawObstacle(position: Position): void {
+    this.ctx.fillStyle = '#5c6b8a';
+    this.ctx.fillRect(position.x * this.cellSize + 1, position.y * this.cellSize + 1, this.cellSize - 2, this.cellSize - 2);
+  }
+
   drawFood(position: Position): void {
     const x = position.x * this.cellSize;
     const y = position.y * this.cellSize;
@@ -573,7 +656,8 @@ export class GameComponent implements OnInit, OnDestroy {
     if (!snake.body || snake.body.length === 0) return;
 
     const previousAlpha = this.ctx.globalAlpha;
-    if (isPlaceholder) {
+    // A practice snake that crashed fades out until it respawns
+    if (isPlaceholder || snake.down) {
       this.ctx.globalAlpha = 0.45;
     }
 
@@ -603,6 +687,10 @@ export class GameComponent implements OnInit, OnDestroy {
   backToLobby(): void {
     // Don't disconnect - just navigate to lobby
     // The lobby component will check if we're already connected
+    // Leaving the post-game lounge is what returns the player to the lobby on the server
+    if (this.gameId && this.gameState?.status === 'finished') {
+      this.gameService.leaveGame(this.gameId);
+    }
     this.router.navigate(['/']);
   }
 
@@ -629,13 +717,107 @@ export class GameComponent implements OnInit, OnDestroy {
     this.router.navigate(['/login']);
   }
 
+  canSendEmotes(): boolean {
+    return !this.isSpectator && !this.isSinglePlayer() && !this.gameState?.hot_seat && this.emotes.length > 0;
+  }
+
+  sendEmote(emoteId: string): void {
+    this.gameService.sendEmote(this.gameId, emoteId);
+  }
+
+  canInviteSpectators(): boolean {
+    return !this.isSpectator && !this.isSinglePlayer() && !!this.gameState && this.gameState.status !== 'finished';
+  }
+
+  inviteSpectator(): void {
+    const username = this.inviteUsername.trim();
+    if (!username) {
+      return;
+    }
+    this.gameService.inviteSpectator(this.gameId, username);
+    this.inviteUsername = '';
+  }
+
+  isOpponentMuted(): boolean {
+    const opponent = this.getOtherPlayer();
+    return !!opponent && this.mutedUsernames.includes(opponent.username.toLowerCase());
+  }
+
+  toggleMuteOpponent(): void {
+    const opponent = this.getOtherPlayer();
+    if (opponent) {
+      this.gameService.setMuted(opponent.username, !this.isOpponentMuted());
+    }
+  }
+
+  reportOpponent(): void {
+    const opponent = this.getOtherPlayer();
+    if (!opponent || !this.reportCategory) {
+      return;
+    }
+    this.gameService.reportPlayer(opponent.username, this.reportCategory, '', this.gameId);
+    this.reportCategory = '';
+  }
+
+  isInLounge(): boolean {
+    return this.gameState?.status === 'finished' && !this.isSinglePlayer();
+  }
+
+  sendLoungeChat(): void {
+    const text = this.chatDraft.trim();
+    if (!text) {
+      return;
+    }
+    this.gameService.sendLoungeChat(this.gameId, text);
+    this.chatDraft = '';
+  }
+
+  formatDuration(ms: number): string {
+    const seconds = Math.round(ms / 1000);
+    return `${Math.floor(seconds / 60)}:${String(seconds % 60).padStart(2, '0')}`;
+  }
+
   isSinglePlayer(): boolean {
     return this.gameState?.is_single_player || false;
   }
 
   shouldShowStatusBanner(): boolean {
     if (!this.gameState) return false;
-    return ['waiting', 'countdown', 'rematch_countdown'].includes(this.gameState.status);
+    return ['waiting', 'countdown', 'rematch_countdown', 'paused'].includes(this.gameState.status);
+  }
+
+  formatTimeLeft(seconds: number): string {
+    return `${Math.floor(seconds / 60)}:${String(seconds % 60).padStart(2, '0')}`;
+  }
+
+  canPause(): boolean {
+    return !this.isSpectator && !!this.gameState?.is_single_player && this.gameState.variant !== 'daily' &&
+      ['playing', 'paused'].includes(this.gameState.status);
+  }
+
+  canRestart(): boolean {
+    return !this.isSpectator && !!this.gameState?.is_single_player &&
+      ['playing', 'paused', 'finished'].includes(this.gameState.status);
+  }
+
+  restartGame(): void {
+    this.gameService.restartGame(this.gameId);
+  }
+
+  // Anything that can be paused can be saved and resumed later, from any device
+  canSuspend(): boolean {
+    return this.canPause();
+  }
+
+  suspendGame(): void {
+    this.gameService.suspendGame(this.gameId);
+    this.gameState = null;
+    this.router.navigate(['/mode-selection']);
+  }
+
+  togglePause(): void {
+    if (!this.gameState) return;
+    this.gameService.pauseGame(this.gameId, this.gameState.status !== 'paused');
   }
 
   getStatusBannerText(): string {
@@ -650,6 +832,9 @@ export class GameComponent implements OnInit, OnDestroy {
     if (this.gameState.status === 'rematch_countdown') {
       return `Rematch starting in ${this.gameState.countdown || 0}`;
     }
+    if (this.gameState.status === 'paused') {
+      return 'Paused - press P to resume';
+    }
     return '';
   }
 
@@ -659,6 +844,15 @@ export class GameComponent implements OnInit, OnDestroy {
     return snake?.score || 0;
   }
 
+  tieBreakLabel(rule: string): string {
+    switch (rule) {
+      case 'tie': return 'calling it a tie';
+      case 'length': return 'the longer snake';
+      case 'sudden_death': return 'sudden death';
+      default: return 'the higher score';
+    }
+  }
+
   getWinnerUsername(): string {
     if (!this.gameState || !this.gameState.winner || this.gameState.winner === 'tie') {
       return '';
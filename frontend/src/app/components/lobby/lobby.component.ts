@@ -2,7 +2,7 @@ import { Component, OnInit, OnDestroy } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { FormsModule } from '@angular/forms';
 import { Router } from '@angular/router';
-import { GameService, Player } from '../../services/game.service';
+import { CircuitStandings, Clan, ClanInvite, ClanMember, ClanScoreboard, GameService, GameState, Party, LeagueStandings, PartyInvite, Player, Profile, RecentOpponent, SpectatorInvite, Tournament, TournamentEntrant, TournamentMatch, TournamentScores } from '../../services/game.service';
 import { Subscription } from 'rxjs';
 
 @Component({
@@ -17,6 +17,33 @@ export class LobbyComponent implements OnInit, OnDestroy {
   isConnected: boolean = false;
   players: Player[] = [];
   gameRequests: any[] = [];
+  spectatorInvites: SpectatorInvite[] = [];
+  mutedUsernames: string[] = [];
+  recentOpponents: RecentOpponent[] = [];
+  party: Party | null = null;
+  partyInvites: PartyInvite[] = [];
+  partyInviteName: string = '';
+  profile: Profile = { rating: 0, titles: [] };
+  clan: Clan | null = null;
+  clanInvites: ClanInvite[] = [];
+  clanScoreboard: ClanScoreboard | null = null;
+  circuit: CircuitStandings | null = null;
+  clanTag: string = '';
+  clanName: string = '';
+  clanInviteName: string = '';
+  tournaments: Tournament[] = [];
+  tournamentName: string = '';
+  tournamentSize: number = 8;
+  tournamentStart: string = '';
+  tournamentRepeat: string = '';
+  tournamentFormat: string = 'elimination';
+  tournamentCheckIn: number = 0; // Minutes to check in to each match, 0 for none
+  leagueStandings: Record<string, LeagueStandings> = {};
+  tournamentScores: Record<string, TournamentScores> = {};
+  openTournamentId: string | null = null;
+  staffNames: Record<'moderator' | 'caster', string> = { moderator: '', caster: '' };
+  castStates: Record<string, GameState> = {};
+  readonly staffRoles: ('moderator' | 'caster')[] = ['moderator', 'caster'];
   pendingRequests: any[] = [];
   currentPlayer: Player | null = null;
   showUsernameEdit: boolean = false;
@@ -68,7 +95,8 @@ export class LobbyComponent implements OnInit, OnDestroy {
           .map(player => ({
             ...player,
             joinedAt: player.joinedAt || (player as any).joined_at,
-            in_game: (player as any).in_game || false
+            in_game: (player as any).in_game || false,
+            bot: player.bot || false
           }))
           .sort((a, b) => {
             const aTime = new Date(a.joinedAt || 0).getTime();
@@ -85,6 +113,94 @@ export class LobbyComponent implements OnInit, OnDestroy {
       })
     );
 
+    this.subscriptions.add(
+      this.gameService.getMutedUsernames().subscribe(usernames => {
+        this.mutedUsernames = usernames;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getProfile().subscribe(profile => {
+        this.profile = profile;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getClan().subscribe(clan => {
+        this.clan = clan;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getClanInvites().subscribe(invites => {
+        this.clanInvites = invites;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getClanScoreboard().subscribe(scoreboard => {
+        this.clanScoreboard = scoreboard;
+      })
+    );
+    this.gameService.requestClanScoreboard();
+
+    this.subscriptions.add(
+      this.gameService.getCircuit().subscribe(circuit => {
+        this.circuit = circuit;
+      })
+    );
+    this.gameService.requestCircuit();
+
+    this.subscriptions.add(
+      this.gameService.getTournaments().subscribe(tournaments => {
+        this.tournaments = tournaments;
+      })
+    );
+    this.gameService.listTournaments();
+
+    this.subscriptions.add(
+      this.gameService.getLeagueStandings().subscribe(standings => {
+        this.leagueStandings = standings;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getTournamentScores().subscribe(scores => {
+        this.tournamentScores = scores;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getCastStates().subscribe(states => {
+        this.castStates = states;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getParty().subscribe(party => {
+        this.party = party;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getPartyInvites().subscribe(invites => {
+        this.partyInvites = invites;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getRecentOpponents().subscribe(opponents => {
+        this.recentOpponents = opponents;
+      })
+    );
+    this.gameService.requestRecentOpponents();
+
+    this.subscriptions.add(
+      this.gameService.getSpectatorInvites().subscribe(invites => {
+        this.spectatorInvites = invites;
+      })
+    );
+
     this.subscriptions.add(
       this.gameService.getPendingRequest().subscribe(requests => {
         this.pendingRequests = requests || [];
@@ -224,6 +340,287 @@ export class LobbyComponent implements OnInit, OnDestroy {
     this.router.navigate(['/game/multiplayer', gameId]);
   }
 
+  isMuted(username: string): boolean {
+    return this.mutedUsernames.includes(username.toLowerCase());
+  }
+
+  toggleMute(username: string): void {
+    this.gameService.setMuted(username, !this.isMuted(username));
+  }
+
+  equipTitle(titleId: string): void {
+    this.gameService.equipTitle(titleId);
+  }
+
+  dismissSpectatorInvite(gameId: string): void {
+    this.gameService.dismissSpectatorInvite(gameId);
+  }
+
+  isPartyLeader(): boolean {
+    return !this.party || this.party.leader_id === this.currentPlayer?.id;
+  }
+
+  inviteToParty(): void {
+    const username = this.partyInviteName.trim();
+    if (username) {
+      this.gameService.inviteToParty(username);
+      this.partyInviteName = '';
+    }
+  }
+
+  answerPartyInvite(partyId: string, accept: boolean): void {
+    this.gameService.answerPartyInvite(partyId, accept);
+  }
+
+  leaveParty(): void {
+    this.gameService.leaveParty();
+  }
+
+  queueParty(): void {
+    this.gameService.queueParty();
+  }
+
+  clanRole(): string {
+    return this.clan?.members.find(member => member.player_id === this.currentPlayer?.id)?.role || '';
+  }
+
+  createClan(): void {
+    const tag = this.clanTag.trim();
+    const name = this.clanName.trim();
+    if (tag && name) {
+      this.gameService.createClan(tag, name);
+      this.clanTag = '';
+      this.clanName = '';
+    }
+  }
+
+  inviteToClan(): void {
+    const username = this.clanInviteName.trim();
+    if (username) {
+      this.gameService.inviteToClan(username);
+      this.clanInviteName = '';
+    }
+  }
+
+  answerClanInvite(tag: string, accept: boolean): void {
+    this.gameService.answerClanInvite(tag, accept);
+  }
+
+  leaveClan(): void {
+    this.gameService.leaveClan();
+  }
+
+  // Leaders manage everyone, officers only members
+  canManageMember(member: ClanMember): boolean {
+    const role = this.clanRole();
+    return member.player_id !== this.currentPlayer?.id &&
+      (role === 'leader' || (role === 'officer' && member.role === 'member'));
+  }
+
+  removeFromClan(member: ClanMember): void {
+    this.gameService.removeFromClan(member.player_id);
+  }
+
+  setClanRole(member: ClanMember, role: string): void {
+    this.gameService.setClanRole(member.player_id, role);
+  }
+
+  refreshClanScoreboard(): void {
+    this.gameService.requestClanScoreboard();
+  }
+
+  refreshCircuit(): void {
+    this.gameService.requestCircuit(this.circuit?.season || '');
+  }
+
+  refreshTournaments(): void {
+    this.gameService.listTournaments();
+  }
+
+  createTournament(): void {
+    const name = this.tournamentName.trim();
+    if (name) {
+      const startsAt = this.tournamentStart ? new Date(this.tournamentStart) : null;
+      const roundCheckIn = this.tournamentFormat === 'league' ? 0 : this.tournamentCheckIn;
+      this.gameService.createTournament(name, this.tournamentSize, startsAt, this.tournamentRepeat, this.tournamentFormat, '', roundCheckIn);
+      this.tournamentName = '';
+      this.tournamentStart = '';
+      this.tournamentRepeat = '';
+    }
+  }
+
+  isRegistered(tournament: Tournament): boolean {
+    return tournament.entrants.some(entrant => entrant.id === this.currentPlayer?.id);
+  }
+
+  // Entrants check in before the start, and to each match with round check-in
+  needsCheckIn(tournament: Tournament): boolean {
+    if (this.gameService.matchAwaitingCheckIn(tournament, this.currentPlayer?.id)) {
+      return true;
+    }
+    return tournament.status === 'check_in' &&
+      tournament.entrants.some(entrant => entrant.id === this.currentPlayer?.id && !entrant.checked_in);
+  }
+
+  checkIn(tournament: Tournament): void {
+    this.gameService.checkIn(tournament.id);
+  }
+
+  tournamentStatus(tournament: Tournament): string {
+    switch (tournament.status) {
+      case 'finished': return '🏆 ' + tournament.champion?.username;
+      case 'canceled': return 'canceled';
+      case 'check_in': return 'check-in';
+      case 'running': return tournament.paused ? '⏸ paused' : 'running';
+      case 'registering':
+        return tournament.starts_at ? 'starts ' + new Date(tournament.starts_at).toLocaleString() : 'registering';
+      default: return tournament.status;
+    }
+  }
+
+  registerForTournament(tournament: Tournament, register: boolean): void {
+    this.gameService.registerForTournament(tournament.id, register);
+  }
+
+  startTournament(tournament: Tournament): void {
+    this.gameService.startTournament(tournament.id);
+  }
+
+  // Organizer and moderators run a tournament: pausing, restarting matches, disqualifying
+  // players and extending check-in
+  moderates(tournament: Tournament): boolean {
+    const playerId = this.currentPlayer?.id;
+    return tournament.organizer_id === playerId || !!tournament.moderators?.some(moderator => moderator.id === playerId);
+  }
+
+  // Organizer, moderators and casters may follow several match games at once
+  casts(tournament: Tournament): boolean {
+    const playerId = this.currentPlayer?.id;
+    return this.moderates(tournament) || !!tournament.casters?.some(caster => caster.id === playerId);
+  }
+
+  addStaff(tournament: Tournament, role: 'moderator' | 'caster'): void {
+    const username = this.staffNames[role].trim();
+    if (username) {
+      this.gameService.setTournamentStaff(tournament.id, role, username, false);
+      this.staffNames[role] = '';
+    }
+  }
+
+  removeStaff(tournament: Tournament, role: 'moderator' | 'caster', username: string): void {
+    this.gameService.setTournamentStaff(tournament.id, role, username, true);
+  }
+
+  toggleCast(gameId: string): void {
+    if (this.castStates[gameId]) {
+      this.gameService.uncastGames([gameId]);
+    } else {
+      this.gameService.castGames([gameId]);
+    }
+  }
+
+  togglePause(tournament: Tournament): void {
+    this.gameService.pauseTournament(tournament.id, !tournament.paused);
+  }
+
+  extendCheckIn(tournament: Tournament): void {
+    this.gameService.extendTournamentCheckIn(tournament.id, 15);
+  }
+
+  canRestart(tournament: Tournament, match: TournamentMatch): boolean {
+    return tournament.status === 'running' && !!match.player1 && !!match.player2 &&
+      (match.result ? !['bye', 'expired', 'disqualified'].includes(match.result) : !!match.game_id);
+  }
+
+  restartMatch(tournament: Tournament, match: TournamentMatch): void {
+    if (confirm(`Restart ${match.player1?.username} vs ${match.player2?.username}? The match is played again from the same seed.`)) {
+      this.gameService.restartTournamentMatch(tournament.id, match.id);
+    }
+  }
+
+  disqualify(tournament: Tournament, entrant: TournamentEntrant): void {
+    if (confirm(`Disqualify ${entrant.username} from ${tournament.name}?`)) {
+      this.gameService.disqualifyFromTournament(tournament.id, entrant.id);
+    }
+  }
+
+  toggleBracket(tournament: Tournament): void {
+    this.openTournamentId = this.openTournamentId === tournament.id ? null : tournament.id;
+    if (this.openTournamentId && tournament.format !== 'elimination') {
+      this.gameService.requestLeagueStandings(tournament.id);
+    }
+  }
+
+  // Opens or closes the live view of a tournament's games; it keeps streaming while the
+  // player spectates from it
+  toggleLive(tournament: Tournament): void {
+    if (this.tournamentScores[tournament.id]) {
+      this.gameService.unwatchTournament(tournament.id);
+    } else {
+      this.gameService.watchTournament(tournament.id);
+    }
+  }
+
+  roundName(tournament: Tournament, index: number): string {
+    if (tournament.format === 'league') {
+      return `Round ${index + 1}`;
+    }
+    if (tournament.format === 'swiss') {
+      return `Round ${index + 1} of ${tournament.swiss_rounds}`;
+    }
+    const remaining = tournament.rounds.length - index;
+    return remaining === 1 ? 'Final' : remaining === 2 ? 'Semifinals' : `Round ${index + 1}`;
+  }
+
+  // Entrants of a finished tournament by placing, with how far they finished above or below
+  // their seed
+  results(tournament: Tournament): { username: string; finish: number; seed: number; change: string }[] {
+    return tournament.entrants
+      .filter(entrant => entrant.finish)
+      .sort((a, b) => a.finish! - b.finish! || a.seed! - b.seed!)
+      .map(entrant => {
+        const change = entrant.seed! - entrant.finish!;
+        return {
+          username: entrant.username,
+          finish: entrant.finish!,
+          seed: entrant.seed!,
+          change: change > 0 ? `▲${change}` : change < 0 ? `▼${-change}` : ''
+        };
+      });
+  }
+
+  matchResult(match: TournamentMatch): string {
+    switch (match.result) {
+      case 'bye': return 'bye';
+      case 'forfeit': return 'forfeit';
+      case 'no_show': return 'no-show';
+      case 'draw': return 'draw';
+      case 'expired': return 'not played';
+      case 'disqualified': return 'disqualified';
+      default:
+        if (match.game_id && !match.winner) {
+          return 'playing';
+        }
+        return !match.result && match.check_ins?.length ? `${match.check_ins.length}/2 checked in` : '';
+    }
+  }
+
+  refreshRecentOpponents(): void {
+    this.gameService.requestRecentOpponents();
+  }
+
+  // Opponents can only be challenged while they wait in the lobby
+  canChallenge(opponent: RecentOpponent): boolean {
+    return opponent.in_lobby && !opponent.in_game && !!opponent.player_id &&
+      !this.hasPendingRequestTo(opponent.player_id) && !this.hasGameRequestFrom(opponent.player_id);
+  }
+
+  opponentStatus(opponent: RecentOpponent): string {
+    if (!opponent.online) return 'Offline';
+    if (opponent.in_game) return 'In Game';
+    return opponent.in_lobby ? 'In Lobby' : 'Online';
+  }
+
   private acquireSessionLock(): boolean {
     const existing = this.getLockValue();
     if (existing && existing.sessionId !== this.sessionId) {
@@ -1,7 +1,7 @@
 import { Component, OnInit, OnDestroy } from '@angular/core';
 import { CommonModule } from '@angular/common';
 import { Router } from '@angular/router';
-import { GameService } from '../../services/game.service';
+import { GameService, Campaign, Leaderboard, SavedGame, SinglePlayerStats } from '../../services/game.service';
 import { Subscription } from 'rxjs';
 
 @Component({
@@ -14,6 +14,15 @@ import { Subscription } from 'rxjs';
 export class ModeSelectionComponent implements OnInit, OnDestroy {
   private subscriptions = new Subscription();
   isConnected: boolean = false;
+  difficulties = ['easy', 'normal', 'hard'];
+  difficulty = localStorage.getItem('snake_difficulty') || 'normal';
+  variants = [{ id: 'endless', label: 'Endless' }, { id: 'score_attack', label: '2-minute score attack' },
+    { id: 'daily', label: 'Daily run' }];
+  variant = localStorage.getItem('snake_variant') || 'endless';
+  campaign: Campaign | null = null;
+  leaderboard: Leaderboard | null = null;
+  savedGame: SavedGame | null = null;
+  singlePlayerStats: SinglePlayerStats | null = null;
 
   constructor(
     private gameService: GameService,
@@ -38,6 +47,9 @@ export class ModeSelectionComponent implements OnInit, OnDestroy {
       this.gameService.getCurrentPlayer().subscribe(player => {
         if (player) {
           this.isConnected = true;
+          this.gameService.requestCampaign();
+          this.gameService.requestLeaderboard(this.variant, this.difficulty);
+          this.gameService.requestSavedGame();
         } else {
           // Player is null - check connection status after a delay
           setTimeout(() => {
@@ -60,6 +72,30 @@ export class ModeSelectionComponent implements OnInit, OnDestroy {
       })
     );
 
+    this.subscriptions.add(
+      this.gameService.getCampaign().subscribe(campaign => {
+        this.campaign = campaign;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getLeaderboard().subscribe(leaderboard => {
+        this.leaderboard = leaderboard;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getProfile().subscribe(profile => {
+        this.singlePlayerStats = profile.single_player || null;
+      })
+    );
+
+    this.subscriptions.add(
+      this.gameService.getSavedGame().subscribe(savedGame => {
+        this.savedGame = savedGame;
+      })
+    );
+
     // Listen for lobby players (multiplayer mode selected)
     this.subscriptions.add(
       this.gameService.getLobbyPlayers().subscribe(players => {
@@ -114,7 +150,51 @@ export class ModeSelectionComponent implements OnInit, OnDestroy {
   }
 
   selectSinglePlayer(): void {
-    this.gameService.startSinglePlayer();
+    this.gameService.startSinglePlayer(this.difficulty, this.variant);
+  }
+
+  // The first level and the one after each cleared level are playable
+  isLevelUnlocked(level: number): boolean {
+    return level <= (this.campaign?.cleared || 0) + 1;
+  }
+
+  selectCampaignLevel(level: number): void {
+    this.gameService.startCampaignLevel(level);
+  }
+
+  selectDifficulty(difficulty: string): void {
+    this.difficulty = difficulty;
+    localStorage.setItem('snake_difficulty', difficulty);
+    this.gameService.requestLeaderboard(this.variant, this.difficulty);
+  }
+
+  // Each variant has its own leaderboard at every difficulty
+  selectVariant(variant: string): void {
+    this.variant = variant;
+    localStorage.setItem('snake_variant', variant);
+    this.gameService.requestLeaderboard(this.variant, this.difficulty);
+  }
+
+  selectHotSeat(): void {
+    this.gameService.startHotSeat();
+  }
+
+  selectCoop(): void {
+    this.gameService.startCoop();
+  }
+
+  selectPractice(): void {
+    this.gameService.startPractice();
+  }
+
+  // Longest survival as minutes and seconds
+  formatSurvival(ms: number): string {
+    const seconds = Math.floor(ms / 1000);
+    return `${Math.floor(seconds / 60)}:${String(seconds % 60).padStart(2, '0')}`;
+  }
+
+  resumeSavedGame(): void {
+    this.gameService.resumeGame();
   }
 
   selectMultiplayer(): void {
@@ -1,52 +1,22 @@
 import { Injectable } from '@angular/core';
-import { BehaviorSubject, Observable } from 'rxjs';
+import { BehaviorSubject, Observable, Subject } from 'rxjs';
 import { WebSocketService } from './websocket.service';
 import { WebRTCService } from './webrtc.service';
 import { Router } from '@angular/router';
+import { Announcement, Emote, EmoteEvent, GameState as ProtocolGameState, LobbyPlayer, LoungeChat, MatchStats, Party, PartyInvite, PlayerStatus, Profile, RecentOpponent, SpectatorInvite, Clan, ClanInvite, ClanScoreboard, CircuitStandings, LeagueStandings, Tournament, TournamentMatch, TournamentScores, CastUpdate, Campaign, Leaderboard, SavedGame } from './protocol';
 
-export interface Player {
-  id: string;
-  username: string;
-  ready: boolean;
-  joinedAt?: string;
-  in_game?: boolean; // True if player is currently in an active game
-}
+// Wire types are generated from the backend's Go structs into protocol.ts
+export type { Snake, Food, Position, PlayerStatus, Announcement, Campaign, CampaignLevel, HighScore, Leaderboard, SavedGame, SinglePlayerStats, CircuitStanding, CircuitStandings, Clan, ClanInvite, ClanMember, ClanScoreboard, EarnedTitle, Emote, EmoteEvent, LoungeChat, MatchStats, Party, PartyInvite, Profile, RecentOpponent, SpectatorInvite, Title, Tournament, TournamentEntrant, TournamentMatch, LeagueStanding, LeagueStandings, TournamentGame, TournamentScore, TournamentScores } from './protocol';
 
-export interface PlayerStatus {
-  id: string;
-  username: string;
-  ready: boolean;
+export interface Player extends LobbyPlayer {
+  joinedAt?: string;
 }
 
-export interface GameState {
-  id: string;
-  snakes: Snake[];
-  food: Food;
+export interface GameState extends Omit<ProtocolGameState, 'status' | 'countdown'> {
   status: 'waiting' | 'countdown' | 'playing' | 'finished' | 'rematch_countdown';
   countdown?: number;
-  winner?: string;
-  players?: PlayerStatus[];
   rematchRequesterId?: string;
   rematchRequesterName?: string;
-  is_single_player?: boolean;
-}
-
-export interface Snake {
-  id: string;
-  body: Position[];
-  direction: string;
-  color: string;
-  score: number;
-  username?: string;
-}
-
-export interface Food {
-  position: Position;
-}
-
-export interface Position {
-  x: number;
-  y: number;
 }
 
 @Injectable({
@@ -60,6 +30,38 @@ export class GameService {
   private pendingRequest$ = new BehaviorSubject<any[]>([]);
   private activeGames$ = new BehaviorSubject<any[]>([]);
   private isSpectator$ = new BehaviorSubject<boolean>(false);
+  private emotes$ = new BehaviorSubject<Emote[]>([]);
+  private emote$ = new Subject<EmoteEvent>();
+  private matchStats$ = new BehaviorSubject<MatchStats | null>(null);
+  private loungeChat$ = new BehaviorSubject<LoungeChat[]>([]);
+  private spectatorInvites$ = new BehaviorSubject<SpectatorInvite[]>([]);
+  private muted$ = new BehaviorSubject<string[]>([]); // Lowercased usernames
+  private recentOpponents$ = new BehaviorSubject<RecentOpponent[]>([]);
+  private announcement$ = new BehaviorSubject<Announcement | null>(null);
+  private party$ = new BehaviorSubject<Party | null>(null);
+  private partyInvites$ = new BehaviorSubject<PartyInvite[]>([]);
+  private profile$ = new BehaviorSubject<Profile>({ rating: 0, titles: [] });
+  private clan$ = new BehaviorSubject<Clan | null>(null);
+  private clanInvites$ = new BehaviorSubject<ClanInvite[]>([]);
+  private clanScoreboard$ = new BehaviorSubject<ClanScoreboard | null>(null);
+  private circuit$ = new BehaviorSubject<CircuitStandings | null>(null);
+  private campaign$ = new BehaviorSubject<Campaign | null>(null);
+  private leaderboard$ = new BehaviorSubject<Leaderboard | null>(null);
+  private savedGame$ = new BehaviorSubject<SavedGame | null>(null);
+  private tournaments$ = new BehaviorSubject<Tournament[]>([]);
+  private leagueStandings$ = new BehaviorSubject<Record<string, LeagueStandings>>({});
+  private tournamentScores$ = new BehaviorSubject<Record<string, TournamentScores>>({});
+  private watchedTournaments = new Set<string>();
+  // The server turns a snake at most once per tick, so turns pressed between two updates are
+  // held and sent one per update; keyed by snake slot, 0 for the player's own snake
+  private heldMoves = new Map<number, { gameId: string; directions: string[] }>();
+  private movedSlots = new Set<number>();
+  // Under the latency_compensation feature, moves carry the tick they were pressed on, and the
+  // server applies the ones that arrive a tick late to that tick
+  private latencyCompensation = false;
+  private castStates$ = new BehaviorSubject<Record<string, GameState>>({}); // Game ID -> latest state of a cast game
+  private playerTitles$ = new BehaviorSubject<Record<string, string>>({}); // Player ID -> title name in the current round
+  private announcementTimer?: ReturnType<typeof setTimeout>;
   private banner$ = new BehaviorSubject<{ type: 'info' | 'warning'; message: string } | null>(null);
   private connectionError$ = new BehaviorSubject<string | null>(null);
   private connectionStatus$ = new BehaviorSubject<{ step: string; completed: boolean }>({ step: 'idle', completed: false });
@@ -105,6 +107,7 @@ export class GameService {
           console.log('Received game_update from P2P:', message.data?.status, 'gameId:', message.data?.id);
           if (message.data) {
             this.currentGameState$.next(message.data);
+            this.releaseHeldMoves();
           }
           break;
         case 'game_start':
@@ -203,6 +206,12 @@ export class GameService {
           }
           
           this.connectionStatus$.next({ step: 'connected', completed: true });
+          this.emotes$.next(message.emotes || []);
+          this.latencyCompensation = !!message.features?.latency_compensation;
+          this.muted$.next(message.muted || []);
+          this.showAnnouncement(message.announcement || null);
+          this.profile$.next(message.profile || { rating: 0, titles: [] });
+          this.clan$.next(message.clan || null);
           if (message.player) {
             this.currentPlayer$.next(message.player);
             this.wsService.setPlayerId(message.player.id);
@@ -245,6 +254,159 @@ export class GameService {
           this.connectionStatus$.next({ step: 'ready', completed: true });
           this.activeGames$.next(message.games || []);
           break;
+        case 'spectator_invite':
+          // Keep only the latest invite per game
+          this.spectatorInvites$.next([
+            ...this.spectatorInvites$.value.filter(invite => invite.game_id !== message.game_id),
+            message
+          ]);
+          this.showInfoBanner(`${message.from_username} invited you to watch their game.`);
+          break;
+        case 'spectator_invite_sent':
+          this.showInfoBanner(`Invited ${message.to_username} to watch.`);
+          break;
+        case 'muted_players':
+          this.muted$.next(message.usernames || []);
+          break;
+        case 'announcement':
+          this.showAnnouncement(message);
+          break;
+        case 'announcement_cleared':
+          if (this.announcement$.value?.id === message.id) {
+            this.showAnnouncement(null);
+          }
+          break;
+        case 'kicked':
+          // Don't reconnect; the token is refused until the kick's cooldown is over, or the ban lifted
+          this.wsService.disconnect();
+          this.currentGameState$.next(null);
+          this.connectionError$.next(message.reason ? `${message.message} (${message.reason})` : message.message);
+          this.router.navigate(['/login']);
+          break;
+        case 'party_invite':
+          this.partyInvites$.next([
+            ...this.partyInvites$.value.filter(invite => invite.party_id !== message.party_id),
+            message
+          ]);
+          this.showInfoBanner(`${message.from_username} invited you to their party.`);
+          break;
+        case 'party_update':
+          this.party$.next(message);
+          this.partyInvites$.next(this.partyInvites$.value.filter(invite => invite.party_id !== message.party_id));
+          break;
+        case 'party_left':
+          this.party$.next(null);
+          if (message.reason === 'disbanded') {
+            this.showInfoBanner('Your party was disbanded.');
+          }
+          break;
+        case 'party_spectate':
+          // Follow the party member's game; the spectator update with its state comes next
+          this.isSpectator$.next(true);
+          this.router.navigate(['/game/multiplayer', message.game_id]);
+          break;
+        case 'profile':
+          this.profile$.next({
+            rating: message.rating, title: message.title, titles: message.titles || [], single_player: message.single_player
+          });
+          break;
+        case 'single_player_stats':
+          this.profile$.next({ ...this.profile$.value, single_player: message.stats });
+          break;
+        case 'title_earned':
+          this.profile$.next({
+            ...this.profile$.value,
+            titles: [...this.profile$.value.titles, { ...message.title, earned_at: new Date().toISOString() }]
+          });
+          this.showInfoBanner(`You earned the title ${message.title.name}!`);
+          break;
+        case 'clan_invite':
+          this.clanInvites$.next([
+            ...this.clanInvites$.value.filter(invite => invite.tag !== message.tag),
+            message
+          ]);
+          this.showInfoBanner(`${message.from_username} invited you to join [${message.tag}] ${message.name}.`);
+          break;
+        case 'clan_update':
+          this.clan$.next(message.clan);
+          this.clanInvites$.next([]);
+          break;
+        case 'clan_left':
+          this.clan$.next(null);
+          if (message.reason === 'removed') {
+            this.showInfoBanner(`You were removed from [${message.tag}].`);
+          }
+          break;
+        case 'clan_scoreboard':
+          this.clanScoreboard$.next(message);
+          break;
+        case 'circuit_standings':
+          this.circuit$.next(message);
+          break;
+        case 'circuit_points':
+          this.showInfoBanner(`+${message.points} circuit points for finishing #${message.finish} in ${message.tournament_name} (${message.total} this season)`);
+          this.requestCircuit();
+          break;
+        case 'campaign': {
+          const campaign = message as Campaign;
+          this.campaign$.next(campaign);
+          if (campaign.level_cleared) {
+            const next = campaign.levels.find(level => level.level === campaign.level_cleared! + 1);
+            this.showInfoBanner(next
+              ? `Level ${campaign.level_cleared} cleared! ${next.name} is unlocked`
+              : 'You cleared the whole campaign!');
+          }
+          break;
+        }
+        case 'leaderboard':
+          this.leaderboard$.next(message as Leaderboard);
+          break;
+        case 'saved_game':
+          this.savedGame$.next(message.saved_game || null);
+          break;
+        case 'tournaments_list':
+          this.tournaments$.next(message.tournaments || []);
+          break;
+        case 'tournament_update':
+          this.updateTournament(message.tournament);
+          break;
+        case 'league_standings':
+          this.leagueStandings$.next({ ...this.leagueStandings$.value, [message.tournament_id]: message });
+          break;
+        case 'tournament_scores':
+          // Scores sent before an unwatch reached the server are dropped
+          if (this.watchedTournaments.has(message.tournament_id)) {
+            this.tournamentScores$.next({ ...this.tournamentScores$.value, [message.tournament_id]: message });
+          }
+          break;
+        case 'casting': {
+          // Drop the states of games the player no longer casts
+          const casting = new Set<string>(message.game_ids || []);
+          this.castStates$.next(Object.fromEntries(
+            Object.entries(this.castStates$.value).filter(([gameId]) => casting.has(gameId))
+          ));
+          break;
+        }
+        case 'cast_update': {
+          const update = message as CastUpdate;
+          if (update.event === 'game_update' || update.event === 'game_over') {
+            this.castStates$.next({ ...this.castStates$.value, [update.game_id]: update.data as GameState });
+          }
+          break;
+        }
+        case 'tournament_featured':
+          if (message.spectating) {
+            // The featured game being watched ended: follow the tournament to its next one
+            this.isSpectator$.next(true);
+            this.router.navigate(['/game/multiplayer', message.game_id]);
+          }
+          break;
+        case 'recent_opponents':
+          this.recentOpponents$.next(message.opponents || []);
+          break;
+        case 'report_received':
+          this.showInfoBanner(`Thanks, your report about ${message.username} was sent to the moderators.`);
+          break;
         case 'spectator_update':
           if (message.data) {
             this.currentGameState$.next(message.data);
@@ -318,7 +480,14 @@ export class GameService {
           break;
         case 'game_request_cancel':
           // Remove cancelled request
-          if (message.from_player) {
+          if (message.game_id && message.message) {
+            // The server ended a tournament match game: a no-show, a restart or a disqualification
+            this.showInfoBanner(message.message, 'warning');
+            if (this.currentGameState$.value?.id === message.game_id) {
+              this.currentGameState$.next(null);
+              this.router.navigate(['/lobby']);
+            }
+          } else if (message.from_player) {
             // Someone cancelled their request to us
             const requestsAfterCancel = (this.gameRequest$.value || []).filter(
               (req: any) => req.from_player?.id !== message.from_player.id
@@ -347,6 +516,7 @@ export class GameService {
           console.log('Received game_update:', message.data?.status, 'gameId:', message.data?.id, 'isSinglePlayer:', isSinglePlayer);
           const previousState = this.currentGameState$.value;
           this.currentGameState$.next(message.data);
+          this.releaseHeldMoves();
           
           // Handle navigation for game updates
           if (message.data?.id) {
@@ -364,6 +534,10 @@ export class GameService {
           }
           break;
         case 'game_start':
+          // A new round closes the lounge of the previous one
+          this.matchStats$.next(null);
+          this.loungeChat$.next([]);
+          this.playerTitles$.next(message.titles || {});
           console.log('Received game_start:', message.data?.status, 'gameId:', message.data?.id, 'snakes:', message.data?.snakes?.length);
           this.currentGameState$.next({
             ...(message.data || {}),
@@ -399,17 +573,35 @@ export class GameService {
             rematchRequesterName: undefined
           });
           
-          // For multiplayer games, automatically redirect to lobby after a short delay
-          const isMultiplayerGame = !gameOverData.is_single_player && gameOverData.players && gameOverData.players.length > 1;
-          if (isMultiplayerGame) {
-            // Wait a moment to show the game over screen, then redirect to lobby
-            setTimeout(() => {
-              // Clear game state
-              this.currentGameState$.next(null);
-              // Navigate to lobby
-              this.router.navigate(['/lobby']);
-            }, 3000); // 3 seconds to show game over screen
-          }
+          // Multiplayer players stay on the game over screen, the post-game lounge, until they
+          // leave or the server closes it
+          break;
+        case 'match_stats':
+          this.matchStats$.next(message.data);
+          break;
+        case 'lounge_chat':
+          this.loungeChat$.next([...this.loungeChat$.value.slice(-49), message]);
+          break;
+        case 'lounge_closed':
+          this.matchStats$.next(null);
+          this.loungeChat$.next([]);
+          this.currentGameState$.next(null);
+          this.showInfoBanner('The post-game lounge has closed. Back to the lobby!');
+          this.router.navigate(['/lobby']);
+          break;
+        case 'game_force_ended':
+          // A tie or win already showed its game_over; the game is gone either way
+          this.matchStats$.next(null);
+          this.loungeChat$.next([]);
+          this.currentGameState$.next(null);
+          this.showInfoBanner(message.reason ? `${message.message} (${message.reason})` : message.message, 'warning');
+          this.router.navigate(['/lobby']);
+          break;
+        case 'player_reconnecting':
+          this.showInfoBanner(`${message.player} lost connection. Waiting ${message.grace_seconds}s for them to return...`, 'warning');
+          break;
+        case 'player_reconnected':
+          this.showInfoBanner(`${message.player} is back.`);
           break;
         case 'player_disconnected':
           // Player disconnected - update game state to show opponent disconnected
@@ -433,6 +625,9 @@ export class GameService {
             }, 2000);
           }
           break;
+        case 'emote':
+          this.emote$.next(message);
+          break;
         case 'rematch_request':
           this.currentGameState$.next({
             ...this.currentGameState$.value,
@@ -469,6 +664,9 @@ export class GameService {
             this.showInfoBanner(message.message || 'Opponent has left the game. Returning to lobby...', 'warning');
             this.currentGameState$.next(null); // Clear game state
             setTimeout(() => this.router.navigate(['/lobby']), 2500);
+          } else if (message.code === 'KICKED' || message.code === 'BANNED') {
+            this.connectionError$.next(message.message);
+            this.wsService.disconnect();
           } else if (message.code === 'USERNAME_EXISTS') {
             this.connectionError$.next('Username already in use. Please choose another name.');
             this.wsService.disconnect();
@@ -572,8 +770,25 @@ export class GameService {
     this.wsService.send({ type: 'join_lobby' });
   }
 
-  startSinglePlayer(): void {
-    this.wsService.send({ type: 'start_single_player' });
+  // Difficulty sets the starting speed and how quickly it rises as the snake eats; the
+  // variant is endless, or a two-minute score attack
+  startSinglePlayer(difficulty?: string, variant?: string): void {
+    this.wsService.send({ type: 'start_single_player', difficulty, variant });
+  }
+
+  // Local two-player game: both snakes are steered from this connection
+  startHotSeat(): void {
+    this.wsService.send({ type: 'start_hot_seat' });
+  }
+
+  // Couch co-op: a hot-seat game whose two snakes share a team score and lose together
+  startCoop(): void {
+    this.wsService.send({ type: 'start_single_player', coop: true });
+  }
+
+  // Practice against a bot the server steers; crashing respawns instead of ending the game
+  startPractice(): void {
+    this.wsService.send({ type: 'start_practice' });
   }
 
   requestGameState(gameId: string): void {
@@ -637,20 +852,81 @@ export class GameService {
     });
   }
 
-  sendPlayerMove(gameId: string, direction: string): void {
+  // Only single player games can be paused; there is no opponent to agree
+  pauseGame(gameId: string, paused: boolean): void {
+    this.wsService.send({ type: 'pause_game', game_id: gameId, paused });
+  }
+
+  // Starts a single player game over in place, without a countdown
+  restartGame(gameId: string): void {
+    this.wsService.send({ type: 'restart_game', game_id: gameId });
+  }
+
+  // Saves a single player game being played to the account and leaves it; it can be
+  // resumed later from any device
+  suspendGame(gameId: string): void {
+    this.wsService.send({ type: 'suspend_game', game_id: gameId });
+    this.currentGameState$.next(null);
+  }
+
+  sendPlayerMove(gameId: string, direction: string, snakeSlot?: number): void {
+    const slot = snakeSlot ?? 0;
+    if (this.movedSlots.has(slot)) {
+      const held = this.heldMoves.get(slot) ?? { gameId, directions: [] };
+      if (held.directions.length < 2 && held.directions[held.directions.length - 1] !== direction) {
+        held.directions.push(direction);
+      }
+      this.heldMoves.set(slot, held);
+      return;
+    }
+    this.movedSlots.add(slot);
+    this.transmitMove(gameId, direction, snakeSlot);
+  }
+
+  // Sends the next held turn of each snake once a game update shows the last one was applied
+  private releaseHeldMoves(): void {
+    this.movedSlots.clear();
+    for (const [slot, held] of this.heldMoves) {
+      const direction = held.directions.shift();
+      if (direction) {
+        this.movedSlots.add(slot);
+        this.transmitMove(held.gameId, direction, slot || undefined);
+      }
+      if (held.directions.length === 0) {
+        this.heldMoves.delete(slot);
+      }
+    }
+  }
+
+  private transmitMove(gameId: string, direction: string, snakeSlot?: number): void {
+    const state = this.currentGameState$.value;
+    const stamp = this.latencyCompensation && state?.id === gameId ? { tick: state.tick } : {};
+    // Hot-seat games have no peer; snake slot 2 steers the guest snake
+    if (snakeSlot) {
+      this.wsService.send({
+        type: 'player_move',
+        game_id: gameId,
+        direction: direction,
+        snake_slot: snakeSlot,
+        ...stamp
+      });
+      return;
+    }
     // Try sending via peer-to-peer first (low latency)
     if (this.webrtcService.isPeerConnected()) {
       this.webrtcService.sendToPeer({
         type: 'player_move',
         game_id: gameId,
-        direction: direction
+        direction: direction,
+        ...stamp
       });
     } else {
       // Fallback to WebSocket (always available)
       this.wsService.send({
         type: 'player_move',
         game_id: gameId,
-        direction: direction
+        direction: direction,
+        ...stamp
       });
     }
   }
@@ -683,6 +959,42 @@ export class GameService {
     return this.isSpectator$.asObservable();
   }
 
+  // Quick-chat emotes the server accepts, in display order
+  getEmotes(): Observable<Emote[]> {
+    return this.emotes$.asObservable();
+  }
+
+  // Emotes sent by players of the current game, including our own
+  getEmoteEvents(): Observable<EmoteEvent> {
+    return this.emote$.asObservable();
+  }
+
+  // Summary of the last finished round, null while a round is being played
+  getMatchStats(): Observable<MatchStats | null> {
+    return this.matchStats$.asObservable();
+  }
+
+  // Chat of the current post-game lounge
+  getLoungeChat(): Observable<LoungeChat[]> {
+    return this.loungeChat$.asObservable();
+  }
+
+  sendLoungeChat(gameId: string, text: string): void {
+    this.wsService.send({
+      type: 'lounge_chat',
+      game_id: gameId,
+      text: text
+    });
+  }
+
+  sendEmote(gameId: string, emoteId: string): void {
+    this.wsService.send({
+      type: 'emote',
+      game_id: gameId,
+      emote_id: emoteId
+    });
+  }
+
   getBanner(): Observable<{ type: 'info' | 'warning'; message: string } | null> {
     return this.banner$.asObservable();
   }
@@ -716,7 +1028,346 @@ export class GameService {
     this.wsService.send({ type: 'list_games' });
   }
 
+  // Invites to watch games, received from their players
+  getSpectatorInvites(): Observable<SpectatorInvite[]> {
+    return this.spectatorInvites$.asObservable();
+  }
+
+  dismissSpectatorInvite(gameId: string): void {
+    this.spectatorInvites$.next(this.spectatorInvites$.value.filter(invite => invite.game_id !== gameId));
+  }
+
+  inviteSpectator(gameId: string, username: string): void {
+    this.wsService.send({
+      type: 'invite_spectator',
+      game_id: gameId,
+      username: username
+    });
+  }
+
+  // Usernames whose chat, emotes and invites the server hides from this player
+  getMutedUsernames(): Observable<string[]> {
+    return this.muted$.asObservable();
+  }
+
+  setMuted(username: string, muted: boolean): void {
+    this.wsService.send({
+      type: muted ? 'mute_player' : 'unmute_player',
+      username: username
+    });
+  }
+
+  // Server-wide banner from the operators, hidden when it expires or is cleared
+  getAnnouncement(): Observable<Announcement | null> {
+    return this.announcement$.asObservable();
+  }
+
+  dismissAnnouncement(): void {
+    this.showAnnouncement(null);
+  }
+
+  private showAnnouncement(announcement: Announcement | null): void {
+    clearTimeout(this.announcementTimer);
+    if (announcement?.expires_at) {
+      const remaining = new Date(announcement.expires_at).getTime() - Date.now();
+      if (remaining <= 0) {
+        announcement = null;
+      } else {
+        this.announcementTimer = setTimeout(() => this.announcement$.next(null), remaining);
+      }
+    }
+    this.announcement$.next(announcement);
+  }
+
+  // The party this player is in, and invites to join others
+  getParty(): Observable<Party | null> {
+    return this.party$.asObservable();
+  }
+
+  getPartyInvites(): Observable<PartyInvite[]> {
+    return this.partyInvites$.asObservable();
+  }
+
+  inviteToParty(username: string): void {
+    this.wsService.send({ type: 'party_invite', username: username });
+  }
+
+  answerPartyInvite(partyId: string, accept: boolean): void {
+    this.partyInvites$.next(this.partyInvites$.value.filter(invite => invite.party_id !== partyId));
+    this.wsService.send({ type: accept ? 'party_accept' : 'party_decline', party_id: partyId });
+  }
+
+  leaveParty(): void {
+    this.wsService.send({ type: 'party_leave' });
+  }
+
+  queueParty(): void {
+    this.wsService.send({ type: 'party_queue' });
+  }
+
+  // Titles this player earned and the one shown next to their username
+  getProfile(): Observable<Profile> {
+    return this.profile$.asObservable();
+  }
+
+  equipTitle(titleId: string): void {
+    this.wsService.send({ type: 'update_profile', title: titleId });
+  }
+
+  // Titles the players of the current round show next to their usernames
+  getPlayerTitles(): Observable<Record<string, string>> {
+    return this.playerTitles$.asObservable();
+  }
+
+  // The clan this player is in, invites to join others and the clan-vs-clan scoreboard
+  getClan(): Observable<Clan | null> {
+    return this.clan$.asObservable();
+  }
+
+  getClanInvites(): Observable<ClanInvite[]> {
+    return this.clanInvites$.asObservable();
+  }
+
+  getClanScoreboard(): Observable<ClanScoreboard | null> {
+    return this.clanScoreboard$.asObservable();
+  }
+
+  createClan(tag: string, name: string): void {
+    this.wsService.send({ type: 'clan_create', tag: tag, name: name });
+  }
+
+  inviteToClan(username: string): void {
+    this.wsService.send({ type: 'clan_invite', username: username });
+  }
+
+  answerClanInvite(tag: string, accept: boolean): void {
+    this.clanInvites$.next(this.clanInvites$.value.filter(invite => invite.tag !== tag));
+    this.wsService.send({ type: accept ? 'clan_accept' : 'clan_decline', tag: tag });
+  }
+
+  leaveClan(): void {
+    this.wsService.send({ type: 'clan_leave' });
+  }
+
+  removeFromClan(playerId: string): void {
+    this.wsService.send({ type: 'clan_remove', target_id: playerId });
+  }
+
+  setClanRole(playerId: string, role: string): void {
+    this.wsService.send({ type: 'clan_set_role', target_id: playerId, role: role });
+  }
+
+  requestClanScoreboard(): void {
+    this.wsService.send({ type: 'get_clan_scoreboard' });
+  }
+
+  // Tournament circuit standings of the season asked for with requestCircuit
+  getCircuit(): Observable<CircuitStandings | null> {
+    return this.circuit$.asObservable();
+  }
+
+  // An empty season asks for the current one; seasons are months such as '2026-01'
+  requestCircuit(season: string = ''): void {
+    this.wsService.send({ type: 'get_circuit', season: season });
+  }
+
+  // Campaign levels and how far the player got, asked for with requestCampaign
+  getCampaign(): Observable<Campaign | null> {
+    return this.campaign$.asObservable();
+  }
+
+  requestCampaign(): void {
+    this.wsService.send({ type: 'get_campaign' });
+  }
+
+  // Levels unlock one at a time: the first, and each one after a cleared level
+  startCampaignLevel(level: number): void {
+    this.wsService.send({ type: 'start_campaign_level', level });
+  }
+
+  // Best scores of the leaderboard last asked for with requestLeaderboard; a finished game
+  // that set a personal best sends its leaderboard too
+  getLeaderboard(): Observable<Leaderboard | null> {
+    return this.leaderboard$.asObservable();
+  }
+
+  requestLeaderboard(variant: string, difficulty: string): void {
+    this.wsService.send({ type: 'get_leaderboard', variant, difficulty });
+  }
+
+  // The single player game the player suspended, null when they have none
+  getSavedGame(): Observable<SavedGame | null> {
+    return this.savedGame$.asObservable();
+  }
+
+  requestSavedGame(): void {
+    this.wsService.send({ type: 'get_saved_game' });
+  }
+
+  // A saved game is used up once resumed
+  resumeGame(): void {
+    this.savedGame$.next(null);
+    this.wsService.send({ type: 'resume_game' });
+  }
+
+  // Tournaments open for registration, running or recently finished, newest first
+  getTournaments(): Observable<Tournament[]> {
+    return this.tournaments$.asObservable();
+  }
+
+  listTournaments(): void {
+    this.wsService.send({ type: 'list_tournaments' });
+  }
+
+  // A tournament with a start time starts on its own; repeat is 'daily', 'weekly' or empty.
+  // The format is 'elimination', 'league' or 'swiss'.
+  // A round check-in of 1 to 15 minutes has players check in to each match before its game
+  // is created; not for leagues.
+  createTournament(name: string, maxPlayers: number, startsAt: Date | null = null, repeat: string = '', format: string = 'elimination', rules: string = '', roundCheckIn: number = 0): void {
+    this.wsService.send({
+      type: 'tournament_create',
+      name: name,
+      format: format,
+      rules: rules,
+      max_players: maxPlayers,
+      ...(startsAt ? { starts_at: startsAt.toISOString(), repeat: repeat } : {}),
+      ...(roundCheckIn ? { round_check_in: roundCheckIn } : {})
+    });
+  }
+
+  // The player's match of a tournament with round check-in that waits for them to check in
+  matchAwaitingCheckIn(tournament: Tournament, playerId: string | undefined): TournamentMatch | undefined {
+    if (!tournament.round_check_in || tournament.status !== 'running' || !playerId) {
+      return undefined;
+    }
+    return tournament.rounds.flat().find(match =>
+      !match.result && !match.game_id && !!match.deadline && !!match.player1 && !!match.player2 &&
+      (match.player1.id === playerId || match.player2.id === playerId) && !match.check_ins?.includes(playerId));
+  }
+
+  // Standings of the leagues asked for with requestLeagueStandings, by tournament ID
+  getLeagueStandings(): Observable<Record<string, LeagueStandings>> {
+    return this.leagueStandings$.asObservable();
+  }
+
+  requestLeagueStandings(tournamentId: string): void {
+    this.wsService.send({ type: 'get_league_standings', tournament_id: tournamentId });
+  }
+
+  // Live games of the tournaments watched with watchTournament, by tournament ID
+  getTournamentScores(): Observable<Record<string, TournamentScores>> {
+    return this.tournamentScores$.asObservable();
+  }
+
+  // Streams the scores of a tournament's live games; spectating its featured game moves the
+  // player on to the next featured game when that one ends
+  watchTournament(tournamentId: string): void {
+    this.watchedTournaments.add(tournamentId);
+    this.wsService.send({ type: 'watch_tournament', tournament_id: tournamentId });
+  }
+
+  unwatchTournament(tournamentId: string): void {
+    this.watchedTournaments.delete(tournamentId);
+    this.wsService.send({ type: 'unwatch_tournament', tournament_id: tournamentId });
+    const { [tournamentId]: _, ...watched } = this.tournamentScores$.value;
+    this.tournamentScores$.next(watched);
+  }
+
+  checkIn(tournamentId: string): void {
+    this.wsService.send({ type: 'check_in', tournament_id: tournamentId });
+  }
+
+  registerForTournament(tournamentId: string, register: boolean): void {
+    this.wsService.send({ type: register ? 'tournament_register' : 'tournament_unregister', tournament_id: tournamentId });
+  }
+
+  startTournament(tournamentId: string): void {
+    this.wsService.send({ type: 'tournament_start', tournament_id: tournamentId });
+  }
+
+  // Moderator controls; the server answers players who don't run the tournament with NOT_ORGANIZER
+  setTournamentStaff(tournamentId: string, role: 'moderator' | 'caster', username: string, remove: boolean): void {
+    this.wsService.send({ type: `tournament_${role}`, tournament_id: tournamentId, username, remove });
+  }
+
+  pauseTournament(tournamentId: string, paused: boolean): void {
+    this.wsService.send({ type: 'tournament_pause', tournament_id: tournamentId, paused });
+  }
+
+  restartTournamentMatch(tournamentId: string, matchId: string): void {
+    this.wsService.send({ type: 'tournament_restart_match', tournament_id: tournamentId, match_id: matchId });
+  }
+
+  disqualifyFromTournament(tournamentId: string, playerId: string): void {
+    this.wsService.send({ type: 'tournament_disqualify', tournament_id: tournamentId, player_id: playerId });
+  }
+
+  extendTournamentCheckIn(tournamentId: string, minutes: number): void {
+    this.wsService.send({ type: 'tournament_extend_check_in', tournament_id: tournamentId, minutes });
+  }
+
+  // Latest states of the match games cast with castGames, by game ID
+  getCastStates(): Observable<Record<string, GameState>> {
+    return this.castStates$.asObservable();
+  }
+
+  // Follows tournament match games on this connection without spectating them; only the
+  // organizer, moderators and casters of their tournament may
+  castGames(gameIds: string[]): void {
+    this.wsService.send({ type: 'cast_games', game_ids: gameIds });
+  }
+
+  uncastGames(gameIds: string[]): void {
+    this.wsService.send({ type: 'uncast_games', game_ids: gameIds });
+  }
+
+  private updateTournament(tournament: Tournament): void {
+    const previous = this.tournaments$.value.find(t => t.id === tournament.id);
+    const playerId = this.currentPlayer$.value?.id;
+    if (previous && previous.status !== 'finished' && tournament.status === 'finished' && tournament.champion) {
+      this.showInfoBanner(`${tournament.champion.username} won ${tournament.name}!`);
+    } else if (previous?.status === 'registering' && tournament.status === 'check_in' &&
+      tournament.entrants.some(entrant => entrant.id === playerId && !entrant.checked_in)) {
+      this.showInfoBanner(`Check-in for ${tournament.name} is open. Check in to keep your place.`);
+    } else if (previous && previous.status !== 'running' && tournament.status === 'running' &&
+      tournament.entrants.some(entrant => entrant.id === playerId)) {
+      this.showInfoBanner(`${tournament.name} has started. Your match begins as soon as you and your opponent are free.`);
+    } else if (previous) {
+      const match = this.matchAwaitingCheckIn(tournament, playerId);
+      if (match && this.matchAwaitingCheckIn(previous, playerId)?.id !== match.id) {
+        this.showInfoBanner(`Your next match in ${tournament.name} is ready. Check in within ${tournament.round_check_in} minutes or you forfeit it.`, 'warning');
+      }
+    }
+    this.tournaments$.next(previous
+      ? this.tournaments$.value.map(t => t.id === tournament.id ? tournament : t)
+      : [tournament, ...this.tournaments$.value]);
+    // Keep the standings of a league or Swiss tournament shown in the lobby up to date
+    if (tournament.format !== 'elimination' && this.leagueStandings$.value[tournament.id]) {
+      this.requestLeagueStandings(tournament.id);
+    }
+  }
+
+  // Players this player recently played against, with whether they can be challenged now
+  getRecentOpponents(): Observable<RecentOpponent[]> {
+    return this.recentOpponents$.asObservable();
+  }
+
+  requestRecentOpponents(): void {
+    this.wsService.send({ type: 'get_recent_opponents' });
+  }
+
+  reportPlayer(username: string, category: string, comment: string, gameId?: string): void {
+    this.wsService.send({
+      type: 'report_player',
+      username: username,
+      category: category,
+      comment: comment,
+      game_id: gameId || ''
+    });
+  }
+
   joinAsSpectator(gameId: string): void {
+    this.dismissSpectatorInvite(gameId);
     this.wsService.send({
       type: 'join_spectator',
       game_id: gameId
@@ -746,6 +1397,8 @@ export class GameService {
     }
     // Clear game state immediately
     this.currentGameState$.next(null);
+    this.matchStats$.next(null);
+    this.loungeChat$.next([]);
   }
 
   disconnect(): void {
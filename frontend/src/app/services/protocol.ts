@@ -0,0 +1,1310 @@
+// Code generated by cmd/tsgen from the backend's Go structs; DO NOT EDIT.
+// Regenerate with `go generate .` in backend/.
+
+/** Every message type of the protocol, in either direction */
+export type MessageType =
+  | 'connected'
+  | 'batch'
+  | 'join_lobby'
+  | 'leave_lobby'
+  | 'game_request'
+  | 'game_request_sent'
+  | 'game_accept'
+  | 'game_reject'
+  | 'player_ready'
+  | 'game_start'
+  | 'game_update'
+  | 'player_move'
+  | 'game_over'
+  | 'error'
+  | 'lobby_status'
+  | 'match_found'
+  | 'list_games'
+  | 'games_list'
+  | 'join_spectator'
+  | 'spectator_update'
+  | 'rematch_request'
+  | 'rematch_accept'
+  | 'rematch_countdown'
+  | 'rematch_start'
+  | 'player_disconnected'
+  | 'player_reconnecting'
+  | 'player_reconnected'
+  | 'game_request_cancel'
+  | 'peer_offer'
+  | 'peer_answer'
+  | 'peer_ice_candidate'
+  | 'start_single_player'
+  | 'pause_game'
+  | 'restart_game'
+  | 'get_game_state'
+  | 'leave_game'
+  | 'list_rules'
+  | 'rules_list'
+  | 'start_hot_seat'
+  | 'emote'
+  | 'match_stats'
+  | 'lounge_chat'
+  | 'lounge_closed'
+  | 'invite_spectator'
+  | 'spectator_invite'
+  | 'spectator_invite_sent'
+  | 'report_player'
+  | 'report_received'
+  | 'mute_player'
+  | 'unmute_player'
+  | 'muted_players'
+  | 'get_recent_opponents'
+  | 'recent_opponents'
+  | 'announcement'
+  | 'announcement_cleared'
+  | 'kicked'
+  | 'game_force_ended'
+  | 'party_invite'
+  | 'party_accept'
+  | 'party_decline'
+  | 'party_leave'
+  | 'party_queue'
+  | 'party_update'
+  | 'party_left'
+  | 'party_spectate'
+  | 'update_profile'
+  | 'profile'
+  | 'title_earned'
+  | 'clan_create'
+  | 'clan_invite'
+  | 'clan_accept'
+  | 'clan_decline'
+  | 'clan_leave'
+  | 'clan_remove'
+  | 'clan_set_role'
+  | 'clan_update'
+  | 'clan_left'
+  | 'get_clan_scoreboard'
+  | 'clan_scoreboard'
+  | 'tournament_create'
+  | 'tournament_register'
+  | 'tournament_unregister'
+  | 'tournament_start'
+  | 'check_in'
+  | 'tournament_update'
+  | 'list_tournaments'
+  | 'tournaments_list'
+  | 'get_tournament'
+  | 'get_league_standings'
+  | 'league_standings'
+  | 'watch_tournament'
+  | 'unwatch_tournament'
+  | 'tournament_scores'
+  | 'tournament_featured'
+  | 'get_circuit'
+  | 'circuit_standings'
+  | 'circuit_points'
+  | 'tournament_moderator'
+  | 'tournament_pause'
+  | 'tournament_restart_match'
+  | 'tournament_disqualify'
+  | 'tournament_extend_check_in'
+  | 'tournament_caster'
+  | 'tournament_verify_match'
+  | 'match_verification'
+  | 'cast_games'
+  | 'uncast_games'
+  | 'casting'
+  | 'cast_update'
+  | 'get_campaign'
+  | 'start_campaign_level'
+  | 'campaign'
+  | 'get_leaderboard'
+  | 'leaderboard'
+  | 'start_practice'
+  | 'suspend_game'
+  | 'resume_game'
+  | 'get_saved_game'
+  | 'saved_game'
+  | 'get_single_player_stats'
+  | 'single_player_stats';
+
+// Game state
+
+export interface GameState {
+  id: string;
+  snakes: Snake[];
+  /** First item of Foods, kept for older clients */
+  food: Food;
+  /** All food on the board */
+  foods: Food[];
+  /** Cells no snake may enter; fixed for the whole game */
+  obstacles?: Position[];
+  /** "waiting", "countdown", "playing", "paused", "finished" */
+  status: string;
+  /** Ticks simulated in the current round; moves name the one they were sent after */
+  tick: number;
+  countdown: number;
+  winner?: string;
+  players?: PlayerStatus[];
+  is_single_player?: boolean;
+  /** Both snakes are steered from one connection */
+  hot_seat?: boolean;
+  /** The snakes of a hot-seat game play as a team */
+  coop?: boolean;
+  /** A practice game against a server bot, nothing is recorded */
+  practice?: boolean;
+  /** Food eaten by a co-op team */
+  team_score?: number;
+  /** Difficulty of a single player game */
+  difficulty?: string;
+  /** Speed stages a single player game went up */
+  speed_stage?: number;
+  campaign_level?: number;
+  /** endless, score_attack or daily, for a single player game outside the campaign */
+  variant?: string;
+  /** Seconds left in a score attack */
+  time_left?: number;
+  /** Tie-break rule applied after a head-on collision this round */
+  tie_break?: string;
+  /** The round was replayed after a head-on collision; the first snake to eat wins */
+  sudden_death?: boolean;
+}
+
+export interface Snake {
+  id: string;
+  body: Position[];
+  direction: Direction;
+  color: string;
+  score: number;
+  username?: string;
+  /** Crashed and waiting to respawn; it stays where it crashed */
+  down?: boolean;
+}
+
+export interface Food {
+  position: Position;
+}
+
+export interface Position {
+  x: number;
+  y: number;
+}
+
+export interface PlayerStatus {
+  id: string;
+  username: string;
+  ready: boolean;
+}
+
+/** RulesProfile describes the rules a game is played with */
+export interface RulesProfile {
+  name: string;
+  wrap: boolean;
+  tick_rate_ms: number;
+  food_count: number;
+  win_condition: string;
+  target_score?: number;
+  power_ups: boolean;
+  /** How a head-on collision is decided: "score" when empty, "tie", "length" or "sudden_death" */
+  tie_break?: string;
+  /** Layout the snakes start in, such as "random_symmetric"; the server's configured one when empty */
+  spawn_layout?: string;
+  /** How new food is placed: "random" when empty, or "fair" for cells as far from each head */
+  food_spawn?: string;
+  /** Crashed snakes respawn after this long instead of ending the round; 0 for never */
+  respawn_ms?: number;
+}
+
+// Server message payloads
+
+/** ServerInfo identifies the server instance a client is connected to */
+export interface ServerInfo {
+  instance_id: string;
+  region: string;
+}
+
+/** ServerError is an error message sent by the server */
+export interface ServerError {
+  game_id?: string;
+  code?: string;
+  message: string;
+}
+
+/** PlayerInfo identifies another player */
+export interface PlayerInfo {
+  id: string;
+  username: string;
+}
+
+/** LobbyPlayer is an entry of the lobby status */
+export interface LobbyPlayer {
+  id: string;
+  username: string;
+  ready: boolean;
+  in_game?: boolean;
+  bot?: boolean;
+  /** Name of the player's equipped title */
+  title?: string;
+  /** Tag of the player's clan */
+  clan?: string;
+  /** Set for players connected to another instance */
+  instance_id?: string;
+}
+
+/** GameSummary is an unfinished game as listed in the lobby */
+export interface GameSummary {
+  id: string;
+  player1: string;
+  /** Empty for single player games */
+  player2?: string;
+  status: string;
+  spectators: number;
+  instance_id: string;
+  region: string;
+}
+
+/** GameRequest is a challenge received from another player, or the confirmation of one sent */
+export interface GameRequest {
+  game_id: string;
+  /** Set on received challenges */
+  from_player: PlayerInfo;
+  /** Set on sent challenges */
+  to_player: PlayerInfo;
+  rules: RulesProfile;
+}
+
+/** GameStart is sent when a round starts */
+export interface GameStart {
+  data: GameState | null;
+  rules: RulesProfile;
+  /** Player ID -> name of their equipped title */
+  titles: Record<string, string>;
+}
+
+/** RematchRequest is a rematch asked for by the opponent */
+export interface RematchRequest {
+  game_id: string;
+  requester_id: string;
+  requester_name: string;
+}
+
+/** Disconnect is sent when the opponent leaves a game */
+export interface Disconnect {
+  game_id: string;
+  /** Username of the player who left */
+  player: string;
+  message: string;
+}
+
+/**
+ * Reconnecting is sent when the opponent drops out of a game and the server waits for them
+ * to come back
+ */
+export interface Reconnecting {
+  game_id: string;
+  /** Username of the player who dropped out */
+  player: string;
+  /** How long the server waits before they lose the round */
+  grace_seconds: number;
+}
+
+/**
+ * Kicked is sent when an admin removes this player from the server, just before the
+ * connection is closed
+ */
+export interface Kicked {
+  /** KICKED, or BANNED for a ban */
+  code: string;
+  message: string;
+  /** Given by the admin, may be empty */
+  reason: string;
+  /** The player's token is refused until then; zero for a ban, which lasts until lifted */
+  until: string;
+}
+
+/**
+ * GameForceEnded is sent to the players and spectators of a game an admin ended; the game is
+ * removed right after and its players are back in the lobby
+ */
+export interface GameForceEnded {
+  game_id: string;
+  /** tie, win or void, when nothing was recorded */
+  outcome: string;
+  /** ID of the player awarded the round, empty unless the outcome is win */
+  winner: string;
+  winner_username: string;
+  /** Given by the admin, may be empty */
+  reason: string;
+  code: string;
+  message: string;
+}
+
+/** RulesList lists the rules presets and single player difficulties the server offers */
+export interface RulesList {
+  default: string;
+  rules: RulesProfile[];
+  default_difficulty: string;
+  difficulties: Difficulty[];
+  /** Single player variants, the default first */
+  variants: string[];
+}
+
+/** Emote is a predefined quick-chat message the server accepts */
+export interface Emote {
+  id: string;
+  text: string;
+}
+
+/** EmoteEvent is an emote sent by a player of a game this player plays in or watches */
+export interface EmoteEvent {
+  game_id: string;
+  player_id: string;
+  username: string;
+  emote_id: string;
+  text: string;
+}
+
+/** MatchStats summarizes a finished round; it follows game_over */
+export interface MatchStats {
+  game_id: string;
+  winner: string;
+  /** 1 for the first round, one more per rematch */
+  round: number;
+  duration_ms: number;
+  ticks: number;
+  /** Difficulty of a single player game */
+  difficulty?: string;
+  /** Variant of a single player game */
+  variant?: string;
+  players: PlayerMatchStats[];
+}
+
+/** LoungeChat is a chat message sent in the lounge of a finished game */
+export interface LoungeChat {
+  game_id: string;
+  player_id: string;
+  username: string;
+  spectator: boolean;
+  text: string;
+  sent_at: string;
+}
+
+/** SpectatorInvite invites this player to watch a game */
+export interface SpectatorInvite {
+  game_id: string;
+  from_id: string;
+  from_username: string;
+  /** Usernames of the game's players */
+  players: string[];
+  /** Web client route that watches the game */
+  link: string;
+}
+
+/** ReportReceipt confirms a player report was filed for review */
+export interface ReportReceipt {
+  report_id: number;
+  /** The reported player */
+  username: string;
+  category: string;
+}
+
+/**
+ * RecentOpponent is someone the player recently played against, with their current status
+ * Challenge an opponent that is InLobby with their PlayerID.
+ */
+export interface RecentOpponent {
+  /** Player ID the opponent had in the last round */
+  id: string;
+  username: string;
+  last_game_id: string;
+  last_played: string;
+  /** win, loss or tie, for this player */
+  last_result: string;
+  rounds: number;
+  online: boolean;
+  in_lobby: boolean;
+  in_game: boolean;
+  /** Current player ID while online */
+  player_id?: string;
+}
+
+/** Announcement is a server-wide banner from the operators */
+export interface Announcement {
+  id: string;
+  text: string;
+  /** info, warning or critical */
+  severity: string;
+  sent_at: string;
+  /** When to hide the banner; unset when it has no expiry */
+  expires_at?: string;
+}
+
+/** PartyInvite invites this player to join a party */
+export interface PartyInvite {
+  party_id: string;
+  from_id: string;
+  from_username: string;
+  /** Usernames of the party's members */
+  members: string[];
+}
+
+/** Party is the current state of the player's party */
+export interface Party {
+  party_id: string;
+  leader_id: string;
+  /** In join order */
+  members: PlayerInfo[];
+  /** Invited players who haven't answered yet */
+  invited: PlayerInfo[];
+}
+
+/** PartySpectate tells this player they were added as a spectator of a party member's game */
+export interface PartySpectate {
+  party_id: string;
+  game_id: string;
+  /** Usernames of the game's players */
+  players: string[];
+  /** Web client route that watches the game */
+  link: string;
+}
+
+/** Title is a badge a player earns and can show next to their username */
+export interface Title {
+  id: string;
+  name: string;
+  description: string;
+  /** achievement, season or tournament */
+  source: string;
+}
+
+/** EarnedTitle is a title this player earned */
+export interface EarnedTitle {
+  id: string;
+  name: string;
+  description: string;
+  /** achievement, season or tournament */
+  source: string;
+  earned_at: string;
+}
+
+/**
+ * SinglePlayerStats sums up the player's finished single player games, kept apart from
+ * their multiplayer rounds
+ */
+export interface SinglePlayerStats {
+  games_played: number;
+  best_score: number;
+  total_food: number;
+  longest_survival_ms: number;
+  /** Mean snake length at the end of a game */
+  average_length: number;
+}
+
+/** Profile is this player's equipped title and the titles they can equip */
+export interface Profile {
+  /** Elo rating from finished multiplayer rounds */
+  rating: number;
+  /** Unset when no title is equipped */
+  title?: Title | null;
+  titles: EarnedTitle[];
+  /** Unset on servers without profiles */
+  single_player?: SinglePlayerStats | null;
+}
+
+/** ClanMember is a player in a clan */
+export interface ClanMember {
+  player_id: string;
+  /** Username when the player joined */
+  username: string;
+  /** leader, officer or member */
+  role: string;
+  joined_at: string;
+}
+
+/** Clan is the player's clan */
+export interface Clan {
+  tag: string;
+  name: string;
+  created_at: string;
+  /** Leader first, then by role and join time */
+  members: ClanMember[];
+}
+
+/** ClanInvite invites this player to join a clan */
+export interface ClanInvite {
+  tag: string;
+  name: string;
+  members: number;
+  from_id: string;
+  from_username: string;
+}
+
+/** ClanStanding is a clan's record in rounds against members of other clans */
+export interface ClanStanding {
+  tag: string;
+  name: string;
+  members: number;
+  rounds: number;
+  wins: number;
+  losses: number;
+  ties: number;
+}
+
+/** ClanMatchup is the head-to-head record of two clans */
+export interface ClanMatchup {
+  /** The tag that sorts first */
+  clan_a: string;
+  clan_b: string;
+  wins_a: number;
+  wins_b: number;
+  ties: number;
+}
+
+/** ClanScoreboard is the clan-vs-clan record computed from member rounds */
+export interface ClanScoreboard {
+  /** Most wins first */
+  standings: ClanStanding[];
+  /** Most rounds first */
+  matchups: ClanMatchup[];
+}
+
+/** TournamentEntrant is a player registered for a tournament */
+export interface TournamentEntrant {
+  id: string;
+  username: string;
+  /** Set once the tournament starts; 1 is the top seed */
+  seed?: number;
+  /** Rating when registered, which seeds are given by */
+  rating?: number;
+  /** Final placing once finished; tied entrants share the best */
+  finish?: number;
+  /** Confirmed during check-in */
+  checked_in?: boolean;
+  /** Removed by a moderator; isn't placed */
+  disqualified?: boolean;
+}
+
+/** TournamentMatch is a pairing of a tournament bracket */
+export interface TournamentMatch {
+  id: string;
+  /** 1 for the first round */
+  round: number;
+  /** Position within the round, from the top of the bracket */
+  index: number;
+  player1: TournamentEntrant | null;
+  player2: TournamentEntrant | null;
+  /** The game being played for the match */
+  game_id?: string;
+  /** Player ID of the winner once decided */
+  winner?: string;
+  /** played, forfeit, no_show, bye, draw, expired or disqualified */
+  result?: string;
+  /** When players who haven't shown up forfeit */
+  deadline?: string;
+  /** Seed of the latest game played for the match */
+  seed?: number;
+  /** The next game reuses Seed: a moderator restarted the match */
+  replay?: boolean;
+  /** Player ID -> score in the game that decided the match */
+  scores?: Record<string, number>;
+  /** Players who checked in to the match, with round check-in */
+  check_ins?: string[];
+}
+
+/** Tournament is a single-elimination bracket and its registered players */
+export interface Tournament {
+  id: string;
+  name: string;
+  /** elimination, league or swiss */
+  format: string;
+  rules: string;
+  organizer_id: string;
+  organizer_name: string;
+  max_players: number;
+  /** registering, check_in, running, finished or canceled */
+  status: string;
+  created_at: string;
+  started_at?: string;
+  finished_at?: string;
+  entrants: TournamentEntrant[];
+  /** First round first, empty until started; every pairing of a league */
+  rounds: (TournamentMatch | null)[][];
+  champion?: TournamentEntrant | null;
+  /** Scheduled start, zero when the organizer starts it */
+  starts_at?: string;
+  /** daily or weekly for a recurring tournament */
+  repeat?: string;
+  /** ID of the first tournament of a recurring series */
+  series?: string;
+  /** Incremented on every change */
+  revision: number;
+  /** How long a league runs once started */
+  window_hours?: number;
+  /** When a running league closes */
+  ends_at?: string;
+  /** Rounds a Swiss tournament plays; each is drawn once the previous one is decided */
+  swiss_rounds?: number;
+  /** Players who run the tournament with the organizer */
+  moderators?: TournamentStaff[];
+  /** Players who may cast its match games */
+  casters?: TournamentStaff[];
+  /** No match games are created while paused */
+  paused?: boolean;
+  /** When the running pause began */
+  paused_at?: string;
+  /** How far check-in was extended past the scheduled start */
+  delay_minutes?: number;
+  /** Minutes players have to check in to each match */
+  round_check_in?: number;
+}
+
+/** LeagueStanding is an entrant's record in a league or Swiss tournament */
+export interface LeagueStanding {
+  rank: number;
+  entrant: TournamentEntrant;
+  played: number;
+  wins: number;
+  draws: number;
+  losses: number;
+  /** 3 per win and 1 per draw */
+  points: number;
+  /** Breaks ties in points and wins */
+  sonneborn_berger: number;
+}
+
+/** LeagueStandings ranks the entrants of a league */
+export interface LeagueStandings {
+  tournament_id: string;
+  standings: LeagueStanding[];
+}
+
+/** TournamentScore is a match player's score in a live tournament game */
+export interface TournamentScore {
+  id: string;
+  username: string;
+  score: number;
+}
+
+/** TournamentGame is a live match game of a watched tournament */
+export interface TournamentGame {
+  match_id: string;
+  round: number;
+  game_id: string;
+  status: string;
+  players: TournamentScore[];
+  spectators: number;
+}
+
+/** TournamentScores lists the live games of a watched tournament in bracket order */
+export interface TournamentScores {
+  tournament_id: string;
+  games: TournamentGame[];
+  /** Game ID of the featured match, empty when none is live */
+  featured: string;
+}
+
+/** TournamentFeatured announces the new featured game of a watched tournament */
+export interface TournamentFeatured {
+  tournament_id: string;
+  match_id: string;
+  game_id: string;
+  /** The player was moved to the game from the featured one that ended */
+  spectating: boolean;
+}
+
+/** CircuitStanding is a player's record in a season of the tournament circuit */
+export interface CircuitStanding {
+  rank: number;
+  player_id: string;
+  username: string;
+  points: number;
+  /** Tournaments finished in the season */
+  tournaments: number;
+  /** Tournaments won in the season */
+  wins: number;
+}
+
+/** CircuitStandings ranks the players of a circuit season */
+export interface CircuitStandings {
+  /** A month such as 2026-01 */
+  season: string;
+  standings: CircuitStanding[];
+}
+
+/** CircuitPoints tells a player the circuit points a tournament placing earned them */
+export interface CircuitPoints {
+  tournament_id: string;
+  tournament_name: string;
+  season: string;
+  finish: number;
+  points: number;
+  /** The player's points in the season so far */
+  total: number;
+}
+
+/** CastUpdate is a message sent to the players and spectators of a game the player casts */
+export interface CastUpdate {
+  game_id: string;
+  tournament_id: string;
+  /** Type of the message, such as game_update or game_over */
+  event: string;
+  /** The message's data, a game state for game_update and game_over */
+  data?: unknown;
+}
+
+/** Casting lists the games the player casts */
+export interface Casting {
+  game_ids: string[];
+}
+
+/** Campaign lists the levels of the single player campaign and how far the player got */
+export interface Campaign {
+  levels: CampaignLevel[];
+  /** Highest level cleared; the next one is unlocked */
+  cleared: number;
+  /** Level the player just cleared, when sent after a game */
+  level_cleared?: number;
+}
+
+/** HighScore is a player's best score on a leaderboard */
+export interface HighScore {
+  rank: number;
+  player_id: string;
+  username: string;
+  score: number;
+  set_at: string;
+}
+
+/**
+ * Leaderboard ranks the best scores of a single player variant played at a difficulty
+ * The daily run's ranking also names its day and when it resets.
+ */
+export interface Leaderboard {
+  variant: string;
+  difficulty: string;
+  date?: string;
+  resets_at?: string;
+  scores: HighScore[];
+}
+
+/** SavedGame is a single player game the player suspended and can resume */
+export interface SavedGame {
+  saved_at: string;
+  rules: string;
+  difficulty?: string;
+  variant?: string;
+  campaign_level?: number;
+  score: number;
+}
+
+// Client messages
+
+export interface CastGamesMessage {
+  type: 'cast_games';
+  /** Match games; uncasting none stops every game cast */
+  game_ids?: string[];
+}
+
+export interface CheckInMessage {
+  type: 'check_in';
+  tournament_id: string;
+}
+
+export interface ClanAcceptMessage {
+  type: 'clan_accept';
+  tag: string;
+}
+
+export interface ClanCreateMessage {
+  type: 'clan_create';
+  tag: string;
+  name: string;
+}
+
+export interface ClanDeclineMessage {
+  type: 'clan_decline';
+  tag: string;
+}
+
+export interface ClanInviteMessage {
+  type: 'clan_invite';
+  username: string;
+}
+
+export interface ClanLeaveMessage {
+  type: 'clan_leave';
+}
+
+export interface ClanRemoveMessage {
+  type: 'clan_remove';
+  target_id: string;
+}
+
+export interface ClanSetRoleMessage {
+  type: 'clan_set_role';
+  target_id: string;
+  /** leader, officer or member */
+  role: string;
+}
+
+export interface EmoteMessage {
+  type: 'emote';
+  game_id: string;
+  emote_id: string;
+}
+
+export interface GameAcceptMessage {
+  type: 'game_accept';
+  game_id: string;
+}
+
+export interface GameRejectMessage {
+  type: 'game_reject';
+  game_id: string;
+}
+
+export interface GameRequestMessage {
+  type: 'game_request';
+  target_id: string;
+  rules?: string;
+  /** Replaces the preset's tie-break; empty keeps it */
+  tie_break?: string;
+}
+
+export interface GameRequestCancelMessage {
+  type: 'game_request_cancel';
+  target_id: string;
+}
+
+export interface GetCampaignMessage {
+  type: 'get_campaign';
+}
+
+export interface GetCircuitMessage {
+  type: 'get_circuit';
+  /** A month such as 2026-01; empty for the current season */
+  season?: string;
+}
+
+export interface GetClanScoreboardMessage {
+  type: 'get_clan_scoreboard';
+}
+
+export interface GetGameStateMessage {
+  type: 'get_game_state';
+  game_id: string;
+}
+
+export interface GetLeaderboardMessage {
+  type: 'get_leaderboard';
+  /** Empty for endless */
+  variant?: string;
+  /** Empty for normal */
+  difficulty?: string;
+}
+
+export interface GetLeagueStandingsMessage {
+  type: 'get_league_standings';
+  tournament_id: string;
+}
+
+export interface GetRecentOpponentsMessage {
+  type: 'get_recent_opponents';
+}
+
+export interface GetSavedGameMessage {
+  type: 'get_saved_game';
+}
+
+export interface GetSinglePlayerStatsMessage {
+  type: 'get_single_player_stats';
+}
+
+export interface GetTournamentMessage {
+  type: 'get_tournament';
+  tournament_id: string;
+}
+
+export interface InviteSpectatorMessage {
+  type: 'invite_spectator';
+  game_id: string;
+  username: string;
+}
+
+export interface JoinLobbyMessage {
+  type: 'join_lobby';
+}
+
+export interface JoinSpectatorMessage {
+  type: 'join_spectator';
+  game_id: string;
+}
+
+export interface LeaveGameMessage {
+  type: 'leave_game';
+  game_id: string;
+}
+
+export interface LeaveLobbyMessage {
+  type: 'leave_lobby';
+}
+
+export interface ListGamesMessage {
+  type: 'list_games';
+}
+
+export interface ListRulesMessage {
+  type: 'list_rules';
+}
+
+export interface ListTournamentsMessage {
+  type: 'list_tournaments';
+}
+
+export interface LoungeChatMessage {
+  type: 'lounge_chat';
+  game_id: string;
+  text: string;
+}
+
+export interface MutePlayerMessage {
+  type: 'mute_player';
+  username: string;
+}
+
+export interface PartyAcceptMessage {
+  type: 'party_accept';
+  party_id: string;
+}
+
+export interface PartyDeclineMessage {
+  type: 'party_decline';
+  party_id: string;
+}
+
+export interface PartyInviteMessage {
+  type: 'party_invite';
+  username: string;
+}
+
+export interface PartyLeaveMessage {
+  type: 'party_leave';
+}
+
+export interface PartyQueueMessage {
+  type: 'party_queue';
+  rules?: string;
+}
+
+export interface PauseGameMessage {
+  type: 'pause_game';
+  game_id: string;
+  /** false resumes the game */
+  paused?: boolean;
+}
+
+export interface PlayerMoveMessage {
+  type: 'player_move';
+  game_id: string;
+  direction: string;
+  /** 2 steers the guest of a hot-seat or co-op game; 0 or 1 the sender's own snake */
+  snake_slot?: number;
+  /** Tick of the latest game update the client had; the move is meant for the next one */
+  tick?: number | null;
+}
+
+export interface PlayerReadyMessage {
+  type: 'player_ready';
+  game_id: string;
+}
+
+export interface RematchAcceptMessage {
+  type: 'rematch_accept';
+  game_id: string;
+}
+
+export interface RematchRequestMessage {
+  type: 'rematch_request';
+  game_id: string;
+}
+
+export interface ReportPlayerMessage {
+  type: 'report_player';
+  username: string;
+  category: string;
+  comment?: string;
+  /** The game the report is about, if any */
+  game_id?: string;
+}
+
+export interface RestartGameMessage {
+  type: 'restart_game';
+  game_id: string;
+}
+
+export interface ResumeGameMessage {
+  type: 'resume_game';
+}
+
+export interface StartCampaignLevelMessage {
+  type: 'start_campaign_level';
+  /** From 1 */
+  level?: number;
+}
+
+export interface StartHotSeatMessage {
+  type: 'start_hot_seat';
+  rules?: string;
+  guest_name?: string;
+}
+
+export interface StartPracticeMessage {
+  type: 'start_practice';
+  /** Bundled bot to practice against; empty for greedy */
+  bot?: string;
+}
+
+export interface StartSinglePlayerMessage {
+  type: 'start_single_player';
+  rules?: string;
+  /** easy, normal or hard; empty for normal */
+  difficulty?: string;
+  /** endless, score_attack or daily; empty for endless. A daily run ignores rules and difficulty */
+  variant?: string;
+  /** Adds a guest snake steered with snake_slot 2 as a teammate; ignores difficulty and variant */
+  coop?: boolean;
+  /** Names the co-op guest */
+  guest_name?: string;
+}
+
+export interface SuspendGameMessage {
+  type: 'suspend_game';
+  game_id: string;
+}
+
+export interface TournamentCasterMessage {
+  type: 'tournament_caster';
+  tournament_id: string;
+  username: string;
+  /** Dismisses the player instead */
+  remove?: boolean;
+}
+
+export interface TournamentCreateMessage {
+  type: 'tournament_create';
+  name: string;
+  /** elimination (default) or league */
+  format?: string;
+  rules?: string;
+  /** 0 uses DefaultTournamentPlayers */
+  max_players?: number;
+  /** Scheduled start; zero for the organizer to start it */
+  starts_at?: string;
+  /** daily or weekly for a recurring scheduled tournament */
+  repeat?: string;
+  /** How long a league runs once started */
+  window_hours?: number;
+  /** Minutes to check in to each match, 1 to 15; 0 for none */
+  round_check_in?: number;
+}
+
+export interface TournamentDisqualifyMessage {
+  type: 'tournament_disqualify';
+  tournament_id: string;
+  player_id: string;
+}
+
+export interface TournamentExtendCheckInMessage {
+  type: 'tournament_extend_check_in';
+  tournament_id: string;
+  /** 1 to 60 */
+  minutes?: number;
+}
+
+export interface TournamentModeratorMessage {
+  type: 'tournament_moderator';
+  tournament_id: string;
+  username: string;
+  /** Dismisses the player instead */
+  remove?: boolean;
+}
+
+export interface TournamentPauseMessage {
+  type: 'tournament_pause';
+  tournament_id: string;
+  /** false resumes the tournament */
+  paused?: boolean;
+}
+
+export interface TournamentRegisterMessage {
+  type: 'tournament_register';
+  tournament_id: string;
+}
+
+export interface TournamentRestartMatchMessage {
+  type: 'tournament_restart_match';
+  tournament_id: string;
+  match_id: string;
+}
+
+export interface TournamentStartMessage {
+  type: 'tournament_start';
+  tournament_id: string;
+}
+
+export interface TournamentUnregisterMessage {
+  type: 'tournament_unregister';
+  tournament_id: string;
+}
+
+export interface TournamentVerifyMatchMessage {
+  type: 'tournament_verify_match';
+  tournament_id: string;
+  match_id: string;
+}
+
+export interface UncastGamesMessage {
+  type: 'uncast_games';
+  /** Match games; uncasting none stops every game cast */
+  game_ids?: string[];
+}
+
+export interface UnmutePlayerMessage {
+  type: 'unmute_player';
+  username: string;
+}
+
+export interface UnwatchTournamentMessage {
+  type: 'unwatch_tournament';
+  tournament_id: string;
+}
+
+export interface UpdateProfileMessage {
+  type: 'update_profile';
+  /** Earned title ID to equip; empty takes the title off */
+  title?: string;
+}
+
+export interface WatchTournamentMessage {
+  type: 'watch_tournament';
+  tournament_id: string;
+}
+
+/** Any message a client may send */
+export type ClientMessage =
+  | CastGamesMessage
+  | CheckInMessage
+  | ClanAcceptMessage
+  | ClanCreateMessage
+  | ClanDeclineMessage
+  | ClanInviteMessage
+  | ClanLeaveMessage
+  | ClanRemoveMessage
+  | ClanSetRoleMessage
+  | EmoteMessage
+  | GameAcceptMessage
+  | GameRejectMessage
+  | GameRequestMessage
+  | GameRequestCancelMessage
+  | GetCampaignMessage
+  | GetCircuitMessage
+  | GetClanScoreboardMessage
+  | GetGameStateMessage
+  | GetLeaderboardMessage
+  | GetLeagueStandingsMessage
+  | GetRecentOpponentsMessage
+  | GetSavedGameMessage
+  | GetSinglePlayerStatsMessage
+  | GetTournamentMessage
+  | InviteSpectatorMessage
+  | JoinLobbyMessage
+  | JoinSpectatorMessage
+  | LeaveGameMessage
+  | LeaveLobbyMessage
+  | ListGamesMessage
+  | ListRulesMessage
+  | ListTournamentsMessage
+  | LoungeChatMessage
+  | MutePlayerMessage
+  | PartyAcceptMessage
+  | PartyDeclineMessage
+  | PartyInviteMessage
+  | PartyLeaveMessage
+  | PartyQueueMessage
+  | PauseGameMessage
+  | PlayerMoveMessage
+  | PlayerReadyMessage
+  | RematchAcceptMessage
+  | RematchRequestMessage
+  | ReportPlayerMessage
+  | RestartGameMessage
+  | ResumeGameMessage
+  | StartCampaignLevelMessage
+  | StartHotSeatMessage
+  | StartPracticeMessage
+  | StartSinglePlayerMessage
+  | SuspendGameMessage
+  | TournamentCasterMessage
+  | TournamentCreateMessage
+  | TournamentDisqualifyMessage
+  | TournamentExtendCheckInMessage
+  | TournamentModeratorMessage
+  | TournamentPauseMessage
+  | TournamentRegisterMessage
+  | TournamentRestartMatchMessage
+  | TournamentStartMessage
+  | TournamentUnregisterMessage
+  | TournamentVerifyMatchMessage
+  | UncastGamesMessage
+  | UnmutePlayerMessage
+  | UnwatchTournamentMessage
+  | UpdateProfileMessage
+  | WatchTournamentMessage;
+
+export type Direction = number;
+
+/**
+ * Difficulty sets how fast a single player game starts and how quickly it speeds up
+ * Every StageFood food eaten moves the game up a speed stage that ticks StageMs faster,
+ * until it ticks every MinTickRateMs.
+ */
+export interface Difficulty {
+  name: string;
+  /** Starting tick rate; 0 keeps the rules' own */
+  tick_rate_ms: number;
+  /** Food eaten per speed stage */
+  stage_food: number;
+  /** How much faster each stage ticks */
+  stage_ms: number;
+  /** The fastest the game gets */
+  min_tick_rate_ms: number;
+}
+
+/** PlayerMatchStats is one player's part of MatchStats */
+export interface PlayerMatchStats {
+  id: string;
+  username: string;
+  score: number;
+  /** Snake length at the end of the round */
+  length: number;
+  /** Turns applied during the round */
+  turns: number;
+  /** Rounds won in this game, across rematches */
+  wins: number;
+}
+
+/** TournamentStaff is a player the organizer appointed a moderator or caster of a tournament */
+export interface TournamentStaff {
+  id: string;
+  username: string;
+}
+
+/**
+ * CampaignLevel is one level of the single player campaign: a board, a speed and the score
+ * that clears it
+ */
+export interface CampaignLevel {
+  level: number;
+  name: string;
+  target_score: number;
+  tick_rate_ms: number;
+  wrap: boolean;
+  obstacles: Position[];
+}
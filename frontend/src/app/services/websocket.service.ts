@@ -2,6 +2,16 @@ import { Injectable } from '@angular/core';
 import { Subject, Observable } from 'rxjs';
 import { environment } from '../../environments/environment';
 
+// WebSocket subprotocols naming the protocol versions and encodings this client speaks,
+// batch envelopes first
+const SUBPROTOCOLS = ['snake.v2.json', 'snake.v1.json'];
+
+// Local storage key of the device ID sent when connecting
+const DEVICE_ID_KEY = 'snake_device_id';
+
+// Start of every frame under snake.v2.json: one envelope with the messages of a tick
+const ENVELOPE_PREFIX = '{"type":"batch",';
+
 @Injectable({
   providedIn: 'root'
 })
@@ -92,6 +102,17 @@ export class WebSocketService {
     return this.token || localStorage.getItem('snake_game_token');
   }
 
+  // ID of this browser, kept across accounts, so moderators can tell accounts played from
+  // one device apart
+  private getDeviceId(): string {
+    let deviceId = localStorage.getItem(DEVICE_ID_KEY);
+    if (!deviceId) {
+      deviceId = crypto.randomUUID();
+      localStorage.setItem(DEVICE_ID_KEY, deviceId);
+    }
+    return deviceId;
+  }
+
   private setupConnection(username: string): void {
     try {
       let wsUrl = this.getWebSocketUrl();
@@ -106,7 +127,8 @@ export class WebSocketService {
         console.error('Cannot connect: Token or username required');
         return;
       }
-      this.ws = new WebSocket(wsUrl);
+      wsUrl += `&device=${encodeURIComponent(this.getDeviceId())}`;
+      this.ws = new WebSocket(wsUrl, SUBPROTOCOLS);
       this.connectionStateSubject.next('connecting');
 
       this.ws.onopen = () => {
@@ -121,12 +143,12 @@ export class WebSocketService {
           const bytes = new Blob([event.data]).size;
           this.bytesReceived += bytes;
           
-          // Split by newline in case multiple messages are concatenated
-          const messages = event.data.split('\n').filter((m: string) => m.trim());
-          messages.forEach((msg: string) => {
-            const message = JSON.parse(msg);
-            this.messageSubject.next(message);
-          });
+          // A frame carries a batch envelope, or messages separated by newlines
+          const data: string = event.data;
+          const messages: any[] = data.startsWith(ENVELOPE_PREFIX)
+            ? JSON.parse(data).messages
+            : data.split('\n').filter((m: string) => m.trim()).map((m: string) => JSON.parse(m));
+          messages.forEach((message: any) => this.messageSubject.next(message));
         } catch (error) {
           console.error('Error parsing WebSocket message:', error, 'Raw data:', event.data);
         }
@@ -264,10 +286,10 @@ export class WebSocketService {
       }
     }
 
-    // Production: Use same hostname as frontend, no port (HTTPS/WSS handles it)
+    // Production: Use same origin as frontend (behind the proxy, or served by the backend itself)
     // Development/Docker: Use same hostname with backend port (8020)
     if (environment.production) {
-      const url = `${protocol}//${host}/ws`;
+      const url = `${protocol}//${window.location.host}/ws`;
       console.log('WebSocket URL (production):', url, 'hostname:', host);
       return url;
     }